@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -9,13 +10,24 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/handlers"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/migrations"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/realtime"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	skipMigrations := flag.Bool("skip-migrations", false, "start the server without applying pending migrations")
+	flag.Parse()
+
 	// Initialize config
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -23,12 +35,13 @@ func main() {
 	}
 
 	log.Printf("Starting server in %s environment...", cfg.Environment)
+	cfg.LogSummary()
 
 	// Initialize MongoDB client
 	mongoClient, _, err := config.InitMongoDB(cfg)
 	if err != nil {
 		log.Printf("MongoDB connection error: %v", err)
-		log.Printf("Check if MongoDB is running at %s", cfg.MongoURI)
+		log.Printf("Check if MongoDB is running at %s", config.RedactURI(cfg.MongoURI))
 		log.Fatal("Cannot continue without database connection")
 	}
 	defer func() {
@@ -37,13 +50,30 @@ func main() {
 		}
 	}()
 
+	// Apply pending index/schema migrations
+	if *migrateOnly || !*skipMigrations {
+		migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 2*time.Minute)
+		err := migrations.Run(migrateCtx, mongoClient.Database(cfg.DatabaseName))
+		cancelMigrate()
+		if *migrateOnly {
+			if err != nil {
+				log.Fatalf("Migrations failed: %v", err)
+			}
+			log.Println("Migrations complete")
+			return
+		}
+		if err != nil {
+			log.Printf("Warning: migrations failed, continuing startup: %v", err)
+		}
+	}
+
 	// Initialize Redis client
 	redisClient, err := config.InitRedis(cfg)
 	if err != nil {
 		log.Printf("Warning: Redis connection failed: %v", err)
-		log.Println("Continuing without Redis - caching will be disabled")
+		log.Println("Continuing without Redis - falling back to in-memory cache")
 		log.Println("This is expected if Redis is not configured")
-		// Create a nil Redis client - handlers should check for nil
+		// Leave the Redis client nil - the DB client falls back to an in-memory cache
 		redisClient = nil
 	}
 	defer func() {
@@ -56,31 +86,68 @@ func main() {
 
 	// Create database client wrapper
 	dbClient := database.NewDBClient(mongoClient, cfg.DatabaseName, redisClient)
+	// Catalog and content entries can also be kept in this instance's memory;
+	// changes are broadcast over Redis so every instance drops its copies
+	if redisClient != nil && cfg.CacheLocalTTLSeconds > 0 {
+		dbClient.Cache = database.NewNearCache(redisClient, time.Duration(cfg.CacheLocalTTLSeconds)*time.Second)
+	}
+	log.Printf("Using %s cache backend, %s job locks", dbClient.Cache.Name(), dbClient.Locks.Name())
+
+	// File storage for uploads; the API still starts if it is unreachable, only uploads fail
+	store, err := storage.New(context.Background(), cfg)
+	if err != nil {
+		log.Printf("Warning: %s storage unavailable, uploads will fail: %v", cfg.StorageProvider, err)
+		store = storage.Unavailable(err)
+	}
+
+	// Job queue shared by handlers (producers) and the worker pool
+	queue := jobs.NewQueue(jobs.NewBroker(redisClient), jobs.Options{MaxAttempts: cfg.JobMaxAttempts})
+
+	// Lifecycle events published by handlers and jobs; webhook delivery subscribes in jobs.Start
+	bus := events.NewBus()
+
+	// Admin dashboards receive new orders, payments and support messages live, via Redis pub/sub when available
+	hub := realtime.NewHub(redisClient)
+	bus.Subscribe(hub.Forward)
+
+	// Customers get an in-app notification when their order changes status or
+	// staff reply to their support ticket
+	bus.Subscribe(notify.OrderUpdates(dbClient))
+	bus.Subscribe(notify.SupportReplies(dbClient))
+
+	// Background jobs (low-stock alerts, queue workers) stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobs.Start(jobsCtx, dbClient, cfg, queue, store, bus)
+	go hub.Run(jobsCtx)
+	go dbClient.SubscribeInvalidations(jobsCtx)
+
+	// Home content and popular listings are rebuilt after every change and
+	// ahead of their cache TTLs, so visitors never wait on a cold cache
+	var warmer *handlers.CacheWarmer
+	if cfg.CacheWarmIntervalMinutes > 0 {
+		warmer = handlers.NewCacheWarmer(dbClient, cfg)
+		dbClient.Invalidated = warmer.Trigger
+		go warmer.Run(jobsCtx, time.Duration(cfg.CacheWarmIntervalMinutes)*time.Minute)
+	}
 
 	// Initialize Fiber app with custom error handling
 	app := fiber.New(fiber.Config{
 		AppName:      "Makwatches API",
 		ErrorHandler: customErrorHandler,
-		BodyLimit:    10 * 1024 * 1024, // 10MB
-
+		ProxyHeader:  cfg.ProxyHeader, // client IP header set by nginx, e.g. X-Real-IP
+		// Bodies up to MAX_BODY_KB are read into memory; larger ones (uploads)
+		// are streamed. The per-route caps are enforced by middleware.BodyLimits.
+		BodyLimit:                    cfg.MaxBodyKB * 1024,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
-	// Configure CORS for production and development
-	// Allow list is CSV; default includes production domains and Vercel preview, plus common local dev ports
-	prodOrigins := cfg.GetEnvOrDefault("ALLOWED_ORIGINS", "https://makwatches.in,https://www.makwatches.in,https://mak-watches.vercel.app")
-	devOrigins := cfg.GetEnvOrDefault("DEV_ORIGINS", "http://localhost:4200,http://localhost:3000")
-	allOrigins := prodOrigins + "," + devOrigins
-
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     allOrigins,
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With",
-		AllowCredentials: true,
-		ExposeHeaders:    "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers",
-	}))
+	// Browsers may call the API from ALLOWED_ORIGINS only
+	app.Use(middleware.CORS(cfg.AllowedOrigins))
 
 	// Setup all routes and middleware
-	handlers.SetupRoutes(app, dbClient, cfg)
+	handlers.SetupRoutes(app, dbClient, cfg, queue, store, bus, hub, warmer)
 
 	// Start the server in a goroutine
 	go func() {
@@ -97,6 +164,9 @@ func main() {
 
 	log.Println("Shutting down server...")
 
+	// End live event streams so they don't hold the shutdown open
+	hub.Close()
+
 	// Give the server 5 seconds to finish ongoing requests
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -108,20 +178,9 @@ func main() {
 	log.Println("Server exiting")
 }
 
-// customErrorHandler provides consistent error responses
+// customErrorHandler provides consistent error responses. Typed errors from
+// the apperrors package keep their status and code; anything else becomes a
+// generic 500 whose cause is logged rather than returned to the client.
 func customErrorHandler(c *fiber.Ctx, err error) error {
-	// Default status code is 500
-	code := fiber.StatusInternalServerError
-
-	// Check if it's a Fiber error
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
-	}
-
-	// Return JSON response with error details
-	return c.Status(code).JSON(fiber.Map{
-		"success": false,
-		"message": "An error occurred",
-		"error":   err.Error(),
-	})
+	return apperrors.Handler(c, err)
 }
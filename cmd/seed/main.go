@@ -0,0 +1,88 @@
+// Command seed fills the configured database with demo data for local
+// development and staging. It is safe to run repeatedly: documents that
+// already exist are left alone.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/migrations"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/seed"
+)
+
+func main() {
+	adminEmail := flag.String("admin-email", envOr("SEED_ADMIN_EMAIL", "admin@example.com"), "email of the admin user to create")
+	force := flag.Bool("force", false, "seed even when ENVIRONMENT is production")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load configuration: ", err)
+	}
+	if cfg.Environment == "production" && !*force {
+		log.Fatal("Refusing to seed a production database; pass -force if you mean it")
+	}
+
+	mongoClient, _, err := config.InitMongoDB(cfg)
+	if err != nil {
+		log.Fatalf("MongoDB connection error: %v", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// The unique indexes the seed relies on come from the migrations
+	if err := migrations.Run(ctx, mongoClient.Database(cfg.DatabaseName)); err != nil {
+		log.Fatalf("Migrations failed: %v", err)
+	}
+
+	// Redis, when there is one, is only needed to drop the API's cached
+	// product listings
+	redisClient, err := config.InitRedis(cfg)
+	if err != nil {
+		log.Printf("Continuing without Redis: %v", err)
+		redisClient = nil
+	}
+	db := database.NewDBClient(mongoClient, cfg.DatabaseName, redisClient)
+	report, err := seed.Run(ctx, db, seed.Options{
+		AdminEmail:    *adminEmail,
+		AdminPassword: os.Getenv("SEED_ADMIN_PASSWORD"),
+	})
+	collections := make([]string, 0, len(report.Inserted))
+	for name := range report.Inserted {
+		collections = append(collections, name)
+	}
+	sort.Strings(collections)
+	for _, name := range collections {
+		log.Printf("Inserted %d into %s", report.Inserted[name], name)
+	}
+	if err != nil {
+		log.Fatalf("Seeding failed: %v", err)
+	}
+	if len(collections) == 0 {
+		log.Println("Nothing to seed; the database already has the demo data")
+	}
+	if report.AdminPassword != "" {
+		log.Printf("Created admin %s with the generated password %s", *adminEmail, report.AdminPassword)
+	}
+	if report.Inserted[db.Collections().Products.Name()] > 0 {
+		db.InvalidateProductCaches(ctx)
+	}
+	log.Println("Seeding complete; cached home content refreshes within 5 minutes")
+}
+
+// envOr returns the environment variable key, or fallback when it is unset
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
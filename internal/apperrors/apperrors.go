@@ -0,0 +1,197 @@
+// Package apperrors defines the typed errors handlers return instead of
+// writing error responses inline. Every error carries an HTTP status and a
+// stable machine-readable code; Handler renders them in the standard
+// response envelope and keeps internal causes out of client responses.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error codes returned in the "code" field of every error response.
+// Clients should branch on these rather than on the message text.
+const (
+	CodeBadRequest      = "bad_request"
+	CodeValidation      = "validation_failed"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodePayloadTooLarge = "payload_too_large"
+	CodeRateLimited     = "rate_limited"
+	CodeUnavailable     = "service_unavailable"
+	CodeBadGateway      = "bad_gateway"
+	CodeInternal        = "internal_error"
+
+	// Specific codes for cases clients handle differently from the generic ones
+	CodeSessionRevoked   = "session_revoked"
+	CodeAccountSuspended = "account_suspended"
+	CodeAccountLocked    = "account_locked"
+	CodeEmailTaken       = "email_taken"
+	CodePhoneTaken       = "phone_taken"
+	CodeOTPInvalid       = "otp_invalid"
+	CodeEmailUnverified  = "email_unverified"
+	CodePriceChanged     = "price_changed"
+	CodePurchaseLimit    = "purchase_limit"
+	// A mutation attempted with an admin's read-only impersonation token
+	CodeImpersonationReadOnly = "impersonation_read_only"
+)
+
+// Error is an error that knows how it should be presented to the client
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Detail is a client-safe explanation, rendered as "error"
+	Detail string
+	// Fields lists per-field problems, rendered as "errors"
+	Fields interface{}
+	// Err is the underlying cause. It is logged for 5xx errors but never sent.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithCode returns a copy of e using a more specific error code
+func (e *Error) WithCode(code string) *Error {
+	clone := *e
+	clone.Code = code
+	return &clone
+}
+
+// New creates an error with an explicit status and code
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest reports malformed input such as an unparsable body or ID.
+// The cause, when given, is considered safe to show to the client.
+func BadRequest(message string, cause error) *Error {
+	e := &Error{Status: fiber.StatusBadRequest, Code: CodeBadRequest, Message: message, Err: cause}
+	if cause != nil {
+		e.Detail = cause.Error()
+	}
+	return e
+}
+
+// Validation reports well-formed input that breaks one or more field rules
+func Validation(message string, fields interface{}) *Error {
+	return &Error{Status: fiber.StatusUnprocessableEntity, Code: CodeValidation, Message: message, Fields: fields}
+}
+
+// Unauthorized reports a missing or invalid credential
+func Unauthorized(message string) *Error {
+	return &Error{Status: fiber.StatusUnauthorized, Code: CodeUnauthorized, Message: message}
+}
+
+// Forbidden reports an authenticated caller without access to the resource
+func Forbidden(message string) *Error {
+	return &Error{Status: fiber.StatusForbidden, Code: CodeForbidden, Message: message}
+}
+
+// NotFound reports a resource that doesn't exist
+func NotFound(message string) *Error {
+	return &Error{Status: fiber.StatusNotFound, Code: CodeNotFound, Message: message}
+}
+
+// Conflict reports a request that clashes with the current state, e.g. a duplicate
+func Conflict(message string) *Error {
+	return &Error{Status: fiber.StatusConflict, Code: CodeConflict, Message: message}
+}
+
+// PayloadTooLarge reports a request body or uploaded file over its size
+// limit. detail names the limit so clients can tell users what to change.
+func PayloadTooLarge(message, detail string) *Error {
+	return &Error{Status: fiber.StatusRequestEntityTooLarge, Code: CodePayloadTooLarge, Message: message, Detail: detail}
+}
+
+// Unavailable reports a dependency that isn't configured or reachable
+func Unavailable(message string, cause error) *Error {
+	return &Error{Status: fiber.StatusServiceUnavailable, Code: CodeUnavailable, Message: message, Err: cause}
+}
+
+// BadGateway reports a failed call to an upstream service
+func BadGateway(message string, cause error) *Error {
+	return &Error{Status: fiber.StatusBadGateway, Code: CodeBadGateway, Message: message, Err: cause}
+}
+
+// Internal reports an unexpected server-side failure. The cause is logged
+// but only the message reaches the client.
+func Internal(message string, cause error) *Error {
+	return &Error{Status: fiber.StatusInternalServerError, Code: CodeInternal, Message: message, Err: cause}
+}
+
+// codeForStatus maps plain fiber errors (unknown routes, oversized bodies,
+// rate limits) onto the catalogue
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound, fiber.StatusMethodNotAllowed:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnprocessableEntity:
+		return CodeValidation
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusServiceUnavailable:
+		return CodeUnavailable
+	case fiber.StatusBadGateway:
+		return CodeBadGateway
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Handler renders err in the standard envelope. It is meant to back the
+// Fiber ErrorHandler so handlers can simply return an *Error.
+func Handler(c *fiber.Ctx, err error) error {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			appErr = New(fe.Code, codeForStatus(fe.Code), fe.Message)
+		} else {
+			appErr = Internal("An error occurred", err)
+		}
+	}
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		log.Printf("[ERROR] %s %s: %v", c.Method(), c.Path(), appErr)
+	}
+
+	body := fiber.Map{
+		"success": false,
+		"message": appErr.Message,
+		"code":    appErr.Code,
+	}
+	if appErr.Detail != "" {
+		body["error"] = appErr.Detail
+	}
+	if appErr.Fields != nil {
+		body["errors"] = appErr.Fields
+	}
+	return c.Status(appErr.Status).JSON(body)
+}
@@ -0,0 +1,235 @@
+// Package catalogread maintains catalog_read, a denormalized copy of the
+// products collection shaped for storefront listings: each document carries
+// the product's effective price (after any active discount), its rating and
+// its filterable attributes, so listings filter and sort on stored fields
+// instead of evaluating discounts per request.
+//
+// Watch keeps the copy in step with a change stream on products. Change
+// streams need a replica set; without one Ready stays false and listings
+// read the products collection as before.
+package catalogread
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// ErrUnsupported means the deployment has no change streams (a standalone
+// server)
+var ErrUnsupported = errors.New("change streams are not supported by this MongoDB deployment")
+
+// changeStreamUnsupported is the server error for $changeStream outside a
+// replica set or sharded cluster
+const changeStreamUnsupported = 40573
+
+// ready is set while this instance's copy is rebuilt and followed
+var ready atomic.Bool
+
+// Ready reports whether catalog_read is current and may serve listings
+func Ready() bool {
+	return ready.Load()
+}
+
+// copied are the product fields listings decode. They are copied as
+// stored, unset ones staying unset, so a listing read from catalog_read
+// responds exactly as one read from products.
+var copied = []string{
+	"name", "slug", "price", "images", "category", "main_category", "subcategory", "brand", "stock",
+	"translations", "seo", "description", "created_at",
+	"avg_rating", "ratings_count", "popularity",
+	"max_per_order", "max_per_customer", "release_date", "allow_preorder",
+	"discount_percentage", "discount_amount", "discount_start_date", "discount_end_date",
+}
+
+// document builds the catalog_read document of a stored product as of now
+func document(raw bson.Raw, now time.Time) (bson.M, error) {
+	var p models.Product
+	if err := bson.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+
+	doc := bson.M{
+		"_id":             p.ID,
+		"effective_price": p.GetFinalPrice(),
+		"discount_active": p.IsDiscountActive(),
+		"synced_at":       now,
+	}
+	for _, field := range copied {
+		if value, err := raw.LookupErr(field); err == nil {
+			doc[field] = value
+		}
+	}
+	for _, field := range models.ProductAttributeFields {
+		if value, ok := raw.Lookup(field).StringValueOK(); ok && value != "" {
+			doc[field] = value
+		}
+	}
+	// The effective price changes when a discount starts or ends, without
+	// the product being written; Refresh recomputes it then
+	var validUntil *time.Time
+	if p.DiscountPercentage != nil || p.DiscountAmount != nil {
+		if p.DiscountStartDate != nil && p.DiscountStartDate.After(now) {
+			validUntil = p.DiscountStartDate
+		} else if p.DiscountEndDate != nil && p.DiscountEndDate.After(now) {
+			validUntil = p.DiscountEndDate
+		}
+	}
+	if validUntil != nil {
+		doc["price_valid_until"] = *validUntil
+	}
+	return doc, nil
+}
+
+// Sync rewrites the catalog_read documents of the products, removing those
+// of products that no longer exist
+func Sync(ctx context.Context, db *database.DBClient, ids ...primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	cursor, err := db.Collections().Products.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return fmt.Errorf("find products: %w", err)
+	}
+	var products []bson.Raw
+	if err := cursor.All(ctx, &products); err != nil {
+		return fmt.Errorf("decode products: %w", err)
+	}
+
+	now := time.Now()
+	found := make(map[primitive.ObjectID]bool, len(products))
+	writes := make([]mongo.WriteModel, 0, len(ids))
+	for _, raw := range products {
+		doc, err := document(raw, now)
+		if err != nil {
+			return fmt.Errorf("decode product: %w", err)
+		}
+		id := doc["_id"].(primitive.ObjectID)
+		found[id] = true
+		writes = append(writes, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true))
+	}
+	for _, id := range ids {
+		if !found[id] {
+			writes = append(writes, mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": id}))
+		}
+	}
+	if _, err := db.Collections().CatalogRead.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("write catalog_read: %w", err)
+	}
+	return nil
+}
+
+// rebuildBatch is how many products Rebuild writes at a time
+const rebuildBatch = 500
+
+// Rebuild rewrites catalog_read from every product and removes documents of
+// deleted products. It repairs any drift, e.g. from events missed while no
+// instance was watching.
+func Rebuild(ctx context.Context, db *database.DBClient) error {
+	started := time.Now()
+	cursor, err := db.Collections().Products.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("find products: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]primitive.ObjectID, 0, rebuildBatch)
+	for cursor.Next(ctx) {
+		var p struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&p); err != nil {
+			return fmt.Errorf("decode product: %w", err)
+		}
+		if batch = append(batch, p.ID); len(batch) == rebuildBatch {
+			if err := Sync(ctx, db, batch...); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("find products: %w", err)
+	}
+	if err := Sync(ctx, db, batch...); err != nil {
+		return err
+	}
+
+	// Every product still present was synced since started
+	if _, err := db.Collections().CatalogRead.DeleteMany(ctx, bson.M{"synced_at": bson.M{"$lt": started}}); err != nil {
+		return fmt.Errorf("remove deleted products: %w", err)
+	}
+	return nil
+}
+
+// Refresh recomputes the documents whose discount started or ended since
+// they were written
+func Refresh(ctx context.Context, db *database.DBClient) error {
+	ids, err := db.Collections().CatalogRead.Distinct(ctx, "_id", bson.M{"price_valid_until": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		return fmt.Errorf("find expired prices: %w", err)
+	}
+	stale := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			stale = append(stale, oid)
+		}
+	}
+	return Sync(ctx, db, stale...)
+}
+
+// Watch rebuilds catalog_read and then follows the change stream on
+// products, syncing each changed product, until ctx is cancelled or the
+// stream fails. Ready is true from the end of the rebuild until Watch
+// returns. It returns ErrUnsupported on a standalone server.
+func Watch(ctx context.Context, db *database.DBClient) error {
+	// Opened before the rebuild so no change made during it is missed
+	stream, err := db.Collections().Products.Watch(ctx, mongo.Pipeline{})
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Code == changeStreamUnsupported {
+			return ErrUnsupported
+		}
+		return fmt.Errorf("watch products: %w", err)
+	}
+	defer stream.Close(context.Background())
+
+	if err := Rebuild(ctx, db); err != nil {
+		return err
+	}
+	ready.Store(true)
+	defer ready.Store(false)
+
+	for stream.Next(ctx) {
+		var change struct {
+			DocumentKey struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return fmt.Errorf("decode change: %w", err)
+		}
+		if change.DocumentKey.ID.IsZero() {
+			continue // e.g. the collection was dropped
+		}
+		// The product is read again rather than taken from the event, so
+		// the copy converges on the current state whatever the order
+		if err := Sync(ctx, db, change.DocumentKey.ID); err != nil {
+			return err
+		}
+	}
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("watch products: %w", err)
+	}
+	return nil
+}
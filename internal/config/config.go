@@ -2,9 +2,11 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -12,6 +14,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
 
 // Config holds the application configuration
@@ -25,10 +30,34 @@ type Config struct {
 	JWTSecret          string
 	JWTExpirationHours int
 	RedisDatabase      int
-	// Razorpay settings
-	RazorpayKey           string
-	RazorpaySecret        string
-	RazorpayWebhookSecret string
+	// Tokens are signed with JWTAlgorithm: HS256 with JWTSecret, or RS256 or
+	// EdDSA with JWTPrivateKey (PEM or a path to it). After a rotation the
+	// previous secret or public key keeps verifying the tokens it signed.
+	// JWTKeys is the resulting key set; see package jwtkeys.
+	JWTAlgorithm         string
+	JWTPrivateKey        string
+	JWTKeyID             string
+	JWTPreviousSecret    string
+	JWTPreviousPublicKey string
+	JWTPreviousKeyID     string
+	JWTKeys              *jwtkeys.Set
+	// Razorpay settings. The RAZORPAY_* keys are the live account's and the
+	// RAZORPAY_TEST_* keys the test account's; RazorpayMode is the mode
+	// checkouts use until the store settings pick one.
+	RazorpayKey               string
+	RazorpaySecret            string
+	RazorpayWebhookSecret     string
+	RazorpayMode              string
+	RazorpayTestKey           string
+	RazorpayTestSecret        string
+	RazorpayTestWebhookSecret string
+	// Orders placed before paying are checked with Razorpay once they have
+	// waited PaymentTimeoutMinutes; an interval of 0 disables the check.
+	// Those still unpaid after UnpaidOrderTTLMinutes are cancelled and
+	// restocked; 0 never cancels them.
+	PaymentReconcileIntervalMinutes int
+	PaymentTimeoutMinutes           int
+	UnpaidOrderTTLMinutes           int
 	// AWS S3 settings
 	AWSS3AccessKey  string
 	AWSS3SecretKey  string
@@ -41,12 +70,148 @@ type Config struct {
 	// Firebase settings
 	FirebaseCredentialsPath string
 	FirebaseBucketName      string
+	// StorageProvider selects where uploads are stored: "firebase", "s3" or "local"
+	StorageProvider string
+	// PublicBaseURL is the API's public origin, used for locally stored file URLs
+	PublicBaseURL string
+	// StorageSignedURLTTLMinutes hands out stored file URLs signed for this
+	// long, for private buckets; 0 hands out the public URLs
+	StorageSignedURLTTLMinutes int
+	// StorageCDNURL hands out stored file URLs on this CDN origin instead
+	StorageCDNURL string
+	// EnableLegacyRoutes keeps the unversioned root paths mounted as deprecated aliases of /api/v1
+	EnableLegacyRoutes bool
+	// SMS settings for phone OTP login ("msg91", "twilio" or "log")
+	SMSProvider      string
+	MSG91AuthKey     string
+	MSG91TemplateID  string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFromNumber string
+	// Order updates by WhatsApp and SMS, each switched on separately. Customers
+	// opt in per channel in their preferences; channels without credentials
+	// only log their messages.
+	WhatsAppOrderUpdates     bool
+	WhatsAppPhoneNumberID    string
+	WhatsAppAccessToken      string
+	WhatsAppTemplateLanguage string
+	SMSOrderUpdates          bool
+	// MSG91 DLT template IDs per order update; MSG91_AUTH_KEY is shared with OTPs
+	MSG91OrderConfirmedTemplateID string
+	MSG91OrderShippedTemplateID   string
+	MSG91OrderDeliveredTemplateID string
+	// Email settings (SMTP); without SMTP_HOST emails are only logged
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	// FrontendURL is the storefront origin used in links and redirects
+	FrontendURL string
+	// Content locales. Home content and products are written in DefaultLocale;
+	// Locales (LOCALES, comma-separated) are those shoppers may ask for and
+	// translations may be added in. DefaultLocale is always one of them.
+	DefaultLocale string
+	Locales       []string
+	// AllowedOrigins may call the API from a browser (ALLOWED_ORIGINS,
+	// comma-separated). "https://*.example.com" allows every subdomain.
+	AllowedOrigins []string
+	// ProxyHeader names the header carrying the client IP when the API sits
+	// behind a reverse proxy (e.g. "X-Real-IP"); empty uses the socket address
+	ProxyHeader string
+	// Request body caps: JSON and other non-file bodies at MaxBodyKB, file
+	// uploads at MaxUploadMB (upload routes may set lower caps of their own)
+	MaxBodyKB   int
+	MaxUploadMB int
+	// RequireVerifiedEmail blocks checkout until the account's email is verified
+	RequireVerifiedEmail bool
+	// Password login lockout: an email is locked for LoginLockoutMinutes after
+	// LoginMaxFailures consecutive failed attempts; 0 failures disables it
+	LoginMaxFailures    int
+	LoginLockoutMinutes int
+	// Inventory alerts; a check interval of 0 disables the background job
+	LowStockThreshold            int
+	LowStockCheckIntervalMinutes int
+	AdminAlertEmails             string // Comma-separated; defaults to every admin account
+	// Most units of one product a single order may hold; 0 leaves only stock
+	// as the limit
+	MaxQuantityPerOrder int
+	// Sales digests go out at ReportHour in ReportTimezone once the period is
+	// over, to the recipients in the store settings. The check interval is
+	// how often the job looks; 0 disables it.
+	ReportCheckIntervalMinutes int
+	ReportHour                 int
+	ReportTimezone             string
+	// How often co-purchase recommendation scores are rebuilt; 0 disables
+	RecommendationRebuildIntervalMinutes int
+	// How often discount campaigns are started and ended; 0 disables
+	CampaignCheckIntervalMinutes int
+	// Cash-on-delivery verification ("off", "otp" or "email"). Unverified
+	// orders are cancelled after the TTL by a job on the check interval.
+	CODVerification                     string
+	CODVerificationTTLMinutes           int
+	CODVerificationCheckIntervalMinutes int
+	// Days after delivery a customer may request a return; 0 removes the limit
+	ReturnWindowDays int
+	// Carrier volumetric divisor in cm³ per kg: parcels are billed by the
+	// greater of their weight and their volume divided by this
+	ShippingVolumetricDivisor int
+	// Pincodes missing from the pincodes collection are looked up at this API
+	// (empty disables it). ValidatePincodes rejects Indian addresses whose
+	// pincode is unknown or lies in another state.
+	PincodeAPIURL    string
+	ValidatePincodes bool
+	// How often product ratings are recomputed from reviews to repair drift; 0 disables
+	RatingReconcileIntervalHours int
+	// Product views are counted in the cache and flushed to products this
+	// often (0 stops counting them); popularity is the views over the last
+	// PopularityWindowDays days
+	ProductViewFlushIntervalMinutes int
+	PopularityWindowDays            int
+	// Storefront listings read the catalog_read collection, kept in sync with
+	// products by a change stream (replica sets only; elsewhere listings read
+	// products directly). It is rebuilt in full every reconcile interval.
+	CatalogReadModel                    bool
+	CatalogReadReconcileIntervalMinutes int
+	// Orphaned file cleanup; an interval of 0 disables it (the admin dry run still works)
+	ImageCleanupIntervalHours int
+	ImageCleanupMinAgeDays    int
+	// Data retention: every RetentionIntervalHours (0 disables it) documents
+	// older than their collection's retention are deleted; 0 days keeps them
+	RetentionIntervalHours              int
+	RecommendationFeedbackRetentionDays int
+	CartItemRetentionDays               int
+	NotificationRetentionDays           int
+	// Exchange-rate feed for currencies marked autoUpdate; no URL or an
+	// interval of 0 disables it and rates are then managed by admins only
+	ExchangeRateURL           string
+	ExchangeRateIntervalHours int
+	// Cache warming rebuilds home content and the CacheWarmQueries most
+	// requested first pages of /products after every change and on the
+	// interval, ahead of visitors; an interval of 0 disables it
+	CacheWarmIntervalMinutes int
+	CacheWarmQueries         int
+	// Seconds each instance keeps its own copy of catalog and content cache
+	// entries in front of Redis; writes reach every instance's copies over
+	// Redis pub/sub. 0 reads every entry from Redis.
+	CacheLocalTTLSeconds int
+	// Captcha on the contact form: the secret of a reCAPTCHA, hCaptcha or
+	// Turnstile site and the provider's siteverify URL. Without a secret the
+	// form relies on its honeypot field and rate limit alone.
+	CaptchaSecret    string
+	CaptchaVerifyURL string
+	// Background job queue; 0 workers disables processing on this instance
+	JobWorkers     int
+	JobMaxAttempts int
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists
 	godotenv.Load()
+	if err := loadSecretFiles(); err != nil {
+		return nil, fmt.Errorf("secret files: %w", err)
+	}
 
 	// Set defaults and override with environment variables if they exist
 	cfg := &Config{
@@ -56,9 +221,16 @@ func LoadConfig() (*Config, error) {
 		DatabaseName:       getEnv("DATABASE_NAME", "makwatches"),
 		RedisURI:           getEnv("REDIS_URI", "localhost:6379"),
 		RedisPassword:      getEnv("REDIS_PASSWORD", ""),
-		JWTSecret:          getEnv("JWT_SECRET", "your_jwt_secret_key_here"),
+		JWTSecret:          getEnv("JWT_SECRET", defaultJWTSecret),
 		JWTExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
 		RedisDatabase:      getEnvAsInt("REDIS_DATABASE", 0),
+		// JWT signing keys
+		JWTAlgorithm:         getEnv("JWT_ALGORITHM", jwtkeys.HS256),
+		JWTPrivateKey:        getEnv("JWT_PRIVATE_KEY", ""),
+		JWTKeyID:             getEnv("JWT_KEY_ID", ""),
+		JWTPreviousSecret:    getEnv("JWT_PREVIOUS_SECRET", ""),
+		JWTPreviousPublicKey: getEnv("JWT_PREVIOUS_PUBLIC_KEY", ""),
+		JWTPreviousKeyID:     getEnv("JWT_PREVIOUS_KEY_ID", ""),
 		// Razorpay config (support both KEY/SECRET and KEY_ID/KEY_SECRET naming)
 		RazorpayKey: func() string {
 			v := getEnv("RAZORPAY_KEY", "")
@@ -74,7 +246,14 @@ func LoadConfig() (*Config, error) {
 			}
 			return getEnv("RAZORPAY_KEY_SECRET", "")
 		}(),
-		RazorpayWebhookSecret: getEnv("RAZORPAY_WEBHOOK_SECRET", ""),
+		RazorpayWebhookSecret:           getEnv("RAZORPAY_WEBHOOK_SECRET", ""),
+		RazorpayMode:                    getEnv("RAZORPAY_MODE", models.PaymentModeLive),
+		RazorpayTestKey:                 getEnv("RAZORPAY_TEST_KEY", ""),
+		RazorpayTestSecret:              getEnv("RAZORPAY_TEST_SECRET", ""),
+		RazorpayTestWebhookSecret:       getEnv("RAZORPAY_TEST_WEBHOOK_SECRET", ""),
+		PaymentReconcileIntervalMinutes: getEnvAsInt("PAYMENT_RECONCILE_INTERVAL_MINUTES", 10),
+		PaymentTimeoutMinutes:           getEnvAsInt("PAYMENT_TIMEOUT_MINUTES", 30),
+		UnpaidOrderTTLMinutes:           getEnvAsInt("UNPAID_ORDER_TTL_MINUTES", 60),
 		// AWS S3 config
 		AWSS3AccessKey:  getEnv("AWS_S3_ACCESS_KEY", ""),
 		AWSS3SecretKey:  getEnv("AWS_S3_SECRET_KEY", ""),
@@ -87,11 +266,174 @@ func LoadConfig() (*Config, error) {
 		// Firebase config
 		FirebaseCredentialsPath: getEnv("FIREBASE_CREDENTIALS_PATH", "firebase-admin.json"),
 		FirebaseBucketName:      getEnv("FIREBASE_BUCKET_NAME", "mak-watches.firebasestorage.app"),
+		// File storage
+		StorageProvider: getEnv("STORAGE_PROVIDER", "firebase"),
+		PublicBaseURL:   getEnv("PUBLIC_BASE_URL", ""),
+		// File delivery
+		StorageSignedURLTTLMinutes: getEnvAsInt("STORAGE_SIGNED_URL_TTL_MINUTES", 0),
+		StorageCDNURL:              getEnv("STORAGE_CDN_URL", ""),
+		// API versioning
+		EnableLegacyRoutes: getEnvAsBool("ENABLE_LEGACY_ROUTES", true),
+		// SMS config
+		SMSProvider:      getEnv("SMS_PROVIDER", "log"),
+		MSG91AuthKey:     getEnv("MSG91_AUTH_KEY", ""),
+		MSG91TemplateID:  getEnv("MSG91_TEMPLATE_ID", ""),
+		TwilioAccountSID: getEnv("TWILIO_ACCOUNT_SID", ""),
+		TwilioAuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
+		TwilioFromNumber: getEnv("TWILIO_FROM_NUMBER", ""),
+		// Order update messaging
+		WhatsAppOrderUpdates:          getEnvAsBool("WHATSAPP_ORDER_UPDATES", false),
+		WhatsAppPhoneNumberID:         getEnv("WHATSAPP_PHONE_NUMBER_ID", ""),
+		WhatsAppAccessToken:           getEnv("WHATSAPP_ACCESS_TOKEN", ""),
+		WhatsAppTemplateLanguage:      getEnv("WHATSAPP_TEMPLATE_LANGUAGE", "en"),
+		SMSOrderUpdates:               getEnvAsBool("SMS_ORDER_UPDATES", false),
+		MSG91OrderConfirmedTemplateID: getEnv("MSG91_ORDER_CONFIRMED_TEMPLATE_ID", ""),
+		MSG91OrderShippedTemplateID:   getEnv("MSG91_ORDER_SHIPPED_TEMPLATE_ID", ""),
+		MSG91OrderDeliveredTemplateID: getEnv("MSG91_ORDER_DELIVERED_TEMPLATE_ID", ""),
+		// Email config
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "MAK Watches <no-reply@makwatches.in>"),
+		FrontendURL:  getEnv("FRONTEND_URL", ""),
+		// CORS
+		AllowedOrigins: getEnvAsList("ALLOWED_ORIGINS"),
+		ProxyHeader:    getEnv("PROXY_HEADER", ""),
+		// Content locales
+		DefaultLocale: strings.ToLower(getEnv("DEFAULT_LOCALE", "en")),
+		Locales:       getEnvAsList("LOCALES"),
+		// Request body limits
+		MaxBodyKB:   getEnvAsInt("MAX_BODY_KB", 1024),
+		MaxUploadMB: getEnvAsInt("MAX_UPLOAD_MB", 60),
+		// Account verification
+		RequireVerifiedEmail: getEnvAsBool("REQUIRE_VERIFIED_EMAIL", false),
+		// Login lockout
+		LoginMaxFailures:    getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+		LoginLockoutMinutes: getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15),
+		// Inventory alerts
+		LowStockThreshold:            getEnvAsInt("LOW_STOCK_THRESHOLD", 5),
+		LowStockCheckIntervalMinutes: getEnvAsInt("LOW_STOCK_CHECK_INTERVAL_MINUTES", 15),
+		AdminAlertEmails:             getEnv("ADMIN_ALERT_EMAILS", ""),
+		MaxQuantityPerOrder:          getEnvAsInt("MAX_QUANTITY_PER_ORDER", 0),
+		// Sales digests
+		ReportCheckIntervalMinutes: getEnvAsInt("REPORT_CHECK_INTERVAL_MINUTES", 15),
+		ReportHour:                 getEnvAsInt("REPORT_HOUR", 8),
+		ReportTimezone:             getEnv("REPORT_TIMEZONE", "Asia/Kolkata"),
+		// Recommendations
+		RecommendationRebuildIntervalMinutes: getEnvAsInt("RECOMMENDATION_REBUILD_INTERVAL_MINUTES", 360),
+		// Discount campaigns
+		CampaignCheckIntervalMinutes: getEnvAsInt("CAMPAIGN_CHECK_INTERVAL_MINUTES", 1),
+		// COD verification
+		CODVerification:                     getEnv("COD_VERIFICATION", "off"),
+		CODVerificationTTLMinutes:           getEnvAsInt("COD_VERIFICATION_TTL_MINUTES", 60),
+		CODVerificationCheckIntervalMinutes: getEnvAsInt("COD_VERIFICATION_CHECK_INTERVAL_MINUTES", 5),
+		// Returns
+		ReturnWindowDays: getEnvAsInt("RETURN_WINDOW_DAYS", 7),
+		// Shipping
+		ShippingVolumetricDivisor: getEnvAsInt("SHIPPING_VOLUMETRIC_DIVISOR", 5000),
+		// Addresses
+		PincodeAPIURL:    getEnv("PINCODE_API_URL", "https://api.postalpincode.in/pincode/"),
+		ValidatePincodes: getEnvAsBool("VALIDATE_PINCODES", true),
+		// Product ratings
+		RatingReconcileIntervalHours: getEnvAsInt("RATING_RECONCILE_INTERVAL_HOURS", 24),
+		// Product popularity
+		ProductViewFlushIntervalMinutes: getEnvAsInt("PRODUCT_VIEW_FLUSH_INTERVAL_MINUTES", 5),
+		PopularityWindowDays:            getEnvAsInt("POPULARITY_WINDOW_DAYS", 7),
+		// Catalog read model
+		CatalogReadModel:                    getEnvAsBool("CATALOG_READ_MODEL", true),
+		CatalogReadReconcileIntervalMinutes: getEnvAsInt("CATALOG_READ_RECONCILE_INTERVAL_MINUTES", 60),
+		// Orphaned file cleanup
+		ImageCleanupIntervalHours: getEnvAsInt("IMAGE_CLEANUP_INTERVAL_HOURS", 0),
+		ImageCleanupMinAgeDays:    getEnvAsInt("IMAGE_CLEANUP_MIN_AGE_DAYS", 7),
+		// Data retention
+		RetentionIntervalHours:              getEnvAsInt("RETENTION_INTERVAL_HOURS", 0),
+		RecommendationFeedbackRetentionDays: getEnvAsInt("RETENTION_RECOMMENDATION_FEEDBACK_DAYS", 365),
+		CartItemRetentionDays:               getEnvAsInt("RETENTION_CART_ITEM_DAYS", 90),
+		NotificationRetentionDays:           getEnvAsInt("RETENTION_NOTIFICATION_DAYS", 180),
+		// Exchange rates
+		ExchangeRateURL:           getEnv("EXCHANGE_RATE_URL", ""),
+		ExchangeRateIntervalHours: getEnvAsInt("EXCHANGE_RATE_INTERVAL_HOURS", 12),
+		// Contact form captcha
+		CaptchaSecret:    getEnv("CAPTCHA_SECRET", ""),
+		CaptchaVerifyURL: getEnv("CAPTCHA_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify"),
+		// Cache warming
+		CacheWarmIntervalMinutes: getEnvAsInt("CACHE_WARM_INTERVAL_MINUTES", 4),
+		CacheWarmQueries:         getEnvAsInt("CACHE_WARM_QUERIES", 10),
+		CacheLocalTTLSeconds:     getEnvAsInt("CACHE_LOCAL_TTL_SECONDS", 0),
+		// Job queue
+		JobWorkers:     getEnvAsInt("JOB_WORKERS", 4),
+		JobMaxAttempts: getEnvAsInt("JOB_MAX_ATTEMPTS", 5),
+	}
+
+	if cfg.FrontendURL == "" {
+		cfg.FrontendURL = "http://localhost:3000"
+		if cfg.Environment == "production" {
+			cfg.FrontendURL = "https://makwatches.in"
+		}
+	}
+
+	if len(cfg.Locales) == 0 {
+		cfg.Locales = []string{"en", "hi"}
+	}
+	hasDefault := false
+	for i, locale := range cfg.Locales {
+		cfg.Locales[i] = strings.ToLower(locale)
+		hasDefault = hasDefault || cfg.Locales[i] == cfg.DefaultLocale
+	}
+	if !hasDefault {
+		cfg.Locales = append([]string{cfg.DefaultLocale}, cfg.Locales...)
+	}
+
+	keys, err := jwtkeys.Load(jwtkeys.Options{
+		Algorithm:         cfg.JWTAlgorithm,
+		Secret:            cfg.JWTSecret,
+		PrivateKey:        cfg.JWTPrivateKey,
+		KeyID:             cfg.JWTKeyID,
+		PreviousSecret:    cfg.JWTPreviousSecret,
+		PreviousPublicKey: cfg.JWTPreviousPublicKey,
+		PreviousKeyID:     cfg.JWTPreviousKeyID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JWT keys: %w", err)
+	}
+	cfg.JWTKeys = keys
+
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = []string{"https://makwatches.in", "https://www.makwatches.in", "https://mak-watches.vercel.app"}
+		if cfg.Environment != "production" {
+			cfg.AllowedOrigins = append(cfg.AllowedOrigins, "http://localhost:3000", "http://localhost:4200")
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// RazorpayKeys is the key pair and webhook secret of one Razorpay mode
+type RazorpayKeys struct {
+	Mode          string
+	Key           string
+	Secret        string
+	WebhookSecret string
+}
+
+// Configured reports whether both halves of the key pair are set
+func (k RazorpayKeys) Configured() bool {
+	return k.Key != "" && k.Secret != ""
+}
+
+// Razorpay returns the keys of a payment mode; anything but test is live
+func (c *Config) Razorpay(mode string) RazorpayKeys {
+	if mode == models.PaymentModeTest {
+		return RazorpayKeys{Mode: mode, Key: c.RazorpayTestKey, Secret: c.RazorpayTestSecret, WebhookSecret: c.RazorpayTestWebhookSecret}
+	}
+	return RazorpayKeys{Mode: models.PaymentModeLive, Key: c.RazorpayKey, Secret: c.RazorpaySecret, WebhookSecret: c.RazorpayWebhookSecret}
+}
+
 // InitMongoDB initializes the MongoDB client
 func InitMongoDB(config *Config) (*mongo.Client, *mongo.Database, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -103,7 +445,7 @@ func InitMongoDB(config *Config) (*mongo.Client, *mongo.Database, error) {
 		SetConnectTimeout(5 * time.Second).
 		SetServerSelectionTimeout(5 * time.Second)
 
-	log.Printf("Attempting to connect to MongoDB at %s...", config.MongoURI)
+	log.Printf("Attempting to connect to MongoDB at %s...", RedactURI(config.MongoURI))
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
@@ -179,6 +521,29 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvAsBool gets the environment variable as a boolean with fallback
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		result, err := strconv.ParseBool(value)
+		if err == nil {
+			return result
+		}
+	}
+	return fallback
+}
+
+// getEnvAsList splits a comma-separated environment variable, dropping
+// empty entries
+func getEnvAsList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 // GetEnvOrDefault returns the environment variable value or a fallback
 func (c *Config) GetEnvOrDefault(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
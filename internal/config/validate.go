@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// defaultJWTSecret is the placeholder JWT_SECRET falls back to
+const defaultJWTSecret = "your_jwt_secret_key_here"
+
+// minJWTSecretLength is the shortest HS256 secret accepted in production
+const minJWTSecretLength = 32
+
+// loadSecretFiles sets each variable NAME from the file named by NAME_FILE,
+// as Docker and Kubernetes secrets are mounted. Setting both is an error.
+func loadSecretFiles() error {
+	var errs []error
+	for _, entry := range os.Environ() {
+		name, path, _ := strings.Cut(entry, "=")
+		key, ok := strings.CutSuffix(name, "_FILE")
+		if !ok || key == "" || path == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(key); set {
+			errs = append(errs, fmt.Errorf("both %s and %s are set", key, name))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		// Files written by editors and echo end with a newline
+		os.Setenv(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that the secrets the API needs are set and aren't
+// placeholders. In production every problem is returned; elsewhere they are
+// only logged so local setups run without real credentials.
+func (c *Config) Validate() error {
+	var problems []string
+	require := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if c.JWTAlgorithm == jwtkeys.HS256 || c.JWTAlgorithm == "" {
+		require(c.JWTSecret != defaultJWTSecret, "JWT_SECRET is the example value")
+		require(len(c.JWTSecret) >= minJWTSecretLength, "JWT_SECRET must be at least %d characters", minJWTSecretLength)
+	}
+	// OTP and COD confirmation codes are signed with JWT_SECRET whatever the
+	// token algorithm
+	require(c.JWTSecret != "", "JWT_SECRET is not set")
+	require(c.JWTPreviousSecret != defaultJWTSecret, "JWT_PREVIOUS_SECRET is the example value")
+
+	require(c.RazorpayKey != "", "RAZORPAY_KEY is not set")
+	require(c.RazorpaySecret != "", "RAZORPAY_SECRET is not set")
+	require(c.RazorpayWebhookSecret != "", "RAZORPAY_WEBHOOK_SECRET is not set")
+	require(c.RazorpayMode == models.PaymentModeLive || c.RazorpayMode == models.PaymentModeTest, "RAZORPAY_MODE must be live or test")
+	if c.RazorpayMode == models.PaymentModeTest {
+		require(c.RazorpayTestKey != "" && c.RazorpayTestSecret != "", "RAZORPAY_TEST_KEY and RAZORPAY_TEST_SECRET are required with RAZORPAY_MODE=test")
+	}
+
+	if c.StorageProvider == "s3" {
+		require(c.AWSS3AccessKey != "" && c.AWSS3SecretKey != "", "AWS_S3_ACCESS_KEY and AWS_S3_SECRET_KEY are required with STORAGE_PROVIDER=s3")
+	}
+	if c.GoogleClientID != "" {
+		require(c.GoogleClientSecret != "", "GOOGLE_CLIENT_SECRET is required with GOOGLE_CLIENT_ID")
+	}
+	switch c.SMSProvider {
+	case "msg91":
+		require(c.MSG91AuthKey != "", "MSG91_AUTH_KEY is required with SMS_PROVIDER=msg91")
+	case "twilio":
+		require(c.TwilioAccountSID != "" && c.TwilioAuthToken != "", "TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN are required with SMS_PROVIDER=twilio")
+	}
+	if c.SMTPUsername != "" {
+		require(c.SMTPPassword != "", "SMTP_PASSWORD is required with SMTP_USERNAME")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if c.Environment != "production" {
+		for _, p := range problems {
+			log.Printf("Config warning: %s (required in production)", p)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid production configuration:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// LogSummary logs the settings the server starts with. Secrets are only
+// reported as set or not, and credentials are removed from URIs.
+func (c *Config) LogSummary() {
+	log.Println("Configuration:")
+	for _, line := range [][2]string{
+		{"ENVIRONMENT", c.Environment},
+		{"PORT", c.Port},
+		{"MONGO_URI", RedactURI(c.MongoURI)},
+		{"DATABASE_NAME", c.DatabaseName},
+		{"REDIS_URI", RedactURI(c.RedisURI)},
+		{"REDIS_PASSWORD", redact(c.RedisPassword)},
+		{"JWT_ALGORITHM", c.JWTKeys.Algorithm()},
+		{"JWT_SECRET", redact(c.JWTSecret)},
+		{"JWT_PRIVATE_KEY", redact(c.JWTPrivateKey)},
+		{"JWT_PREVIOUS_SECRET", redact(c.JWTPreviousSecret)},
+		{"JWT_PREVIOUS_PUBLIC_KEY", redact(c.JWTPreviousPublicKey)},
+		{"RAZORPAY_KEY", c.RazorpayKey},
+		{"RAZORPAY_SECRET", redact(c.RazorpaySecret)},
+		{"RAZORPAY_WEBHOOK_SECRET", redact(c.RazorpayWebhookSecret)},
+		{"RAZORPAY_MODE", c.RazorpayMode},
+		{"RAZORPAY_TEST_KEY", c.RazorpayTestKey},
+		{"RAZORPAY_TEST_SECRET", redact(c.RazorpayTestSecret)},
+		{"STORAGE_PROVIDER", c.StorageProvider},
+		{"AWS_S3_SECRET_KEY", redact(c.AWSS3SecretKey)},
+		{"GOOGLE_CLIENT_SECRET", redact(c.GoogleClientSecret)},
+		{"SMS_PROVIDER", c.SMSProvider},
+		{"MSG91_AUTH_KEY", redact(c.MSG91AuthKey)},
+		{"TWILIO_AUTH_TOKEN", redact(c.TwilioAuthToken)},
+		{"WHATSAPP_ACCESS_TOKEN", redact(c.WhatsAppAccessToken)},
+		{"SMTP_HOST", c.SMTPHost},
+		{"SMTP_PASSWORD", redact(c.SMTPPassword)},
+		{"FRONTEND_URL", c.FrontendURL},
+		{"ALLOWED_ORIGINS", strings.Join(c.AllowedOrigins, ",")},
+	} {
+		value := line[1]
+		if value == "" {
+			value = "(not set)"
+		}
+		log.Printf("  %-24s %s", line[0], value)
+	}
+}
+
+// redact reports whether a secret is set without revealing it
+func redact(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	return "(set)"
+}
+
+// RedactURI removes the password from a connection URI
+func RedactURI(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redact(raw)
+	}
+	if u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
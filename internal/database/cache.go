@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProductsCacheNamespace groups every cached product listing. Bumping its
+// version makes all previously cached listings unreachable at once.
+const ProductsCacheNamespace = "products"
+
+// HomeContentCacheNamespace is reported to Invalidated when the cached home
+// page content is dropped
+const HomeContentCacheNamespace = "home_content"
+
+// PostsCacheNamespace groups the cached journal listings and articles
+const PostsCacheNamespace = "posts"
+
+// cacheVersionKey returns the cache key holding the version counter for a namespace
+func cacheVersionKey(namespace string) string {
+	return "cache_version:" + namespace
+}
+
+// CacheNamespaceVersion returns the current version of a cache namespace.
+// It returns 0 when no cache is configured or the namespace has never been bumped.
+func (db *DBClient) CacheNamespaceVersion(ctx context.Context, namespace string) int64 {
+	if db.Cache == nil {
+		return 0
+	}
+
+	raw, err := db.Cache.Get(ctx, cacheVersionKey(namespace))
+	if err != nil {
+		return 0
+	}
+	version, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// CacheBumpNamespace invalidates every key built for a namespace by incrementing its version.
+// Stale entries are left to expire through their own TTL.
+func (db *DBClient) CacheBumpNamespace(ctx context.Context, namespace string) error {
+	if db.Cache == nil {
+		return nil // Silently skip if no cache is configured
+	}
+
+	_, err := db.Cache.Incr(ctx, cacheVersionKey(namespace))
+	db.NotifyInvalidated(namespace)
+	return err
+}
+
+// NotifyInvalidated reports an invalidated cache namespace to Invalidated
+func (db *DBClient) NotifyInvalidated(namespace string) {
+	if db.Invalidated != nil {
+		db.Invalidated(namespace)
+	}
+}
+
+// CacheDelPattern deletes every key matching a glob pattern
+func (db *DBClient) CacheDelPattern(ctx context.Context, pattern string) error {
+	if db.Cache == nil {
+		return nil // Silently skip if no cache is configured
+	}
+
+	return db.Cache.DelPattern(ctx, pattern)
+}
+
+// VersionedCacheKey builds a deterministic key for a namespace from a set of
+// parameters. Empty values are skipped and the remaining parameters are
+// sorted, so equivalent queries always share a key regardless of ordering.
+func (db *DBClient) VersionedCacheKey(ctx context.Context, namespace string, params map[string]string) string {
+	return fmt.Sprintf("%s:v%d:%s", namespace, db.CacheNamespaceVersion(ctx, namespace), CacheParams(params))
+}
+
+// CacheParams encodes a set of parameters the way VersionedCacheKey does:
+// empty values skipped and the rest sorted by name
+func CacheParams(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name, value := range params {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, url.QueryEscape(name)+"="+url.QueryEscape(params[name]))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// InvalidateProductCaches drops the cached detail entries for the given
+// product IDs and every cached product listing.
+func (db *DBClient) InvalidateProductCaches(ctx context.Context, productIDs ...string) error {
+	if len(productIDs) > 0 {
+		keys := make([]string, 0, len(productIDs))
+		for _, id := range productIDs {
+			keys = append(keys, "product:"+id)
+		}
+		if err := db.CacheDel(ctx, keys...); err != nil {
+			return err
+		}
+	}
+
+	return db.CacheBumpNamespace(ctx, ProductsCacheNamespace)
+}
@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LocalCachePrefixes are the keys NearCache keeps in process memory: the
+// catalog and content entries read on every storefront request, and the
+// namespace versions their keys are built from. Per-user entries, counters
+// and one-time codes are always read from Redis.
+var LocalCachePrefixes = []string{
+	"cache_version:",
+	ProductsCacheNamespace + ":",
+	PostsCacheNamespace + ":",
+	"product:",
+	"home_content_snapshot:",
+	"faqs",
+	"product_attributes",
+}
+
+// CacheInvalidation is broadcast to every instance when cached entries change
+type CacheInvalidation struct {
+	Origin   string   `json:"origin"`
+	Keys     []string `json:"keys,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// CacheBus broadcasts cache invalidations between API instances through
+// Redis pub/sub. Messages published by this instance are not delivered back
+// to it.
+type CacheBus struct {
+	client  *redis.Client
+	channel string
+	origin  string
+}
+
+// NewCacheBus creates a bus on the given Redis client
+func NewCacheBus(client *redis.Client) *CacheBus {
+	origin, err := randomToken()
+	if err != nil {
+		origin = time.Now().String()
+	}
+	return &CacheBus{client: client, channel: "cache:invalidate", origin: origin}
+}
+
+// Publish tells the other instances that keys and keys matching patterns
+// have changed
+func (b *CacheBus) Publish(ctx context.Context, keys, patterns []string) error {
+	data, err := json.Marshal(CacheInvalidation{Origin: b.origin, Keys: keys, Patterns: patterns})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Run calls fn with every invalidation published by another instance until
+// ctx is cancelled
+func (b *CacheBus) Run(ctx context.Context, fn func(CacheInvalidation)) {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	log.Printf("[CACHE] receiving invalidations through redis channel %s", b.channel)
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var inv CacheInvalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				log.Printf("[CACHE] dropping malformed invalidation: %v", err)
+				continue
+			}
+			if inv.Origin != b.origin {
+				fn(inv)
+			}
+		}
+	}
+}
+
+// NearCache keeps short-lived copies of the LocalCachePrefixes entries of a
+// Redis cache in process memory, saving a round trip on the hottest reads.
+// Every write is broadcast on a CacheBus so the other instances drop their
+// copies at once; a copy missed while the bus is disconnected lives at most
+// TTL.
+type NearCache struct {
+	*RedisCache
+	local    *MemoryCache
+	bus      *CacheBus
+	ttl      time.Duration
+	prefixes []string
+}
+
+// NewNearCache wraps a Redis client as a Cache with local copies kept for
+// at most ttl. Run must be started to receive the other instances'
+// invalidations.
+func NewNearCache(client *redis.Client, ttl time.Duration) *NearCache {
+	return &NearCache{
+		RedisCache: NewRedisCache(client),
+		local:      NewMemoryCache(defaultMemoryCacheEntries),
+		bus:        NewCacheBus(client),
+		ttl:        ttl,
+		prefixes:   LocalCachePrefixes,
+	}
+}
+
+// keepsLocal reports whether copies of key are kept in process memory
+func (n *NearCache) keepsLocal(key string) bool {
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// localKeys returns the keys kept in process memory
+func (n *NearCache) localKeys(keys []string) []string {
+	local := []string{}
+	for _, key := range keys {
+		if n.keepsLocal(key) {
+			local = append(local, key)
+		}
+	}
+	return local
+}
+
+// invalidate drops local copies of keys and tells the other instances to
+// drop theirs. Redis is already up to date, so a failed broadcast is only
+// logged.
+func (n *NearCache) invalidate(ctx context.Context, keys, patterns []string) {
+	keys = n.localKeys(keys)
+	if len(keys) == 0 && len(patterns) == 0 {
+		return
+	}
+	n.purge(CacheInvalidation{Keys: keys, Patterns: patterns})
+	if err := n.bus.Publish(ctx, keys, patterns); err != nil {
+		log.Printf("[CACHE] Failed to broadcast invalidation of %v %v: %v", keys, patterns, err)
+	}
+}
+
+// purge drops the local copies named by an invalidation
+func (n *NearCache) purge(inv CacheInvalidation) {
+	ctx := context.Background()
+	if len(inv.Keys) > 0 {
+		_ = n.local.Del(ctx, inv.Keys...)
+	}
+	for _, pattern := range inv.Patterns {
+		_ = n.local.DelPattern(ctx, pattern)
+	}
+}
+
+// Run drops local copies invalidated by the other instances until ctx is
+// cancelled
+func (n *NearCache) Run(ctx context.Context) {
+	n.bus.Run(ctx, n.purge)
+}
+
+// Get implements Cache, answering from the local copy when there is one
+func (n *NearCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if !n.keepsLocal(key) {
+		return n.RedisCache.Get(ctx, key)
+	}
+	if value, err := n.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+	value, ttl, err := n.RedisCache.GetWithTTL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	// The copy never outlives the entry in Redis
+	if ttl == 0 || ttl > n.ttl {
+		ttl = n.ttl
+	}
+	_ = n.local.Set(ctx, key, value, ttl)
+	return value, nil
+}
+
+// Set implements Cache
+func (n *NearCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	if err := n.RedisCache.Set(ctx, key, value, expiration); err != nil {
+		return err
+	}
+	n.invalidate(ctx, []string{key}, nil)
+	return nil
+}
+
+// Del implements Cache
+func (n *NearCache) Del(ctx context.Context, keys ...string) error {
+	err := n.RedisCache.Del(ctx, keys...)
+	n.invalidate(ctx, keys, nil)
+	return err
+}
+
+// DelPattern implements Cache
+func (n *NearCache) DelPattern(ctx context.Context, pattern string) error {
+	err := n.RedisCache.DelPattern(ctx, pattern)
+	n.invalidate(ctx, nil, []string{pattern})
+	return err
+}
+
+// Incr implements Cache. Namespace versions are counters, so bumping one
+// drops every instance's copy and its listings become unreachable at once.
+func (n *NearCache) Incr(ctx context.Context, key string) (int64, error) {
+	value, err := n.RedisCache.Incr(ctx, key)
+	n.invalidate(ctx, []string{key}, nil)
+	return value, err
+}
+
+// Name implements Cache
+func (n *NearCache) Name() string {
+	return "redis with local copies"
+}
+
+// SubscribeInvalidations drops this instance's local cache copies whenever
+// another instance changes them, until ctx is cancelled. It returns at once
+// when the cache keeps no local copies.
+func (db *DBClient) SubscribeInvalidations(ctx context.Context) {
+	if near, ok := db.Cache.(*NearCache); ok {
+		near.Run(ctx)
+	}
+}
@@ -0,0 +1,348 @@
+package database
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrCacheMiss is returned by Cache.Get when a key is absent or expired
+var ErrCacheMiss = errors.New("key not found in cache")
+
+// Cache is the key/value store behind DBClient's caching helpers.
+// Values are opaque byte slices; callers handle serialization.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	// DelPattern deletes every key matching a glob pattern (e.g. "products:*")
+	DelPattern(ctx context.Context, pattern string) error
+	// Incr atomically increments an integer counter, creating it at 1 if missing
+	Incr(ctx context.Context, key string) (int64, error)
+	// HIncr atomically increments one counter of the hash stored at key
+	HIncr(ctx context.Context, key, field string) error
+	// HDrain returns every counter of the hash at key and removes it, so
+	// increments made after it are kept for the next drain
+	HDrain(ctx context.Context, key string) (map[string]int64, error)
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+	// Name identifies the backend for logging
+	Name() string
+}
+
+// NewCache selects the cache backend: Redis when a client is available,
+// otherwise an in-process LRU so handlers keep caching without Redis.
+func NewCache(redisClient *redis.Client) Cache {
+	if redisClient != nil {
+		return NewRedisCache(redisClient)
+	}
+	return NewMemoryCache(defaultMemoryCacheEntries)
+}
+
+// RedisCache implements Cache on top of a Redis client
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache wraps a Redis client as a Cache
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+// Get returns the raw value stored at key
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	val, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrCacheMiss
+		}
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetWithTTL returns the raw value stored at key and how long it has left,
+// 0 when it never expires
+func (r *RedisCache) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err == redis.Nil {
+		return nil, 0, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl := pttl.Val()
+	if ttl < 0 {
+		ttl = 0
+	}
+	return []byte(get.Val()), ttl, nil
+}
+
+// Set stores value at key with the given expiration (0 means no expiry)
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return r.client.Set(ctx, key, value, expiration).Err()
+}
+
+// Del removes the given keys
+func (r *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// DelPattern deletes matching keys using SCAN, so it never blocks Redis the
+// way KEYS would on a large keyspace.
+func (r *RedisCache) DelPattern(ctx context.Context, pattern string) error {
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	batch := make([]string, 0, 100)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := r.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return r.Del(ctx, batch...)
+}
+
+// Incr increments the counter stored at key
+func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// HIncr increments a field of the hash stored at key
+func (r *RedisCache) HIncr(ctx context.Context, key, field string) error {
+	return r.client.HIncrBy(ctx, key, field, 1).Err()
+}
+
+// HDrain reads and deletes the hash in one transaction
+func (r *RedisCache) HDrain(ctx context.Context, key string) (map[string]int64, error) {
+	var values *redis.StringStringMapCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(values.Val()))
+	for field, value := range values.Val() {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", key, field, err)
+		}
+		counts[field] = n
+	}
+	return counts, nil
+}
+
+// Ping round-trips to Redis
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Name identifies the backend
+func (r *RedisCache) Name() string {
+	return "redis"
+}
+
+// defaultMemoryCacheEntries bounds the in-process cache when Redis is unavailable
+const defaultMemoryCacheEntries = 10000
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+// MemoryCache is a size-bounded, TTL-aware LRU cache used when Redis is not
+// configured. It is local to the process, so multiple API instances do not
+// share entries.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	// Counter hashes are drained by their readers rather than evicted
+	hashes map[string]map[string]int64
+}
+
+// NewMemoryCache creates an LRU cache holding at most maxEntries keys
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryCacheEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		hashes:     make(map[string]map[string]int64),
+	}
+}
+
+// Get returns the value at key, evicting it if expired
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	entry := el.Value.(*memoryEntry)
+	if m.expired(entry) {
+		m.remove(el)
+		return nil, ErrCacheMiss
+	}
+	m.ll.MoveToFront(el)
+	return entry.value, nil
+}
+
+// Set stores value at key, evicting the least recently used key when full
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, value, expiration)
+	return nil
+}
+
+// Del removes the given keys
+func (m *MemoryCache) Del(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// DelPattern removes every key matching a glob pattern
+func (m *MemoryCache) DelPattern(_ context.Context, pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, el := range m.items {
+		if matched, _ := path.Match(pattern, key); matched {
+			m.remove(el)
+		}
+	}
+	return nil
+}
+
+// Incr increments the counter stored at key, keeping any existing expiry
+func (m *MemoryCache) Incr(_ context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var current int64
+	var ttl time.Duration
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		if !m.expired(entry) {
+			n, err := strconv.ParseInt(string(entry.value), 10, 64)
+			if err != nil {
+				return 0, errors.New("value is not an integer")
+			}
+			current = n
+			if !entry.expiresAt.IsZero() {
+				ttl = time.Until(entry.expiresAt)
+			}
+		}
+	}
+
+	current++
+	m.set(key, []byte(strconv.FormatInt(current, 10)), ttl)
+	return current, nil
+}
+
+// HIncr increments a field of the hash stored at key
+func (m *MemoryCache) HIncr(_ context.Context, key, field string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok {
+		hash = make(map[string]int64)
+		m.hashes[key] = hash
+	}
+	hash[field]++
+	return nil
+}
+
+// HDrain returns and removes the hash stored at key
+func (m *MemoryCache) HDrain(_ context.Context, key string) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := m.hashes[key]
+	delete(m.hashes, key)
+	if hash == nil {
+		hash = map[string]int64{}
+	}
+	return hash, nil
+}
+
+// Ping always succeeds; the cache lives in this process
+func (m *MemoryCache) Ping(context.Context) error {
+	return nil
+}
+
+// Name identifies the backend
+func (m *MemoryCache) Name() string {
+	return "memory"
+}
+
+// set inserts or replaces an entry; the caller must hold m.mu
+func (m *MemoryCache) set(key string, value []byte, expiration time.Duration) {
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.ll.MoveToFront(el)
+		return
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.ll.Len() > m.maxEntries {
+		m.remove(m.ll.Back())
+	}
+}
+
+// remove drops an element; the caller must hold m.mu
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
+
+func (m *MemoryCache) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)
+}
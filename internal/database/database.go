@@ -13,17 +13,25 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// DBClient represents our database client with both MongoDB and Redis connections
+// DBClient represents our database client with a MongoDB connection and a cache backend
 type DBClient struct {
 	MongoDB *mongo.Database
-	Redis   *redis.Client
+	Cache   Cache
+	// Locks guards work that must not run on two instances at once
+	Locks Locker
+	// Invalidated, when set, is told the namespace of every cache
+	// invalidation so the entries can be rebuilt ahead of the next request.
+	// It is called inline and must not block.
+	Invalidated func(namespace string)
 }
 
-// NewDBClient creates a new database client wrapper
+// NewDBClient creates a new database client wrapper. A nil redisClient falls
+// back to an in-process cache so caching keeps working without Redis.
 func NewDBClient(mongoClient *mongo.Client, dbName string, redisClient *redis.Client) *DBClient {
 	return &DBClient{
 		MongoDB: mongoClient.Database(dbName),
-		Redis:   redisClient,
+		Cache:   NewCache(redisClient),
+		Locks:   NewLocker(redisClient),
 	}
 }
 
@@ -45,6 +53,34 @@ func (db *DBClient) Collections() struct {
 	Notifications     *mongo.Collection
 	Recommendations   *mongo.Collection
 	RecFeedbacks      *mongo.Collection
+	AuditLogs         *mongo.Collection
+	StockMovements    *mongo.Collection
+	Campaigns         *mongo.Collection
+	Returns           *mongo.Collection
+	WebhookEndpoints  *mongo.Collection
+	ProductQuestions  *mongo.Collection
+	Brands            *mongo.Collection
+	Currencies        *mongo.Collection
+	Pincodes          *mongo.Collection
+	APIKeys           *mongo.Collection
+	Roles             *mongo.Collection
+	FeatureFlags      *mongo.Collection
+	ProductAttributes *mongo.Collection
+	PriceHistory      *mongo.Collection
+	GiftCards         *mongo.Collection
+	ReportRuns        *mongo.Collection
+	CatalogRead       *mongo.Collection
+	ReviewVotes       *mongo.Collection
+	Activities        *mongo.Collection
+	ProductViews      *mongo.Collection
+	Warranties        *mongo.Collection
+	Stores            *mongo.Collection
+	SerialNumbers     *mongo.Collection
+	ContactMessages   *mongo.Collection
+	Posts             *mongo.Collection
+	FAQs              *mongo.Collection
+	Media             *mongo.Collection
+	RetentionStats    *mongo.Collection
 } {
 	return struct {
 		Users             *mongo.Collection
@@ -63,6 +99,34 @@ func (db *DBClient) Collections() struct {
 		Notifications     *mongo.Collection
 		Recommendations   *mongo.Collection
 		RecFeedbacks      *mongo.Collection
+		AuditLogs         *mongo.Collection
+		StockMovements    *mongo.Collection
+		Campaigns         *mongo.Collection
+		Returns           *mongo.Collection
+		WebhookEndpoints  *mongo.Collection
+		ProductQuestions  *mongo.Collection
+		Brands            *mongo.Collection
+		Currencies        *mongo.Collection
+		Pincodes          *mongo.Collection
+		APIKeys           *mongo.Collection
+		Roles             *mongo.Collection
+		FeatureFlags      *mongo.Collection
+		ProductAttributes *mongo.Collection
+		PriceHistory      *mongo.Collection
+		GiftCards         *mongo.Collection
+		ReportRuns        *mongo.Collection
+		CatalogRead       *mongo.Collection
+		ReviewVotes       *mongo.Collection
+		Activities        *mongo.Collection
+		ProductViews      *mongo.Collection
+		Warranties        *mongo.Collection
+		Stores            *mongo.Collection
+		SerialNumbers     *mongo.Collection
+		ContactMessages   *mongo.Collection
+		Posts             *mongo.Collection
+		FAQs              *mongo.Collection
+		Media             *mongo.Collection
+		RetentionStats    *mongo.Collection
 	}{
 		Users:             db.MongoDB.Collection("users"),
 		Products:          db.MongoDB.Collection("products"),
@@ -80,32 +144,69 @@ func (db *DBClient) Collections() struct {
 		Notifications:     db.MongoDB.Collection("notifications"),
 		Recommendations:   db.MongoDB.Collection("recommendations"),
 		RecFeedbacks:      db.MongoDB.Collection("recommendation_feedbacks"),
+		AuditLogs:         db.MongoDB.Collection("audit_logs"),
+		StockMovements:    db.MongoDB.Collection("stock_movements"),
+		Campaigns:         db.MongoDB.Collection("campaigns"),
+		Returns:           db.MongoDB.Collection("returns"),
+		WebhookEndpoints:  db.MongoDB.Collection("webhook_endpoints"),
+		ProductQuestions:  db.MongoDB.Collection("product_questions"),
+		Brands:            db.MongoDB.Collection("brands"),
+		Currencies:        db.MongoDB.Collection("currencies"),
+		Pincodes:          db.MongoDB.Collection("pincodes"),
+		APIKeys:           db.MongoDB.Collection("api_keys"),
+		Roles:             db.MongoDB.Collection("roles"),
+		FeatureFlags:      db.MongoDB.Collection("feature_flags"),
+		ProductAttributes: db.MongoDB.Collection("product_attributes"),
+		PriceHistory:      db.MongoDB.Collection("price_history"),
+		GiftCards:         db.MongoDB.Collection("gift_cards"),
+		ReportRuns:        db.MongoDB.Collection("report_runs"),
+		CatalogRead:       db.MongoDB.Collection("catalog_read"),
+		ReviewVotes:       db.MongoDB.Collection("review_votes"),
+		Activities:        db.MongoDB.Collection("activities"),
+		ProductViews:      db.MongoDB.Collection("product_views"),
+		Warranties:        db.MongoDB.Collection("warranties"),
+		Stores:            db.MongoDB.Collection("stores"),
+		SerialNumbers:     db.MongoDB.Collection("serial_numbers"),
+		ContactMessages:   db.MongoDB.Collection("contact_messages"),
+		Posts:             db.MongoDB.Collection("posts"),
+		FAQs:              db.MongoDB.Collection("faqs"),
+		Media:             db.MongoDB.Collection("media"),
+		RetentionStats:    db.MongoDB.Collection("retention_stats"),
 	}
 }
 
-// CacheGet retrieves data from Redis cache
+// EmailCollation is the case-insensitive collation of the unique users.email
+// index. Email lookups must use it so they match regardless of case and hit the index.
+var EmailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// BrandCollation is the case-insensitive collation of the unique brands.name
+// index, so "Casio" and "casio" are the same brand
+var BrandCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// CategoryCollation is the case-insensitive collation of the unique
+// categories.path index, so "Men/Luxury" and "men/luxury" are the same category
+var CategoryCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// CacheGet retrieves data from the cache
 func (db *DBClient) CacheGet(ctx context.Context, key string, dest interface{}) error {
-	// Check if Redis is available
-	if db.Redis == nil {
-		return errors.New("redis not available")
+	// Check if a cache backend is configured
+	if db.Cache == nil {
+		return errors.New("cache not available")
 	}
 	
-	val, err := db.Redis.Get(ctx, key).Result()
+	val, err := db.Cache.Get(ctx, key)
 	if err != nil {
-		if err == redis.Nil {
-			return errors.New("key not found in cache")
-		}
 		return err
 	}
 
-	return json.Unmarshal([]byte(val), dest)
+	return json.Unmarshal(val, dest)
 }
 
-// CacheSet stores data in Redis cache
+// CacheSet stores data in the cache
 func (db *DBClient) CacheSet(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	// Check if Redis is available
-	if db.Redis == nil {
-		return nil // Silently skip if Redis is not available
+	// Check if a cache backend is configured
+	if db.Cache == nil {
+		return nil // Silently skip if no cache is configured
 	}
 	
 	data, err := json.Marshal(value)
@@ -113,17 +214,17 @@ func (db *DBClient) CacheSet(ctx context.Context, key string, value interface{},
 		return err
 	}
 
-	return db.Redis.Set(ctx, key, data, expiration).Err()
+	return db.Cache.Set(ctx, key, data, expiration)
 }
 
-// CacheDel deletes data from Redis cache
+// CacheDel deletes data from the cache
 func (db *DBClient) CacheDel(ctx context.Context, keys ...string) error {
-	// Check if Redis is available
-	if db.Redis == nil {
-		return nil // Silently skip if Redis is not available
+	// Check if a cache backend is configured
+	if db.Cache == nil {
+		return nil // Silently skip if no cache is configured
 	}
 	
-	return db.Redis.Del(ctx, keys...).Err()
+	return db.Cache.Del(ctx, keys...)
 }
 
 // FindByID is a generic function to find a document by ID
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLockHeld is returned when another holder has the lock
+var ErrLockHeld = errors.New("lock is held elsewhere")
+
+// ErrLockLost is returned when a lock expired or was taken over before its
+// holder renewed or released it
+var ErrLockLost = errors.New("lock was lost")
+
+// lockTTL is how long a lock outlives a holder that stops renewing it, e.g.
+// because its instance died. Holders renew it every third of that.
+const lockTTL = 30 * time.Second
+
+// Locker hands out named locks. Each lock carries a random token so only its
+// holder can renew or release it.
+type Locker interface {
+	// Lock takes the lock called name for ttl and returns its token, or
+	// ErrLockHeld when someone else holds it
+	Lock(ctx context.Context, name string, ttl time.Duration) (string, error)
+	// Renew extends the lock to ttl from now, or returns ErrLockLost when
+	// token no longer holds it
+	Renew(ctx context.Context, name, token string, ttl time.Duration) error
+	// Unlock releases the lock if token still holds it
+	Unlock(ctx context.Context, name, token string) error
+	// Name identifies the backend for logging
+	Name() string
+}
+
+// NewLocker selects the lock backend: Redis when a client is available, so
+// locks are shared by every API instance, otherwise in-process locks.
+func NewLocker(redisClient *redis.Client) Locker {
+	if redisClient != nil {
+		return NewRedisLocker(redisClient, "lock:")
+	}
+	return NewMemoryLocker()
+}
+
+// WithLock runs fn while holding the lock called name, renewing it until fn
+// returns. When another instance holds the lock fn is not run and ErrLockHeld
+// is returned. If the lock is lost midway, fn's context is cancelled.
+func (db *DBClient) WithLock(ctx context.Context, name string, fn func(context.Context) error) error {
+	token, err := db.Locks.Lock(ctx, name, lockTTL)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(lockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := db.Locks.Renew(runCtx, name, token, lockTTL); err != nil {
+				if runCtx.Err() != nil {
+					return
+				}
+				// A failed renewal may still leave the lock with us until it
+				// expires; a lost lock means someone else may be running
+				if errors.Is(err, ErrLockLost) {
+					log.Printf("[LOCK] Lost %s, stopping", name)
+					cancel()
+					return
+				}
+				log.Printf("[LOCK] Failed to renew %s: %v", name, err)
+			}
+		}
+	}()
+
+	err = fn(runCtx)
+	cancel()
+	<-renewed
+	// Released on a fresh context so a cancelled ctx doesn't leave the lock
+	// held until it expires
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unlockCancel()
+	if unlockErr := db.Locks.Unlock(unlockCtx, name, token); unlockErr != nil && !errors.Is(unlockErr, ErrLockLost) {
+		log.Printf("[LOCK] Failed to release %s: %v", name, unlockErr)
+	}
+	return err
+}
+
+// randomToken returns a random hex token, e.g. identifying one holding of a lock
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// renewScript extends a lock only while the caller's token holds it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// unlockScript deletes a lock only while the caller's token holds it
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLocker implements Locker with SET NX keys holding the token, so locks
+// are shared by every API instance
+type RedisLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisLocker creates a locker whose keys start with prefix
+func NewRedisLocker(client *redis.Client, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+// Lock implements Locker
+func (r *RedisLocker) Lock(ctx context.Context, name string, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	ok, err := r.client.SetNX(ctx, r.prefix+name, token, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrLockHeld
+	}
+	return token, nil
+}
+
+// Renew implements Locker
+func (r *RedisLocker) Renew(ctx context.Context, name, token string, ttl time.Duration) error {
+	n, err := renewScript.Run(ctx, r.client, []string{r.prefix + name}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Unlock implements Locker
+func (r *RedisLocker) Unlock(ctx context.Context, name, token string) error {
+	n, err := unlockScript.Run(ctx, r.client, []string{r.prefix + name}, token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Name implements Locker
+func (r *RedisLocker) Name() string { return "redis" }
+
+// MemoryLocker implements Locker within this process. It keeps one instance
+// from overlapping itself but does nothing across instances.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+type memoryLock struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemoryLocker creates an empty in-process locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: map[string]memoryLock{}}
+}
+
+// Lock implements Locker
+func (m *MemoryLocker) Lock(_ context.Context, name string, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if held, ok := m.locks[name]; ok && time.Now().Before(held.expires) {
+		return "", ErrLockHeld
+	}
+	m.locks[name] = memoryLock{token: token, expires: time.Now().Add(ttl)}
+	return token, nil
+}
+
+// Renew implements Locker
+func (m *MemoryLocker) Renew(_ context.Context, name, token string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	held, ok := m.locks[name]
+	if !ok || held.token != token || !time.Now().Before(held.expires) {
+		return ErrLockLost
+	}
+	m.locks[name] = memoryLock{token: token, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Unlock implements Locker
+func (m *MemoryLocker) Unlock(_ context.Context, name, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	held, ok := m.locks[name]
+	if !ok || held.token != token {
+		return ErrLockLost
+	}
+	delete(m.locks, name)
+	return nil
+}
+
+// Name implements Locker
+func (m *MemoryLocker) Name() string { return "memory" }
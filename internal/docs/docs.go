@@ -0,0 +1,46 @@
+// Package docs serves the hand-maintained OpenAPI specification and a Swagger UI
+// page for browsing it. Update openapi.yaml alongside any route or payload change.
+package docs
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// swaggerUI loads Swagger UI from a CDN and points it at the embedded spec
+const swaggerUI = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Makwatches API Docs</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "%s", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>`
+
+// Register mounts the Swagger UI at prefix and the raw spec at prefix + "/openapi.yaml"
+func Register(app *fiber.App, prefix string) {
+	specPath := prefix + "/openapi.yaml"
+
+	app.Get(specPath, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/yaml")
+		return c.Send(spec)
+	})
+
+	page := []byte(fmt.Sprintf(swaggerUI, specPath))
+	app.Get(prefix, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(page)
+	})
+}
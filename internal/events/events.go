@@ -0,0 +1,171 @@
+// Package events lets the rest of the application announce what happened
+// (an order was placed, a product changed) without knowing who listens.
+// Subscribers such as the outbound webhook dispatcher react to them.
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types
+const (
+	OrderCreated       = "order.created"
+	OrderStatusChanged = "order.status_changed"
+	PaymentCaptured    = "payment.captured"
+	ProductUpdated     = "product.updated"
+	StockLow           = "stock.low"
+	SupportMessage     = "support.message"
+	// Account events only concern the account that raised them. They feed
+	// its activity timeline and aren't offered to webhooks.
+	ReviewCreated  = "review.created"
+	WishlistAdded  = "wishlist.added"
+	AddressChanged = "address.changed"
+	// Ping is only sent to test a webhook endpoint
+	Ping = "ping"
+)
+
+// Types lists the event types subscribers can choose from
+var Types = []string{OrderCreated, OrderStatusChanged, PaymentCaptured, ProductUpdated, StockLow, SupportMessage}
+
+// Event is one occurrence of something subscribers may care about
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// OrderStatusChange is the data of an order.status_changed event
+type OrderStatusChange struct {
+	OrderID string `json:"orderId"`
+	UserID  string `json:"userId"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Actor   string `json:"actor"` // customer, admin or system
+	Note    string `json:"note,omitempty"`
+}
+
+// PaymentCapture is the data of a payment.captured event
+type PaymentCapture struct {
+	OrderID           string  `json:"orderId,omitempty"` // Empty when checkout hasn't created the order yet
+	RazorpayOrderID   string  `json:"razorpayOrderId"`
+	RazorpayPaymentID string  `json:"razorpayPaymentId"`
+	Amount            float64 `json:"amount"` // Rupees
+	Method            string  `json:"method,omitempty"`
+	Mode              string  `json:"mode"` // Razorpay mode: "live" or "test"
+}
+
+// LowStock is the data of a stock.low event
+type LowStock struct {
+	ProductID string `json:"productId"`
+	Name      string `json:"name"`
+	Stock     int    `json:"stock"`
+	Threshold int    `json:"threshold"`
+}
+
+// SupportTicketMessage is the data of a support.message event: a customer or
+// staff member wrote on a support ticket
+type SupportTicketMessage struct {
+	TicketID  string `json:"ticketId"`
+	UserID    string `json:"userId"` // The customer who opened the ticket
+	Subject   string `json:"subject"`
+	MessageID string `json:"messageId"`
+	Staff     bool   `json:"staff"`
+	Status    string `json:"status"` // The ticket's status after the message
+}
+
+// ReviewWritten is the data of a review.created event
+type ReviewWritten struct {
+	ReviewID    string  `json:"reviewId"`
+	UserID      string  `json:"userId"`
+	ProductID   string  `json:"productId"`
+	ProductName string  `json:"productName"`
+	Rating      float64 `json:"rating"`
+}
+
+// WishlistAdd is the data of a wishlist.added event
+type WishlistAdd struct {
+	UserID      string `json:"userId"`
+	ProductID   string `json:"productId"`
+	ProductName string `json:"productName"`
+}
+
+// Address changes
+const (
+	AddressCreated = "created"
+	AddressUpdated = "updated"
+	AddressDeleted = "deleted"
+)
+
+// AddressChange is the data of an address.changed event
+type AddressChange struct {
+	UserID    string `json:"userId"`
+	AddressID string `json:"addressId"`
+	Change    string `json:"change"` // created, updated or deleted
+	City      string `json:"city"`
+}
+
+// New creates an event with a fresh ID
+func New(eventType string, data interface{}) Event {
+	return Event{ID: primitive.NewObjectID().Hex(), Type: eventType, OccurredAt: time.Now(), Data: data}
+}
+
+// Handler reacts to an event. It runs on the publisher's goroutine, so it
+// should hand slow work (e.g. HTTP calls) to the job queue.
+type Handler func(ctx context.Context, e Event) error
+
+// Bus fans events out to its subscribers
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus creates a bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a handler for every event published from now on
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish announces an event. Subscriber failures are logged and never
+// reach the publisher. A nil bus discards events, so publishers don't need
+// to check whether one is configured.
+func (b *Bus) Publish(ctx context.Context, eventType string, data interface{}) {
+	if b == nil {
+		return
+	}
+	e := New(eventType, data)
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			log.Printf("[EVENTS] %s %s: %v", e.Type, e.ID, err)
+		}
+	}
+}
+
+// Sign computes the signature sent with webhook deliveries: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the endpoint's secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -3,17 +3,15 @@ package firebase
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/option"
 )
 
-// FirebaseClient wraps the GCS client for Firebase Storage
+// FirebaseClient wraps the GCS client for Firebase Storage. Uploads go
+// through storage.Firebase, which builds on this client.
 // Usage: client, err := NewFirebaseClient(ctx, "path/to/serviceAccountKey.json", "your-bucket-name")
 type FirebaseClient struct {
 	StorageClient *storage.Client
@@ -52,51 +50,3 @@ func contains404(errStr string) bool {
 		strings.Contains(errStr, "bucket doesn't exist") ||
 		strings.Contains(errStr, "bucket does not exist")
 }
-
-// UploadFile uploads a file to Firebase Storage and returns the public URL
-func (f *FirebaseClient) UploadFile(ctx context.Context, file io.Reader, filename string) (string, error) {
-	objectName := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(filename))
-	log.Printf("[FIREBASE] Starting upload for %s as %s to bucket %s", filename, objectName, f.BucketName)
-
-	wc := f.StorageClient.Bucket(f.BucketName).Object(objectName).NewWriter(ctx)
-
-	// Detect content type from file extension
-	ext := filepath.Ext(filename)
-	switch ext {
-	case ".jpg", ".jpeg":
-		wc.ContentType = "image/jpeg"
-	case ".png":
-		wc.ContentType = "image/png"
-	case ".gif":
-		wc.ContentType = "image/gif"
-	case ".webp":
-		wc.ContentType = "image/webp"
-	default:
-		wc.ContentType = "image/jpeg" // default
-	}
-	log.Printf("[FIREBASE] Set content type: %s", wc.ContentType)
-
-	log.Println("[FIREBASE] Copying file data...")
-	if _, err := io.Copy(wc, file); err != nil {
-		log.Printf("[FIREBASE] Failed to copy file data: %v", err)
-		return "", fmt.Errorf("failed to copy file data: %w", err)
-	}
-
-	log.Println("[FIREBASE] Closing writer...")
-	if err := wc.Close(); err != nil {
-		log.Printf("[FIREBASE] Failed to close writer: %v", err)
-		return "", fmt.Errorf("failed to close writer: %w", err)
-	}
-
-	log.Println("[FIREBASE] Setting public access...")
-	// Make the file public
-	obj := f.StorageClient.Bucket(f.BucketName).Object(objectName)
-	if err := obj.ACL().Set(ctx, storage.AllUsers, storage.RoleReader); err != nil {
-		log.Printf("[FIREBASE] Failed to set public access: %v", err)
-		return "", fmt.Errorf("failed to set public access: %w", err)
-	}
-
-	publicURL := fmt.Sprintf("https://storage.googleapis.com/%s/%s", f.BucketName, objectName)
-	log.Printf("[FIREBASE] Upload completed successfully, URL: %s", publicURL)
-	return publicURL, nil
-}
@@ -0,0 +1,90 @@
+// Package flags evaluates feature flags: features turned on for a
+// percentage of users while they are rolled out. Flags live in the
+// feature_flags collection and are cached briefly, so a change reaches every
+// instance within flagCacheTTL.
+package flags
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const flagCacheTTL = 30 * time.Second
+
+// CacheKey is where a flag is cached; drop it when the flag changes
+func CacheKey(key string) string {
+	return "feature_flag:" + key
+}
+
+// Service answers whether a flag is on for a user
+type Service struct {
+	DB *database.DBClient
+}
+
+// Enabled reports whether the flag is on for the user; pass a zero ID for
+// anonymous requests. Unknown flags are off. When a flag can't be read it
+// takes its default from models.DefaultFeatureFlags, so a database or cache
+// outage doesn't switch off checks like COD verification.
+func (s *Service) Enabled(ctx context.Context, key string, userID primitive.ObjectID) bool {
+	flag, err := s.load(ctx, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false
+		}
+		log.Printf("[FLAGS] Failed to load %s, using its default: %v", key, err)
+		var ok bool
+		if flag, ok = defaultFlag(key); !ok {
+			return false
+		}
+	}
+	if !flag.Enabled || flag.Rollout <= 0 {
+		return false
+	}
+	if flag.Rollout >= 100 {
+		return true
+	}
+	if userID.IsZero() {
+		return false
+	}
+	return Bucket(key, userID) < flag.Rollout
+}
+
+// Bucket places a user in 0-99 for a flag. It is stable, and differs
+// between flags so the same users aren't always the first to get features.
+func Bucket(key string, userID primitive.ObjectID) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + userID.Hex()))
+	return int(h.Sum32() % 100)
+}
+
+// defaultFlag returns the flag a store starts with, if key is one of them
+func defaultFlag(key string) (models.FeatureFlag, bool) {
+	for _, flag := range models.DefaultFeatureFlags() {
+		if flag.Key == key {
+			return flag, true
+		}
+	}
+	return models.FeatureFlag{}, false
+}
+
+func (s *Service) load(ctx context.Context, key string) (models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	if err := s.DB.CacheGet(ctx, CacheKey(key), &flag); err == nil {
+		return flag, nil
+	}
+	if err := s.DB.Collections().FeatureFlags.FindOne(ctx, bson.M{"_id": key}).Decode(&flag); err != nil {
+		return flag, err
+	}
+	s.DB.CacheSet(ctx, CacheKey(key), flag, flagCacheTTL)
+	return flag, nil
+}
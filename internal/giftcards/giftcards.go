@@ -0,0 +1,131 @@
+// Package giftcards redeems gift card balances at checkout and gives them
+// back when orders are cancelled or lowered. Gift cards live in the gift_cards
+// collection; each balance change is recorded in the card's ledger.
+package giftcards
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// ErrUnavailable means the card is unknown, disabled, expired or its
+// balance changed since it was looked up
+var ErrUnavailable = errors.New("gift card is not available")
+
+// codeAlphabet leaves out characters easily misread on a printed card
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Normalize returns the stored form of a code as a customer typed it
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// NewCode generates a random 16 character code
+func NewCode() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b)
+}
+
+// Find returns the usable card with the code, or ErrUnavailable
+func Find(ctx context.Context, db *database.DBClient, code string) (models.GiftCard, error) {
+	var card models.GiftCard
+	err := db.Collections().GiftCards.FindOne(ctx, bson.M{"code": Normalize(code)}).Decode(&card)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && !card.Usable(time.Now())) {
+		return card, ErrUnavailable
+	}
+	return card, err
+}
+
+// Applied returns how much of due the card pays
+func Applied(card models.GiftCard, due float64) float64 {
+	return math.Round(math.Min(card.Balance, due)*100) / 100
+}
+
+// Redeem takes amount off the card's balance for the order. It fails with
+// ErrUnavailable when the card can no longer pay it.
+func Redeem(ctx context.Context, db *database.DBClient, code string, amount float64, orderID primitive.ObjectID) error {
+	now := time.Now()
+	res, err := db.Collections().GiftCards.UpdateOne(ctx,
+		bson.M{
+			"code":     Normalize(code),
+			"disabled": false,
+			"balance":  bson.M{"$gte": amount},
+			"$or":      bson.A{bson.M{"expires_at": nil}, bson.M{"expires_at": bson.M{"$gt": now}}},
+		},
+		bson.M{
+			"$inc":  bson.M{"balance": -amount},
+			"$set":  bson.M{"updated_at": now},
+			"$push": bson.M{"ledger": models.GiftCardEntry{Kind: models.GiftCardRedeemed, OrderID: orderID, Amount: amount, CreatedAt: now}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("redeem gift card: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Refund gives back what the order paid by gift card, once. Failures are
+// logged; the cancellation goes ahead regardless.
+func Refund(ctx context.Context, db *database.DBClient, order models.Order) {
+	if order.GiftCard == nil || order.GiftCard.Amount <= 0 {
+		return
+	}
+	now := time.Now()
+	_, err := db.Collections().GiftCards.UpdateOne(ctx,
+		bson.M{
+			"code":   order.GiftCard.Code,
+			"ledger": bson.M{"$not": bson.M{"$elemMatch": bson.M{"kind": models.GiftCardRefunded, "order_id": order.ID}}},
+		},
+		bson.M{
+			"$inc":  bson.M{"balance": order.GiftCard.Amount},
+			"$set":  bson.M{"updated_at": now},
+			"$push": bson.M{"ledger": models.GiftCardEntry{Kind: models.GiftCardRefunded, OrderID: order.ID, Amount: order.GiftCard.Amount, CreatedAt: now}},
+		},
+	)
+	if err != nil {
+		log.Printf("[GIFTCARDS] Failed to refund gift card %s for order %s: %v", order.GiftCard.Code, order.ID.Hex(), err)
+	}
+}
+
+// GiveBack returns part of what an order paid by gift card when its total
+// went down or items were returned. The order must then record the smaller
+// amount, so a later cancellation only refunds what is left.
+func GiveBack(ctx context.Context, db *database.DBClient, order models.Order, amount float64) error {
+	if order.GiftCard == nil || amount <= 0 {
+		return nil
+	}
+	now := time.Now()
+	_, err := db.Collections().GiftCards.UpdateOne(ctx,
+		bson.M{"code": order.GiftCard.Code},
+		bson.M{
+			"$inc":  bson.M{"balance": amount},
+			"$set":  bson.M{"updated_at": now},
+			"$push": bson.M{"ledger": models.GiftCardEntry{Kind: models.GiftCardAdjusted, OrderID: order.ID, Amount: amount, CreatedAt: now}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("give back gift card: %w", err)
+	}
+	return nil
+}
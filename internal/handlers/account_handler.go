@@ -1,20 +1,36 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
 // AccountHandler handles user account operations
 type AccountHandler struct {
-	DB     *database.DBClient
-	Config *config.Config
+	DB      *database.DBClient
+	Config  *config.Config
+	Storage storage.Storage // Removes the photos of deleted reviews
 }
 
 // NewAccountHandler creates a new instance of AccountHandler
@@ -32,10 +48,7 @@ func (h *AccountHandler) GetAccountOverview(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get base user data
@@ -44,16 +57,9 @@ func (h *AccountHandler) GetAccountOverview(c *fiber.Ctx) error {
 	err := userCollection.FindOne(ctx, bson.M{"_id": user.UserID}).Decode(&userData)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "User not found",
-			})
+			return apperrors.NotFound("User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve user data",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve user data", err)
 	}
 
 	// Get profile data
@@ -105,6 +111,93 @@ func (h *AccountHandler) GetAccountOverview(c *fiber.Ctx) error {
 	})
 }
 
+// GetAccountActivity returns the current user's timeline of orders, reviews,
+// wishlist adds and address changes, newest first
+func (h *AccountHandler) GetAccountActivity(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	// Get user info from token
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	paging, err := parseCursorPage(c, "created_at", -1)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"user_id": user.UserID}
+	if activityType := c.Query("type"); activityType != "" {
+		if !slices.Contains(models.ActivityTypes, activityType) {
+			return apperrors.BadRequest("Invalid activity type", fmt.Errorf("type must be one of %s", strings.Join(models.ActivityTypes, ", ")))
+		}
+		filter["type"] = activityType
+	}
+
+	activityCollection := h.DB.Collections().Activities
+	findOptions := options.Find()
+	query := filter
+	if paging != nil {
+		query = paging.apply(filter, findOptions, limit)
+	} else {
+		findOptions.
+			SetSkip(int64((page - 1) * limit)).
+			SetLimit(int64(limit)).
+			SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	}
+	cursor, err := activityCollection.Find(ctx, query, findOptions)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve activity", err)
+	}
+	defer cursor.Close(ctx)
+
+	activities := []models.Activity{}
+	if err := cursor.All(ctx, &activities); err != nil {
+		return apperrors.Internal("Failed to decode activity", err)
+	}
+
+	if paging != nil {
+		n, nextCursor, err := paging.next(len(activities), limit, func(i int) interface{} { return activities[i] })
+		if err != nil {
+			return apperrors.Internal("Failed to build next cursor", err)
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Activity retrieved successfully",
+			"data":    activities[:n],
+			"meta": fiber.Map{
+				"limit":      limit,
+				"nextCursor": nextCursor,
+			},
+		})
+	}
+
+	total, err := activityCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count activity", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Activity retrieved successfully",
+		"data":    activities,
+		"pagination": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"totalItems": total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
 // GetAccountReviews retrieves all reviews by the current user
 func (h *AccountHandler) GetAccountReviews(c *fiber.Ctx) error {
 	// We can reuse the existing ReviewHandler's GetUserReviews method
@@ -116,6 +209,7 @@ func (h *AccountHandler) GetAccountReviews(c *fiber.Ctx) error {
 func (h *AccountHandler) DeleteAccountReview(c *fiber.Ctx) error {
 	// We can reuse the existing ReviewHandler's DeleteReview method
 	reviewHandler := NewReviewHandler(h.DB, h.Config)
+	reviewHandler.Storage = h.Storage
 	return reviewHandler.DeleteReview(c)
 }
 
@@ -139,10 +233,7 @@ func (h *AccountHandler) GetAccountOrders(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Set the userID param for the handler (Fiber doesn't allow setting Params directly, but you can provide a default value)
@@ -168,3 +259,294 @@ func (h *AccountHandler) UpdateAccountProfile(c *fiber.Ctx) error {
 	profileHandler := NewUserProfileHandler(h.DB, h.Config)
 	return profileHandler.UpdateProfile(c)
 }
+
+// purgedAccountCollections are the per-user collections removed outright when a
+// user deletes their account. Orders are kept for bookkeeping and anonymized instead.
+var purgedAccountCollections = []struct {
+	name       string
+	collection string
+}{
+	{"cart items", "cart_items"},
+	{"wishlists", "wishlists"},
+	{"reviews", "reviews"},
+	{"product questions", "product_questions"},
+	{"addresses", "user_addresses"},
+	{"profile", "user_profiles"},
+	{"preferences", "user_preferences"},
+	{"notifications", "notifications"},
+	{"recommendations", "recommendations"},
+	{"recommendation feedbacks", "recommendation_feedbacks"},
+	{"chat conversations", "chat_conversations"},
+	{"chat messages", "chat_messages"},
+	{"activity", "activities"},
+}
+
+// DeleteAccount lets the current user delete their own account. The user
+// record is kept but anonymized so orders still reference a valid account,
+// shipping details on past orders are scrubbed, the remaining personal data is
+// purged and every issued token is revoked.
+func (h *AccountHandler) DeleteAccount(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	var req models.DeleteAccountRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperrors.BadRequest("Invalid request body", err)
+		}
+	}
+
+	var existing models.User
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": user.UserID}).Decode(&existing); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to retrieve user data", err)
+	}
+	if existing.IsDeleted() {
+		return apperrors.NotFound("User not found")
+	}
+
+	// Local accounts must confirm with their password so a leaked token alone can't wipe the account
+	if existing.Password != "" {
+		if req.Password == "" {
+			return apperrors.BadRequest("Password is required to delete the account", nil)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(existing.Password), []byte(req.Password)); err != nil {
+			return apperrors.Forbidden("Incorrect password")
+		}
+	}
+
+	// Remember which products lost a review so their cached ratings can be dropped
+	reviewedProducts, _ := h.DB.Collections().Reviews.Distinct(ctx, "product_id", bson.M{"user_id": user.UserID})
+
+	now := time.Now()
+	_, err := h.DB.Collections().Orders.UpdateMany(ctx,
+		bson.M{"user_id": user.UserID},
+		bson.M{
+			"$set": bson.M{
+				"shipping_address.name":   "",
+				"shipping_address.street": "",
+				"shipping_address.phone":  "",
+				"updated_at":              now,
+			},
+			"$unset": bson.M{
+				"payment_info.card_number": "",
+				"payment_info.expiry_date": "",
+			},
+		},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to anonymize orders", err)
+	}
+	// Watches registered to the account can be registered by their next owner
+	if _, err := h.DB.Collections().SerialNumbers.UpdateMany(ctx,
+		bson.M{"registered_to": user.UserID},
+		bson.M{"$unset": bson.M{"registered_to": "", "registered_at": ""}},
+	); err != nil {
+		return apperrors.Internal("Failed to release registered serial numbers", err)
+	}
+
+	summary := fiber.Map{}
+	for _, p := range purgedAccountCollections {
+		res, derr := h.DB.MongoDB.Collection(p.collection).DeleteMany(ctx, bson.M{"user_id": user.UserID})
+		if derr != nil {
+			summary[p.name] = fmt.Sprintf("error: %v", derr)
+			continue
+		}
+		summary[p.name] = res.DeletedCount
+	}
+
+	// Anonymize the user last so a failure above leaves the account usable for a retry
+	_, err = h.DB.Collections().Users.UpdateOne(ctx,
+		bson.M{"_id": user.UserID},
+		bson.M{
+			"$set": bson.M{
+				"name":       "Deleted user",
+				"email":      fmt.Sprintf("deleted-%s@deleted.invalid", user.UserID.Hex()),
+				"password":   "",
+				"status":     models.UserStatusDeleted,
+				"deleted_at": now,
+				"updated_at": now,
+			},
+			"$unset": bson.M{"google_id": "", "picture": "", "phone": "", "phone_verified": ""},
+			"$inc":   bson.M{"token_version": 1},
+		},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to delete account", err)
+	}
+
+	invalidateRecommendations(ctx, h.DB, user.UserID)
+	_ = h.DB.CacheDel(ctx,
+		fmt.Sprintf("wishlist:%s", user.UserID.Hex()),
+		fmt.Sprintf("profile:%s", user.UserID.Hex()),
+		middleware.AccountStateCacheKey(user.UserID.Hex()),
+	)
+	if len(reviewedProducts) > 0 {
+		ids := make([]string, 0, len(reviewedProducts))
+		for _, raw := range reviewedProducts {
+			if id, ok := raw.(primitive.ObjectID); ok {
+				ids = append(ids, id.Hex())
+			}
+		}
+		_ = h.DB.InvalidateProductCaches(ctx, ids...)
+	}
+
+	// No before/after snapshot: the audit trail must not retain the erased personal data
+	recordAudit(c, h.DB.MongoDB, "account.self_delete", "account", user.UserID.Hex(), nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Account deleted",
+		"data": fiber.Map{
+			"summary": summary,
+		},
+	})
+}
+
+// accountExport holds every piece of personal data stored for one user
+type accountExport struct {
+	ExportedAt    time.Time                       `json:"exportedAt"`
+	User          models.User                     `json:"user"`
+	Profile       *models.UserProfile             `json:"profile,omitempty"`
+	Preferences   *models.UserPreferences         `json:"preferences,omitempty"`
+	Addresses     []models.UserAddress            `json:"addresses"`
+	Orders        []models.Order                  `json:"orders"`
+	Reviews       []models.Review                 `json:"reviews"`
+	Questions     []models.ProductQuestion        `json:"productQuestions"`
+	Wishlist      []models.Wishlist               `json:"wishlist"`
+	Cart          []models.CartItem               `json:"cart"`
+	Notifications []models.Notification           `json:"notifications"`
+	Feedback      []models.RecommendationFeedback `json:"recommendationFeedback"`
+	Activity      []models.Activity               `json:"activity"`
+	Warranties    []models.Warranty               `json:"warranties"`
+}
+
+// ExportAccountData returns all personal data held for the current user as a
+// downloadable JSON document, or with ?format=zip as an archive containing one
+// JSON file per section.
+func (h *AccountHandler) ExportAccountData(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "zip" {
+		return apperrors.BadRequest("format must be 'json' or 'zip'", nil)
+	}
+
+	export, err := h.collectAccountData(ctx, user.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to export account data", err)
+	}
+
+	filename := fmt.Sprintf("makwatches-account-%s", export.ExportedAt.Format("20060102"))
+	if format == "json" {
+		c.Attachment(filename + ".json")
+		return c.JSON(export)
+	}
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"profile.json", export.Profile},
+		{"preferences.json", export.Preferences},
+		{"addresses.json", export.Addresses},
+		{"orders.json", export.Orders},
+		{"reviews.json", export.Reviews},
+		{"product_questions.json", export.Questions},
+		{"wishlist.json", export.Wishlist},
+		{"cart.json", export.Cart},
+		{"notifications.json", export.Notifications},
+		{"recommendation_feedback.json", export.Feedback},
+		{"activity.json", export.Activity},
+		{"warranties.json", export.Warranties},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		w, err := zw.Create(section.name)
+		if err != nil {
+			return apperrors.Internal("Failed to build export archive", err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(section.data); err != nil {
+			return apperrors.Internal("Failed to build export archive", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return apperrors.Internal("Failed to build export archive", err)
+	}
+
+	c.Attachment(filename + ".zip")
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Send(buf.Bytes())
+}
+
+// collectAccountData loads every user-scoped document for the export
+func (h *AccountHandler) collectAccountData(ctx context.Context, userID primitive.ObjectID) (*accountExport, error) {
+	cols := h.DB.Collections()
+	export := &accountExport{ExportedAt: time.Now()}
+
+	if err := cols.Users.FindOne(ctx, bson.M{"_id": userID}).Decode(&export.User); err != nil {
+		return nil, err
+	}
+
+	var profile models.UserProfile
+	if err := cols.UserProfiles.FindOne(ctx, bson.M{"user_id": userID}).Decode(&profile); err == nil {
+		export.Profile = &profile
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	var prefs models.UserPreferences
+	if err := cols.UserPreferences.FindOne(ctx, bson.M{"user_id": userID}).Decode(&prefs); err == nil {
+		export.Preferences = &prefs
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	lists := []struct {
+		collection *mongo.Collection
+		dst        interface{}
+	}{
+		{cols.UserAddresses, &export.Addresses},
+		{cols.Orders, &export.Orders},
+		{cols.Reviews, &export.Reviews},
+		{cols.ProductQuestions, &export.Questions},
+		{cols.Wishlists, &export.Wishlist},
+		{cols.CartItems, &export.Cart},
+		{cols.Notifications, &export.Notifications},
+		{cols.RecFeedbacks, &export.Feedback},
+		{cols.Activities, &export.Activity},
+		{cols.Warranties, &export.Warranties},
+	}
+	for _, l := range lists {
+		cursor, err := l.collection.Find(ctx, bson.M{"user_id": userID})
+		if err != nil {
+			return nil, err
+		}
+		err = cursor.All(ctx, l.dst)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
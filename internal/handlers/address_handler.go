@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,23 +10,29 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pincode"
 )
 
 // AddressBookHandler handles address operations
 type AddressBookHandler struct {
-	DB     *database.DBClient
-	Config *config.Config
+	DB       *database.DBClient
+	Config   *config.Config
+	Pincodes *pincode.Directory
+	Events   *events.Bus // Optional; receives an address.changed event per change
 }
 
 // NewAddressBookHandler creates a new instance of AddressBookHandler
 func NewAddressBookHandler(db *database.DBClient, cfg *config.Config) *AddressBookHandler {
 	return &AddressBookHandler{
-		DB:     db,
-		Config: cfg,
+		DB:       db,
+		Config:   cfg,
+		Pincodes: newPincodeDirectory(db, cfg),
 	}
 }
 
@@ -36,32 +43,21 @@ func (h *AddressBookHandler) GetAddresses(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Find all addresses
 	addressCollection := h.DB.Collections().UserAddresses
 	cursor, err := addressCollection.Find(ctx, bson.M{"user_id": user.UserID})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve addresses",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve addresses", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode the results
 	addresses := []models.UserAddress{}
 	if err := cursor.All(ctx, &addresses); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode addresses",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode addresses", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -78,19 +74,13 @@ func (h *AddressBookHandler) GetAddress(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get address ID from parameters
 	addressID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid address ID",
-		})
+		return apperrors.BadRequest("Invalid address ID", nil)
 	}
 
 	// Find the address
@@ -103,16 +93,9 @@ func (h *AddressBookHandler) GetAddress(c *fiber.Ctx) error {
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Address not found",
-			})
+			return apperrors.NotFound("Address not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve address", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -129,20 +112,18 @@ func (h *AddressBookHandler) CreateAddress(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
-	// Parse request body
+	// Parse and validate request body
 	var req models.UserAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if h.Config.ValidatePincodes {
+		if err := checkPincode(ctx, h.Pincodes, "", req.ZipCode, req.State, req.Country); err != nil {
+			return err
+		}
 	}
 
 	// Create the new address
@@ -172,21 +153,13 @@ func (h *AddressBookHandler) CreateAddress(c *fiber.Ctx) error {
 			bson.M{"$set": bson.M{"is_default": false, "updated_at": now}},
 		)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update existing default address",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to update existing default address", err)
 		}
 	} else {
 		// Check if this is the first address, if so make it default
 		count, err := addressCollection.CountDocuments(ctx, bson.M{"user_id": user.UserID})
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to count addresses",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to count addresses", err)
 		}
 
 		if count == 0 {
@@ -197,12 +170,9 @@ func (h *AddressBookHandler) CreateAddress(c *fiber.Ctx) error {
 	// Insert the address
 	_, err := addressCollection.InsertOne(ctx, newAddress)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to create address", err)
 	}
+	h.publishChange(ctx, user.UserID, newAddress.ID, events.AddressCreated, newAddress.City)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
@@ -218,29 +188,24 @@ func (h *AddressBookHandler) UpdateAddress(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get address ID from parameters
 	addressID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid address ID",
-		})
+		return apperrors.BadRequest("Invalid address ID", nil)
 	}
 
-	// Parse request body
+	// Parse and validate request body
 	var req models.UserAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if h.Config.ValidatePincodes {
+		if err := checkPincode(ctx, h.Pincodes, "", req.ZipCode, req.State, req.Country); err != nil {
+			return err
+		}
 	}
 
 	// Prepare the update
@@ -271,11 +236,7 @@ func (h *AddressBookHandler) UpdateAddress(c *fiber.Ctx) error {
 			bson.M{"$set": bson.M{"is_default": false, "updated_at": now}},
 		)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update existing default address",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to update existing default address", err)
 		}
 		update["is_default"] = true
 	}
@@ -291,19 +252,13 @@ func (h *AddressBookHandler) UpdateAddress(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to update address", err)
 	}
 
 	if result.MatchedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Address not found or does not belong to you",
-		})
+		return apperrors.NotFound("Address not found or does not belong to you")
 	}
+	h.publishChange(ctx, user.UserID, addressID, events.AddressUpdated, req.City)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -318,19 +273,13 @@ func (h *AddressBookHandler) DeleteAddress(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get address ID from parameters
 	addressID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid address ID",
-		})
+		return apperrors.BadRequest("Invalid address ID", nil)
 	}
 
 	// Find the address to check if it's default
@@ -343,16 +292,9 @@ func (h *AddressBookHandler) DeleteAddress(c *fiber.Ctx) error {
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Address not found",
-			})
+			return apperrors.NotFound("Address not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve address", err)
 	}
 
 	// Delete the address
@@ -365,19 +307,13 @@ func (h *AddressBookHandler) DeleteAddress(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to delete address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to delete address", err)
 	}
 
 	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Address not found or does not belong to you",
-		})
+		return apperrors.NotFound("Address not found or does not belong to you")
 	}
+	h.publishChange(ctx, user.UserID, addressID, events.AddressDeleted, address.City)
 
 	// If deleted address was default, set another address as default
 	if address.IsDefault {
@@ -391,21 +327,13 @@ func (h *AddressBookHandler) DeleteAddress(c *fiber.Ctx) error {
 		)
 
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to find replacement default address",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to find replacement default address", err)
 		}
 		defer cursor.Close(ctx)
 
 		var addresses []models.UserAddress
 		if err := cursor.All(ctx, &addresses); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to decode addresses",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to decode addresses", err)
 		}
 
 		if len(addresses) > 0 {
@@ -416,11 +344,7 @@ func (h *AddressBookHandler) DeleteAddress(c *fiber.Ctx) error {
 				bson.M{"$set": bson.M{"is_default": true, "updated_at": time.Now()}},
 			)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"success": false,
-					"message": "Failed to update new default address",
-					"error":   err.Error(),
-				})
+				return apperrors.Internal("Failed to update new default address", err)
 			}
 		}
 	}
@@ -438,19 +362,13 @@ func (h *AddressBookHandler) SetDefaultAddress(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get address ID from parameters
 	addressID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid address ID",
-		})
+		return apperrors.BadRequest("Invalid address ID", nil)
 	}
 
 	now := time.Now()
@@ -466,18 +384,11 @@ func (h *AddressBookHandler) SetDefaultAddress(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to verify address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to verify address", err)
 	}
 
 	if count == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Address not found or does not belong to you",
-		})
+		return apperrors.NotFound("Address not found or does not belong to you")
 	}
 
 	// Update existing default addresses
@@ -490,11 +401,7 @@ func (h *AddressBookHandler) SetDefaultAddress(c *fiber.Ctx) error {
 		bson.M{"$set": bson.M{"is_default": false, "updated_at": now}},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update existing default address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to update existing default address", err)
 	}
 
 	// Set the new default address
@@ -508,11 +415,7 @@ func (h *AddressBookHandler) SetDefaultAddress(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to set default address",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to set default address", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -520,3 +423,13 @@ func (h *AddressBookHandler) SetDefaultAddress(c *fiber.Ctx) error {
 		"message": "Address set as default successfully",
 	})
 }
+
+// publishChange announces a change to one of the user's addresses
+func (h *AddressBookHandler) publishChange(ctx context.Context, userID, addressID primitive.ObjectID, change, city string) {
+	h.Events.Publish(ctx, events.AddressChanged, events.AddressChange{
+		UserID:    userID.Hex(),
+		AddressID: addressID.Hex(),
+		Change:    change,
+		City:      city,
+	})
+}
@@ -4,48 +4,357 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Account is a light representation for admin listing
 type Account struct {
-	ID    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name  string             `json:"name" bson:"name"`
-	Email string             `json:"email" bson:"email"`
-	Role  string             `json:"role" bson:"role"`
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name         string             `json:"name" bson:"name"`
+	Email        string             `json:"email" bson:"email"`
+	Role         string             `json:"role" bson:"role"`
+	Status       string             `json:"status" bson:"status"`
+	AuthProvider string             `json:"authProvider,omitempty" bson:"auth_provider"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"created_at"`
 }
 
 type AdminAccountHandler struct {
-	DB *database.DBClient
+	DB     *database.DBClient
+	Config *config.Config
 }
 
+// GetAllAccounts lists accounts with pagination and optional filters
+// GET /admin/accounts?page=1&limit=20&search=jane&role=admin&status=suspended
 func (h *AdminAccountHandler) GetAllAccounts(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	collection := h.DB.MongoDB.Collection("users")
-	cursor, err := collection.Find(ctx, bson.M{})
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"name": pattern},
+			bson.M{"email": pattern},
+		}
+	}
+	if role := c.Query("role"); role != "" {
+		filter["role"] = role
+	}
+	switch c.Query("status") {
+	case models.UserStatusSuspended, models.UserStatusDeleted:
+		filter["status"] = c.Query("status")
+	case models.UserStatusActive:
+		// Accounts created before statuses existed have no status field
+		filter["status"] = bson.M{"$nin": bson.A{models.UserStatusSuspended, models.UserStatusDeleted}}
+	}
+
+	collection := h.DB.Collections().Users
+	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch accounts",
-		})
+		return apperrors.Internal("Failed to count accounts", err)
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch accounts", err)
 	}
 	defer cursor.Close(ctx)
 
-	var accounts []Account
+	accounts := []Account{}
 	if err := cursor.All(ctx, &accounts); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to parse accounts",
-		})
+		return apperrors.Internal("Failed to parse accounts", err)
+	}
+	for i := range accounts {
+		if accounts[i].Status == "" {
+			accounts[i].Status = models.UserStatusActive
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Accounts retrieved successfully",
+		"data":    accounts,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// UpdateAccountRole changes a user's role to any existing role. Only admins
+// may grant or take away the admin role.
+// PATCH /admin/accounts/:id/role {"role": "staff"}
+func (h *AdminAccountHandler) UpdateAccountRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.UpdateUserRoleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	count, err := h.DB.Collections().Roles.CountDocuments(ctx, bson.M{"_id": req.Role})
+	if err != nil {
+		return apperrors.Internal("Failed to lookup role", err)
+	}
+	if count == 0 {
+		return apperrors.BadRequest(fmt.Sprintf("Role '%s' does not exist", req.Role), nil)
+	}
+
+	actor, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	if actor.Role != models.RoleAdmin {
+		if req.Role == models.RoleAdmin {
+			return apperrors.Forbidden("Only admins can grant the admin role")
+		}
+		userID, err := parseObjectID(c.Params("id"))
+		if err != nil {
+			return apperrors.BadRequest("Invalid user ID format", err)
+		}
+		var target Account
+		opts := options.FindOne().SetProjection(bson.M{"role": 1})
+		if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&target); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return apperrors.NotFound("User not found")
+			}
+			return apperrors.Internal("Failed to lookup user", err)
+		}
+		if target.Role == models.RoleAdmin {
+			return apperrors.Forbidden("Only admins can change an admin's role")
+		}
+	}
+	return h.updateAccountField(c, "role", req.Role, "Account role updated")
+}
+
+// UpdateAccountStatus suspends or reactivates a user. Suspended users cannot
+// log in and their existing tokens are rejected by the Auth middleware.
+// PATCH /admin/accounts/:id/status {"status": "suspended"}
+func (h *AdminAccountHandler) UpdateAccountStatus(c *fiber.Ctx) error {
+	var req models.UpdateUserStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperrors.BadRequest("Invalid request body", err)
+	}
+	if req.Status != models.UserStatusActive && req.Status != models.UserStatusSuspended {
+		return apperrors.BadRequest("Status must be 'active' or 'suspended'", nil)
+	}
+	return h.updateAccountField(c, "status", req.Status, "Account status updated")
+}
+
+// RevokeAccountSessions invalidates every access and refresh token issued to a user
+// POST /admin/accounts/:id/revoke-sessions
+func (h *AdminAccountHandler) RevokeAccountSessions(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	userID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid user ID format", err)
+	}
+
+	var updated Account
+	err = h.DB.Collections().Users.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$inc": bson.M{"token_version": 1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to revoke sessions", err)
 	}
 
-	return c.JSON(accounts)
+	_ = h.DB.CacheDel(ctx, middleware.AccountStateCacheKey(userID.Hex()))
+
+	recordAudit(c, h.DB.MongoDB, "account.revoke_sessions", "account", userID.Hex(), nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "All sessions revoked",
+		"data": fiber.Map{
+			"userId": userID.Hex(),
+		},
+	})
+}
+
+// UnlockAccount lifts a login lockout and forgets the account's failed logins
+// POST /admin/accounts/:id/unlock
+func (h *AdminAccountHandler) UnlockAccount(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	userID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid user ID format", err)
+	}
+
+	var account Account
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": userID}).Decode(&account); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to lookup user", err)
+	}
+	if account.Email == "" {
+		return apperrors.BadRequest("Account has no email address", nil)
+	}
+	if err := clearLoginFailures(ctx, h.DB, models.NormalizeEmail(account.Email)); err != nil {
+		return apperrors.Internal("Failed to unlock account", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "account.unlock", "account", userID.Hex(), nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Account unlocked",
+		"data": fiber.Map{
+			"userId": userID.Hex(),
+		},
+	})
+}
+
+// impersonationTTL is how long an impersonation token is valid
+const impersonationTTL = 15 * time.Minute
+
+// ImpersonateAccount issues a short-lived token that lets support staff see
+// the storefront as a customer does: their cart, orders, addresses and so on.
+// The token is flagged with the admin's ID, can't be refreshed, is refused
+// for anything but GET requests, and every request made with it is recorded
+// in the audit log. Only active customer accounts can be impersonated.
+// POST /admin/accounts/:id/impersonate
+func (h *AdminAccountHandler) ImpersonateAccount(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	admin, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	userID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid user ID format", err)
+	}
+	if userID == admin.UserID {
+		return apperrors.BadRequest("You cannot impersonate yourself", nil)
+	}
+
+	var user models.User
+	opts := options.FindOne().SetProjection(bson.M{"role": 1, "status": 1, "token_version": 1})
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to lookup user", err)
+	}
+	if middleware.IsStaff(user.Role) {
+		return apperrors.Forbidden("Staff accounts cannot be impersonated")
+	}
+	if user.IsSuspended() || user.IsDeleted() {
+		return apperrors.BadRequest("Only active accounts can be impersonated", nil)
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	token, err := h.Config.JWTKeys.Sign(jwt.MapClaims{
+		"userId":                      userID.Hex(),
+		"role":                        user.Role,
+		"tv":                          user.TokenVersion,
+		"exp":                         expiresAt.Unix(),
+		middleware.ImpersonationClaim: admin.UserID.Hex(),
+	})
+	if err != nil {
+		return apperrors.Internal("Failed to issue impersonation token", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "account.impersonate", "account", userID.Hex(), nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Impersonation token issued; it is read-only",
+		"data": fiber.Map{
+			"token":     token,
+			"userId":    userID.Hex(),
+			"readOnly":  true,
+			"expiresAt": expiresAt,
+		},
+	})
+}
+
+// updateAccountField sets a single account field, refusing to let admins demote or suspend themselves
+func (h *AdminAccountHandler) updateAccountField(c *fiber.Ctx, field, value, message string) error {
+	ctx := c.Context()
+
+	userID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid user ID format", err)
+	}
+
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok && actor.UserID == userID {
+		return apperrors.BadRequest("You cannot change your own "+field, nil)
+	}
+
+	var previous Account
+	err = h.DB.Collections().Users.FindOneAndUpdate(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{field: value, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to update account", err)
+	}
+	if previous.Status == "" {
+		previous.Status = models.UserStatusActive
+	}
+
+	updated := previous
+	switch field {
+	case "role":
+		updated.Role = value
+	case "status":
+		updated.Status = value
+	}
+
+	_ = h.DB.CacheDel(ctx, middleware.AccountStateCacheKey(userID.Hex()))
+
+	recordAudit(c, h.DB.MongoDB, "account."+field+"_change", "account", userID.Hex(), previous, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    updated,
+	})
 }
 
 // DeleteAccount removes a user and (best-effort) all associated data across collections.
@@ -57,34 +366,20 @@ func (h *AdminAccountHandler) DeleteAccount(c *fiber.Ctx) error {
 
 	rawID := c.Params("id")
 	if rawID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "User ID is required",
-		})
+		return apperrors.BadRequest("User ID is required", nil)
 	}
 	userID, err := primitive.ObjectIDFromHex(rawID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid user ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid user ID format", err)
 	}
 
 	// First ensure user exists
 	var existing Account
 	if err := h.DB.MongoDB.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&existing); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "User not found",
-			})
+			return apperrors.NotFound("User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to lookup user",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to lookup user", err)
 	}
 
 	// Build deletion tasks (collection pointer, filter description)
@@ -102,12 +397,14 @@ func (h *AdminAccountHandler) DeleteAccount(c *fiber.Ctx) error {
 		{"orders", "orders", bson.M{"user_id": userID}},
 		{"inventories", "inventories", bson.M{"user_id": userID}},
 		{"reviews", "reviews", bson.M{"user_id": userID}},
+		{"product questions", "product_questions", bson.M{"user_id": userID}},
 		{"wishlists", "wishlists", bson.M{"user_id": userID}},
 		{"chat conversations", "chat_conversations", bson.M{"user_id": userID}},
 		{"chat messages", "chat_messages", bson.M{"user_id": userID}},
 		{"notifications", "notifications", bson.M{"user_id": userID}},
 		{"recommendations", "recommendations", bson.M{"user_id": userID}},
 		{"recommendation feedbacks", "recommendation_feedbacks", bson.M{"user_id": userID}},
+		{"activity", "activities", bson.M{"user_id": userID}},
 	}
 
 	summary := fiber.Map{}
@@ -123,12 +420,15 @@ func (h *AdminAccountHandler) DeleteAccount(c *fiber.Ctx) error {
 
 	// Best-effort cache invalidation of known per-user keys.
 	// (If adding new user-scoped caches, append here.)
+	invalidateRecommendations(ctx, h.DB, userID)
 	_ = h.DB.CacheDel(ctx,
-		fmt.Sprintf("recommendations:%s", userID.Hex()),
 		fmt.Sprintf("wishlist:%s", userID.Hex()),
 		fmt.Sprintf("profile:%s", userID.Hex()),
+		middleware.AccountStateCacheKey(userID.Hex()),
 	)
 
+	recordAudit(c, h.DB.MongoDB, "account.delete", "account", userID.Hex(), existing, nil)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"message": "User and related data deleted",
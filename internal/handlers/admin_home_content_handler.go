@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// homeContentSection is one section of an import, ready to write
+type homeContentSection struct {
+	collection string
+	ids        []primitive.ObjectID
+	docs       []interface{}
+}
+
+// ExportHomeContent downloads every home page section as one JSON bundle,
+// for importing into another environment
+// GET /admin/home-content/export
+func (h *HomeContentHandler) ExportHomeContent(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	bundle := models.HomeContentBundle{ExportedAt: time.Now().UTC()}
+	var err error
+	if bundle.HeroSlides, err = h.fetchHeroSlides(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch hero slides", err)
+	}
+	if bundle.Categories, err = h.fetchCategoryCards(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch category cards", err)
+	}
+	if bundle.Collections, err = h.fetchCollectionFeatures(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch collection features", err)
+	}
+	if bundle.TechCards, err = h.fetchTechCards(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch tech showcase cards", err)
+	}
+	if bundle.Highlight, err = h.fetchTechHighlight(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch tech highlight", err)
+	}
+	if bundle.Gallery, err = h.fetchGalleryImages(ctx); err != nil {
+		return apperrors.Internal("Failed to fetch gallery images", err)
+	}
+	if bundle.Testimonials, err = h.fetchTestimonials(ctx, bson.M{}); err != nil {
+		return apperrors.Internal("Failed to fetch testimonials", err)
+	}
+
+	// Empty sections are exported as [] so importing them empties them too;
+	// null would leave them alone
+	if bundle.HeroSlides == nil {
+		bundle.HeroSlides = []models.HeroSlide{}
+	}
+	if bundle.Categories == nil {
+		bundle.Categories = []models.HomeCategoryCard{}
+	}
+	if bundle.Collections == nil {
+		bundle.Collections = []models.HomeCollectionFeature{}
+	}
+	if bundle.TechCards == nil {
+		bundle.TechCards = []models.TechShowcaseCard{}
+	}
+	if bundle.Gallery == nil {
+		bundle.Gallery = []models.GalleryImage{}
+	}
+
+	filename := "home-content-" + time.Now().Format("20060102-150405") + ".json"
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	return c.Status(fiber.StatusOK).JSON(bundle)
+}
+
+// ImportHomeContent loads a bundle made by ExportHomeContent. Every item is
+// validated before anything is written. Items are matched by ID, so
+// importing the same bundle twice changes nothing. With mode=replace (the
+// default) items of an imported section missing from the bundle are
+// deleted; mode=merge keeps them. The highlight is always replaced.
+// POST /admin/home-content/import?mode=replace|merge
+func (h *HomeContentHandler) ImportHomeContent(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	mode := c.Query("mode", "replace")
+	if mode != "replace" && mode != "merge" {
+		return apperrors.BadRequest("mode must be replace or merge", nil)
+	}
+	var bundle models.HomeContentBundle
+	if err := c.BodyParser(&bundle); err != nil {
+		return apperrors.BadRequest("Invalid payload", err)
+	}
+
+	now := time.Now().UTC()
+	problems := []validation.FieldError{}
+	var sections []homeContentSection
+	// add validates the items of a section and queues them for writing.
+	// item returns the i-th item's ID and timestamps and its validation error.
+	add := func(name, collection string, n int, item func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error)) {
+		section := homeContentSection{collection: collection, ids: []primitive.ObjectID{}}
+		seen := make(map[primitive.ObjectID]bool, n)
+		for i := 0; i < n; i++ {
+			id, createdAt, updatedAt, doc, err := item(i)
+			field := fmt.Sprintf("%s[%d]", name, i)
+			if err != nil {
+				problems = append(problems, validation.FieldError{Field: field, Rule: "invalid", Message: field + ": " + err.Error()})
+				continue
+			}
+			if id.IsZero() {
+				*id = primitive.NewObjectID()
+			}
+			if seen[*id] {
+				problems = append(problems, validation.FieldError{Field: field, Rule: "unique", Message: field + " repeats id " + id.Hex()})
+				continue
+			}
+			seen[*id] = true
+			if createdAt.IsZero() {
+				*createdAt = now
+			}
+			*updatedAt = now
+			section.ids = append(section.ids, *id)
+			section.docs = append(section.docs, doc)
+		}
+		sections = append(sections, section)
+	}
+
+	if bundle.HeroSlides != nil {
+		add("heroSlides", heroSlidesCollectionName, len(bundle.HeroSlides), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			s := &bundle.HeroSlides[i]
+			return &s.ID, &s.CreatedAt, &s.UpdatedAt, s, validateHeroSlide(s)
+		})
+	}
+	if bundle.Categories != nil {
+		add("categories", categoryCardsCollectionName, len(bundle.Categories), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			card := &bundle.Categories[i]
+			return &card.ID, &card.CreatedAt, &card.UpdatedAt, card, validateCategoryCard(card)
+		})
+	}
+	if bundle.Collections != nil {
+		add("collections", collectionFeaturesCollectionName, len(bundle.Collections), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			f := &bundle.Collections[i]
+			return &f.ID, &f.CreatedAt, &f.UpdatedAt, f, validateCollectionFeature(f)
+		})
+	}
+	if bundle.TechCards != nil {
+		add("techCards", techCardsCollectionName, len(bundle.TechCards), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			card := &bundle.TechCards[i]
+			return &card.ID, &card.CreatedAt, &card.UpdatedAt, card, validateTechCard(card)
+		})
+	}
+	if bundle.Gallery != nil {
+		add("gallery", galleryCollectionName, len(bundle.Gallery), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			img := &bundle.Gallery[i]
+			return &img.ID, &img.CreatedAt, &img.UpdatedAt, img, validateGalleryImage(img)
+		})
+	}
+	if bundle.Testimonials != nil {
+		add("testimonials", testimonialsCollectionName, len(bundle.Testimonials), func(i int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			t := &bundle.Testimonials[i]
+			return &t.ID, &t.CreatedAt, &t.UpdatedAt, t, validateTestimonial(t)
+		})
+	}
+	if bundle.Highlight != nil {
+		add("highlight", techHighlightCollectionName, 1, func(int) (*primitive.ObjectID, *time.Time, *time.Time, interface{}, error) {
+			hl := bundle.Highlight
+			return &hl.ID, &hl.CreatedAt, &hl.UpdatedAt, hl, validateHighlight(hl)
+		})
+	}
+	if len(problems) > 0 {
+		return apperrors.Validation("The bundle has invalid items; nothing was imported", problems)
+	}
+
+	counts := fiber.Map{}
+	for _, section := range sections {
+		// There is only ever one highlight
+		replace := mode == "replace" || section.collection == techHighlightCollectionName
+		if err := h.importSection(ctx, section, replace); err != nil {
+			h.clearHomeCache(ctx)
+			return apperrors.Internal("Failed to import "+section.collection, err)
+		}
+		counts[section.collection] = len(section.docs)
+	}
+	h.clearHomeCache(ctx)
+	recordAudit(c, h.DB.MongoDB, "home_content.import", "home_content", "", nil, fiber.Map{"mode": mode, "imported": counts})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Home content imported",
+		"data": fiber.Map{
+			"mode":     mode,
+			"imported": counts,
+		},
+	})
+}
+
+// importSection upserts the section's items by ID and, when replacing,
+// deletes the items the section doesn't list
+func (h *HomeContentHandler) importSection(ctx context.Context, section homeContentSection, replace bool) error {
+	coll := h.DB.MongoDB.Collection(section.collection)
+	if replace {
+		if _, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$nin": section.ids}}); err != nil {
+			return err
+		}
+	}
+	if len(section.docs) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(section.docs))
+	for i, doc := range section.docs {
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": section.ids[i]}).
+			SetReplacement(doc).
+			SetUpsert(true)
+	}
+	_, err := coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// orderExportTimeout bounds how long an export may stream
+const orderExportTimeout = 5 * time.Minute
+
+// bulkOrderStatusRequest moves several orders to the same status
+type bulkOrderStatusRequest struct {
+	OrderIDs []string `json:"orderIds" validate:"required,min=1,max=200,dive,required"`
+	orderStatusUpdate
+}
+
+// bulkOrderFailure is an order a bulk update skipped and why
+type bulkOrderFailure struct {
+	OrderID string `json:"orderId"`
+	Code    string `json:"code"`
+	Error   string `json:"error"`
+}
+
+// BulkUpdateOrderStatus moves up to 200 orders to one status. Each order is
+// checked against the order lifecycle on its own; orders that can't move are
+// reported in data.failed and don't stop the others.
+// PATCH /admin/orders/bulk-status
+func (h *OrderHandler) BulkUpdateOrderStatus(c *fiber.Ctx) error {
+	var req bulkOrderStatusRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	updated := []models.Order{}
+	failed := []bulkOrderFailure{}
+	seen := make(map[string]bool, len(req.OrderIDs))
+	for _, id := range req.OrderIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		orderID, err := primitive.ObjectIDFromHex(id)
+		if err == nil {
+			var order models.Order
+			order, err = h.applyStatusUpdate(c, orderID, req.orderStatusUpdate)
+			if err == nil {
+				updated = append(updated, order)
+				continue
+			}
+		} else {
+			err = apperrors.BadRequest("Invalid order ID format", err)
+		}
+
+		failure := bulkOrderFailure{OrderID: id, Code: apperrors.CodeInternal, Error: "Failed to update order status"}
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			failure.Code, failure.Error = appErr.Code, appErr.Message
+			if appErr.Status >= fiber.StatusInternalServerError {
+				log.Printf("[ORDERS] Bulk status update of %s failed: %v", id, err)
+			}
+		}
+		failed = append(failed, failure)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("%d of %d orders updated", len(updated), len(seen)),
+		"data": fiber.Map{
+			"updated": updated,
+			"failed":  failed,
+		},
+	})
+}
+
+// parseExportTime reads a from/to bound given as a date (YYYY-MM-DD, in the
+// server's time zone) or an RFC3339 timestamp. A date used as the upper
+// bound includes the whole day.
+func parseExportTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// paymentModeOf is the Razorpay mode an order was paid in, empty for orders
+// not paid through Razorpay
+func paymentModeOf(o models.Order) string {
+	switch {
+	case o.PaymentInfo.Method != "razorpay":
+		return ""
+	case o.PaymentInfo.Mode == "":
+		return models.PaymentModeLive
+	}
+	return o.PaymentInfo.Mode
+}
+
+// ExportOrders streams the orders placed between from and to, oldest first,
+// as CSV for accounting: one row per order with the customer, items, totals
+// and payment details.
+// GET /admin/orders/export?from=2024-04-01&to=2024-04-30
+func (h *OrderHandler) ExportOrders(c *fiber.Ctx) error {
+	createdAt := bson.M{}
+	if from := c.Query("from"); from != "" {
+		t, err := parseExportTime(from, false)
+		if err != nil {
+			return apperrors.BadRequest("Invalid from date, expected YYYY-MM-DD or RFC3339", err)
+		}
+		createdAt["$gte"] = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseExportTime(to, true)
+		if err != nil {
+			return apperrors.BadRequest("Invalid to date, expected YYYY-MM-DD or RFC3339", err)
+		}
+		createdAt["$lte"] = t
+	}
+	filter := bson.M{}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	if mode := c.Query("paymentMode"); mode != "" {
+		if mode != models.PaymentModeLive && mode != models.PaymentModeTest {
+			return apperrors.BadRequest("paymentMode must be live or test", nil)
+		}
+		filter["payment_info.mode"] = paymentModeFilter(mode)
+	}
+
+	// The stream outlives the request context, so it gets its own
+	ctx, cancel := context.WithTimeout(context.Background(), orderExportTimeout)
+	cursor, err := h.DB.Collections().Orders.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		cancel()
+		return apperrors.Internal("Failed to retrieve orders", err)
+	}
+
+	filename := "orders-" + time.Now().Format("20060102-150405") + ".csv"
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		defer cancel()
+		defer cursor.Close(ctx)
+
+		w := csv.NewWriter(bw)
+		w.Write([]string{
+			"order_id", "created_at", "status", "payment_status", "payment_method", "razorpay_order_id", "razorpay_payment_id",
+			"customer_id", "customer_name", "customer_email", "customer_phone",
+			"ship_name", "ship_street", "ship_city", "ship_state", "ship_zip", "ship_country", "ship_phone",
+			"items", "item_count", "total_inr", "currency", "exchange_rate", "charged_total", "refunded_inr",
+			"payment_mode",
+		})
+
+		customers := map[primitive.ObjectID]models.User{}
+		for cursor.Next(ctx) {
+			var o models.Order
+			if err := cursor.Decode(&o); err != nil {
+				log.Printf("[ORDERS] Export: failed to decode order: %v", err)
+				continue
+			}
+			customer, ok := customers[o.UserID]
+			if !ok {
+				opts := options.FindOne().SetProjection(bson.M{"name": 1, "email": 1, "phone": 1})
+				if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": o.UserID}, opts).Decode(&customer); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+					log.Printf("[ORDERS] Export: failed to load customer %s: %v", o.UserID.Hex(), err)
+				}
+				customers[o.UserID] = customer
+			}
+
+			items := make([]string, 0, len(o.Items))
+			count := 0
+			for _, it := range o.Items {
+				name := it.ProductName
+				if it.Size != "" {
+					name += " (" + it.Size + ")"
+				}
+				items = append(items, fmt.Sprintf("%s x%d @ %.2f", name, it.Quantity, it.Price))
+				count += it.Quantity
+			}
+			currency, rate, charged := o.Currency, o.ExchangeRate, o.ChargedTotal
+			if currency == "" {
+				// Placed before orders recorded a currency
+				currency, rate, charged = models.BaseCurrency, 1, o.Total
+			}
+			addr := o.ShippingAddress
+			w.Write([]string{
+				o.ID.Hex(), o.CreatedAt.Format(time.RFC3339), o.Status, o.PaymentStatus, o.PaymentInfo.Method,
+				o.PaymentInfo.RazorpayOrderID, o.PaymentInfo.RazorpayPaymentID,
+				o.UserID.Hex(), customer.Name, customer.Email, customer.Phone,
+				addr.Name, addr.Street, addr.City, addr.State, addr.ZipCode, addr.Country, addr.Phone,
+				strings.Join(items, "; "), strconv.Itoa(count),
+				strconv.FormatFloat(o.Total, 'f', 2, 64), currency, strconv.FormatFloat(rate, 'f', -1, 64),
+				strconv.FormatFloat(charged, 'f', 2, 64), strconv.FormatFloat(o.RefundedAmount, 'f', 2, 64),
+				paymentModeOf(o),
+			})
+			// Writes fail once the client has gone away
+			if w.Flush(); w.Error() != nil {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			log.Printf("[ORDERS] Export stopped early: %v", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
@@ -1,10 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -13,93 +13,31 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/shivam-mishra-20/mak-watches-be/internal/firebase"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pricehistory"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
 // CreateProduct adds a new product to the database (admin only)
 func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	ctx := c.Context()
 
-	// Prepare product and helper container for uploaded images
 	var product models.Product
-	uploadedImages := []string{}
 
-	// Initialize Firebase client early so we can upload files if present.
-	fbClient, err := firebase.NewFirebaseClient(ctx, h.Config.FirebaseCredentialsPath, h.Config.FirebaseBucketName)
-	useLocalFallback := false
+	// Store uploaded files first so we don't lose the stream when parsing body
+	uploadedImages, err := h.uploadProductImages(c)
 	if err != nil {
-		if h.Config.Environment == "development" || h.Config.Environment == "dev" || h.Config.Environment == "local" {
-			useLocalFallback = true
-		} else {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to initialize Firebase client",
-				"error":   err.Error(),
-			})
-		}
-	}
-
-	// If this is a multipart form, try to read files first so we don't lose the stream when parsing body
-	if form, ferr := c.MultipartForm(); ferr == nil {
-		// Accept both "images" (multiple) and "image" (single) form keys
-		files := form.File["images"]
-		if len(files) == 0 {
-			files = form.File["image"]
-		}
-		if len(files) > 0 {
-			for _, fh := range files {
-				if useLocalFallback {
-					if err := os.MkdirAll("uploads", 0o755); err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to prepare uploads directory",
-							"error":   err.Error(),
-						})
-					}
-					unique := fmt.Sprintf("%d-%s", time.Now().UnixNano(), fh.Filename)
-					destPath := filepath.Join("uploads", unique)
-					if err := c.SaveFile(fh, destPath); err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to save image",
-							"error":   err.Error(),
-						})
-					}
-					imageURL := c.BaseURL() + "/uploads/" + unique
-					uploadedImages = append(uploadedImages, imageURL)
-				} else {
-					fileReader, err := fh.Open()
-					if err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to open uploaded file",
-							"error":   err.Error(),
-						})
-					}
-					imageURL, err := fbClient.UploadFile(ctx, fileReader, fh.Filename)
-					fileReader.Close()
-					if err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to upload image to Firebase Storage",
-							"error":   err.Error(),
-						})
-					}
-					uploadedImages = append(uploadedImages, imageURL)
-				}
-			}
-		}
+		return err
 	}
 
 	// Parse product data (fields). BodyParser works for both JSON and form fields.
 	if err := c.BodyParser(&product); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product data",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product data", err)
 	}
 
 	// Handle images from multiple sources:
@@ -130,26 +68,37 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		}
 	}
 
-	// Validate required fields (Name, Description, Price, Category)
-	if product.Name == "" || product.Description == "" || product.Price <= 0 || product.Category == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Missing required product fields",
-		})
+	// Validate required fields (Name, Description, Price, Category) and ranges
+	if err := validateRequest(&product); err != nil {
+		return err
+	}
+	if product.Brand, err = resolveBrand(ctx, h.DB, product.Brand); err != nil {
+		return err
+	}
+	if err := applyProductAttributes(ctx, h.DB, &product, nil); err != nil {
+		return err
+	}
+	category, err := resolveCategory(ctx, h.DB, product.Category)
+	if err != nil {
+		return err
 	}
 
 	// (image uploads already handled above)
 
-	// Derive MainCategory/Subcategory from Category if not individually provided
-	if product.MainCategory == "" && product.Category != "" {
-		parts := strings.Split(product.Category, "/")
-		if len(parts) > 0 {
-			product.MainCategory = parts[0]
-		}
-		if len(parts) > 1 {
-			product.Subcategory = parts[1]
-		}
-	}
+	// MainCategory/Subcategory always mirror the category path
+	product.Category = category.Path
+	product.MainCategory, product.Subcategory = models.SplitCategoryPath(category.Path)
+
+	// Views and pre-orders are counted by the server only
+	product.ViewCount, product.Popularity = 0, 0
+	product.PreorderCount = 0
+	product.SEO = product.SEO.Clean()
+	product.MaxPerOrder = positiveOrNil(product.MaxPerOrder)
+	product.MaxPerCustomer = positiveOrNil(product.MaxPerCustomer)
+
+	// The ID is assigned up front because the slug is derived from it
+	product.ID = primitive.NewObjectID()
+	product.Slug = models.ProductSlug(product.Name, product.ID)
 
 	// Set timestamps
 	product.CreatedAt = time.Now()
@@ -157,21 +106,26 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 
 	// Insert product into database (ensure we store brand/mainCategory/subcategory fields as well)
 	collection := h.DB.Collections().Products
-	result, err := collection.InsertOne(ctx, product)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create product",
-			"error":   err.Error(),
-		})
+	if _, err := collection.InsertOne(ctx, product); err != nil {
+		return apperrors.Internal("Failed to create product", err)
 	}
 
-	// Get the inserted ID
-	product.ID = result.InsertedID.(primitive.ObjectID)
+	// Invalidate cached product listings
+	h.DB.InvalidateProductCaches(ctx)
 
-	// Invalidate relevant caches
-	cacheKey := "products:" + product.Category
-	h.DB.CacheDel(ctx, cacheKey)
+	// Opening balance for the stock ledger
+	if product.Stock != 0 {
+		logStockMovement(ctx, h.DB, adminStockMovement(c, models.StockMovement{
+			ProductID:  product.ID,
+			Delta:      product.Stock,
+			StockAfter: product.Stock,
+			Reason:     models.StockReasonCorrection,
+			Note:       "Initial stock",
+		}))
+	}
+	pricehistory.Record(ctx, h.DB, adminPriceChange(c, models.PriceChangeCreated), product)
+
+	recordAudit(c, h.DB.MongoDB, "product.create", "product", product.ID.Hex(), nil, product)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
@@ -180,6 +134,80 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 	})
 }
 
+// DuplicateProduct copies a product so a similar one can be created without
+// re-entering every field. The copy is named "<name> (Copy)" unless a name is
+// given, gets its own slug, starts with no stock, ratings or campaign
+// discount, and shares the original's images.
+// POST /admin/products/:id/duplicate {"name": "Seiko Presage Blue"}
+func (h *ProductHandler) DuplicateProduct(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID format", err)
+	}
+	var req struct {
+		Name string `json:"name" validate:"max=200"`
+	}
+	if len(c.Body()) > 0 {
+		if err := bindAndValidate(c, &req); err != nil {
+			return err
+		}
+	}
+
+	var product models.Product
+	if err := h.DB.Collections().Products.FindOne(ctx, bson.M{"_id": objectID}).Decode(&product); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.Internal("Failed to retrieve product", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = product.Name + " (Copy)"
+		for locale, fields := range product.Translations {
+			if translated := fields["name"]; translated != "" {
+				product.Translations[locale]["name"] = translated + " (Copy)"
+			}
+		}
+	} else {
+		// Translations of the original name no longer fit
+		for _, fields := range product.Translations {
+			delete(fields, "name")
+		}
+	}
+
+	product.ID = primitive.NewObjectID()
+	product.Name = name
+	product.Slug = models.ProductSlug(product.Name, product.ID)
+	product.Stock = 0
+	product.LowStockAlertedAt = nil
+	product.ViewCount, product.Popularity = 0, 0 // The copy hasn't been viewed
+	if product.CampaignID != nil {
+		// The discount belongs to the campaign, which doesn't target the copy
+		product.CampaignID = nil
+		product.DiscountPercentage, product.DiscountAmount = nil, nil
+		product.DiscountStartDate, product.DiscountEndDate = nil, nil
+	}
+	product.CreatedAt = time.Now()
+	product.UpdatedAt = product.CreatedAt
+
+	if _, err := h.DB.Collections().Products.InsertOne(ctx, product); err != nil {
+		return apperrors.Internal("Failed to duplicate product", err)
+	}
+	h.DB.InvalidateProductCaches(ctx)
+	pricehistory.Record(ctx, h.DB, adminPriceChange(c, models.PriceChangeCreated), product)
+	recordAudit(c, h.DB.MongoDB, "product.duplicate", "product", product.ID.Hex(), nil, fiber.Map{"source": id, "product": product})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Product duplicated successfully",
+		"data":    product,
+	})
+}
+
 // UpdateProduct updates an existing product (admin only)
 func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	fmt.Printf("[UpdateProduct] Called for ID: %s\n", c.Params("id"))
@@ -190,20 +218,13 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	// Get product ID
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Product ID is required",
-		})
+		return apperrors.BadRequest("Product ID is required", nil)
 	}
 
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product ID format", err)
 	}
 
 	// First, get the existing product to check if it exists
@@ -212,98 +233,25 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&existingProduct)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve product", err)
 	}
 
 	// Fiber handles multipart form parsing automatically
 
-	// Prepare updatedProduct and capture uploaded images first
 	var updatedProduct models.Product
-	uploadedImages := []string{}
 
-	// Initialize Firebase client for uploads (with development fallback)
-	fbClient, err := firebase.NewFirebaseClient(ctx, h.Config.FirebaseCredentialsPath, h.Config.FirebaseBucketName)
-	useLocalFallback := false
+	// Store multipart uploads first so body parsing can still work
+	uploadedImages, err := h.uploadProductImages(c)
 	if err != nil {
-		if h.Config.Environment == "development" || h.Config.Environment == "dev" || h.Config.Environment == "local" {
-			useLocalFallback = true
-		} else {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to initialize Firebase client",
-				"error":   err.Error(),
-			})
-		}
-	}
-
-	// Parse multipart uploads first so body parsing can still work
-	if form, ferr := c.MultipartForm(); ferr == nil {
-		files := form.File["images"]
-		if len(files) == 0 {
-			files = form.File["image"]
-		}
-		if len(files) > 0 {
-			for _, fh := range files {
-				if useLocalFallback {
-					if err := os.MkdirAll("uploads", 0o755); err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to prepare uploads directory",
-							"error":   err.Error(),
-						})
-					}
-					unique := fmt.Sprintf("%d-%s", time.Now().UnixNano(), fh.Filename)
-					destPath := filepath.Join("uploads", unique)
-					if err := c.SaveFile(fh, destPath); err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to save image",
-							"error":   err.Error(),
-						})
-					}
-					imageURL := c.BaseURL() + "/uploads/" + unique
-					uploadedImages = append(uploadedImages, imageURL)
-				} else {
-					fileReader, err := fh.Open()
-					if err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to open uploaded file",
-							"error":   err.Error(),
-						})
-					}
-					imageURL, err := fbClient.UploadFile(ctx, fileReader, fh.Filename)
-					fileReader.Close()
-					if err != nil {
-						return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-							"success": false,
-							"message": "Failed to upload image to Firebase Storage",
-							"error":   err.Error(),
-						})
-					}
-					uploadedImages = append(uploadedImages, imageURL)
-				}
-			}
-		}
+		return err
 	}
 
 	// Parse product data from body (works with form fields or JSON)
 	if err := c.BodyParser(&updatedProduct); err != nil {
 		fmt.Printf("[UpdateProduct] Error parsing body: %v\n", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product data",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product data", err)
 	}
 
 	// Capture images from JSON body (if provided) before we potentially overwrite them
@@ -335,6 +283,23 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	if updatedProduct.Stock < 0 {
 		updatedProduct.Stock = existingProduct.Stock
 	}
+	if updatedProduct.ReorderThreshold == nil {
+		updatedProduct.ReorderThreshold = existingProduct.ReorderThreshold
+	}
+	if updatedProduct.MaxPerOrder == nil {
+		updatedProduct.MaxPerOrder = existingProduct.MaxPerOrder
+	}
+	if updatedProduct.MaxPerCustomer == nil {
+		updatedProduct.MaxPerCustomer = existingProduct.MaxPerCustomer
+	}
+	updatedProduct.MaxPerOrder = positiveOrNil(updatedProduct.MaxPerOrder)
+	updatedProduct.MaxPerCustomer = positiveOrNil(updatedProduct.MaxPerCustomer)
+	// An empty seo object clears the overrides
+	if updatedProduct.SEO == nil {
+		updatedProduct.SEO = existingProduct.SEO
+	} else {
+		updatedProduct.SEO = updatedProduct.SEO.Clean()
+	}
 
 	// Derive Category if still blank but we have MainCategory/Subcategory
 	if updatedProduct.Category == "" && updatedProduct.MainCategory != "" {
@@ -388,14 +353,34 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 
 	// Ensure at least one image if neither images nor imageUrl were provided
 	if len(updatedProduct.Images) == 0 && updatedProduct.ImageURL == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Product must have at least one image",
-		})
+		return apperrors.BadRequest("Product must have at least one image", nil)
 	}
 
+	// Validate the merged product so partial updates can't break its invariants
+	if err := validateRequest(&updatedProduct); err != nil {
+		return err
+	}
+	// Unchanged legacy brands are kept so unrelated edits still save
+	if updatedProduct.Brand != existingProduct.Brand {
+		if updatedProduct.Brand, err = resolveBrand(ctx, h.DB, updatedProduct.Brand); err != nil {
+			return err
+		}
+	}
+	if err := applyProductAttributes(ctx, h.DB, &updatedProduct, &existingProduct); err != nil {
+		return err
+	}
+	if updatedProduct.Category != existingProduct.Category {
+		category, err := resolveCategory(ctx, h.DB, updatedProduct.Category)
+		if err != nil {
+			return err
+		}
+		updatedProduct.Category = category.Path
+	}
+	updatedProduct.MainCategory, updatedProduct.Subcategory = models.SplitCategoryPath(updatedProduct.Category)
+
 	// Keep original ID and created timestamp
 	updatedProduct.ID = objectID
+	updatedProduct.Slug = models.ProductSlug(updatedProduct.Name, objectID)
 	updatedProduct.CreatedAt = existingProduct.CreatedAt
 	updatedProduct.UpdatedAt = time.Now()
 
@@ -403,6 +388,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	update := bson.M{
 		"$set": bson.M{
 			"name":          updatedProduct.Name,
+			"slug":          updatedProduct.Slug,
 			"description":   updatedProduct.Description,
 			"brand":         updatedProduct.Brand,
 			"price":         updatedProduct.Price,
@@ -412,6 +398,16 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 			"image_url":     updatedProduct.ImageURL,
 			"images":        updatedProduct.Images,
 			"stock":         updatedProduct.Stock,
+			// low-stock alerting
+			"reorder_threshold": updatedProduct.ReorderThreshold,
+			// purchase limits
+			"max_per_order":    updatedProduct.MaxPerOrder,
+			"max_per_customer": updatedProduct.MaxPerCustomer,
+			// upcoming releases
+			"release_date":   updatedProduct.ReleaseDate,
+			"allow_preorder": updatedProduct.AllowPreorder,
+			// warranty
+			"warranty_months": updatedProduct.WarrantyMonths,
 			// filterable attributes
 			"gender":         updatedProduct.Gender,
 			"dial_color":     updatedProduct.DialColor,
@@ -421,6 +417,15 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 			"strap_material": updatedProduct.StrapMaterial,
 			"style":          updatedProduct.Style,
 			"dial_thickness": updatedProduct.DialThickness,
+			// watch specifications
+			"movement":         updatedProduct.Movement,
+			"water_resistance": updatedProduct.WaterResistance,
+			"case_size":        updatedProduct.CaseSize,
+			// shipping
+			"weight_grams": updatedProduct.WeightGrams,
+			"dimensions":   updatedProduct.Dimensions,
+			// storefront meta tags
+			"seo": updatedProduct.SEO,
 			// Discount fields (optional)
 			"discount_percentage": updatedProduct.DiscountPercentage,
 			"discount_amount":     updatedProduct.DiscountAmount,
@@ -433,20 +438,34 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
 	if err != nil {
 		fmt.Printf("[UpdateProduct] Error updating product: %v\n", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to update product", err)
 	}
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("product:%s", id)
-	h.DB.CacheDel(ctx, cacheKey)
-	categoryCacheKey := "products:" + updatedProduct.Category
-	h.DB.CacheDel(ctx, categoryCacheKey)
-	oldCategoryCacheKey := "products:" + existingProduct.Category
-	h.DB.CacheDel(ctx, oldCategoryCacheKey)
+	// Invalidate the product and every cached listing it may appear in
+	h.DB.InvalidateProductCaches(ctx, id)
+
+	// Stock set directly on the product is logged as a correction so the ledger still reconciles
+	if updatedProduct.Stock != existingProduct.Stock {
+		logStockMovement(ctx, h.DB, adminStockMovement(c, models.StockMovement{
+			ProductID:  objectID,
+			Delta:      updatedProduct.Stock - existingProduct.Stock,
+			StockAfter: updatedProduct.Stock,
+			Reason:     models.StockReasonCorrection,
+			Note:       "Stock set via product update",
+		}))
+	}
+
+	if models.PriceChanged(existingProduct, updatedProduct) {
+		change := adminPriceChange(c, models.PriceChangeUpdate)
+		change.CampaignID = existingProduct.CampaignID
+		pricehistory.Record(ctx, h.DB, change, updatedProduct)
+	}
+	if err := notify.PriceDrop(ctx, h.DB, updatedProduct, existingProduct.GetFinalPrice()); err != nil {
+		log.Printf("[NOTIFY] Failed to send price drop notifications for product %s: %v", id, err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "product.update", "product", id, existingProduct, updatedProduct)
+	h.Events.Publish(ctx, events.ProductUpdated, updatedProduct)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -468,21 +487,14 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		fmt.Printf("[DeleteProduct] Product ID missing\n")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Product ID is required",
-		})
+		return apperrors.BadRequest("Product ID is required", nil)
 	}
 
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		fmt.Printf("[DeleteProduct] Invalid product ID format: %v\n", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product ID format", err)
 	}
 
 	// First, get the product to delete (to get image URLs)
@@ -495,18 +507,11 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	deleteResult, err := collection.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
 		fmt.Printf("[DeleteProduct] Error deleting product: %v\n", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to delete product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to delete product", err)
 	}
 	if deleteResult.DeletedCount == 0 {
 		fmt.Printf("[DeleteProduct] No product deleted for ID: %s\n", id)
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Product not found or already deleted",
-		})
+		return apperrors.NotFound("Product not found or already deleted")
 	}
 
 	// After finding the product
@@ -524,39 +529,21 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 		fmt.Printf("[DeleteProduct] Deleting images: %+v\n", product.Images)
 	}
 
-	// ALWAYS delete images if product existed and had images
-	if findErr == nil && len(product.Images) > 0 {
-		// Note: Firebase Storage files are automatically managed and don't need manual deletion
-		// The files will remain in Firebase Storage but are no longer referenced in the database
-
-		// 2. Also remove local files if they exist in uploads directory
-		// This handles both local-only and S3+local scenarios
-		for _, imageURL := range product.Images {
-			// Extract filename from URL path (e.g., http://localhost:8080/uploads/1234-image.jpg -> 1234-image.jpg)
-			parts := strings.Split(imageURL, "/")
-			if len(parts) > 0 {
-				filename := parts[len(parts)-1]
-				// Delete from local filesystem
-				localPath := fmt.Sprintf("uploads/%s", filename)
-				os.Remove(localPath) // Ignore errors, best effort
-			}
-		}
+	// Delete stored images unless another product still uses them
+	if findErr == nil {
+		h.deleteProductImages(ctx, product)
 	}
 
-	// Invalidate cache
-	fmt.Printf("[DeleteProduct] Invalidating cache for product:%s\n", id)
-	h.DB.CacheDel(ctx, fmt.Sprintf("product:%s", id))
+	// Invalidate the product and every cached listing it may appear in
+	fmt.Printf("[DeleteProduct] Invalidating caches for product:%s\n", id)
+	h.DB.InvalidateProductCaches(ctx, id)
 
-	// If we found the product, also clear category cache
-	if findErr == nil && product.Category != "" {
-		fmt.Printf("[DeleteProduct] Invalidating cache for products:%s\n", product.Category)
-		h.DB.CacheDel(ctx, "products:"+product.Category)
+	if findErr == nil {
+		recordAudit(c, h.DB.MongoDB, "product.delete", "product", id, product, nil)
+	} else {
+		recordAudit(c, h.DB.MongoDB, "product.delete", "product", id, nil, nil)
 	}
 
-	// For good measure, also clear the global products cache
-	fmt.Printf("[DeleteProduct] Invalidating global products cache\n")
-	h.DB.CacheDel(ctx, "products:")
-
 	fmt.Printf("[DeleteProduct] Product deleted successfully for ID: %s\n", id)
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -566,3 +553,99 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// uploadProductImages stores files sent in the "images" (or "image") form
+// field, adds them to the media library and returns their URLs. Requests
+// that aren't multipart have none.
+func (h *ProductHandler) uploadProductImages(c *fiber.Ctx) ([]string, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, nil
+	}
+	files := form.File["images"]
+	if len(files) == 0 {
+		files = form.File["image"]
+	}
+
+	ctx := context.Background()
+	urls := make([]string, 0, len(files))
+	assets := make([]models.MediaAsset, 0, len(files))
+	for _, fh := range files {
+		asset, err := uploadFormFile(ctx, h.Storage, "products", fh)
+		if err != nil {
+			storage.DeleteURLs(ctx, h.Storage, urls)
+			var appErr *apperrors.Error
+			if errors.As(err, &appErr) {
+				return nil, appErr
+			}
+			return nil, apperrors.Internal("Failed to upload image", err)
+		}
+		urls = append(urls, asset.URL)
+		assets = append(assets, asset)
+	}
+	recordMedia(c, h.DB, assets)
+	return urls, nil
+}
+
+// deleteProductImages removes a deleted product's images from storage,
+// skipping any URL another product still references or the media library
+// holds
+func (h *ProductHandler) deleteProductImages(ctx context.Context, product models.Product) {
+	urls := append([]string{}, product.Images...)
+	if product.ImageURL != "" {
+		urls = append(urls, product.ImageURL)
+	}
+	if len(urls) == 0 {
+		return
+	}
+
+	inUse := make(map[string]bool)
+	cursor, err := h.DB.Collections().Products.Find(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{"images": bson.M{"$in": urls}},
+			bson.M{"image_url": bson.M{"$in": urls}},
+		},
+	}, options.Find().SetProjection(bson.M{"images": 1, "image_url": 1}))
+	if err != nil {
+		log.Printf("[PRODUCTS] Skipping image deletion for %s: %v", product.ID.Hex(), err)
+		return
+	}
+	var others []models.Product
+	if err := cursor.All(ctx, &others); err != nil {
+		log.Printf("[PRODUCTS] Skipping image deletion for %s: %v", product.ID.Hex(), err)
+		return
+	}
+	for _, other := range others {
+		inUse[other.ImageURL] = true
+		for _, url := range other.Images {
+			inUse[url] = true
+		}
+	}
+	// Library images are removed through the library, once nothing uses them
+	var library []models.MediaAsset
+	cursor, err = h.DB.Collections().Media.Find(ctx, bson.M{"urls": bson.M{"$in": urls}}, options.Find().SetProjection(bson.M{"urls": 1}))
+	if err == nil {
+		err = cursor.All(ctx, &library)
+	}
+	if err != nil {
+		log.Printf("[PRODUCTS] Skipping image deletion for %s: %v", product.ID.Hex(), err)
+		return
+	}
+	for _, asset := range library {
+		for _, url := range asset.URLs {
+			inUse[url] = true
+		}
+	}
+
+	unused := make([]string, 0, len(urls))
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if !inUse[url] && !seen[url] {
+			seen[url] = true
+			unused = append(unused, url)
+		}
+	}
+	if n := storage.DeleteURLs(ctx, h.Storage, unused); n > 0 {
+		log.Printf("[PRODUCTS] Deleted %d stored images of product %s", n, product.ID.Hex())
+	}
+}
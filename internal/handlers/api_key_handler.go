@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// apiKeyPrefixLength is how much of a key is kept in clear to tell keys apart
+const apiKeyPrefixLength = 11
+
+// APIKeyHandler manages the keys integrations authenticate with
+type APIKeyHandler struct {
+	DB *database.DBClient
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler
+func NewAPIKeyHandler(db *database.DBClient) *APIKeyHandler {
+	return &APIKeyHandler{DB: db}
+}
+
+// newAPIKey generates a key
+func newAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "mk_" + hex.EncodeToString(b), nil
+}
+
+// GetAPIKeys lists API keys, revoked ones included, with when each was last used
+// GET /admin/api-keys
+func (h *APIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	cursor, err := h.DB.Collections().APIKeys.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch API keys", err)
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return apperrors.Internal("Failed to decode API keys", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "API keys retrieved successfully",
+		"data":    keys,
+	})
+}
+
+// CreateAPIKey issues a key acting as the current account, which must hold
+// the permission of every scope asked for. The key is returned only in this
+// response.
+// POST /admin/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	actor, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	if !actor.APIKeyID.IsZero() {
+		return apperrors.Forbidden("API keys can't create API keys")
+	}
+	var req models.APIKeyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	for _, scope := range req.Scopes {
+		permission, ok := models.APIScopePermissions[scope]
+		if !ok {
+			continue
+		}
+		allowed, err := middleware.HasPermission(c.Context(), h.DB, actor.Role, permission)
+		if err != nil {
+			return apperrors.Internal("Failed to check permissions", err)
+		}
+		if !allowed {
+			return apperrors.Forbidden(fmt.Sprintf("The %s scope needs the %s permission", scope, permission))
+		}
+	}
+	secret, err := newAPIKey()
+	if err != nil {
+		return apperrors.Internal("Failed to generate API key", err)
+	}
+
+	key := models.APIKey{
+		ID:        primitive.NewObjectID(),
+		Name:      req.Name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		Hash:      middleware.HashAPIKey(secret),
+		Scopes:    req.Scopes,
+		OwnerID:   actor.UserID,
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.DB.Collections().APIKeys.InsertOne(c.Context(), key); err != nil {
+		return apperrors.Internal("Failed to create API key", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "api_key.create", "api_key", key.ID.Hex(), nil, key)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "API key created successfully",
+		"data":    fiber.Map{"apiKey": key, "key": secret},
+	})
+}
+
+// RevokeAPIKey stops a key from working. The key stays listed with its
+// revocation time.
+// DELETE /admin/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid API key ID", err)
+	}
+	var before models.APIKey
+	err = h.DB.Collections().APIKeys.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	).Decode(&before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		count, err := h.DB.Collections().APIKeys.CountDocuments(ctx, bson.M{"_id": objectID})
+		if err == nil && count > 0 {
+			return apperrors.Conflict("API key is already revoked")
+		}
+		return apperrors.NotFound("API key not found")
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to revoke API key", err)
+	}
+	h.DB.CacheDel(ctx, middleware.APIKeyCacheKey(before.Hash))
+
+	recordAudit(c, h.DB.MongoDB, "api_key.revoke", "api_key", objectID.Hex(), before, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "API key revoked successfully",
+	})
+}
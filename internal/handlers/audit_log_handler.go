@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"log"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const auditLogsCollectionName = "audit_logs"
+
+// auditIgnoredFields are never diffed: timestamps change on every write and secrets must not be copied
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+	"updatedAt":  true,
+	"password":   true,
+}
+
+// AuditLogHandler exposes the admin audit trail
+type AuditLogHandler struct {
+	DB *database.DBClient
+}
+
+// NewAuditLogHandler creates a new instance of AuditLogHandler
+func NewAuditLogHandler(db *database.DBClient) *AuditLogHandler {
+	return &AuditLogHandler{DB: db}
+}
+
+// GetAuditLogs lists audit entries, newest first
+// GET /admin/audit-logs?actorId=&action=&resourceType=&resourceId=&from=&to=&page=1&limit=50
+// from/to accept RFC3339 timestamps.
+func (h *AuditLogHandler) GetAuditLogs(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := bson.M{}
+	if actorID := c.Query("actorId"); actorID != "" {
+		objID, err := parseObjectID(actorID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid actorId", err)
+		}
+		filter["actor_id"] = objID
+	}
+	if action := c.Query("action"); action != "" {
+		filter["action"] = action
+	}
+	if resourceType := c.Query("resourceType"); resourceType != "" {
+		filter["resource_type"] = resourceType
+	}
+	if resourceID := c.Query("resourceId"); resourceID != "" {
+		filter["resource_id"] = resourceID
+	}
+
+	createdAt := bson.M{}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return apperrors.BadRequest("Invalid from timestamp, expected RFC3339", err)
+		}
+		createdAt["$gte"] = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return apperrors.BadRequest("Invalid to timestamp, expected RFC3339", err)
+		}
+		createdAt["$lte"] = t
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	coll := h.DB.Collections().AuditLogs
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count audit logs", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch audit logs", err)
+	}
+	defer cursor.Close(ctx)
+
+	logs := []models.AuditLog{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return apperrors.Internal("Failed to decode audit logs", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Audit logs retrieved successfully",
+		"data":    logs,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// recordAudit stores an audit entry for an admin mutation. before is nil for
+// creations and after is nil for deletions. Failures are logged and never
+// block the request that triggered them.
+func recordAudit(c *fiber.Ctx, db *mongo.Database, action, resourceType, resourceID string, before, after interface{}) {
+	entry := models.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      diffDocuments(before, after),
+		Method:       c.Method(),
+		Path:         c.Path(),
+		IP:           c.IP(),
+		UserAgent:    c.Get(fiber.HeaderUserAgent),
+		CreatedAt:    time.Now(),
+	}
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		entry.ActorID = actor.UserID
+		entry.ActorRole = actor.Role
+	}
+
+	if _, err := db.Collection(auditLogsCollectionName).InsertOne(c.Context(), entry); err != nil {
+		log.Printf("[AUDIT] Failed to record %s on %s %s: %v", action, resourceType, resourceID, err)
+	}
+}
+
+// diffDocuments returns the fields whose BSON representation differs between before and after
+func diffDocuments(before, after interface{}) map[string]models.AuditChange {
+	b := toBSONMap(before)
+	a := toBSONMap(after)
+
+	changes := map[string]models.AuditChange{}
+	for k, bv := range b {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = models.AuditChange{Before: bv, After: a[k]}
+		}
+	}
+	for k, av := range a {
+		if auditIgnoredFields[k] {
+			continue
+		}
+		if _, ok := b[k]; !ok {
+			changes[k] = models.AuditChange{Before: nil, After: av}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// toBSONMap converts a struct or map into a flat bson.M using its bson tags
+func toBSONMap(v interface{}) bson.M {
+	if v == nil {
+		return bson.M{}
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return bson.M{}
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return bson.M{}
+	}
+	out := bson.M{}
+	if err := bson.Unmarshal(raw, &out); err != nil {
+		return bson.M{}
+	}
+	// _id never changes and is already captured as ResourceID
+	delete(out, "_id")
+	return out
+}
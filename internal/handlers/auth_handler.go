@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/url"
 	"strings"
 	"time"
@@ -11,12 +12,17 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/sms"
 	"github.com/shivam-mishra-20/mak-watches-be/pkg/utils"
 )
 
@@ -25,6 +31,9 @@ type AuthHandler struct {
 	DB          *database.DBClient
 	Config      *config.Config
 	GoogleOAuth *utils.GoogleOAuth
+	SMS         sms.Provider
+	Mailer      mailer.Sender
+	Jobs        *jobs.Queue // Optional; emails are sent inline when nil
 }
 
 // NewAuthHandler creates a new instance of AuthHandler
@@ -39,6 +48,21 @@ func NewAuthHandler(db *database.DBClient, cfg *config.Config) *AuthHandler {
 		DB:          db,
 		Config:      cfg,
 		GoogleOAuth: googleOAuth,
+		SMS: sms.New(sms.Options{
+			Provider:         cfg.SMSProvider,
+			MSG91AuthKey:     cfg.MSG91AuthKey,
+			MSG91TemplateID:  cfg.MSG91TemplateID,
+			TwilioAccountSID: cfg.TwilioAccountSID,
+			TwilioAuthToken:  cfg.TwilioAuthToken,
+			TwilioFromNumber: cfg.TwilioFromNumber,
+		}),
+		Mailer: mailer.New(mailer.Options{
+			SMTPHost:     cfg.SMTPHost,
+			SMTPPort:     cfg.SMTPPort,
+			SMTPUsername: cfg.SMTPUsername,
+			SMTPPassword: cfg.SMTPPassword,
+			From:         cfg.MailFrom,
+		}),
 	}
 }
 
@@ -49,46 +73,29 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
-	// Validate required fields
-	if req.Name == "" || req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Name, email, and password are required",
-		})
+	// Normalize before validating so stray whitespace doesn't fail the email rule
+	req.Email = models.NormalizeEmail(req.Email)
+	if err := validateRequest(&req); err != nil {
+		return err
 	}
 
 	// Check if user already exists
 	collection := h.DB.Collections().Users
 	var existingUser models.User
-	err := collection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&existingUser)
+	err := collection.FindOne(ctx, bson.M{"email": req.Email}, emailLookup()).Decode(&existingUser)
 	if err == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "User with this email already exists",
-		})
+		return apperrors.Conflict("User with this email already exists").WithCode(apperrors.CodeEmailTaken)
 	} else if err != mongo.ErrNoDocuments {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Database error",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Database error", err)
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to hash password",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to hash password", err)
 	}
 
 	// Create new user
@@ -109,24 +116,22 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		UpdatedAt:    now,
 	}
 
-	// Insert user into database
+	// Insert user into database. The unique email index rejects a concurrent
+	// registration that slipped past the existence check above.
 	_, err = collection.InsertOne(ctx, newUser)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create user",
-			"error":   err.Error(),
-		})
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("User with this email already exists").WithCode(apperrors.CodeEmailTaken)
+		}
+		return apperrors.Internal("Failed to create user", err)
 	}
 
+	h.sendVerificationEmailAsync(newUser, verifyEmailURL(c))
+
 	// Generate JWT token
-	token, err := h.generateToken(newUser.ID.Hex(), newUser.Role)
+	token, err := h.generateToken(newUser.ID.Hex(), newUser.Role, newUser.TokenVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to generate token",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to generate token", err)
 	}
 
 	// Return user info and token
@@ -135,11 +140,12 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		"message": "User registered successfully",
 		"data": models.LoginResponse{
 			User: models.UserResponse{
-				ID:           newUser.ID,
-				Name:         newUser.Name,
-				Email:        newUser.Email,
-				Role:         newUser.Role,
-				AuthProvider: newUser.AuthProvider,
+				ID:            newUser.ID,
+				Name:          newUser.Name,
+				Email:         newUser.Email,
+				EmailVerified: newUser.EmailVerified,
+				Role:          newUser.Role,
+				AuthProvider:  newUser.AuthProvider,
 			},
 			Token: token,
 		},
@@ -153,74 +159,66 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 
 	// Parse request body
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
-	// Validate required fields
-	if req.Email == "" || req.Password == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Email and password are required",
-		})
+	// Normalize before validating so stray whitespace doesn't fail the email rule
+	req.Email = models.NormalizeEmail(req.Email)
+	if err := validateRequest(&req); err != nil {
+		return err
+	}
+
+	// Refuse attempts while the email is locked out or backing off
+	if err := h.checkLoginAllowed(c, req.Email); err != nil {
+		return err
+	}
+	invalidCredentials := func() error {
+		if err := h.recordLoginFailure(c, req.Email); err != nil {
+			return err
+		}
+		return apperrors.Unauthorized("Invalid email or password")
 	}
 
 	// Find user by email
 	collection := h.DB.Collections().Users
 	var user models.User
-	err := collection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
+	err := collection.FindOne(ctx, bson.M{"email": req.Email}, emailLookup()).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid email or password",
-			})
+			return invalidCredentials()
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Database error",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Database error", err)
 	}
 
 	// Check if user is using Google auth and trying to login with password
 	if user.AuthProvider == "google" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "This account uses Google authentication. Please sign in with Google.",
-		})
+		return apperrors.BadRequest("This account uses Google authentication. Please sign in with Google.", nil)
 	}
 
 	// Compare password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid email or password",
-		})
+		return invalidCredentials()
+	}
+	if err := clearLoginFailures(ctx, h.DB, req.Email); err != nil {
+		log.Printf("[AUTH] Failed to clear login failures for %s: %v", req.Email, err)
+	}
+
+	// Block suspended accounts
+	if user.IsSuspended() {
+		return apperrors.Forbidden("Account has been suspended").WithCode(apperrors.CodeAccountSuspended)
 	}
 
 	// Generate JWT token
-	token, err := h.generateToken(user.ID.Hex(), user.Role)
+	token, err := h.generateToken(user.ID.Hex(), user.Role, user.TokenVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to generate token",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to generate token", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := h.generateRefreshToken(user.ID.Hex())
+	refreshToken, err := h.generateRefreshToken(user.ID.Hex(), user.TokenVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to generate refresh token",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to generate refresh token", err)
 	}
 
 	// Set refresh token in HTTP-only cookie
@@ -239,12 +237,13 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		"message": "Login successful",
 		"data": models.LoginResponse{
 			User: models.UserResponse{
-				ID:           user.ID,
-				Name:         user.Name,
-				Email:        user.Email,
-				Role:         user.Role,
-				Picture:      user.Picture,
-				AuthProvider: user.AuthProvider,
+				ID:            user.ID,
+				Name:          user.Name,
+				Email:         user.Email,
+				EmailVerified: user.EmailVerified,
+				Role:          user.Role,
+				Picture:       user.Picture,
+				AuthProvider:  user.AuthProvider,
 			},
 			Token: token,
 		},
@@ -369,10 +368,7 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 	}
 
 	if !googleUser.VerifiedEmail {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Email not verified by Google",
-		})
+		return apperrors.BadRequest("Email not verified by Google", nil)
 	}
 
 	// Check if user exists in our database
@@ -383,73 +379,65 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 	err = collection.FindOne(ctx, bson.M{"google_id": googleUser.ID}).Decode(&user)
 	if err == mongo.ErrNoDocuments {
 		// If not found by Google ID, try by email
-		err = collection.FindOne(ctx, bson.M{"email": googleUser.Email}).Decode(&user)
+		googleUser.Email = models.NormalizeEmail(googleUser.Email)
+		err = collection.FindOne(ctx, bson.M{"email": googleUser.Email}, emailLookup()).Decode(&user)
 		if err == mongo.ErrNoDocuments {
 			// User doesn't exist, create a new one
 			now := time.Now()
 			newUser := models.User{
-				ID:           primitive.NewObjectID(),
-				Name:         googleUser.Name,
-				Email:        googleUser.Email,
-				GoogleID:     googleUser.ID,
-				Picture:      googleUser.Picture,
-				Role:         "user", // Default role
-				AuthProvider: "google",
-				CreatedAt:    now,
-				UpdatedAt:    now,
+				ID:            primitive.NewObjectID(),
+				Name:          googleUser.Name,
+				Email:         googleUser.Email,
+				EmailVerified: true, // Google only signs in verified addresses
+				GoogleID:      googleUser.ID,
+				Picture:       googleUser.Picture,
+				Role:          "user", // Default role
+				AuthProvider:  "google",
+				CreatedAt:     now,
+				UpdatedAt:     now,
 			}
 
 			_, err = collection.InsertOne(ctx, newUser)
+			if mongo.IsDuplicateKeyError(err) {
+				// A concurrent sign-in created the account first; use it
+				err = collection.FindOne(ctx, bson.M{"email": googleUser.Email}, emailLookup()).Decode(&newUser)
+			}
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"success": false,
-					"message": "Failed to create user",
-					"error":   err.Error(),
-				})
+				return apperrors.Internal("Failed to create user", err)
 			}
 
 			user = newUser
 		} else if err != nil {
 			// Database error
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Database error",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Database error", err)
 		} else {
 			// User exists but doesn't have Google ID, update it
 			if user.AuthProvider == "" || user.AuthProvider == "local" {
 				update := bson.M{
 					"$set": bson.M{
-						"google_id":     googleUser.ID,
-						"picture":       googleUser.Picture,
-						"auth_provider": "hybrid", // User has both local and Google auth
-						"updated_at":    time.Now(),
+						"google_id":      googleUser.ID,
+						"picture":        googleUser.Picture,
+						"auth_provider":  "hybrid", // User has both local and Google auth
+						"email_verified": true,
+						"updated_at":     time.Now(),
 					},
 				}
 
 				_, err = collection.UpdateOne(ctx, bson.M{"_id": user.ID}, update)
 				if err != nil {
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"success": false,
-						"message": "Failed to update user",
-						"error":   err.Error(),
-					})
+					return apperrors.Internal("Failed to update user", err)
 				}
 
 				// Update local user object
 				user.GoogleID = googleUser.ID
 				user.Picture = googleUser.Picture
 				user.AuthProvider = "hybrid"
+				user.EmailVerified = true
 			}
 		}
 	} else if err != nil {
 		// Database error
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Database error",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Database error", err)
 	} else {
 		// User found by Google ID, update picture if needed
 		if user.Picture != googleUser.Picture {
@@ -462,11 +450,7 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 
 			_, err = collection.UpdateOne(ctx, bson.M{"_id": user.ID}, update)
 			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"success": false,
-					"message": "Failed to update user picture",
-					"error":   err.Error(),
-				})
+				return apperrors.Internal("Failed to update user picture", err)
 			}
 
 			// Update local user object
@@ -474,14 +458,20 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 		}
 	}
 
+	// Block suspended accounts
+	if user.IsSuspended() {
+		frontendURL := "http://localhost:3000"
+		if h.Config.Environment == "production" {
+			frontendURL = "https://makwatches.in"
+		}
+		redirectErr := url.QueryEscape("account_suspended")
+		return c.Redirect(fmt.Sprintf("%s/auth/callback?error=%s", frontendURL, redirectErr))
+	}
+
 	// Generate JWT token
-	token, err := h.generateToken(user.ID.Hex(), user.Role)
+	token, err := h.generateToken(user.ID.Hex(), user.Role, user.TokenVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to generate token",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to generate token", err)
 	}
 
 	// Prepare frontend redirect URL with token
@@ -500,10 +490,7 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	// Get user from context (set by Auth middleware)
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	ctx := c.Context()
@@ -515,16 +502,9 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	err := collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&userData)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "User not found",
-			})
+			return apperrors.NotFound("User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Database error",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Database error", err)
 	}
 
 	// Return user info
@@ -532,12 +512,13 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 		"success": true,
 		"message": "User profile retrieved successfully",
 		"data": models.UserResponse{
-			ID:           userData.ID,
-			Name:         userData.Name,
-			Email:        userData.Email,
-			Role:         userData.Role,
-			Picture:      userData.Picture,
-			AuthProvider: userData.AuthProvider,
+			ID:            userData.ID,
+			Name:          userData.Name,
+			Email:         userData.Email,
+			EmailVerified: userData.EmailVerified,
+			Role:          userData.Role,
+			Picture:       userData.Picture,
+			AuthProvider:  userData.AuthProvider,
 		},
 	})
 }
@@ -546,33 +527,20 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	refreshToken := c.Cookies("refresh_token")
 	if refreshToken == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "No refresh token provided",
-		})
+		return apperrors.Unauthorized("No refresh token provided")
 	}
 
 	// Parse and validate the refresh token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return []byte(h.Config.JWTSecret), nil
-	})
-	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid refresh token",
-		})
+	claims, err := h.Config.JWTKeys.Parse(refreshToken)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid refresh token")
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || claims["userId"] == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid token claims",
-		})
+	if claims["userId"] == nil {
+		return apperrors.Unauthorized("Invalid token claims")
+	}
+	// Impersonation sessions end when their token expires
+	if _, ok := claims[middleware.ImpersonationClaim]; ok {
+		return apperrors.Unauthorized("Impersonation tokens cannot be refreshed")
 	}
 
 	// Normalize userId from claims to hex string
@@ -589,19 +557,13 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	}
 
 	if userIDHex == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid token userId",
-		})
+		return apperrors.Unauthorized("Invalid token userId")
 	}
 
 	// Convert to ObjectID for DB lookup
 	objID, err := primitive.ObjectIDFromHex(userIDHex)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid user ID format",
-		})
+		return apperrors.Unauthorized("Invalid user ID format")
 	}
 
 	// Optionally, check if user exists in DB
@@ -609,19 +571,23 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	var user models.User
 	err = collection.FindOne(c.Context(), bson.M{"_id": objID}).Decode(&user)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "User not found",
-		})
+		return apperrors.Unauthorized("User not found")
+	}
+
+	if user.IsSuspended() {
+		return apperrors.Forbidden("Account has been suspended").WithCode(apperrors.CodeAccountSuspended)
+	}
+
+	// Refresh tokens issued before a session revocation are no longer valid
+	tokenVersion, _ := claims["tv"].(float64)
+	if int(tokenVersion) != user.TokenVersion {
+		return apperrors.Unauthorized("Session has been revoked, please log in again")
 	}
 
 	// Issue new access token
-	accessToken, err := h.generateToken(userIDHex, user.Role)
+	accessToken, err := h.generateToken(userIDHex, user.Role, user.TokenVersion)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to generate access token",
-		})
+		return apperrors.Internal("Failed to generate access token", nil)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -631,40 +597,25 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 }
 
 // generateToken generates a JWT token
-func (h *AuthHandler) generateToken(userID, role string) (string, error) {
-	// Create token
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	// Set claims
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userId"] = userID
-	claims["role"] = role
-	claims["exp"] = time.Now().Add(time.Duration(h.Config.JWTExpirationHours) * time.Hour).Unix()
-
-	// Generate encoded token
-	tokenString, err := token.SignedString([]byte(h.Config.JWTSecret))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+func (h *AuthHandler) generateToken(userID, role string, tokenVersion int) (string, error) {
+	return h.Config.JWTKeys.Sign(jwt.MapClaims{
+		"userId": userID,
+		"role":   role,
+		"tv":     tokenVersion,
+		"exp":    time.Now().Add(time.Duration(h.Config.JWTExpirationHours) * time.Hour).Unix(),
+	})
 }
 
 // generateRefreshToken generates a refresh token
-func (h *AuthHandler) generateRefreshToken(userID string) (string, error) {
-	// Create token
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	// Set claims
-	claims := token.Claims.(jwt.MapClaims)
-	claims["userId"] = userID
-	claims["exp"] = time.Now().Add(30 * 24 * time.Hour).Unix() // 30 days
-
-	// Generate encoded token
-	tokenString, err := token.SignedString([]byte(h.Config.JWTSecret))
-	if err != nil {
-		return "", err
-	}
+func (h *AuthHandler) generateRefreshToken(userID string, tokenVersion int) (string, error) {
+	return h.Config.JWTKeys.Sign(jwt.MapClaims{
+		"userId": userID,
+		"tv":     tokenVersion,
+		"exp":    time.Now().Add(30 * 24 * time.Hour).Unix(), // 30 days
+	})
+}
 
-	return tokenString, nil
+// emailLookup returns FindOne options matching the case-insensitive users.email index
+func emailLookup() *options.FindOneOptions {
+	return options.FindOne().SetCollation(database.EmailCollation)
 }
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Authenticity checks per client IP per hour. Serials are long enough that
+// guessing them takes far more attempts than this allows.
+const (
+	authenticityChecksPerHour = 20
+	authenticityCheckWindow   = time.Hour
+)
+
+func authenticityChecksKey(ip string) string { return "authenticity:checks:" + ip }
+
+// AuthenticityHandler keeps the registry of genuine serial numbers and
+// verifies watches against it
+type AuthenticityHandler struct {
+	DB *database.DBClient
+}
+
+// NewAuthenticityHandler creates a new instance of AuthenticityHandler
+func NewAuthenticityHandler(db *database.DBClient) *AuthenticityHandler {
+	return &AuthenticityHandler{DB: db}
+}
+
+// VerifyAuthenticity checks whether a serial number is one of ours. The
+// first signed-in customer to check a genuine serial becomes its registered
+// owner, so a later buyer sees the watch has been registered before. Every
+// check is audited, counterfeit ones included. Without a cache checks are
+// not rate limited.
+// POST /verify-authenticity
+func (h *AuthenticityHandler) VerifyAuthenticity(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	if cache := h.DB.Cache; cache != nil {
+		key := authenticityChecksKey(c.IP())
+		n, err := cache.Incr(ctx, key)
+		if err == nil && n == 1 {
+			// First check of the window: give the counter its expiry
+			_ = cache.Set(ctx, key, []byte("1"), authenticityCheckWindow)
+		}
+		if n > authenticityChecksPerHour {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(authenticityCheckWindow.Seconds())))
+			return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many authenticity checks, please try again later")
+		}
+	}
+
+	var req models.AuthenticityRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	serial := models.NormalizeSerial(req.SerialNumber)
+	user, signedIn := c.Locals("user").(*middleware.TokenMetadata)
+
+	serials := h.DB.Collections().SerialNumbers
+	now := time.Now()
+	if signedIn {
+		// Only registers a serial nobody owns yet; matches nothing otherwise
+		if _, err := serials.UpdateOne(ctx,
+			bson.M{"serial": serial, "registered_to": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"registered_to": user.UserID, "registered_at": now}},
+		); err != nil {
+			return apperrors.Internal("Failed to verify serial number", err)
+		}
+	}
+	var record models.SerialNumber
+	err := serials.FindOneAndUpdate(ctx,
+		bson.M{"serial": serial},
+		bson.M{"$inc": bson.M{"verifications": 1}, "$set": bson.M{"last_verified_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		recordAudit(c, h.DB.MongoDB, "serial.verify", "serial_number", serial, nil, bson.M{"genuine": false})
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "This serial number is not in our registry of genuine watches",
+			"data":    fiber.Map{"genuine": false, "serialNumber": serial},
+		})
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to verify serial number", err)
+	}
+
+	var product models.Product
+	err = h.DB.Collections().Products.FindOne(ctx, bson.M{"_id": record.ProductID},
+		options.FindOne().SetProjection(bson.M{"name": 1, "brand": 1, "slug": 1, "image_url": 1})).Decode(&product)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Internal("Failed to fetch product", err)
+	}
+
+	registeredToYou := signedIn && record.RegisteredTo != nil && *record.RegisteredTo == user.UserID
+	recordAudit(c, h.DB.MongoDB, "serial.verify", "serial_number", record.ID.Hex(), nil,
+		bson.M{"genuine": true, "registered_to": record.RegisteredTo})
+
+	data := fiber.Map{
+		"genuine":         true,
+		"serialNumber":    record.Serial,
+		"registered":      record.RegisteredTo != nil,
+		"registeredToYou": registeredToYou,
+		"registeredAt":    record.RegisteredAt,
+	}
+	if !product.ID.IsZero() {
+		data["product"] = fiber.Map{
+			"id":       product.ID,
+			"name":     product.Name,
+			"brand":    product.Brand,
+			"slug":     product.Slug,
+			"imageUrl": product.ImageURL,
+		}
+	}
+	message := "Genuine watch"
+	if record.RegisteredTo != nil && !registeredToYou {
+		message = "Genuine watch, already registered to another owner"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    data,
+	})
+}
+
+// GetProductSerials lists a product's genuine serial numbers, newest first
+// GET /admin/products/:id/serials?page=1&limit=50
+func (h *AuthenticityHandler) GetProductSerials(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	productID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	filter := bson.M{"product_id": productID}
+	serials := h.DB.Collections().SerialNumbers
+	total, err := serials.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count serial numbers", err)
+	}
+	cursor, err := serials.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch serial numbers", err)
+	}
+	records := []models.SerialNumber{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return apperrors.Internal("Failed to decode serial numbers", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Serial numbers retrieved successfully",
+		"data":    records,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// AddProductSerials registers genuine serial numbers of a product. Serials
+// already in the registry, for this product or another, are skipped and
+// listed in the response.
+// POST /admin/products/:id/serials
+func (h *AuthenticityHandler) AddProductSerials(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	productID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	var req models.SerialNumbersRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	n, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"_id": productID})
+	if err != nil {
+		return apperrors.Internal("Failed to fetch product", err)
+	}
+	if n == 0 {
+		return apperrors.NotFound("Product not found")
+	}
+
+	now := time.Now()
+	seen := map[string]bool{}
+	var serials []string
+	var docs []interface{}
+	for _, raw := range req.Serials {
+		serial := models.NormalizeSerial(raw)
+		if serial == "" || seen[serial] {
+			continue
+		}
+		seen[serial] = true
+		serials = append(serials, serial)
+		docs = append(docs, models.SerialNumber{
+			ID:        primitive.NewObjectID(),
+			Serial:    serial,
+			ProductID: productID,
+			CreatedAt: now,
+		})
+	}
+	if len(docs) == 0 {
+		return apperrors.BadRequest("No serial numbers given", nil)
+	}
+
+	duplicates := []string{}
+	_, err = h.DB.Collections().SerialNumbers.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, we := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(we) {
+				return apperrors.Internal("Failed to add serial numbers", err)
+			}
+			duplicates = append(duplicates, serials[we.Index])
+		}
+	} else if err != nil {
+		return apperrors.Internal("Failed to add serial numbers", err)
+	}
+	added := len(docs) - len(duplicates)
+
+	if added > 0 {
+		recordAudit(c, h.DB.MongoDB, "product.serials_add", "product", id, nil, bson.M{"added": added})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("%d serial numbers added", added),
+		"data": fiber.Map{
+			"added":      added,
+			"duplicates": duplicates,
+		},
+	})
+}
+
+// DeleteProductSerial removes a serial number added by mistake
+// DELETE /admin/products/:id/serials/:serialId
+func (h *AuthenticityHandler) DeleteProductSerial(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	productID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	serialID, err := parseObjectID(c.Params("serialId"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid serial number ID", err)
+	}
+
+	var record models.SerialNumber
+	err = h.DB.Collections().SerialNumbers.FindOneAndDelete(ctx, bson.M{"_id": serialID, "product_id": productID}).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Serial number not found")
+		}
+		return apperrors.Internal("Failed to delete serial number", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "serial.delete", "serial_number", serialID.Hex(), record, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Serial number deleted successfully",
+	})
+}
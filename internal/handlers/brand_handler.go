@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// BrandHandler manages the brands products can belong to
+type BrandHandler struct {
+	DB *database.DBClient
+}
+
+// NewBrandHandler creates a new instance of BrandHandler
+func NewBrandHandler(db *database.DBClient) *BrandHandler {
+	return &BrandHandler{DB: db}
+}
+
+// resolveBrand returns the canonical name of the brand called name, ignoring
+// case and punctuation. Products and campaigns may only use existing brands.
+func resolveBrand(ctx context.Context, db *database.DBClient, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	var brand models.Brand
+	err := db.Collections().Brands.FindOne(ctx, bson.M{"slug": models.Slugify(name)}).Decode(&brand)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", apperrors.BadRequest(fmt.Sprintf("Unknown brand %q; add it under /admin/brands first", name), nil)
+		}
+		return "", apperrors.Internal("Failed to look up brand", err)
+	}
+	return brand.Name, nil
+}
+
+// brandFromRequest validates req and builds the brand it describes
+func brandFromRequest(req models.BrandRequest) (models.Brand, error) {
+	brand := models.Brand{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        models.Slugify(req.Slug),
+		LogoURL:     req.LogoURL,
+		Description: req.Description,
+	}
+	if brand.Slug == "" {
+		brand.Slug = models.Slugify(brand.Name)
+	}
+	if brand.Name == "" || brand.Slug == "" {
+		return brand, apperrors.BadRequest("Brand name must contain letters or digits", nil)
+	}
+	return brand, nil
+}
+
+// GetBrands lists all brands with how many products each has
+// GET /catalog/brands, GET /admin/brands
+func (h *BrandHandler) GetBrands(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	cursor, err := h.DB.Collections().Brands.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetCollation(database.BrandCollation))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch brands", err)
+	}
+	var brands []models.Brand
+	if err := cursor.All(ctx, &brands); err != nil {
+		return apperrors.Internal("Failed to decode brands", err)
+	}
+
+	counts, err := h.productCounts(ctx)
+	if err != nil {
+		return apperrors.Internal("Failed to count brand products", err)
+	}
+	type brandWithCount struct {
+		models.Brand
+		ProductCount int `json:"productCount"`
+	}
+	data := make([]brandWithCount, 0, len(brands))
+	for _, b := range brands {
+		data = append(data, brandWithCount{Brand: b, ProductCount: counts[b.Name]})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Brands retrieved successfully",
+		"data":    data,
+	})
+}
+
+// productCounts maps each brand name to its number of products
+func (h *BrandHandler) productCounts(ctx context.Context) (map[string]int, error) {
+	cursor, err := h.DB.Collections().Products.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"brand": bson.M{"$type": "string", "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$brand", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Name] = r.Count
+	}
+	return counts, nil
+}
+
+// CreateBrand adds a brand
+// POST /admin/brands
+func (h *BrandHandler) CreateBrand(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.BrandRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	brand, err := brandFromRequest(req)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	brand.ID = primitive.NewObjectID()
+	brand.CreatedAt = now
+	brand.UpdatedAt = now
+
+	if _, err := h.DB.Collections().Brands.InsertOne(ctx, brand); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("A brand with this name or slug already exists")
+		}
+		return apperrors.Internal("Failed to create brand", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "brand.create", "brand", brand.ID.Hex(), nil, brand)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Brand created successfully",
+		"data":    brand,
+	})
+}
+
+// UpdateBrand edits a brand. Renaming it renames the brand on its products
+// and on campaigns targeting it.
+// PUT /admin/brands/:id
+func (h *BrandHandler) UpdateBrand(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid brand ID", err)
+	}
+	var req models.BrandRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	brand, err := brandFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var before models.Brand
+	err = h.DB.Collections().Brands.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{
+		"name":        brand.Name,
+		"slug":        brand.Slug,
+		"logo_url":    brand.LogoURL,
+		"description": brand.Description,
+		"updated_at":  time.Now(),
+	}}).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Brand not found")
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("A brand with this name or slug already exists")
+		}
+		return apperrors.Internal("Failed to update brand", err)
+	}
+
+	if before.Name != brand.Name {
+		if _, err := h.DB.Collections().Products.UpdateMany(ctx,
+			bson.M{"brand": before.Name},
+			bson.M{"$set": bson.M{"brand": brand.Name, "updated_at": time.Now()}},
+		); err != nil {
+			return apperrors.Internal("Brand renamed but its products could not be updated", err)
+		}
+		if _, err := h.DB.Collections().Campaigns.UpdateMany(ctx,
+			bson.M{"target": models.CampaignTargetBrand, "brand": before.Name},
+			bson.M{"$set": bson.M{"brand": brand.Name}},
+		); err != nil {
+			return apperrors.Internal("Brand renamed but its campaigns could not be updated", err)
+		}
+		h.DB.InvalidateProductCaches(ctx)
+	}
+
+	updated, err := h.find(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	recordAudit(c, h.DB.MongoDB, "brand.update", "brand", objectID.Hex(), before, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Brand updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteBrand removes a brand no product uses any more
+// DELETE /admin/brands/:id
+func (h *BrandHandler) DeleteBrand(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid brand ID", err)
+	}
+	brand, err := h.find(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+	n, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"brand": brand.Name})
+	if err != nil {
+		return apperrors.Internal("Failed to count brand products", err)
+	}
+	if n > 0 {
+		return apperrors.Conflict(fmt.Sprintf("%d products still use this brand; move them to another brand first", n))
+	}
+
+	if _, err := h.DB.Collections().Brands.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return apperrors.Internal("Failed to delete brand", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "brand.delete", "brand", objectID.Hex(), brand, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Brand deleted successfully",
+	})
+}
+
+func (h *BrandHandler) find(ctx context.Context, filter bson.M) (models.Brand, error) {
+	var brand models.Brand
+	if err := h.DB.Collections().Brands.FindOne(ctx, filter).Decode(&brand); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return brand, apperrors.NotFound("Brand not found")
+		}
+		return brand, apperrors.Internal("Failed to retrieve brand", err)
+	}
+	return brand, nil
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// maxTrackedQueries bounds how many distinct listings the warmer counts
+const maxTrackedQueries = 500
+
+// CacheWarmer rebuilds the cached home content and the most requested first
+// pages of the product listing in the background, so the first visitor after
+// a change or an expiry doesn't pay for the queries. It warms shortly after
+// every invalidation (wire Trigger to the DB client's Invalidated) and on a
+// fixed interval, ahead of the cache TTLs.
+type CacheWarmer struct {
+	DB     *database.DBClient
+	Config *config.Config
+	// Settle is how long to wait after an invalidation before warming, so a
+	// burst of admin edits is warmed once
+	Settle time.Duration
+
+	trigger chan struct{}
+	mu      sync.Mutex
+	hits    map[string]*queryHits // Requests per listing, by cache params
+}
+
+// queryHits counts the requests for one listing
+type queryHits struct {
+	query productListQuery
+	count int
+}
+
+// NewCacheWarmer creates a warmer; Run starts it
+func NewCacheWarmer(db *database.DBClient, cfg *config.Config) *CacheWarmer {
+	return &CacheWarmer{
+		DB:      db,
+		Config:  cfg,
+		Settle:  2 * time.Second,
+		trigger: make(chan struct{}, 1),
+		hits:    map[string]*queryHits{},
+	}
+}
+
+// Trigger asks for a warm after a cache invalidation. It never blocks, and
+// invalidations arriving before the warm starts share it. Namespaces the
+// warmer doesn't rebuild are ignored.
+func (w *CacheWarmer) Trigger(namespace string) {
+	if w == nil || namespace == database.PostsCacheNamespace {
+		return
+	}
+	select {
+	case w.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Record counts a request for the first page of a listing. Listings are
+// ranked by their counts, which halve on every timed warm so the ranking
+// follows recent traffic.
+func (w *CacheWarmer) Record(q productListQuery) {
+	if w == nil {
+		return
+	}
+	key := database.CacheParams(q.params())
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if entry, ok := w.hits[key]; ok {
+		entry.count++
+		return
+	}
+	if len(w.hits) < maxTrackedQueries {
+		w.hits[key] = &queryHits{query: q, count: 1}
+	}
+}
+
+// popular returns the n most requested listings, and halves every count
+// when decay is set
+func (w *CacheWarmer) popular(n int, decay bool) []productListQuery {
+	w.mu.Lock()
+	entries := make([]*queryHits, 0, len(w.hits))
+	for key, entry := range w.hits {
+		entries = append(entries, &queryHits{query: entry.query, count: entry.count})
+		if decay {
+			if entry.count /= 2; entry.count == 0 {
+				delete(w.hits, key)
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].count > entries[j].count })
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	queries := make([]productListQuery, len(entries))
+	for i, entry := range entries {
+		queries[i] = entry.query
+	}
+	return queries
+}
+
+// Run warms on start, after every Trigger and on each interval until ctx is
+// cancelled. Home content is also warmed when a scheduled gallery image
+// appears or expires before the next interval.
+func (w *CacheWarmer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	homeTimer := time.NewTimer(interval)
+	defer homeTimer.Stop()
+	resetHome := func(next time.Duration) {
+		if !homeTimer.Stop() {
+			select {
+			case <-homeTimer.C:
+			default:
+			}
+		}
+		homeTimer.Reset(next)
+	}
+
+	// Timed warms are taken by one instance at a time; the others skip
+	// theirs and try again on their next tick. Warms after an invalidation
+	// only happen on the instance that made the change, so they aren't
+	// locked and never miss the change.
+	warm := func(locked bool, fn func(context.Context)) {
+		runCtx, cancel := context.WithTimeout(ctx, interval)
+		defer cancel()
+		if !locked {
+			fn(runCtx)
+			return
+		}
+		err := w.DB.WithLock(runCtx, "cache-warm", func(lockCtx context.Context) error {
+			fn(lockCtx)
+			return nil
+		})
+		if errors.Is(err, database.ErrLockHeld) {
+			resetHome(interval)
+		} else if err != nil && ctx.Err() == nil {
+			log.Printf("[CACHE] Failed to take the warm lock: %v", err)
+		}
+	}
+	all := func(decay bool) func(context.Context) {
+		return func(ctx context.Context) {
+			resetHome(w.warmHomeContent(ctx, interval))
+			w.warmProducts(ctx, decay)
+		}
+	}
+	home := func(ctx context.Context) {
+		resetHome(w.warmHomeContent(ctx, interval))
+	}
+
+	warm(true, all(false))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.trigger:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.Settle):
+			}
+			warm(false, all(false))
+		case <-ticker.C:
+			warm(true, all(true))
+		case <-homeTimer.C:
+			warm(true, home)
+		}
+	}
+}
+
+// warmHomeContent rebuilds the home content in every locale and returns
+// when it next needs rebuilding: the shortest time it was cached for, at
+// most interval
+func (w *CacheWarmer) warmHomeContent(ctx context.Context, interval time.Duration) time.Duration {
+	next := interval
+	home := &HomeContentHandler{DB: w.DB, Config: w.Config}
+	for _, locale := range w.Config.Locales {
+		snapshot, err := home.buildHomeContent(ctx, locale)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[CACHE] Failed to warm %s home content: %v", locale, err)
+			}
+			continue
+		}
+		if snapshot.TTL > 0 && snapshot.TTL < next {
+			next = snapshot.TTL
+		}
+	}
+	return next
+}
+
+// warmProducts rebuilds the most requested first pages of the product
+// listing
+func (w *CacheWarmer) warmProducts(ctx context.Context, decay bool) {
+	products := &ProductHandler{DB: w.DB, Config: w.Config}
+	for _, q := range w.popular(w.Config.CacheWarmQueries, decay) {
+		if _, _, err := products.loadProductPage(ctx, q); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[CACHE] Failed to warm product listing %s: %v", database.CacheParams(q.params()), err)
+			}
+			return
+		}
+	}
+}
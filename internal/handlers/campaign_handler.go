@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// CampaignHandler manages bulk discount campaigns
+type CampaignHandler struct {
+	DB        *database.DBClient
+	Scheduler *jobs.CampaignScheduler
+}
+
+// NewCampaignHandler creates a new instance of CampaignHandler
+func NewCampaignHandler(db *database.DBClient) *CampaignHandler {
+	return &CampaignHandler{DB: db, Scheduler: &jobs.CampaignScheduler{DB: db}}
+}
+
+// GetCampaigns lists campaigns, soonest start first
+// GET /admin/campaigns?status=&page=1&limit=20
+func (h *CampaignHandler) GetCampaigns(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	coll := h.DB.Collections().Campaigns
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count campaigns", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "start_date", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch campaigns", err)
+	}
+	defer cursor.Close(ctx)
+
+	campaigns := []models.Campaign{}
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return apperrors.Internal("Failed to decode campaigns", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Campaigns retrieved successfully",
+		"data":    campaigns,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetCampaign returns a single campaign
+// GET /admin/campaigns/:id
+func (h *CampaignHandler) GetCampaign(c *fiber.Ctx) error {
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid campaign ID", err)
+	}
+
+	campaign, err := h.find(c.Context(), objectID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Campaign retrieved successfully",
+		"data":    campaign,
+	})
+}
+
+// CreateCampaign schedules a new campaign. It takes effect immediately when
+// its start date has already passed.
+// POST /admin/campaigns
+func (h *CampaignHandler) CreateCampaign(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.CampaignRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	campaign, err := campaignFromRequest(ctx, h.DB, req)
+	if err != nil {
+		return err
+	}
+	if !campaign.EndDate.After(time.Now()) {
+		return apperrors.BadRequest("Campaign end date must be in the future", nil)
+	}
+
+	now := time.Now()
+	campaign.ID = primitive.NewObjectID()
+	campaign.Status = models.CampaignStatusScheduled
+	campaign.CreatedAt = now
+	campaign.UpdatedAt = now
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		campaign.CreatedBy = actor.UserID
+	}
+
+	if _, err := h.DB.Collections().Campaigns.InsertOne(ctx, campaign); err != nil {
+		return apperrors.Internal("Failed to create campaign", err)
+	}
+
+	h.sync(ctx)
+	if updated, err := h.find(ctx, campaign.ID); err == nil {
+		campaign = updated
+	}
+
+	recordAudit(c, h.DB.MongoDB, "campaign.create", "campaign", campaign.ID.Hex(), nil, campaign)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Campaign created successfully",
+		"data":    campaign,
+	})
+}
+
+// UpdateCampaign replaces a campaign's settings. Discounts it already applied
+// are removed and re-applied under the new settings. Ended campaigns are read-only.
+// PUT /admin/campaigns/:id
+func (h *CampaignHandler) UpdateCampaign(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objectID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid campaign ID", err)
+	}
+
+	var req models.CampaignRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	campaign, err := campaignFromRequest(ctx, h.DB, req)
+	if err != nil {
+		return err
+	}
+
+	existing, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if existing.Status == models.CampaignStatusEnded {
+		return apperrors.Conflict("Ended campaigns cannot be changed")
+	}
+
+	if _, err := jobs.ReleaseCampaign(ctx, h.DB, objectID); err != nil {
+		return apperrors.Internal("Failed to remove campaign discounts", err)
+	}
+
+	_, err = h.DB.Collections().Campaigns.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+		"$set": bson.M{
+			"name":           campaign.Name,
+			"description":    campaign.Description,
+			"target":         campaign.Target,
+			"category":       campaign.Category,
+			"brand":          campaign.Brand,
+			"product_ids":    campaign.ProductIDs,
+			"discount_type":  campaign.DiscountType,
+			"discount_value": campaign.DiscountValue,
+			"start_date":     campaign.StartDate,
+			"end_date":       campaign.EndDate,
+			"status":         models.CampaignStatusScheduled,
+			"applied_count":  0,
+			"updated_at":     time.Now(),
+		},
+		"$unset": bson.M{"activated_at": ""},
+	})
+	if err != nil {
+		return apperrors.Internal("Failed to update campaign", err)
+	}
+
+	h.DB.InvalidateProductCaches(ctx)
+	h.sync(ctx)
+
+	updated, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(c, h.DB.MongoDB, "campaign.update", "campaign", id, existing, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Campaign updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteCampaign removes a campaign and any discounts it applied
+// DELETE /admin/campaigns/:id
+func (h *CampaignHandler) DeleteCampaign(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objectID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid campaign ID", err)
+	}
+
+	existing, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	released, err := jobs.ReleaseCampaign(ctx, h.DB, objectID)
+	if err != nil {
+		return apperrors.Internal("Failed to remove campaign discounts", err)
+	}
+	if _, err := h.DB.Collections().Campaigns.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return apperrors.Internal("Failed to delete campaign", err)
+	}
+	if released > 0 {
+		h.DB.InvalidateProductCaches(ctx)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "campaign.delete", "campaign", id, existing, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Campaign deleted successfully",
+	})
+}
+
+// find loads a campaign by ID
+func (h *CampaignHandler) find(ctx context.Context, id primitive.ObjectID) (models.Campaign, error) {
+	var campaign models.Campaign
+	err := h.DB.Collections().Campaigns.FindOne(ctx, bson.M{"_id": id}).Decode(&campaign)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return campaign, apperrors.NotFound("Campaign not found")
+		}
+		return campaign, apperrors.Internal("Failed to fetch campaign", err)
+	}
+	return campaign, nil
+}
+
+// sync applies campaign changes now rather than on the next scheduled run.
+// Failures are logged; the scheduled job retries them.
+func (h *CampaignHandler) sync(ctx context.Context) {
+	if err := h.Scheduler.Sync(ctx); err != nil {
+		log.Printf("[CAMPAIGNS] Sync after admin change failed: %v", err)
+	}
+}
+
+// campaignFromRequest converts a validated request, checking the rules the
+// validate tags can't express
+func campaignFromRequest(ctx context.Context, db *database.DBClient, req models.CampaignRequest) (models.Campaign, error) {
+	if req.DiscountType == models.CampaignDiscountPercentage && req.DiscountValue > 100 {
+		return models.Campaign{}, apperrors.BadRequest("Percentage discounts cannot exceed 100", nil)
+	}
+
+	campaign := models.Campaign{
+		Name:          req.Name,
+		Description:   req.Description,
+		Target:        req.Target,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+	}
+	switch req.Target {
+	case models.CampaignTargetCategory:
+		category, err := resolveCategory(ctx, db, req.Category)
+		if err != nil {
+			return models.Campaign{}, err
+		}
+		campaign.Category = category.Path
+	case models.CampaignTargetBrand:
+		brand, err := resolveBrand(ctx, db, req.Brand)
+		if err != nil {
+			return models.Campaign{}, err
+		}
+		campaign.Brand = brand
+	case models.CampaignTargetProducts:
+		for _, raw := range req.ProductIDs {
+			id, err := primitive.ObjectIDFromHex(raw)
+			if err != nil {
+				return models.Campaign{}, apperrors.BadRequest("Invalid product ID: "+raw, nil)
+			}
+			campaign.ProductIDs = append(campaign.ProductIDs, id)
+		}
+	}
+	return campaign, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,12 +10,23 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
 
+// maxCartItems caps the distinct lines in a cart, which bounds the product
+// lookup GetCart runs
+const maxCartItems = 50
+
+// cartCacheKey is where a user's cart is cached. It is versioned with the
+// products cache, so carts show price changes as soon as they are made.
+func cartCacheKey(ctx context.Context, db *database.DBClient, userID primitive.ObjectID) string {
+	return db.VersionedCacheKey(ctx, database.ProductsCacheNamespace, map[string]string{"cart": userID.Hex()})
+}
+
 // CartHandler handles cart related requests
 type CartHandler struct {
 	DB     *database.DBClient
@@ -38,19 +50,13 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	if userLocals == nil {
 		fmt.Printf("[CART] AddToCart - user locals is nil, Path: %s, Method: %s, IP: %s\n", 
 			c.Path(), c.Method(), c.IP())
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found in context",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found in context")
 	}
 
 	user, ok := userLocals.(*middleware.TokenMetadata)
 	if !ok || user == nil {
 		fmt.Printf("[CART] AddToCart - user type assertion failed or user is nil, Path: %s\n", c.Path())
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - Invalid user data format",
-		})
+		return apperrors.Unauthorized("Unauthorized - Invalid user data format")
 	}
 
 	fmt.Printf("[CART] AddToCart - User authenticated: %s\n", user.UserID.Hex())
@@ -58,29 +64,18 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	// Parse request body
 	var req models.CartItemRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
 	// Validate required fields
 	if req.ProductID == "" || req.Quantity <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Product ID and quantity > 0 are required",
-		})
+		return apperrors.BadRequest("Product ID and quantity > 0 are required", nil)
 	}
 
 	// Convert product ID from string to ObjectID
 	productID, err := primitive.ObjectIDFromHex(req.ProductID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product ID format", err)
 	}
 
 	// Check if the product exists
@@ -89,24 +84,14 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	err = collection.FindOne(ctx, bson.M{"_id": productID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve product", err)
 	}
 
-	// Check if the product is in stock
-	if product.Stock < req.Quantity {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Not enough stock available",
-		})
+	// Check if the product is in stock, or can be pre-ordered
+	if _, err := checkAvailable(&product, req.Quantity, time.Now()); err != nil {
+		return err
 	}
 
 	// Check if the product (same size) is already in the cart. Size empty matches only empty.
@@ -120,7 +105,19 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 	}
 	err = cartCollection.FindOne(ctx, query).Decode(&existingCartItem)
 
+	// Limits count the product across every size in the cart
+	inCart, cartErr := cartUnits(ctx, h.DB, user.UserID, productID)
+	if cartErr != nil {
+		return apperrors.Internal("Failed to retrieve cart", cartErr)
+	}
+	if err := checkPurchaseLimits(ctx, h.DB, h.Config, user.UserID, &product, inCart+req.Quantity); err != nil {
+		return err
+	}
+
 	now := time.Now()
+	// The customer is looking at the current price, so adding to a line
+	// takes it as the line's price too
+	price := product.GetFinalPrice()
 
 	switch err {
 	case nil:
@@ -130,49 +127,46 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 			bson.M{"_id": existingCartItem.ID},
 			bson.M{
 				"$set": bson.M{
-					"quantity":   existingCartItem.Quantity + req.Quantity,
-					"updated_at": now,
+					"quantity":     existingCartItem.Quantity + req.Quantity,
+					"price_at_add": price,
+					"updated_at":   now,
 				},
 			},
 		)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update cart item",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to update cart item", err)
 		}
 	case mongo.ErrNoDocuments:
+		lines, err := cartCollection.CountDocuments(ctx, bson.M{"user_id": user.UserID})
+		if err != nil {
+			return apperrors.Internal("Failed to count cart items", err)
+		}
+		if lines >= maxCartItems {
+			return apperrors.BadRequest(fmt.Sprintf("Cart can hold at most %d different items", maxCartItems), nil)
+		}
+
 		// Add new cart item
 		cartItem := models.CartItem{
-			ID:        primitive.NewObjectID(),
-			UserID:    user.UserID,
-			ProductID: productID,
-			Size:      req.Size,
-			Quantity:  req.Quantity,
-			CreatedAt: now,
-			UpdatedAt: now,
+			ID:         primitive.NewObjectID(),
+			UserID:     user.UserID,
+			ProductID:  productID,
+			Size:       req.Size,
+			Quantity:   req.Quantity,
+			PriceAtAdd: price,
+			CreatedAt:  now,
+			UpdatedAt:  now,
 		}
 
 		_, err = cartCollection.InsertOne(ctx, cartItem)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to add product to cart",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to add product to cart", err)
 		}
 	default:
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Database error",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Database error", err)
 	}
 
 	// Invalidate cart cache
-	cacheKey := fmt.Sprintf("cart:%s", user.UserID.Hex())
-	h.DB.CacheDel(ctx, cacheKey)
+	h.DB.CacheDel(ctx, cartCacheKey(ctx, h.DB, user.UserID))
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -192,26 +186,19 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 	if userIDParam != "" {
 		userID, err = primitive.ObjectIDFromHex(userIDParam)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid user ID format",
-				"error":   err.Error(),
-			})
+			return apperrors.BadRequest("Invalid user ID format", err)
 		}
 	} else {
 		// Get user info from token
 		user, ok := c.Locals("user").(*middleware.TokenMetadata)
 		if !ok || user == nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Unauthorized - User data not found",
-			})
+			return apperrors.Unauthorized("Unauthorized - User data not found")
 		}
 		userID = user.UserID
 	}
 
 	// Check if the cart is in Redis cache
-	cacheKey := fmt.Sprintf("cart:%s", userID.Hex())
+	cacheKey := cartCacheKey(ctx, h.DB, userID)
 	var cartResponse models.CartResponse
 	err = h.DB.CacheGet(ctx, cacheKey, &cartResponse)
 	if err == nil {
@@ -223,26 +210,30 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 		})
 	}
 
-	// Find all cart items for the user
-	cartCollection := h.DB.Collections().CartItems
-	cursor, err := cartCollection.Find(ctx, bson.M{"user_id": userID})
+	// Load the cart items joined with their products in one round trip
+	collections := h.DB.Collections()
+	cursor, err := collections.CartItems.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: maxCartItems}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         collections.Products.Name(),
+			"localField":   "product_id",
+			"foreignField": "_id",
+			"as":           "product",
+		}}},
+		// Items whose product was deleted keep no product and don't count
+		{{Key: "$unwind", Value: bson.M{"path": "$product", "preserveNullAndEmptyArrays": true}}},
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve cart items",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve cart items", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Parse the results
 	var cartItems []models.CartItem
 	if err := cursor.All(ctx, &cartItems); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode cart items",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode cart items", err)
 	}
 
 	// If cart is empty
@@ -262,24 +253,25 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 		})
 	}
 
-	// Fetch product details for each cart item
-	productCollection := h.DB.Collections().Products
 	var total float64
-
+	var priceChanged bool
 	for i, item := range cartItems {
-		var product models.Product
-		err := productCollection.FindOne(ctx, bson.M{"_id": item.ProductID}).Decode(&product)
-		if err == nil {
-			cartItems[i].Product = &product
-			// Use discounted price if active
-			total += product.GetFinalPrice() * float64(item.Quantity)
+		if item.Product == nil {
+			continue
 		}
+		// Use discounted price if active
+		cartItems[i].UnitPrice = item.Product.GetFinalPrice()
+		cartItems[i].LineTotal = cartItems[i].UnitPrice * float64(item.Quantity)
+		cartItems[i].PriceChanged = item.PriceChangedFrom(cartItems[i].UnitPrice)
+		total += cartItems[i].LineTotal
+		priceChanged = priceChanged || cartItems[i].PriceChanged
 	}
 
 	// Create cart response
 	cartResponse = models.CartResponse{
-		Items: cartItems,
-		Total: total,
+		Items:        cartItems,
+		Total:        total,
+		PriceChanged: priceChanged,
 	}
 
 	// Cache the cart (expire after 30 minutes)
@@ -302,38 +294,24 @@ func (h *CartHandler) RemoveFromCart(c *fiber.Ctx) error {
 	productIDParam := c.Params("productID")
 
 	if userIDParam == "" || productIDParam == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "User ID and product ID are required",
-		})
+		return apperrors.BadRequest("User ID and product ID are required", nil)
 	}
 
 	// Convert IDs from string to ObjectID
 	userID, err := primitive.ObjectIDFromHex(userIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid user ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid user ID format", err)
 	}
 
 	productID, err := primitive.ObjectIDFromHex(productIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product ID format", err)
 	}
 
 	// Check if the user is authorized to remove this item
 	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-	if !ok || tokenUser == nil || (tokenUser.UserID != userID && tokenUser.Role != "admin") {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Not authorized to modify this cart",
-		})
+	if !ok || tokenUser == nil || (tokenUser.UserID != userID && !hasPermission(c, h.DB, models.PermissionCustomersWrite)) {
+		return apperrors.Forbidden("Not authorized to modify this cart")
 	}
 
 	// Remove the item from the cart
@@ -344,26 +322,92 @@ func (h *CartHandler) RemoveFromCart(c *fiber.Ctx) error {
 	})
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to remove item from cart",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to remove item from cart", err)
 	}
 
 	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Item not found in cart",
-		})
+		return apperrors.NotFound("Item not found in cart")
 	}
 
 	// Invalidate cart cache
-	cacheKey := fmt.Sprintf("cart:%s", userID.Hex())
-	h.DB.CacheDel(ctx, cacheKey)
+	h.DB.CacheDel(ctx, cartCacheKey(ctx, h.DB, userID))
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"message": "Item removed from cart successfully",
 	})
 }
+
+// UpdateCartItem sets the quantity of a cart line, within the product's
+// stock and purchase limits
+func (h *CartHandler) UpdateCartItem(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	userID, err := primitive.ObjectIDFromHex(c.Params("userID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid user ID format", err)
+	}
+	productID, err := primitive.ObjectIDFromHex(c.Params("productID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID format", err)
+	}
+
+	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || tokenUser == nil || (tokenUser.UserID != userID && !hasPermission(c, h.DB, models.PermissionCustomersWrite)) {
+		return apperrors.Forbidden("Not authorized to modify this cart")
+	}
+
+	var req models.CartItemUpdateRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	cartCollection := h.DB.Collections().CartItems
+	query := bson.M{"user_id": userID, "product_id": productID}
+	if req.Size != "" {
+		query["size"] = req.Size
+	} else {
+		query["size"] = bson.M{"$in": bson.A{"", nil}}
+	}
+	var line models.CartItem
+	if err := cartCollection.FindOne(ctx, query).Decode(&line); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("Item not found in cart")
+		}
+		return apperrors.Internal("Failed to retrieve cart item", err)
+	}
+
+	var product models.Product
+	if err := h.DB.Collections().Products.FindOne(ctx, bson.M{"_id": productID}).Decode(&product); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.Internal("Failed to retrieve product", err)
+	}
+	if _, err := checkAvailable(&product, req.Quantity, time.Now()); err != nil {
+		return err
+	}
+	inCart, err := cartUnits(ctx, h.DB, userID, productID)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve cart", err)
+	}
+	if err := checkPurchaseLimits(ctx, h.DB, h.Config, userID, &product, inCart-line.Quantity+req.Quantity); err != nil {
+		return err
+	}
+
+	// The line's price is left as it was added; only adding to a line takes
+	// the current price
+	_, err = cartCollection.UpdateOne(ctx, bson.M{"_id": line.ID}, bson.M{
+		"$set": bson.M{"quantity": req.Quantity, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return apperrors.Internal("Failed to update cart item", err)
+	}
+
+	h.DB.CacheDel(ctx, cartCacheKey(ctx, h.DB, userID))
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Cart item updated successfully",
+	})
+}
@@ -1,22 +1,27 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
 
-// CategoryHandler handles category management
-// Routes are mounted under /admin/categories with admin middleware.
+// CategoryHandler handles the category tree
+// Admin routes are mounted under /admin/categories with admin middleware.
 type CategoryHandler struct {
 	DB     *database.DBClient
 	Config *config.Config
@@ -27,323 +32,564 @@ func NewCategoryHandler(db *database.DBClient, cfg *config.Config) *CategoryHand
 	return &CategoryHandler{DB: db, Config: cfg}
 }
 
-// CreateCategory creates a main category (Men or Women) with optional subcategories
-// @example Request:
-// POST /admin/categories
-//
-//	{
-//	  "name": "Men",
-//	  "subcategories": ["Shirts", "Jeans"]
-//	}
-//
-// @example Response (201):
-//
-//	{
-//	  "success": true,
-//	  "message": "Category created successfully",
-//	  "data": {"id": "...","name": "Men","subcategories": [{"id": "...","name": "Shirts"}],"createdAt": "...","updatedAt": "..."}
-//	}
-func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
-	ctx := c.Context()
-	// Parse payload allowing subcategories to be either []string or []SubcategoryInput
-	var raw struct {
-		Name          string          `json:"name"`
-		Subcategories json.RawMessage `json:"subcategories"`
+// resolveCategory finds the category with the given path, e.g. "Men/Luxury",
+// ignoring case and spacing. Products and campaigns may only use existing
+// categories.
+func resolveCategory(ctx context.Context, db *database.DBClient, path string) (models.Category, error) {
+	var cat models.Category
+	path = models.CleanCategoryPath(path)
+	if path == "" {
+		return cat, nil
 	}
-	if err := c.BodyParser(&raw); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid request body", "error": err.Error()})
+	err := db.Collections().Categories.FindOne(ctx, bson.M{"path": path},
+		options.FindOne().SetCollation(database.CategoryCollation)).Decode(&cat)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return cat, apperrors.BadRequest(fmt.Sprintf("Unknown category %q; add it under /admin/categories first", path), nil)
+		}
+		return cat, apperrors.Internal("Failed to look up category", err)
 	}
+	return cat, nil
+}
 
-	if raw.Name != "Men" && raw.Name != "Women" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Category name must be 'Men' or 'Women'"})
+// renameCategoryPaths moves products and campaigns from a category path and
+// its subcategories' paths to the new path
+func renameCategoryPaths(ctx context.Context, db *database.DBClient, oldPath, newPath string) error {
+	products := db.Collections().Products
+	paths, err := products.Distinct(ctx, "category", bson.M{"category": models.CategorySubtree(oldPath)})
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		from, _ := p.(string)
+		to := newPath + strings.TrimPrefix(from, oldPath)
+		main, sub := models.SplitCategoryPath(to)
+		if _, err := products.UpdateMany(ctx, bson.M{"category": from}, bson.M{"$set": bson.M{
+			"category":      to,
+			"main_category": main,
+			"subcategory":   sub,
+			"updated_at":    time.Now(),
+		}}); err != nil {
+			return err
+		}
 	}
 
-	collection := h.DB.Collections().Categories
-
-	// Ensure category uniqueness (Men/Women only once)
-	count, err := collection.CountDocuments(ctx, bson.M{"name": raw.Name})
+	campaigns := db.Collections().Campaigns
+	filter := bson.M{"target": models.CampaignTargetCategory, "category": models.CategorySubtree(oldPath)}
+	paths, err = campaigns.Distinct(ctx, "category", filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Database error", "error": err.Error()})
-	}
-	if count > 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Category already exists"})
+		return err
+	}
+	for _, p := range paths {
+		from, _ := p.(string)
+		if _, err := campaigns.UpdateMany(ctx,
+			bson.M{"target": models.CampaignTargetCategory, "category": from},
+			bson.M{"$set": bson.M{"category": newPath + strings.TrimPrefix(from, oldPath)}},
+		); err != nil {
+			return err
+		}
 	}
 
+	db.InvalidateProductCaches(ctx)
+	return nil
+}
+
+// newCategory builds a category named name under parent, or a top-level one
+// when parent is nil
+func newCategory(parent *models.Category, name string) models.Category {
 	now := time.Now()
-	subcats := make([]models.Subcategory, 0)
-	if len(raw.Subcategories) > 0 && string(raw.Subcategories) != "null" {
-		// Try []string first
-		var names []string
-		if err := json.Unmarshal(raw.Subcategories, &names); err == nil {
-			for _, s := range names {
-				if s == "" {
-					continue
-				}
-				subcats = append(subcats, models.Subcategory{ID: primitive.NewObjectID(), Name: s})
-			}
-		} else {
-			// Try []SubcategoryInput
-			var inputs []models.SubcategoryInput
-			if err2 := json.Unmarshal(raw.Subcategories, &inputs); err2 == nil {
-				for _, in := range inputs {
-					if in.Name == "" {
-						continue
-					}
-					subcats = append(subcats, models.Subcategory{ID: primitive.NewObjectID(), Name: in.Name, ImageURL: in.ImageURL})
-				}
-			} else {
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid subcategories format"})
-			}
+	cat := models.Category{
+		ID:        primitive.NewObjectID(),
+		Name:      strings.TrimSpace(name),
+		Ancestors: []primitive.ObjectID{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	cat.Path = cat.Name
+	if parent != nil {
+		cat.ParentID = &parent.ID
+		cat.Ancestors = append(append([]primitive.ObjectID{}, parent.Ancestors...), parent.ID)
+		cat.Path = models.JoinCategoryPath(parent.Path, cat.Name)
+		cat.Depth = parent.Depth + 1
+	}
+	cat.Slug = models.Slugify(cat.Path)
+	return cat
+}
+
+func (h *CategoryHandler) find(ctx context.Context, id primitive.ObjectID) (models.Category, error) {
+	var cat models.Category
+	if err := h.DB.Collections().Categories.FindOne(ctx, bson.M{"_id": id}).Decode(&cat); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return cat, apperrors.NotFound("Category not found")
 		}
+		return cat, apperrors.Internal("Failed to retrieve category", err)
 	}
+	return cat, nil
+}
 
-	cat := models.Category{
-		ID:            primitive.NewObjectID(),
-		Name:          raw.Name,
-		Subcategories: subcats,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+// parent loads the category a new or moved category goes under; an empty ID
+// means the top level
+func (h *CategoryHandler) parent(ctx context.Context, id string) (*models.Category, error) {
+	if id == "" {
+		return nil, nil
 	}
-
-	if _, err := collection.InsertOne(ctx, cat); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to create category", "error": err.Error()})
+	objID, err := parseObjectID(id)
+	if err != nil {
+		return nil, apperrors.BadRequest("Invalid parent category id", err)
 	}
-
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Category created successfully", "data": cat})
+	parent, err := h.find(ctx, objID)
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) && appErr.Status == fiber.StatusNotFound {
+			return nil, apperrors.NotFound("Parent category not found")
+		}
+		return nil, err
+	}
+	return &parent, nil
 }
 
-// AddSubcategory adds a subcategory to an existing category
-// @example Request:
-// POST /admin/categories/:id/subcategories
-// {"name": "Shoes"}
-// @example Response (200):
-// {"success": true,"message": "Subcategory added successfully","data": {"id": "...","name": "Men","subcategories": [{"id": "...","name": "Shoes"}],"createdAt": "...","updatedAt": "..."}}
-func (h *CategoryHandler) AddSubcategory(c *fiber.Ctx) error {
-	ctx := c.Context()
-	id := c.Params("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+// subcategory checks that subID is a direct subcategory of categoryID, for
+// the routes addressing subcategories through their parent
+func (h *CategoryHandler) subcategory(ctx context.Context, categoryID, subID string) (primitive.ObjectID, error) {
+	catObj, err := parseObjectID(categoryID)
+	if err != nil {
+		return primitive.NilObjectID, apperrors.BadRequest("Invalid category id", nil)
+	}
+	subObj, err := parseObjectID(subID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category id"})
+		return primitive.NilObjectID, apperrors.BadRequest("Invalid subcategory id", nil)
 	}
+	n, err := h.DB.Collections().Categories.CountDocuments(ctx, bson.M{"_id": subObj, "parent_id": catObj})
+	if err != nil {
+		return primitive.NilObjectID, apperrors.Internal("Failed to retrieve category", err)
+	}
+	if n == 0 {
+		return primitive.NilObjectID, apperrors.NotFound("Category or subcategory not found")
+	}
+	return subObj, nil
+}
 
-	var req models.AddSubcategoryRequest
-	if err := c.BodyParser(&req); err != nil || req.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid subcategory"})
+func categoryWriteError(err error, msg string) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return apperrors.Conflict("A category with this name already exists at this level, or its slug is taken")
 	}
+	return apperrors.Internal(msg, err)
+}
 
-	collection := h.DB.Collections().Categories
+// tree loads the categories matching filter and nests them; categories
+// whose parent isn't in the result become roots
+func (h *CategoryHandler) tree(ctx context.Context, filter bson.M) ([]*models.CategoryNode, error) {
+	opts := options.Find().SetSort(bson.D{
+		{Key: "depth", Value: 1},
+		{Key: "sort_order", Value: 1},
+		{Key: "name", Value: 1},
+	})
+	cursor, err := h.DB.Collections().Categories.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var cats []models.Category
+	if err := cursor.All(ctx, &cats); err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[primitive.ObjectID]*models.CategoryNode, len(cats))
+	roots := make([]*models.CategoryNode, 0)
+	// Parents sort before their children, so each parent is already indexed
+	for _, cat := range cats {
+		node := &models.CategoryNode{Category: cat, Children: []*models.CategoryNode{}}
+		nodes[cat.ID] = node
+		if cat.ParentID != nil {
+			if parent, ok := nodes[*cat.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
 
-	subcat := models.Subcategory{ID: primitive.NewObjectID(), Name: req.Name, ImageURL: req.ImageURL}
-	update := bson.M{
-		"$push": bson.M{"subcategories": subcat},
-		"$set":  bson.M{"updated_at": time.Now()},
+// CreateCategory creates a category, optionally under a parent and with
+// subcategories
+// @example Request:
+// POST /admin/categories
+//
+//	{
+//	  "name": "Smart Watches",
+//	  "subcategories": ["Fitness", "Hybrid"]
+//	}
+//
+// @example Response (201):
+//
+//	{
+//	  "success": true,
+//	  "message": "Category created successfully",
+//	  "data": {"id": "...","name": "Smart Watches","slug": "smart-watches","path": "Smart Watches","depth": 0,"children": [{"id": "...","name": "Fitness",...}],...}
+//	}
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	var req models.CreateCategoryRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
+	return h.create(c, req)
+}
 
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	res := collection.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts)
-	var updated models.Category
-	if err := res.Decode(&updated); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category not found"})
+// AddSubcategory creates a category under an existing one
+// POST /admin/categories/:id/subcategories
+// {"name": "Automatic", "imageUrl": "https://..."}
+func (h *CategoryHandler) AddSubcategory(c *fiber.Ctx) error {
+	var req models.CreateCategoryRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Subcategory added successfully", "data": updated})
+	req.ParentID = c.Params("id")
+	return h.create(c, req)
 }
 
-// UpdateCategoryName updates a main category name (switch Men/Women)
-// PATCH /admin/categories/:id
-// {"name": "Women"}
-func (h *CategoryHandler) UpdateCategoryName(c *fiber.Ctx) error {
+func (h *CategoryHandler) create(c *fiber.Ctx, req models.CreateCategoryRequest) error {
 	ctx := c.Context()
-	id := c.Params("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+
+	parent, err := h.parent(ctx, req.ParentID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category id"})
+		return err
 	}
-
-	var req models.UpdateNameRequest
-	if err := c.BodyParser(&req); err != nil || (req.Name != "Men" && req.Name != "Women") {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Name must be 'Men' or 'Women'"})
+	cat := newCategory(parent, req.Name)
+	levels := cat.Depth + 1
+	if len(req.Subcategories) > 0 {
+		levels++
 	}
+	if levels > models.MaxCategoryDepth {
+		return apperrors.BadRequest(fmt.Sprintf("Categories can be at most %d levels deep", models.MaxCategoryDepth), nil)
+	}
+	if req.Slug != "" {
+		cat.Slug = models.Slugify(req.Slug)
+	}
+	if cat.Slug == "" {
+		return apperrors.BadRequest("Category name must contain letters or digits", nil)
+	}
+	cat.ImageURL = req.ImageURL
+	cat.SortOrder = req.SortOrder
+	cat.SEO = req.SEO.Clean()
 
 	collection := h.DB.Collections().Categories
+	if _, err := collection.InsertOne(ctx, cat); err != nil {
+		return categoryWriteError(err, "Failed to create category")
+	}
 
-	update := bson.M{"$set": bson.M{"name": req.Name, "updated_at": time.Now()}}
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	res := collection.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update, opts)
-	var updated models.Category
-	if err := res.Decode(&updated); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category not found"})
+	node := models.CategoryNode{Category: cat, Children: []*models.CategoryNode{}}
+	for i, in := range req.Subcategories {
+		child := newCategory(&cat, in.Name)
+		child.ImageURL = in.ImageURL
+		child.SortOrder = i
+		if _, err := collection.InsertOne(ctx, child); err != nil {
+			return categoryWriteError(err, "Category created but its subcategories could not be")
+		}
+		node.Children = append(node.Children, &models.CategoryNode{Category: child, Children: []*models.CategoryNode{}})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Category updated successfully", "data": updated})
+	recordAudit(c, h.DB.MongoDB, "category.create", "category", cat.ID.Hex(), nil, node)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "message": "Category created successfully", "data": node})
 }
 
-// UpdateSubcategoryName updates a subcategory name
+// UpdateCategory renames, moves or edits a category. Renaming or moving it
+// updates the paths of its subcategories and of the products and campaigns
+// using them.
+// PATCH /admin/categories/:id
+// {"name": "Luxury", "parentId": "..."}
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	objID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid category id", nil)
+	}
+	var req models.UpdateCategoryRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	return h.update(c, objID, req)
+}
+
+// UpdateSubcategory edits a subcategory addressed through its parent
 // PATCH /admin/categories/:categoryId/subcategories/:subId
 // {"name": "Sneakers"}
-func (h *CategoryHandler) UpdateSubcategoryName(c *fiber.Ctx) error {
+func (h *CategoryHandler) UpdateSubcategory(c *fiber.Ctx) error {
+	subObj, err := h.subcategory(c.Context(), c.Params("categoryId"), c.Params("subId"))
+	if err != nil {
+		return err
+	}
+	var req models.UpdateCategoryRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	return h.update(c, subObj, req)
+}
+
+func (h *CategoryHandler) update(c *fiber.Ctx, id primitive.ObjectID, req models.UpdateCategoryRequest) error {
 	ctx := c.Context()
-	categoryID := c.Params("categoryId")
-	subID := c.Params("subId")
 
-	catObj, err := primitive.ObjectIDFromHex(categoryID)
+	before, err := h.find(ctx, id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category id"})
+		return err
 	}
-	subObj, err := primitive.ObjectIDFromHex(subID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid subcategory id"})
+
+	var parent *models.Category
+	if req.ParentID != nil {
+		if parent, err = h.parent(ctx, *req.ParentID); err != nil {
+			return err
+		}
+		if parent != nil && (parent.ID == id || containsObjectID(parent.Ancestors, id)) {
+			return apperrors.BadRequest("A category can't be moved under itself or its subcategories", nil)
+		}
+	} else if before.ParentID != nil {
+		if parent, err = h.parent(ctx, before.ParentID.Hex()); err != nil {
+			return err
+		}
+	}
+	name := before.Name
+	if req.Name != nil {
+		name = *req.Name
 	}
 
-	// Accept payloads to update name and/or imageUrl
-	var req models.UpdateSubcategoryRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid payload"})
+	placed := newCategory(parent, name)
+	after := before
+	after.Name, after.ParentID, after.Ancestors, after.Path, after.Depth =
+		placed.Name, placed.ParentID, placed.Ancestors, placed.Path, placed.Depth
+	after.UpdatedAt = placed.UpdatedAt
+	// Slugs derived from the path follow it; custom slugs are kept
+	if req.Slug != nil {
+		after.Slug = models.Slugify(*req.Slug)
+	} else if before.Slug == models.Slugify(before.Path) {
+		after.Slug = placed.Slug
+	}
+	if after.Slug == "" {
+		after.Slug = placed.Slug
+	}
+	if req.ImageURL != nil {
+		after.ImageURL = *req.ImageURL
+	}
+	if req.SortOrder != nil {
+		after.SortOrder = *req.SortOrder
 	}
-	if (req.Name == nil || *req.Name == "") && req.ImageURL == nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Nothing to update"})
+	if req.SEO != nil {
+		after.SEO = req.SEO.Clean()
+	}
+
+	if after.Depth >= models.MaxCategoryDepth {
+		return apperrors.BadRequest(fmt.Sprintf("Categories can be at most %d levels deep", models.MaxCategoryDepth), nil)
 	}
 
 	collection := h.DB.Collections().Categories
+	var descendants []models.Category
+	if after.Path != before.Path {
+		cursor, err := collection.Find(ctx, bson.M{"ancestors": id})
+		if err != nil {
+			return apperrors.Internal("Failed to fetch subcategories", err)
+		}
+		if err := cursor.All(ctx, &descendants); err != nil {
+			return apperrors.Internal("Failed to decode subcategories", err)
+		}
+		for _, d := range descendants {
+			if after.Depth+d.Depth-before.Depth >= models.MaxCategoryDepth {
+				return apperrors.BadRequest(fmt.Sprintf("Categories can be at most %d levels deep", models.MaxCategoryDepth), nil)
+			}
+		}
+	}
 
-	filter := bson.M{"_id": catObj, "subcategories._id": subObj}
-	set := bson.M{"updated_at": time.Now()}
-	if req.Name != nil && *req.Name != "" {
-		set["subcategories.$.name"] = *req.Name
+	if _, err := collection.ReplaceOne(ctx, bson.M{"_id": id}, after); err != nil {
+		return categoryWriteError(err, "Failed to update category")
 	}
-	if req.ImageURL != nil {
-		// Allow clearing image when empty string provided
-		if *req.ImageURL == "" {
-			set["subcategories.$.image_url"] = nil
-		} else {
-			set["subcategories.$.image_url"] = *req.ImageURL
+
+	if after.Path != before.Path {
+		for _, d := range descendants {
+			// Keep the part of the subtree below this category
+			below := d.Ancestors[before.Depth+1:]
+			ancestors := append(append(append([]primitive.ObjectID{}, after.Ancestors...), id), below...)
+			path := after.Path + strings.TrimPrefix(d.Path, before.Path)
+			set := bson.M{
+				"ancestors":  ancestors,
+				"path":       path,
+				"depth":      len(ancestors),
+				"updated_at": after.UpdatedAt,
+			}
+			if d.Slug == models.Slugify(d.Path) {
+				set["slug"] = models.Slugify(path)
+			}
+			if _, err := collection.UpdateOne(ctx, bson.M{"_id": d.ID}, bson.M{"$set": set}); err != nil {
+				return categoryWriteError(err, "Category updated but its subcategories could not be")
+			}
+		}
+		if err := renameCategoryPaths(ctx, h.DB, before.Path, after.Path); err != nil {
+			return apperrors.Internal("Category updated but its products could not be", err)
 		}
 	}
-	update := bson.M{"$set": set}
 
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	res := collection.FindOneAndUpdate(ctx, filter, update, opts)
-	var updated models.Category
-	if err := res.Decode(&updated); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category or subcategory not found"})
-	}
+	recordAudit(c, h.DB.MongoDB, "category.update", "category", id.Hex(), before, after)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Category updated successfully", "data": after})
+}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Subcategory updated successfully", "data": updated})
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
 }
 
-// DeleteCategory deletes a category entirely
+// DeleteCategory deletes a category that has no subcategories or products
 // DELETE /admin/categories/:id
 func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
-	ctx := c.Context()
-	id := c.Params("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+	objID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category id"})
+		return apperrors.BadRequest("Invalid category id", nil)
 	}
+	return h.delete(c, objID)
+}
 
-	collection := h.DB.Collections().Categories
-	res, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
+// DeleteSubcategory deletes a subcategory addressed through its parent
+// DELETE /admin/categories/:categoryId/subcategories/:subId
+func (h *CategoryHandler) DeleteSubcategory(c *fiber.Ctx) error {
+	subObj, err := h.subcategory(c.Context(), c.Params("categoryId"), c.Params("subId"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to delete category", "error": err.Error()})
+		return err
 	}
-	if res.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category not found"})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Category deleted successfully"})
+	return h.delete(c, subObj)
 }
 
-// DeleteSubcategory removes a subcategory from a category
-// DELETE /admin/categories/:categoryId/subcategories/:subId
-func (h *CategoryHandler) DeleteSubcategory(c *fiber.Ctx) error {
+func (h *CategoryHandler) delete(c *fiber.Ctx, id primitive.ObjectID) error {
 	ctx := c.Context()
-	categoryID := c.Params("categoryId")
-	subID := c.Params("subId")
-	catObj, err := primitive.ObjectIDFromHex(categoryID)
+
+	cat, err := h.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	collection := h.DB.Collections().Categories
+	children, err := collection.CountDocuments(ctx, bson.M{"parent_id": id})
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category id"})
+		return apperrors.Internal("Failed to count subcategories", err)
 	}
-	subObj, err := primitive.ObjectIDFromHex(subID)
+	if children > 0 {
+		return apperrors.Conflict(fmt.Sprintf("The category has %d subcategories; delete or move them first", children))
+	}
+	products, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"category": models.CategorySubtree(cat.Path)})
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid subcategory id"})
+		return apperrors.Internal("Failed to count category products", err)
+	}
+	if products > 0 {
+		return apperrors.Conflict(fmt.Sprintf("%d products are in this category; move them to another category first", products))
 	}
 
-	collection := h.DB.Collections().Categories
-	update := bson.M{"$pull": bson.M{"subcategories": bson.M{"_id": subObj}}, "$set": bson.M{"updated_at": time.Now()}}
-	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	res := collection.FindOneAndUpdate(ctx, bson.M{"_id": catObj}, update, opts)
-	var updated models.Category
-	if err := res.Decode(&updated); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category or subcategory not found"})
+	if _, err := collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return apperrors.Internal("Failed to delete category", err)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Subcategory deleted successfully", "data": updated})
+	recordAudit(c, h.DB.MongoDB, "category.delete", "category", id.Hex(), cat, nil)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Category deleted successfully"})
 }
 
-// GetCategories fetches all categories
+// GetCategories fetches the whole category tree
 // GET /admin/categories
 func (h *CategoryHandler) GetCategories(c *fiber.Ctx) error {
-	ctx := c.Context()
-	collection := h.DB.Collections().Categories
-
-	cursor, err := collection.Find(ctx, bson.M{})
+	roots, err := h.tree(c.Context(), bson.M{})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to fetch categories", "error": err.Error()})
-	}
-	defer cursor.Close(ctx)
-
-	var cats []models.Category
-	if err := cursor.All(ctx, &cats); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to decode categories", "error": err.Error()})
+		return apperrors.Internal("Failed to fetch categories", err)
 	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Categories retrieved successfully", "data": cats})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Categories retrieved successfully", "data": roots})
 }
 
-// GetPublicCategories provides a public (non-admin, no-auth) list of categories.
-// GET /categories?name=Men|Women (optional filter)
+// GetPublicCategories provides a public (non-admin, no-auth) category tree.
+// GET /categories?name=Men (optional filter on the top-level category)
 // Always returns success=true with an array (possibly empty) for easier client handling.
 func (h *CategoryHandler) GetPublicCategories(c *fiber.Ctx) error {
-	ctx := c.Context()
-	collection := h.DB.Collections().Categories
-
-	filter := bson.M{}
+	roots, err := h.tree(c.Context(), bson.M{})
+	if err != nil {
+		return apperrors.Internal("Failed to fetch categories", err)
+	}
 	if name := c.Query("name"); name != "" {
-		filter["name"] = name
+		matched := make([]*models.CategoryNode, 0, 1)
+		for _, root := range roots {
+			if strings.EqualFold(root.Name, name) {
+				matched = append(matched, root)
+			}
+		}
+		roots = matched
 	}
+	return c.JSON(fiber.Map{"success": true, "message": "Categories retrieved successfully", "data": roots})
+}
 
-	cursor, err := collection.Find(ctx, filter)
+// GetPublicCategory returns a category with its subcategories, and its
+// ancestors for breadcrumbs in meta.breadcrumb
+// GET /categories/:slug
+func (h *CategoryHandler) GetPublicCategory(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var cat models.Category
+	err := h.DB.Collections().Categories.FindOne(ctx, bson.M{"slug": models.Slugify(c.Params("slug"))}).Decode(&cat)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to fetch categories", "error": err.Error()})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Category not found")
+		}
+		return apperrors.Internal("Failed to fetch category", err)
 	}
-	defer cursor.Close(ctx)
 
-	var cats []models.Category
-	if err := cursor.All(ctx, &cats); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to decode categories", "error": err.Error()})
+	nodes, err := h.tree(ctx, bson.M{"$or": bson.A{bson.M{"_id": cat.ID}, bson.M{"ancestors": cat.ID}}})
+	if err != nil || len(nodes) == 0 {
+		return apperrors.Internal("Failed to fetch subcategories", err)
+	}
+	breadcrumb := make([]models.Category, 0, len(cat.Ancestors))
+	if len(cat.Ancestors) > 0 {
+		cursor, err := h.DB.Collections().Categories.Find(ctx,
+			bson.M{"_id": bson.M{"$in": cat.Ancestors}},
+			options.Find().SetSort(bson.D{{Key: "depth", Value: 1}}))
+		if err != nil {
+			return apperrors.Internal("Failed to fetch parent categories", err)
+		}
+		if err := cursor.All(ctx, &breadcrumb); err != nil {
+			return apperrors.Internal("Failed to decode parent categories", err)
+		}
 	}
-	return c.JSON(fiber.Map{"success": true, "message": "Categories retrieved successfully", "data": cats})
+
+	// The landing page's meta tags, falling back to the category itself
+	seo := cat.SEO.WithDefaults(cat.Name, "", storefrontURL(h.Config, storefrontCategoryPath+cat.Slug))
+	nodes[0].SEO = &seo
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Category retrieved successfully",
+		"data":    nodes[0],
+		"meta":    fiber.Map{"breadcrumb": breadcrumb},
+	})
 }
 
-// GetPublicSubcategories returns only the subcategories for a given main category by name.
+// GetPublicSubcategories returns the subcategories of a top-level category,
+// given by name or slug.
 // GET /categories/:name/subcategories
 // Returns 200 with empty list if category not found (avoids leaking existence semantics) unless strict is requested via ?strict=1.
 func (h *CategoryHandler) GetPublicSubcategories(c *fiber.Ctx) error {
 	ctx := c.Context()
 	name := c.Params("name")
-	collection := h.DB.Collections().Categories
 
 	var cat models.Category
-	err := collection.FindOne(ctx, bson.M{"name": name}).Decode(&cat)
+	err := h.DB.Collections().Categories.FindOne(ctx, bson.M{
+		"depth": 0,
+		"$or":   bson.A{bson.M{"path": name}, bson.M{"slug": models.Slugify(name)}},
+	}, options.FindOne().SetCollation(database.CategoryCollation)).Decode(&cat)
 	if err != nil {
-		if err == fiber.ErrNotFound || err.Error() == "mongo: no documents in result" {
+		if errors.Is(err, mongo.ErrNoDocuments) {
 			if c.Query("strict") == "1" {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category not found"})
+				return apperrors.NotFound("Category not found")
 			}
-			return c.JSON(fiber.Map{"success": true, "message": "Subcategories retrieved successfully", "data": []models.Subcategory{}})
+			return c.JSON(fiber.Map{"success": true, "message": "Subcategories retrieved successfully", "data": []*models.CategoryNode{}})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to fetch category", "error": err.Error()})
+		return apperrors.Internal("Failed to fetch category", err)
+	}
+
+	children, err := h.tree(ctx, bson.M{"ancestors": cat.ID})
+	if err != nil {
+		return apperrors.Internal("Failed to fetch subcategories", err)
 	}
-	return c.JSON(fiber.Map{"success": true, "message": "Subcategories retrieved successfully", "data": cat.Subcategories})
+	return c.JSON(fiber.Map{"success": true, "message": "Subcategories retrieved successfully", "data": children})
 }
 
 // UpdateCategoryDiscount updates discount settings for a category
@@ -356,117 +602,60 @@ func (h *CategoryHandler) GetPublicSubcategories(c *fiber.Ctx) error {
 //	  "discountEndDate": "2025-10-31T23:59:59Z"
 //	}
 func (h *CategoryHandler) UpdateCategoryDiscount(c *fiber.Ctx) error {
-	ctx := c.Context()
-	id := c.Params("id")
-
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category ID"})
-	}
-
-	var req models.CategoryDiscountRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid request body", "error": err.Error()})
-	}
-
-	// Build update document
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now(),
-		},
-	}
-	setFields := update["$set"].(bson.M)
-
-	if req.DiscountPercentage != nil {
-		setFields["discount_percentage"] = req.DiscountPercentage
-	}
-	if req.DiscountAmount != nil {
-		setFields["discount_amount"] = req.DiscountAmount
-	}
-	if req.DiscountStartDate != nil {
-		setFields["discount_start_date"] = req.DiscountStartDate
-	}
-	if req.DiscountEndDate != nil {
-		setFields["discount_end_date"] = req.DiscountEndDate
-	}
-
-	collection := h.DB.Collections().Categories
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to update discount", "error": err.Error()})
-	}
-
-	if result.MatchedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category not found"})
+		return apperrors.BadRequest("Invalid category ID", nil)
 	}
-
-	return c.JSON(fiber.Map{"success": true, "message": "Category discount updated successfully"})
+	return h.updateDiscount(c, objectID)
 }
 
 // UpdateSubcategoryDiscount updates discount settings for a subcategory
-// @example Request:
+// addressed through its parent
 // PUT /admin/categories/:id/subcategories/:subId/discount
-//
-//	{
-//	  "discountPercentage": 15,
-//	  "discountStartDate": "2025-10-01T00:00:00Z",
-//	  "discountEndDate": "2025-10-31T23:59:59Z"
-//	}
 func (h *CategoryHandler) UpdateSubcategoryDiscount(c *fiber.Ctx) error {
-	ctx := c.Context()
-	id := c.Params("id")
-	subID := c.Params("subId")
-
-	objectID, err := primitive.ObjectIDFromHex(id)
+	subObj, err := h.subcategory(c.Context(), c.Params("id"), c.Params("subId"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid category ID"})
+		return err
 	}
+	return h.updateDiscount(c, subObj)
+}
 
-	subObjectID, err := primitive.ObjectIDFromHex(subID)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid subcategory ID"})
-	}
+func (h *CategoryHandler) updateDiscount(c *fiber.Ctx, id primitive.ObjectID) error {
+	ctx := c.Context()
 
-	var req models.SubcategoryDiscountRequest
+	var req models.CategoryDiscountRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid request body", "error": err.Error()})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
-	// Build update document for specific subcategory
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now(),
-		},
-	}
-	setFields := update["$set"].(bson.M)
-
+	// Build update document
+	setFields := bson.M{"updated_at": time.Now()}
 	if req.DiscountPercentage != nil {
-		setFields["subcategories.$[elem].discount_percentage"] = req.DiscountPercentage
+		setFields["discount_percentage"] = req.DiscountPercentage
 	}
 	if req.DiscountAmount != nil {
-		setFields["subcategories.$[elem].discount_amount"] = req.DiscountAmount
+		setFields["discount_amount"] = req.DiscountAmount
 	}
 	if req.DiscountStartDate != nil {
-		setFields["subcategories.$[elem].discount_start_date"] = req.DiscountStartDate
+		setFields["discount_start_date"] = req.DiscountStartDate
 	}
 	if req.DiscountEndDate != nil {
-		setFields["subcategories.$[elem].discount_end_date"] = req.DiscountEndDate
-	}
-
-	arrayFilters := options.ArrayFilters{
-		Filters: []interface{}{bson.M{"elem._id": subObjectID}},
+		setFields["discount_end_date"] = req.DiscountEndDate
 	}
-	opts := options.Update().SetArrayFilters(arrayFilters)
 
-	collection := h.DB.Collections().Categories
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID}, update, opts)
+	var before models.Category
+	err := h.DB.Collections().Categories.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": setFields}).Decode(&before)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to update subcategory discount", "error": err.Error()})
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Category not found")
+		}
+		return apperrors.Internal("Failed to update discount", err)
 	}
-
-	if result.MatchedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Category or subcategory not found"})
+	after, err := h.find(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(fiber.Map{"success": true, "message": "Subcategory discount updated successfully"})
+	recordAudit(c, h.DB.MongoDB, "category.discount_update", "category", id.Hex(), before, after)
+	return c.JSON(fiber.Map{"success": true, "message": "Category discount updated successfully"})
 }
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+const (
+	codMaxAttempts    = 5 // wrong codes before the code is burned and must be resent
+	codResendCooldown = time.Minute
+)
+
+func codResendCooldownKey(orderID primitive.ObjectID) string {
+	return "cod_verification:cooldown:" + orderID.Hex()
+}
+
+// codVerificationEnabled reports whether the user's new COD orders need
+// confirmation: COD_VERIFICATION picks a method and the user is in the
+// checkout.cod_verification flag's rollout
+func (h *OrderHandler) codVerificationEnabled(ctx context.Context, userID primitive.ObjectID) bool {
+	switch h.Config.CODVerification {
+	case models.CODVerificationOTP, models.CODVerificationEmail:
+		return h.Flags.Enabled(ctx, models.FlagCODVerification, userID)
+	}
+	return false
+}
+
+// hashCODSecret binds a code or link token to its order and the server secret
+func (h *OrderHandler) hashCODSecret(orderID primitive.ObjectID, secret string) string {
+	mac := hmac.New(sha256.New, []byte(h.Config.JWTSecret))
+	mac.Write([]byte(orderID.Hex() + ":" + secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// codSecret generates a 6-digit code for SMS or a random token for links
+func codSecret(method string) (string, error) {
+	if method == models.CODVerificationOTP {
+		n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%06d", n.Int64()), nil
+	}
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// codVerificationMethod picks how to reach the customer: the configured
+// method when possible, otherwise whichever channel the order has. Orders
+// with neither wait for an admin.
+func (h *OrderHandler) codVerificationMethod(account models.User, order *models.Order) string {
+	_, hasPhone := models.NormalizePhone(order.ShippingAddress.Phone)
+	hasEmail := account.Email != ""
+
+	preferred, fallback := h.Config.CODVerification, models.CODVerificationEmail
+	if preferred == models.CODVerificationEmail {
+		fallback = models.CODVerificationOTP
+	}
+	for _, method := range []string{preferred, fallback} {
+		if (method == models.CODVerificationOTP && hasPhone) || (method == models.CODVerificationEmail && hasEmail) {
+			return method
+		}
+	}
+	return models.CODVerificationAdmin
+}
+
+// prepareCODVerification puts a new COD order on hold and returns the secret
+// to deliver once the order is saved
+func (h *OrderHandler) prepareCODVerification(ctx context.Context, order *models.Order) (string, error) {
+	var account models.User
+	opts := options.FindOne().SetProjection(bson.M{"email": 1, "name": 1})
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": order.UserID}, opts).Decode(&account); err != nil {
+		return "", err
+	}
+
+	verification := &models.CODVerification{
+		Method:    h.codVerificationMethod(account, order),
+		ExpiresAt: time.Now().Add(time.Duration(h.Config.CODVerificationTTLMinutes) * time.Minute),
+	}
+	order.Status = orderstatus.PendingVerification
+	order.CODVerification = verification
+	if verification.Method == models.CODVerificationAdmin {
+		return "", nil
+	}
+
+	secret, err := codSecret(verification.Method)
+	if err != nil {
+		return "", err
+	}
+	verification.CodeHash = h.hashCODSecret(order.ID, secret)
+	return secret, nil
+}
+
+// deliverCODVerification sends the code or link. Failures are logged; the
+// customer can ask for a resend and admins can still approve the order.
+func (h *OrderHandler) deliverCODVerification(ctx context.Context, c *fiber.Ctx, order models.Order, secret string) error {
+	switch order.CODVerification.Method {
+	case models.CODVerificationOTP:
+		phone, _ := models.NormalizePhone(order.ShippingAddress.Phone)
+		return h.SMS.SendOTP(ctx, phone, secret)
+
+	case models.CODVerificationEmail:
+		var account models.User
+		if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": order.UserID}).Decode(&account); err != nil {
+			return err
+		}
+		link := fmt.Sprintf("%s%s/orders/%s/confirm-cod?token=%s", c.BaseURL(), APIVersionPrefix, order.ID.Hex(), url.QueryEscape(secret))
+		name := account.Name
+		if name == "" {
+			name = "there"
+		}
+		msg := mailer.Message{
+			To:      account.Email,
+			Subject: "Confirm your cash-on-delivery order",
+			Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your cash-on-delivery order #%s for ₹%.2f by opening the link below:\n\n%s\n\nUnconfirmed orders are cancelled after %d minutes. If you didn't place this order, you can ignore this email.\n",
+				name, order.ID.Hex(), order.Total, link, h.Config.CODVerificationTTLMinutes),
+		}
+		if h.Jobs != nil {
+			_, err := h.Jobs.Enqueue(ctx, jobs.TypeSendEmail, msg)
+			return err
+		}
+		return h.Mailer.Send(ctx, msg)
+	}
+	return nil
+}
+
+// VerifyCOD confirms a COD order with the code sent by SMS (or the token from
+// the email link)
+// POST /orders/:orderID/verify-cod
+func (h *OrderHandler) VerifyCOD(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	orderID, err := primitive.ObjectIDFromHex(c.Params("orderID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid order ID format", err)
+	}
+	var req models.CODVerifyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	order, err := h.findPendingCODOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || order.UserID != tokenUser.UserID {
+		return apperrors.Forbidden("Not authorized to verify this order")
+	}
+
+	updated, err := h.confirmCOD(ctx, order, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Order confirmed",
+		"data":    updated,
+	})
+}
+
+// ConfirmCODLink confirms a COD order from the emailed link. Browsers are
+// redirected to the storefront order page; API clients get JSON.
+// GET /orders/:orderID/confirm-cod?token=
+func (h *OrderHandler) ConfirmCODLink(c *fiber.Ctx) error {
+	ctx := c.Context()
+	wantsHTML := c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML
+
+	respond := func(err error) error {
+		if wantsHTML {
+			status := "success"
+			var appErr *apperrors.Error
+			if errors.As(err, &appErr) && appErr.Status >= fiber.StatusInternalServerError {
+				// The error handler won't see this failure, so log it here
+				log.Printf("[COD] Failed to confirm order %s: %v", c.Params("orderID"), err)
+				status = "error"
+			} else if err != nil {
+				status = "invalid"
+			}
+			return c.Redirect(fmt.Sprintf("%s/orders/%s?cod=%s", h.Config.FrontendURL, url.PathEscape(c.Params("orderID")), status))
+		}
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Order confirmed",
+		})
+	}
+
+	orderID, err := primitive.ObjectIDFromHex(c.Params("orderID"))
+	if err != nil {
+		return respond(apperrors.BadRequest("Invalid order ID format", err))
+	}
+	order, err := h.findPendingCODOrder(ctx, orderID)
+	if err != nil {
+		return respond(err)
+	}
+	_, err = h.confirmCOD(ctx, order, c.Query("token"))
+	return respond(err)
+}
+
+// ResendCODVerification issues a fresh code or link for a pending COD order.
+// The original deadline is kept so resending can't postpone cancellation.
+// POST /orders/:orderID/verify-cod/resend
+func (h *OrderHandler) ResendCODVerification(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	orderID, err := primitive.ObjectIDFromHex(c.Params("orderID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid order ID format", err)
+	}
+	order, err := h.findPendingCODOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || order.UserID != tokenUser.UserID {
+		return apperrors.Forbidden("Not authorized to verify this order")
+	}
+	if order.CODVerification.Method == models.CODVerificationAdmin {
+		return apperrors.Conflict("This order is awaiting confirmation by our team")
+	}
+
+	if h.DB.Cache != nil {
+		if _, err := h.DB.Cache.Get(ctx, codResendCooldownKey(orderID)); err == nil {
+			return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Please wait before requesting another code")
+		}
+	}
+
+	secret, err := codSecret(order.CODVerification.Method)
+	if err != nil {
+		return apperrors.Internal("Failed to generate code", err)
+	}
+	_, err = h.DB.Collections().Orders.UpdateOne(ctx,
+		bson.M{"_id": orderID, "status": orderstatus.PendingVerification},
+		bson.M{"$set": bson.M{
+			"cod_verification.code_hash": h.hashCODSecret(orderID, secret),
+			"cod_verification.attempts":  0,
+			"updated_at":                 time.Now(),
+		}},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to reset verification", err)
+	}
+
+	if err := h.deliverCODVerification(ctx, c, order, secret); err != nil {
+		return apperrors.BadGateway("Failed to send verification", err)
+	}
+	if h.DB.Cache != nil {
+		_ = h.DB.Cache.Set(ctx, codResendCooldownKey(orderID), []byte("1"), codResendCooldown)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Verification sent",
+		"data": fiber.Map{
+			"method":    order.CODVerification.Method,
+			"expiresAt": order.CODVerification.ExpiresAt,
+		},
+	})
+}
+
+// AdminVerifyOrder approves a COD order without customer confirmation, e.g.
+// after calling the customer
+// PATCH /admin/orders/:orderID/verify
+func (h *OrderHandler) AdminVerifyOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	orderID, err := primitive.ObjectIDFromHex(c.Params("orderID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid order ID format", err)
+	}
+	order, err := h.findPendingCODOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := h.markCODVerified(ctx, order, orderStatusEvent(c, orderstatus.Processing, "Cash on delivery approved"))
+	if err != nil {
+		return err
+	}
+
+	recordAudit(c, h.DB.MongoDB, "order.cod_verify", "order", orderID.Hex(),
+		bson.M{"status": order.Status}, bson.M{"status": updated.Status})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Order verified successfully",
+		"data":    updated,
+	})
+}
+
+// findPendingCODOrder loads an order that is still awaiting COD verification
+func (h *OrderHandler) findPendingCODOrder(ctx context.Context, orderID primitive.ObjectID) (models.Order, error) {
+	var order models.Order
+	if err := h.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return order, apperrors.NotFound("Order not found")
+		}
+		return order, apperrors.Internal("Failed to retrieve order", err)
+	}
+	if order.Status != orderstatus.PendingVerification || order.CODVerification == nil {
+		return order, apperrors.Conflict("Order is not awaiting verification")
+	}
+	return order, nil
+}
+
+// confirmCOD checks a customer-supplied secret, counting attempts. Each
+// attempt is counted before the secret is checked, so concurrent guesses
+// can't get past the limit.
+func (h *OrderHandler) confirmCOD(ctx context.Context, order models.Order, secret string) (models.Order, error) {
+	v := order.CODVerification
+	if v.CodeHash == "" || time.Now().After(v.ExpiresAt) {
+		return order, apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	var current models.Order
+	err := h.DB.Collections().Orders.FindOneAndUpdate(ctx,
+		bson.M{"_id": order.ID, "cod_verification.attempts": bson.M{"$lt": codMaxAttempts}},
+		bson.M{"$inc": bson.M{"cod_verification.attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&current)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return order, apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many incorrect attempts, request a new code")
+		}
+		return order, apperrors.Internal("Failed to verify code", err)
+	}
+
+	// A resend may have replaced the code since the order was read
+	v = current.CODVerification
+	if v == nil || v.CodeHash == "" || !hmac.Equal([]byte(v.CodeHash), []byte(h.hashCODSecret(order.ID, secret))) {
+		return order, apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	return h.markCODVerified(ctx, order, models.OrderStatusEvent{
+		Status:    orderstatus.Processing,
+		Actor:     models.OrderActorCustomer,
+		ActorID:   order.UserID,
+		Note:      "Cash on delivery confirmed",
+		Timestamp: time.Now(),
+	})
+}
+
+// markCODVerified releases the order for fulfilment. The status filter makes
+// it safe against the expiry job cancelling the order at the same moment.
+func (h *OrderHandler) markCODVerified(ctx context.Context, order models.Order, event models.OrderStatusEvent) (models.Order, error) {
+	now := event.Timestamp
+	var updated models.Order
+	err := h.DB.Collections().Orders.FindOneAndUpdate(ctx,
+		bson.M{"_id": order.ID, "status": orderstatus.PendingVerification},
+		bson.M{
+			"$set": bson.M{
+				"status":                       orderstatus.Processing,
+				"cod_verification.verified_at": now,
+				"cod_verification.verified_by": event.Actor,
+				"updated_at":                   now,
+			},
+			"$unset": bson.M{"cod_verification.code_hash": ""},
+			"$push":  bson.M{"status_history": event},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return order, apperrors.Conflict("Order is not awaiting verification")
+		}
+		return order, apperrors.Internal("Failed to verify order", err)
+	}
+
+	h.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()))
+	h.DB.CacheDel(ctx, fmt.Sprintf("orders:%s", order.UserID.Hex()))
+
+	h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(updated, orderstatus.PendingVerification, event))
+	return updated, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+)
+
+// sendConditionalJSON writes a response envelope with an ETag computed from
+// its data and meta, and Last-Modified when lastModified is set. Clients
+// revalidating with If-None-Match or If-Modified-Since get an empty 304 when
+// nothing changed. The message isn't part of the ETag, so a cached and a
+// freshly built payload share one.
+func sendConditionalJSON(c *fiber.Ctx, body fiber.Map, lastModified time.Time) error {
+	payload, err := json.Marshal(fiber.Map{"data": body["data"], "meta": body["meta"]})
+	if err != nil {
+		return apperrors.Internal("Failed to encode response", err)
+	}
+	sum := sha256.Sum256(payload)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Set(fiber.HeaderETag, etag)
+	// Browsers and CDNs may store the response but must revalidate it first
+	c.Set(fiber.HeaderCacheControl, "public, no-cache")
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.Status(fiber.StatusOK).JSON(body)
+}
+
+// notModified evaluates the request's conditional headers. If-None-Match
+// takes precedence; If-Modified-Since is only used without it.
+func notModified(c *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			// Weak comparison: W/"x" and "x" match
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/captcha"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Contact form submissions per client IP per hour
+const (
+	contactMessagesPerHour = 5
+	contactMessageWindow   = time.Hour
+)
+
+func contactMessagesKey(ip string) string { return "contact:messages:" + ip }
+
+// ContactHandler takes contact form messages and lists them for staff
+type ContactHandler struct {
+	DB      *database.DBClient
+	Config  *config.Config
+	Captcha *captcha.Verifier
+	Mailer  mailer.Sender
+	Jobs    *jobs.Queue // Optional; emails are sent inline when nil
+}
+
+// NewContactHandler creates a new instance of ContactHandler
+func NewContactHandler(db *database.DBClient, cfg *config.Config) *ContactHandler {
+	return &ContactHandler{
+		DB:      db,
+		Config:  cfg,
+		Captcha: &captcha.Verifier{Secret: cfg.CaptchaSecret, URL: cfg.CaptchaVerifyURL},
+		Mailer: mailer.New(mailer.Options{
+			SMTPHost:     cfg.SMTPHost,
+			SMTPPort:     cfg.SMTPPort,
+			SMTPUsername: cfg.SMTPUsername,
+			SMTPPassword: cfg.SMTPPassword,
+			From:         cfg.MailFrom,
+		}),
+	}
+}
+
+// singleLine collapses whitespace, so fields that end up in mail headers
+// can't carry line breaks
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// SubmitContactMessage takes a message from the contact form and forwards
+// it to the contact email in the store settings. Submissions filling in the
+// honeypot field are answered like any other but dropped.
+// POST /contact
+func (h *ContactHandler) SubmitContactMessage(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	if cache := h.DB.Cache; cache != nil {
+		key := contactMessagesKey(c.IP())
+		n, err := cache.Incr(ctx, key)
+		if err == nil && n == 1 {
+			// First message of the window: give the counter its expiry
+			_ = cache.Set(ctx, key, []byte("1"), contactMessageWindow)
+		}
+		if n > contactMessagesPerHour {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(contactMessageWindow.Seconds())))
+			return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many messages, please try again later")
+		}
+	}
+
+	var req models.ContactRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperrors.BadRequest("Invalid request body", err)
+	}
+	req.Email = models.NormalizeEmail(req.Email)
+	req.Name = singleLine(req.Name)
+	req.Subject = singleLine(req.Subject)
+	req.Message = strings.TrimSpace(req.Message)
+	if err := validateRequest(&req); err != nil {
+		return err
+	}
+
+	respond := func() error {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"success": true,
+			"message": "Thanks for getting in touch, we'll reply by email",
+		})
+	}
+	if req.Website != "" {
+		log.Printf("[CONTACT] Dropped a message from %s that filled in the honeypot", c.IP())
+		return respond()
+	}
+	if h.Captcha.Enabled() {
+		ok, err := h.Captcha.Verify(ctx, req.CaptchaToken, c.IP())
+		if err != nil {
+			return apperrors.Unavailable("Couldn't check the captcha, please try again", err)
+		}
+		if !ok {
+			return apperrors.BadRequest("Captcha verification failed, please try again", nil)
+		}
+	}
+
+	now := time.Now()
+	msg := models.ContactMessage{
+		ID:        primitive.NewObjectID(),
+		Name:      req.Name,
+		Email:     req.Email,
+		Subject:   req.Subject,
+		Message:   req.Message,
+		Status:    models.ContactMessageNew,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := h.DB.Collections().ContactMessages.InsertOne(ctx, msg); err != nil {
+		return apperrors.Internal("Failed to save message", err)
+	}
+	// The message is saved, so a failed forward only costs the email
+	if err := h.forward(ctx, msg); err != nil {
+		log.Printf("[CONTACT] Failed to forward message %s: %v", msg.ID.Hex(), err)
+	}
+	return respond()
+}
+
+// forward emails a contact message to the store's contact email, with
+// replies going to the sender
+func (h *ContactHandler) forward(ctx context.Context, msg models.ContactMessage) error {
+	var settings models.Settings
+	err := h.DB.MongoDB.Collection("settings").FindOne(ctx, bson.M{},
+		options.FindOne().SetProjection(bson.M{"contact_email": 1})).Decode(&settings)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if settings.ContactEmail == "" {
+		return fmt.Errorf("no contact email in the store settings")
+	}
+	mail := mailer.Message{
+		To:      settings.ContactEmail,
+		Subject: "Contact form: " + msg.Subject,
+		Body: fmt.Sprintf("%s <%s> wrote through the contact form:\n\n%s\n\nReply to this email to answer them, then mark message %s as replied in the admin.\n",
+			msg.Name, msg.Email, msg.Message, msg.ID.Hex()),
+		ReplyTo: msg.Email,
+	}
+	if h.Jobs != nil {
+		_, err := h.Jobs.Enqueue(ctx, jobs.TypeSendEmail, mail)
+		return err
+	}
+	return h.Mailer.Send(ctx, mail)
+}
+
+// GetContactMessages lists contact messages, newest first
+// GET /admin/contact-messages?status=new|replied&page=1&limit=20
+func (h *ContactHandler) GetContactMessages(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	filter := bson.M{}
+	switch status := c.Query("status"); status {
+	case "":
+	case models.ContactMessageNew, models.ContactMessageReplied:
+		filter["status"] = status
+	default:
+		return apperrors.BadRequest("status must be new or replied", nil)
+	}
+
+	coll := h.DB.Collections().ContactMessages
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count contact messages", err)
+	}
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch contact messages", err)
+	}
+	messages := []models.ContactMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return apperrors.Internal("Failed to decode contact messages", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contact messages retrieved successfully",
+		"data":    messages,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// UpdateContactMessageStatus marks a contact message as replied to, or
+// back as new
+// PATCH /admin/contact-messages/:id
+func (h *ContactHandler) UpdateContactMessageStatus(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid contact message ID", err)
+	}
+	var req models.ContactStatusRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"status": req.Status, "updated_at": now}}
+	if req.Status == models.ContactMessageReplied {
+		set := update["$set"].(bson.M)
+		set["replied_at"] = now
+		if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+			set["replied_by"] = actor.UserID
+		}
+	} else {
+		update["$unset"] = bson.M{"replied_at": "", "replied_by": ""}
+	}
+
+	var before models.ContactMessage
+	err = h.DB.Collections().ContactMessages.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Contact message not found")
+		}
+		return apperrors.Internal("Failed to update contact message", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "contact_message.status", "contact_message", id,
+		bson.M{"status": before.Status}, bson.M{"status": req.Status})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contact message updated",
+	})
+}
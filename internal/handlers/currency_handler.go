@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// CurrencyHandler manages the currencies prices can be shown and charged in
+type CurrencyHandler struct {
+	DB *database.DBClient
+}
+
+// NewCurrencyHandler creates a new instance of CurrencyHandler
+func NewCurrencyHandler(db *database.DBClient) *CurrencyHandler {
+	return &CurrencyHandler{DB: db}
+}
+
+// lookupCurrency returns the enabled currency with the given ISO code, or
+// the base currency when code is empty
+func lookupCurrency(ctx context.Context, db *database.DBClient, code string) (models.Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return models.DefaultCurrency(), nil
+	}
+	var currency models.Currency
+	err := db.Collections().Currencies.FindOne(ctx, bson.M{"_id": code, "enabled": true}).Decode(&currency)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if code == models.BaseCurrency {
+				return models.DefaultCurrency(), nil
+			}
+			return currency, apperrors.BadRequest(fmt.Sprintf("Unsupported currency %q", code), nil)
+		}
+		return currency, apperrors.Internal("Failed to look up currency", err)
+	}
+	return currency, nil
+}
+
+// orderCurrency is the currency an order was charged in, at the exchange
+// rate of its checkout. Orders placed before currencies were recorded were
+// charged in the base currency.
+func orderCurrency(ctx context.Context, db *database.DBClient, order models.Order) (models.Currency, error) {
+	currency := models.DefaultCurrency()
+	if order.Currency == "" || order.Currency == currency.Code {
+		return currency, nil
+	}
+	// Its decimals still apply if it has been disabled since
+	if err := db.Collections().Currencies.FindOne(ctx, bson.M{"_id": order.Currency}).Decode(&currency); err != nil {
+		return currency, err
+	}
+	currency.Rate = order.ExchangeRate
+	return currency, nil
+}
+
+// catalogCurrency is the currency asked for in the currency query param
+func catalogCurrency(c *fiber.Ctx, db *database.DBClient) (models.Currency, error) {
+	return lookupCurrency(c.Context(), db, c.Query("currency"))
+}
+
+// GetCurrencies lists the currencies shoppers can choose from
+// GET /catalog/currencies
+func (h *CurrencyHandler) GetCurrencies(c *fiber.Ctx) error {
+	return h.list(c, bson.M{"enabled": true})
+}
+
+// GetAllCurrencies lists every currency, including disabled ones
+// GET /admin/currencies
+func (h *CurrencyHandler) GetAllCurrencies(c *fiber.Ctx) error {
+	return h.list(c, bson.M{})
+}
+
+func (h *CurrencyHandler) list(c *fiber.Ctx, filter bson.M) error {
+	ctx := c.Context()
+	cursor, err := h.DB.Collections().Currencies.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch currencies", err)
+	}
+	currencies := []models.Currency{}
+	if err := cursor.All(ctx, &currencies); err != nil {
+		return apperrors.Internal("Failed to decode currencies", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Currencies retrieved successfully",
+		"data":    currencies,
+		"meta":    fiber.Map{"base": models.BaseCurrency},
+	})
+}
+
+// PutCurrency creates or updates a currency. The base currency always has
+// rate 1 and stays enabled.
+// PUT /admin/currencies/:code
+func (h *CurrencyHandler) PutCurrency(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	code := strings.ToUpper(c.Params("code"))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return apperrors.BadRequest("Currency code must be a three-letter ISO 4217 code", nil)
+	}
+	var req models.CurrencyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	decimals := 2
+	if req.Decimals != nil {
+		decimals = *req.Decimals
+	}
+	if code == models.BaseCurrency {
+		req.Rate, req.Enabled, req.AutoUpdate = 1, true, false
+	}
+
+	var before *models.Currency
+	if existing, err := h.find(ctx, code); err == nil {
+		before = &existing
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Internal("Failed to retrieve currency", err)
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"name":        strings.TrimSpace(req.Name),
+		"symbol":      req.Symbol,
+		"rate":        req.Rate,
+		"decimals":    decimals,
+		"enabled":     req.Enabled,
+		"auto_update": req.AutoUpdate,
+		"updated_at":  now,
+	}
+	if before == nil || before.Rate != req.Rate {
+		set["rate_updated_at"] = now
+	}
+	_, err := h.DB.Collections().Currencies.UpdateOne(ctx,
+		bson.M{"_id": code},
+		bson.M{"$set": set, "$setOnInsert": bson.M{"created_at": now}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to save currency", err)
+	}
+	currency, err := h.find(ctx, code)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve currency", err)
+	}
+
+	status, message := fiber.StatusOK, "Currency updated successfully"
+	if before == nil {
+		status, message = fiber.StatusCreated, "Currency created successfully"
+		recordAudit(c, h.DB.MongoDB, "currency.create", "currency", code, nil, currency)
+	} else {
+		recordAudit(c, h.DB.MongoDB, "currency.update", "currency", code, before, currency)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    currency,
+	})
+}
+
+// DeleteCurrency removes a currency. Orders placed in it keep their
+// recorded rate.
+// DELETE /admin/currencies/:code
+func (h *CurrencyHandler) DeleteCurrency(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	code := strings.ToUpper(c.Params("code"))
+	if code == models.BaseCurrency {
+		return apperrors.BadRequest("The base currency cannot be deleted", nil)
+	}
+	currency, err := h.find(ctx, code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Currency not found")
+		}
+		return apperrors.Internal("Failed to retrieve currency", err)
+	}
+	if _, err := h.DB.Collections().Currencies.DeleteOne(ctx, bson.M{"_id": code}); err != nil {
+		return apperrors.Internal("Failed to delete currency", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "currency.delete", "currency", code, currency, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Currency deleted successfully",
+	})
+}
+
+func (h *CurrencyHandler) find(ctx context.Context, code string) (models.Currency, error) {
+	var currency models.Currency
+	err := h.DB.Collections().Currencies.FindOne(ctx, bson.M{"_id": code}).Decode(&currency)
+	return currency, err
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+const customerExportTimeout = 10 * time.Minute
+
+// Customer is a customer account with what they have bought, for
+// segmenting customers for marketing. Cancelled orders don't count and
+// refunds are taken off the lifetime value, in the base currency.
+type Customer struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id"`
+	Name             string             `json:"name" bson:"name"`
+	Email            string             `json:"email,omitempty" bson:"email,omitempty"`
+	Phone            string             `json:"phone,omitempty" bson:"phone,omitempty"`
+	CreatedAt        time.Time          `json:"createdAt" bson:"created_at"`
+	LifetimeValue    float64            `json:"lifetimeValue" bson:"lifetime_value"`
+	OrderCount       int                `json:"orderCount" bson:"order_count"`
+	FirstOrderAt     *time.Time         `json:"firstOrderAt,omitempty" bson:"first_order_at,omitempty"`
+	LastOrderAt      *time.Time         `json:"lastOrderAt,omitempty" bson:"last_order_at,omitempty"`
+	FavoriteCategory string             `json:"favoriteCategory,omitempty" bson:"-"` // Category they bought the most items from
+}
+
+// customerSortFields maps the sortBy values GetCustomers accepts to fields
+var customerSortFields = map[string]string{
+	"lifetimeValue": "lifetime_value",
+	"orderCount":    "order_count",
+	"lastOrderAt":   "last_order_at",
+	"createdAt":     "created_at",
+}
+
+// customerSegment builds the pipeline selecting the customers in the
+// segment described by the query, with their order totals:
+//
+//	search        name or email contains
+//	minSpent      lifetime value of at least (maxSpent: at most)
+//	minOrders     at least this many orders (maxOrders: at most)
+//	inactiveDays  ordered before, but not in the last N days
+//	activeDays    ordered in the last N days
+//	sortBy        lifetimeValue (default), orderCount, lastOrderAt or createdAt; order asc or desc
+func customerSegment(c *fiber.Ctx) (mongo.Pipeline, error) {
+	match := bson.M{
+		"role":   models.RoleUser,
+		"status": bson.M{"$ne": models.UserStatusDeleted},
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+		match["$or"] = bson.A{bson.M{"name": pattern}, bson.M{"email": pattern}}
+	}
+
+	segment := bson.M{}
+	number := func(param string) (float64, bool, error) {
+		raw := c.Query(param)
+		if raw == "" {
+			return 0, false, nil
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return 0, false, apperrors.BadRequest(param+" must be a non-negative number", err)
+		}
+		return v, true, nil
+	}
+	between := func(field, minParam, maxParam string) error {
+		bounds := bson.M{}
+		if v, ok, err := number(minParam); err != nil {
+			return err
+		} else if ok {
+			bounds["$gte"] = v
+		}
+		if v, ok, err := number(maxParam); err != nil {
+			return err
+		} else if ok {
+			bounds["$lte"] = v
+		}
+		if len(bounds) > 0 {
+			segment[field] = bounds
+		}
+		return nil
+	}
+	if err := between("lifetime_value", "minSpent", "maxSpent"); err != nil {
+		return nil, err
+	}
+	if err := between("order_count", "minOrders", "maxOrders"); err != nil {
+		return nil, err
+	}
+	lastOrder := bson.M{}
+	if days, ok, err := number("inactiveDays"); err != nil {
+		return nil, err
+	} else if ok {
+		lastOrder["$lt"] = time.Now().AddDate(0, 0, -int(days))
+	}
+	if days, ok, err := number("activeDays"); err != nil {
+		return nil, err
+	} else if ok {
+		lastOrder["$gte"] = time.Now().AddDate(0, 0, -int(days))
+	}
+	if len(lastOrder) > 0 {
+		// Customers who never ordered have no last order and match neither
+		segment["last_order_at"] = lastOrder
+	}
+
+	sortField := "lifetime_value"
+	if field, ok := customerSortFields[c.Query("sortBy")]; ok {
+		sortField = field
+	}
+	dir := -1
+	if strings.EqualFold(c.Query("order"), "asc") {
+		dir = 1
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{"name": 1, "email": 1, "phone": 1, "created_at": 1}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "orders",
+			"let":  bson.M{"uid": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"$expr":  bson.M{"$eq": bson.A{"$user_id", "$$uid"}},
+					"status": bson.M{"$ne": orderstatus.Cancelled},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"value": bson.M{"$sum": bson.M{"$subtract": bson.A{"$total", bson.M{"$ifNull": bson.A{"$refunded_amount", 0}}}}},
+					"count": bson.M{"$sum": 1},
+					"first": bson.M{"$min": "$created_at"},
+					"last":  bson.M{"$max": "$created_at"},
+				}},
+			},
+			"as": "stats",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"lifetime_value": bson.M{"$ifNull": bson.A{bson.M{"$first": "$stats.value"}, 0}},
+			"order_count":    bson.M{"$ifNull": bson.A{bson.M{"$first": "$stats.count"}, 0}},
+			"first_order_at": bson.M{"$first": "$stats.first"},
+			"last_order_at":  bson.M{"$first": "$stats.last"},
+		}}},
+		{{Key: "$unset", Value: "stats"}},
+	}
+	if len(segment) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: segment}})
+	}
+	return append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: sortField, Value: dir}, {Key: "_id", Value: dir}}}}), nil
+}
+
+// GetCustomers lists customers with their lifetime value, order count, last
+// order and favorite category, filtered to a segment (see customerSegment)
+// GET /admin/customers?minSpent=50000&inactiveDays=90&page=1&limit=20
+func (h *AdminAccountHandler) GetCustomers(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	pipeline, err := customerSegment(c)
+	if err != nil {
+		return err
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"data":  bson.A{bson.M{"$skip": (page - 1) * limit}, bson.M{"$limit": limit}},
+		"total": bson.A{bson.M{"$count": "n"}},
+	}}})
+
+	cursor, err := h.DB.Collections().Users.Aggregate(ctx, pipeline)
+	if err != nil {
+		return apperrors.Internal("Failed to segment customers", err)
+	}
+	var result []struct {
+		Data  []Customer `bson:"data"`
+		Total []struct {
+			N int64 `bson:"n"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return apperrors.Internal("Failed to decode customers", err)
+	}
+	customers := []Customer{}
+	var total int64
+	if len(result) > 0 {
+		if result[0].Data != nil {
+			customers = result[0].Data
+		}
+		if len(result[0].Total) > 0 {
+			total = result[0].Total[0].N
+		}
+	}
+	if err := h.addFavoriteCategories(ctx, customers); err != nil {
+		return apperrors.Internal("Failed to find favorite categories", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Customers retrieved successfully",
+		"data":    customers,
+		"meta": fiber.Map{
+			"page":     page,
+			"limit":    limit,
+			"total":    total,
+			"pages":    (total + int64(limit) - 1) / int64(limit),
+			"currency": models.BaseCurrency,
+		},
+	})
+}
+
+// ExportCustomers streams every customer in a segment as CSV, with the same
+// filters and sort as GetCustomers
+// GET /admin/customers/export?inactiveDays=90
+func (h *AdminAccountHandler) ExportCustomers(c *fiber.Ctx) error {
+	pipeline, err := customerSegment(c)
+	if err != nil {
+		return err
+	}
+
+	// The stream outlives the request context, so it gets its own
+	ctx, cancel := context.WithTimeout(context.Background(), customerExportTimeout)
+	cursor, err := h.DB.Collections().Users.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		cancel()
+		return apperrors.Internal("Failed to segment customers", err)
+	}
+
+	filename := "customers-" + time.Now().Format("20060102-150405") + ".csv"
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		defer cancel()
+		defer cursor.Close(ctx)
+
+		w := csv.NewWriter(bw)
+		w.Write([]string{
+			"customer_id", "name", "email", "phone", "created_at",
+			"lifetime_value_inr", "order_count", "first_order_at", "last_order_at", "favorite_category",
+		})
+		formatTime := func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.Format(time.RFC3339)
+		}
+
+		// Favorite categories are looked up a batch of customers at a time
+		const batchSize = 200
+		batch := make([]Customer, 0, batchSize)
+		flush := func() bool {
+			if err := h.addFavoriteCategories(ctx, batch); err != nil {
+				log.Printf("[CUSTOMERS] Export: failed to find favorite categories: %v", err)
+			}
+			for _, cu := range batch {
+				w.Write([]string{
+					cu.ID.Hex(), cu.Name, cu.Email, cu.Phone, cu.CreatedAt.Format(time.RFC3339),
+					strconv.FormatFloat(cu.LifetimeValue, 'f', 2, 64), strconv.Itoa(cu.OrderCount),
+					formatTime(cu.FirstOrderAt), formatTime(cu.LastOrderAt), cu.FavoriteCategory,
+				})
+			}
+			batch = batch[:0]
+			// Writes fail once the client has gone away
+			w.Flush()
+			return w.Error() == nil
+		}
+		for cursor.Next(ctx) {
+			var cu Customer
+			if err := cursor.Decode(&cu); err != nil {
+				log.Printf("[CUSTOMERS] Export: failed to decode customer: %v", err)
+				continue
+			}
+			if batch = append(batch, cu); len(batch) == batchSize && !flush() {
+				return
+			}
+		}
+		if err := cursor.Err(); err != nil {
+			log.Printf("[CUSTOMERS] Export stopped early: %v", err)
+		}
+		flush()
+	})
+	return nil
+}
+
+// addFavoriteCategories sets each customer's favorite category: the one
+// they bought the most items from, ignoring cancelled orders
+func (h *AdminAccountHandler) addFavoriteCategories(ctx context.Context, customers []Customer) error {
+	if len(customers) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, len(customers))
+	for i, cu := range customers {
+		ids[i] = cu.ID
+	}
+
+	cursor, err := h.DB.Collections().Orders.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": bson.M{"$in": ids}, "status": bson.M{"$ne": orderstatus.Cancelled}}}},
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "products",
+			"localField":   "items.product_id",
+			"foreignField": "_id",
+			"as":           "product",
+		}}},
+		{{Key: "$unwind", Value: "$product"}},
+		{{Key: "$match", Value: bson.M{"product.category": bson.M{"$nin": bson.A{nil, ""}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user": "$user_id", "category": "$product.category"},
+			"items": bson.M{"$sum": "$items.quantity"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "items", Value: -1}, {Key: "_id.category", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$_id.user", "category": bson.M{"$first": "$_id.category"}}}},
+	})
+	if err != nil {
+		return err
+	}
+	var favorites []struct {
+		UserID   primitive.ObjectID `bson:"_id"`
+		Category string             `bson:"category"`
+	}
+	if err := cursor.All(ctx, &favorites); err != nil {
+		return err
+	}
+	byUser := make(map[primitive.ObjectID]string, len(favorites))
+	for _, f := range favorites {
+		byUser[f.UserID] = f.Category
+	}
+	for i := range customers {
+		customers[i].FavoriteCategory = byUser[customers[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const (
+	// emailVerificationPurpose keeps verification tokens from being accepted anywhere else
+	emailVerificationPurpose = "email_verification"
+	emailVerificationTTL     = 48 * time.Hour
+)
+
+// generateEmailVerificationToken signs a link token bound to the user and the
+// address being verified, so changing the email invalidates older links
+func (h *AuthHandler) generateEmailVerificationToken(userID, email string) (string, error) {
+	return h.Config.JWTKeys.Sign(jwt.MapClaims{
+		"sub":     userID,
+		"email":   email,
+		"purpose": emailVerificationPurpose,
+		"exp":     time.Now().Add(emailVerificationTTL).Unix(),
+	})
+}
+
+// parseEmailVerificationToken returns the user ID and email a token was issued for
+func (h *AuthHandler) parseEmailVerificationToken(raw string) (primitive.ObjectID, string, error) {
+	claims, err := h.Config.JWTKeys.Parse(raw)
+	if err != nil {
+		return primitive.NilObjectID, "", errors.New("invalid or expired token")
+	}
+	if claims["purpose"] != emailVerificationPurpose {
+		return primitive.NilObjectID, "", errors.New("invalid token")
+	}
+	sub, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	userID, err := primitive.ObjectIDFromHex(sub)
+	if err != nil || email == "" {
+		return primitive.NilObjectID, "", errors.New("invalid token")
+	}
+	return userID, email, nil
+}
+
+// verificationEmail builds the message carrying a verification link.
+// verifyURL is the public URL of the verify endpoint; the token is appended
+// as a query parameter.
+func (h *AuthHandler) verificationEmail(user models.User, verifyURL string) (mailer.Message, error) {
+	token, err := h.generateEmailVerificationToken(user.ID.Hex(), user.Email)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	link := verifyURL + "?token=" + url.QueryEscape(token)
+	name := user.Name
+	if name == "" {
+		name = "there"
+	}
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address for your MAK Watches account by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you didn't create an account, you can ignore this email.\n",
+			name, link, int(emailVerificationTTL.Hours())),
+	}, nil
+}
+
+// sendVerificationEmail emails a verification link immediately
+func (h *AuthHandler) sendVerificationEmail(ctx context.Context, user models.User, verifyURL string) error {
+	msg, err := h.verificationEmail(user, verifyURL)
+	if err != nil {
+		return err
+	}
+	return h.Mailer.Send(ctx, msg)
+}
+
+// verifyEmailURL builds the public URL of the verify endpoint for the API
+// version the request came in on
+func verifyEmailURL(c *fiber.Ctx) string {
+	return c.BaseURL() + APIVersionPrefix + "/auth/verify-email"
+}
+
+// sendVerificationEmailAsync hands the email to the job queue so slow mail
+// relays don't hold up registration and failed sends are retried. Without a
+// queue it falls back to a background goroutine. Failures are logged; users
+// can resend.
+func (h *AuthHandler) sendVerificationEmailAsync(user models.User, verifyURL string) {
+	if h.Jobs != nil {
+		msg, err := h.verificationEmail(user, verifyURL)
+		if err == nil {
+			_, err = h.Jobs.Enqueue(context.Background(), jobs.TypeSendEmail, msg)
+		}
+		if err != nil {
+			log.Printf("[MAIL] Failed to queue verification email for user %s: %v", user.ID.Hex(), err)
+		}
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.sendVerificationEmail(ctx, user, verifyURL); err != nil {
+			log.Printf("[MAIL] Failed to send verification email to user %s via %s: %v", user.ID.Hex(), h.Mailer.Name(), err)
+		}
+	}()
+}
+
+// VerifyEmail confirms an address from the link sent by email. Browsers are
+// redirected to the storefront; API clients get a JSON response.
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	ctx := c.Context()
+	wantsHTML := c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML
+
+	respond := func(status string, appErr *apperrors.Error) error {
+		if wantsHTML {
+			return c.Redirect(fmt.Sprintf("%s/auth/verify-email?status=%s", h.Config.FrontendURL, url.QueryEscape(status)))
+		}
+		if appErr != nil {
+			return appErr
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Email verified",
+		})
+	}
+
+	userID, email, err := h.parseEmailVerificationToken(c.Query("token"))
+	if err != nil {
+		return respond("invalid", apperrors.BadRequest("Verification link is invalid or has expired", nil))
+	}
+
+	res, err := h.DB.Collections().Users.UpdateOne(ctx,
+		bson.M{"_id": userID, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true, "updated_at": time.Now()}},
+		options.Update().SetCollation(database.EmailCollation),
+	)
+	if err != nil {
+		if wantsHTML {
+			// The error handler won't see this failure, so log it here
+			log.Printf("[MAIL] Failed to mark email verified for user %s: %v", userID.Hex(), err)
+		}
+		return respond("error", apperrors.Internal("Failed to verify email", err))
+	}
+	if res.MatchedCount == 0 {
+		// The account was deleted or its email changed after the link was sent
+		return respond("invalid", apperrors.BadRequest("Verification link is invalid or has expired", nil))
+	}
+
+	return respond("success", nil)
+}
+
+// ResendVerificationEmail sends a fresh verification link to the current user
+func (h *AuthHandler) ResendVerificationEmail(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	var user models.User
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": tokenUser.UserID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to retrieve user data", err)
+	}
+
+	if user.Email == "" {
+		return apperrors.BadRequest("Account has no email address", nil)
+	}
+	if user.EmailVerified {
+		return apperrors.Conflict("Email is already verified")
+	}
+
+	// One email per minute is plenty for a human and stops the endpoint being used to spam an inbox
+	cooldownKey := "email_verification:cooldown:" + user.ID.Hex()
+	if h.DB.Cache != nil {
+		if _, err := h.DB.Cache.Get(ctx, cooldownKey); err == nil {
+			return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Please wait before requesting another email")
+		}
+	}
+
+	if err := h.sendVerificationEmail(ctx, user, verifyEmailURL(c)); err != nil {
+		return apperrors.BadGateway("Failed to send verification email", err)
+	}
+	if h.DB.Cache != nil {
+		_ = h.DB.Cache.Set(ctx, cooldownKey, []byte("1"), time.Minute)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Verification email sent",
+	})
+}
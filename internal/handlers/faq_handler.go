@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// The published FAQs are cached under one key, dropped on every edit
+const (
+	faqsCacheKey = "faqs"
+	faqsCacheTTL = time.Hour
+)
+
+// FAQHandler manages the questions answered on the storefront's help page
+type FAQHandler struct {
+	DB *database.DBClient
+}
+
+// NewFAQHandler creates a new instance of FAQHandler
+func NewFAQHandler(db *database.DBClient) *FAQHandler {
+	return &FAQHandler{DB: db}
+}
+
+// faqSnapshot is the cached help page
+type faqSnapshot struct {
+	Categories []models.FAQCategory `json:"categories"`
+	BuiltAt    time.Time            `json:"builtAt"`
+}
+
+// faqFromRequest builds the FAQ req describes
+func faqFromRequest(req models.FAQRequest) (models.FAQ, error) {
+	faq := models.FAQ{
+		Question:  strings.TrimSpace(req.Question),
+		Answer:    strings.TrimSpace(req.Answer),
+		Category:  strings.Join(strings.Fields(req.Category), " "),
+		Position:  req.Position,
+		Published: req.Published == nil || *req.Published,
+	}
+	if faq.Question == "" || faq.Answer == "" || faq.Category == "" {
+		return faq, apperrors.BadRequest("Question, answer and category must not be blank", nil)
+	}
+	return faq, nil
+}
+
+// findFAQs returns the FAQs matching filter in position order
+func (h *FAQHandler) findFAQs(ctx context.Context, filter bson.M) ([]models.FAQ, error) {
+	cursor, err := h.DB.Collections().FAQs.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "position", Value: 1}, {Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, apperrors.Internal("Failed to fetch FAQs", err)
+	}
+	faqs := []models.FAQ{}
+	if err := cursor.All(ctx, &faqs); err != nil {
+		return nil, apperrors.Internal("Failed to decode FAQs", err)
+	}
+	return faqs, nil
+}
+
+// groupFAQs groups faqs by category. Categories come in the order of
+// their first FAQ, so positions order the categories too.
+func groupFAQs(faqs []models.FAQ) []models.FAQCategory {
+	groups := []models.FAQCategory{}
+	index := map[string]int{}
+	for _, faq := range faqs {
+		i, ok := index[faq.Category]
+		if !ok {
+			i = len(groups)
+			index[faq.Category] = i
+			groups = append(groups, models.FAQCategory{Category: faq.Category, FAQs: []models.FAQ{}})
+		}
+		groups[i].FAQs = append(groups[i].FAQs, faq)
+	}
+	return groups
+}
+
+// GetFAQs returns the published FAQs grouped by category
+// GET /faqs
+func (h *FAQHandler) GetFAQs(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var cached faqSnapshot
+	if err := h.DB.CacheGet(ctx, faqsCacheKey, &cached); err == nil {
+		return sendConditionalJSON(c, fiber.Map{
+			"success": true,
+			"message": "FAQs retrieved from cache",
+			"data":    cached.Categories,
+		}, cached.BuiltAt)
+	}
+
+	faqs, err := h.findFAQs(ctx, bson.M{"published": true})
+	if err != nil {
+		return err
+	}
+	snapshot := faqSnapshot{Categories: groupFAQs(faqs), BuiltAt: time.Now()}
+	_ = h.DB.CacheSet(ctx, faqsCacheKey, snapshot, faqsCacheTTL)
+
+	return sendConditionalJSON(c, fiber.Map{
+		"success": true,
+		"message": "FAQs retrieved successfully",
+		"data":    snapshot.Categories,
+	}, snapshot.BuiltAt)
+}
+
+// ListFAQs lists every FAQ in position order, unpublished ones included
+// GET /admin/faqs
+func (h *FAQHandler) ListFAQs(c *fiber.Ctx) error {
+	faqs, err := h.findFAQs(c.Context(), bson.M{})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "FAQs retrieved successfully",
+		"data":    faqs,
+	})
+}
+
+// CreateFAQ adds a FAQ
+// POST /admin/faqs
+func (h *FAQHandler) CreateFAQ(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.FAQRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	faq, err := faqFromRequest(req)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	faq.ID = primitive.NewObjectID()
+	faq.CreatedAt = now
+	faq.UpdatedAt = now
+
+	if _, err := h.DB.Collections().FAQs.InsertOne(ctx, faq); err != nil {
+		return apperrors.Internal("Failed to create FAQ", err)
+	}
+	_ = h.DB.CacheDel(ctx, faqsCacheKey)
+	recordAudit(c, h.DB.MongoDB, "faq.create", "faq", faq.ID.Hex(), nil, faq)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "FAQ created successfully",
+		"data":    faq,
+	})
+}
+
+// UpdateFAQ edits a FAQ
+// PUT /admin/faqs/:id
+func (h *FAQHandler) UpdateFAQ(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid FAQ ID", err)
+	}
+	var req models.FAQRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	faq, err := faqFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var before, updated models.FAQ
+	coll := h.DB.Collections().FAQs
+	err = coll.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{
+		"question":   faq.Question,
+		"answer":     faq.Answer,
+		"category":   faq.Category,
+		"position":   faq.Position,
+		"published":  faq.Published,
+		"updated_at": time.Now(),
+	}}).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("FAQ not found")
+		}
+		return apperrors.Internal("Failed to update FAQ", err)
+	}
+	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
+		return apperrors.Internal("Failed to fetch FAQ", err)
+	}
+	_ = h.DB.CacheDel(ctx, faqsCacheKey)
+	recordAudit(c, h.DB.MongoDB, "faq.update", "faq", objectID.Hex(), before, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "FAQ updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteFAQ removes a FAQ
+// DELETE /admin/faqs/:id
+func (h *FAQHandler) DeleteFAQ(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid FAQ ID", err)
+	}
+	var faq models.FAQ
+	if err := h.DB.Collections().FAQs.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&faq); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("FAQ not found")
+		}
+		return apperrors.Internal("Failed to delete FAQ", err)
+	}
+	_ = h.DB.CacheDel(ctx, faqsCacheKey)
+	recordAudit(c, h.DB.MongoDB, "faq.delete", "faq", objectID.Hex(), faq, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "FAQ deleted successfully",
+	})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/flags"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// flagKeyPattern keeps flag keys short dotted slugs, e.g. "checkout.guest"
+var flagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{1,63}$`)
+
+// FeatureFlagHandler manages feature flags and their rollouts
+type FeatureFlagHandler struct {
+	DB *database.DBClient
+}
+
+// NewFeatureFlagHandler creates a new instance of FeatureFlagHandler
+func NewFeatureFlagHandler(db *database.DBClient) *FeatureFlagHandler {
+	return &FeatureFlagHandler{DB: db}
+}
+
+// GetFeatureFlags lists every feature flag
+// GET /admin/feature-flags
+func (h *FeatureFlagHandler) GetFeatureFlags(c *fiber.Ctx) error {
+	ctx := c.Context()
+	cursor, err := h.DB.Collections().FeatureFlags.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch feature flags", err)
+	}
+	list := []models.FeatureFlag{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return apperrors.Internal("Failed to decode feature flags", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flags retrieved successfully",
+		"data":    list,
+	})
+}
+
+// PutFeatureFlag creates or updates a feature flag. Changes reach every
+// instance within half a minute.
+// PUT /admin/feature-flags/:key
+func (h *FeatureFlagHandler) PutFeatureFlag(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	key := strings.ToLower(c.Params("key"))
+	if !flagKeyPattern.MatchString(key) {
+		return apperrors.BadRequest("Flag key must be 2-64 lowercase letters, digits, '.', '-' or '_', starting with a letter", nil)
+	}
+	var req models.FeatureFlagRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var before *models.FeatureFlag
+	if existing, err := h.find(ctx, key); err == nil {
+		before = &existing
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Internal("Failed to retrieve feature flag", err)
+	}
+
+	now := time.Now()
+	_, err := h.DB.Collections().FeatureFlags.UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$set": bson.M{
+				"description": strings.TrimSpace(req.Description),
+				"enabled":     req.Enabled,
+				"rollout":     req.Rollout,
+				"updated_at":  now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to save feature flag", err)
+	}
+	h.DB.CacheDel(ctx, flags.CacheKey(key))
+	flag, err := h.find(ctx, key)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve feature flag", err)
+	}
+
+	status, message := fiber.StatusOK, "Feature flag updated successfully"
+	if before == nil {
+		status, message = fiber.StatusCreated, "Feature flag created successfully"
+		recordAudit(c, h.DB.MongoDB, "feature_flag.create", "feature_flag", key, nil, flag)
+	} else {
+		recordAudit(c, h.DB.MongoDB, "feature_flag.update", "feature_flag", key, before, flag)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    flag,
+	})
+}
+
+// DeleteFeatureFlag removes a feature flag, turning its feature off for
+// everyone. Remove a flag once its feature is fully rolled out and no
+// longer checks it.
+// DELETE /admin/feature-flags/:key
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	key := strings.ToLower(c.Params("key"))
+	flag, err := h.find(ctx, key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Feature flag not found")
+		}
+		return apperrors.Internal("Failed to retrieve feature flag", err)
+	}
+
+	if _, err := h.DB.Collections().FeatureFlags.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		return apperrors.Internal("Failed to delete feature flag", err)
+	}
+	h.DB.CacheDel(ctx, flags.CacheKey(key))
+	recordAudit(c, h.DB.MongoDB, "feature_flag.delete", "feature_flag", key, flag, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Feature flag deleted successfully",
+	})
+}
+
+func (h *FeatureFlagHandler) find(ctx context.Context, key string) (models.FeatureFlag, error) {
+	var flag models.FeatureFlag
+	err := h.DB.Collections().FeatureFlags.FindOne(ctx, bson.M{"_id": key}).Decode(&flag)
+	return flag, err
+}
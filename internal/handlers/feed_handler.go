@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const (
+	// sitemapMaxURLs is the sitemap protocol's limit per file; larger
+	// sitemaps are split and listed by a sitemap index
+	sitemapMaxURLs = 50000
+	feedCacheTTL   = time.Hour
+
+	// Storefront pages the sitemap and feeds link to, under FrontendURL
+	storefrontProductPath  = "/products/"
+	storefrontCategoryPath = "/categories/"
+	storefrontBrandPath    = "/brands/"
+	storefrontPostPath     = "/journal/"
+)
+
+// storefrontURL is the absolute URL of a storefront page
+func storefrontURL(cfg *config.Config, path string) string {
+	return strings.TrimSuffix(cfg.FrontendURL, "/") + path
+}
+
+// FeedHandler serves machine-readable catalog exports: the sitemap for
+// search engines and product feeds for marketing integrations
+type FeedHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewFeedHandler creates a new instance of FeedHandler
+func NewFeedHandler(db *database.DBClient, cfg *config.Config) *FeedHandler {
+	return &FeedHandler{DB: db, Config: cfg}
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+const sitemapXMLNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// cached returns the document cached under params, building and caching it
+// on a miss. Keys live in the products namespace so catalog changes
+// invalidate them.
+func (h *FeedHandler) cached(ctx context.Context, params map[string]string, build func() ([]byte, error)) ([]byte, error) {
+	key := h.DB.VersionedCacheKey(ctx, database.ProductsCacheNamespace, params)
+	var body []byte
+	if err := h.DB.CacheGet(ctx, key, &body); err == nil {
+		return body, nil
+	}
+	body, err := build()
+	if err != nil {
+		return nil, err
+	}
+	h.DB.CacheSet(ctx, key, body, feedCacheTTL)
+	return body, nil
+}
+
+// Sitemap lists the storefront's home, category, brand and product pages.
+// Past sitemapMaxURLs it becomes a sitemap index of /sitemap-N.xml files.
+// GET /sitemap.xml
+func (h *FeedHandler) Sitemap(c *fiber.Ctx) error {
+	return h.sitemap(c, 0)
+}
+
+// SitemapPage serves one file of a split sitemap
+// GET /sitemap-:page.xml
+func (h *FeedHandler) SitemapPage(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Params("page"))
+	if err != nil || page < 1 {
+		return apperrors.NotFound("Sitemap not found")
+	}
+	return h.sitemap(c, page)
+}
+
+// sitemap renders page of the sitemap; page 0 is /sitemap.xml itself
+func (h *FeedHandler) sitemap(c *fiber.Ctx, page int) error {
+	ctx := c.Context()
+	baseURL := c.BaseURL()
+
+	body, err := h.cached(ctx, map[string]string{"feed": "sitemap", "page": strconv.Itoa(page), "base": baseURL}, func() ([]byte, error) {
+		urls, err := h.sitemapURLs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pages := (len(urls) + sitemapMaxURLs - 1) / sitemapMaxURLs
+
+		var doc interface{}
+		switch {
+		case page == 0 && pages <= 1:
+			doc = sitemapURLSet{Xmlns: sitemapXMLNS, URLs: urls}
+		case page == 0:
+			index := sitemapIndex{Xmlns: sitemapXMLNS}
+			for i := 1; i <= pages; i++ {
+				index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: fmt.Sprintf("%s/sitemap-%d.xml", baseURL, i)})
+			}
+			doc = index
+		case page <= pages:
+			end := page * sitemapMaxURLs
+			if end > len(urls) {
+				end = len(urls)
+			}
+			doc = sitemapURLSet{Xmlns: sitemapXMLNS, URLs: urls[(page-1)*sitemapMaxURLs : end]}
+		default:
+			return nil, apperrors.NotFound("Sitemap not found")
+		}
+		return marshalXML(doc)
+	})
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return err
+		}
+		return apperrors.Internal("Failed to build sitemap", err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Send(body)
+}
+
+// sitemapURLs collects every storefront page worth indexing
+func (h *FeedHandler) sitemapURLs(ctx context.Context) ([]sitemapURL, error) {
+	base := strings.TrimSuffix(h.Config.FrontendURL, "/")
+	seen := map[string]bool{}
+	urls := make([]sitemapURL, 0)
+	// path may instead be an absolute canonical URL set by an admin
+	add := func(path string, modified time.Time) {
+		loc := path
+		if !strings.HasPrefix(path, "http") {
+			loc = base + path
+		}
+		if seen[loc] {
+			return
+		}
+		seen[loc] = true
+		u := sitemapURL{Loc: loc}
+		if !modified.IsZero() {
+			u.LastMod = modified.UTC().Format("2006-01-02")
+		}
+		urls = append(urls, u)
+	}
+
+	add("/", time.Time{})
+
+	// Internal links curated on the home page, e.g. collection landing pages
+	var cards []models.HomeCategoryCard
+	if err := h.findAll(ctx, h.DB.MongoDB.Collection(categoryCardsCollectionName), &cards, nil); err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		if strings.HasPrefix(card.Href, "/") {
+			add(card.Href, card.UpdatedAt)
+		}
+	}
+	var features []models.HomeCollectionFeature
+	if err := h.findAll(ctx, h.DB.MongoDB.Collection(collectionFeaturesCollectionName), &features, nil); err != nil {
+		return nil, err
+	}
+	for _, f := range features {
+		if strings.HasPrefix(f.CtaHref, "/") {
+			add(f.CtaHref, f.UpdatedAt)
+		}
+	}
+
+	var categories []models.Category
+	if err := h.findAll(ctx, h.DB.Collections().Categories, &categories, bson.D{{Key: "path", Value: 1}}); err != nil {
+		return nil, err
+	}
+	for _, cat := range categories {
+		add(cat.SEO.WithDefaults("", "", storefrontCategoryPath+cat.Slug).CanonicalURL, cat.UpdatedAt)
+	}
+
+	var brands []models.Brand
+	if err := h.findAll(ctx, h.DB.Collections().Brands, &brands, bson.D{{Key: "slug", Value: 1}}); err != nil {
+		return nil, err
+	}
+	for _, b := range brands {
+		add(storefrontBrandPath+b.Slug, b.UpdatedAt)
+	}
+
+	postCursor, err := h.DB.Collections().Posts.Find(ctx, livePosts(time.Now()),
+		options.Find().
+			SetProjection(bson.M{"slug": 1, "updated_at": 1, "seo.canonical_url": 1}).
+			SetSort(bson.D{{Key: "published_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var posts []models.Post
+	if err := postCursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	for _, p := range posts {
+		add(p.SEO.WithDefaults("", "", storefrontPostPath+p.Slug).CanonicalURL, p.UpdatedAt)
+	}
+
+	cursor, err := h.DB.Collections().Products.Find(ctx,
+		bson.M{"slug": bson.M{"$type": "string"}},
+		options.Find().
+			SetProjection(bson.M{"slug": 1, "updated_at": 1, "seo.canonical_url": 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var p struct {
+			Slug      string      `bson:"slug"`
+			UpdatedAt time.Time   `bson:"updated_at"`
+			SEO       *models.SEO `bson:"seo"`
+		}
+		if err := cursor.Decode(&p); err != nil {
+			return nil, err
+		}
+		add(p.SEO.WithDefaults("", "", storefrontProductPath+p.Slug).CanonicalURL, p.UpdatedAt)
+	}
+	return urls, cursor.Err()
+}
+
+// findAll decodes every document of coll into dst
+func (h *FeedHandler) findAll(ctx context.Context, coll *mongo.Collection, dst interface{}, sort bson.D) error {
+	opts := options.Find()
+	if sort != nil {
+		opts.SetSort(sort)
+	}
+	cursor, err := coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, dst)
+}
+
+// merchantItem is one product of a Google Merchant Center feed. Field names
+// follow the feed specification.
+type merchantItem struct {
+	ID                     string   `xml:"g:id"`
+	Title                  string   `xml:"g:title"`
+	Description            string   `xml:"g:description"`
+	Link                   string   `xml:"g:link"`
+	ImageLink              string   `xml:"g:image_link,omitempty"`
+	AdditionalImageLinks   []string `xml:"g:additional_image_link"`
+	Availability           string   `xml:"g:availability"`
+	Price                  string   `xml:"g:price"`
+	SalePrice              string   `xml:"g:sale_price,omitempty"`
+	SalePriceEffectiveDate string   `xml:"g:sale_price_effective_date,omitempty"`
+	Brand                  string   `xml:"g:brand,omitempty"`
+	Condition              string   `xml:"g:condition"`
+	ProductType            string   `xml:"g:product_type,omitempty"`
+	IdentifierExists       string   `xml:"g:identifier_exists"`
+}
+
+type merchantFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	XmlnsG  string   `xml:"xmlns:g,attr"`
+	Channel struct {
+		Title       string         `xml:"title"`
+		Link        string         `xml:"link"`
+		Description string         `xml:"description"`
+		Items       []merchantItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// merchantMaxAdditionalImages is the feed specification's limit
+const merchantMaxAdditionalImages = 10
+
+// GoogleMerchantFeed exports the catalog as a Google Merchant Center
+// product feed, as RSS 2.0 XML or, with ?format=csv, as CSV
+// GET /catalog/feed/google-merchant?format=xml|csv
+func (h *FeedHandler) GoogleMerchantFeed(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	format := strings.ToLower(c.Query("format", "xml"))
+	if format != "xml" && format != "csv" {
+		return apperrors.BadRequest("format must be xml or csv", nil)
+	}
+
+	body, err := h.cached(ctx, map[string]string{"feed": "google-merchant", "format": format}, func() ([]byte, error) {
+		store, items, err := h.merchantItems(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if format == "csv" {
+			return merchantCSV(items)
+		}
+		var feed merchantFeed
+		feed.Version = "2.0"
+		feed.XmlnsG = "http://base.google.com/ns/1.0"
+		feed.Channel.Title = store.StoreName
+		feed.Channel.Link = strings.TrimSuffix(h.Config.FrontendURL, "/")
+		feed.Channel.Description = store.StoreDescription
+		feed.Channel.Items = items
+		return marshalXML(feed)
+	})
+	if err != nil {
+		return apperrors.Internal("Failed to build product feed", err)
+	}
+
+	if format == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	}
+	return c.Send(body)
+}
+
+// merchantItems maps every product with a slug to a feed item, along with
+// the store settings the feed is titled and priced with
+func (h *FeedHandler) merchantItems(ctx context.Context) (models.Settings, []merchantItem, error) {
+	store := models.Settings{StoreName: "MAK Watches", Currency: "INR"}
+	var saved models.Settings
+	if err := h.DB.MongoDB.Collection("settings").FindOne(ctx, bson.M{}).Decode(&saved); err == nil {
+		if saved.StoreName != "" {
+			store.StoreName = saved.StoreName
+		}
+		if saved.Currency != "" {
+			store.Currency = saved.Currency
+		}
+		store.StoreDescription = saved.StoreDescription
+	}
+
+	cursor, err := h.DB.Collections().Products.Find(ctx,
+		bson.M{"slug": bson.M{"$type": "string"}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return store, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	base := strings.TrimSuffix(h.Config.FrontendURL, "/")
+	price := func(amount float64) string {
+		return fmt.Sprintf("%.2f %s", amount, store.Currency)
+	}
+	items := make([]merchantItem, 0)
+	for cursor.Next(ctx) {
+		var p models.Product
+		if err := cursor.Decode(&p); err != nil {
+			return store, nil, err
+		}
+		item := merchantItem{
+			ID:               p.ID.Hex(),
+			Title:            p.Name,
+			Description:      p.Description,
+			Link:             base + storefrontProductPath + p.Slug,
+			Availability:     "out_of_stock",
+			Price:            price(p.Price),
+			Brand:            p.Brand,
+			Condition:        "new",
+			ProductType:      strings.ReplaceAll(p.Category, models.CategoryPathSeparator, " > "),
+			IdentifierExists: "no",
+		}
+		if p.Stock > 0 {
+			item.Availability = "in_stock"
+		}
+		images := p.Images
+		if len(images) == 0 && p.ImageURL != "" {
+			images = []string{p.ImageURL}
+		}
+		if len(images) > 0 {
+			item.ImageLink = images[0]
+			extra := images[1:]
+			if len(extra) > merchantMaxAdditionalImages {
+				extra = extra[:merchantMaxAdditionalImages]
+			}
+			item.AdditionalImageLinks = extra
+		}
+		if p.IsDiscountActive() && p.GetFinalPrice() < p.Price {
+			item.SalePrice = price(p.GetFinalPrice())
+			if p.DiscountStartDate != nil && p.DiscountEndDate != nil {
+				item.SalePriceEffectiveDate = p.DiscountStartDate.UTC().Format(time.RFC3339) + "/" + p.DiscountEndDate.UTC().Format(time.RFC3339)
+			}
+		}
+		items = append(items, item)
+	}
+	return store, items, cursor.Err()
+}
+
+// merchantCSV renders feed items as CSV with the specification's column
+// names; additional images are comma-separated in one column
+func merchantCSV(items []merchantItem) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"id", "title", "description", "link", "image_link", "additional_image_link", "availability",
+		"price", "sale_price", "sale_price_effective_date", "brand", "condition", "product_type", "identifier_exists",
+	})
+	for _, it := range items {
+		w.Write([]string{
+			it.ID, it.Title, it.Description, it.Link, it.ImageLink, strings.Join(it.AdditionalImageLinks, ","), it.Availability,
+			it.Price, it.SalePrice, it.SalePriceEffectiveDate, it.Brand, it.Condition, it.ProductType, it.IdentifierExists,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// GiftCardHandler serves gift options at checkout and manages gift cards
+type GiftCardHandler struct {
+	DB *database.DBClient
+}
+
+// NewGiftCardHandler creates a new instance of GiftCardHandler
+func NewGiftCardHandler(db *database.DBClient) *GiftCardHandler {
+	return &GiftCardHandler{DB: db}
+}
+
+// loadGiftOptions returns the gift options from the store settings
+func loadGiftOptions(ctx context.Context, db *database.DBClient) (models.GiftOptions, error) {
+	var settings models.Settings
+	err := db.MongoDB.Collection("settings").FindOne(ctx, bson.M{}, options.FindOne().SetProjection(bson.M{"gift_options": 1})).Decode(&settings)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.GiftOptions{}, err
+	}
+	return settings.GiftOptions, nil
+}
+
+// checkoutPrice is what an order costs, in the base currency
+type checkoutPrice struct {
+	Total    float64 // Items and gift wrap
+	Due      float64 // Total less the gift card, charged to the payment method
+	Gift     *models.OrderGift
+	GiftCard *models.OrderGiftCard
+}
+
+// priceCheckout adds the requested gift options to the items total and
+// applies the gift card. Checkout and Razorpay order creation must agree
+// on it, so both price through here.
+func priceCheckout(ctx context.Context, db *database.DBClient, itemsTotal float64, giftWrap bool, giftMessage, giftCardCode string) (checkoutPrice, error) {
+	price := checkoutPrice{Total: itemsTotal}
+
+	giftMessage = strings.TrimSpace(giftMessage)
+	if giftWrap || giftMessage != "" {
+		opts, err := loadGiftOptions(ctx, db)
+		if err != nil {
+			return price, apperrors.Internal("Failed to load gift options", err)
+		}
+		if giftWrap && !opts.WrapEnabled {
+			return price, apperrors.BadRequest("Gift wrapping is not available", nil)
+		}
+		if max := opts.MaxMessageLength(); len([]rune(giftMessage)) > max {
+			return price, apperrors.Validation("Gift message is too long", []validation.FieldError{{
+				Field:   "giftMessage",
+				Rule:    "max",
+				Message: "giftMessage must be at most " + strconv.Itoa(max) + " characters",
+			}})
+		}
+		price.Gift = &models.OrderGift{Wrap: giftWrap, Message: giftMessage}
+		if giftWrap {
+			price.Gift.WrapPrice = opts.WrapPrice
+			price.Total += opts.WrapPrice
+		}
+	}
+
+	price.Due = price.Total
+	if strings.TrimSpace(giftCardCode) != "" {
+		card, err := giftcards.Find(ctx, db, giftCardCode)
+		if err != nil {
+			if errors.Is(err, giftcards.ErrUnavailable) {
+				return price, apperrors.BadRequest("Gift card is invalid, expired or used up", nil)
+			}
+			return price, apperrors.Internal("Failed to look up gift card", err)
+		}
+		amount := giftcards.Applied(card, price.Total)
+		price.GiftCard = &models.OrderGiftCard{Code: card.Code, Amount: amount}
+		price.Due -= amount
+	}
+	return price, nil
+}
+
+// GetGiftOptions tells checkout whether gift wrapping is offered, at what
+// price and how long gift messages may be
+// GET /checkout/gift-options?currency=USD
+func (h *GiftCardHandler) GetGiftOptions(c *fiber.Ctx) error {
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	opts, err := loadGiftOptions(c.Context(), h.DB)
+	if err != nil {
+		return apperrors.Internal("Failed to load gift options", err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Gift options retrieved successfully",
+		"data": fiber.Map{
+			"wrapEnabled":   opts.WrapEnabled,
+			"wrapPrice":     currency.Convert(opts.WrapPrice),
+			"messageLength": opts.MaxMessageLength(),
+			"currency":      currency.Code,
+		},
+	})
+}
+
+// CheckGiftCard returns the balance of a gift card a customer entered
+// GET /gift-cards/:code?currency=USD
+func (h *GiftCardHandler) CheckGiftCard(c *fiber.Ctx) error {
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	card, err := giftcards.Find(c.Context(), h.DB, c.Params("code"))
+	if err != nil {
+		if errors.Is(err, giftcards.ErrUnavailable) {
+			return apperrors.NotFound("Gift card is invalid, expired or used up")
+		}
+		return apperrors.Internal("Failed to look up gift card", err)
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Gift card retrieved successfully",
+		"data": fiber.Map{
+			"code":      card.Code,
+			"balance":   currency.Convert(card.Balance),
+			"currency":  currency.Code,
+			"expiresAt": card.ExpiresAt,
+		},
+	})
+}
+
+// GetGiftCards lists gift cards, newest first
+// GET /admin/gift-cards?code=&active=true&page=1&limit=50
+func (h *GiftCardHandler) GetGiftCards(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := bson.M{}
+	if code := giftcards.Normalize(c.Query("code")); code != "" {
+		filter["code"] = bson.M{"$regex": "^" + code}
+	}
+	if c.QueryBool("active") {
+		filter["disabled"] = false
+		filter["balance"] = bson.M{"$gt": 0}
+		filter["$or"] = bson.A{bson.M{"expires_at": nil}, bson.M{"expires_at": bson.M{"$gt": time.Now()}}}
+	}
+
+	coll := h.DB.Collections().GiftCards
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count gift cards", err)
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch gift cards", err)
+	}
+	cards := []models.GiftCard{}
+	if err := cursor.All(ctx, &cards); err != nil {
+		return apperrors.Internal("Failed to decode gift cards", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Gift cards retrieved successfully",
+		"data":    cards,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// IssueGiftCard issues a gift card with a balance in the base currency
+// POST /admin/gift-cards
+func (h *GiftCardHandler) IssueGiftCard(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.IssueGiftCardRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return apperrors.BadRequest("expiresAt must be in the future", nil)
+	}
+
+	now := time.Now()
+	card := models.GiftCard{
+		ID:             primitive.NewObjectID(),
+		Code:           giftcards.Normalize(req.Code),
+		InitialBalance: req.Amount,
+		Balance:        req.Amount,
+		ExpiresAt:      req.ExpiresAt,
+		Note:           strings.TrimSpace(req.Note),
+		Ledger:         []models.GiftCardEntry{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		card.IssuedBy = actor.UserID
+	}
+	generated := card.Code == ""
+	if generated {
+		card.Code = giftcards.NewCode()
+	}
+	if _, err := h.DB.Collections().GiftCards.InsertOne(ctx, card); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			if generated {
+				return apperrors.Internal("Failed to generate a unique gift card code, try again", err)
+			}
+			return apperrors.Conflict("A gift card with this code already exists")
+		}
+		return apperrors.Internal("Failed to issue gift card", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "gift_card.issue", "gift_card", card.ID.Hex(), nil, card)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Gift card issued successfully",
+		"data":    card,
+	})
+}
+
+// UpdateGiftCard disables or re-enables a gift card or changes its expiry.
+// Balances only change through orders.
+// PATCH /admin/gift-cards/:id
+func (h *GiftCardHandler) UpdateGiftCard(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid gift card ID", err)
+	}
+	var req models.UpdateGiftCardRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if req.Disabled != nil {
+		set["disabled"] = *req.Disabled
+	}
+	if req.ExpiresAt != nil {
+		set["expires_at"] = req.ExpiresAt
+	}
+	if req.Note != nil {
+		set["note"] = strings.TrimSpace(*req.Note)
+	}
+
+	var before models.GiftCard
+	err = h.DB.Collections().GiftCards.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set}).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Gift card not found")
+		}
+		return apperrors.Internal("Failed to update gift card", err)
+	}
+	var card models.GiftCard
+	if err := h.DB.Collections().GiftCards.FindOne(ctx, bson.M{"_id": id}).Decode(&card); err != nil {
+		return apperrors.Internal("Failed to retrieve gift card", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "gift_card.update", "gift_card", id.Hex(), before, card)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Gift card updated successfully",
+		"data":    card,
+	})
+}
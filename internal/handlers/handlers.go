@@ -7,85 +7,232 @@ import (
 
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/docs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/realtime"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
+// APIVersionPrefix is where the current API version is mounted
+const APIVersionPrefix = "/api/v1"
+
 // SetupRoutes configures all application routes
-func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
+// The warmer is optional; when set it learns which listings to keep warm.
+func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config, queue *jobs.Queue, store storage.Storage, bus *events.Bus, hub *realtime.Hub, warmer *CacheWarmer) {
 	// Middleware
 	app.Use(logger.New())
 	app.Use(recover.New())
-
-	// Health check endpoint
-	app.Get("/health", HealthHandler)
+	// JSON bodies are capped at MAX_BODY_KB and file uploads at MAX_UPLOAD_MB;
+	// upload routes below set tighter caps for what they accept
+	app.Use(middleware.BodyLimits(cfg.MaxBodyKB*1024, cfg.MaxUploadMB*1024*1024))
+	// Stored file URLs go out signed or on the CDN when configured
+	if delivery := storage.NewDelivery(store, cfg); delivery != nil {
+		app.Use(middleware.StorageURLs(delivery))
+	}
+
+	// Health checks: /health/live for liveness probes, /health/ready (and
+	// /health) for readiness probes and uptime monitors
+	healthHandler := NewHealthHandler(db, store)
+	app.Get("/health", healthHandler.Ready)
+	app.Get("/health/live", healthHandler.Live)
+	app.Get("/health/ready", healthHandler.Ready)
 
 	// Welcome endpoint
 	app.Get("/welcome", WelcomeHandler)
 
+	// API documentation (Swagger UI + OpenAPI spec)
+	docs.Register(app, "/docs")
+
+	// Sitemap for search engines, at the root where crawlers look for it
+	feedHandler := NewFeedHandler(db, cfg)
+	app.Get("/sitemap.xml", feedHandler.Sitemap)
+	app.Get("/sitemap-:page.xml", feedHandler.SitemapPage)
+
+	// Public keys for services verifying the API's tokens (empty with HS256)
+	app.Get("/.well-known/jwks.json", JWKSHandler(cfg.JWTKeys))
+
+	// Uploaded files are served outside the versioned API
+	app.Static("/uploads", "uploads")
+
+	// Versioned API. A future breaking change gets its own group (e.g. /api/v2)
+	// registered alongside this one.
+	registerAPIRoutes(app.Group(APIVersionPrefix), db, cfg, queue, store, bus, hub, warmer)
+
+	// Legacy unversioned paths, kept as deprecated aliases until clients migrate.
+	// Registered last so their catch-all middleware never shadows /api/v1.
+	if cfg.EnableLegacyRoutes {
+		registerAPIRoutes(app.Group("", middleware.Deprecated(APIVersionPrefix)), db, cfg, queue, store, bus, hub, warmer)
+	}
+}
+
+// registerAPIRoutes mounts every API endpoint on r
+func registerAPIRoutes(r fiber.Router, db *database.DBClient, cfg *config.Config, queue *jobs.Queue, store storage.Storage, bus *events.Bus, hub *realtime.Hub, warmer *CacheWarmer) {
 	// Initialize handlers
 	authHandler := NewAuthHandler(db, cfg)
+	authHandler.Jobs = queue // verification emails are delivered by the job queue
 	productHandler := NewProductHandler(db, cfg)
-	// upload handler is plain function
+	productHandler.Storage = store
+	productHandler.Events = bus
+	productHandler.Warmer = warmer
 	cartHandler := NewCartHandler(db, cfg)
 	orderHandler := NewOrderHandler(db, cfg)
+	orderHandler.Jobs = queue
+	orderHandler.Events = bus
 	paymentHandler := NewPaymentHandler(db, cfg)
+	paymentHandler.Events = bus
 	recHandler := NewRecommendationHandler(db, cfg)
 	userProfileHandler := NewUserProfileHandler(db, cfg)
 	wishlistHandler := NewWishlistHandler(db, cfg)
+	wishlistHandler.Events = bus
 	addressBookHandler := NewAddressBookHandler(db, cfg)
-	adminAccountHandler := &AdminAccountHandler{DB: db}
+	addressBookHandler.Events = bus
+	adminAccountHandler := &AdminAccountHandler{DB: db, Config: cfg}
 	categoryHandler := NewCategoryHandler(db, cfg)
-	homeContentHandler := NewHomeContentHandler(db)
+	homeContentHandler := NewHomeContentHandler(db, cfg)
+	auditLogHandler := NewAuditLogHandler(db)
+	inventoryHandler := NewInventoryHandler(db, cfg)
+	jobHandler := NewJobHandler(db, queue)
+	reportHandler := NewReportHandler(db, cfg, queue)
+	campaignHandler := NewCampaignHandler(db)
+	storageHandler := NewStorageHandler(db, cfg, store)
+	mediaHandler := NewMediaHandler(db, store)
+	retentionHandler := NewRetentionHandler(db, cfg)
+	returnHandler := NewReturnHandler(db, cfg)
+	warrantyHandler := NewWarrantyHandler(db, cfg)
+	returnHandler.Storage = store
+	returnHandler.Events = bus
+	webhookHandler := NewWebhookHandler(db, queue)
+	realtimeHandler := NewRealtimeHandler(hub)
+	notificationHandler := NewNotificationHandler(db)
+	brandHandler := NewBrandHandler(db)
+	storeHandler := NewStoreHandler(db)
+	authenticityHandler := NewAuthenticityHandler(db)
+	contactHandler := NewContactHandler(db, cfg)
+	contactHandler.Jobs = queue
+	postHandler := NewPostHandler(db, cfg)
+	faqHandler := NewFAQHandler(db)
+	feedHandler := NewFeedHandler(db, cfg)
+	currencyHandler := NewCurrencyHandler(db)
+	apiKeyHandler := NewAPIKeyHandler(db)
+	roleHandler := NewRoleHandler(db)
+	giftCardHandler := NewGiftCardHandler(db)
+	featureFlagHandler := NewFeatureFlagHandler(db)
+	productAttributeHandler := NewProductAttributeHandler(db)
+	translationHandler := NewTranslationHandler(db, cfg)
+	supportHandler := NewSupportHandler(db)
+	supportHandler.Events = bus
+
+	// can lets through accounts whose role grants any of the permissions
+	can := func(permissions ...string) fiber.Handler {
+		return middleware.Permission(db, permissions...)
+	}
 
 	// Auth routes
-	auth := app.Group("/auth")
+	auth := r.Group("/auth")
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
 	auth.Get("/google", authHandler.GoogleLogin)
 	auth.Get("/google/callback", authHandler.GoogleCallback)
+	auth.Post("/otp/request", authHandler.RequestOTP)
+	auth.Post("/otp/verify", authHandler.VerifyOTP)
+	auth.Get("/verify-email", authHandler.VerifyEmail)
+	auth.Post("/password/forgot", authHandler.ForgotPassword)
+	auth.Post("/password/reset", authHandler.ResetPassword)
 
 	// Product routes
-	products := app.Group("/products")
+	products := r.Group("/products")
 	products.Get("/", productHandler.GetProducts)
 	products.Get("/:id", productHandler.GetProductByID)
 	// Product reviews (public)
 	// GET /products/:id/reviews
 	// Use ReviewHandler to serve product-level reviews
 	reviewHandler := NewReviewHandler(db, cfg)
-	products.Get("/:productId/reviews", reviewHandler.GetProductReviews)
+	reviewHandler.Storage = store
+	reviewHandler.Events = bus
+	products.Get("/:productId/reviews", middleware.OptionalAuth(cfg.JWTKeys, db), reviewHandler.GetProductReviews)
+	products.Get("/:productId/reviews/summary", reviewHandler.GetReviewSummary)
+	// Product Q&A: anyone can read, signed-in customers can ask
+	questionHandler := NewProductQuestionHandler(db)
+	products.Get("/:productId/questions", questionHandler.GetProductQuestions)
+	products.Post("/:productId/questions", middleware.Auth(cfg.JWTKeys, db), questionHandler.AskQuestion)
 
 	// Public catalog (optimized) product routes
-	catalog := app.Group("/catalog")
+	catalog := r.Group("/catalog")
 	catalog.Get("/products", productHandler.GetPublicProducts)
+	catalog.Get("/products/slug/:slug", productHandler.GetPublicProductBySlug)
 	catalog.Get("/products/:id", productHandler.GetPublicProductByID)
+	catalog.Get("/products/:id/related", productHandler.GetRelatedProducts)
+	catalog.Get("/products/:id/availability", productHandler.GetProductAvailability)
 	catalog.Get("/filters", productHandler.GetCatalogFilters)
+	catalog.Get("/trending", productHandler.GetTrendingProducts)
+	catalog.Get("/brands", brandHandler.GetBrands)
+	catalog.Get("/brands/:slug/products", productHandler.GetBrandProducts)
+	catalog.Get("/feed/google-merchant", feedHandler.GoogleMerchantFeed)
+	catalog.Get("/currencies", currencyHandler.GetCurrencies)
 
 	// Public category routes (no auth) - read-only for storefront
-	app.Get("/categories", categoryHandler.GetPublicCategories)
-	app.Get("/categories/:name/subcategories", categoryHandler.GetPublicSubcategories)
-	app.Get("/home-content", homeContentHandler.GetHomeContent)
-
-	// Public (or auth-protected) upload route for admin (requires auth+role)
-	app.Static("/uploads", "uploads")
-	app.Post("/upload", middleware.Auth(cfg.JWTSecret), middleware.Role("admin"), UploadHandler)
-
-	// Admin product routes (must authenticate first, then role check)
-	adminProducts := products.Group("/", middleware.Auth(cfg.JWTSecret), middleware.Role("admin"))
+	r.Get("/categories", categoryHandler.GetPublicCategories)
+	r.Get("/categories/:slug", categoryHandler.GetPublicCategory)
+	r.Get("/categories/:name/subcategories", categoryHandler.GetPublicSubcategories)
+	r.Get("/home-content", homeContentHandler.GetHomeContent)
+	r.Get("/stores", storeHandler.GetStores)
+	r.Get("/stores/nearby", storeHandler.GetNearbyStores)
+	// Genuine serial number check; signing in registers the watch to the customer
+	r.Post("/verify-authenticity", middleware.OptionalAuth(cfg.JWTKeys, db), authenticityHandler.VerifyAuthenticity)
+	r.Post("/contact", contactHandler.SubmitContactMessage)
+
+	// Journal articles
+	r.Get("/content/posts", postHandler.GetPosts)
+	r.Get("/content/posts/:slug", postHandler.GetPost)
+	r.Get("/faqs", faqHandler.GetFAQs)
+
+	// COD confirmation link from the order email (the token authenticates it)
+	r.Get("/orders/:orderID/confirm-cod", orderHandler.ConfirmCODLink)
+
+	// Upload route for staff editing products or content (requires auth+permission)
+	uploaders := can(models.PermissionProductsWrite, models.PermissionContentWrite)
+	r.Post("/upload", middleware.Auth(cfg.JWTKeys, db), uploaders, middleware.BodyLimit(uploadBodyLimit(maxImageFiles)), UploadHandler(db, store))
+	r.Post("/upload/images", middleware.Auth(cfg.JWTKeys, db), uploaders, middleware.BodyLimit(uploadBodyLimit(maxImageFiles)), NewImageUploadHandler(db, store).UploadImages)
+
+	// Admin product routes (must authenticate first, then permission check)
+	adminProducts := products.Group("/", middleware.Auth(cfg.JWTKeys, db), can(models.PermissionProductsWrite))
 	adminProducts.Post("/", productHandler.CreateProduct)
 	adminProducts.Put("/:id", productHandler.UpdateProduct)
 	adminProducts.Delete("/:id", productHandler.DeleteProduct)
 
+	// Integrations authenticate with an X-API-Key instead of a user token.
+	// Registered before the protected routes so their JWT check never runs.
+	catalogKey := middleware.APIKeyAuth(db, models.APIScopeCatalogRead)
+	ordersKey := middleware.APIKeyAuth(db, models.APIScopeOrdersRead)
+	integrations := r.Group("/integrations")
+	integrations.Get("/products", catalogKey, productHandler.GetPublicProducts)
+	integrations.Get("/products/:id", catalogKey, productHandler.GetPublicProductByID)
+	integrations.Get("/categories", catalogKey, categoryHandler.GetPublicCategories)
+	integrations.Get("/orders", ordersKey, orderHandler.GetAllOrders)
+	integrations.Get("/orders/export", ordersKey, orderHandler.ExportOrders)
+	integrations.Get("/orders/:orderID", ordersKey, orderHandler.GetOrder)
+
 	// Protected routes
-	api := app.Group("/", middleware.Auth(cfg.JWTSecret))
+	api := r.Group("/", middleware.Auth(cfg.JWTKeys, db))
 
 	// Review routes (authenticated)
 	// POST /reviews -> CreateReview
 	reviews := api.Group("/reviews")
 	reviews.Post("/", reviewHandler.CreateReview)
+	reviews.Post("/uploads", middleware.BodyLimit(uploadBodyLimit(maxReviewPhotos)), reviewHandler.UploadPhotos)
+	reviews.Post("/photos", middleware.BodyLimit(uploadBodyLimit(maxReviewPhotos)), reviewHandler.UploadPhotos)
 	// Optional: allow updating/deleting reviews by owner
 	reviews.Put("/:id", reviewHandler.UpdateReview)
 	reviews.Delete("/:id", reviewHandler.DeleteReview)
 	reviews.Post("/:id/helpful", reviewHandler.MarkReviewHelpful)
+	reviews.Post("/:id/not-helpful", reviewHandler.MarkReviewNotHelpful)
+
+	// Product question upvotes
+	api.Post("/questions/:id/upvote", questionHandler.UpvoteQuestion)
 
 	// User "me" endpoint
 	api.Get("/me", authHandler.Me)
@@ -94,37 +241,223 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 	cart := api.Group("/cart")
 	cart.Post("/", cartHandler.AddToCart)
 	cart.Get("/:userID", cartHandler.GetCart)
+	cart.Put("/:userID/:productID", cartHandler.UpdateCartItem)
 	cart.Delete("/:userID/:productID", cartHandler.RemoveFromCart)
 
 	// Order routes
 	orders := api.Group("/orders")
 	orders.Get("/user/:userID", orderHandler.GetOrders)
 	orders.Get("/:orderID", orderHandler.GetOrder)
+	orders.Patch("/:orderID", orderHandler.EditOrder)
 	orders.Post("/:orderID/cancel", orderHandler.CancelOrder)
-	// Admin-only: get all orders, update status
-	orders.Get("/", middleware.Role("admin"), orderHandler.GetAllOrders)
-	orders.Patch("/:orderID/status", middleware.Role("admin"), orderHandler.UpdateOrderStatus)
+	orders.Post("/:orderID/verify-cod", orderHandler.VerifyCOD)
+	orders.Post("/:orderID/verify-cod/resend", orderHandler.ResendCODVerification)
+	orders.Post("/:orderID/return", returnHandler.CreateReturn)
+
+	// Return routes
+	returns := api.Group("/returns")
+	returns.Get("/", returnHandler.GetMyReturns)
+	returns.Post("/photos", middleware.BodyLimit(uploadBodyLimit(maxReturnPhotos)), returnHandler.UploadPhotos)
+	returns.Get("/:id", returnHandler.GetReturn)
+
+	// Support tickets
+	tickets := api.Group("/support/tickets")
+	tickets.Get("/", supportHandler.GetMyTickets)
+	tickets.Post("/", supportHandler.CreateTicket)
+	tickets.Get("/:id", supportHandler.GetTicket)
+	tickets.Post("/:id/messages", supportHandler.ReplyToTicket)
+
+	// Notification center
+	notifications := api.Group("/notifications")
+	notifications.Get("/", notificationHandler.GetNotifications)
+	notifications.Patch("/read-all", notificationHandler.MarkAllRead)
+	notifications.Patch("/:id/read", notificationHandler.MarkRead)
+
+	// Staff only: get all orders, update status
+	orders.Get("/", can(models.PermissionOrdersRead), orderHandler.GetAllOrders)
+	orders.Patch("/:orderID/status", can(models.PermissionOrdersWrite), orderHandler.UpdateOrderStatus)
 
 	// Payment routes
 	payments := api.Group("/payments")
 	payments.Post("/razorpay/order", paymentHandler.CreateRazorpayOrder)
+	payments.Get("/razorpay/order/:id/status", paymentHandler.GetRazorpayOrderStatus)
+	payments.Get("/methods", paymentHandler.GetPaymentMethods)
 
 	// Public webhook endpoint for Razorpay (Razorpay will POST here)
-	app.Post("/webhooks/razorpay", paymentHandler.RazorpayWebhook)
-
-	// Admin only routes (must authenticate first, then check role)
-	admin := app.Group("/admin", middleware.Auth(cfg.JWTSecret), middleware.Role("admin"))
-	admin.Get("/accounts", adminAccountHandler.GetAllAccounts)
-	admin.Delete("/accounts/:id", adminAccountHandler.DeleteAccount)
+	r.Post("/webhooks/razorpay", paymentHandler.RazorpayWebhook)
+
+	// Staff routes (must authenticate first, then every route checks its
+	// own permission)
+	admin := r.Group("/admin", middleware.Auth(cfg.JWTKeys, db), middleware.Staff())
+	admin.Get("/me", roleHandler.GetMyPermissions)
+	admin.Get("/accounts", can(models.PermissionCustomersRead), adminAccountHandler.GetAllAccounts)
+	admin.Get("/customers", can(models.PermissionCustomersRead), adminAccountHandler.GetCustomers)
+	admin.Get("/customers/export", can(models.PermissionCustomersRead), adminAccountHandler.ExportCustomers)
+	admin.Delete("/accounts/:id", can(models.PermissionCustomersWrite), adminAccountHandler.DeleteAccount)
+	admin.Patch("/accounts/:id/role", can(models.PermissionRolesWrite), adminAccountHandler.UpdateAccountRole)
+	admin.Patch("/accounts/:id/status", can(models.PermissionCustomersWrite), adminAccountHandler.UpdateAccountStatus)
+	admin.Post("/accounts/:id/revoke-sessions", can(models.PermissionCustomersWrite), adminAccountHandler.RevokeAccountSessions)
+	admin.Post("/accounts/:id/unlock", can(models.PermissionCustomersWrite), adminAccountHandler.UnlockAccount)
+	admin.Post("/accounts/:id/impersonate", can(models.PermissionCustomersWrite), adminAccountHandler.ImpersonateAccount)
+	admin.Post("/orders", can(models.PermissionOrdersWrite), orderHandler.AdminCreateOrder)
+	admin.Patch("/orders/bulk-status", can(models.PermissionOrdersWrite), orderHandler.BulkUpdateOrderStatus)
+	admin.Get("/orders/export", can(models.PermissionOrdersRead), orderHandler.ExportOrders)
+	admin.Patch("/orders/:orderID/verify", can(models.PermissionOrdersWrite), orderHandler.AdminVerifyOrder)
+	admin.Get("/returns", can(models.PermissionOrdersRead), returnHandler.GetAllReturns)
+	admin.Get("/returns/:id", can(models.PermissionOrdersRead), returnHandler.GetReturn)
+	admin.Patch("/returns/:id/status", can(models.PermissionOrdersWrite), returnHandler.UpdateReturnStatus)
+	admin.Get("/warranties", can(models.PermissionOrdersRead), warrantyHandler.LookupWarranties)
+	admin.Post("/warranties/:id/extend", can(models.PermissionOrdersWrite), warrantyHandler.ExtendWarranty)
+	admin.Put("/warranties/:id/serial", can(models.PermissionOrdersWrite), warrantyHandler.SetWarrantySerial)
+
+	// Contact form inbox
+	admin.Get("/contact-messages", can(models.PermissionSupportWrite), contactHandler.GetContactMessages)
+	admin.Patch("/contact-messages/:id", can(models.PermissionSupportWrite), contactHandler.UpdateContactMessageStatus)
+
+	// Support inbox
+	admin.Get("/support/tickets", can(models.PermissionSupportWrite), supportHandler.GetInbox)
+	admin.Get("/support/tickets/:id", can(models.PermissionSupportWrite), supportHandler.GetTicket)
+	admin.Post("/support/tickets/:id/messages", can(models.PermissionSupportWrite), supportHandler.StaffReply)
+	admin.Patch("/support/tickets/:id/status", can(models.PermissionSupportWrite), supportHandler.UpdateTicketStatus)
+
+	// Roles and their permissions
+	admin.Get("/roles", can(models.PermissionRolesWrite), roleHandler.GetRoles)
+	admin.Put("/roles/:name", can(models.PermissionRolesWrite), roleHandler.PutRole)
+	admin.Delete("/roles/:name", can(models.PermissionRolesWrite), roleHandler.DeleteRole)
+
+	// Gift cards: issued by admins, spent at checkout
+	admin.Get("/gift-cards", can(models.PermissionOrdersWrite), giftCardHandler.GetGiftCards)
+	admin.Post("/gift-cards", can(models.PermissionOrdersWrite), giftCardHandler.IssueGiftCard)
+	admin.Patch("/gift-cards/:id", can(models.PermissionOrdersWrite), giftCardHandler.UpdateGiftCard)
+
+	// Feature flags and their percentage rollouts
+	admin.Get("/feature-flags", can(models.PermissionSettingsWrite), featureFlagHandler.GetFeatureFlags)
+	admin.Put("/feature-flags/:key", can(models.PermissionSettingsWrite), featureFlagHandler.PutFeatureFlag)
+	admin.Delete("/feature-flags/:key", can(models.PermissionSettingsWrite), featureFlagHandler.DeleteFeatureFlag)
+
+	// Product Q&A moderation
+	admin.Get("/questions", can(models.PermissionProductsWrite), questionHandler.GetQuestions)
+	admin.Put("/questions/:id/answer", can(models.PermissionProductsWrite), questionHandler.AnswerQuestion)
+	admin.Delete("/questions/:id", can(models.PermissionProductsWrite), questionHandler.DeleteQuestion)
+	admin.Post("/reviews/:id/reply", can(models.PermissionProductsWrite), reviewHandler.CreateReviewReply)
+	admin.Put("/reviews/:id/reply", can(models.PermissionProductsWrite), reviewHandler.UpdateReviewReply)
+	admin.Delete("/reviews/:id/reply", can(models.PermissionProductsWrite), reviewHandler.DeleteReviewReply)
+
+	// Brands
+	admin.Get("/brands", can(models.PermissionProductsWrite), brandHandler.GetBrands)
+	admin.Post("/brands", can(models.PermissionProductsWrite), brandHandler.CreateBrand)
+	admin.Put("/brands/:id", can(models.PermissionProductsWrite), brandHandler.UpdateBrand)
+	admin.Delete("/brands/:id", can(models.PermissionProductsWrite), brandHandler.DeleteBrand)
+
+	// Currencies and exchange rates
+	admin.Get("/currencies", can(models.PermissionSettingsWrite), currencyHandler.GetAllCurrencies)
+	admin.Put("/currencies/:code", can(models.PermissionSettingsWrite), currencyHandler.PutCurrency)
+	admin.Delete("/currencies/:code", can(models.PermissionSettingsWrite), currencyHandler.DeleteCurrency)
 
 	// Settings routes
-	settingsHandler := NewSettingsHandler(db.MongoDB)
-	admin.Get("/settings", settingsHandler.GetSettings())
-	admin.Put("/settings", settingsHandler.UpdateSettings())
-	admin.Post("/settings/logo", settingsHandler.UploadLogo())
+	settingsHandler := NewSettingsHandler(db.MongoDB, store)
+	settingsHandler.Config = cfg
+	admin.Get("/settings", can(models.PermissionSettingsWrite), settingsHandler.GetSettings())
+	admin.Put("/settings", can(models.PermissionSettingsWrite), settingsHandler.UpdateSettings())
+	admin.Post("/settings/logo", can(models.PermissionSettingsWrite), middleware.BodyLimit(uploadBodyLimit(1)), settingsHandler.UploadLogo())
+
+	// Audit trail of admin mutations
+	admin.Get("/audit-logs", can(models.PermissionAuditRead), auditLogHandler.GetAuditLogs)
+
+	// Inventory dashboard (stock, reserved units, reorder thresholds)
+	admin.Get("/inventory", can(models.PermissionProductsWrite), inventoryHandler.GetInventory)
+
+	// Copies of a product to start a similar one from
+	admin.Post("/products/:id/duplicate", can(models.PermissionProductsWrite), productHandler.DuplicateProduct)
+
+	// Stock ledger: manual adjustments and per-product movement history
+	admin.Post("/products/:id/stock-adjustments", can(models.PermissionProductsWrite), productHandler.AdjustStock)
+	admin.Patch("/products/stock", can(models.PermissionProductsWrite), productHandler.BulkUpdateStock)
+	admin.Post("/products/:id/preorders/allocate", can(models.PermissionProductsWrite), productHandler.AllocatePreorders)
+	admin.Get("/products/:id/stock-movements", can(models.PermissionProductsWrite), productHandler.GetStockMovements)
+	admin.Get("/products/:id/price-history", can(models.PermissionProductsWrite), productHandler.GetPriceHistory)
+
+	// Allowed values of the filterable product attributes
+	admin.Get("/product-attributes", can(models.PermissionProductsWrite), productAttributeHandler.GetProductAttributes)
+	admin.Put("/product-attributes/:key", can(models.PermissionProductsWrite), productAttributeHandler.PutProductAttribute)
+	admin.Delete("/product-attributes/:key", can(models.PermissionProductsWrite), productAttributeHandler.DeleteProductAttribute)
+
+	// Registry of genuine serial numbers checked by /verify-authenticity
+	admin.Get("/products/:id/serials", can(models.PermissionProductsWrite), authenticityHandler.GetProductSerials)
+	admin.Post("/products/:id/serials", can(models.PermissionProductsWrite), authenticityHandler.AddProductSerials)
+	admin.Delete("/products/:id/serials/:serialId", can(models.PermissionProductsWrite), authenticityHandler.DeleteProductSerial)
+
+	// Discount campaigns applied in bulk to product discount fields
+	adminCampaigns := admin.Group("/campaigns", can(models.PermissionProductsWrite))
+	adminCampaigns.Get("/", campaignHandler.GetCampaigns)
+	adminCampaigns.Post("/", campaignHandler.CreateCampaign)
+	adminCampaigns.Get("/:id", campaignHandler.GetCampaign)
+	adminCampaigns.Put("/:id", campaignHandler.UpdateCampaign)
+	adminCampaigns.Delete("/:id", campaignHandler.DeleteCampaign)
+
+	// Background job queue inspection and dead-letter management
+	admin.Get("/jobs/stats", can(models.PermissionSettingsWrite), jobHandler.GetStats)
+	admin.Get("/jobs/dead", can(models.PermissionSettingsWrite), jobHandler.GetDeadJobs)
+	admin.Post("/jobs/dead/:id/retry", can(models.PermissionSettingsWrite), jobHandler.RetryDeadJob)
+	admin.Delete("/jobs/dead/:id", can(models.PermissionSettingsWrite), jobHandler.DeleteDeadJob)
+
+	// Sales digests, sent on demand (scheduled sending is a background job)
+	admin.Post("/reports/run", can(models.PermissionSettingsWrite), reportHandler.RunReport)
+
+	// Live new-order, payment and support message notifications (Server-Sent Events)
+	admin.Get("/events", can(models.PermissionOrdersRead, models.PermissionSupportWrite), realtimeHandler.StreamAdminEvents)
+
+	// API keys for integrations
+	admin.Get("/api-keys", can(models.PermissionSettingsWrite), apiKeyHandler.GetAPIKeys)
+	admin.Post("/api-keys", can(models.PermissionSettingsWrite), apiKeyHandler.CreateAPIKey)
+	admin.Delete("/api-keys/:id", can(models.PermissionSettingsWrite), apiKeyHandler.RevokeAPIKey)
+
+	// Outbound webhooks
+	admin.Get("/webhooks", can(models.PermissionSettingsWrite), webhookHandler.GetWebhooks)
+	admin.Post("/webhooks", can(models.PermissionSettingsWrite), webhookHandler.CreateWebhook)
+	admin.Get("/webhooks/:id", can(models.PermissionSettingsWrite), webhookHandler.GetWebhook)
+	admin.Put("/webhooks/:id", can(models.PermissionSettingsWrite), webhookHandler.UpdateWebhook)
+	admin.Delete("/webhooks/:id", can(models.PermissionSettingsWrite), webhookHandler.DeleteWebhook)
+	admin.Post("/webhooks/:id/rotate-secret", can(models.PermissionSettingsWrite), webhookHandler.RotateWebhookSecret)
+	admin.Post("/webhooks/:id/test", can(models.PermissionSettingsWrite), webhookHandler.TestWebhook)
+
+	// Stored files
+	admin.Get("/storage/orphans", can(models.PermissionSettingsWrite), storageHandler.GetOrphans)
+
+	// Data retention policies and what the pruning job has purged
+	admin.Get("/retention", can(models.PermissionSettingsWrite), retentionHandler.GetRetention)
+
+	// Media library of staff uploads, open to whoever may upload
+	admin.Get("/media", uploaders, mediaHandler.GetMedia)
+	admin.Delete("/media/:id", uploaders, mediaHandler.DeleteMedia)
+
+	// Translations of home content and products; each resource checks its own permission
+	translators := can(models.PermissionProductsWrite, models.PermissionContentWrite)
+	admin.Get("/translations/:resource/:id", translators, translationHandler.GetTranslations)
+	admin.Put("/translations/:resource/:id/:locale", translators, translationHandler.PutTranslation)
+	admin.Delete("/translations/:resource/:id/:locale", translators, translationHandler.DeleteTranslation)
+
+	// Journal posts
+	admin.Get("/posts", can(models.PermissionContentWrite), postHandler.ListPosts)
+	admin.Get("/posts/:id", can(models.PermissionContentWrite), postHandler.GetPostByID)
+	admin.Post("/posts", can(models.PermissionContentWrite), postHandler.CreatePost)
+	admin.Put("/posts/:id", can(models.PermissionContentWrite), postHandler.UpdatePost)
+	admin.Delete("/posts/:id", can(models.PermissionContentWrite), postHandler.DeletePost)
+
+	// Help page FAQs
+	admin.Get("/faqs", can(models.PermissionContentWrite), faqHandler.ListFAQs)
+	admin.Post("/faqs", can(models.PermissionContentWrite), faqHandler.CreateFAQ)
+	admin.Put("/faqs/:id", can(models.PermissionContentWrite), faqHandler.UpdateFAQ)
+	admin.Delete("/faqs/:id", can(models.PermissionContentWrite), faqHandler.DeleteFAQ)
+
+	// Store locator boutiques
+	admin.Get("/stores", can(models.PermissionContentWrite), storeHandler.ListStores)
+	admin.Post("/stores", can(models.PermissionContentWrite), storeHandler.CreateStore)
+	admin.Put("/stores/:id", can(models.PermissionContentWrite), storeHandler.UpdateStore)
+	admin.Delete("/stores/:id", can(models.PermissionContentWrite), storeHandler.DeleteStore)
 
 	// Home content management routes
-	adminHome := admin.Group("/home-content")
+	adminHome := admin.Group("/home-content", can(models.PermissionContentWrite))
 	adminHome.Get("/hero-slides", homeContentHandler.ListHeroSlides)
 	adminHome.Post("/hero-slides", homeContentHandler.CreateHeroSlide)
 	adminHome.Put("/hero-slides/:id", homeContentHandler.UpdateHeroSlide)
@@ -154,24 +487,35 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 	adminHome.Put("/gallery/:id", homeContentHandler.UpdateGalleryImage)
 	adminHome.Delete("/gallery/:id", homeContentHandler.DeleteGalleryImage)
 
+	// Customer testimonials
+	adminHome.Get("/testimonials", homeContentHandler.ListTestimonials)
+	adminHome.Post("/testimonials", homeContentHandler.CreateTestimonial)
+	adminHome.Put("/testimonials/:id", homeContentHandler.UpdateTestimonial)
+	adminHome.Delete("/testimonials/:id", homeContentHandler.DeleteTestimonial)
+
+	// Whole home page as one bundle, to promote a design from staging
+	adminHome.Get("/export", homeContentHandler.ExportHomeContent)
+	adminHome.Post("/import", homeContentHandler.ImportHomeContent)
+
 	// Category management routes (/admin/categories)
-	adminCategories := admin.Group("/categories")
+	adminCategories := admin.Group("/categories", can(models.PermissionProductsWrite))
 	adminCategories.Get("/", categoryHandler.GetCategories)
 	adminCategories.Post("/", categoryHandler.CreateCategory)
 	// Fix missing leading slashes on parameterized routes
 	adminCategories.Post("/:id/subcategories", categoryHandler.AddSubcategory)
-	adminCategories.Patch("/:id", categoryHandler.UpdateCategoryName)
-	adminCategories.Patch("/:categoryId/subcategories/:subId", categoryHandler.UpdateSubcategoryName)
+	adminCategories.Patch("/:id", categoryHandler.UpdateCategory)
+	adminCategories.Patch("/:categoryId/subcategories/:subId", categoryHandler.UpdateSubcategory)
 	adminCategories.Delete("/:id", categoryHandler.DeleteCategory)
 	adminCategories.Delete("/:categoryId/subcategories/:subId", categoryHandler.DeleteSubcategory)
 	// Discount routes for categories
 	adminCategories.Put("/:id/discount", categoryHandler.UpdateCategoryDiscount)
 	adminCategories.Put("/:id/subcategories/:subId/discount", categoryHandler.UpdateSubcategoryDiscount)
-	adminOrders := orders.Group("/", middleware.Role("admin"))
-	adminOrders.Patch("/:orderID/status", orderHandler.UpdateOrderStatus)
 
 	// Checkout route
 	api.Post("/checkout", orderHandler.Checkout)
+	api.Post("/checkout/buy-now", orderHandler.BuyNow)
+	api.Get("/checkout/gift-options", giftCardHandler.GetGiftOptions)
+	api.Get("/gift-cards/:code", giftCardHandler.CheckGiftCard)
 
 	// Recommendation routes
 	recommendations := api.Group("/recommendations")
@@ -185,6 +529,7 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 
 	// User preferences routes
 	preferences := api.Group("/preferences")
+	preferences.Get("/", userProfileHandler.GetPreferences)
 	preferences.Put("/", userProfileHandler.UpdatePreferences)
 
 	// Wishlist routes
@@ -196,8 +541,10 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 
 	// Account routes (consolidated user account functionality)
 	accountHandler := NewAccountHandler(db, cfg)
+	accountHandler.Storage = store
 	account := api.Group("/account")
 	account.Get("/overview", accountHandler.GetAccountOverview)
+	account.Get("/activity", accountHandler.GetAccountActivity)
 	account.Get("/reviews", accountHandler.GetAccountReviews)
 	account.Delete("/reviews/:id", accountHandler.DeleteAccountReview)
 	// Create a review under account scope as well
@@ -206,10 +553,17 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 	account.Delete("/wishlist/:id", accountHandler.RemoveAccountWishlistItem)
 	account.Get("/orders", accountHandler.GetAccountOrders)
 	account.Get("/orders/:orderID", accountHandler.GetAccountOrder)
+	account.Get("/warranties", warrantyHandler.GetAccountWarranties)
+	account.Put("/warranties/:id/serial", warrantyHandler.RegisterWarrantySerial)
+	account.Get("/export", accountHandler.ExportAccountData)
+	account.Post("/phone", authHandler.LinkPhone)
+	account.Post("/verify-email", authHandler.ResendVerificationEmail)
+	account.Delete("/", accountHandler.DeleteAccount)
 
 	// Address book routes
 	addresses := api.Group("/addresses")
 	addresses.Get("/", addressBookHandler.GetAddresses)
+	addresses.Get("/pincode/:code", addressBookHandler.LookupPincode)
 	addresses.Get("/:id", addressBookHandler.GetAddress)
 	addresses.Post("/", addressBookHandler.CreateAddress)
 	addresses.Put("/:id", addressBookHandler.UpdateAddress)
@@ -217,14 +571,6 @@ func SetupRoutes(app *fiber.App, db *database.DBClient, cfg *config.Config) {
 	addresses.Put("/:id/default", addressBookHandler.SetDefaultAddress)
 }
 
-// HealthHandler handles the health check endpoint
-func HealthHandler(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Server is healthy! Welcome to Makwatches API",
-	})
-}
-
 // WelcomeHandler handles the welcome endpoint
 func WelcomeHandler(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
@@ -232,3 +578,11 @@ func WelcomeHandler(c *fiber.Ctx) error {
 		"message": "Welcome to Makwatches API",
 	})
 }
+
+// JWKSHandler publishes the public keys that verify the API's tokens
+func JWKSHandler(keys *jwtkeys.Set) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Cache-Control", "public, max-age=300")
+		return c.JSON(keys.JWKS())
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// healthProbeTimeout bounds each dependency probe
+const healthProbeTimeout = 2 * time.Second
+
+// HealthHandler reports whether the API and its dependencies are up
+type HealthHandler struct {
+	DB      *database.DBClient
+	Storage storage.Storage
+}
+
+// NewHealthHandler creates a new instance of HealthHandler
+func NewHealthHandler(db *database.DBClient, store storage.Storage) *HealthHandler {
+	return &HealthHandler{DB: db, Storage: store}
+}
+
+// dependencyCheck is the result of probing one dependency
+type dependencyCheck struct {
+	Status string `json:"status"` // "up" or "down"
+	// Required dependencies make the API not ready when down; the API keeps
+	// serving without the others, with reduced features
+	Required  bool   `json:"required"`
+	Backend   string `json:"backend,omitempty"`
+	LatencyMS int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Live reports that the process is up and serving requests. It checks no
+// dependencies, so a database outage never gets the container restarted.
+// GET /health/live
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Server is live",
+	})
+}
+
+// Ready probes MongoDB, the cache and file storage in parallel and reports
+// each one's status and latency. It answers 503 while MongoDB, or Redis when
+// it backs the cache, is down; storage only affects uploads, so its outage
+// marks the API degraded but still ready.
+// GET /health/ready, GET /health
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	probes := map[string]struct {
+		required bool
+		backend  string
+		ping     func(context.Context) error
+	}{
+		"mongodb": {true, "mongodb", func(ctx context.Context) error {
+			return h.DB.MongoDB.Client().Ping(ctx, readpref.Primary())
+		}},
+		// The in-process fallback cache can't fail, so only Redis is required
+		"cache":   {h.DB.Cache.Name() == "redis", h.DB.Cache.Name(), h.DB.Cache.Ping},
+		"storage": {false, "", h.Storage.Ping},
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		checks = make(map[string]dependencyCheck, len(probes))
+	)
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, required bool, backend string, ping func(context.Context) error) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), healthProbeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := ping(ctx)
+			check := dependencyCheck{Status: "up", Required: required, Backend: backend, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				check.Status, check.Error = "down", err.Error()
+			}
+			mu.Lock()
+			checks[name] = check
+			mu.Unlock()
+		}(name, probe.required, probe.backend, probe.ping)
+	}
+	wg.Wait()
+
+	status, message, code := "ok", "Server is healthy! Welcome to Makwatches API", fiber.StatusOK
+	for _, check := range checks {
+		if check.Status == "up" {
+			continue
+		}
+		if check.Required {
+			status, message, code = "unavailable", "A required dependency is down", fiber.StatusServiceUnavailable
+			break
+		}
+		status, message = "degraded", "Server is up with reduced features"
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(code).JSON(fiber.Map{
+		"success": code == fiber.StatusOK,
+		"message": message,
+		"data": fiber.Map{
+			"status": status,
+			"checks": checks,
+		},
+	})
+}
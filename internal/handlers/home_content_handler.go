@@ -12,6 +12,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
@@ -23,93 +25,144 @@ const (
 	techCardsCollectionName          = "home_tech_cards"
 	techHighlightCollectionName      = "home_tech_highlights"
 	galleryCollectionName            = "home_gallery_images"
-	homeContentCacheKey              = "home_content"
+	testimonialsCollectionName       = "home_testimonials"
+	homeContentCacheKey              = "home_content_snapshot"
 )
 
 // HomeContentHandler manages curated landing page data.
 type HomeContentHandler struct {
-	DB *database.DBClient
+	DB     *database.DBClient
+	Config *config.Config
 }
 
 // NewHomeContentHandler wires a handler with the provided DB client.
-func NewHomeContentHandler(db *database.DBClient) *HomeContentHandler {
-	return &HomeContentHandler{DB: db}
+func NewHomeContentHandler(db *database.DBClient, cfg *config.Config) *HomeContentHandler {
+	return &HomeContentHandler{DB: db, Config: cfg}
 }
 
-// GetHomeContent returns aggregated landing page content for the storefront.
+// homeContentSnapshot is the cached home content and when it was built. The
+// build time is its Last-Modified: deleting an item changes the content
+// without leaving a newer updatedAt behind.
+type homeContentSnapshot struct {
+	Content models.HomeContent `json:"content"`
+	BuiltAt time.Time          `json:"builtAt"`
+	TTL     time.Duration      `json:"-"` // How long it was cached for
+}
+
+// GetHomeContent returns aggregated landing page content for the storefront,
+// translated into the locale asked for by ?locale= or Accept-Language.
 func (h *HomeContentHandler) GetHomeContent(c *fiber.Ctx) error {
 	ctx := c.Context()
+	locale := requestLocale(c, h.Config)
+	cacheKey := homeContentCacheKey + ":" + locale
 
-	var cached models.HomeContentWithGallery
-	if err := h.DB.CacheGet(ctx, homeContentCacheKey, &cached); err == nil {
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	var cached homeContentSnapshot
+	if err := h.DB.CacheGet(ctx, cacheKey, &cached); err == nil {
+		return sendConditionalJSON(c, fiber.Map{
 			"success": true,
 			"message": "Home content retrieved from cache",
-			"data":    cached,
-		})
+			"data":    cached.Content,
+		}, cached.BuiltAt)
+	}
+
+	snapshot, err := h.buildHomeContent(ctx, locale)
+	if err != nil {
+		return err
 	}
 
+	return sendConditionalJSON(c, fiber.Map{
+		"success": true,
+		"message": "Home content retrieved successfully",
+		"data":    snapshot.Content,
+	}, snapshot.BuiltAt)
+}
+
+// buildHomeContent reads the home content translated into locale and caches
+// it. Requests that miss the cache and the cache warmer both build through it.
+func (h *HomeContentHandler) buildHomeContent(ctx context.Context, locale string) (homeContentSnapshot, error) {
 	heroSlides, err := h.fetchHeroSlides(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch hero slides")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch hero slides", err)
 	}
 
 	categories, err := h.fetchCategoryCards(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch category cards")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch category cards", err)
 	}
 
 	collections, err := h.fetchCollectionFeatures(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch collection features")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch collection features", err)
 	}
 
 	techCards, err := h.fetchTechCards(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch tech showcase cards")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch tech showcase cards", err)
 	}
 
 	highlight, err := h.fetchTechHighlight(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch tech highlight")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch tech highlight", err)
 	}
 
 	gallery, err := h.fetchGalleryImages(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch gallery images")
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch gallery images", err)
+	}
+
+	testimonials, err := h.fetchTestimonials(ctx, bson.M{"visible": true})
+	if err != nil {
+		return homeContentSnapshot{}, apperrors.Internal("Failed to fetch testimonials", err)
 	}
 
-	base := models.HomeContent{
-		HeroSlides:  heroSlides,
-		Categories:  categories,
-		Collections: collections,
-		TechCards:   techCards,
-		Highlight:   highlight,
+	for i := range heroSlides {
+		heroSlides[i].Localize(locale)
+	}
+	for i := range categories {
+		categories[i].Localize(locale)
+	}
+	for i := range collections {
+		collections[i].Localize(locale)
 	}
 
-	payload := models.HomeContentWithGallery{
-		HomeContent: base,
-		Gallery:     gallery,
+	now := time.Now()
+	liveSlides, nextSlideChange := scheduledHeroSlides(heroSlides, now)
+	visible, nextChange := scheduledGallery(gallery, now)
+	if !nextSlideChange.IsZero() && (nextChange.IsZero() || nextSlideChange.Before(nextChange)) {
+		nextChange = nextSlideChange
 	}
 
-	// Cache for five minutes to avoid excessive DB hits while remaining responsive to updates.
-	_ = h.DB.CacheSet(ctx, homeContentCacheKey, payload, 5*time.Minute)
+	payload := models.HomeContent{
+		HeroSlides:   liveSlides,
+		Categories:   categories,
+		Collections:  collections,
+		TechCards:    techCards,
+		Highlight:    highlight,
+		Gallery:      visible,
+		Testimonials: testimonials,
+	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Home content retrieved successfully",
-		"data":    payload,
-	})
+	// Cache for five minutes to avoid excessive DB hits while remaining responsive to updates,
+	// but no longer than until a scheduled hero slide or gallery image appears or expires.
+	ttl := 5 * time.Minute
+	if !nextChange.IsZero() && nextChange.Sub(now) < ttl {
+		ttl = nextChange.Sub(now)
+	}
+	snapshot := homeContentSnapshot{Content: payload, BuiltAt: now, TTL: ttl}
+	_ = h.DB.CacheSet(ctx, homeContentCacheKey+":"+locale, snapshot, ttl)
+
+	return snapshot, nil
 }
 
 // ============ Hero Slides CRUD ============
 
-// ListHeroSlides returns all hero slides for admin management.
+// ListHeroSlides returns all hero slides for admin management, including
+// those scheduled for later or already expired.
 func (h *HomeContentHandler) ListHeroSlides(c *fiber.Ctx) error {
 	ctx := c.Context()
 	slides, err := h.fetchHeroSlides(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch hero slides")
+		return apperrors.Internal("Failed to fetch hero slides", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -123,10 +176,10 @@ func (h *HomeContentHandler) CreateHeroSlide(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.HeroSlide
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateHeroSlide(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(heroSlidesCollectionName)
@@ -145,7 +198,7 @@ func (h *HomeContentHandler) CreateHeroSlide(c *fiber.Ctx) error {
 
 	res, err := coll.InsertOne(ctx, payload)
 	if err != nil {
-		return fiberError(c, err, "Failed to create hero slide")
+		return apperrors.Internal("Failed to create hero slide", err)
 	}
 
 	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
@@ -161,20 +214,21 @@ func (h *HomeContentHandler) CreateHeroSlide(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateHeroSlide updates an existing hero slide document.
+// UpdateHeroSlide updates an existing hero slide document. Leaving out
+// startAt or endAt clears it.
 func (h *HomeContentHandler) UpdateHeroSlide(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid hero slide id", err)
+		return apperrors.BadRequest("Invalid hero slide id", err)
 	}
 
 	var payload models.HeroSlide
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateHeroSlide(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	update := bson.M{
@@ -186,6 +240,8 @@ func (h *HomeContentHandler) UpdateHeroSlide(c *fiber.Ctx) error {
 		"features":    payload.Features,
 		"gradient":    payload.Gradient,
 		"glowColor":   payload.GlowColor,
+		"startAt":     payload.StartAt,
+		"endAt":       payload.EndAt,
 		"updatedAt":   time.Now().UTC(),
 	}
 	if payload.Position > 0 {
@@ -195,15 +251,15 @@ func (h *HomeContentHandler) UpdateHeroSlide(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(heroSlidesCollectionName)
 	result, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
 	if err != nil {
-		return fiberError(c, err, "Failed to update hero slide")
+		return apperrors.Internal("Failed to update hero slide", err)
 	}
 	if result.MatchedCount == 0 {
-		return fiberNotFound(c, "Hero slide not found")
+		return apperrors.NotFound("Hero slide not found")
 	}
 
 	var updated models.HeroSlide
 	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
-		return fiberError(c, err, "Failed to load updated hero slide")
+		return apperrors.Internal("Failed to load updated hero slide", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -220,16 +276,16 @@ func (h *HomeContentHandler) DeleteHeroSlide(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid hero slide id", err)
+		return apperrors.BadRequest("Invalid hero slide id", err)
 	}
 
 	coll := h.DB.MongoDB.Collection(heroSlidesCollectionName)
 	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete hero slide")
+		return apperrors.Internal("Failed to delete hero slide", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "Hero slide not found")
+		return apperrors.NotFound("Hero slide not found")
 	}
 
 	h.clearHomeCache(ctx)
@@ -246,7 +302,7 @@ func (h *HomeContentHandler) ListCategoryCards(c *fiber.Ctx) error {
 	ctx := c.Context()
 	cards, err := h.fetchCategoryCards(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch category cards")
+		return apperrors.Internal("Failed to fetch category cards", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -259,10 +315,10 @@ func (h *HomeContentHandler) CreateCategoryCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.HomeCategoryCard
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateCategoryCard(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(categoryCardsCollectionName)
@@ -281,7 +337,7 @@ func (h *HomeContentHandler) CreateCategoryCard(c *fiber.Ctx) error {
 
 	res, err := coll.InsertOne(ctx, payload)
 	if err != nil {
-		return fiberError(c, err, "Failed to create category card")
+		return apperrors.Internal("Failed to create category card", err)
 	}
 	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
 		payload.ID = insertedID
@@ -300,15 +356,15 @@ func (h *HomeContentHandler) UpdateCategoryCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid category card id", err)
+		return apperrors.BadRequest("Invalid category card id", err)
 	}
 
 	var payload models.HomeCategoryCard
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateCategoryCard(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	update := bson.M{
@@ -326,15 +382,15 @@ func (h *HomeContentHandler) UpdateCategoryCard(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(categoryCardsCollectionName)
 	res, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
 	if err != nil {
-		return fiberError(c, err, "Failed to update category card")
+		return apperrors.Internal("Failed to update category card", err)
 	}
 	if res.MatchedCount == 0 {
-		return fiberNotFound(c, "Category card not found")
+		return apperrors.NotFound("Category card not found")
 	}
 
 	var updated models.HomeCategoryCard
 	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
-		return fiberError(c, err, "Failed to load updated category card")
+		return apperrors.Internal("Failed to load updated category card", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -350,16 +406,16 @@ func (h *HomeContentHandler) DeleteCategoryCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid category card id", err)
+		return apperrors.BadRequest("Invalid category card id", err)
 	}
 
 	coll := h.DB.MongoDB.Collection(categoryCardsCollectionName)
 	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete category card")
+		return apperrors.Internal("Failed to delete category card", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "Category card not found")
+		return apperrors.NotFound("Category card not found")
 	}
 
 	h.clearHomeCache(ctx)
@@ -376,7 +432,7 @@ func (h *HomeContentHandler) ListCollectionFeatures(c *fiber.Ctx) error {
 	ctx := c.Context()
 	cards, err := h.fetchCollectionFeatures(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch collection features")
+		return apperrors.Internal("Failed to fetch collection features", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -389,10 +445,10 @@ func (h *HomeContentHandler) CreateCollectionFeature(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.HomeCollectionFeature
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateCollectionFeature(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(collectionFeaturesCollectionName)
@@ -411,7 +467,7 @@ func (h *HomeContentHandler) CreateCollectionFeature(c *fiber.Ctx) error {
 
 	res, err := coll.InsertOne(ctx, payload)
 	if err != nil {
-		return fiberError(c, err, "Failed to create collection feature")
+		return apperrors.Internal("Failed to create collection feature", err)
 	}
 	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
 		payload.ID = insertedID
@@ -430,15 +486,15 @@ func (h *HomeContentHandler) UpdateCollectionFeature(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid collection feature id", err)
+		return apperrors.BadRequest("Invalid collection feature id", err)
 	}
 
 	var payload models.HomeCollectionFeature
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateCollectionFeature(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	update := bson.M{
@@ -460,15 +516,15 @@ func (h *HomeContentHandler) UpdateCollectionFeature(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(collectionFeaturesCollectionName)
 	res, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
 	if err != nil {
-		return fiberError(c, err, "Failed to update collection feature")
+		return apperrors.Internal("Failed to update collection feature", err)
 	}
 	if res.MatchedCount == 0 {
-		return fiberNotFound(c, "Collection feature not found")
+		return apperrors.NotFound("Collection feature not found")
 	}
 
 	var updated models.HomeCollectionFeature
 	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
-		return fiberError(c, err, "Failed to load updated collection feature")
+		return apperrors.Internal("Failed to load updated collection feature", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -484,16 +540,16 @@ func (h *HomeContentHandler) DeleteCollectionFeature(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid collection feature id", err)
+		return apperrors.BadRequest("Invalid collection feature id", err)
 	}
 
 	coll := h.DB.MongoDB.Collection(collectionFeaturesCollectionName)
 	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete collection feature")
+		return apperrors.Internal("Failed to delete collection feature", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "Collection feature not found")
+		return apperrors.NotFound("Collection feature not found")
 	}
 
 	h.clearHomeCache(ctx)
@@ -510,7 +566,7 @@ func (h *HomeContentHandler) ListTechCards(c *fiber.Ctx) error {
 	ctx := c.Context()
 	cards, err := h.fetchTechCards(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch tech cards")
+		return apperrors.Internal("Failed to fetch tech cards", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -523,10 +579,10 @@ func (h *HomeContentHandler) CreateTechCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.TechShowcaseCard
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateTechCard(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(techCardsCollectionName)
@@ -545,7 +601,7 @@ func (h *HomeContentHandler) CreateTechCard(c *fiber.Ctx) error {
 
 	res, err := coll.InsertOne(ctx, payload)
 	if err != nil {
-		return fiberError(c, err, "Failed to create tech card")
+		return apperrors.Internal("Failed to create tech card", err)
 	}
 	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
 		payload.ID = insertedID
@@ -564,15 +620,15 @@ func (h *HomeContentHandler) UpdateTechCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid tech card id", err)
+		return apperrors.BadRequest("Invalid tech card id", err)
 	}
 
 	var payload models.TechShowcaseCard
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateTechCard(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	update := bson.M{
@@ -593,15 +649,15 @@ func (h *HomeContentHandler) UpdateTechCard(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(techCardsCollectionName)
 	res, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
 	if err != nil {
-		return fiberError(c, err, "Failed to update tech card")
+		return apperrors.Internal("Failed to update tech card", err)
 	}
 	if res.MatchedCount == 0 {
-		return fiberNotFound(c, "Tech card not found")
+		return apperrors.NotFound("Tech card not found")
 	}
 
 	var updated models.TechShowcaseCard
 	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
-		return fiberError(c, err, "Failed to load updated tech card")
+		return apperrors.Internal("Failed to load updated tech card", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -617,16 +673,16 @@ func (h *HomeContentHandler) DeleteTechCard(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid tech card id", err)
+		return apperrors.BadRequest("Invalid tech card id", err)
 	}
 
 	coll := h.DB.MongoDB.Collection(techCardsCollectionName)
 	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete tech card")
+		return apperrors.Internal("Failed to delete tech card", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "Tech card not found")
+		return apperrors.NotFound("Tech card not found")
 	}
 
 	h.clearHomeCache(ctx)
@@ -639,12 +695,13 @@ func (h *HomeContentHandler) DeleteTechCard(c *fiber.Ctx) error {
 
 // ============ Gallery Images CRUD ============
 
-// ListGalleryImages returns all gallery images for admin management.
+// ListGalleryImages returns all gallery images for admin management,
+// including those scheduled for later or already expired.
 func (h *HomeContentHandler) ListGalleryImages(c *fiber.Ctx) error {
 	ctx := c.Context()
 	images, err := h.fetchGalleryImages(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch gallery images")
+		return apperrors.Internal("Failed to fetch gallery images", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -658,10 +715,10 @@ func (h *HomeContentHandler) CreateGalleryImage(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.GalleryImage
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateGalleryImage(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(galleryCollectionName)
@@ -680,7 +737,7 @@ func (h *HomeContentHandler) CreateGalleryImage(c *fiber.Ctx) error {
 
 	res, err := coll.InsertOne(ctx, payload)
 	if err != nil {
-		return fiberError(c, err, "Failed to create gallery image")
+		return apperrors.Internal("Failed to create gallery image", err)
 	}
 	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
 		payload.ID = insertedID
@@ -695,25 +752,30 @@ func (h *HomeContentHandler) CreateGalleryImage(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateGalleryImage updates an existing gallery image (alt text/position)
+// UpdateGalleryImage updates an existing gallery image. The url and position
+// are kept when left out; the other fields are replaced, so leaving out a
+// date clears it.
 func (h *HomeContentHandler) UpdateGalleryImage(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid gallery image id", err)
+		return apperrors.BadRequest("Invalid gallery image id", err)
 	}
 
 	var payload models.GalleryImage
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
-	// url optional on update; validate basic fields if provided
-	if strings.TrimSpace(payload.Url) == "" {
-		// allow keeping existing URL; build update without url unless provided
+	if err := validateGallerySchedule(&payload); err != nil {
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	update := bson.M{
 		"alt":       payload.Alt,
+		"caption":   payload.Caption,
+		"href":      payload.Href,
+		"startDate": payload.StartDate,
+		"endDate":   payload.EndDate,
 		"updatedAt": time.Now().UTC(),
 	}
 	if payload.Position > 0 {
@@ -726,15 +788,15 @@ func (h *HomeContentHandler) UpdateGalleryImage(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(galleryCollectionName)
 	res, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
 	if err != nil {
-		return fiberError(c, err, "Failed to update gallery image")
+		return apperrors.Internal("Failed to update gallery image", err)
 	}
 	if res.MatchedCount == 0 {
-		return fiberNotFound(c, "Gallery image not found")
+		return apperrors.NotFound("Gallery image not found")
 	}
 
 	var updated models.GalleryImage
 	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
-		return fiberError(c, err, "Failed to load updated gallery image")
+		return apperrors.Internal("Failed to load updated gallery image", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -751,16 +813,16 @@ func (h *HomeContentHandler) DeleteGalleryImage(c *fiber.Ctx) error {
 	ctx := c.Context()
 	objectID, err := parseObjectID(c.Params("id"))
 	if err != nil {
-		return fiberBadRequest(c, "Invalid gallery image id", err)
+		return apperrors.BadRequest("Invalid gallery image id", err)
 	}
 
 	coll := h.DB.MongoDB.Collection(galleryCollectionName)
 	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete gallery image")
+		return apperrors.Internal("Failed to delete gallery image", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "Gallery image not found")
+		return apperrors.NotFound("Gallery image not found")
 	}
 
 	h.clearHomeCache(ctx)
@@ -771,12 +833,148 @@ func (h *HomeContentHandler) DeleteGalleryImage(c *fiber.Ctx) error {
 	})
 }
 
+// ============ Testimonials CRUD ============
+
+// ListTestimonials returns all testimonials for admin management, hidden
+// ones included.
+func (h *HomeContentHandler) ListTestimonials(c *fiber.Ctx) error {
+	ctx := c.Context()
+	testimonials, err := h.fetchTestimonials(ctx, bson.M{})
+	if err != nil {
+		return apperrors.Internal("Failed to fetch testimonials", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Testimonials retrieved successfully",
+		"data":    testimonials,
+	})
+}
+
+// CreateTestimonial inserts a new testimonial document.
+func (h *HomeContentHandler) CreateTestimonial(c *fiber.Ctx) error {
+	ctx := c.Context()
+	var payload models.Testimonial
+	if err := c.BodyParser(&payload); err != nil {
+		return apperrors.BadRequest("Invalid payload", err)
+	}
+	if err := validateTestimonial(&payload); err != nil {
+		return apperrors.BadRequest(err.Error(), err)
+	}
+
+	coll := h.DB.MongoDB.Collection(testimonialsCollectionName)
+	now := time.Now().UTC()
+	payload.ID = primitive.NilObjectID
+	payload.CreatedAt = now
+	payload.UpdatedAt = now
+	if payload.Position <= 0 {
+		count, err := coll.CountDocuments(ctx, bson.M{})
+		if err == nil {
+			payload.Position = int(count) + 1
+		} else {
+			payload.Position = 1
+		}
+	}
+
+	res, err := coll.InsertOne(ctx, payload)
+	if err != nil {
+		return apperrors.Internal("Failed to create testimonial", err)
+	}
+	if insertedID, ok := res.InsertedID.(primitive.ObjectID); ok {
+		payload.ID = insertedID
+	}
+
+	h.clearHomeCache(ctx)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Testimonial created",
+		"data":    payload,
+	})
+}
+
+// UpdateTestimonial updates an existing testimonial. The position is kept
+// when left out; the other fields are replaced.
+func (h *HomeContentHandler) UpdateTestimonial(c *fiber.Ctx) error {
+	ctx := c.Context()
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid testimonial id", err)
+	}
+
+	var payload models.Testimonial
+	if err := c.BodyParser(&payload); err != nil {
+		return apperrors.BadRequest("Invalid payload", err)
+	}
+	if err := validateTestimonial(&payload); err != nil {
+		return apperrors.BadRequest(err.Error(), err)
+	}
+
+	update := bson.M{
+		"author":    payload.Author,
+		"avatar":    payload.Avatar,
+		"quote":     payload.Quote,
+		"rating":    payload.Rating,
+		"visible":   payload.Visible,
+		"updatedAt": time.Now().UTC(),
+	}
+	if payload.Position > 0 {
+		update["position"] = payload.Position
+	}
+
+	coll := h.DB.MongoDB.Collection(testimonialsCollectionName)
+	res, err := coll.UpdateByID(ctx, objectID, bson.M{"$set": update})
+	if err != nil {
+		return apperrors.Internal("Failed to update testimonial", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound("Testimonial not found")
+	}
+
+	var updated models.Testimonial
+	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&updated); err != nil {
+		return apperrors.Internal("Failed to load updated testimonial", err)
+	}
+
+	h.clearHomeCache(ctx)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Testimonial updated",
+		"data":    updated,
+	})
+}
+
+// DeleteTestimonial removes a testimonial by id.
+func (h *HomeContentHandler) DeleteTestimonial(c *fiber.Ctx) error {
+	ctx := c.Context()
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid testimonial id", err)
+	}
+
+	coll := h.DB.MongoDB.Collection(testimonialsCollectionName)
+	res, err := coll.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return apperrors.Internal("Failed to delete testimonial", err)
+	}
+	if res.DeletedCount == 0 {
+		return apperrors.NotFound("Testimonial not found")
+	}
+
+	h.clearHomeCache(ctx)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Testimonial deleted",
+	})
+}
+
 // GetTechHighlight returns the current showcase highlight for admin editing.
 func (h *HomeContentHandler) GetTechHighlight(c *fiber.Ctx) error {
 	ctx := c.Context()
 	highlight, err := h.fetchTechHighlight(ctx)
 	if err != nil {
-		return fiberError(c, err, "Failed to fetch tech highlight")
+		return apperrors.Internal("Failed to fetch tech highlight", err)
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -790,10 +988,10 @@ func (h *HomeContentHandler) UpsertTechHighlight(c *fiber.Ctx) error {
 	ctx := c.Context()
 	var payload models.TechShowcaseHighlight
 	if err := c.BodyParser(&payload); err != nil {
-		return fiberBadRequest(c, "Invalid payload", err)
+		return apperrors.BadRequest("Invalid payload", err)
 	}
 	if err := validateHighlight(&payload); err != nil {
-		return fiberBadRequest(c, err.Error(), err)
+		return apperrors.BadRequest(err.Error(), err)
 	}
 
 	coll := h.DB.MongoDB.Collection(techHighlightCollectionName)
@@ -811,12 +1009,12 @@ func (h *HomeContentHandler) UpsertTechHighlight(c *fiber.Ctx) error {
 	// Upsert to ensure a single document exists.
 	opts := options.Update().SetUpsert(true)
 	if _, err := coll.UpdateOne(ctx, bson.M{}, bson.M{"$set": update, "$setOnInsert": bson.M{"createdAt": now}}, opts); err != nil {
-		return fiberError(c, err, "Failed to upsert tech highlight")
+		return apperrors.Internal("Failed to upsert tech highlight", err)
 	}
 
 	var highlight models.TechShowcaseHighlight
 	if err := coll.FindOne(ctx, bson.M{}).Decode(&highlight); err != nil {
-		return fiberError(c, err, "Failed to load tech highlight")
+		return apperrors.Internal("Failed to load tech highlight", err)
 	}
 
 	h.clearHomeCache(ctx)
@@ -834,10 +1032,10 @@ func (h *HomeContentHandler) DeleteTechHighlight(c *fiber.Ctx) error {
 	coll := h.DB.MongoDB.Collection(techHighlightCollectionName)
 	res, err := coll.DeleteMany(ctx, bson.M{})
 	if err != nil {
-		return fiberError(c, err, "Failed to delete tech highlight")
+		return apperrors.Internal("Failed to delete tech highlight", err)
 	}
 	if res.DeletedCount == 0 {
-		return fiberNotFound(c, "No tech highlight to delete")
+		return apperrors.NotFound("No tech highlight to delete")
 	}
 
 	h.clearHomeCache(ctx)
@@ -943,8 +1141,70 @@ func (h *HomeContentHandler) fetchGalleryImages(ctx context.Context) ([]models.G
 	return images, nil
 }
 
+func (h *HomeContentHandler) fetchTestimonials(ctx context.Context, filter bson.M) ([]models.Testimonial, error) {
+	coll := h.DB.MongoDB.Collection(testimonialsCollectionName)
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "createdAt", Value: 1}})
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	testimonials := []models.Testimonial{}
+	if err := cursor.All(ctx, &testimonials); err != nil {
+		return nil, err
+	}
+	return testimonials, nil
+}
+
+// scheduledHeroSlides returns the slides visible at now and the next time
+// one is scheduled to go live or expire, zero when none is
+func scheduledHeroSlides(slides []models.HeroSlide, now time.Time) ([]models.HeroSlide, time.Time) {
+	visible := []models.HeroSlide{}
+	var next time.Time
+	for _, slide := range slides {
+		if slide.VisibleAt(now) {
+			visible = append(visible, slide)
+		}
+		for _, t := range []*time.Time{slide.StartAt, slide.EndAt} {
+			if t != nil && t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = *t
+			}
+		}
+	}
+	return visible, next
+}
+
+// scheduledGallery returns the images visible at now and the next time one
+// is scheduled to appear or expire, zero when none is
+func scheduledGallery(images []models.GalleryImage, now time.Time) ([]models.GalleryImage, time.Time) {
+	visible := []models.GalleryImage{}
+	var next time.Time
+	for _, img := range images {
+		if img.VisibleAt(now) {
+			visible = append(visible, img)
+		}
+		for _, t := range []*time.Time{img.StartDate, img.EndDate} {
+			if t != nil && t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = *t
+			}
+		}
+	}
+	return visible, next
+}
+
 func (h *HomeContentHandler) clearHomeCache(ctx context.Context) {
-	_ = h.DB.CacheDel(ctx, homeContentCacheKey)
+	clearHomeContentCache(ctx, h.DB, h.Config)
+}
+
+// clearHomeContentCache drops the cached home content of every locale
+func clearHomeContentCache(ctx context.Context, db *database.DBClient, cfg *config.Config) {
+	keys := make([]string, len(cfg.Locales))
+	for i, locale := range cfg.Locales {
+		keys[i] = homeContentCacheKey + ":" + locale
+	}
+	_ = db.CacheDel(ctx, keys...)
+	db.NotifyInvalidated(database.HomeContentCacheNamespace)
 }
 
 func validateHeroSlide(slide *models.HeroSlide) error {
@@ -963,6 +1223,9 @@ func validateHeroSlide(slide *models.HeroSlide) error {
 	if slide.Features == nil {
 		slide.Features = []string{}
 	}
+	if slide.StartAt != nil && slide.EndAt != nil && !slide.EndAt.After(*slide.StartAt) {
+		return errors.New("endAt must be after startAt")
+	}
 	return nil
 }
 
@@ -1057,35 +1320,37 @@ func validateGalleryImage(img *models.GalleryImage) error {
 	if strings.TrimSpace(img.Alt) == "" {
 		img.Alt = "Gallery image"
 	}
-	return nil
+	return validateGallerySchedule(img)
 }
 
-func parseObjectID(id string) (primitive.ObjectID, error) {
-	return primitive.ObjectIDFromHex(id)
-}
-
-func fiberError(c *fiber.Ctx, err error, message string) error {
-	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		"success": false,
-		"message": message,
-		"error":   err.Error(),
-	})
+func validateGallerySchedule(img *models.GalleryImage) error {
+	img.Caption = strings.TrimSpace(img.Caption)
+	img.Href = strings.TrimSpace(img.Href)
+	if img.StartDate != nil && img.EndDate != nil && !img.EndDate.After(*img.StartDate) {
+		return errors.New("endDate must be after startDate")
+	}
+	return nil
 }
 
-func fiberBadRequest(c *fiber.Ctx, message string, err error) error {
-	payload := fiber.Map{
-		"success": false,
-		"message": message,
+func validateTestimonial(t *models.Testimonial) error {
+	t.Author = strings.TrimSpace(t.Author)
+	t.Avatar = strings.TrimSpace(t.Avatar)
+	t.Quote = strings.TrimSpace(t.Quote)
+	if t.Author == "" {
+		return errors.New("author is required")
 	}
-	if err != nil {
-		payload["error"] = err.Error()
+	if t.Quote == "" {
+		return errors.New("quote is required")
+	}
+	if t.Rating < 0 || t.Rating > 5 {
+		return errors.New("rating must be between 1 and 5, or 0 for none")
+	}
+	if t.Position < 0 {
+		t.Position = 0
 	}
-	return c.Status(fiber.StatusBadRequest).JSON(payload)
+	return nil
 }
 
-func fiberNotFound(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"success": false,
-		"message": message,
-	})
+func parseObjectID(id string) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(id)
 }
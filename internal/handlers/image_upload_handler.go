@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/imaging"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+const (
+	// maxImageFiles is how many images one request may carry
+	maxImageFiles = 10
+	// maxImageSize is the largest accepted source image in bytes
+	maxImageSize = 5 * 1024 * 1024
+)
+
+// ImageRendition holds the URLs of one size of an uploaded image
+type ImageRendition struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+	WebP   string `json:"webp"`
+}
+
+// UploadedImage is the result for one source file
+type UploadedImage struct {
+	Filename   string                    `json:"filename"`
+	Renditions map[string]ImageRendition `json:"renditions"`
+}
+
+// ImageUploadHandler resizes uploaded images and stores every rendition
+type ImageUploadHandler struct {
+	DB      *database.DBClient
+	Storage storage.Storage
+}
+
+// NewImageUploadHandler creates a new instance of ImageUploadHandler
+func NewImageUploadHandler(db *database.DBClient, store storage.Storage) *ImageUploadHandler {
+	return &ImageUploadHandler{DB: db, Storage: store}
+}
+
+// UploadImages validates each file in the "images" field, generates
+// thumbnail, medium and large renditions plus WebP copies and uploads them.
+// All files are validated and processed before anything is stored, so a bad
+// file fails the request without leaving partial uploads behind. Each image
+// is added to the media library, its largest rendition as the one to use.
+// POST /upload/images
+func (h *ImageUploadHandler) UploadImages(c *fiber.Ctx) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return apperrors.BadRequest("Invalid multipart form", err)
+	}
+	files := form.File["images"]
+	if len(files) == 0 {
+		return apperrors.BadRequest("No images provided", nil)
+	}
+	if len(files) > maxImageFiles {
+		return apperrors.BadRequest(fmt.Sprintf("At most %d images can be uploaded at once", maxImageFiles), nil)
+	}
+
+	type processed struct {
+		name     string
+		variants []imaging.Variant
+	}
+	batch := make([]processed, 0, len(files))
+	for _, f := range files {
+		if f.Size > maxImageSize {
+			return fileTooLarge(f.Filename)
+		}
+		file, err := f.Open()
+		if err != nil {
+			return apperrors.Internal("Failed to open file", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+		file.Close()
+		if err != nil {
+			return apperrors.Internal("Failed to read file", err)
+		}
+		if len(data) > maxImageSize {
+			return fileTooLarge(f.Filename)
+		}
+
+		variants, err := imaging.Process(data, imaging.DefaultRenditions)
+		if err != nil {
+			if errors.Is(err, imaging.ErrUnsupportedType) {
+				return apperrors.BadRequest(f.Filename+" is not a supported image. Only JPEG, PNG, GIF or WEBP allowed", nil)
+			}
+			if errors.Is(err, imaging.ErrTooManyPixels) {
+				return apperrors.BadRequest(fmt.Sprintf("%s is too large; images may have at most %d megapixels", f.Filename, imaging.MaxPixels/1_000_000), nil)
+			}
+			return apperrors.BadRequest(f.Filename+" could not be processed as an image", err)
+		}
+		batch = append(batch, processed{name: f.Filename, variants: variants})
+	}
+
+	ctx := context.Background()
+	results := make([]UploadedImage, 0, len(batch))
+	assets := make([]models.MediaAsset, 0, len(batch))
+	stored := []string{}
+	for _, p := range batch {
+		// All renditions of one source share a base name so they are easy to find together
+		base := storage.NewKey("images", p.name)
+		base = strings.TrimSuffix(base, path.Ext(base))
+		result := UploadedImage{Filename: p.name, Renditions: make(map[string]ImageRendition)}
+		asset := models.MediaAsset{Filename: p.name, URLs: []string{}}
+		for _, v := range p.variants {
+			key := fmt.Sprintf("%s-%s%s", base, v.Rendition, v.Extension())
+			url, err := h.Storage.Upload(ctx, key, bytes.NewReader(v.Data), v.ContentType)
+			if err != nil {
+				log.Printf("[UPLOAD] Failed to store %s rendition of %s: %v", v.Rendition, p.name, err)
+				storage.DeleteURLs(ctx, h.Storage, stored)
+				return apperrors.Internal("Failed to store image", err)
+			}
+			stored = append(stored, url)
+			asset.URLs = append(asset.URLs, url)
+			asset.Size += int64(len(v.Data))
+			// Renditions come smallest first, so the last one not in WebP is the largest
+			if v.Format != "webp" {
+				asset.URL, asset.ContentType, asset.Width, asset.Height = url, v.ContentType, v.Width, v.Height
+			}
+			r := result.Renditions[v.Rendition]
+			r.Width, r.Height = v.Width, v.Height
+			if v.Format == "webp" {
+				r.WebP = url
+			} else {
+				r.URL = url
+			}
+			result.Renditions[v.Rendition] = r
+		}
+		results = append(results, result)
+		assets = append(assets, asset)
+	}
+	recordMedia(c, h.DB, assets)
+
+	log.Printf("[UPLOAD] Stored %d images with %d renditions each", len(results), len(imaging.DefaultRenditions)*2)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Upload successful",
+		"data":    fiber.Map{"images": results},
+	})
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// InventoryHandler serves the admin inventory dashboard
+type InventoryHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewInventoryHandler creates a new instance of InventoryHandler
+func NewInventoryHandler(db *database.DBClient, cfg *config.Config) *InventoryHandler {
+	return &InventoryHandler{DB: db, Config: cfg}
+}
+
+// InventoryItem is one product row on the inventory dashboard
+type InventoryItem struct {
+	ProductID         primitive.ObjectID `json:"productId"`
+	Name              string             `json:"name"`
+	ImageURL          string             `json:"imageUrl,omitempty"`
+	Category          string             `json:"category"`
+	Stock             int                `json:"stock"`      // Sellable units
+	Reserved          int                `json:"reserved"`   // Units in orders not yet shipped
+	OnHand            int                `json:"onHand"`     // Stock plus reserved
+	Preordered        int                `json:"preordered"` // Pre-ordered units awaiting stock
+	ReorderThreshold  int                `json:"reorderThreshold"`
+	ThresholdOverride bool               `json:"thresholdOverride"` // False when the default threshold applies
+	LowStock          bool               `json:"lowStock"`
+	LowStockAlertedAt *time.Time         `json:"lowStockAlertedAt,omitempty"`
+}
+
+// GetInventory lists per-product stock levels, lowest stock first
+// GET /admin/inventory?lowStock=true&category=&page=1&limit=50
+func (h *InventoryHandler) GetInventory(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	defaultThreshold := h.Config.LowStockThreshold
+	lowStockExpr := models.LowStockExpr(defaultThreshold)
+
+	filter := bson.M{}
+	if c.QueryBool("lowStock") {
+		filter["$expr"] = lowStockExpr
+	}
+	if category := c.Query("category"); category != "" {
+		filter["category"] = category
+	}
+
+	products := h.DB.Collections().Products
+	total, err := products.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count products", err)
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "image_url": 1, "category": 1, "stock": 1, "reorder_threshold": 1, "low_stock_alerted_at": 1, "preorder_count": 1}).
+		SetSort(bson.D{{Key: "stock", Value: 1}, {Key: "name", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := products.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch inventory", err)
+	}
+	var list []models.Product
+	if err := cursor.All(ctx, &list); err != nil {
+		return apperrors.Internal("Failed to decode inventory", err)
+	}
+
+	ids := make([]primitive.ObjectID, len(list))
+	for i, p := range list {
+		ids[i] = p.ID
+	}
+	reserved, err := reservedQuantities(ctx, h.DB, ids)
+	if err != nil {
+		return apperrors.Internal("Failed to calculate reserved stock", err)
+	}
+
+	items := make([]InventoryItem, 0, len(list))
+	for _, p := range list {
+		threshold := p.EffectiveReorderThreshold(defaultThreshold)
+		items = append(items, InventoryItem{
+			ProductID:         p.ID,
+			Name:              p.Name,
+			ImageURL:          p.ImageURL,
+			Category:          p.Category,
+			Stock:             p.Stock,
+			Reserved:          reserved[p.ID],
+			OnHand:            p.Stock + reserved[p.ID],
+			Preordered:        p.PreorderCount,
+			ReorderThreshold:  threshold,
+			ThresholdOverride: p.ReorderThreshold != nil,
+			LowStock:          p.Stock <= threshold,
+			LowStockAlertedAt: p.LowStockAlertedAt,
+		})
+	}
+
+	// Catalogue-wide counts for the dashboard header
+	lowStockCount, err := products.CountDocuments(ctx, bson.M{"$expr": lowStockExpr})
+	if err != nil {
+		return apperrors.Internal("Failed to count low-stock products", err)
+	}
+	outOfStockCount, err := products.CountDocuments(ctx, bson.M{"stock": bson.M{"$lte": 0}})
+	if err != nil {
+		return apperrors.Internal("Failed to count out-of-stock products", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Inventory retrieved successfully",
+		"data":    items,
+		"summary": fiber.Map{
+			"lowStock":         lowStockCount,
+			"outOfStock":       outOfStockCount,
+			"defaultThreshold": defaultThreshold,
+		},
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// reservedQuantities sums the units of each product held by orders that have
+// not shipped yet. Unallocated pre-orders hold no stock and are left out.
+func reservedQuantities(ctx context.Context, db *database.DBClient, productIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	reserved := make(map[primitive.ObjectID]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return reserved, nil
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"status": bson.M{"$in": orderstatus.Reserving}, "items.product_id": bson.M{"$in": productIDs}}},
+		bson.M{"$unwind": "$items"},
+		bson.M{"$match": bson.M{"items.product_id": bson.M{"$in": productIDs}, "items.preorder": bson.M{"$ne": true}}},
+		bson.M{"$group": bson.M{"_id": "$items.product_id", "quantity": bson.M{"$sum": "$items.quantity"}}},
+	}
+	cursor, err := db.Collections().Orders.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID       primitive.ObjectID `bson:"_id"`
+		Quantity int                `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		reserved[row.ID] = row.Quantity
+	}
+	return reserved, nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+)
+
+// JobHandler exposes the background job queue to admins
+type JobHandler struct {
+	DB    *database.DBClient
+	Queue *jobs.Queue
+}
+
+// NewJobHandler creates a new instance of JobHandler
+func NewJobHandler(db *database.DBClient, queue *jobs.Queue) *JobHandler {
+	return &JobHandler{DB: db, Queue: queue}
+}
+
+// GetStats reports how many jobs are ready, waiting to retry and dead-lettered
+// GET /admin/jobs/stats
+func (h *JobHandler) GetStats(c *fiber.Ctx) error {
+	stats, err := h.Queue.Stats(c.Context())
+	if err != nil {
+		return apperrors.Internal("Failed to read job queue stats", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Job queue stats retrieved successfully",
+		"data": fiber.Map{
+			"backend":   h.Queue.Backend(),
+			"ready":     stats.Ready,
+			"scheduled": stats.Scheduled,
+			"dead":      stats.Dead,
+		},
+	})
+}
+
+// GetDeadJobs lists jobs that exhausted their retries, most recent first
+// GET /admin/jobs/dead?type=
+func (h *JobHandler) GetDeadJobs(c *fiber.Ctx) error {
+	dead, err := h.Queue.DeadJobs(c.Context())
+	if err != nil {
+		return apperrors.Internal("Failed to fetch dead-lettered jobs", err)
+	}
+
+	if jobType := c.Query("type"); jobType != "" {
+		filtered := dead[:0]
+		for _, job := range dead {
+			if job.Type == jobType {
+				filtered = append(filtered, job)
+			}
+		}
+		dead = filtered
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Dead-lettered jobs retrieved successfully",
+		"data":    dead,
+	})
+}
+
+// RetryDeadJob puts a dead-lettered job back on the queue with fresh attempts
+// POST /admin/jobs/dead/:id/retry
+func (h *JobHandler) RetryDeadJob(c *fiber.Ctx) error {
+	job, err := h.Queue.RetryDead(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			return apperrors.NotFound("Job not found")
+		}
+		return apperrors.Internal("Failed to retry job", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "job.retry", "job", job.ID, nil, job)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Job re-queued successfully",
+		"data":    job,
+	})
+}
+
+// DeleteDeadJob discards a dead-lettered job
+// DELETE /admin/jobs/dead/:id
+func (h *JobHandler) DeleteDeadJob(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.Queue.DiscardDead(c.Context(), id); err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			return apperrors.NotFound("Job not found")
+		}
+		return apperrors.Internal("Failed to delete job", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "job.delete", "job", id, nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Job deleted successfully",
+	})
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+)
+
+// requestLocale picks the locale of a storefront response: ?locale= when it
+// is one of cfg.Locales, otherwise the supported language the shopper's
+// Accept-Language prefers most, otherwise the default locale. The response
+// is marked as varying by Accept-Language and labelled with the locale.
+func requestLocale(c *fiber.Ctx, cfg *config.Config) string {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	locale := cfg.DefaultLocale
+	if query := strings.ToLower(c.Query("locale")); supportedLocale(cfg, query) {
+		locale = query
+	} else {
+		bestQ := 0.0
+		for _, part := range strings.Split(c.Get(fiber.HeaderAcceptLanguage), ",") {
+			tag, params, _ := strings.Cut(part, ";")
+			q := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				parsed, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				q = parsed
+			}
+			// "hi-IN" is served as "hi"
+			lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+			if q > bestQ && supportedLocale(cfg, lang) {
+				locale, bestQ = lang, q
+			}
+		}
+	}
+	c.Set(fiber.HeaderContentLanguage, locale)
+	return locale
+}
+
+// supportedLocale reports whether locale is one of cfg.Locales
+func supportedLocale(cfg *config.Config, locale string) bool {
+	return slices.Contains(cfg.Locales, locale)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// Password login throttling. Failed attempts are counted in the cache per
+// email and per client IP. After loginBackoffAfter consecutive failures an
+// email has to wait before its next attempt, doubling each time; after
+// Config.LoginMaxFailures it is locked until the lockout expires, an admin
+// unlocks it or the password is reset. Failures are counted for unknown
+// emails too, so lockouts don't reveal which accounts exist.
+const (
+	loginFailureWindow = time.Hour // failures older than this are forgotten
+	loginBackoffAfter  = 3
+	loginMaxBackoff    = 5 * time.Minute
+	loginMaxIPFailures = 50 // failed logins from one IP per window, across all emails
+)
+
+func loginFailuresKey(email string) string { return "login:failures:" + email }
+func loginBackoffKey(email string) string  { return "login:backoff:" + email }
+func loginLockedKey(email string) string   { return "login:locked:" + email }
+func loginIPFailuresKey(ip string) string  { return "login:ip_failures:" + ip }
+
+// blockedUntil reads a key holding the unix time a block ends
+func blockedUntil(ctx context.Context, cache database.Cache, key string) (time.Time, bool) {
+	raw, err := cache.Get(ctx, key)
+	if err != nil {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	until := time.Unix(unix, 0)
+	return until, until.After(time.Now())
+}
+
+// retryAfter sets the Retry-After header for a block ending at until
+func retryAfter(c *fiber.Ctx, until time.Time) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(time.Until(until).Seconds()))))
+}
+
+// checkLoginAllowed rejects a login attempt while its email is locked or
+// backing off, or its IP has failed too often. Without a cache logins are
+// not throttled.
+func (h *AuthHandler) checkLoginAllowed(c *fiber.Ctx, email string) error {
+	cache := h.DB.Cache
+	if cache == nil {
+		return nil
+	}
+	ctx := c.Context()
+
+	if until, ok := blockedUntil(ctx, cache, loginLockedKey(email)); ok {
+		retryAfter(c, until)
+		return apperrors.New(fiber.StatusLocked, apperrors.CodeAccountLocked,
+			"Account is temporarily locked after too many failed logins. Try again later or reset your password")
+	}
+	if until, ok := blockedUntil(ctx, cache, loginBackoffKey(email)); ok {
+		retryAfter(c, until)
+		return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many failed logins, please wait before trying again")
+	}
+	if raw, err := cache.Get(ctx, loginIPFailuresKey(c.IP())); err == nil {
+		if n, _ := strconv.Atoi(string(raw)); n >= loginMaxIPFailures {
+			return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many failed logins, please try again later")
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and starts a backoff or lockout
+// once the email has failed often enough. It returns the error to send in
+// place of the usual invalid-credentials response, if any.
+func (h *AuthHandler) recordLoginFailure(c *fiber.Ctx, email string) error {
+	cache := h.DB.Cache
+	if cache == nil {
+		return nil
+	}
+	ctx := c.Context()
+
+	if n, err := cache.Incr(ctx, loginIPFailuresKey(c.IP())); err == nil && n == 1 {
+		_ = cache.Set(ctx, loginIPFailuresKey(c.IP()), []byte("1"), loginFailureWindow)
+	}
+	failures, err := cache.Incr(ctx, loginFailuresKey(email))
+	if err != nil {
+		log.Printf("[AUTH] Failed to count login failure for %s: %v", email, err)
+		return nil
+	}
+	if failures == 1 {
+		// First failure of the window: give the counter its expiry
+		_ = cache.Set(ctx, loginFailuresKey(email), []byte("1"), loginFailureWindow)
+	}
+
+	if limit := h.Config.LoginMaxFailures; limit > 0 && failures >= int64(limit) {
+		lockout := time.Duration(h.Config.LoginLockoutMinutes) * time.Minute
+		until := time.Now().Add(lockout)
+		_ = cache.Set(ctx, loginLockedKey(email), []byte(strconv.FormatInt(until.Unix(), 10)), lockout)
+		_ = cache.Del(ctx, loginFailuresKey(email), loginBackoffKey(email))
+		log.Printf("[AUTH] Locked %s for %s after %d failed logins (last from %s)", email, lockout, failures, c.IP())
+		retryAfter(c, until)
+		return apperrors.New(fiber.StatusLocked, apperrors.CodeAccountLocked,
+			fmt.Sprintf("Too many failed logins. The account is locked for %d minutes or until the password is reset", h.Config.LoginLockoutMinutes))
+	}
+	if failures >= loginBackoffAfter {
+		backoff := time.Second << (failures - loginBackoffAfter)
+		if backoff > loginMaxBackoff || backoff <= 0 {
+			backoff = loginMaxBackoff
+		}
+		until := time.Now().Add(backoff)
+		_ = cache.Set(ctx, loginBackoffKey(email), []byte(strconv.FormatInt(until.Unix(), 10)), backoff)
+		retryAfter(c, until)
+	}
+	return nil
+}
+
+// clearLoginFailures forgets an email's failed logins and lifts any lockout
+func clearLoginFailures(ctx context.Context, db *database.DBClient, email string) error {
+	if email == "" {
+		return nil
+	}
+	return db.CacheDel(ctx, loginFailuresKey(email), loginBackoffKey(email), loginLockedKey(email))
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AdminCreateOrder places an order on a customer's behalf, e.g. one taken
+// over the phone or a sale in a boutique. The cart, purchase limits and gift
+// options don't apply; staff may override line prices, giving a reason.
+// Orders without a shipping address were handed over in store and are
+// placed as delivered.
+// POST /admin/orders
+func (h *OrderHandler) AdminCreateOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.AdminOrderRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if (req.UserID == "") == (req.Customer == nil) {
+		return apperrors.BadRequest("Give either userId or customer", nil)
+	}
+	inStore := req.ShippingAddress == nil
+	if inStore && req.PaymentMethod == "cod" {
+		return apperrors.BadRequest("Cash-on-delivery orders need a shippingAddress", nil)
+	}
+	if !inStore && h.Config.ValidatePincodes {
+		addr := req.ShippingAddress
+		if err := checkPincode(ctx, h.Pincodes, "shippingAddress.", addr.ZipCode, addr.State, addr.Country); err != nil {
+			return err
+		}
+	}
+	staff, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+
+	// Lines are priced and checked like checkout's, except for the purchase
+	// limits, then take their price overrides
+	lines := make([]models.CartItem, 0, len(req.Items))
+	for _, line := range req.Items {
+		productID, err := parseObjectID(line.ProductID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid product ID", err)
+		}
+		lines = append(lines, models.CartItem{ProductID: productID, Size: line.Size, Quantity: line.Quantity})
+	}
+	orderItems, shipmentItems, err := h.orderItems(ctx, primitive.NilObjectID, lines, false)
+	if err != nil {
+		return err
+	}
+	var total float64
+	for i, line := range req.Items {
+		item := &orderItems[i]
+		if item.Preorder && inStore {
+			return apperrors.BadRequest(fmt.Sprintf("%s is a pre-order and can't be handed over in store", item.ProductName), nil)
+		}
+		if line.Price != nil && *line.Price != item.Price {
+			item.ListPrice = item.Price
+			item.Price = *line.Price
+			item.PriceReason = strings.TrimSpace(line.PriceReason)
+			item.Subtotal = item.Price * float64(item.Quantity)
+		}
+		total += item.Subtotal
+	}
+
+	customer, newGuest, err := h.orderCustomer(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	stockAfter, err := h.takeStock(ctx, orderItems)
+	if err != nil {
+		return err
+	}
+	// A guest account is only created once the order's stock is secured
+	if newGuest {
+		if err := h.createGuest(ctx, customer); err != nil {
+			h.putBackStock(ctx, orderItems)
+			return err
+		}
+	}
+
+	now := time.Now()
+	placed := "Order placed by staff"
+	if req.Note != "" {
+		placed += ": " + req.Note
+	}
+	// Staff orders are taken in the base currency
+	currency := models.DefaultCurrency()
+	paymentStatus := "unpaid"
+	if req.PaymentMethod == "offline" {
+		paymentStatus = "paid"
+	}
+	order := models.Order{
+		ID:            primitive.NewObjectID(),
+		UserID:        customer.ID,
+		Items:         orderItems,
+		Total:         total,
+		Currency:      currency.Code,
+		ExchangeRate:  currency.Rate,
+		ChargedTotal:  total,
+		Status:        orderstatus.Processing,
+		PaymentStatus: paymentStatus,
+		PaymentInfo:   models.PaymentInfo{Method: req.PaymentMethod},
+		StatusHistory: []models.OrderStatusEvent{orderStatusEvent(c, orderstatus.Processing, placed)},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	var delivered models.OrderStatusEvent
+	if inStore {
+		delivered = orderStatusEvent(c, orderstatus.Delivered, "Handed over in store")
+		order.Status = orderstatus.Delivered
+		order.DeliveredAt = &now
+		order.StatusHistory = append(order.StatusHistory, delivered)
+	} else {
+		shipment := models.PackShipment(shipmentItems, h.Config.ShippingVolumetricDivisor)
+		order.ShippingAddress = *req.ShippingAddress
+		order.Shipment = &shipment
+	}
+
+	if _, err := h.DB.Collections().Orders.InsertOne(ctx, order); err != nil {
+		h.putBackStock(ctx, orderItems)
+		if newGuest {
+			if _, err := h.DB.Collections().Users.DeleteOne(ctx, bson.M{"_id": customer.ID}); err != nil {
+				log.Printf("[ORDERS] Failed to remove guest account %s of unplaced order: %v", customer.ID.Hex(), err)
+			}
+		}
+		return apperrors.Internal("Failed to create order", err)
+	}
+
+	logSale(ctx, h.DB, order, stockAfter, staff.UserID)
+
+	h.DB.CacheDel(ctx, fmt.Sprintf("orders:%s", customer.ID.Hex()))
+
+	h.Events.Publish(ctx, events.OrderCreated, order)
+	if inStore {
+		// Issues the warranties and starts the return window as a delivery would
+		h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(order, orderstatus.Processing, delivered))
+	}
+	recordAudit(c, h.DB.MongoDB, "order.create", "order", order.ID.Hex(), nil, order)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Order created successfully",
+		"data":    order,
+	})
+}
+
+// orderCustomer returns the account an admin order is for: the one named
+// by userId, or the walk-in customer's. A walk-in customer whose email or
+// phone already has an account gets that one; otherwise a new guest account
+// is returned for createGuest to store, which they can later sign in to with
+// the same email or phone. An email and phone of two different accounts are
+// refused.
+func (h *OrderHandler) orderCustomer(ctx context.Context, req models.AdminOrderRequest) (user models.User, newGuest bool, err error) {
+	users := h.DB.Collections().Users
+	if req.UserID != "" {
+		userID, err := parseObjectID(req.UserID)
+		if err != nil {
+			return user, false, apperrors.BadRequest("Invalid user ID", err)
+		}
+		if err = users.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return user, false, apperrors.NotFound("Customer not found")
+			}
+			return user, false, apperrors.Internal("Failed to retrieve customer", err)
+		}
+		if user.IsDeleted() {
+			return user, false, apperrors.Conflict("The customer's account has been deleted")
+		}
+		return user, false, nil
+	}
+
+	guest := req.Customer
+	email := models.NormalizeEmail(guest.Email)
+	var phone string
+	if guest.Phone != "" {
+		var ok bool
+		if phone, ok = models.NormalizePhone(guest.Phone); !ok {
+			return user, false, apperrors.BadRequest("Invalid customer phone number", nil)
+		}
+	}
+	var known []bson.M
+	if email != "" {
+		known = append(known, bson.M{"email": email})
+	}
+	if phone != "" {
+		known = append(known, bson.M{"phone": phone})
+	}
+	cursor, err := users.Find(ctx, bson.M{"$or": known, "status": bson.M{"$ne": models.UserStatusDeleted}}, options.Find().SetLimit(2))
+	if err != nil {
+		return user, false, apperrors.Internal("Failed to look up customer", err)
+	}
+	var matches []models.User
+	if err := cursor.All(ctx, &matches); err != nil {
+		return user, false, apperrors.Internal("Failed to look up customer", err)
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], false, nil
+	case 2:
+		// The order would go to whichever account came first
+		return user, false, apperrors.Conflict("The customer's email and phone belong to different accounts; place the order with userId")
+	}
+
+	now := time.Now()
+	user = models.User{
+		ID:           primitive.NewObjectID(),
+		Name:         strings.TrimSpace(guest.Name),
+		Email:        email,
+		Phone:        phone,
+		Role:         models.RoleUser,
+		AuthProvider: "guest",
+		Status:       models.UserStatusActive,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return user, true, nil
+}
+
+// createGuest stores the guest account orderCustomer made for a walk-in
+// customer
+func (h *OrderHandler) createGuest(ctx context.Context, user models.User) error {
+	if _, err := h.DB.Collections().Users.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("The customer's email or phone belongs to a deleted account")
+		}
+		return apperrors.Internal("Failed to create customer", err)
+	}
+	return nil
+}
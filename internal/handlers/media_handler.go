@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// MediaHandler lists the media library and deletes unused images from it
+type MediaHandler struct {
+	DB      *database.DBClient
+	Storage storage.Storage
+}
+
+// NewMediaHandler creates a new instance of MediaHandler
+func NewMediaHandler(db *database.DBClient, store storage.Storage) *MediaHandler {
+	return &MediaHandler{DB: db, Storage: store}
+}
+
+// mediaItem is a library image with the documents using it
+type mediaItem struct {
+	models.MediaAsset
+	UsedBy []jobs.ImageUsage `json:"usedBy"`
+}
+
+// recordMedia adds freshly stored uploads to the media library. The files
+// are stored either way, so a failure is only logged.
+func recordMedia(c *fiber.Ctx, db *database.DBClient, assets []models.MediaAsset) {
+	if len(assets) == 0 {
+		return
+	}
+	now := time.Now()
+	var uploadedBy primitive.ObjectID
+	if user, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		uploadedBy = user.UserID
+	}
+	docs := make([]interface{}, len(assets))
+	for i := range assets {
+		assets[i].ID = primitive.NewObjectID()
+		assets[i].UploadedBy = uploadedBy
+		assets[i].CreatedAt = now
+		docs[i] = assets[i]
+	}
+	if _, err := db.Collections().Media.InsertMany(context.Background(), docs); err != nil {
+		log.Printf("[MEDIA] Failed to add %d uploads to the media library: %v", len(assets), err)
+	}
+}
+
+// mediaUsages lists the documents using any file of each asset
+func (h *MediaHandler) mediaUsages(ctx context.Context, assets []models.MediaAsset) ([][]jobs.ImageUsage, error) {
+	urls := []string{}
+	for _, asset := range assets {
+		urls = append(urls, asset.URLs...)
+	}
+	byURL, err := jobs.ImageUsages(ctx, h.DB, urls)
+	if err != nil {
+		return nil, err
+	}
+	usages := make([][]jobs.ImageUsage, len(assets))
+	for i, asset := range assets {
+		seen := map[jobs.ImageUsage]bool{}
+		usages[i] = []jobs.ImageUsage{}
+		for _, url := range asset.URLs {
+			for _, usage := range byURL[url] {
+				if !seen[usage] {
+					seen[usage] = true
+					usages[i] = append(usages[i], usage)
+				}
+			}
+		}
+	}
+	return usages, nil
+}
+
+// GetMedia lists the media library newest first, each image with the
+// documents using it. search matches the original file name.
+// GET /admin/media?search=&page=1&limit=24
+func (h *MediaHandler) GetMedia(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "24"))
+	if limit < 1 || limit > 100 {
+		limit = 24
+	}
+	filter := bson.M{}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		filter["filename"] = primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+	}
+
+	coll := h.DB.Collections().Media
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count media", err)
+	}
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch media", err)
+	}
+	assets := []models.MediaAsset{}
+	if err := cursor.All(ctx, &assets); err != nil {
+		return apperrors.Internal("Failed to decode media", err)
+	}
+	usages, err := h.mediaUsages(ctx, assets)
+	if err != nil {
+		return apperrors.Internal("Failed to find where media is used", err)
+	}
+	items := make([]mediaItem, len(assets))
+	for i, asset := range assets {
+		items[i] = mediaItem{MediaAsset: asset, UsedBy: usages[i]}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Media retrieved successfully",
+		"data":    items,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// DeleteMedia removes an image from the media library and deletes its files
+// from storage. Images still used anywhere can't be deleted.
+// DELETE /admin/media/:id
+func (h *MediaHandler) DeleteMedia(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objectID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid media ID", err)
+	}
+	coll := h.DB.Collections().Media
+	var asset models.MediaAsset
+	if err := coll.FindOne(ctx, bson.M{"_id": objectID}).Decode(&asset); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Media not found")
+		}
+		return apperrors.Internal("Failed to fetch media", err)
+	}
+
+	usages, err := h.mediaUsages(ctx, []models.MediaAsset{asset})
+	if err != nil {
+		return apperrors.Internal("Failed to find where media is used", err)
+	}
+	if used := usages[0]; len(used) > 0 {
+		collections := map[string]bool{}
+		for _, usage := range used {
+			collections[usage.Collection] = true
+		}
+		names := make([]string, 0, len(collections))
+		for name := range collections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return apperrors.Conflict(fmt.Sprintf("The image is still used by %d documents in %s", len(used), strings.Join(names, ", ")))
+	}
+
+	if _, err := coll.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return apperrors.Internal("Failed to delete media", err)
+	}
+	// The record is gone, so leftover files are only orphans for the cleanup job
+	if n := storage.DeleteURLs(ctx, h.Storage, asset.URLs); n < len(asset.URLs) {
+		log.Printf("[MEDIA] Deleted %d of %d files of media %s", n, len(asset.URLs), id)
+	}
+	recordAudit(c, h.DB.MongoDB, "media.delete", "media", id, asset, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Media deleted successfully",
+	})
+}
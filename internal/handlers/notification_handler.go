@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// NotificationHandler serves the customer's in-app notification center.
+// Notifications are created by the notify package.
+type NotificationHandler struct {
+	DB *database.DBClient
+}
+
+// NewNotificationHandler creates a new instance of NotificationHandler
+func NewNotificationHandler(db *database.DBClient) *NotificationHandler {
+	return &NotificationHandler{DB: db}
+}
+
+// GetNotifications lists the user's notifications, newest first, with the
+// unread count
+// GET /notifications?unread=true&page=1&limit=20
+func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{"user_id": user.UserID}
+	if c.QueryBool("unread") {
+		filter["is_read"] = false
+	}
+
+	coll := h.DB.Collections().Notifications
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count notifications", err)
+	}
+	unread, err := coll.CountDocuments(ctx, bson.M{"user_id": user.UserID, "is_read": false})
+	if err != nil {
+		return apperrors.Internal("Failed to count unread notifications", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch notifications", err)
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []models.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return apperrors.Internal("Failed to decode notifications", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Notifications retrieved successfully",
+		"data":    notifications,
+		"meta": fiber.Map{
+			"page":   page,
+			"limit":  limit,
+			"total":  total,
+			"pages":  (total + int64(limit) - 1) / int64(limit),
+			"unread": unread,
+		},
+	})
+}
+
+// MarkRead marks one of the user's notifications as read
+// PATCH /notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid notification ID", err)
+	}
+
+	// Scoped to the user so nobody can touch someone else's notifications
+	res, err := h.DB.Collections().Notifications.UpdateOne(ctx,
+		bson.M{"_id": objectID, "user_id": user.UserID},
+		bson.M{"$set": bson.M{"is_read": true}},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to update notification", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound("Notification not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllRead marks every unread notification of the user as read
+// PATCH /notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	res, err := h.DB.Collections().Notifications.UpdateMany(ctx,
+		bson.M{"user_id": user.UserID, "is_read": false},
+		bson.M{"$set": bson.M{"is_read": true}},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to update notifications", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "All notifications marked as read",
+		"data":    fiber.Map{"updated": res.ModifiedCount},
+	})
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EditOrder lets a customer correct an order while it waits for payment:
+// fix the shipping address and lower item quantities. The total, the stock,
+// the gift card and the Razorpay payment follow the change, and the order's
+// status history records it.
+// PATCH /orders/:orderID
+func (h *OrderHandler) EditOrder(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	orderID, err := parseObjectID(c.Params("orderID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid order ID format", err)
+	}
+	var req models.OrderEditRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if req.ShippingAddress == nil && len(req.Items) == 0 {
+		return apperrors.BadRequest("Nothing to change; send a shippingAddress or items", nil)
+	}
+
+	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+
+	orderCollection := h.DB.Collections().Orders
+	var order models.Order
+	if err := orderCollection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Order not found")
+		}
+		return apperrors.Internal("Failed to retrieve order", err)
+	}
+	if order.UserID != tokenUser.UserID {
+		return apperrors.Forbidden("Not authorized to edit this order")
+	}
+	// Once processing starts the order may already be picked and packed
+	if order.Status != orderstatus.Pending {
+		return apperrors.Conflict(fmt.Sprintf("A %s order can no longer be edited", order.Status))
+	}
+
+	if req.ShippingAddress != nil && h.Config.ValidatePincodes {
+		addr := req.ShippingAddress
+		if err := checkPincode(ctx, h.Pincodes, "shippingAddress.", addr.ZipCode, addr.State, addr.Country); err != nil {
+			return err
+		}
+	}
+
+	items, released, notes, err := editOrderItems(order.Items, req.Items)
+	if err != nil {
+		return err
+	}
+	if req.ShippingAddress != nil {
+		notes = append([]string{"Shipping address changed"}, notes...)
+	}
+	if len(notes) == 0 {
+		return apperrors.BadRequest("Nothing to change; the order already has these quantities", nil)
+	}
+
+	set := bson.M{}
+	if req.ShippingAddress != nil {
+		set["shipping_address"] = *req.ShippingAddress
+	}
+
+	// Lower quantities lower the total. The gift card keeps paying what it
+	// can, and gives back anything above the new total.
+	status := order.Status
+	var payment fiber.Map
+	var giveBack float64
+	if len(released) > 0 {
+		total := 0.0
+		for _, item := range items {
+			total += item.Subtotal
+		}
+		if order.Gift != nil && order.Gift.Wrap {
+			total += order.Gift.WrapPrice
+		}
+		due := total
+		if order.GiftCard != nil {
+			giftCard := *order.GiftCard
+			if giftCard.Amount > total {
+				giveBack = giftCard.Amount - total
+				giftCard.Amount = total
+			}
+			due -= giftCard.Amount
+			set["gift_card"] = giftCard
+		}
+
+		// The order is charged at its checkout exchange rate
+		currency := models.DefaultCurrency()
+		if order.Currency != "" && order.Currency != currency.Code {
+			currency, err = lookupCurrency(ctx, h.DB, order.Currency)
+			if err != nil {
+				return err
+			}
+			currency.Rate = order.ExchangeRate
+		}
+
+		shipment, err := h.packOrder(ctx, items)
+		if err != nil {
+			return err
+		}
+		set["items"] = items
+		set["total"] = total
+		set["charged_total"] = currency.Convert(due)
+		set["shipment"] = shipment
+
+		// The Razorpay order was created for the old total, so paying it
+		// must no longer settle this order
+		if awaitingRazorpayPayment(order) {
+			payments := &PaymentHandler{DB: h.DB, Cfg: h.Config, Events: h.Events}
+			check, err := payments.payments().Check(ctx, order)
+			if err != nil {
+				return apperrors.BadGateway("Failed to check the order's payment", err)
+			}
+			if check.Paid || check.InFlight {
+				return apperrors.Conflict("The order is being paid and can no longer be edited")
+			}
+			if due > 0 {
+				mode := order.PaymentInfo.Mode
+				if mode != models.PaymentModeTest {
+					mode = models.PaymentModeLive
+				}
+				keys := h.Config.Razorpay(mode)
+				if !keys.Configured() {
+					return apperrors.Unavailable("Payment gateway not configured", nil)
+				}
+				var razorpayOrderID string
+				payment, razorpayOrderID, err = payments.createRazorpayOrder(ctx, keys, order.UserID, currency, due)
+				if err != nil {
+					return apperrors.BadGateway("Failed to create payment order", err)
+				}
+				set["payment_info.razorpay_order_id"] = razorpayOrderID
+			} else {
+				// The gift card now covers the whole order
+				status = orderstatus.Processing
+				set["status"] = status
+				set["payment_status"] = "paid"
+				set["payment_info.method"] = "gift_card"
+				notes = append(notes, "Paid in full by gift card")
+			}
+		}
+	}
+
+	// The status and update time filters stop the order from being edited
+	// twice, or paid for the old total, between reading and writing it
+	event := orderStatusEvent(c, status, strings.Join(notes, "; "))
+	set["updated_at"] = time.Now()
+	var updated models.Order
+	err = orderCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": order.ID, "status": orderstatus.Pending, "updated_at": order.UpdatedAt},
+		bson.M{"$set": set, "$push": bson.M{"status_history": event}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.Conflict("The order was changed by someone else, reload and try again")
+		}
+		return apperrors.Internal("Failed to update order", err)
+	}
+
+	if giveBack > 0 {
+		if err := giftcards.GiveBack(ctx, h.DB, order, giveBack); err != nil {
+			log.Printf("[ORDERS] Failed to give back %.2f of gift card for order %s: %v", giveBack, order.ID.Hex(), err)
+		}
+	}
+	h.restock(ctx, order, released, tokenUser.UserID)
+
+	h.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()))
+	h.DB.CacheDel(ctx, fmt.Sprintf("orders:%s", order.UserID.Hex()))
+
+	if status != order.Status {
+		h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(updated, order.Status, event))
+	}
+	recordAudit(c, h.DB.MongoDB, "order.edit", "order", order.ID.Hex(),
+		bson.M{"items": order.Items, "total": order.Total, "shipping_address": order.ShippingAddress},
+		bson.M{"items": updated.Items, "total": updated.Total, "shipping_address": updated.ShippingAddress})
+
+	response := fiber.Map{
+		"success": true,
+		"message": "Order updated successfully",
+		"data":    updated,
+	}
+	if payment != nil {
+		response["razorpay"] = payment
+	}
+	return c.JSON(response)
+}
+
+// editOrderItems applies quantity edits to an order's lines. It returns the
+// remaining lines, the units each lowered line gives up and a note per
+// change for the status history. Quantities can only go down, and at least
+// one line must remain.
+func editOrderItems(items []models.OrderItem, edits []models.OrderItemEdit) ([]models.OrderItem, []models.OrderItem, []string, error) {
+	quantities := make([]int, len(items))
+	for i, item := range items {
+		quantities[i] = item.Quantity
+	}
+	for _, edit := range edits {
+		i := -1
+		for j, item := range items {
+			if item.ProductID.Hex() == edit.ProductID && item.Size == edit.Size {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, nil, nil, apperrors.BadRequest(fmt.Sprintf("The order has no line for product %s in size %q", edit.ProductID, edit.Size), nil)
+		}
+		if edit.Quantity > items[i].Quantity {
+			return nil, nil, nil, apperrors.BadRequest(fmt.Sprintf("The quantity of %s can only be lowered; place another order for more", items[i].ProductName), nil)
+		}
+		quantities[i] = edit.Quantity
+	}
+
+	var remaining, released []models.OrderItem
+	var notes []string
+	for i, item := range items {
+		if dropped := item.Quantity - quantities[i]; dropped > 0 {
+			released = append(released, models.OrderItem{ProductID: item.ProductID, Size: item.Size, Quantity: dropped, Preorder: item.Preorder})
+			if quantities[i] == 0 {
+				notes = append(notes, fmt.Sprintf("%s removed", item.ProductName))
+			} else {
+				notes = append(notes, fmt.Sprintf("%s lowered from %d to %d", item.ProductName, item.Quantity, quantities[i]))
+			}
+		}
+		if quantities[i] > 0 {
+			item.Quantity = quantities[i]
+			item.Subtotal = item.Price * float64(item.Quantity)
+			remaining = append(remaining, item)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, nil, nil, apperrors.BadRequest("An order needs at least one item; cancel the order instead", nil)
+	}
+	return remaining, released, notes, nil
+}
+
+// packOrder packs an order's lines into a shipment, as checkout does
+func (h *OrderHandler) packOrder(ctx context.Context, items []models.OrderItem) (models.Shipment, error) {
+	shipmentItems := make([]models.ShipmentItem, 0, len(items))
+	for _, item := range items {
+		var product models.Product
+		if err := h.DB.Collections().Products.FindOne(ctx, bson.M{"_id": item.ProductID}).Decode(&product); err != nil {
+			return models.Shipment{}, apperrors.Internal("Failed to retrieve product details", err)
+		}
+		shipmentItems = append(shipmentItems, models.ShipmentItem{Product: product, Quantity: item.Quantity})
+	}
+	return models.PackShipment(shipmentItems, h.Config.ShippingVolumetricDivisor), nil
+}
+
+// restock returns the units of items taken by an order to stock, recording
+// them in the stock ledger. Unallocated pre-orders never took stock, so they
+// only leave the pre-order count.
+func (h *OrderHandler) restock(ctx context.Context, order models.Order, items []models.OrderItem, actorID primitive.ObjectID) {
+	productsCollection := h.DB.Collections().Products
+	for _, item := range items {
+		if item.Preorder {
+			if _, err := productsCollection.UpdateOne(ctx, bson.M{"_id": item.ProductID},
+				bson.M{"$inc": bson.M{"preorder_count": -item.Quantity}}); err != nil {
+				log.Printf("[ORDERS] Failed to release pre-order of product %s: %v", item.ProductID.Hex(), err)
+			}
+			h.DB.InvalidateProductCaches(ctx, item.ProductID.Hex())
+			continue
+		}
+		var restored models.Product
+		err := productsCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"stock": item.Quantity}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"stock": 1}),
+		).Decode(&restored)
+		if err != nil {
+			log.Printf("[ORDERS] Failed to restore stock of product %s: %v", item.ProductID.Hex(), err)
+		} else {
+			logStockMovement(ctx, h.DB, models.StockMovement{
+				ProductID:  item.ProductID,
+				Delta:      item.Quantity,
+				StockAfter: restored.Stock,
+				Reason:     models.StockReasonCancellation,
+				OrderID:    &order.ID,
+				ActorID:    actorID,
+			})
+		}
+
+		// Invalidate product cache (stock also affects listings)
+		h.DB.InvalidateProductCaches(ctx, item.ProductID.Hex())
+	}
+}
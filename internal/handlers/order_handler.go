@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,16 +19,32 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/flags"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pincode"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/razorpay"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/sms"
 )
 
 // OrderHandler handles order related requests
 type OrderHandler struct {
-	DB     *database.DBClient
-	Config *config.Config
+	DB       *database.DBClient
+	Config   *config.Config
+	SMS      sms.Provider
+	Mailer   mailer.Sender
+	Pincodes *pincode.Directory
+	Jobs     *jobs.Queue // Optional; emails are sent inline when nil
+	Events   *events.Bus // Optional
+	Flags    *flags.Service
 }
 
 // NewOrderHandler creates a new instance of OrderHandler
@@ -31,6 +52,23 @@ func NewOrderHandler(db *database.DBClient, cfg *config.Config) *OrderHandler {
 	return &OrderHandler{
 		DB:     db,
 		Config: cfg,
+		SMS: sms.New(sms.Options{
+			Provider:         cfg.SMSProvider,
+			MSG91AuthKey:     cfg.MSG91AuthKey,
+			MSG91TemplateID:  cfg.MSG91TemplateID,
+			TwilioAccountSID: cfg.TwilioAccountSID,
+			TwilioAuthToken:  cfg.TwilioAuthToken,
+			TwilioFromNumber: cfg.TwilioFromNumber,
+		}),
+		Mailer: mailer.New(mailer.Options{
+			SMTPHost:     cfg.SMTPHost,
+			SMTPPort:     cfg.SMTPPort,
+			SMTPUsername: cfg.SMTPUsername,
+			SMTPPassword: cfg.SMTPPassword,
+			From:         cfg.MailFrom,
+		}),
+		Pincodes: newPincodeDirectory(db, cfg),
+		Flags:    &flags.Service{DB: db},
 	}
 }
 
@@ -41,215 +79,541 @@ func (h *OrderHandler) Checkout(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
-	// Parse request body
+	// Parse and validate request body
 	var req models.CheckoutRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
-	}
-
-	// Validate request
-	if req.ShippingAddress.Street == "" || req.ShippingAddress.City == "" ||
-		req.ShippingAddress.State == "" || req.ShippingAddress.ZipCode == "" ||
-		req.ShippingAddress.Country == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Complete shipping address is required",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	if req.PaymentInfo.Method == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Payment method is required",
-		})
+	// Get the user's cart, or the lines selected from it
+	cartFilter, selected, err := cartSelection(user.UserID, req.CartItemIDs)
+	if err != nil {
+		return err
 	}
-
-	// Get the user's cart
 	cartCollection := h.DB.Collections().CartItems
-	cursor, err := cartCollection.Find(ctx, bson.M{"user_id": user.UserID})
+	cursor, err := cartCollection.Find(ctx, cartFilter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve cart",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve cart", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Parse cart items
 	var cartItems []models.CartItem
 	if err := cursor.All(ctx, &cartItems); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode cart items",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode cart items", err)
 	}
 
 	// Check if cart is empty
-	if len(cartItems) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Cart is empty",
-		})
+	if len(cartItems) == 0 && selected == 0 {
+		return apperrors.BadRequest("Cart is empty", nil)
+	}
+	if len(cartItems) < selected {
+		return apperrors.Conflict("Some of the selected items are no longer in your cart; refresh it and try again")
 	}
 
-	// Create order items and calculate total (authoritative server-side)
-	var orderItems []models.OrderItem
-	var total float64
-	productsCollection := h.DB.Collections().Products
+	order, _, err := h.placeOrder(c, user, &req, cartItems, cartFilter, false)
+	if err != nil {
+		return err
+	}
 
-	for _, item := range cartItems {
-		// Get product details
-		var product models.Product
-		err := productsCollection.FindOne(ctx, bson.M{"_id": item.ProductID}).Decode(&product)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to retrieve product details",
-				"error":   err.Error(),
-			})
-		}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Order placed successfully",
+		"data":    order,
+	})
+}
 
-		// Check if there's enough stock
-		if product.Stock < item.Quantity {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": fmt.Sprintf("Not enough stock for product %s", product.Name),
-			})
-		}
+// BuyNow places an order for one product without touching the cart. A
+// Razorpay checkout without a Razorpay order gets one for the order's total,
+// returned as razorpay for the payment widget; the order stays pending until
+// it is paid.
+// POST /checkout/buy-now
+func (h *OrderHandler) BuyNow(c *fiber.Ctx) error {
+	ctx := c.Context()
 
-		// Use discounted price if active
-		finalPrice := product.GetFinalPrice()
-		// Create order item
-		orderItem := models.OrderItem{
-			ProductID:   product.ID,
-			ProductName: product.Name,
-			Price:       finalPrice,
-			Size:        item.Size,
-			Quantity:    item.Quantity,
-			Subtotal:    finalPrice * float64(item.Quantity),
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	var line models.BuyNowRequest
+	if err := bindAndValidate(c, &line); err != nil {
+		return err
+	}
+	var req models.CheckoutRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	req.CartItemIDs = nil
+
+	productID, err := parseObjectID(line.ProductID)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	count, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"_id": productID})
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve product", err)
+	}
+	if count == 0 {
+		return apperrors.NotFound("Product not found")
+	}
+
+	item := models.CartItem{UserID: user.UserID, ProductID: productID, Size: line.Size, Quantity: line.Quantity}
+	order, payment, err := h.placeOrder(c, user, &req, []models.CartItem{item}, nil, true)
+	if err != nil {
+		return err
+	}
+
+	response := fiber.Map{
+		"success": true,
+		"message": "Order placed successfully",
+		"data":    order,
+	}
+	if payment != nil {
+		response["razorpay"] = payment
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// placeOrder places an order for lines: it prices them, checks stock,
+// purchase limits and the payment, takes the units out of stock and stores
+// the order. lines are removed from the cart with cartFilter unless it is
+// nil. With createPayment, a Razorpay checkout without a Razorpay order gets
+// one for the order's total, returned for the payment widget; the order
+// then waits for it to be paid.
+func (h *OrderHandler) placeOrder(c *fiber.Ctx, user *middleware.TokenMetadata, req *models.CheckoutRequest, lines []models.CartItem, cartFilter bson.M, createPayment bool) (models.Order, fiber.Map, error) {
+	ctx := c.Context()
+
+	if h.Config.ValidatePincodes {
+		addr := req.ShippingAddress
+		if err := checkPincode(ctx, h.Pincodes, "shippingAddress.", addr.ZipCode, addr.State, addr.Country); err != nil {
+			return models.Order{}, nil, err
 		}
+	}
+
+	if err := requireVerifiedAccount(ctx, h.DB, h.Config, user.UserID); err != nil {
+		return models.Order{}, nil, err
+	}
+	currency, err := lookupCurrency(ctx, h.DB, req.Currency)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
 
-		orderItems = append(orderItems, orderItem)
+	// Create order items and calculate total (authoritative server-side)
+	orderItems, shipmentItems, err := h.orderItems(ctx, user.UserID, lines, true)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	// Lines priced differently from when they were added, and the cart's
+	// total at the prices it was added at
+	var priceChanges []models.CartPriceChange
+	var total, addedTotal float64
+	for i, item := range lines {
+		orderItem := orderItems[i]
 		total += orderItem.Subtotal
 
-		// Update product stock
-		_, err = productsCollection.UpdateOne(
-			ctx,
-			bson.M{"_id": product.ID},
-			bson.M{"$inc": bson.M{"stock": -item.Quantity}},
-		)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update product stock",
-				"error":   err.Error(),
+		addedPrice := orderItem.Price
+		if item.PriceChangedFrom(orderItem.Price) {
+			addedPrice = item.PriceAtAdd
+			priceChanges = append(priceChanges, models.CartPriceChange{
+				ProductID:   orderItem.ProductID,
+				ProductName: orderItem.ProductName,
+				Size:        item.Size,
+				OldPrice:    item.PriceAtAdd,
+				NewPrice:    orderItem.Price,
 			})
 		}
+		addedTotal += addedPrice * float64(item.Quantity)
+	}
+	// Price changes that cancel out leave the total alone and need no consent
+	if math.Abs(total-addedTotal) >= 0.01 && !req.AcceptPriceChanges {
+		e := apperrors.Conflict("Prices in your cart have changed; review them and confirm to place the order").WithCode(apperrors.CodePriceChanged)
+		e.Fields = priceChanges
+		return models.Order{}, nil, e
+	}
+	shipment := models.PackShipment(shipmentItems, h.Config.ShippingVolumetricDivisor)
 
-		// Invalidate product cache
-		productCacheKey := fmt.Sprintf("product:%s", product.ID.Hex())
-		h.DB.CacheDel(ctx, productCacheKey)
+	price, err := priceCheckout(ctx, h.DB, total, req.GiftWrap, req.GiftMessage, req.GiftCardCode)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	total = price.Total
+	chargedTotal := currency.Convert(price.Due)
+
+	// Orders the gift card pays in full are paid with it; the rest need
+	// another payment method
+	paidByGiftCard := req.PaymentInfo.Method == "gift_card"
+	if paidByGiftCard != (price.GiftCard != nil && price.Due <= 0) {
+		if paidByGiftCard {
+			return models.Order{}, nil, apperrors.BadRequest("The gift card balance doesn't cover the order; choose a payment method for the rest", nil)
+		}
+		return models.Order{}, nil, apperrors.BadRequest("The gift card covers the whole order; use payment method gift_card", nil)
 	}
 
-	// Verify Razorpay signature if method is razorpay
+	// A Razorpay order may be placed before it is paid: without the payment
+	// and its signature it waits in pending until the webhook, the payment
+	// status endpoint or the reconciliation job confirms it
+	awaitingPayment := req.PaymentInfo.Method == "razorpay" && req.PaymentInfo.RazorpayPaymentID == "" && req.PaymentInfo.RazorpaySignature == ""
+
+	// Verify the Razorpay payment if method is razorpay. It must have been
+	// made in the current mode, which the order records.
+	req.PaymentInfo.Mode = ""
+	newRazorpayOrder := createPayment && awaitingPayment && req.PaymentInfo.RazorpayOrderID == ""
+	var keys config.RazorpayKeys
 	if req.PaymentInfo.Method == "razorpay" {
-		if req.PaymentInfo.RazorpayOrderID == "" || req.PaymentInfo.RazorpayPaymentID == "" || req.PaymentInfo.RazorpaySignature == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Missing Razorpay payment details"})
+		if req.PaymentInfo.RazorpayOrderID == "" && !newRazorpayOrder {
+			return models.Order{}, nil, apperrors.BadRequest("Missing Razorpay payment details", nil)
+		}
+		keys = h.Config.Razorpay(paymentMode(ctx, h.DB, h.Config))
+		if !keys.Configured() {
+			return models.Order{}, nil, apperrors.Unavailable("Payment gateway not configured", nil)
+		}
+		req.PaymentInfo.Mode = keys.Mode
+	}
+	if req.PaymentInfo.Method == "razorpay" && !newRazorpayOrder {
+		placed, err := h.DB.Collections().Orders.CountDocuments(ctx, bson.M{"payment_info.razorpay_order_id": req.PaymentInfo.RazorpayOrderID})
+		if err != nil {
+			return models.Order{}, nil, apperrors.Internal("Failed to check for an existing order", err)
+		}
+		if placed > 0 {
+			return models.Order{}, nil, apperrors.Conflict("An order was already placed for this payment")
 		}
-		mac := hmac.New(sha256.New, []byte(h.Config.RazorpaySecret))
-		mac.Write([]byte(req.PaymentInfo.RazorpayOrderID + "|" + req.PaymentInfo.RazorpayPaymentID))
-		expected := hex.EncodeToString(mac.Sum(nil))
-		if expected != req.PaymentInfo.RazorpaySignature {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid payment signature"})
+		if awaitingPayment {
+			if err := h.checkRazorpayOrder(ctx, keys, user.UserID, req.PaymentInfo.RazorpayOrderID, currency, chargedTotal); err != nil {
+				return models.Order{}, nil, err
+			}
+		} else {
+			if req.PaymentInfo.RazorpayPaymentID == "" || req.PaymentInfo.RazorpaySignature == "" {
+				return models.Order{}, nil, apperrors.BadRequest("Missing Razorpay payment details", nil)
+			}
+			mac := hmac.New(sha256.New, []byte(keys.Secret))
+			mac.Write([]byte(req.PaymentInfo.RazorpayOrderID + "|" + req.PaymentInfo.RazorpayPaymentID))
+			expected := hex.EncodeToString(mac.Sum(nil))
+			if expected != req.PaymentInfo.RazorpaySignature {
+				return models.Order{}, nil, apperrors.BadRequest("Invalid payment signature", nil)
+			}
 		}
 	}
 
 	// Defensive: If client supplied a clientTotal ensure it matches authoritative total
 	if req.ClientTotal != nil {
 		clientTotal := *req.ClientTotal
-		// Allow small rounding difference (₹1)
-		if clientTotal < total-1 || clientTotal > total+1 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": fmt.Sprintf("Total mismatch. Client: %.2f Server: %.2f", clientTotal, total),
-			})
+		// Allow small rounding difference (one unit of the currency)
+		if clientTotal < chargedTotal-1 || clientTotal > chargedTotal+1 {
+			return models.Order{}, nil, apperrors.BadRequest(fmt.Sprintf("Total mismatch. Client: %.2f Server: %.2f %s", clientTotal, chargedTotal, currency.Code), nil)
+		}
+	}
+
+	// The Razorpay order is created once the checkout is known to be valid,
+	// for exactly its total
+	var payment fiber.Map
+	if newRazorpayOrder {
+		payments := &PaymentHandler{DB: h.DB, Cfg: h.Config}
+		payment, req.PaymentInfo.RazorpayOrderID, err = payments.createRazorpayOrder(ctx, keys, user.UserID, currency, price.Due)
+		if err != nil {
+			return models.Order{}, nil, apperrors.BadGateway("Failed to create payment order", err)
+		}
+	}
+
+	orderID := primitive.NewObjectID()
+	if price.GiftCard != nil {
+		if err := giftcards.Redeem(ctx, h.DB, price.GiftCard.Code, price.GiftCard.Amount, orderID); err != nil {
+			if errors.Is(err, giftcards.ErrUnavailable) {
+				return models.Order{}, nil, apperrors.Conflict("The gift card balance changed, review the order and try again")
+			}
+			return models.Order{}, nil, apperrors.Internal("Failed to redeem gift card", err)
 		}
 	}
+	// Gives the gift card back if the order can't be placed after all
+	unredeem := func() {
+		giftcards.Refund(ctx, h.DB, models.Order{ID: orderID, GiftCard: price.GiftCard})
+	}
 
 	// Determine order and payment statuses
-	orderStatus := "pending"  // pending -> processing -> shipped -> delivered/cancelled/returned
-	paymentStatus := "unpaid" // unpaid | paid | refunded | failed
-	switch req.PaymentInfo.Method {
-	case "razorpay":
+	orderStatus := orderstatus.Pending // see the orderstatus package for the lifecycle
+	paymentStatus := "unpaid"          // unpaid | paid | refunded | failed
+	switch {
+	case awaitingPayment:
+		// Stays pending and unpaid until the payment is confirmed
+	case req.PaymentInfo.Method == "razorpay":
 		// Signature already verified above, consider payment successful
 		paymentStatus = "paid"
-		orderStatus = "processing"
-	case "cod":
+		orderStatus = orderstatus.Processing
+	case req.PaymentInfo.Method == "cod":
 		paymentStatus = "unpaid"
-		orderStatus = "processing"
+		orderStatus = orderstatus.Processing
+	case paidByGiftCard:
+		paymentStatus = "paid"
+		orderStatus = orderstatus.Processing
 	}
 
 	// Create the order
 	now := time.Now()
 	order := models.Order{
-		ID:              primitive.NewObjectID(),
+		ID:              orderID,
 		UserID:          user.UserID,
 		Items:           orderItems,
 		Total:           total,
+		Currency:        currency.Code,
+		ExchangeRate:    currency.Rate,
+		ChargedTotal:    chargedTotal,
 		Status:          orderStatus,
 		PaymentStatus:   paymentStatus,
 		ShippingAddress: req.ShippingAddress,
 		PaymentInfo:     req.PaymentInfo,
+		Shipment:        &shipment,
+		Gift:            price.Gift,
+		GiftCard:        price.GiftCard,
 		CreatedAt:       now,
 		UpdatedAt:       now,
 	}
 
+	// COD orders can be held until the customer confirms them
+	var verificationSecret string
+	if req.PaymentInfo.Method == "cod" && h.codVerificationEnabled(ctx, user.UserID) {
+		verificationSecret, err = h.prepareCODVerification(ctx, &order)
+		if err != nil {
+			unredeem()
+			return models.Order{}, nil, apperrors.Internal("Failed to prepare order verification", err)
+		}
+	}
+
+	order.StatusHistory = []models.OrderStatusEvent{orderStatusEvent(c, order.Status, "Order placed")}
+
+	// Take the items out of stock once the order is known to go through
+	stockAfter, err := h.takeStock(ctx, orderItems)
+	if err != nil {
+		unredeem()
+		return models.Order{}, nil, err
+	}
+
 	// Insert the order into the database
 	orderCollection := h.DB.Collections().Orders
 	_, err = orderCollection.InsertOne(ctx, order)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create order",
-			"error":   err.Error(),
-		})
+		h.putBackStock(ctx, orderItems)
+		unredeem()
+		return models.Order{}, nil, apperrors.Internal("Failed to create order", err)
 	}
 
-	// Clear the user's cart
-	_, err = cartCollection.DeleteMany(ctx, bson.M{"user_id": user.UserID})
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to clear cart after order",
-			"error":   err.Error(),
-		})
-	}
+	logSale(ctx, h.DB, order, stockAfter, user.UserID)
+
+	// Clear the ordered lines from the user's cart
+	if cartFilter != nil {
+		_, err = h.DB.Collections().CartItems.DeleteMany(ctx, cartFilter)
+		if err != nil {
+			return models.Order{}, nil, apperrors.Internal("Failed to clear cart after order", err)
+		}
 
-	// Invalidate cart cache
-	cartCacheKey := fmt.Sprintf("cart:%s", user.UserID.Hex())
-	h.DB.CacheDel(ctx, cartCacheKey)
+		// Invalidate cart cache
+		h.DB.CacheDel(ctx, cartCacheKey(ctx, h.DB, user.UserID))
+	}
 
 	// Invalidate order cache
 	ordersCacheKey := fmt.Sprintf("orders:%s", user.UserID.Hex())
 	h.DB.CacheDel(ctx, ordersCacheKey)
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "Order placed successfully",
-		"data":    order,
-	})
+	if verificationSecret != "" {
+		if err := h.deliverCODVerification(ctx, c, order, verificationSecret); err != nil {
+			log.Printf("[COD] Failed to send verification for order %s: %v", order.ID.Hex(), err)
+		}
+	}
+
+	h.Events.Publish(ctx, events.OrderCreated, order)
+
+	return order, payment, nil
+}
+
+// orderItems prices lines at their products' current prices and checks they
+// can be ordered: each product's units across its lines must be in stock,
+// unless it takes pre-orders, and with limits within the user's purchase
+// limits. It returns the order lines and their products for packing.
+func (h *OrderHandler) orderItems(ctx context.Context, userID primitive.ObjectID, lines []models.CartItem, limits bool) ([]models.OrderItem, []models.ShipmentItem, error) {
+	productsCollection := h.DB.Collections().Products
+	orderItems := make([]models.OrderItem, 0, len(lines))
+	shipmentItems := make([]models.ShipmentItem, 0, len(lines))
+	// Units of each product across its sizes, which share its stock
+	perProduct := make(map[primitive.ObjectID]int, len(lines))
+	now := time.Now()
+
+	for _, item := range lines {
+		var product models.Product
+		err := productsCollection.FindOne(ctx, bson.M{"_id": item.ProductID}).Decode(&product)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, nil, apperrors.NotFound(fmt.Sprintf("Product %s not found", item.ProductID.Hex()))
+			}
+			return nil, nil, apperrors.Internal("Failed to retrieve product details", err)
+		}
+
+		// Check if there's enough stock, unless the item is a pre-order
+		perProduct[product.ID] += item.Quantity
+		preorder, err := checkAvailable(&product, perProduct[product.ID], now)
+		if err != nil {
+			return nil, nil, err
+		}
+		if limits {
+			if err := checkPurchaseLimits(ctx, h.DB, h.Config, userID, &product, perProduct[product.ID]); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// Use discounted price if active
+		finalPrice := product.GetFinalPrice()
+		orderItems = append(orderItems, models.OrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       finalPrice,
+			Size:        item.Size,
+			Quantity:    item.Quantity,
+			Subtotal:    finalPrice * float64(item.Quantity),
+			Preorder:    preorder,
+		})
+		shipmentItems = append(shipmentItems, models.ShipmentItem{Product: product, Quantity: item.Quantity})
+	}
+	return orderItems, shipmentItems, nil
+}
+
+// takeStock takes an order's units out of stock and returns each product's
+// stock afterwards. Pre-ordered units are counted apart until they are
+// allocated. On failure the units already taken are put back.
+func (h *OrderHandler) takeStock(ctx context.Context, items []models.OrderItem) (map[primitive.ObjectID]int, error) {
+	productsCollection := h.DB.Collections().Products
+	stockAfter := make(map[primitive.ObjectID]int, len(items))
+	for i, item := range items {
+		inc := bson.M{"stock": -item.Quantity}
+		if item.Preorder {
+			inc = bson.M{"preorder_count": item.Quantity}
+		}
+		var updated models.Product
+		err := productsCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": inc},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"stock": 1}),
+		).Decode(&updated)
+		if err != nil {
+			h.putBackStock(ctx, items[:i])
+			return nil, apperrors.Internal("Failed to update product stock", err)
+		}
+		stockAfter[item.ProductID] = updated.Stock
+
+		// Invalidate product cache (stock also affects listings)
+		h.DB.InvalidateProductCaches(ctx, item.ProductID.Hex())
+	}
+	return stockAfter, nil
+}
+
+// putBackStock undoes takeStock for an order that couldn't be placed after
+// all. Its sale was never recorded in the stock ledger, so neither is this.
+func (h *OrderHandler) putBackStock(ctx context.Context, items []models.OrderItem) {
+	for _, item := range items {
+		inc := bson.M{"stock": item.Quantity}
+		if item.Preorder {
+			inc = bson.M{"preorder_count": -item.Quantity}
+		}
+		if _, err := h.DB.Collections().Products.UpdateOne(ctx, bson.M{"_id": item.ProductID}, bson.M{"$inc": inc}); err != nil {
+			log.Printf("[ORDERS] Failed to put back stock of product %s: %v", item.ProductID.Hex(), err)
+		}
+		h.DB.InvalidateProductCaches(ctx, item.ProductID.Hex())
+	}
+}
+
+// logSale records the units an order took from stock in the stock ledger
+func logSale(ctx context.Context, db *database.DBClient, order models.Order, stockAfter map[primitive.ObjectID]int, actorID primitive.ObjectID) {
+	for _, item := range order.Items {
+		if item.Preorder {
+			continue
+		}
+		logStockMovement(ctx, db, models.StockMovement{
+			ProductID:  item.ProductID,
+			Delta:      -item.Quantity,
+			StockAfter: stockAfter[item.ProductID],
+			Reason:     models.StockReasonSale,
+			OrderID:    &order.ID,
+			ActorID:    actorID,
+		})
+	}
+}
+
+// cartSelection returns the filter matching the user's cart lines to check
+// out, and how many distinct lines were selected: the lines in itemIDs, or
+// the whole cart (0 selected) when itemIDs is empty
+func cartSelection(userID primitive.ObjectID, itemIDs []string) (bson.M, int, error) {
+	filter := bson.M{"user_id": userID}
+	if len(itemIDs) == 0 {
+		return filter, 0, nil
+	}
+	seen := make(map[primitive.ObjectID]bool, len(itemIDs))
+	ids := make([]primitive.ObjectID, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		objectID, err := parseObjectID(id)
+		if err != nil {
+			return nil, 0, apperrors.BadRequest("Invalid cart item ID", err)
+		}
+		if !seen[objectID] {
+			seen[objectID] = true
+			ids = append(ids, objectID)
+		}
+	}
+	filter["_id"] = bson.M{"$in": ids}
+	return filter, len(ids), nil
+}
+
+// checkRazorpayOrder makes sure an order placed before it is paid names a
+// Razorpay order created for this user and for the amount checkout computed,
+// so paying that Razorpay order settles exactly this order
+func (h *OrderHandler) checkRazorpayOrder(ctx context.Context, keys config.RazorpayKeys, userID primitive.ObjectID, razorpayOrderID string, currency models.Currency, chargedTotal float64) error {
+	rzOrder, err := razorpay.New(keys.Key, keys.Secret).Order(ctx, razorpayOrderID)
+	if err != nil {
+		return apperrors.BadGateway("Failed to verify payment order", err)
+	}
+	if rzOrder.Note("user_id") != userID.Hex() {
+		return apperrors.BadRequest("Payment order belongs to another checkout", nil)
+	}
+	if rzOrder.Currency != currency.Code || rzOrder.Amount != currency.MinorUnits(chargedTotal) {
+		return apperrors.BadRequest("Payment order amount doesn't match the cart; create a new one", nil)
+	}
+	return nil
+}
+
+// checkOrderTransition rejects status changes the order lifecycle doesn't allow
+func checkOrderTransition(from, to string) error {
+	if orderstatus.CanTransition(from, to) {
+		return nil
+	}
+	next := orderstatus.Next(from)
+	if len(next) == 0 {
+		return apperrors.Conflict(fmt.Sprintf("A %s order can no longer change status", from))
+	}
+	return apperrors.Conflict(fmt.Sprintf("A %s order cannot be marked %s; it can move to: %s", from, to, strings.Join(next, ", ")))
+}
+
+// orderStatusEvent records a status change made by the current user
+func orderStatusEvent(c *fiber.Ctx, status, note string) models.OrderStatusEvent {
+	event := models.OrderStatusEvent{Status: status, Actor: models.OrderActorCustomer, Note: note, Timestamp: time.Now()}
+	if user, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		event.ActorID = user.UserID
+		if middleware.IsStaff(user.Role) {
+			event.Actor = models.OrderActorAdmin
+		}
+	}
+	return event
+}
+
+// statusChange describes a recorded status event for event subscribers
+func statusChange(order models.Order, from string, event models.OrderStatusEvent) events.OrderStatusChange {
+	return events.OrderStatusChange{
+		OrderID: order.ID.Hex(),
+		UserID:  order.UserID.Hex(),
+		From:    from,
+		To:      event.Status,
+		Actor:   event.Actor,
+		Note:    event.Note,
+	}
 }
 
 // GetOrders retrieves order history for a user
@@ -259,10 +623,7 @@ func (h *OrderHandler) GetOrders(c *fiber.Ctx) error {
 	// Determine the target user ID from route params or the authenticated token
 	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	userIDParam := c.Params("userID")
@@ -275,20 +636,13 @@ func (h *OrderHandler) GetOrders(c *fiber.Ctx) error {
 		// Convert user ID from string to ObjectID
 		userID, err = primitive.ObjectIDFromHex(userIDParam)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid user ID format",
-				"error":   err.Error(),
-			})
+			return apperrors.BadRequest("Invalid user ID format", err)
 		}
 	}
 
-	// Authorization: user can view own orders; admin can view any user's orders
-	if tokenUser.UserID != userID && tokenUser.Role != "admin" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Not authorized to view these orders",
-		})
+	// Authorization: user can view own orders; staff with orders:read can view any user's orders
+	if tokenUser.UserID != userID && !hasPermission(c, h.DB, models.PermissionOrdersRead) {
+		return apperrors.Forbidden("Not authorized to view these orders")
 	}
 
 	// Check if the orders are in Redis cache
@@ -309,21 +663,13 @@ func (h *OrderHandler) GetOrders(c *fiber.Ctx) error {
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	cursor, err := orderCollection.Find(ctx, bson.M{"user_id": userID}, opts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve orders",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve orders", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Parse the results
 	if err := cursor.All(ctx, &orders); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode orders",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode orders", err)
 	}
 
 	// Map orders to convert ObjectID to hex string for frontend
@@ -345,7 +691,7 @@ func (h *OrderHandler) GetOrders(c *fiber.Ctx) error {
 		if payStatus == "" {
 			if o.Status == "paid" || o.PaymentInfo.RazorpayPaymentID != "" {
 				payStatus = "paid"
-			} else if o.Status == "cancelled" {
+			} else if o.Status == orderstatus.Cancelled {
 				payStatus = "refunded"
 			} else {
 				payStatus = "unpaid"
@@ -383,20 +729,13 @@ func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 	// Get order ID from URL parameter
 	orderIDParam := c.Params("orderID")
 	if orderIDParam == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Order ID is required",
-		})
+		return apperrors.BadRequest("Order ID is required", nil)
 	}
 
 	// Convert order ID from string to ObjectID
 	orderID, err := primitive.ObjectIDFromHex(orderIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid order ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid order ID format", err)
 	}
 
 	// Check if the order is in Redis cache
@@ -407,11 +746,8 @@ func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 		// Cache hit
 		// Check if the user is authorized to view this order
 		tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-		if !ok || (order.UserID != tokenUser.UserID && tokenUser.Role != "admin") {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"success": false,
-				"message": "Not authorized to view this order",
-			})
+		if !ok || (order.UserID != tokenUser.UserID && !hasPermission(c, h.DB, models.PermissionOrdersRead)) {
+			return apperrors.Forbidden("Not authorized to view this order")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -426,25 +762,15 @@ func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 	err = orderCollection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Order not found",
-			})
+			return apperrors.NotFound("Order not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve order",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve order", err)
 	}
 
 	// Check if the user is authorized to view this order
 	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-	if !ok || (order.UserID != tokenUser.UserID && tokenUser.Role != "admin") {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Not authorized to view this order",
-		})
+	if !ok || (order.UserID != tokenUser.UserID && !hasPermission(c, h.DB, models.PermissionOrdersRead)) {
+		return apperrors.Forbidden("Not authorized to view this order")
 	}
 
 	// Cache the order (expire after 15 minutes)
@@ -458,122 +784,135 @@ func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateOrderStatus updates the status of an order (admin only)
-func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
-	ctx := c.Context()
+// orderStatusUpdate is an admin change of an order's status and, optionally,
+// its payment status
+type orderStatusUpdate struct {
+	Status        string `json:"status"`
+	PaymentStatus string `json:"paymentStatus,omitempty"`
+	Note          string `json:"note,omitempty"`
+}
 
-	// Only admin can update order status
-	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-	if !ok || tokenUser.Role != "admin" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Only admins can update order status",
-		})
+// validate checks that the statuses exist; whether the order may move to
+// them is checked per order
+func (u orderStatusUpdate) validate() error {
+	if !orderstatus.Valid(u.Status) {
+		return apperrors.BadRequest("Invalid order status. Must be one of: "+strings.Join(orderstatus.All, ", "), nil)
+	}
+
+	validPaymentStatuses := map[string]bool{
+		"unpaid":   true,
+		"paid":     true,
+		"failed":   true,
+		"refunded": true,
+	}
+	if u.PaymentStatus != "" && !validPaymentStatuses[u.PaymentStatus] {
+		return apperrors.BadRequest("Invalid payment status. Must be one of: unpaid, paid, failed, refunded", nil)
+	}
+	return nil
+}
+
+// UpdateOrderStatus updates the status of an order (staff with orders:write)
+func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	// Only staff with orders:write can update order status
+	if !hasPermission(c, h.DB, models.PermissionOrdersWrite) {
+		return apperrors.Forbidden("Not authorized to update order status")
 	}
 
 	// Get order ID from URL parameter
 	orderIDParam := c.Params("orderID")
 	if orderIDParam == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Order ID is required",
-		})
+		return apperrors.BadRequest("Order ID is required", nil)
 	}
 
 	// Convert order ID from string to ObjectID
 	orderID, err := primitive.ObjectIDFromHex(orderIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid order ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid order ID format", err)
 	}
 
 	// Parse request body
-	type StatusUpdate struct {
-		Status        string `json:"status"`
-		PaymentStatus string `json:"paymentStatus,omitempty"`
-	}
-	var req StatusUpdate
+	var req orderStatusUpdate
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
-
-	// Validate statuses
-	validStatuses := map[string]bool{
-		"pending":    true,
-		"processing": true,
-		"shipped":    true,
-		"delivered":  true,
-		"cancelled":  true,
-		"returned":   true,
+	if err := req.validate(); err != nil {
+		return err
 	}
 
-	if !validStatuses[req.Status] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid order status. Must be one of: pending, processing, shipped, delivered, cancelled, returned",
-		})
+	updatedOrder, err := h.applyStatusUpdate(c, orderID, req)
+	if err != nil {
+		return err
 	}
 
-	validPaymentStatuses := map[string]bool{
-		"unpaid":   true,
-		"paid":     true,
-		"failed":   true,
-		"refunded": true,
-	}
-	if req.PaymentStatus != "" && !validPaymentStatuses[req.PaymentStatus] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid payment status. Must be one of: unpaid, paid, failed, refunded",
-		})
+	// Return the updated order
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Order status updated successfully",
+		"data":    updatedOrder,
+	})
+}
+
+// applyStatusUpdate moves one order to the requested status if its
+// lifecycle allows it, then notifies subscribers and records the change.
+// req must already be validated.
+func (h *OrderHandler) applyStatusUpdate(c *fiber.Ctx, orderID primitive.ObjectID, req orderStatusUpdate) (models.Order, error) {
+	ctx := c.Context()
+
+	// Capture the previous state for the audit trail and the transition check
+	orderCollection := h.DB.Collections().Orders
+	var previousOrder models.Order
+	if err := orderCollection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&previousOrder); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return previousOrder, apperrors.NotFound("Order not found")
+		}
+		return previousOrder, apperrors.Internal("Failed to retrieve order", err)
 	}
 
-	// Update the order status
+	// Update the order status. Resending the current status only updates
+	// the payment status.
 	now := time.Now()
-	orderCollection := h.DB.Collections().Orders
 	setFields := bson.M{
-		"status":     req.Status,
 		"updated_at": now,
 	}
+	update := bson.M{"$set": setFields}
 	if req.PaymentStatus != "" {
 		setFields["payment_status"] = req.PaymentStatus
 	}
-	result, err := orderCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": orderID},
-		bson.M{"$set": setFields},
-	)
-
+	var statusEvent models.OrderStatusEvent
+	if req.Status != previousOrder.Status {
+		if err := checkOrderTransition(previousOrder.Status, req.Status); err != nil {
+			return previousOrder, err
+		}
+		if req.Status == orderstatus.Shipped && hasPreorders(previousOrder) {
+			return previousOrder, apperrors.Conflict("The order has pre-ordered items; allocate them from stock before shipping it")
+		}
+		statusEvent = orderStatusEvent(c, req.Status, req.Note)
+		setFields["status"] = req.Status
+		update["$push"] = bson.M{"status_history": statusEvent}
+		if req.Status == orderstatus.Delivered {
+			// Starts the return window
+			setFields["delivered_at"] = now
+		}
+	}
+	res, err := orderCollection.UpdateOne(ctx, bson.M{"_id": orderID, "status": previousOrder.Status}, update)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update order status",
-			"error":   err.Error(),
-		})
+		return previousOrder, apperrors.Internal("Failed to update order status", err)
+	}
+	if res.MatchedCount == 0 {
+		return previousOrder, apperrors.Conflict("The order was changed by someone else, reload and try again")
 	}
 
-	if result.MatchedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Order not found",
-		})
+	// Staff cancellations give back the gift card and stock like the
+	// customer's own
+	if statusEvent.Status == orderstatus.Cancelled {
+		h.releaseCancelled(ctx, previousOrder, statusEvent.ActorID)
 	}
 
 	// Get the updated order
 	var updatedOrder models.Order
 	err = orderCollection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&updatedOrder)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve updated order",
-			"error":   err.Error(),
-		})
+		return previousOrder, apperrors.Internal("Failed to retrieve updated order", err)
 	}
 
 	// Invalidate order caches
@@ -582,35 +921,38 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 	h.DB.CacheDel(ctx, orderCacheKey)
 	h.DB.CacheDel(ctx, userOrdersCacheKey)
 
-	// Return the updated order
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Order status updated successfully",
-		"data":    updatedOrder,
-	})
+	if statusEvent.Status != "" {
+		h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(updatedOrder, previousOrder.Status, statusEvent))
+	}
+
+	recordAudit(c, h.DB.MongoDB, "order.status_change", "order", orderID.Hex(),
+		bson.M{"status": previousOrder.Status, "payment_status": previousOrder.PaymentStatus},
+		bson.M{"status": updatedOrder.Status, "payment_status": updatedOrder.PaymentStatus})
+
+	return updatedOrder, nil
+}
+
+// releaseCancelled gives back what a cancelled order held: the gift card
+// balance it redeemed and its units in stock
+func (h *OrderHandler) releaseCancelled(ctx context.Context, order models.Order, actorID primitive.ObjectID) {
+	giftcards.Refund(ctx, h.DB, order)
+	h.restock(ctx, order, order.Items, actorID)
 }
 
-// CancelOrder cancels an order if it's still in "pending" or "processing" status
+// CancelOrder cancels an order that hasn't shipped yet
 func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
 	ctx := c.Context()
 
 	// Get order ID from URL parameter
 	orderIDParam := c.Params("orderID")
 	if orderIDParam == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Order ID is required",
-		})
+		return apperrors.BadRequest("Order ID is required", nil)
 	}
 
 	// Convert order ID from string to ObjectID
 	orderID, err := primitive.ObjectIDFromHex(orderIDParam)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid order ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid order ID format", err)
 	}
 
 	// Get the order
@@ -619,83 +961,57 @@ func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
 	err = orderCollection.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Order not found",
-			})
+			return apperrors.NotFound("Order not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve order",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve order", err)
 	}
 
 	// Check if the user is authorized to cancel this order
 	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-	if !ok || (order.UserID != tokenUser.UserID && tokenUser.Role != "admin") {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Not authorized to cancel this order",
-		})
+	if !ok || (order.UserID != tokenUser.UserID && !hasPermission(c, h.DB, models.PermissionOrdersWrite)) {
+		return apperrors.Forbidden("Not authorized to cancel this order")
 	}
 
 	// Check if the order can be cancelled
-	if order.Status != "pending" && order.Status != "processing" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Only pending or processing orders can be cancelled",
-		})
+	if !orderstatus.CanCancel(order.Status) {
+		return checkOrderTransition(order.Status, orderstatus.Cancelled)
 	}
 
 	// Update the order status to "cancelled" and set paymentStatus if prepaid
 	now := time.Now()
 	setCancel := bson.M{
-		"status":     "cancelled",
+		"status":     orderstatus.Cancelled,
 		"updated_at": now,
 	}
 	if order.PaymentStatus == "paid" {
 		// Business rule: mark as refunded; real refund should be processed via gateway
 		setCancel["payment_status"] = "refunded"
 	}
-	_, err = orderCollection.UpdateOne(
+	// The status filter stops two cancellations from both restoring stock
+	cancelEvent := orderStatusEvent(c, orderstatus.Cancelled, "")
+	res, err := orderCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": orderID},
-		bson.M{"$set": setCancel},
+		bson.M{"_id": orderID, "status": order.Status},
+		bson.M{"$set": setCancel, "$push": bson.M{"status_history": cancelEvent}},
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to cancel order",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to cancel order", err)
 	}
-
-	// Return inventory to stock
-	productsCollection := h.DB.Collections().Products
-	for _, item := range order.Items {
-		_, err = productsCollection.UpdateOne(
-			ctx,
-			bson.M{"_id": item.ProductID},
-			bson.M{"$inc": bson.M{"stock": item.Quantity}},
-		)
-		if err != nil {
-			// Log error but continue processing
-			fmt.Printf("Error restoring inventory for product %s: %v\n", item.ProductID.Hex(), err)
-		}
-
-		// Invalidate product cache
-		productCacheKey := fmt.Sprintf("product:%s", item.ProductID.Hex())
-		h.DB.CacheDel(ctx, productCacheKey)
+	if res.MatchedCount == 0 {
+		return apperrors.Conflict("The order was changed by someone else, reload and try again")
 	}
 
+	h.releaseCancelled(ctx, order, tokenUser.UserID)
+
 	// Invalidate order caches
 	orderCacheKey := fmt.Sprintf("order:%s", orderID.Hex())
 	userOrdersCacheKey := fmt.Sprintf("orders:%s", order.UserID.Hex())
 	h.DB.CacheDel(ctx, orderCacheKey)
 	h.DB.CacheDel(ctx, userOrdersCacheKey)
 
+	h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(order, order.Status, cancelEvent))
+
 	// Return success response
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -703,35 +1019,52 @@ func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
 	})
 }
 
-// GetAllOrders returns all orders (admin only)
+// GetAllOrders returns all orders (staff with orders:read)
 func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
 	ctx := c.Context()
-	// Only admin can access
-	tokenUser, ok := c.Locals("user").(*middleware.TokenMetadata)
-	if !ok || tokenUser.Role != "admin" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"success": false,
-			"message": "Not authorized",
-		})
+	// Only staff with orders:read can access
+	if !hasPermission(c, h.DB, models.PermissionOrdersRead) {
+		return apperrors.Forbidden("Not authorized")
 	}
+	// Without after every order is returned; with it, pages of limit orders
+	paging, err := parseCursorPage(c, "created_at", -1)
+	if err != nil {
+		return err
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
 	orderCollection := h.DB.Collections().Orders
+	filter := bson.M{}
+	if mode := c.Query("paymentMode"); mode != "" {
+		if mode != models.PaymentModeLive && mode != models.PaymentModeTest {
+			return apperrors.BadRequest("paymentMode must be live or test", nil)
+		}
+		filter["payment_info.mode"] = paymentModeFilter(mode)
+	}
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	cursor, err := orderCollection.Find(ctx, bson.M{}, opts)
+	if paging != nil {
+		filter = paging.apply(filter, opts, limit)
+	}
+	cursor, err := orderCollection.Find(ctx, filter, opts)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve orders",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve orders", err)
 	}
 	defer cursor.Close(ctx)
 	var orders []models.Order
 	if err := cursor.All(ctx, &orders); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode orders",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode orders", err)
+	}
+	var meta fiber.Map
+	if paging != nil {
+		n, nextCursor, err := paging.next(len(orders), limit, func(i int) interface{} { return orders[i] })
+		if err != nil {
+			return apperrors.Internal("Failed to build next cursor", err)
+		}
+		orders = orders[:n]
+		meta = fiber.Map{"limit": limit, "nextCursor": nextCursor}
 	}
 	// Map orders to frontend format if needed
 	type OrderResponse struct {
@@ -756,7 +1089,7 @@ func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
 		if payStatus == "" {
 			if o.Status == "paid" || o.PaymentInfo.RazorpayPaymentID != "" {
 				payStatus = "paid"
-			} else if o.Status == "cancelled" {
+			} else if o.Status == orderstatus.Cancelled {
 				payStatus = "refunded"
 			} else {
 				payStatus = "unpaid"
@@ -788,9 +1121,36 @@ func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
 			UpdatedAt:       o.UpdatedAt,
 		})
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	resp := fiber.Map{
 		"success": true,
 		"message": "All orders retrieved",
 		"data":    respOrders,
-	})
+	}
+	if meta != nil {
+		resp["meta"] = meta
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// requireVerifiedAccount rejects orders from accounts whose email hasn't been
+// verified when REQUIRE_VERIFIED_EMAIL is enabled. Phone-only accounts have no
+// email to verify, so their verified phone number stands in for it.
+func requireVerifiedAccount(ctx context.Context, db *database.DBClient, cfg *config.Config, userID primitive.ObjectID) error {
+	if cfg == nil || !cfg.RequireVerifiedEmail {
+		return nil
+	}
+
+	var account models.User
+	opts := options.FindOne().SetProjection(bson.M{"email": 1, "email_verified": 1, "phone_verified": 1})
+	if err := db.Collections().Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&account); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("User not found")
+		}
+		return apperrors.Internal("Failed to retrieve user data", err)
+	}
+
+	if account.EmailVerified || (account.Email == "" && account.PhoneVerified) {
+		return nil
+	}
+	return apperrors.Forbidden("Please verify your email address before placing an order").WithCode(apperrors.CodeEmailUnverified)
 }
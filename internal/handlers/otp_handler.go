@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// OTP limits. Codes live in the cache (Redis when available) and are stored
+// hashed, so a cache dump doesn't reveal live codes.
+const (
+	otpTTL            = 5 * time.Minute
+	otpResendCooldown = time.Minute
+	otpMaxAttempts    = 5  // wrong guesses before the code is burned
+	otpMaxSendsPerDay = 10 // codes sent to one number per 24h
+)
+
+func otpCodeKey(phone string) string     { return "otp:code:" + phone }
+func otpAttemptsKey(phone string) string { return "otp:attempts:" + phone }
+func otpCooldownKey(phone string) string { return "otp:cooldown:" + phone }
+func otpSendsKey(phone string) string    { return "otp:sends:" + phone }
+
+// hashOTP binds a code to its phone number and the server secret
+func (h *AuthHandler) hashOTP(phone, code string) string {
+	mac := hmac.New(sha256.New, []byte(h.Config.JWTSecret))
+	mac.Write([]byte(phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RequestOTP sends a one-time login code to a phone number
+func (h *AuthHandler) RequestOTP(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.OTPRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	phone, ok := models.NormalizePhone(req.Phone)
+	if !ok {
+		return apperrors.Validation("Validation failed", []validation.FieldError{
+			{Field: "phone", Rule: "phone", Message: "phone must be a valid mobile number"},
+		})
+	}
+
+	if h.DB.Cache == nil {
+		return apperrors.Unavailable("Phone login is temporarily unavailable", nil)
+	}
+
+	if _, err := h.DB.Cache.Get(ctx, otpCooldownKey(phone)); err == nil {
+		return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Please wait before requesting another code")
+	}
+
+	sends, err := h.DB.Cache.Incr(ctx, otpSendsKey(phone))
+	if err != nil {
+		return apperrors.Internal("Failed to send code", err)
+	}
+	if sends == 1 {
+		// First send of the window: give the counter its expiry
+		_ = h.DB.Cache.Set(ctx, otpSendsKey(phone), []byte("1"), 24*time.Hour)
+	}
+	if sends > otpMaxSendsPerDay {
+		return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many codes requested for this number, try again later")
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return apperrors.Internal("Failed to generate code", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	if err := h.DB.Cache.Set(ctx, otpCodeKey(phone), []byte(h.hashOTP(phone, code)), otpTTL); err != nil {
+		return apperrors.Internal("Failed to send code", err)
+	}
+	_ = h.DB.Cache.Set(ctx, otpAttemptsKey(phone), []byte("0"), otpTTL)
+	_ = h.DB.Cache.Set(ctx, otpCooldownKey(phone), []byte("1"), otpResendCooldown)
+
+	if err := h.SMS.SendOTP(ctx, phone, code); err != nil {
+		log.Printf("[OTP] %s failed to deliver code to %s: %v", h.SMS.Name(), phone, err)
+		_ = h.DB.Cache.Del(ctx, otpCodeKey(phone), otpCooldownKey(phone))
+		return apperrors.BadGateway("Failed to send code", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Verification code sent",
+		"data": fiber.Map{
+			"phone":     phone,
+			"expiresIn": int(otpTTL.Seconds()),
+		},
+	})
+}
+
+// checkOTP validates a code, counting failed attempts. The code is consumed on success.
+func (h *AuthHandler) checkOTP(ctx context.Context, phone, code string) error {
+	if h.DB.Cache == nil {
+		return apperrors.Unavailable("Phone login is temporarily unavailable", nil)
+	}
+
+	stored, err := h.DB.Cache.Get(ctx, otpCodeKey(phone))
+	if err != nil {
+		return apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	attempts, err := h.DB.Cache.Incr(ctx, otpAttemptsKey(phone))
+	if err != nil {
+		return apperrors.Internal("Failed to verify code", err)
+	}
+	if attempts > otpMaxAttempts {
+		_ = h.DB.Cache.Del(ctx, otpCodeKey(phone), otpAttemptsKey(phone))
+		return apperrors.New(fiber.StatusTooManyRequests, apperrors.CodeRateLimited, "Too many incorrect attempts, request a new code")
+	}
+
+	if !hmac.Equal(stored, []byte(h.hashOTP(phone, code))) {
+		return apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	_ = h.DB.Cache.Del(ctx, otpCodeKey(phone), otpAttemptsKey(phone))
+	return nil
+}
+
+// VerifyOTP exchanges a valid code for a session, creating a phone-only
+// account the first time a number is seen
+func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.OTPVerifyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	phone, ok := models.NormalizePhone(req.Phone)
+	if !ok {
+		return apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	if err := h.checkOTP(ctx, phone, req.Code); err != nil {
+		return err
+	}
+
+	collection := h.DB.Collections().Users
+	var user models.User
+	status := fiber.StatusOK
+	message := "Login successful"
+
+	err := collection.FindOne(ctx, bson.M{"phone": phone}).Decode(&user)
+	switch {
+	case err == mongo.ErrNoDocuments:
+		now := time.Now()
+		user = models.User{
+			ID:            primitive.NewObjectID(),
+			Name:          req.Name,
+			Phone:         phone,
+			PhoneVerified: true,
+			Role:          "user",
+			AuthProvider:  "phone",
+			Status:        models.UserStatusActive,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if _, err := collection.InsertOne(ctx, user); err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				return apperrors.Internal("Failed to create user", err)
+			}
+			// A concurrent verification created the account first
+			if err := collection.FindOne(ctx, bson.M{"phone": phone}).Decode(&user); err != nil {
+				return apperrors.Internal("Failed to retrieve user", err)
+			}
+		} else {
+			status = fiber.StatusCreated
+			message = "User registered successfully"
+		}
+	case err != nil:
+		return apperrors.Internal("Database error", err)
+	}
+
+	if user.IsSuspended() {
+		return apperrors.Forbidden("Account has been suspended").WithCode(apperrors.CodeAccountSuspended)
+	}
+
+	if !user.PhoneVerified {
+		user.PhoneVerified = true
+		_, _ = collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"phone_verified": true, "updated_at": time.Now()}})
+	}
+
+	token, err := h.generateToken(user.ID.Hex(), user.Role, user.TokenVersion)
+	if err != nil {
+		return apperrors.Internal("Failed to generate token", err)
+	}
+	refreshToken, err := h.generateRefreshToken(user.ID.Hex(), user.TokenVersion)
+	if err != nil {
+		return apperrors.Internal("Failed to generate refresh token", err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Expires:  time.Now().Add(7 * 24 * time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+	})
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": models.LoginResponse{
+			User: models.UserResponse{
+				ID:            user.ID,
+				Name:          user.Name,
+				Email:         user.Email,
+				EmailVerified: user.EmailVerified,
+				Role:          user.Role,
+				Picture:       user.Picture,
+				Phone:         user.Phone,
+				AuthProvider:  user.AuthProvider,
+			},
+			Token: token,
+		},
+	})
+}
+
+// LinkPhone verifies a code and attaches the phone number to the current account
+func (h *AuthHandler) LinkPhone(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	var req models.OTPVerifyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	phone, ok := models.NormalizePhone(req.Phone)
+	if !ok {
+		return apperrors.BadRequest("Code is invalid or has expired", nil).WithCode(apperrors.CodeOTPInvalid)
+	}
+
+	if err := h.checkOTP(ctx, phone, req.Code); err != nil {
+		return err
+	}
+
+	res, err := h.DB.Collections().Users.UpdateOne(ctx,
+		bson.M{"_id": user.UserID},
+		bson.M{"$set": bson.M{"phone": phone, "phone_verified": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("Phone number is already linked to another account").WithCode(apperrors.CodePhoneTaken)
+		}
+		return apperrors.Internal("Failed to link phone number", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound("User not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Phone number verified",
+		"data": fiber.Map{
+			"phone":         phone,
+			"phoneVerified": true,
+		},
+	})
+}
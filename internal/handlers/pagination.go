@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+)
+
+// cursorPage is the opt-in alternative to page/limit for large listings.
+// Passing after (empty for the first page) switches a listing to keyset
+// pagination: each page continues from the sort value and _id of the
+// previous page's last item, so deep pages cost the same as the first one.
+// The response meta carries nextCursor, which is empty on the last page.
+type cursorPage struct {
+	Field string // bson field the listing is sorted by
+	Dir   int    // 1 ascending, -1 descending
+	after *pageCursor
+}
+
+// pageCursor is the position encoded in an after/nextCursor value
+type pageCursor struct {
+	Sort  string             `bson:"s"` // field and direction the cursor was issued for
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+func (p *cursorPage) sortKey() string {
+	return p.Field + ":" + strconv.Itoa(p.Dir)
+}
+
+// parseCursorPage returns the cursor pagination requested by the after
+// query param, or nil when the listing should use page/limit
+func parseCursorPage(c *fiber.Ctx, field string, dir int) (*cursorPage, error) {
+	if !c.Context().QueryArgs().Has("after") {
+		return nil, nil
+	}
+	p := &cursorPage{Field: field, Dir: dir}
+	raw := c.Query("after")
+	if raw == "" {
+		return p, nil
+	}
+
+	invalid := apperrors.BadRequest("Invalid cursor", errors.New("after must be a nextCursor returned by this listing with the same sort"))
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, invalid
+	}
+	var cur pageCursor
+	if err := bson.Unmarshal(data, &cur); err != nil || cur.Sort != p.sortKey() || cur.ID.IsZero() {
+		return nil, invalid
+	}
+	p.after = &cur
+	return p, nil
+}
+
+// apply restricts filter to the items after the cursor and sorts and limits
+// opts to match. It asks for one item more than limit so next can tell
+// whether another page follows.
+func (p *cursorPage) apply(filter bson.M, opts *options.FindOptions, limit int) bson.M {
+	opts.SetSort(bson.D{{Key: p.Field, Value: p.Dir}, {Key: "_id", Value: p.Dir}})
+	opts.SetLimit(int64(limit) + 1)
+	if p.after == nil {
+		return filter
+	}
+
+	op := "$gt"
+	if p.Dir < 0 {
+		op = "$lt"
+	}
+	var after bson.M
+	if p.Field == "_id" {
+		after = bson.M{"_id": bson.M{op: p.after.ID}}
+	} else {
+		// Items sharing the cursor's sort value are ordered by _id
+		after = bson.M{"$or": bson.A{
+			bson.M{p.Field: bson.M{op: p.after.Value}},
+			bson.M{p.Field: p.after.Value, "_id": bson.M{op: p.after.ID}},
+		}}
+	}
+	if len(filter) == 0 {
+		return after
+	}
+	return bson.M{"$and": bson.A{filter, after}}
+}
+
+// next trims the extra item apply asked for and returns how many items to
+// keep and the cursor of the following page. last returns the item at an
+// index, which must marshal to BSON with its _id and sort field.
+func (p *cursorPage) next(count, limit int, last func(i int) interface{}) (int, string, error) {
+	if count <= limit {
+		return count, "", nil
+	}
+
+	data, err := bson.Marshal(last(limit - 1))
+	if err != nil {
+		return 0, "", err
+	}
+	doc := bson.Raw(data)
+	id, ok := doc.Lookup("_id").ObjectIDOK()
+	if !ok {
+		return 0, "", errors.New("item has no ObjectID _id")
+	}
+	cur := pageCursor{Sort: p.sortKey(), ID: id, Value: doc.Lookup(p.Field)}
+	if cur.Value.Type == 0 {
+		// Missing fields sort as null
+		cur.Value = bson.RawValue{Type: bsontype.Null}
+	}
+	encoded, err := bson.Marshal(cur)
+	if err != nil {
+		return 0, "", err
+	}
+	return limit, base64.RawURLEncoding.EncodeToString(encoded), nil
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const (
+	// passwordResetPurpose keeps reset tokens from being accepted anywhere else
+	passwordResetPurpose = "password_reset"
+	passwordResetTTL     = time.Hour
+)
+
+// passwordFingerprint identifies the password a reset token was issued
+// against, so a token stops working once the password has changed
+func passwordFingerprint(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// generatePasswordResetToken signs a reset token bound to the user and their current password
+func (h *AuthHandler) generatePasswordResetToken(user models.User) (string, error) {
+	return h.Config.JWTKeys.Sign(jwt.MapClaims{
+		"sub":     user.ID.Hex(),
+		"pwd":     passwordFingerprint(user.Password),
+		"purpose": passwordResetPurpose,
+		"exp":     time.Now().Add(passwordResetTTL).Unix(),
+	})
+}
+
+// parsePasswordResetToken returns the user ID and password fingerprint a token was issued for
+func (h *AuthHandler) parsePasswordResetToken(raw string) (primitive.ObjectID, string, error) {
+	claims, err := h.Config.JWTKeys.Parse(raw)
+	if err != nil {
+		return primitive.NilObjectID, "", errors.New("invalid or expired token")
+	}
+	if claims["purpose"] != passwordResetPurpose {
+		return primitive.NilObjectID, "", errors.New("invalid token")
+	}
+	sub, _ := claims["sub"].(string)
+	fingerprint, _ := claims["pwd"].(string)
+	userID, err := primitive.ObjectIDFromHex(sub)
+	if err != nil || fingerprint == "" {
+		return primitive.NilObjectID, "", errors.New("invalid token")
+	}
+	return userID, fingerprint, nil
+}
+
+// passwordResetEmail builds the message carrying a link to the storefront's reset page
+func (h *AuthHandler) passwordResetEmail(user models.User) (mailer.Message, error) {
+	token, err := h.generatePasswordResetToken(user)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	link := h.Config.FrontendURL + "/auth/reset-password?token=" + url.QueryEscape(token)
+	name := user.Name
+	if name == "" {
+		name = "there"
+	}
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset the password for your MAK Watches account. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %d minutes. If you didn't ask for this, you can ignore this email; your password won't change.\n",
+			name, link, int(passwordResetTTL.Minutes())),
+	}, nil
+}
+
+// sendPasswordResetEmailAsync queues the reset email, or sends it from a
+// background goroutine without a queue, so the response time doesn't reveal
+// whether the account exists
+func (h *AuthHandler) sendPasswordResetEmailAsync(user models.User) {
+	msg, err := h.passwordResetEmail(user)
+	if err != nil {
+		log.Printf("[MAIL] Failed to build password reset email for user %s: %v", user.ID.Hex(), err)
+		return
+	}
+	if h.Jobs != nil {
+		if _, err := h.Jobs.Enqueue(context.Background(), jobs.TypeSendEmail, msg); err != nil {
+			log.Printf("[MAIL] Failed to queue password reset email for user %s: %v", user.ID.Hex(), err)
+		}
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := h.Mailer.Send(ctx, msg); err != nil {
+			log.Printf("[MAIL] Failed to send password reset email to user %s via %s: %v", user.ID.Hex(), h.Mailer.Name(), err)
+		}
+	}()
+}
+
+// ForgotPassword emails a password reset link. It answers the same way
+// whether or not the email belongs to an account.
+// POST /auth/password/forgot
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperrors.BadRequest("Invalid request body", err)
+	}
+	req.Email = models.NormalizeEmail(req.Email)
+	if err := validateRequest(&req); err != nil {
+		return err
+	}
+
+	respond := func() error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "If an account exists for this email, a password reset link has been sent",
+		})
+	}
+
+	// One email per minute stops the endpoint being used to spam an inbox
+	cooldownKey := "password_reset:cooldown:" + req.Email
+	if h.DB.Cache != nil {
+		if _, err := h.DB.Cache.Get(ctx, cooldownKey); err == nil {
+			return respond()
+		}
+		_ = h.DB.Cache.Set(ctx, cooldownKey, []byte("1"), time.Minute)
+	}
+
+	var user models.User
+	err := h.DB.Collections().Users.FindOne(ctx, bson.M{"email": req.Email}, emailLookup()).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return respond()
+		}
+		return apperrors.Internal("Database error", err)
+	}
+	// Google accounts sign in with Google, and suspended accounts stay locked out
+	if user.AuthProvider == "google" || user.IsSuspended() {
+		return respond()
+	}
+
+	h.sendPasswordResetEmailAsync(user)
+	return respond()
+}
+
+// ResetPassword sets a new password from a reset link. It signs the account
+// out everywhere and lifts any login lockout.
+// POST /auth/password/reset
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.ResetPasswordRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	invalidLink := apperrors.BadRequest("Reset link is invalid or has expired", nil)
+	userID, fingerprint, err := h.parsePasswordResetToken(req.Token)
+	if err != nil {
+		return invalidLink
+	}
+
+	collection := h.DB.Collections().Users
+	var user models.User
+	if err := collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return invalidLink
+		}
+		return apperrors.Internal("Database error", err)
+	}
+	// The password already changed since the link was sent, e.g. the link was used
+	if passwordFingerprint(user.Password) != fingerprint {
+		return invalidLink
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return apperrors.Internal("Failed to hash password", err)
+	}
+	res, err := collection.UpdateOne(ctx,
+		// Matching the old hash makes concurrent uses of one link apply once
+		bson.M{"_id": userID, "password": user.Password},
+		bson.M{
+			"$set": bson.M{"password": string(hashedPassword), "updated_at": time.Now()},
+			"$inc": bson.M{"token_version": 1},
+		},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to reset password", err)
+	}
+	if res.MatchedCount == 0 {
+		return invalidLink
+	}
+
+	_ = h.DB.CacheDel(ctx, middleware.AccountStateCacheKey(userID.Hex()))
+	if err := clearLoginFailures(ctx, h.DB, models.NormalizeEmail(user.Email)); err != nil {
+		log.Printf("[AUTH] Failed to clear login failures for %s: %v", user.Email, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Password has been reset. Please log in with your new password",
+	})
+}
@@ -8,37 +8,84 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"math"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/razorpay"
 )
 
 // PaymentHandler provides endpoints for initiating payments (Razorpay order creation)
 type PaymentHandler struct {
-	DB  *database.DBClient
-	Cfg *config.Config
+	DB     *database.DBClient
+	Cfg    *config.Config
+	Events *events.Bus // Optional
 }
 
 func NewPaymentHandler(db *database.DBClient, cfg *config.Config) *PaymentHandler {
 	return &PaymentHandler{DB: db, Cfg: cfg}
 }
 
-// cartTotalINR computes the current cart total for a user
-func (h *PaymentHandler) cartTotalINR(userID any) (float64, error) {
+// gateway returns a Razorpay client for the key pair of a payment mode
+func (h *PaymentHandler) gateway(mode string) *razorpay.Client {
+	keys := h.Cfg.Razorpay(mode)
+	return razorpay.New(keys.Key, keys.Secret)
+}
+
+// payments confirms orders placed before they were paid
+func (h *PaymentHandler) payments() *jobs.PaymentReconciler {
+	return &jobs.PaymentReconciler{
+		DB:           h.DB,
+		Razorpay:     h.gateway(models.PaymentModeLive),
+		TestRazorpay: h.gateway(models.PaymentModeTest),
+		Events:       h.Events,
+	}
+}
+
+// paymentMode returns the Razorpay mode checkouts use now: the one chosen
+// in the store settings, else RAZORPAY_MODE
+func paymentMode(ctx context.Context, db *database.DBClient, cfg *config.Config) string {
+	var settings models.Settings
+	err := db.MongoDB.Collection("settings").FindOne(ctx, bson.M{}, options.FindOne().SetProjection(bson.M{"payment_mode": 1})).Decode(&settings)
+	if err == nil && settings.PaymentMode != "" {
+		return settings.PaymentMode
+	}
+	return cfg.RazorpayMode
+}
+
+// paymentModeFilter matches the orders paid in a mode; orders from before
+// modes were recorded were paid live
+func paymentModeFilter(mode string) interface{} {
+	if mode == models.PaymentModeTest {
+		return models.PaymentModeTest
+	}
+	return bson.M{"$ne": models.PaymentModeTest}
+}
+
+// cartTotalINR computes the current total of the cart lines matching filter
+func (h *PaymentHandler) cartTotalINR(filter bson.M) (float64, error) {
 	ctx := context.Background()
 	cartCol := h.DB.Collections().CartItems
 	prodCol := h.DB.Collections().Products
-	cursor, err := cartCol.Find(ctx, bson.M{"user_id": userID})
+	cursor, err := cartCol.Find(ctx, filter)
 	if err != nil {
 		return 0, err
 	}
@@ -67,73 +114,339 @@ func (h *PaymentHandler) cartTotalINR(userID any) (float64, error) {
 	return total, nil
 }
 
-// CreateRazorpayOrder creates a Razorpay order from cart total
+// CreateRazorpayOrder creates a Razorpay order from cart total, in the
+// currency given by the currency query param (default INR). The giftWrap,
+// giftCardCode and cartItemIds (comma-separated) query params price it like
+// the checkout that follows.
 func (h *PaymentHandler) CreateRazorpayOrder(c *fiber.Ctx) error {
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"success": false, "message": "Unauthorized"})
+		return apperrors.Unauthorized("Unauthorized")
 	}
 
-	if h.Cfg.RazorpayKey == "" || h.Cfg.RazorpaySecret == "" {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"success": false, "message": "Payment gateway not configured"})
+	keys := h.Cfg.Razorpay(paymentMode(c.Context(), h.DB, h.Cfg))
+	if !keys.Configured() {
+		return apperrors.Unavailable("Payment gateway not configured", nil)
 	}
-	total, err := h.cartTotalINR(user.UserID)
+	// Check before taking payment so the later checkout can't be refused
+	if err := requireVerifiedAccount(c.Context(), h.DB, h.Cfg, user.UserID); err != nil {
+		return err
+	}
+	currency, err := lookupCurrency(c.Context(), h.DB, c.Query("currency"))
+	if err != nil {
+		return err
+	}
+	var itemIDs []string
+	if ids := strings.TrimSpace(c.Query("cartItemIds")); ids != "" {
+		itemIDs = strings.Split(ids, ",")
+	}
+	cartFilter, _, err := cartSelection(user.UserID, itemIDs)
+	if err != nil {
+		return err
+	}
+	total, err := h.cartTotalINR(cartFilter)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": err.Error()})
+		return apperrors.BadRequest(err.Error(), nil)
 	}
 	if total <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Cart empty"})
+		return apperrors.BadRequest("Cart empty", nil)
+	}
+	price, err := priceCheckout(c.Context(), h.DB, total, c.QueryBool("giftWrap"), "", c.Query("giftCardCode"))
+	if err != nil {
+		return err
+	}
+	if price.Due <= 0 {
+		return apperrors.BadRequest("The gift card covers the whole order; check out with payment method gift_card", nil)
+	}
+
+	order, _, err := h.createRazorpayOrder(c.Context(), keys, user.UserID, currency, price.Due)
+	var gatewayErr *razorpayGatewayError
+	if errors.As(err, &gatewayErr) {
+		return c.Status(gatewayErr.Status).JSON(fiber.Map{"success": false, "message": "Gateway error", "raw": gatewayErr.Body})
 	}
+	if err != nil {
+		return apperrors.BadGateway("Failed to create payment order", err)
+	}
+	order["success"] = true
+	return c.JSON(order)
+}
 
-	amountPaise := int64(math.Round(total * 100))
+// razorpayGatewayError is Razorpay refusing to create an order, with the
+// status and body it answered
+type razorpayGatewayError struct {
+	Status int
+	Body   string
+}
+
+func (e *razorpayGatewayError) Error() string {
+	return fmt.Sprintf("razorpay answered %d: %s", e.Status, e.Body)
+}
+
+// createRazorpayOrder creates a Razorpay order charging due, in the base
+// currency, in the shopper's currency; checkout must then name the same one.
+// It returns what the storefront's payment widget needs and the Razorpay
+// order ID.
+func (h *PaymentHandler) createRazorpayOrder(ctx context.Context, keys config.RazorpayKeys, userID primitive.ObjectID, currency models.Currency, due float64) (fiber.Map, string, error) {
+	amount := currency.MinorUnits(currency.Convert(due))
 	rnd := make([]byte, 6)
 	rand.Read(rnd)
 	receipt := fmt.Sprintf("rcpt_%s", hex.EncodeToString(rnd))
 
-	payload := map[string]any{"amount": amountPaise, "currency": "INR", "receipt": receipt, "payment_capture": 1}
+	// The user note ties the payment to its shopper when checkout places the
+	// order before it is paid
+	payload := map[string]any{
+		"amount":          amount,
+		"currency":        currency.Code,
+		"receipt":         receipt,
+		"payment_capture": 1,
+		"notes":           map[string]string{"user_id": userID.Hex()},
+	}
+	// Linking the order to the user's Razorpay Customer lets checkout offer
+	// their saved cards and UPI IDs. Paying without them still works.
+	customerID, err := h.razorpayCustomerID(ctx, userID, keys.Mode)
+	if err != nil {
+		log.Printf("[PAYMENTS] Failed to get Razorpay customer for %s: %v", userID.Hex(), err)
+	} else {
+		payload["customer_id"] = customerID
+	}
 	b, _ := json.Marshal(payload)
-	req, _ := http.NewRequest("POST", "https://api.razorpay.com/v1/orders", bytes.NewBuffer(b))
+	req, _ := http.NewRequestWithContext(ctx, "POST", "https://api.razorpay.com/v1/orders", bytes.NewBuffer(b))
 	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(h.Cfg.RazorpayKey, h.Cfg.RazorpaySecret)
+	req.SetBasicAuth(keys.Key, keys.Secret)
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"success": false, "message": "Failed to create payment order", "error": err.Error()})
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode >= 300 {
-		return c.Status(resp.StatusCode).JSON(fiber.Map{"success": false, "message": "Gateway error", "raw": string(body)})
+		return nil, "", &razorpayGatewayError{Status: resp.StatusCode, Body: string(body)}
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, "", fmt.Errorf("decode razorpay order: %w", err)
 	}
 
-	return c.JSON(fiber.Map{"success": true, "key": h.Cfg.RazorpayKey, "amount": amountPaise, "currency": "INR", "data": json.RawMessage(body)})
+	// The mode tells the storefront which checkout key it was given, so
+	// test checkouts can be labelled as such
+	return fiber.Map{"key": keys.Key, "mode": keys.Mode, "amount": amount, "currency": currency.Code, "customerId": customerID, "data": json.RawMessage(body)}, created.ID, nil
 }
 
 // RazorpayWebhook validates webhook signatures from Razorpay
-// Set the endpoint URL in Razorpay dashboard and use Cfg.RazorpayWebhookSecret
+// Set the endpoint URL in the Razorpay dashboard of each mode, with
+// RAZORPAY_WEBHOOK_SECRET (live) or RAZORPAY_TEST_WEBHOOK_SECRET (test)
 func (h *PaymentHandler) RazorpayWebhook(c *fiber.Ctx) error {
-	if h.Cfg.RazorpayWebhookSecret == "" {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"success": false, "message": "Webhook secret not configured"})
+	live, test := h.Cfg.Razorpay(models.PaymentModeLive), h.Cfg.Razorpay(models.PaymentModeTest)
+	if live.WebhookSecret == "" && test.WebhookSecret == "" {
+		return apperrors.Unavailable("Webhook secret not configured", nil)
 	}
 
 	sig := c.Get("X-Razorpay-Signature")
 	if sig == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Missing signature"})
+		return apperrors.BadRequest("Missing signature", nil)
 	}
 
+	// The secret that signed the event tells which mode it comes from
 	body := c.Body()
-	mac := hmac.New(sha256.New, []byte(h.Cfg.RazorpayWebhookSecret))
-	mac.Write(body)
-	expected := hex.EncodeToString(mac.Sum(nil))
-	if !hmac.Equal([]byte(expected), []byte(sig)) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid webhook signature"})
+	var mode string
+	for _, keys := range []config.RazorpayKeys{live, test} {
+		if keys.WebhookSecret == "" {
+			continue
+		}
+		mac := hmac.New(sha256.New, []byte(keys.WebhookSecret))
+		mac.Write(body)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		if hmac.Equal([]byte(expected), []byte(sig)) {
+			mode = keys.Mode
+			break
+		}
+	}
+	if mode == "" {
+		return apperrors.BadRequest("Invalid webhook signature", nil)
 	}
 
-	// Parse event (optional minimal handling)
-	var evt map[string]any
-	if err := json.Unmarshal(body, &evt); err == nil {
-		// You can extend: update order/payment status based on event
+	var evt razorpayEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return apperrors.BadRequest("Invalid webhook payload", err)
+	}
+	if evt.Event == "payment.captured" {
+		if err := h.paymentCaptured(c.Context(), mode, evt.Payload.Payment.Entity); err != nil {
+			return err
+		}
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true})
 }
+
+// razorpayEvent is the subset of a Razorpay webhook body this API reads
+type razorpayEvent struct {
+	Event   string `json:"event"`
+	Payload struct {
+		Payment struct {
+			Entity razorpayPayment `json:"entity"`
+		} `json:"payment"`
+	} `json:"payload"`
+}
+
+type razorpayPayment struct {
+	ID      string `json:"id"`
+	OrderID string `json:"order_id"`
+	Amount  int64  `json:"amount"` // Paise
+	Method  string `json:"method"`
+}
+
+// paymentCaptured marks the matching order paid in the mode and announces
+// the capture. Razorpay retries webhooks, so each payment is announced once.
+func (h *PaymentHandler) paymentCaptured(ctx context.Context, mode string, payment razorpayPayment) error {
+	if payment.ID == "" {
+		return apperrors.BadRequest("Missing payment entity", nil)
+	}
+	seenKey := fmt.Sprintf("razorpay:captured:%s", payment.ID)
+	var seen bool
+	if err := h.DB.CacheGet(ctx, seenKey, &seen); err == nil && seen {
+		return nil
+	}
+
+	// Checkout usually creates the order first; when the webhook wins the
+	// race the event goes out without an order ID
+	capture := events.PaymentCapture{
+		RazorpayOrderID:   payment.OrderID,
+		RazorpayPaymentID: payment.ID,
+		Amount:            float64(payment.Amount) / 100,
+		Method:            payment.Method,
+		Mode:              mode,
+	}
+	orders := h.DB.Collections().Orders
+	var order models.Order
+	// A test payment must never settle a live order
+	err := orders.FindOne(ctx, bson.M{"payment_info.razorpay_order_id": payment.OrderID, "payment_info.mode": paymentModeFilter(mode)}).Decode(&order)
+	switch {
+	case err == nil:
+		capture.OrderID = order.ID.Hex()
+		if order.Status == orderstatus.Pending && order.PaymentInfo.Method == "razorpay" {
+			// Placed before it was paid, so the capture also releases it for fulfilment
+			_, err = h.payments().Confirm(ctx, order, razorpay.Payment{ID: payment.ID, OrderID: payment.OrderID, Amount: payment.Amount, Method: payment.Method})
+		} else {
+			// Never overwrite a refund
+			_, err = orders.UpdateOne(ctx,
+				bson.M{"_id": order.ID, "payment_status": bson.M{"$in": []string{"unpaid", "failed"}}},
+				bson.M{"$set": bson.M{"payment_status": "paid", "updated_at": time.Now()}},
+			)
+		}
+		if err != nil {
+			return apperrors.Internal("Failed to update order payment", err)
+		}
+		h.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()), fmt.Sprintf("orders:%s", order.UserID.Hex()))
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return apperrors.Internal("Failed to retrieve order", err)
+	}
+
+	h.Events.Publish(ctx, events.PaymentCaptured, capture)
+	h.DB.CacheSet(ctx, seenKey, true, 72*time.Hour)
+	return nil
+}
+
+// razorpayOrderStatus is the payment state of an order as its owner sees it
+type razorpayOrderStatus struct {
+	OrderID         string `json:"orderId"`
+	RazorpayOrderID string `json:"razorpayOrderId"`
+	Status          string `json:"status"`
+	PaymentStatus   string `json:"paymentStatus"`
+	// Set when the order was awaiting payment and Razorpay was asked
+	GatewayStatus string `json:"gatewayStatus,omitempty"` // "created", "attempted" or "paid"
+	// Awaiting is true while the order still waits for its payment
+	Awaiting bool `json:"awaiting"`
+}
+
+// GetRazorpayOrderStatus reports the payment state of the user's order for a
+// Razorpay order. Apps poll it when they lost the checkout callback; an order
+// still awaiting payment is checked with Razorpay and confirmed on the spot
+// when its payment was captured.
+// GET /payments/razorpay/order/:id/status
+func (h *PaymentHandler) GetRazorpayOrderStatus(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	ctx := c.Context()
+	orders := h.DB.Collections().Orders
+	var order models.Order
+	err := orders.FindOne(ctx, bson.M{"payment_info.razorpay_order_id": c.Params("id"), "user_id": user.UserID}).Decode(&order)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Order not found")
+		}
+		return apperrors.Internal("Failed to retrieve order", err)
+	}
+
+	var gatewayStatus string
+	if awaitingRazorpayPayment(order) {
+		if !h.Cfg.Razorpay(order.PaymentInfo.Mode).Configured() {
+			return apperrors.Unavailable("Payment gateway not configured", nil)
+		}
+		check, err := h.payments().Check(ctx, order)
+		if err != nil {
+			return apperrors.BadGateway("Failed to check payment status", err)
+		}
+		gatewayStatus = check.GatewayStatus
+		if check.Paid {
+			if err := orders.FindOne(ctx, bson.M{"_id": order.ID}).Decode(&order); err != nil {
+				return apperrors.Internal("Failed to retrieve order", err)
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Payment status retrieved",
+		"data": razorpayOrderStatus{
+			OrderID:         order.ID.Hex(),
+			RazorpayOrderID: order.PaymentInfo.RazorpayOrderID,
+			Status:          order.Status,
+			PaymentStatus:   order.PaymentStatus,
+			GatewayStatus:   gatewayStatus,
+			Awaiting:        awaitingRazorpayPayment(order),
+		},
+	})
+}
+
+// awaitingRazorpayPayment reports whether an order was placed before it was
+// paid and its payment hasn't been confirmed yet
+func awaitingRazorpayPayment(order models.Order) bool {
+	return order.PaymentInfo.Method == "razorpay" && order.Status == orderstatus.Pending &&
+		(order.PaymentStatus == "unpaid" || order.PaymentStatus == "failed")
+}
+
+// razorpayRefund refunds amount of a captured payment, in the minor units of
+// the currency it was made in, and returns the gateway's refund ID
+func razorpayRefund(ctx context.Context, keys config.RazorpayKeys, paymentID string, amount int64, notes map[string]string) (string, error) {
+	payload := map[string]any{"amount": amount, "notes": notes}
+	b, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("https://api.razorpay.com/v1/payments/%s/refund", paymentID), bytes.NewBuffer(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(keys.Key, keys.Secret)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("razorpay refund failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	var refund struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &refund); err != nil {
+		return "", fmt.Errorf("decode razorpay refund: %w", err)
+	}
+	return refund.ID, nil
+}
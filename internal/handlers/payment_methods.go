@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// razorpayCustomerField is where users store their Razorpay Customer of a
+// payment mode; test mode has customers of its own
+func razorpayCustomerField(mode string) string {
+	if mode == models.PaymentModeTest {
+		return "razorpay_test_customer_id"
+	}
+	return "razorpay_customer_id"
+}
+
+// savedCustomerID returns the user's Razorpay Customer of a mode, if any
+func savedCustomerID(user models.User, mode string) string {
+	if mode == models.PaymentModeTest {
+		return user.RazorpayTestCustomerID
+	}
+	return user.RazorpayCustomerID
+}
+
+// razorpayCustomerID returns the user's Razorpay Customer in a mode,
+// creating it on their first payment. Razorpay hands back the existing
+// customer when one already has the same email and phone, so concurrent
+// first payments agree.
+func (h *PaymentHandler) razorpayCustomerID(ctx context.Context, userID primitive.ObjectID, mode string) (string, error) {
+	field := razorpayCustomerField(mode)
+	var user models.User
+	opts := options.FindOne().SetProjection(bson.M{"name": 1, "email": 1, "phone": 1, field: 1})
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&user); err != nil {
+		return "", err
+	}
+	if id := savedCustomerID(user, mode); id != "" {
+		return id, nil
+	}
+
+	payload := map[string]any{"name": user.Name, "fail_existing": "0", "notes": map[string]string{"user_id": userID.Hex()}}
+	if user.Email != "" {
+		payload["email"] = user.Email
+	}
+	if user.Phone != "" {
+		payload["contact"] = user.Phone
+	}
+	var customer struct {
+		ID string `json:"id"`
+	}
+	if err := h.gateway(mode).Do(ctx, http.MethodPost, "/customers", payload, &customer); err != nil {
+		return "", err
+	}
+	if customer.ID == "" {
+		return "", fmt.Errorf("razorpay returned no customer ID")
+	}
+	if _, err := h.DB.Collections().Users.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": bson.M{
+		field:        customer.ID,
+		"updated_at": time.Now(),
+	}}); err != nil {
+		return "", err
+	}
+	return customer.ID, nil
+}
+
+// razorpayToken is a saved card or UPI ID as Razorpay returns it
+type razorpayToken struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Bank   string `json:"bank"`
+	Wallet string `json:"wallet"`
+	Card   *struct {
+		Name        string `json:"name"`
+		Last4       string `json:"last4"`
+		Network     string `json:"network"`
+		Type        string `json:"type"`
+		Issuer      string `json:"issuer"`
+		ExpiryMonth any    `json:"expiry_month"`
+		ExpiryYear  any    `json:"expiry_year"`
+	} `json:"card"`
+	VPA *struct {
+		Username string `json:"username"`
+		Handle   string `json:"handle"`
+	} `json:"vpa"`
+	UsedAt    int64 `json:"used_at"`
+	CreatedAt int64 `json:"created_at"`
+}
+
+// SavedPaymentMethod is a saved instrument as shown to its owner. Only
+// display details are included; paying with it happens in Razorpay checkout.
+type SavedPaymentMethod struct {
+	ID          string     `json:"id"`
+	Method      string     `json:"method"` // "card", "upi", ...
+	Network     string     `json:"network,omitempty"`
+	Last4       string     `json:"last4,omitempty"`
+	CardType    string     `json:"cardType,omitempty"`
+	Issuer      string     `json:"issuer,omitempty"`
+	ExpiryMonth string     `json:"expiryMonth,omitempty"`
+	ExpiryYear  string     `json:"expiryYear,omitempty"`
+	VPA         string     `json:"vpa,omitempty"`
+	Bank        string     `json:"bank,omitempty"`
+	Wallet      string     `json:"wallet,omitempty"`
+	UsedAt      *time.Time `json:"usedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (t razorpayToken) saved() SavedPaymentMethod {
+	m := SavedPaymentMethod{
+		ID:        t.ID,
+		Method:    t.Method,
+		Bank:      t.Bank,
+		Wallet:    t.Wallet,
+		CreatedAt: time.Unix(t.CreatedAt, 0),
+	}
+	if t.Card != nil {
+		m.Network, m.Last4, m.CardType, m.Issuer = t.Card.Network, t.Card.Last4, t.Card.Type, t.Card.Issuer
+		if t.Card.ExpiryMonth != nil {
+			m.ExpiryMonth = fmt.Sprint(t.Card.ExpiryMonth)
+		}
+		if t.Card.ExpiryYear != nil {
+			m.ExpiryYear = fmt.Sprint(t.Card.ExpiryYear)
+		}
+	}
+	if t.VPA != nil && t.VPA.Username != "" {
+		m.VPA = t.VPA.Username + "@" + t.VPA.Handle
+	}
+	if t.UsedAt > 0 {
+		used := time.Unix(t.UsedAt, 0)
+		m.UsedAt = &used
+	}
+	return m
+}
+
+// GetPaymentMethods lists the cards and UPI IDs the user saved in Razorpay
+// checkout, in the current payment mode. Users who haven't paid online yet
+// have none.
+// GET /payments/methods
+func (h *PaymentHandler) GetPaymentMethods(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	mode := paymentMode(c.Context(), h.DB, h.Cfg)
+	if !h.Cfg.Razorpay(mode).Configured() {
+		return apperrors.Unavailable("Payment gateway not configured", nil)
+	}
+
+	var account models.User
+	opts := options.FindOne().SetProjection(bson.M{razorpayCustomerField(mode): 1})
+	if err := h.DB.Collections().Users.FindOne(c.Context(), bson.M{"_id": user.UserID}, opts).Decode(&account); err != nil {
+		return apperrors.Internal("Failed to load account", err)
+	}
+
+	methods := []SavedPaymentMethod{}
+	if customerID := savedCustomerID(account, mode); customerID != "" {
+		var tokens struct {
+			Items []razorpayToken `json:"items"`
+		}
+		if err := h.gateway(mode).Do(c.Context(), http.MethodGet, "/customers/"+customerID+"/tokens", nil, &tokens); err != nil {
+			return apperrors.BadGateway("Failed to fetch saved payment methods", err)
+		}
+		for _, t := range tokens.Items {
+			methods = append(methods, t.saved())
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Saved payment methods retrieved",
+		"data":    methods,
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pincode"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// newPincodeDirectory creates the pincode lookup configured in cfg
+func newPincodeDirectory(db *database.DBClient, cfg *config.Config) *pincode.Directory {
+	return &pincode.Directory{DB: db, URL: cfg.PincodeAPIURL}
+}
+
+// LookupPincode returns the city, district and state of an Indian pincode
+// so address forms can fill them in
+// GET /addresses/pincode/:code
+func (h *AddressBookHandler) LookupPincode(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if !pincode.Valid(code) {
+		return apperrors.BadRequest("Invalid pincode, expected 6 digits", nil)
+	}
+	place, err := h.Pincodes.Lookup(c.Context(), code)
+	if err != nil {
+		if errors.Is(err, pincode.ErrNotFound) {
+			return apperrors.NotFound("Pincode not found")
+		}
+		return apperrors.BadGateway("Failed to look up pincode", err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Pincode found",
+		"data":    place,
+	})
+}
+
+// checkPincode rejects an Indian address whose pincode is unknown or lies in
+// another state. prefix is where the address sits in the request, e.g.
+// "shippingAddress.". Addresses are accepted when the lookup itself fails, so
+// an outage of the pincode API never blocks checkout.
+func checkPincode(ctx context.Context, dir *pincode.Directory, prefix, zipCode, state, country string) error {
+	if !pincode.IsIndia(country) {
+		return nil
+	}
+	if !pincode.Valid(zipCode) {
+		return apperrors.Validation("Validation failed", []validation.FieldError{
+			{Field: prefix + "zipCode", Rule: "pincode", Message: prefix + "zipCode must be a 6-digit pincode"},
+		})
+	}
+	place, err := dir.Lookup(ctx, zipCode)
+	if errors.Is(err, pincode.ErrNotFound) {
+		return apperrors.Validation("Validation failed", []validation.FieldError{
+			{Field: prefix + "zipCode", Rule: "pincode", Message: prefix + "zipCode is not a known pincode"},
+		})
+	}
+	if err != nil {
+		log.Printf("[ADDRESS] Failed to look up pincode %s: %v", zipCode, err)
+		return nil
+	}
+	if !place.InState(state) {
+		return apperrors.Validation("Validation failed", []validation.FieldError{
+			{Field: prefix + "state", Rule: "pincode_state", Message: fmt.Sprintf("Pincode %s is in %s, not %s", zipCode, place.State, state)},
+		})
+	}
+	return nil
+}
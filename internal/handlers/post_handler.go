@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// postsCacheTTL is how long journal pages are cached at most; a scheduled
+// post going live shortens it
+const postsCacheTTL = 5 * time.Minute
+
+// postListProjection leaves the article bodies out of listings
+var postListProjection = bson.M{"body": 0}
+
+// PostHandler manages the journal: articles written by staff and published
+// on the storefront
+type PostHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewPostHandler creates a new instance of PostHandler
+func NewPostHandler(db *database.DBClient, cfg *config.Config) *PostHandler {
+	return &PostHandler{DB: db, Config: cfg}
+}
+
+// postPage is a cached page of the journal
+type postPage struct {
+	Posts []models.Post `json:"posts"`
+	Total int64         `json:"total"`
+}
+
+// livePosts matches the posts shown on the storefront at now
+func livePosts(now time.Time) bson.M {
+	return bson.M{"status": models.PostPublished, "published_at": bson.M{"$lte": now}}
+}
+
+// cacheTTL returns how long journal pages built at now may be cached: no
+// longer than until the next scheduled post goes live
+func (h *PostHandler) cacheTTL(ctx context.Context, now time.Time) time.Duration {
+	var next models.Post
+	err := h.DB.Collections().Posts.FindOne(ctx,
+		bson.M{"status": models.PostPublished, "published_at": bson.M{"$gt": now}},
+		options.FindOne().SetSort(bson.D{{Key: "published_at", Value: 1}}).SetProjection(bson.M{"published_at": 1}),
+	).Decode(&next)
+	if err == nil && next.PublishedAt != nil && next.PublishedAt.Sub(now) < postsCacheTTL {
+		return next.PublishedAt.Sub(now)
+	}
+	return postsCacheTTL
+}
+
+// GetPosts lists the published journal posts, newest first, without their
+// bodies
+// GET /content/posts?tag=&page=1&limit=12
+func (h *PostHandler) GetPosts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "12"))
+	if limit < 1 || limit > 50 {
+		limit = 12
+	}
+	tag := models.NormalizeTags([]string{c.Query("tag")})
+
+	params := map[string]string{"list": "posts", "page": strconv.Itoa(page), "limit": strconv.Itoa(limit)}
+	if len(tag) > 0 {
+		params["tag"] = tag[0]
+	}
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.PostsCacheNamespace, params)
+	meta := func(total int64) fiber.Map {
+		return fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		}
+	}
+
+	var cached postPage
+	if err := h.DB.CacheGet(ctx, cacheKey, &cached); err == nil {
+		return sendConditionalJSON(c, fiber.Map{
+			"success": true,
+			"message": "Posts retrieved from cache",
+			"data":    cached.Posts,
+			"meta":    meta(cached.Total),
+		}, time.Time{})
+	}
+
+	now := time.Now()
+	filter := livePosts(now)
+	if len(tag) > 0 {
+		filter["tags"] = tag[0]
+	}
+	coll := h.DB.Collections().Posts
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count posts", err)
+	}
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "published_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)).
+		SetProjection(postListProjection))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch posts", err)
+	}
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return apperrors.Internal("Failed to decode posts", err)
+	}
+	_ = h.DB.CacheSet(ctx, cacheKey, postPage{Posts: posts, Total: total}, h.cacheTTL(ctx, now))
+
+	return sendConditionalJSON(c, fiber.Map{
+		"success": true,
+		"message": "Posts retrieved successfully",
+		"data":    posts,
+		"meta":    meta(total),
+	}, time.Time{})
+}
+
+// GetPost returns a published journal post with its body and SEO tags
+// GET /content/posts/:slug
+func (h *PostHandler) GetPost(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	slug := c.Params("slug")
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.PostsCacheNamespace, map[string]string{"post": slug})
+	var post models.Post
+	if err := h.DB.CacheGet(ctx, cacheKey, &post); err == nil {
+		return sendConditionalJSON(c, fiber.Map{
+			"success": true,
+			"message": "Post retrieved from cache",
+			"data":    post,
+		}, post.UpdatedAt)
+	}
+
+	now := time.Now()
+	filter := livePosts(now)
+	filter["slug"] = slug
+	if err := h.DB.Collections().Posts.FindOne(ctx, filter).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Post not found")
+		}
+		return apperrors.Internal("Failed to fetch post", err)
+	}
+	post.AuthorID = nil
+	seo := post.SEO.WithDefaults(post.Title, models.MetaDescription(post.Excerpt), storefrontURL(h.Config, storefrontPostPath+post.Slug))
+	post.SEO = &seo
+	_ = h.DB.CacheSet(ctx, cacheKey, post, postsCacheTTL)
+
+	return sendConditionalJSON(c, fiber.Map{
+		"success": true,
+		"message": "Post retrieved successfully",
+		"data":    post,
+	}, post.UpdatedAt)
+}
+
+// postFromRequest validates req and builds the post it describes. Posts
+// published without a date are published at now.
+func postFromRequest(req models.PostRequest, now time.Time) (models.Post, error) {
+	post := models.Post{
+		Title:       strings.TrimSpace(req.Title),
+		Slug:        models.Slugify(req.Slug),
+		Excerpt:     strings.TrimSpace(req.Excerpt),
+		Body:        req.Body,
+		CoverImage:  req.CoverImage,
+		Tags:        models.NormalizeTags(req.Tags),
+		Status:      req.Status,
+		PublishedAt: req.PublishedAt,
+		SEO:         req.SEO.Clean(),
+	}
+	if post.Slug == "" {
+		post.Slug = models.Slugify(post.Title)
+	}
+	if post.Title == "" || post.Slug == "" {
+		return post, apperrors.BadRequest("Post title must contain letters or digits", nil)
+	}
+	if post.Status == models.PostPublished && post.PublishedAt == nil {
+		post.PublishedAt = &now
+	}
+	return post, nil
+}
+
+// ListPosts lists every post for staff, drafts and scheduled ones included,
+// most recently updated first
+// GET /admin/posts?status=draft|published&page=1&limit=20
+func (h *PostHandler) ListPosts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	filter := bson.M{}
+	switch status := c.Query("status"); status {
+	case "":
+	case models.PostDraft, models.PostPublished:
+		filter["status"] = status
+	default:
+		return apperrors.BadRequest("status must be draft or published", nil)
+	}
+
+	coll := h.DB.Collections().Posts
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count posts", err)
+	}
+	cursor, err := coll.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)).
+		SetProjection(postListProjection))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch posts", err)
+	}
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return apperrors.Internal("Failed to decode posts", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Posts retrieved successfully",
+		"data":    posts,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetPostByID returns any post with its body, for editing
+// GET /admin/posts/:id
+func (h *PostHandler) GetPostByID(c *fiber.Ctx) error {
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid post ID", err)
+	}
+	post, err := h.find(c.Context(), objectID)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Post retrieved successfully",
+		"data":    post,
+	})
+}
+
+// CreatePost adds a journal post
+// POST /admin/posts
+func (h *PostHandler) CreatePost(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.PostRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	now := time.Now()
+	post, err := postFromRequest(req, now)
+	if err != nil {
+		return err
+	}
+	post.ID = primitive.NewObjectID()
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		post.AuthorID = &actor.UserID
+	}
+	post.CreatedAt = now
+	post.UpdatedAt = now
+
+	if _, err := h.DB.Collections().Posts.InsertOne(ctx, post); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("A post with this slug already exists")
+		}
+		return apperrors.Internal("Failed to create post", err)
+	}
+	h.DB.CacheBumpNamespace(ctx, database.PostsCacheNamespace)
+	recordAudit(c, h.DB.MongoDB, "post.create", "post", post.ID.Hex(), nil, post)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Post created successfully",
+		"data":    post,
+	})
+}
+
+// UpdatePost replaces a journal post. A published post keeps its publish
+// date unless a new one is given.
+// PUT /admin/posts/:id
+func (h *PostHandler) UpdatePost(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid post ID", err)
+	}
+	var req models.PostRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	before, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if req.PublishedAt == nil {
+		req.PublishedAt = before.PublishedAt
+	}
+	now := time.Now()
+	post, err := postFromRequest(req, now)
+	if err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"title":       post.Title,
+		"slug":        post.Slug,
+		"excerpt":     post.Excerpt,
+		"body":        post.Body,
+		"cover_image": post.CoverImage,
+		"tags":        post.Tags,
+		"status":      post.Status,
+		"updated_at":  now,
+	}
+	unset := bson.M{}
+	if post.PublishedAt != nil {
+		set["published_at"] = post.PublishedAt
+	} else {
+		unset["published_at"] = ""
+	}
+	if post.SEO != nil {
+		set["seo"] = post.SEO
+	} else {
+		unset["seo"] = ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var updated models.Post
+	err = h.DB.Collections().Posts.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Post not found")
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.Conflict("A post with this slug already exists")
+		}
+		return apperrors.Internal("Failed to update post", err)
+	}
+	h.DB.CacheBumpNamespace(ctx, database.PostsCacheNamespace)
+	recordAudit(c, h.DB.MongoDB, "post.update", "post", objectID.Hex(), before, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Post updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeletePost removes a journal post
+// DELETE /admin/posts/:id
+func (h *PostHandler) DeletePost(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid post ID", err)
+	}
+	var post models.Post
+	if err := h.DB.Collections().Posts.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Post not found")
+		}
+		return apperrors.Internal("Failed to delete post", err)
+	}
+	h.DB.CacheBumpNamespace(ctx, database.PostsCacheNamespace)
+	recordAudit(c, h.DB.MongoDB, "post.delete", "post", objectID.Hex(), post, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Post deleted successfully",
+	})
+}
+
+func (h *PostHandler) find(ctx context.Context, id primitive.ObjectID) (models.Post, error) {
+	var post models.Post
+	if err := h.DB.Collections().Posts.FindOne(ctx, bson.M{"_id": id}).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return post, apperrors.NotFound("Post not found")
+		}
+		return post, apperrors.Internal("Failed to fetch post", err)
+	}
+	return post, nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// checkAvailable reports whether ordering quantity units of product now is
+// a pre-order, which needs no stock, or returns an error when the units
+// can't be ordered at all
+func checkAvailable(product *models.Product, quantity int, now time.Time) (bool, error) {
+	if product.TakesPreorders(now) {
+		return true, nil
+	}
+	if !product.Released(now) {
+		return false, apperrors.BadRequest(fmt.Sprintf("%s is not released yet", product.Name), nil)
+	}
+	if product.Stock < quantity {
+		return false, apperrors.BadRequest(fmt.Sprintf("Not enough stock for product %s", product.Name), nil)
+	}
+	return false, nil
+}
+
+// hasPreorders reports whether any of the order's lines await allocation
+func hasPreorders(order models.Order) bool {
+	for _, item := range order.Items {
+		if item.Preorder {
+			return true
+		}
+	}
+	return false
+}
+
+// AllocatePreorders takes a product's pre-ordered units out of stock once
+// it has arrived, turning the pre-order lines into regular ones that can
+// ship. Orders are filled oldest first, stopping at the first one stock
+// can't cover so later orders never jump the queue.
+// POST /admin/products/:id/preorders/allocate
+func (h *ProductHandler) AllocatePreorders(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	productID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	products := h.DB.Collections().Products
+	var before models.Product
+	err = products.FindOne(ctx, bson.M{"_id": productID},
+		options.FindOne().SetProjection(bson.M{"stock": 1, "preorder_count": 1})).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.Internal("Failed to fetch product", err)
+	}
+
+	orders := h.DB.Collections().Orders
+	open := bson.M{"$nin": bson.A{orderstatus.Cancelled, orderstatus.Returned}}
+	cursor, err := orders.Find(ctx, bson.M{
+		"items":  bson.M{"$elemMatch": bson.M{"product_id": productID, "preorder": true}},
+		"status": open,
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch pre-orders", err)
+	}
+	var pending []models.Order
+	if err := cursor.All(ctx, &pending); err != nil {
+		return apperrors.Internal("Failed to decode pre-orders", err)
+	}
+
+	now := time.Now()
+	stock := before.Stock
+	allocatedUnits, allocatedOrders := 0, 0
+allocate:
+	for _, order := range pending {
+		allocated := false
+		for i, item := range order.Items {
+			if item.ProductID != productID || !item.Preorder {
+				continue
+			}
+			// Guarded like checkout, so stock never goes below zero
+			var updated models.Product
+			err := products.FindOneAndUpdate(ctx,
+				bson.M{"_id": productID, "stock": bson.M{"$gte": item.Quantity}},
+				bson.M{
+					"$inc": bson.M{"stock": -item.Quantity, "preorder_count": -item.Quantity},
+					"$set": bson.M{"updated_at": now},
+				},
+				options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"stock": 1}),
+			).Decode(&updated)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				break allocate
+			}
+			if err != nil {
+				return apperrors.Internal("Failed to take pre-ordered units from stock", err)
+			}
+
+			line := fmt.Sprintf("items.%d.preorder", i)
+			res, err := orders.UpdateOne(ctx,
+				bson.M{"_id": order.ID, line: true, "status": open},
+				bson.M{"$set": bson.M{line: false, "updated_at": now}},
+			)
+			if err != nil || res.MatchedCount == 0 {
+				// The order was cancelled meanwhile, which already released
+				// its pre-order, so the units go back as they were
+				products.UpdateOne(ctx, bson.M{"_id": productID},
+					bson.M{"$inc": bson.M{"stock": item.Quantity, "preorder_count": item.Quantity}})
+				if err != nil {
+					return apperrors.Internal("Failed to allocate pre-order", err)
+				}
+				continue
+			}
+			stock = updated.Stock
+			logStockMovement(ctx, h.DB, adminStockMovement(c, models.StockMovement{
+				ProductID:  productID,
+				Delta:      -item.Quantity,
+				StockAfter: updated.Stock,
+				Reason:     models.StockReasonSale,
+				Note:       "Pre-order allocated",
+				OrderID:    &order.ID,
+			}))
+			allocatedUnits += item.Quantity
+			allocated = true
+		}
+		if allocated {
+			allocatedOrders++
+			h.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()), fmt.Sprintf("orders:%s", order.UserID.Hex()))
+		}
+	}
+	if allocatedUnits > 0 {
+		h.DB.InvalidateProductCaches(ctx, id)
+	}
+
+	remaining := before.PreorderCount - allocatedUnits
+	recordAudit(c, h.DB.MongoDB, "product.preorders_allocate", "product", id,
+		bson.M{"stock": before.Stock, "preorder_count": before.PreorderCount},
+		bson.M{"stock": stock, "preorder_count": remaining})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("%d pre-ordered units allocated across %d orders", allocatedUnits, allocatedOrders),
+		"data": fiber.Map{
+			"allocatedUnits":     allocatedUnits,
+			"allocatedOrders":    allocatedOrders,
+			"remainingPreorders": remaining,
+			"stock":              stock,
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// lowestPriceWindowDays is how far back the storefront's lowest price looks
+const lowestPriceWindowDays = 30
+
+// GetPriceHistory lists a product's price and discount changes, newest first
+// GET /admin/products/:id/price-history?reason=&page=1&limit=50
+func (h *ProductHandler) GetPriceHistory(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := bson.M{"product_id": objectID}
+	if reason := c.Query("reason"); reason != "" {
+		filter["reason"] = reason
+	}
+
+	coll := h.DB.Collections().PriceHistory
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count price history", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch price history", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.PriceHistoryEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return apperrors.Internal("Failed to decode price history", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Price history retrieved successfully",
+		"data":    entries,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// adminPriceChange attributes a price change to the authenticated admin
+func adminPriceChange(c *fiber.Ctx, reason string) models.PriceHistoryEntry {
+	change := models.PriceHistoryEntry{Reason: reason}
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		change.ActorID = actor.UserID
+	}
+	return change
+}
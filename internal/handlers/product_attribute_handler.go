@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+const productAttributesCacheKey = "product_attributes"
+
+// ProductAttributeHandler manages the allowed values of product attributes
+type ProductAttributeHandler struct {
+	DB *database.DBClient
+}
+
+// NewProductAttributeHandler creates a new instance of ProductAttributeHandler
+func NewProductAttributeHandler(db *database.DBClient) *ProductAttributeHandler {
+	return &ProductAttributeHandler{DB: db}
+}
+
+// loadProductAttributes returns the attribute vocabularies by key.
+// Attributes missing from the map accept any value.
+func loadProductAttributes(ctx context.Context, db *database.DBClient) (map[string]models.ProductAttribute, error) {
+	var list []models.ProductAttribute
+	if err := db.CacheGet(ctx, productAttributesCacheKey, &list); err != nil {
+		cursor, err := db.Collections().ProductAttributes.Find(ctx, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		if err := cursor.All(ctx, &list); err != nil {
+			return nil, err
+		}
+		db.CacheSet(ctx, productAttributesCacheKey, list, 5*time.Minute)
+	}
+	attributes := make(map[string]models.ProductAttribute, len(list))
+	for _, a := range list {
+		attributes[a.Key] = a
+	}
+	return attributes, nil
+}
+
+// applyProductAttributes checks the product's attributes against their
+// vocabularies and rewrites them as the vocabulary spells them. On update,
+// before is the saved product: unchanged values are kept even when they
+// aren't allowed any more, so unrelated edits still save.
+func applyProductAttributes(ctx context.Context, db *database.DBClient, product, before *models.Product) error {
+	attributes, err := loadProductAttributes(ctx, db)
+	if err != nil {
+		return apperrors.Internal("Failed to load product attributes", err)
+	}
+	var saved map[string]*string
+	if before != nil {
+		saved = before.Attributes()
+	}
+
+	var fields []validation.FieldError
+	for key, value := range product.Attributes() {
+		*value = strings.TrimSpace(*value)
+		attribute, ok := attributes[key]
+		if !ok || *value == "" || (saved != nil && *saved[key] == *value) {
+			continue
+		}
+		allowed, ok := attribute.Match(*value)
+		if !ok {
+			fields = append(fields, validation.FieldError{
+				Field:   key,
+				Rule:    "oneof",
+				Message: fmt.Sprintf("%s must be one of: %s", attribute.Label, strings.Join(attribute.Values, ", ")),
+			})
+			continue
+		}
+		*value = allowed
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return apperrors.Validation("Invalid product attributes", fields)
+	}
+	return nil
+}
+
+// GetProductAttributes lists the attribute vocabularies; meta.keys lists
+// every attribute, including those that accept any value
+// GET /admin/product-attributes
+func (h *ProductAttributeHandler) GetProductAttributes(c *fiber.Ctx) error {
+	ctx := c.Context()
+	cursor, err := h.DB.Collections().ProductAttributes.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch product attributes", err)
+	}
+	list := []models.ProductAttribute{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return apperrors.Internal("Failed to decode product attributes", err)
+	}
+	keys := make([]string, 0, len(models.ProductAttributeFields))
+	for key := range models.ProductAttributeFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Product attributes retrieved successfully",
+		"data":    list,
+		"meta":    fiber.Map{"keys": keys},
+	})
+}
+
+// PutProductAttribute sets the allowed values of an attribute. Products
+// already using a value in another case are rewritten to match it, so
+// "leather" and "Leather" become one filter option.
+// PUT /admin/product-attributes/:key
+func (h *ProductAttributeHandler) PutProductAttribute(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	key := c.Params("key")
+	field, ok := models.ProductAttributeFields[key]
+	if !ok {
+		return apperrors.NotFound("Unknown product attribute")
+	}
+	var req models.ProductAttributeRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	values := make([]string, 0, len(req.Values))
+	seen := map[string]bool{}
+	for _, v := range req.Values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[strings.ToLower(v)] {
+			continue
+		}
+		seen[strings.ToLower(v)] = true
+		values = append(values, v)
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = key
+	}
+
+	var before *models.ProductAttribute
+	var existing models.ProductAttribute
+	if err := h.DB.Collections().ProductAttributes.FindOne(ctx, bson.M{"_id": key}).Decode(&existing); err == nil {
+		before = &existing
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Internal("Failed to retrieve product attribute", err)
+	}
+
+	now := time.Now()
+	_, err := h.DB.Collections().ProductAttributes.UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$set":         bson.M{"label": label, "values": values, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to save product attribute", err)
+	}
+	h.DB.CacheDel(ctx, productAttributesCacheKey)
+
+	// Spell existing values the way the vocabulary does
+	var normalized int64
+	for _, v := range values {
+		res, err := h.DB.Collections().Products.UpdateMany(ctx,
+			bson.M{field: primitive.Regex{Pattern: "^\\s*" + regexp.QuoteMeta(v) + "\\s*$", Options: "i"}},
+			bson.M{"$set": bson.M{field: v}},
+		)
+		if err != nil {
+			return apperrors.Internal("Failed to update products", err)
+		}
+		normalized += res.ModifiedCount
+	}
+	// The catalog filters follow the vocabulary either way
+	h.DB.InvalidateProductCaches(ctx)
+
+	attribute := models.ProductAttribute{Key: key, Label: label, Values: values, CreatedAt: now, UpdatedAt: now}
+	if before != nil {
+		attribute.CreatedAt = before.CreatedAt
+	}
+	recordAudit(c, h.DB.MongoDB, "product_attribute.update", "product_attribute", key, before, attribute)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Product attribute saved successfully",
+		"data":    attribute,
+		"meta":    fiber.Map{"normalizedProducts": normalized},
+	})
+}
+
+// DeleteProductAttribute removes an attribute's vocabulary, so it accepts
+// any value again
+// DELETE /admin/product-attributes/:key
+func (h *ProductAttributeHandler) DeleteProductAttribute(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	key := c.Params("key")
+	var attribute models.ProductAttribute
+	if err := h.DB.Collections().ProductAttributes.FindOneAndDelete(ctx, bson.M{"_id": key}).Decode(&attribute); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Product attribute has no allowed values")
+		}
+		return apperrors.Internal("Failed to delete product attribute", err)
+	}
+	h.DB.CacheDel(ctx, productAttributesCacheKey)
+	h.DB.CacheBumpNamespace(ctx, database.ProductsCacheNamespace)
+	recordAudit(c, h.DB.MongoDB, "product_attribute.delete", "product_attribute", key, attribute, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Product attribute deleted successfully",
+	})
+}
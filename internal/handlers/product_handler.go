@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,15 +15,24 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/catalogread"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pricehistory"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/productviews"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
 // ProductHandler handles product related requests
 type ProductHandler struct {
-	DB     *database.DBClient
-	Config *config.Config
+	DB      *database.DBClient
+	Config  *config.Config
+	Storage storage.Storage
+	Events  *events.Bus  // Optional
+	Warmer  *CacheWarmer // Optional; learns which listings to keep warm
 }
 
 // NewProductHandler creates a new instance of ProductHandler
@@ -31,54 +43,131 @@ func NewProductHandler(db *database.DBClient, cfg *config.Config) *ProductHandle
 	}
 }
 
+// categoryPath returns the category path a listing is scoped to, given
+// either as the full path or as the legacy mainCategory/subcategory pair.
+// Listings include the products of its subcategories.
+func categoryPath(category, mainCategory, subcategory string) string {
+	if category != "" {
+		return models.CleanCategoryPath(category)
+	}
+	return models.CleanCategoryPath(models.JoinCategoryPath(mainCategory, subcategory))
+}
+
 // GetProducts returns all products with optional filters
 func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 	ctx := c.Context()
 
 	// Parse query parameters for filtering
-	category := c.Query("category")
-	mainCategory := c.Query("mainCategory")
-	subcategory := c.Query("subcategory")
-	minPriceStr := c.Query("minPrice")
-	maxPriceStr := c.Query("maxPrice")
-	sortBy := c.Query("sortBy", "createdAt") // Default sort by createdAt
-	order := c.Query("order", "desc")        // Default order desc
-	pageStr := c.Query("page", "1")
-	limitStr := c.Query("limit", "10")
+	q := productListQuery{
+		Category:     c.Query("category"),
+		MainCategory: c.Query("mainCategory"),
+		Subcategory:  c.Query("subcategory"),
+		MinPrice:     c.Query("minPrice"),
+		MaxPrice:     c.Query("maxPrice"),
+		SortBy:       c.Query("sortBy", "createdAt"), // Default sort by createdAt
+		Order:        c.Query("order", "desc"),       // Default order desc
+	}
 
 	// Convert string parameters to appropriate types
-	page, err := strconv.Atoi(pageStr)
+	page, err := strconv.Atoi(c.Query("page", "1"))
 	if err != nil || page < 1 {
 		page = 1
 	}
+	q.Page = page
 
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
 	if err != nil || limit < 1 {
 		limit = 10
 	}
+	q.Limit = limit
+
+	// Opt-in cursor pagination for deep pages
+	sortField, sortDirection := q.sort()
+	paging, err := parseCursorPage(c, sortField, sortDirection)
+	if err != nil {
+		return err
+	}
+	if paging != nil {
+		if _, ok := productSortFields[q.SortBy]; !ok {
+			return apperrors.BadRequest("Cursor pagination supports sortBy createdAt, price, stock, rating or popularity", nil)
+		}
+		return h.listProductsAfter(c, q.filter(), paging, limit)
+	}
+
+	// First pages are what the cache warmer keeps warm
+	if page == 1 {
+		h.Warmer.Record(q)
+	}
+
+	// First check if we have this query cached in Redis. The key covers every
+	// filter and is versioned so product mutations invalidate all listings.
+	var products []models.Product
+	err = h.DB.CacheGet(ctx, q.cacheKey(ctx, h.DB), &products)
+	if err == nil {
+		// Cache hit
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Products retrieved from cache",
+			"data":    products,
+			"meta": fiber.Map{
+				"page":  page,
+				"limit": limit,
+			},
+		})
+	}
+
+	// Cache miss, get from database
+	products, total, err := h.loadProductPage(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	// Return the products
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Products retrieved successfully",
+		"data":    products,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit), // ceiling division
+		},
+	})
+}
 
-	// Build the filter
+// productListQuery is a page of the GET /products listing
+type productListQuery struct {
+	Category     string
+	MainCategory string
+	Subcategory  string
+	MinPrice     string
+	MaxPrice     string
+	SortBy       string
+	Order        string
+	Page         int
+	Limit        int
+}
+
+// filter builds the listing's filter
+func (q productListQuery) filter() bson.M {
 	filter := bson.M{}
 
 	// Add category filter if provided (support legacy and split main/sub params)
-	if category != "" {
-		filter["category"] = category
-	} else if mainCategory != "" && subcategory != "" {
-		filter["category"] = mainCategory + "/" + subcategory
-	} else if mainCategory != "" {
-		filter["category"] = bson.M{"$regex": fmt.Sprintf("^%s", mainCategory)}
+	if path := categoryPath(q.Category, q.MainCategory, q.Subcategory); path != "" {
+		filter["category"] = models.CategorySubtree(path)
 	}
 
 	// Add price range filters if provided
-	if minPriceStr != "" {
-		minPrice, err := strconv.ParseFloat(minPriceStr, 64)
+	if q.MinPrice != "" {
+		minPrice, err := strconv.ParseFloat(q.MinPrice, 64)
 		if err == nil && minPrice >= 0 {
 			filter["price"] = bson.M{"$gte": minPrice}
 		}
 	}
 
-	if maxPriceStr != "" {
-		maxPrice, err := strconv.ParseFloat(maxPriceStr, 64)
+	if q.MaxPrice != "" {
+		maxPrice, err := strconv.ParseFloat(q.MaxPrice, 64)
 		if err == nil && maxPrice > 0 {
 			if _, ok := filter["price"]; ok {
 				filter["price"].(bson.M)["$lte"] = maxPrice
@@ -87,84 +176,122 @@ func (h *ProductHandler) GetProducts(c *fiber.Ctx) error {
 			}
 		}
 	}
+	return filter
+}
 
-	// Determine sort direction
+// sort returns the field and direction the listing is sorted by
+func (q productListQuery) sort() (string, int) {
 	sortDirection := 1 // ascending
-	if order == "desc" {
+	if q.Order == "desc" {
 		sortDirection = -1 // descending
 	}
 
-	// Configure options for pagination and sorting
-	findOptions := options.Find()
-	findOptions.SetSkip(int64((page - 1) * limit))
-	findOptions.SetLimit(int64(limit))
-	findOptions.SetSort(bson.D{{Key: sortBy, Value: sortDirection}})
-
-	// First check if we have this query cached in Redis
-	cacheKey := fmt.Sprintf("products:%s:%s:%s:%s:%d:%d",
-		category, minPriceStr, maxPriceStr, sortBy, page, limit)
+	sortField := q.SortBy
+	if field, ok := productSortFields[q.SortBy]; ok {
+		sortField = field
+	}
+	return sortField, sortDirection
+}
 
-	var products []models.Product
-	err = h.DB.CacheGet(ctx, cacheKey, &products)
-	if err == nil {
-		// Cache hit
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"success": true,
-			"message": "Products retrieved from cache",
-			"data":    products,
-			"meta": fiber.Map{
-				"page":  page,
-				"limit": limit,
-			},
-		})
+// params returns the query's parameters as they appear in the URL
+func (q productListQuery) params() map[string]string {
+	return map[string]string{
+		"category":     q.Category,
+		"mainCategory": q.MainCategory,
+		"subcategory":  q.Subcategory,
+		"minPrice":     q.MinPrice,
+		"maxPrice":     q.MaxPrice,
+		"sortBy":       q.SortBy,
+		"order":        q.Order,
+		"page":         strconv.Itoa(q.Page),
+		"limit":        strconv.Itoa(q.Limit),
 	}
+}
+
+// cacheKey is where the page is cached
+func (q productListQuery) cacheKey(ctx context.Context, db *database.DBClient) string {
+	return db.VersionedCacheKey(ctx, database.ProductsCacheNamespace, q.params())
+}
+
+// loadProductPage reads a page of the listing from the database and caches
+// it. Requests that miss the cache and the cache warmer both load through it.
+func (h *ProductHandler) loadProductPage(ctx context.Context, q productListQuery) ([]models.Product, int64, error) {
+	// Read the key before querying, so a mutation during the query leaves the
+	// page under the version it invalidated
+	cacheKey := q.cacheKey(ctx, h.DB)
+	filter := q.filter()
+	sortField, sortDirection := q.sort()
+
+	// Configure options for pagination and sorting
+	findOptions := options.Find()
+	findOptions.SetSkip(int64((q.Page - 1) * q.Limit))
+	findOptions.SetLimit(int64(q.Limit))
+	findOptions.SetSort(bson.D{{Key: sortField, Value: sortDirection}})
 
-	// Cache miss, get from database
 	collection := h.DB.Collections().Products
 
 	// Count total matching documents for pagination info
 	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to count products",
-			"error":   err.Error(),
-		})
+		return nil, 0, apperrors.Internal("Failed to count products", err)
 	}
 
 	// Execute the query
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve products",
-			"error":   err.Error(),
-		})
+		return nil, 0, apperrors.Internal("Failed to retrieve products", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode the results
+	var products []models.Product
 	if err := cursor.All(ctx, &products); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode products",
-			"error":   err.Error(),
-		})
+		return nil, 0, apperrors.Internal("Failed to decode products", err)
 	}
 
 	// Cache the results for future requests (expire after 10 minutes)
 	h.DB.CacheSet(ctx, cacheKey, products, 10*time.Minute)
+	return products, total, nil
+}
+
+// productSortFields maps the sortBy values product listings accept to fields
+var productSortFields = map[string]string{
+	"createdAt":  "created_at",
+	"price":      "price",
+	"stock":      "stock",
+	"rating":     "avg_rating",
+	"popularity": "popularity",
+}
+
+// listProductsAfter serves GetProducts with cursor pagination. Pages are
+// read straight from the database since each cursor is only used once.
+func (h *ProductHandler) listProductsAfter(c *fiber.Ctx, filter bson.M, paging *cursorPage, limit int) error {
+	ctx := c.Context()
+
+	findOptions := options.Find()
+	filter = paging.apply(filter, findOptions, limit)
+	cursor, err := h.DB.Collections().Products.Find(ctx, filter, findOptions)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve products", err)
+	}
+	defer cursor.Close(ctx)
+
+	products := []models.Product{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return apperrors.Internal("Failed to decode products", err)
+	}
+	n, nextCursor, err := paging.next(len(products), limit, func(i int) interface{} { return products[i] })
+	if err != nil {
+		return apperrors.Internal("Failed to build next cursor", err)
+	}
 
-	// Return the products
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"message": "Products retrieved successfully",
-		"data":    products,
+		"data":    products[:n],
 		"meta": fiber.Map{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-			"pages": (total + int64(limit) - 1) / int64(limit), // ceiling division
+			"limit":      limit,
+			"nextCursor": nextCursor,
 		},
 	})
 }
@@ -176,10 +303,7 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	// Get product ID from URL parameter
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Product ID is required",
-		})
+		return apperrors.BadRequest("Product ID is required", nil)
 	}
 
 	// Check if the product is in Redis cache
@@ -189,6 +313,8 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	err := h.DB.CacheGet(ctx, cacheKey, &product)
 	if err == nil {
 		// Cache hit
+		h.recordView(ctx, product.ID)
+		h.translateProduct(c, &product)
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"success": true,
 			"message": "Product retrieved from cache",
@@ -199,31 +325,22 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID format",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid product ID format", err)
 	}
 
 	// Find product in database
 	collection := h.DB.Collections().Products
 	if err := collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&product); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve product",
-			"error":   err.Error(),
-		})
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.Internal("Failed to retrieve product", err)
 	}
 
 	// Cache the product for future requests (expire after 30 minutes)
 	h.DB.CacheSet(ctx, cacheKey, product, 30*time.Minute)
+	h.recordView(ctx, product.ID)
+	h.translateProduct(c, &product)
 
 	// Return the product
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -233,29 +350,182 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 	})
 }
 
+// recordView counts a product page view towards its popularity, unless
+// views aren't flushed
+func (h *ProductHandler) recordView(ctx context.Context, id primitive.ObjectID) {
+	if h.Config.ProductViewFlushIntervalMinutes > 0 {
+		productviews.Record(ctx, h.DB, id)
+	}
+}
+
+// translateProduct applies the translations of the locale asked for with
+// ?locale=. Without it the product keeps its source text and translations,
+// which is what the admin panel edits; Accept-Language is only honoured by
+// the catalog endpoints.
+func (h *ProductHandler) translateProduct(c *fiber.Ctx, product *models.Product) {
+	locale := strings.ToLower(c.Query("locale"))
+	if !supportedLocale(h.Config, locale) {
+		return
+	}
+	product.Localize(locale)
+	c.Set(fiber.HeaderContentLanguage, locale)
+}
+
+// publicProduct is the reduced product shape served by catalog listings
+type publicProduct struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	Name         string             `json:"name"`
+	Slug         string             `json:"slug"`
+	Price        float64            `json:"price"`
+	Images       []string           `json:"images"`
+	Category     string             `json:"category"`
+	Stock        int                `json:"stock"`
+	Brand        string             `json:"brand,omitempty"`
+	MainCategory string             `bson:"main_category" json:"mainCategory,omitempty"`
+	Subcategory  string             `bson:"subcategory" json:"subcategory,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at" json:"-"` // Sort key for cursor pagination
+	// Price after any active discount; set by localize. Its bson name is the
+	// catalog read model's sort key for price.
+	FinalPrice float64 `bson:"effective_price" json:"finalPrice"`
+	// Unset on unreviewed products, so cursors built from it match how
+	// missing ratings sort
+	Rating       *float64 `bson:"avg_rating,omitempty" json:"rating,omitempty"`
+	RatingsCount int      `bson:"ratings_count" json:"ratingsCount"`
+	// Sort key for popularity; unset on products nobody viewed lately
+	Popularity *int64 `bson:"popularity,omitempty" json:"-"`
+	// Purchase limits, for capping quantity selectors; limitQuantity fills
+	// in MAX_QUANTITY_PER_ORDER when the product sets no limit of its own
+	MaxPerOrder    *int `bson:"max_per_order,omitempty" json:"maxPerOrder,omitempty"`
+	MaxPerCustomer *int `bson:"max_per_customer,omitempty" json:"maxPerCustomer,omitempty"`
+	// Upcoming releases; see models.Product.TakesPreorders
+	ReleaseDate   *time.Time `bson:"release_date,omitempty" json:"releaseDate,omitempty"`
+	AllowPreorder bool       `bson:"allow_preorder,omitempty" json:"allowPreorder,omitempty"`
+	// discount fields
+	DiscountPercentage *float64   `bson:"discount_percentage,omitempty" json:"discountPercentage,omitempty"`
+	DiscountAmount     *float64   `bson:"discount_amount,omitempty" json:"discountAmount,omitempty"`
+	DiscountStartDate  *time.Time `bson:"discount_start_date,omitempty" json:"discountStartDate,omitempty"`
+	DiscountEndDate    *time.Time `bson:"discount_end_date,omitempty" json:"discountEndDate,omitempty"`
+	// Cleared by localize, which applies them
+	Translations models.Translations `bson:"translations,omitempty" json:"translations,omitempty"`
+	// Set by localize
+	Currency       string `bson:"-" json:"currency,omitempty"`
+	FormattedPrice string `bson:"-" json:"formattedPrice,omitempty"`
+	// Product detail only; the lowest price over the last 30 days, shown
+	// next to discounts
+	LowestPrice30Days *float64 `bson:"-" json:"lowestPrice30Days,omitempty"`
+	// Product detail only; the page's meta tags, defaulted from the product
+	SEO         *models.SEO `bson:"seo,omitempty" json:"seo,omitempty"`
+	Description string      `bson:"description,omitempty" json:"-"`
+}
+
+// localize converts the prices of p into currency and labels them, and
+// translates its name into locale
+func (p *publicProduct) localize(currency models.Currency, locale string) {
+	p.Name = p.Translations.Text(locale, "name", p.Name)
+	p.Translations = nil
+	product := models.Product{
+		Price:              p.Price,
+		DiscountPercentage: p.DiscountPercentage,
+		DiscountAmount:     p.DiscountAmount,
+		DiscountStartDate:  p.DiscountStartDate,
+		DiscountEndDate:    p.DiscountEndDate,
+	}
+	p.FinalPrice = currency.Convert(product.GetFinalPrice())
+	p.Price = currency.Convert(p.Price)
+	if p.DiscountAmount != nil {
+		amount := currency.Convert(*p.DiscountAmount)
+		p.DiscountAmount = &amount
+	}
+	p.Currency = currency.Code
+	p.FormattedPrice = currency.Format(p.Price)
+	if p.LowestPrice30Days != nil {
+		lowest := currency.Convert(*p.LowestPrice30Days)
+		p.LowestPrice30Days = &lowest
+	}
+}
+
+// limitQuantity sets MaxPerOrder to the product's own limit or, failing
+// that, defaultLimit, the store-wide one
+func (p *publicProduct) limitQuantity(defaultLimit int) {
+	product := models.Product{MaxPerOrder: p.MaxPerOrder}
+	if limit := product.EffectiveMaxPerOrder(defaultLimit); limit > 0 {
+		p.MaxPerOrder = &limit
+	}
+}
+
+// publicProductProjection selects the fields of publicProduct
+var publicProductProjection = bson.M{
+	"name":          1,
+	"slug":          1,
+	"price":         1,
+	"images":        1,
+	"category":      1,
+	"stock":         1,
+	"brand":         1,
+	"main_category": 1,
+	"subcategory":   1,
+	"created_at":    1,
+	"translations":  1,
+	// Precomputed in the catalog read model; ratings are on products too
+	"effective_price": 1,
+	"avg_rating":      1,
+	"ratings_count":   1,
+	"popularity":      1,
+	// purchase limits
+	"max_per_order":    1,
+	"max_per_customer": 1,
+	// upcoming releases
+	"release_date":   1,
+	"allow_preorder": 1,
+	// discount fields
+	"discount_percentage": 1,
+	"discount_amount":     1,
+	"discount_start_date": 1,
+	"discount_end_date":   1,
+}
+
 // GetPublicProducts is a light-weight customer storefront endpoint.
 // GET /catalog/products
 // Accepts same query params as GetProducts but responds with a reduced field set
 // to minimize payload (id, name, price, images, category, stock, brand, mainCategory, subcategory).
 func (h *ProductHandler) GetPublicProducts(c *fiber.Ctx) error {
+	return h.listPublicProducts(c, nil, fiber.Map{})
+}
+
+// GetBrandProducts is the storefront listing of one brand's products. It
+// accepts the same query params as GetPublicProducts except brand.
+// GET /catalog/brands/:slug/products
+func (h *ProductHandler) GetBrandProducts(c *fiber.Ctx) error {
+	var brand models.Brand
+	err := h.DB.Collections().Brands.FindOne(c.Context(), bson.M{"slug": c.Params("slug")}).Decode(&brand)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Brand not found")
+		}
+		return apperrors.Internal("Failed to retrieve brand", err)
+	}
+	return h.listPublicProducts(c, bson.M{"brand": brand.Name}, fiber.Map{"brand": brand})
+}
+
+// listPublicProducts serves GetPublicProducts. scope overrides the filters
+// parsed from the query and meta is added to the response meta.
+func (h *ProductHandler) listPublicProducts(c *fiber.Ctx, scope bson.M, meta fiber.Map) error {
 	// Reuse GetProducts logic but then map response data
 	// Call the internal logic directly by duplicating minimal parts to avoid double writes.
 	ctx := c.Context()
 
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	locale := requestLocale(c, h.Config)
+
 	// Parse subset of filters (reuse existing parsing by calling original handler would cause double writes)
 	category := c.Query("category")
 	mainCategory := c.Query("mainCategory")
 	subcategory := c.Query("subcategory")
 	// Dynamic filter params
 	brandParam := c.Query("brand") // comma-separated or single
-	gender := c.Query("gender")
-	dialColor := c.Query("dialColor")
-	dialShape := c.Query("dialShape")
-	dialType := c.Query("dialType")
-	strapColor := c.Query("strapColor")
-	strapMaterial := c.Query("strapMaterial")
-	style := c.Query("style")
-	dialThickness := c.Query("dialThickness")
 	minPriceStr := c.Query("minPrice")
 	maxPriceStr := c.Query("maxPrice")
 	inStockStr := c.Query("inStock")
@@ -274,12 +544,8 @@ func (h *ProductHandler) GetPublicProducts(c *fiber.Ctx) error {
 	}
 
 	filter := bson.M{}
-	if category != "" {
-		filter["category"] = category
-	} else if mainCategory != "" && subcategory != "" {
-		filter["category"] = mainCategory + "/" + subcategory
-	} else if mainCategory != "" {
-		filter["category"] = bson.M{"$regex": fmt.Sprintf("^%s", mainCategory)}
+	if path := categoryPath(category, mainCategory, subcategory); path != "" {
+		filter["category"] = models.CategorySubtree(path)
 	}
 
 	// Apply dynamic attribute filters
@@ -297,128 +563,128 @@ func (h *ProductHandler) GetPublicProducts(c *fiber.Ctx) error {
 			filter["brand"] = bson.M{"$in": parts}
 		}
 	}
-	if gender != "" {
-		filter["gender"] = gender
-	}
-	if dialColor != "" {
-		filter["dial_color"] = dialColor
-	}
-	if dialShape != "" {
-		filter["dial_shape"] = dialShape
-	}
-	if dialType != "" {
-		filter["dial_type"] = dialType
-	}
-	if strapColor != "" {
-		filter["strap_color"] = strapColor
-	}
-	if strapMaterial != "" {
-		filter["strap_material"] = strapMaterial
-	}
-	if style != "" {
-		filter["style"] = style
+	// Attribute params are matched as their vocabulary spells them, if any
+	attributes, err := loadProductAttributes(ctx, h.DB)
+	if err != nil {
+		return apperrors.Internal("Failed to load product attributes", err)
 	}
-	if dialThickness != "" {
-		filter["dial_thickness"] = dialThickness
+	for key, field := range models.ProductAttributeFields {
+		value := strings.TrimSpace(c.Query(key))
+		if value == "" {
+			continue
+		}
+		if attribute, ok := attributes[key]; ok {
+			if allowed, ok := attribute.Match(value); ok {
+				value = allowed
+			}
+		}
+		filter[field] = value
 	}
 	if inStockStr != "" {
 		if inStockStr == "1" || strings.EqualFold(inStockStr, "true") {
 			filter["stock"] = bson.M{"$gt": 0}
 		}
 	}
+	// Listings read the catalog read model while it is in sync. Its stored
+	// effective price lets price filters and sorting see discounts; reading
+	// products, they use the list price.
+	collection, priceField := h.DB.Collections().Products, "price"
+	if h.Config.CatalogReadModel && catalogread.Ready() {
+		collection, priceField = h.DB.Collections().CatalogRead, "effective_price"
+	}
+
+	// Price bounds are in the requested currency
 	if minPriceStr != "" {
 		if v, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
-			filter["price"] = bson.M{"$gte": v}
+			filter[priceField] = bson.M{"$gte": currency.ToBase(v)}
 		}
 	}
 	if maxPriceStr != "" {
 		if v, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
-			if m, ok := filter["price"].(bson.M); ok {
-				m["$lte"] = v
+			if m, ok := filter[priceField].(bson.M); ok {
+				m["$lte"] = currency.ToBase(v)
 			} else {
-				filter["price"] = bson.M{"$lte": v}
+				filter[priceField] = bson.M{"$lte": currency.ToBase(v)}
 			}
 		}
 	}
 
-	collection := h.DB.Collections().Products
+	for k, v := range scope {
+		filter[k] = v
+	}
 
-	// Simple pagination without caching (could add later)
-	findOptions := options.Find()
-	findOptions.SetSkip(int64((page - 1) * limit))
-	findOptions.SetLimit(int64(limit))
 	// Determine sort
-	sortField := sortBy
-	if sortField == "createdAt" || sortField == "price" || sortField == "stock" {
-		dir := -1
+	sortField, dir := "created_at", -1
+	if field, ok := productSortFields[sortBy]; ok {
+		sortField = field
+		if field == "price" {
+			sortField = priceField
+		}
 		if strings.EqualFold(order, "asc") {
 			dir = 1
 		}
-		findOptions.SetSort(bson.D{{Key: sortField, Value: dir}})
-	} else {
-		findOptions.SetSort(bson.D{{Key: "createdAt", Value: -1}})
 	}
-	// Projection to reduce payload (but include discount fields)
-	findOptions.SetProjection(bson.M{
-		"name":         1,
-		"price":        1,
-		"images":       1,
-		"category":     1,
-		"stock":        1,
-		"brand":        1,
-		"mainCategory": 1,
-		"subcategory":  1,
-		// discount fields
-		"discount_percentage": 1,
-		"discount_amount":     1,
-		"discount_start_date": 1,
-		"discount_end_date":   1,
-	})
-
-	total, err := collection.CountDocuments(ctx, filter)
+	paging, err := parseCursorPage(c, sortField, dir)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to count products", "error": err.Error()})
+		return err
 	}
 
+	// Simple pagination without caching (could add later)
+	findOptions := options.Find()
+	var total int64
+	if paging != nil {
+		filter = paging.apply(filter, findOptions, limit)
+	} else {
+		findOptions.SetSkip(int64((page - 1) * limit))
+		findOptions.SetLimit(int64(limit))
+		findOptions.SetSort(bson.D{{Key: sortField, Value: dir}})
+
+		total, err = collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return apperrors.Internal("Failed to count products", err)
+		}
+	}
+	// Projection to reduce payload (but include discount fields)
+	findOptions.SetProjection(publicProductProjection)
+
 	cursor, err := collection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to retrieve products", "error": err.Error()})
+		return apperrors.Internal("Failed to retrieve products", err)
 	}
 	defer cursor.Close(ctx)
 
-	type PublicProduct struct {
-		ID           primitive.ObjectID `bson:"_id" json:"id"`
-		Name         string             `json:"name"`
-		Price        float64            `json:"price"`
-		Images       []string           `json:"images"`
-		Category     string             `json:"category"`
-		Stock        int                `json:"stock"`
-		Brand        string             `json:"brand,omitempty"`
-		MainCategory string             `json:"mainCategory,omitempty"`
-		Subcategory  string             `json:"subcategory,omitempty"`
-		// discount fields
-		DiscountPercentage *float64   `bson:"discount_percentage,omitempty" json:"discountPercentage,omitempty"`
-		DiscountAmount     *float64   `bson:"discount_amount,omitempty" json:"discountAmount,omitempty"`
-		DiscountStartDate  *time.Time `bson:"discount_start_date,omitempty" json:"discountStartDate,omitempty"`
-		DiscountEndDate    *time.Time `bson:"discount_end_date,omitempty" json:"discountEndDate,omitempty"`
-	}
-
-	var items []PublicProduct
+	items := []publicProduct{}
 	if err := cursor.All(ctx, &items); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to decode products", "error": err.Error()})
+		return apperrors.Internal("Failed to decode products", err)
 	}
 
-	return c.JSON(fiber.Map{
+	meta["currency"] = currency.Code
+	meta["limit"] = limit
+	if paging != nil {
+		// The cursor is built from base-currency prices, so before localizing
+		n, nextCursor, err := paging.next(len(items), limit, func(i int) interface{} { return items[i] })
+		if err != nil {
+			return apperrors.Internal("Failed to build next cursor", err)
+		}
+		items = items[:n]
+		meta["nextCursor"] = nextCursor
+	} else {
+		meta["page"] = page
+		meta["total"] = total
+		meta["pages"] = (total + int64(limit) - 1) / int64(limit)
+	}
+	for i := range items {
+		items[i].localize(currency, locale)
+		items[i].limitQuantity(h.Config.MaxQuantityPerOrder)
+	}
+
+	// Every visitor loads listings, so let repeat views revalidate cheaply
+	return sendConditionalJSON(c, fiber.Map{
 		"success": true,
 		"message": "Products retrieved successfully",
 		"data":    items,
-		"meta": fiber.Map{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-			"pages": (total + int64(limit) - 1) / int64(limit),
-		},
-	})
+		"meta":    meta,
+	}, time.Time{})
 }
 
 // GetPublicProductByID returns reduced product info for storefront
@@ -426,185 +692,523 @@ func (h *ProductHandler) GetPublicProducts(c *fiber.Ctx) error {
 func (h *ProductHandler) GetPublicProductByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Product ID is required"})
+		return apperrors.BadRequest("Product ID is required", nil)
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", nil)
 	}
+	return h.getPublicProduct(c, bson.M{"_id": objID})
+}
+
+// productAvailability is all a product page or cart widget needs to poll
+type productAvailability struct {
+	Stock       int  `json:"stock"`
+	InStock     bool `json:"inStock"`
+	MaxPerOrder int  `json:"maxPerOrder"` // Most units one order may take; 0 on a pre-order is no limit
+	// Most units one customer may ever buy; unset when unlimited
+	MaxPerCustomer *int `json:"maxPerCustomer,omitempty"`
+	// Orders taken now are pre-orders, which need no stock
+	Preorder    bool       `json:"preorder"`
+	ReleaseDate *time.Time `json:"releaseDate,omitempty"`
+}
+
+// GetProductAvailability returns a product's stock alone, cheap enough to
+// poll. It is cached until the product changes and browsers may reuse it
+// for 15 seconds.
+// GET /catalog/products/:id/availability
+func (h *ProductHandler) GetProductAvailability(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid product ID"})
+		return apperrors.BadRequest("Invalid product ID", nil)
+	}
+
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.ProductsCacheNamespace, map[string]string{"availability": id})
+	var availability productAvailability
+	if err := h.DB.CacheGet(ctx, cacheKey, &availability); err != nil {
+		var product models.Product
+		err := h.DB.Collections().Products.FindOne(ctx, bson.M{"_id": objID}, options.FindOne().SetProjection(bson.M{
+			"stock":            1,
+			"max_per_order":    1,
+			"max_per_customer": 1,
+			"release_date":     1,
+			"allow_preorder":   1,
+		})).Decode(&product)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return apperrors.NotFound("Product not found")
+			}
+			return apperrors.Internal("Failed to fetch product", err)
+		}
+		availability = productAvailability{
+			Stock:          product.Stock,
+			InStock:        product.Stock > 0,
+			MaxPerOrder:    product.Stock,
+			MaxPerCustomer: product.MaxPerCustomer,
+			Preorder:       product.TakesPreorders(time.Now()),
+			ReleaseDate:    product.ReleaseDate,
+		}
+		// Pre-orders take no stock, so only the limit caps them
+		limit := product.EffectiveMaxPerOrder(h.Config.MaxQuantityPerOrder)
+		if availability.Preorder || (limit > 0 && limit < product.Stock) {
+			availability.MaxPerOrder = limit
+		}
+		h.DB.CacheSet(ctx, cacheKey, availability, 5*time.Minute)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=15")
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    availability,
+	})
+}
+
+// GetPublicProductBySlug is GetPublicProductByID for the storefront's clean
+// URLs
+// GET /catalog/products/slug/:slug
+func (h *ProductHandler) GetPublicProductBySlug(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+	if slug == "" {
+		return apperrors.BadRequest("Product slug is required", nil)
+	}
+	return h.getPublicProduct(c, bson.M{"slug": strings.ToLower(slug)})
+}
+
+func (h *ProductHandler) getPublicProduct(c *fiber.Ctx, filter bson.M) error {
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
 	}
+	locale := requestLocale(c, h.Config)
 	collection := h.DB.Collections().Products
-	var doc struct {
-		ID           primitive.ObjectID `bson:"_id" json:"id"`
-		Name         string             `json:"name"`
-		Price        float64            `json:"price"`
-		Images       []string           `json:"images"`
-		Category     string             `json:"category"`
-		Stock        int                `json:"stock"`
-		Brand        string             `json:"brand,omitempty"`
-		MainCategory string             `json:"mainCategory,omitempty"`
-		Subcategory  string             `json:"subcategory,omitempty"`
-		// discount fields
-		DiscountPercentage *float64   `bson:"discount_percentage,omitempty" json:"discountPercentage,omitempty"`
-		DiscountAmount     *float64   `bson:"discount_amount,omitempty" json:"discountAmount,omitempty"`
-		DiscountStartDate  *time.Time `bson:"discount_start_date,omitempty" json:"discountStartDate,omitempty"`
-		DiscountEndDate    *time.Time `bson:"discount_end_date,omitempty" json:"discountEndDate,omitempty"`
-	}
-	err = collection.FindOne(c.Context(), bson.M{"_id": objID}, options.FindOne().SetProjection(bson.M{
-		"name": 1, "price": 1, "images": 1, "category": 1, "stock": 1, "brand": 1, "mainCategory": 1, "subcategory": 1, "description": 1,
-		"discount_percentage": 1, "discount_amount": 1, "discount_start_date": 1, "discount_end_date": 1,
-	})).Decode(&doc)
+	projection := bson.M{"seo": 1, "description": 1}
+	for field, v := range publicProductProjection {
+		projection[field] = v
+	}
+	var doc publicProduct
+	err = collection.FindOne(c.Context(), filter, options.FindOne().SetProjection(projection)).Decode(&doc)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": "Product not found"})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to fetch product", "error": err.Error()})
+		return apperrors.Internal("Failed to fetch product", err)
+	}
+	lowest, err := pricehistory.Lowest(c.Context(), h.DB, models.Product{
+		ID:                 doc.ID,
+		Price:              doc.Price,
+		DiscountPercentage: doc.DiscountPercentage,
+		DiscountAmount:     doc.DiscountAmount,
+		DiscountStartDate:  doc.DiscountStartDate,
+		DiscountEndDate:    doc.DiscountEndDate,
+	}, time.Now().AddDate(0, 0, -lowestPriceWindowDays))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch price history", err)
 	}
-	return c.JSON(fiber.Map{"success": true, "message": "Product retrieved successfully", "data": doc})
+	doc.LowestPrice30Days = &lowest
+	h.recordView(c.Context(), doc.ID)
+	description := models.MetaDescription(doc.Translations.Text(locale, "description", doc.Description))
+	doc.localize(currency, locale)
+	doc.limitQuantity(h.Config.MaxQuantityPerOrder)
+	seo := doc.SEO.WithDefaults(doc.Name, description, storefrontURL(h.Config, storefrontProductPath+doc.Slug))
+	doc.SEO = &seo
+	return sendConditionalJSON(c, fiber.Map{"success": true, "message": "Product retrieved successfully", "data": doc}, time.Time{})
 }
 
-// GetCatalogFilters returns dynamic filter options based on current products and optional category scope
-// GET /catalog/filters?mainCategory=Men&category=Men&subcategory=Chronograph
-func (h *ProductHandler) GetCatalogFilters(c *fiber.Ctx) error {
+// GetRelatedProducts returns in-stock products related to a product: those
+// customers also bought first, then products sharing its category, brand or
+// price band (±25%), best match first. The product itself is never included.
+// GET /catalog/products/:id/related?limit=8
+func (h *ProductHandler) GetRelatedProducts(c *fiber.Ctx) error {
 	ctx := c.Context()
 
-	mainCategory := c.Query("mainCategory")
-	category := c.Query("category")
-	subcategory := c.Query("subcategory")
+	id := c.Params("id")
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", nil)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "8"))
+	if limit < 1 || limit > 24 {
+		limit = 8
+	}
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	locale := requestLocale(c, h.Config)
 
-	filter := bson.M{}
-	if category != "" {
-		filter["category"] = category
-	} else if mainCategory != "" && subcategory != "" {
-		filter["category"] = mainCategory + "/" + subcategory
-	} else if mainCategory != "" {
-		filter["category"] = bson.M{"$regex": fmt.Sprintf("^%s", mainCategory)}
-	}
-
-	// Only project fields needed for filters
-	proj := bson.M{
-		"brand":          1,
-		"gender":         1,
-		"dial_color":     1,
-		"dial_shape":     1,
-		"dial_type":      1,
-		"strap_color":    1,
-		"strap_material": 1,
-		"style":          1,
-		"dial_thickness": 1,
-		"price":          1,
-		"stock":          1,
-	}
-
-	coll := h.DB.Collections().Products
-	cur, err := coll.Find(ctx, filter, options.Find().SetProjection(proj))
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.ProductsCacheNamespace, map[string]string{
+		"related": id,
+		"limit":   strconv.Itoa(limit),
+	})
+	var cached []publicProduct
+	if err := h.DB.CacheGet(ctx, cacheKey, &cached); err == nil {
+		for i := range cached {
+			cached[i].localize(currency, locale)
+			cached[i].limitQuantity(h.Config.MaxQuantityPerOrder)
+		}
+		return c.JSON(fiber.Map{
+			"success": true,
+			"message": "Related products retrieved successfully",
+			"data":    cached,
+			"source":  "cache",
+		})
+	}
+
+	collection := h.DB.Collections().Products
+	var product models.Product
+	err = collection.FindOne(ctx, bson.M{"_id": objID}, options.FindOne().SetProjection(bson.M{
+		"category": 1, "brand": 1, "price": 1,
+	})).Decode(&product)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to fetch filters", "error": err.Error()})
-	}
-	defer cur.Close(ctx)
-
-	type row struct {
-		Brand         string  `bson:"brand"`
-		Gender        string  `bson:"gender"`
-		DialColor     string  `bson:"dial_color"`
-		DialShape     string  `bson:"dial_shape"`
-		DialType      string  `bson:"dial_type"`
-		StrapColor    string  `bson:"strap_color"`
-		StrapMaterial string  `bson:"strap_material"`
-		Style         string  `bson:"style"`
-		DialThickness string  `bson:"dial_thickness"`
-		Price         float64 `bson:"price"`
-		Stock         int     `bson:"stock"`
-	}
-
-	var items []row
-	if err := cur.All(ctx, &items); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to decode filters", "error": err.Error()})
-	}
-
-	// Build unique sets
-	uniq := func() map[string]struct{} { return map[string]struct{}{} }
-	brands := uniq()
-	genders := uniq()
-	dialColors := uniq()
-	dialShapes := uniq()
-	dialTypes := uniq()
-	strapColors := uniq()
-	strapMaterials := uniq()
-	styles := uniq()
-	dialThicknesses := uniq()
-
-	var minPrice, maxPrice float64
-	var havePrice bool
-	inStock := false
-
-	for _, it := range items {
-		if it.Brand != "" {
-			brands[it.Brand] = struct{}{}
-		}
-		if it.Gender != "" {
-			genders[it.Gender] = struct{}{}
-		}
-		if it.DialColor != "" {
-			dialColors[it.DialColor] = struct{}{}
-		}
-		if it.DialShape != "" {
-			dialShapes[it.DialShape] = struct{}{}
-		}
-		if it.DialType != "" {
-			dialTypes[it.DialType] = struct{}{}
-		}
-		if it.StrapColor != "" {
-			strapColors[it.StrapColor] = struct{}{}
-		}
-		if it.StrapMaterial != "" {
-			strapMaterials[it.StrapMaterial] = struct{}{}
-		}
-		if it.Style != "" {
-			styles[it.Style] = struct{}{}
-		}
-		if it.DialThickness != "" {
-			dialThicknesses[it.DialThickness] = struct{}{}
-		}
-		if !havePrice {
-			minPrice, maxPrice, havePrice = it.Price, it.Price, true
-		} else {
-			if it.Price < minPrice {
-				minPrice = it.Price
-			}
-			if it.Price > maxPrice {
-				maxPrice = it.Price
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.Internal("Failed to fetch product", err)
+	}
+
+	related := make([]publicProduct, 0, limit)
+	seen := []primitive.ObjectID{objID}
+
+	// Co-purchase neighbours, strongest first
+	recCursor, err := h.DB.Collections().Recommendations.Find(ctx,
+		bson.M{"source": models.SourceCoPurchase, "basis_product_id": objID},
+		options.Find().SetSort(bson.D{{Key: "score", Value: -1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch related products", err)
+	}
+	var neighbours []models.RecommendationItem
+	if err := recCursor.All(ctx, &neighbours); err != nil {
+		return apperrors.Internal("Failed to decode related products", err)
+	}
+	if len(neighbours) > 0 {
+		ids := make([]primitive.ObjectID, len(neighbours))
+		for i, n := range neighbours {
+			ids[i] = n.ProductID
+		}
+		cursor, err := collection.Find(ctx,
+			bson.M{"_id": bson.M{"$in": ids}, "stock": bson.M{"$gt": 0}},
+			options.Find().SetProjection(publicProductProjection),
+		)
+		if err != nil {
+			return apperrors.Internal("Failed to fetch related products", err)
+		}
+		var bought []publicProduct
+		if err := cursor.All(ctx, &bought); err != nil {
+			return apperrors.Internal("Failed to decode related products", err)
+		}
+		byID := make(map[primitive.ObjectID]publicProduct, len(bought))
+		for _, p := range bought {
+			byID[p.ID] = p
+		}
+		for _, id := range ids {
+			if p, ok := byID[id]; ok {
+				related = append(related, p)
+				seen = append(seen, id)
 			}
 		}
-		if it.Stock > 0 {
-			inStock = true
+	}
+
+	// Fill the rest with attribute matches
+	if remaining := limit - len(related); remaining > 0 {
+		minPrice, maxPrice := product.Price*0.75, product.Price*1.25
+		match := bson.A{
+			bson.M{"category": product.Category},
+			bson.M{"price": bson.M{"$gte": minPrice, "$lte": maxPrice}},
+		}
+		matchScore := bson.A{
+			bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$category", product.Category}}, 3, 0}},
+			bson.M{"$cond": bson.A{bson.M{"$and": bson.A{
+				bson.M{"$gte": bson.A{"$price", minPrice}},
+				bson.M{"$lte": bson.A{"$price", maxPrice}},
+			}}, 1, 0}},
 		}
+		if product.Brand != "" {
+			match = append(match, bson.M{"brand": product.Brand})
+			matchScore = append(matchScore, bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$brand", product.Brand}}, 2, 0}})
+		}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"_id":   bson.M{"$nin": seen},
+				"stock": bson.M{"$gt": 0},
+				"$or":   match,
+			}}},
+			{{Key: "$addFields", Value: bson.M{"related_score": bson.M{"$add": matchScore}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "related_score", Value: -1}, {Key: "created_at", Value: -1}}}},
+			{{Key: "$limit", Value: remaining}},
+			{{Key: "$project", Value: publicProductProjection}},
+		}
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		if err != nil {
+			return apperrors.Internal("Failed to fetch related products", err)
+		}
+		var similar []publicProduct
+		if err := cursor.All(ctx, &similar); err != nil {
+			return apperrors.Internal("Failed to decode related products", err)
+		}
+		related = append(related, similar...)
 	}
 
-	// Convert sets to arrays (sorted optional)
-	toList := func(m map[string]struct{}) []string {
-		out := make([]string, 0, len(m))
-		for k := range m {
-			out = append(out, k)
+	// Cached in the base currency and locale, localized per request
+	h.DB.CacheSet(ctx, cacheKey, related, 30*time.Minute)
+	for i := range related {
+		related[i].localize(currency, locale)
+		related[i].limitQuantity(h.Config.MaxQuantityPerOrder)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Related products retrieved successfully",
+		"data":    related,
+	})
+}
+
+// GetTrendingProducts returns the in-stock products viewed most over the
+// popularity window, optionally within a category, for the home page. Until
+// enough products have views, the newest fill the rest.
+// GET /catalog/trending?limit=12&category=Men
+func (h *ProductHandler) GetTrendingProducts(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	limit, _ := strconv.Atoi(c.Query("limit", "12"))
+	if limit < 1 || limit > 48 {
+		limit = 12
+	}
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	locale := requestLocale(c, h.Config)
+	path := categoryPath(c.Query("category"), c.Query("mainCategory"), c.Query("subcategory"))
+
+	// Popularity changes without product edits bumping the version, so the
+	// list is only cached briefly
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.ProductsCacheNamespace, map[string]string{
+		"trending": path,
+		"limit":    strconv.Itoa(limit),
+	})
+	var trending []publicProduct
+	if err := h.DB.CacheGet(ctx, cacheKey, &trending); err != nil {
+		filter := bson.M{"stock": bson.M{"$gt": 0}}
+		if path != "" {
+			filter["category"] = models.CategorySubtree(path)
+		}
+		cursor, err := h.DB.Collections().Products.Find(ctx, filter, options.Find().
+			SetSort(bson.D{{Key: "popularity", Value: -1}, {Key: "created_at", Value: -1}}).
+			SetLimit(int64(limit)).
+			SetProjection(publicProductProjection))
+		if err != nil {
+			return apperrors.Internal("Failed to retrieve trending products", err)
 		}
-		return out
+		trending = []publicProduct{}
+		if err := cursor.All(ctx, &trending); err != nil {
+			return apperrors.Internal("Failed to decode trending products", err)
+		}
+		// Cached in the base currency and locale, localized per request
+		h.DB.CacheSet(ctx, cacheKey, trending, 5*time.Minute)
+	}
+	for i := range trending {
+		trending[i].localize(currency, locale)
+		trending[i].limitQuantity(h.Config.MaxQuantityPerOrder)
 	}
 
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Trending products retrieved successfully",
+		"data":    trending,
+		"meta":    fiber.Map{"currency": currency.Code},
+	})
+}
+
+// catalogFilterFields are the product fields offered as filter values, by
+// the key they are returned under
+var catalogFilterFields = map[string]string{
+	"brands":           "brand",
+	"genders":          "gender",
+	"dialColors":       "dial_color",
+	"dialShapes":       "dial_shape",
+	"dialTypes":        "dial_type",
+	"strapColors":      "strap_color",
+	"strapMaterials":   "strap_material",
+	"styles":           "style",
+	"dialThicknesses":  "dial_thickness",
+	"movements":        "movement",
+	"waterResistances": "water_resistance",
+	"caseSizes":        "case_size",
+}
+
+// catalogFilters is the filter data of a category scope, cached in the base currency
+type catalogFilters struct {
+	Values   map[string][]string `json:"values"`
+	MinPrice float64             `json:"minPrice"`
+	MaxPrice float64             `json:"maxPrice"`
+	HasStock bool                `json:"hasStock"`
+}
+
+// GetCatalogFilters returns dynamic filter options based on current products and optional category scope
+// GET /catalog/filters?mainCategory=Men&category=Men&subcategory=Chronograph
+func (h *ProductHandler) GetCatalogFilters(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	currency, err := catalogCurrency(c, h.DB)
+	if err != nil {
+		return err
+	}
+	path := categoryPath(c.Query("category"), c.Query("mainCategory"), c.Query("subcategory"))
+
+	// Versioned with the product listings, so product changes invalidate it
+	cacheKey := h.DB.VersionedCacheKey(ctx, database.ProductsCacheNamespace, map[string]string{
+		"filters":  "1",
+		"category": path,
+	})
+	var filters catalogFilters
+	if err := h.DB.CacheGet(ctx, cacheKey, &filters); err != nil {
+		if filters, err = h.buildCatalogFilters(ctx, path); err != nil {
+			return apperrors.Internal("Failed to fetch filters", err)
+		}
+		h.DB.CacheSet(ctx, cacheKey, filters, 15*time.Minute)
+	}
+
+	data := fiber.Map{
+		"minPrice": currency.Convert(filters.MinPrice),
+		"maxPrice": currency.Convert(filters.MaxPrice),
+		"currency": currency.Code,
+		"hasStock": filters.HasStock,
+	}
+	attributes, err := loadProductAttributes(ctx, h.DB)
+	if err != nil {
+		return apperrors.Internal("Failed to load product attributes", err)
+	}
+	attributeKeys := make(map[string]string, len(models.ProductAttributeFields))
+	for key, field := range models.ProductAttributeFields {
+		attributeKeys[field] = key
+	}
+	for key, field := range catalogFilterFields {
+		values := filters.Values[key]
+		if values == nil {
+			values = []string{}
+		}
+		// Attributes with a vocabulary list its values in its order
+		if attribute, ok := attributes[attributeKeys[field]]; ok {
+			values = vocabularyValues(attribute, values)
+		}
+		data[key] = values
+	}
+	list := make([]fiber.Map, 0, len(attributes))
+	for _, attribute := range attributes {
+		list = append(list, fiber.Map{
+			"key":    attribute.Key,
+			"label":  attribute.Label,
+			"values": data[catalogFilterKey(models.ProductAttributeFields[attribute.Key])],
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i]["key"].(string) < list[j]["key"].(string) })
+	data["attributes"] = list
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Filters retrieved",
-		"data": fiber.Map{
-			"brands":          toList(brands),
-			"genders":         toList(genders),
-			"dialColors":      toList(dialColors),
-			"dialShapes":      toList(dialShapes),
-			"dialTypes":       toList(dialTypes),
-			"strapColors":     toList(strapColors),
-			"strapMaterials":  toList(strapMaterials),
-			"styles":          toList(styles),
-			"dialThicknesses": toList(dialThicknesses),
-			"minPrice":        minPrice,
-			"maxPrice":        maxPrice,
-			"hasStock":        inStock,
+		"data":    data,
+	})
+}
+
+// vocabularyValues returns the attribute's allowed values found among the
+// product values, in the vocabulary's order
+func vocabularyValues(attribute models.ProductAttribute, found []string) []string {
+	present := make(map[string]bool, len(found))
+	for _, v := range found {
+		present[strings.ToLower(v)] = true
+	}
+	values := []string{}
+	for _, v := range attribute.Values {
+		if present[strings.ToLower(v)] {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// catalogFilterKey returns the key a product field's filter values are returned under
+func catalogFilterKey(field string) string {
+	for key, f := range catalogFilterFields {
+		if f == field {
+			return key
+		}
+	}
+	return ""
+}
+
+// buildCatalogFilters computes the filter data of a category scope ("" for
+// the whole catalog) in one $facet aggregation: the sorted distinct non-empty
+// values of each filter field, the price range and whether anything is in stock
+func (h *ProductHandler) buildCatalogFilters(ctx context.Context, path string) (catalogFilters, error) {
+	match := bson.M{}
+	if path != "" {
+		match["category"] = models.CategorySubtree(path)
+	}
+
+	facets := bson.M{
+		"summary": bson.A{
+			bson.M{"$group": bson.M{
+				"_id":       nil,
+				"min_price": bson.M{"$min": "$price"},
+				"max_price": bson.M{"$max": "$price"},
+				"has_stock": bson.M{"$max": bson.M{"$gt": bson.A{"$stock", 0}}},
+			}},
 		},
+	}
+	for key, field := range catalogFilterFields {
+		facets[key] = bson.A{
+			bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{"", nil}}}},
+			bson.M{"$group": bson.M{"_id": "$" + field}},
+			bson.M{"$sort": bson.M{"_id": 1}},
+		}
+	}
+
+	cursor, err := h.DB.Collections().Products.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: facets}},
 	})
+	if err != nil {
+		return catalogFilters{}, err
+	}
+	var results []bson.Raw
+	if err := cursor.All(ctx, &results); err != nil {
+		return catalogFilters{}, err
+	}
+
+	filters := catalogFilters{Values: make(map[string][]string, len(catalogFilterFields))}
+	if len(results) == 0 {
+		return filters, nil
+	}
+	var out struct {
+		Summary []struct {
+			MinPrice float64 `bson:"min_price"`
+			MaxPrice float64 `bson:"max_price"`
+			HasStock bool    `bson:"has_stock"`
+		} `bson:"summary"`
+	}
+	if err := bson.Unmarshal(results[0], &out); err != nil {
+		return catalogFilters{}, err
+	}
+	if len(out.Summary) > 0 {
+		filters.MinPrice, filters.MaxPrice, filters.HasStock = out.Summary[0].MinPrice, out.Summary[0].MaxPrice, out.Summary[0].HasStock
+	}
+	for key := range catalogFilterFields {
+		var groups []struct {
+			Value string `bson:"_id"`
+		}
+		if err := results[0].Lookup(key).Unmarshal(&groups); err != nil {
+			return catalogFilters{}, err
+		}
+		values := make([]string, 0, len(groups))
+		for _, g := range groups {
+			values = append(values, g.Value)
+		}
+		filters.Values[key] = values
+	}
+	return filters, nil
 }
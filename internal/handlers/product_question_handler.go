@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+)
+
+// ProductQuestionHandler handles customer questions about products and the
+// store's answers
+type ProductQuestionHandler struct {
+	DB *database.DBClient
+}
+
+// NewProductQuestionHandler creates a new instance of ProductQuestionHandler
+func NewProductQuestionHandler(db *database.DBClient) *ProductQuestionHandler {
+	return &ProductQuestionHandler{DB: db}
+}
+
+// AskQuestion posts a question about a product
+// POST /products/:productId/questions
+func (h *ProductQuestionHandler) AskQuestion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	productID, err := parseObjectID(c.Params("productId"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+	var req models.ProductQuestionRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	n, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"_id": productID})
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve product", err)
+	}
+	if n == 0 {
+		return apperrors.NotFound("Product not found")
+	}
+
+	now := time.Now()
+	question := models.ProductQuestion{
+		ID:        primitive.NewObjectID(),
+		ProductID: productID,
+		UserID:    user.UserID,
+		Question:  req.Question,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := h.DB.Collections().ProductQuestions.InsertOne(ctx, question); err != nil {
+		return apperrors.Internal("Failed to save question", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Question submitted successfully",
+		"data":    question,
+	})
+}
+
+// GetProductQuestions lists a product's questions, most upvoted first
+// GET /products/:productId/questions?answered=true&sort=top|newest&page=1&limit=10
+func (h *ProductQuestionHandler) GetProductQuestions(c *fiber.Ctx) error {
+	productID, err := parseObjectID(c.Params("productId"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+
+	filter := bson.M{"product_id": productID}
+	if c.QueryBool("answered") {
+		filter["answered_at"] = bson.M{"$exists": true}
+	}
+	sort := bson.D{{Key: "upvotes", Value: -1}, {Key: "created_at", Value: -1}}
+	if c.Query("sort") == "newest" {
+		sort = bson.D{{Key: "created_at", Value: -1}}
+	}
+	return h.listQuestions(c, filter, sort, 10)
+}
+
+// GetQuestions lists questions for moderation, unanswered ones with
+// ?answered=false
+// GET /admin/questions?answered=false&productId=&page=1&limit=20
+func (h *ProductQuestionHandler) GetQuestions(c *fiber.Ctx) error {
+	filter := bson.M{}
+	switch c.Query("answered") {
+	case "true":
+		filter["answered_at"] = bson.M{"$exists": true}
+	case "false":
+		filter["answered_at"] = bson.M{"$exists": false}
+	}
+	if productID := c.Query("productId"); productID != "" {
+		objectID, err := parseObjectID(productID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid product ID", err)
+		}
+		filter["product_id"] = objectID
+	}
+	// Oldest first, so the longest-waiting questions are answered first
+	return h.listQuestions(c, filter, bson.D{{Key: "created_at", Value: 1}}, 20)
+}
+
+func (h *ProductQuestionHandler) listQuestions(c *fiber.Ctx, filter bson.M, sort bson.D, defaultLimit int) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	if limit < 1 || limit > 100 {
+		limit = defaultLimit
+	}
+
+	coll := h.DB.Collections().ProductQuestions
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count questions", err)
+	}
+
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch questions", err)
+	}
+	defer cursor.Close(ctx)
+
+	var questions []models.ProductQuestion
+	if err := cursor.All(ctx, &questions); err != nil {
+		return apperrors.Internal("Failed to decode questions", err)
+	}
+	response, err := h.withUserNames(ctx, questions)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve user details", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Questions retrieved successfully",
+		"data":    response,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// withUserNames adds the asker's name to each question
+func (h *ProductQuestionHandler) withUserNames(ctx context.Context, questions []models.ProductQuestion) ([]models.ProductQuestionResponse, error) {
+	userIDs := make([]primitive.ObjectID, 0, len(questions))
+	for _, q := range questions {
+		userIDs = append(userIDs, q.UserID)
+	}
+	names := make(map[primitive.ObjectID]string, len(userIDs))
+	if len(userIDs) > 0 {
+		cursor, err := h.DB.Collections().Users.Find(ctx,
+			bson.M{"_id": bson.M{"$in": userIDs}},
+			options.Find().SetProjection(bson.M{"name": 1}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		var users []models.User
+		if err := cursor.All(ctx, &users); err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	}
+
+	response := make([]models.ProductQuestionResponse, 0, len(questions))
+	for _, q := range questions {
+		name, ok := names[q.UserID]
+		if !ok || name == "" {
+			name = "Anonymous"
+		}
+		response = append(response, models.ProductQuestionResponse{ProductQuestion: q, UserName: name})
+	}
+	return response, nil
+}
+
+// UpvoteQuestion records the current user's upvote; repeating it has no
+// effect
+// POST /questions/:id/upvote
+func (h *ProductQuestionHandler) UpvoteQuestion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid question ID", err)
+	}
+
+	// The filter only matches users who haven't upvoted yet, so the count
+	// and the voter list stay in step
+	var question models.ProductQuestion
+	err = h.DB.Collections().ProductQuestions.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "upvoted_by": bson.M{"$ne": user.UserID}},
+		bson.M{"$inc": bson.M{"upvotes": 1}, "$push": bson.M{"upvoted_by": user.UserID}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&question)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		question, err = h.find(ctx, objectID)
+	}
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return err
+		}
+		return apperrors.Internal("Failed to upvote question", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Question upvoted",
+		"data":    fiber.Map{"upvotes": question.Upvotes},
+	})
+}
+
+// AnswerQuestion sets or replaces the store's answer and notifies the asker
+// the first time a question is answered
+// PUT /admin/questions/:id/answer
+func (h *ProductQuestionHandler) AnswerQuestion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	admin, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid question ID", err)
+	}
+	var req models.ProductAnswerRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var before models.ProductQuestion
+	err = h.DB.Collections().ProductQuestions.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"answer":      req.Answer,
+			"answered_by": admin.UserID,
+			"answered_at": now,
+			"updated_at":  now,
+		}},
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Question not found")
+		}
+		return apperrors.Internal("Failed to save answer", err)
+	}
+
+	if before.AnsweredAt == nil {
+		err := notify.Create(ctx, h.DB, models.Notification{
+			UserID:      before.UserID,
+			Type:        notify.TypeProduct,
+			Title:       "Your question was answered",
+			Message:     req.Answer,
+			ReferenceID: before.ProductID,
+		})
+		if err != nil {
+			log.Printf("[NOTIFY] Failed to notify user %s of answer to question %s: %v", before.UserID.Hex(), objectID.Hex(), err)
+		}
+	}
+
+	updated, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, h.DB.MongoDB, "question.answer", "product_question", objectID.Hex(),
+		bson.M{"answer": before.Answer}, bson.M{"answer": updated.Answer})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Answer saved successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteQuestion removes a question, e.g. spam or one that isn't about the
+// product
+// DELETE /admin/questions/:id
+func (h *ProductQuestionHandler) DeleteQuestion(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid question ID", err)
+	}
+	var deleted models.ProductQuestion
+	if err := h.DB.Collections().ProductQuestions.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&deleted); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Question not found")
+		}
+		return apperrors.Internal("Failed to delete question", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "question.delete", "product_question", objectID.Hex(), deleted, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Question deleted successfully",
+	})
+}
+
+func (h *ProductQuestionHandler) find(ctx context.Context, id primitive.ObjectID) (models.ProductQuestion, error) {
+	var question models.ProductQuestion
+	if err := h.DB.Collections().ProductQuestions.FindOne(ctx, bson.M{"_id": id}).Decode(&question); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return question, apperrors.NotFound("Question not found")
+		}
+		return question, apperrors.Internal("Failed to retrieve question", err)
+	}
+	return question, nil
+}
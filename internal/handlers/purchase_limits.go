@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// checkPurchaseLimits returns a purchase_limit error when one order of
+// quantity units of product would break its per-order limit, or take the
+// user past its per-customer limit counting their earlier orders
+func checkPurchaseLimits(ctx context.Context, db *database.DBClient, cfg *config.Config, userID primitive.ObjectID, product *models.Product, quantity int) error {
+	if limit := product.EffectiveMaxPerOrder(cfg.MaxQuantityPerOrder); limit > 0 && quantity > limit {
+		return apperrors.BadRequest(fmt.Sprintf("At most %d of %s can be ordered at once", limit, product.Name), nil).
+			WithCode(apperrors.CodePurchaseLimit)
+	}
+	if product.MaxPerCustomer == nil || *product.MaxPerCustomer <= 0 {
+		return nil
+	}
+	bought, err := unitsBought(ctx, db, userID, product.ID)
+	if err != nil {
+		return apperrors.Internal("Failed to check purchase limits", err)
+	}
+	left := *product.MaxPerCustomer - bought
+	if quantity <= left {
+		return nil
+	}
+	if left <= 0 {
+		return apperrors.BadRequest(fmt.Sprintf("%s is limited to %d per customer and you have bought them all", product.Name, *product.MaxPerCustomer), nil).
+			WithCode(apperrors.CodePurchaseLimit)
+	}
+	return apperrors.BadRequest(fmt.Sprintf("%s is limited to %d per customer; you can buy %d more", product.Name, *product.MaxPerCustomer, left), nil).
+		WithCode(apperrors.CodePurchaseLimit)
+}
+
+// unitsBought counts the units of a product in the user's orders, except
+// cancelled and returned ones
+func unitsBought(ctx context.Context, db *database.DBClient, userID, productID primitive.ObjectID) (int, error) {
+	cursor, err := db.Collections().Orders.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id":          userID,
+			"items.product_id": productID,
+			"status":           bson.M{"$nin": bson.A{orderstatus.Cancelled, orderstatus.Returned}},
+		}}},
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$match", Value: bson.M{"items.product_id": productID}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "units": bson.M{"$sum": "$items.quantity"}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var totals []struct {
+		Units int `bson:"units"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return 0, err
+	}
+	if len(totals) == 0 {
+		return 0, nil
+	}
+	return totals[0].Units, nil
+}
+
+// cartUnits counts the units of a product across the sizes in a user's cart
+func cartUnits(ctx context.Context, db *database.DBClient, userID, productID primitive.ObjectID) (int, error) {
+	cursor, err := db.Collections().CartItems.Find(ctx, bson.M{"user_id": userID, "product_id": productID})
+	if err != nil {
+		return 0, err
+	}
+	var lines []models.CartItem
+	if err := cursor.All(ctx, &lines); err != nil {
+		return 0, err
+	}
+	units := 0
+	for _, line := range lines {
+		units += line.Quantity
+	}
+	return units, nil
+}
+
+// positiveOrNil drops a limit of 0 or less, which means no limit
+func positiveOrNil(limit *int) *int {
+	if limit == nil || *limit <= 0 {
+		return nil
+	}
+	return limit
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/realtime"
+)
+
+// sseKeepAlive is how often an idle stream sends a comment so proxies don't
+// close it
+const sseKeepAlive = 25 * time.Second
+
+// RealtimeHandler streams live events to admin dashboards
+type RealtimeHandler struct {
+	Hub *realtime.Hub
+}
+
+// NewRealtimeHandler creates a new instance of RealtimeHandler
+func NewRealtimeHandler(hub *realtime.Hub) *RealtimeHandler {
+	return &RealtimeHandler{Hub: hub}
+}
+
+// StreamAdminEvents is a Server-Sent Events stream of new orders, captured
+// payments and support ticket messages. Each message's event name is the
+// event type and its data is the JSON event.
+// GET /admin/events
+func (h *RealtimeHandler) StreamAdminEvents(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+
+	messages, unsubscribe := h.Hub.Subscribe()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		// Tell EventSource how long to wait before reconnecting
+		fmt.Fprint(w, "retry: 5000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			// Flush fails once the client has gone away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
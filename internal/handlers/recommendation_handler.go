@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,16 +13,20 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/flags"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
 )
 
 // RecommendationHandler handles product recommendations
 type RecommendationHandler struct {
 	DB     *database.DBClient
 	Config *config.Config
+	Flags  *flags.Service
 }
 
 // NewRecommendationHandler creates a new instance of RecommendationHandler
@@ -27,82 +34,138 @@ func NewRecommendationHandler(db *database.DBClient, cfg *config.Config) *Recomm
 	return &RecommendationHandler{
 		DB:     db,
 		Config: cfg,
+		Flags:  &flags.Service{DB: db},
 	}
 }
 
 // GetRecommendations returns product recommendations for the current user
+// GET /recommendations?strategy=hybrid|collaborative|preferences&limit=10
+//
+// collaborative ranks products by co-purchase scores from the user's orders
+// and feedback; hybrid re-ranks those scores with the user's preferences.
+// Both fall back to preference filters when the user has no usable history,
+// and are served as preferences to users outside the
+// recommendations.collaborative flag's rollout.
 func (h *RecommendationHandler) GetRecommendations(c *fiber.Ctx) error {
 	ctx := c.Context()
 
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	strategy := c.Query("strategy", models.StrategyHybrid)
+	switch strategy {
+	case models.StrategyHybrid, models.StrategyCollaborative, models.StrategyPreferences:
+	default:
+		return apperrors.BadRequest("strategy must be one of hybrid, collaborative or preferences", nil)
+	}
+	// Co-purchase strategies are rolled out behind a flag
+	if strategy != models.StrategyPreferences && !h.Flags.Enabled(ctx, models.FlagCollaborativeRecommendations, user.UserID) {
+		strategy = models.StrategyPreferences
+	}
+
+	// Query parameters
+	limit := 10
+	if c.Query("limit") != "" {
+		fmt.Sscanf(c.Query("limit"), "%d", &limit)
+		if limit <= 0 || limit > 50 {
+			limit = 10
+		}
 	}
 
 	// Try to get recommendations from cache
-	cacheKey := fmt.Sprintf("recommendations:%s", user.UserID.Hex())
+	cacheKey := recommendationsCacheKey(user.UserID, strategy, limit)
 	var cachedRecommendations []fiber.Map
 	err := h.DB.CacheGet(ctx, cacheKey, &cachedRecommendations)
 	if err == nil && len(cachedRecommendations) > 0 {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"success": true,
-			"message": "Recommendations retrieved from cache",
-			"data":    cachedRecommendations,
-			"source":  "cache",
+			"success":  true,
+			"message":  "Recommendations retrieved from cache",
+			"data":     cachedRecommendations,
+			"source":   "cache",
+			"strategy": strategy,
 		})
 	}
 
 	// Get user preferences
+	var prefs *models.UserPreferences
 	var userPrefs models.UserPreferences
-	prefsCollection := h.DB.Collections().UserPreferences
-	err = prefsCollection.FindOne(ctx, bson.M{"user_id": user.UserID}).Decode(&userPrefs)
+	if err := h.DB.Collections().UserPreferences.FindOne(ctx, bson.M{"user_id": user.UserID}).Decode(&userPrefs); err == nil {
+		prefs = &userPrefs
+	}
 
-	// Query parameters
-	limit := 10
-	if c.Query("limit") != "" {
-		fmt.Sscanf(c.Query("limit"), "%d", &limit)
-		if limit <= 0 || limit > 50 {
-			limit = 10
+	var recommendations []fiber.Map
+	var source string
+	if strategy != models.StrategyPreferences {
+		recommendations, err = h.collaborativeRecommendations(ctx, user.UserID, prefs, strategy == models.StrategyHybrid, limit)
+		if err != nil {
+			return apperrors.Internal("Failed to retrieve recommendations", err)
+		}
+		source = strategy
+	}
+	if len(recommendations) == 0 {
+		var products []models.Product
+		products, source, err = h.preferenceRecommendations(ctx, prefs, limit)
+		if err != nil {
+			return err
 		}
+		recommendations = buildRecommendationsResponse(products)
 	}
 
+	// Cache the results
+	h.DB.CacheSet(ctx, cacheKey, recommendations, 30*time.Minute)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success":  true,
+		"message":  "Recommendations retrieved successfully",
+		"data":     recommendations,
+		"source":   source,
+		"strategy": strategy,
+	})
+}
+
+// byPopularity ranks recommended products by recent views, newest first
+// among products nobody viewed
+var byPopularity = bson.D{{Key: "popularity", Value: -1}, {Key: "created_at", Value: -1}}
+
+// preferenceRecommendations filters in-stock products by the user's favourite
+// categories, brands and price range, most popular first. Without
+// preferences, or when nothing matches, it returns the most popular
+// products. The second return value is "personalized" when preferences
+// shaped the result.
+func (h *RecommendationHandler) preferenceRecommendations(ctx context.Context, prefs *models.UserPreferences, limit int) ([]models.Product, string, error) {
 	// Set up recommendation query
 	productCollection := h.DB.Collections().Products
-	findOptions := options.Find().SetLimit(int64(limit))
+	findOptions := options.Find().SetLimit(int64(limit)).SetSort(byPopularity)
 
 	// Base query - get products with sufficient stock
 	query := bson.M{"stock": bson.M{"$gt": 0}}
 
 	// Add preference-based filters if available
-	if err == nil {
-		// If we have user preferences, use them to filter recommendations
-
+	if prefs != nil {
 		// Filter by categories if user has favorite categories
-		if len(userPrefs.FavoriteCategories) > 0 {
-			query["category"] = bson.M{"$in": userPrefs.FavoriteCategories}
+		if len(prefs.FavoriteCategories) > 0 {
+			query["category"] = bson.M{"$in": prefs.FavoriteCategories}
 		}
 
 		// Filter by price range if set
-		if len(userPrefs.PriceRange) == 2 {
+		if len(prefs.PriceRange) == 2 {
 			query["price"] = bson.M{
-				"$gte": userPrefs.PriceRange[0],
-				"$lte": userPrefs.PriceRange[1],
+				"$gte": prefs.PriceRange[0],
+				"$lte": prefs.PriceRange[1],
 			}
 		}
 
-		// Set sort order - newest products first, but give priority to favorite brands if available
-		if len(userPrefs.FavoriteBrands) > 0 {
-			// Add score field for sorting
+		// Most popular products first, but give priority to favorite brands if available
+		if len(prefs.FavoriteBrands) > 0 {
 			pipeline := []bson.M{
 				{
 					"$addFields": bson.M{
 						"brandScore": bson.M{
 							"$cond": bson.M{
-								"if":   bson.M{"$in": []interface{}{"$brand", userPrefs.FavoriteBrands}},
+								"if":   bson.M{"$in": []interface{}{"$brand", prefs.FavoriteBrands}},
 								"then": 1,
 								"else": 0,
 							},
@@ -112,106 +175,233 @@ func (h *RecommendationHandler) GetRecommendations(c *fiber.Ctx) error {
 				{"$match": query},
 				{"$sort": bson.D{
 					{Key: "brandScore", Value: -1},
+					{Key: "popularity", Value: -1},
 					{Key: "created_at", Value: -1},
 				}},
 				{"$limit": limit},
 			}
 
-			// Execute aggregation pipeline
-			cursor, err := productCollection.Aggregate(ctx, pipeline)
-			if err != nil {
-				// Fall back to regular query if aggregation fails
-				findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
-			} else {
+			// Fall back to the regular query if aggregation fails
+			if cursor, err := productCollection.Aggregate(ctx, pipeline); err == nil {
 				defer cursor.Close(ctx)
 
-				// Decode results
 				var products []models.Product
 				if err := cursor.All(ctx, &products); err != nil {
-					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-						"success": false,
-						"message": "Failed to decode recommendations",
-						"error":   err.Error(),
-					})
+					return nil, "", apperrors.Internal("Failed to decode recommendations", err)
+				}
+				if len(products) > 0 {
+					return products, "personalized", nil
 				}
-
-				// Build response
-				recommendations := buildRecommendationsResponse(products)
-
-				// Cache the results
-				h.DB.CacheSet(ctx, cacheKey, recommendations, 30*60) // 30 minutes
-
-				return c.Status(fiber.StatusOK).JSON(fiber.Map{
-					"success": true,
-					"message": "Personalized recommendations retrieved successfully",
-					"data":    recommendations,
-					"source":  "personalized",
-				})
 			}
 		}
-	} else {
-		// If we don't have user preferences, just sort by popularity and creation date
-		findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
 	}
 
 	// Execute query
 	cursor, err := productCollection.Find(ctx, query, findOptions)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve recommendations",
-			"error":   err.Error(),
-		})
+		return nil, "", apperrors.Internal("Failed to retrieve recommendations", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode results
 	var products []models.Product
 	if err := cursor.All(ctx, &products); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode recommendations",
-			"error":   err.Error(),
-		})
+		return nil, "", apperrors.Internal("Failed to decode recommendations", err)
+	}
+	if len(products) > 0 {
+		if prefs != nil {
+			return products, "personalized", nil
+		}
+		return products, "general", nil
 	}
 
 	// If no products found based on preferences, get popular products
-	if len(products) == 0 {
-		cursor, err = productCollection.Find(
-			ctx,
-			bson.M{"stock": bson.M{"$gt": 0}},
-			options.Find().SetLimit(int64(limit)).SetSort(bson.D{{Key: "created_at", Value: -1}}),
-		)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to retrieve popular products",
-				"error":   err.Error(),
-			})
+	cursor, err = productCollection.Find(
+		ctx,
+		bson.M{"stock": bson.M{"$gt": 0}},
+		options.Find().SetLimit(int64(limit)).SetSort(byPopularity),
+	)
+	if err != nil {
+		return nil, "", apperrors.Internal("Failed to retrieve popular products", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, "", apperrors.Internal("Failed to decode popular products", err)
+	}
+	return products, "general", nil
+}
+
+// collaborativeRecommendations ranks products by the co-purchase scores of
+// everything the user ordered or showed interest in. Products the user
+// already bought or dismissed are skipped. With blend set, scores are mixed
+// with how well each product matches the user's preferences. It returns nil
+// when the user has no history or no scores exist yet.
+func (h *RecommendationHandler) collaborativeRecommendations(ctx context.Context, userID primitive.ObjectID, prefs *models.UserPreferences, blend bool, limit int) ([]fiber.Map, error) {
+	seeds := make(map[primitive.ObjectID]bool)
+	exclude := make(map[primitive.ObjectID]bool)
+
+	orders, err := h.DB.Collections().Orders.Find(ctx,
+		bson.M{"user_id": userID, "status": bson.M{"$ne": orderstatus.Cancelled}},
+		options.Find().SetProjection(bson.M{"items.product_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var ordered []models.Order
+	if err := orders.All(ctx, &ordered); err != nil {
+		return nil, err
+	}
+	for _, order := range ordered {
+		for _, item := range order.Items {
+			seeds[item.ProductID] = true
+			exclude[item.ProductID] = true
 		}
-		defer cursor.Close(ctx)
-
-		if err := cursor.All(ctx, &products); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to decode popular products",
-				"error":   err.Error(),
-			})
+	}
+
+	feedback, err := h.DB.Collections().RecFeedbacks.Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"product_id": 1, "action": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var feedbacks []models.RecommendationFeedback
+	if err := feedback.All(ctx, &feedbacks); err != nil {
+		return nil, err
+	}
+	for _, fb := range feedbacks {
+		switch fb.Action {
+		case "dismiss":
+			exclude[fb.ProductID] = true
+		case "purchase":
+			seeds[fb.ProductID] = true
+			exclude[fb.ProductID] = true
+		default:
+			seeds[fb.ProductID] = true
 		}
 	}
+	if len(seeds) == 0 {
+		return nil, nil
+	}
 
-	// Build response
-	recommendations := buildRecommendationsResponse(products)
+	seedIDs := make([]primitive.ObjectID, 0, len(seeds))
+	for id := range seeds {
+		seedIDs = append(seedIDs, id)
+	}
+	cursor, err := h.DB.Collections().Recommendations.Find(ctx, bson.M{
+		"source":           models.SourceCoPurchase,
+		"basis_product_id": bson.M{"$in": seedIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var neighbours []models.RecommendationItem
+	if err := cursor.All(ctx, &neighbours); err != nil {
+		return nil, err
+	}
 
-	// Cache the results
-	h.DB.CacheSet(ctx, cacheKey, recommendations, 30*60) // 30 minutes
+	scores := make(map[primitive.ObjectID]float64)
+	maxScore := 0.0
+	for _, n := range neighbours {
+		if exclude[n.ProductID] {
+			continue
+		}
+		scores[n.ProductID] += n.Score
+		if scores[n.ProductID] > maxScore {
+			maxScore = scores[n.ProductID]
+		}
+	}
+	if len(scores) == 0 {
+		return nil, nil
+	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Recommendations retrieved successfully",
-		"data":    recommendations,
-		"source":  "general",
+	candidateIDs := make([]primitive.ObjectID, 0, len(scores))
+	for id := range scores {
+		candidateIDs = append(candidateIDs, id)
+	}
+	cursor, err = h.DB.Collections().Products.Find(ctx, bson.M{
+		"_id":   bson.M{"$in": candidateIDs},
+		"stock": bson.M{"$gt": 0},
 	})
+	if err != nil {
+		return nil, err
+	}
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	ranked := make([]float64, len(products))
+	for i, p := range products {
+		score := scores[p.ID] / maxScore
+		if blend && prefs != nil {
+			score = 0.7*score + 0.3*preferenceMatch(p, prefs)
+		}
+		ranked[i] = score
+	}
+	order := make([]int, len(products))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ranked[order[a]] > ranked[order[b]] })
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	recommendations := make([]fiber.Map, 0, len(order))
+	for _, i := range order {
+		rec := buildRecommendationsResponse(products[i : i+1])[0]
+		rec["score"] = ranked[i]
+		rec["source"] = models.SourceCoPurchase
+		rec["reason"] = "Customers who bought items you like also bought this"
+		recommendations = append(recommendations, rec)
+	}
+	return recommendations, nil
+}
+
+// preferenceMatch returns the share of the user's set preferences (category,
+// brand, price range) that a product satisfies, from 0 to 1
+func preferenceMatch(p models.Product, prefs *models.UserPreferences) float64 {
+	set, matched := 0, 0
+	if len(prefs.FavoriteCategories) > 0 {
+		set++
+		for _, c := range prefs.FavoriteCategories {
+			if strings.EqualFold(c, p.Category) {
+				matched++
+				break
+			}
+		}
+	}
+	if len(prefs.FavoriteBrands) > 0 {
+		set++
+		for _, b := range prefs.FavoriteBrands {
+			if strings.EqualFold(b, p.Brand) {
+				matched++
+				break
+			}
+		}
+	}
+	if len(prefs.PriceRange) == 2 {
+		set++
+		if p.Price >= prefs.PriceRange[0] && p.Price <= prefs.PriceRange[1] {
+			matched++
+		}
+	}
+	if set == 0 {
+		return 0
+	}
+	return float64(matched) / float64(set)
+}
+
+// recommendationsCacheKey is the cache entry for one strategy and page size
+func recommendationsCacheKey(userID primitive.ObjectID, strategy string, limit int) string {
+	return fmt.Sprintf("recommendations:%s:%s:%d", userID.Hex(), strategy, limit)
+}
+
+// invalidateRecommendations drops every cached recommendation list for a user
+func invalidateRecommendations(ctx context.Context, db *database.DBClient, userID primitive.ObjectID) {
+	_ = db.CacheDelPattern(ctx, fmt.Sprintf("recommendations:%s:*", userID.Hex()))
 }
 
 // SubmitFeedback records user feedback for recommendations
@@ -221,10 +411,7 @@ func (h *RecommendationHandler) SubmitFeedback(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse request body
@@ -235,20 +422,13 @@ func (h *RecommendationHandler) SubmitFeedback(c *fiber.Ctx) error {
 	}
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
 	// Convert string ID to ObjectID
 	productID, err := primitive.ObjectIDFromHex(req.ProductID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID",
-		})
+		return apperrors.BadRequest("Invalid product ID", nil)
 	}
 
 	// Check if product exists
@@ -257,16 +437,9 @@ func (h *RecommendationHandler) SubmitFeedback(c *fiber.Ctx) error {
 	err = productCollection.FindOne(ctx, bson.M{"_id": productID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check product", err)
 	}
 
 	// Record feedback
@@ -281,16 +454,12 @@ func (h *RecommendationHandler) SubmitFeedback(c *fiber.Ctx) error {
 
 	_, err = h.DB.Collections().RecFeedbacks.InsertOne(ctx, feedback)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to save feedback",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to save feedback", err)
 	}
 
-	// Update recommendation model (just clear the cache for now, could be more sophisticated)
-	cacheKey := fmt.Sprintf("recommendations:%s", user.UserID.Hex())
-	h.DB.CacheDel(ctx, cacheKey)
+	// Feedback changes the user's collaborative seeds; co-purchase scores
+	// themselves are rebuilt by the scheduled job
+	invalidateRecommendations(ctx, h.DB, user.UserID)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
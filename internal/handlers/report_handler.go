@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// ReportHandler compiles the sales digests on demand
+type ReportHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+	Queue  *jobs.Queue
+}
+
+// NewReportHandler creates a new instance of ReportHandler
+func NewReportHandler(db *database.DBClient, cfg *config.Config, queue *jobs.Queue) *ReportHandler {
+	return &ReportHandler{DB: db, Config: cfg, Queue: queue}
+}
+
+// RunReport compiles the digest for the last complete period and emails it
+// to the recipients in the store settings, whether or not scheduled digests
+// are enabled. With send=false it is only returned. Scheduled sending is
+// unaffected.
+// POST /admin/reports/run?period=daily|weekly&send=true
+func (h *ReportHandler) RunReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	period := c.Query("period", models.ReportDaily)
+	if period != models.ReportDaily && period != models.ReportWeekly {
+		return apperrors.BadRequest("period must be daily or weekly", nil)
+	}
+	loc, err := time.LoadLocation(h.Config.ReportTimezone)
+	if err != nil {
+		return apperrors.Internal("Invalid REPORT_TIMEZONE", err)
+	}
+	send := c.QueryBool("send", true)
+
+	var recipients []string
+	if send {
+		settings, err := jobs.LoadReportSettings(ctx, h.DB)
+		if err != nil {
+			return apperrors.Internal("Failed to load report settings", err)
+		}
+		if len(settings.Recipients) == 0 {
+			return apperrors.BadRequest("No report recipients are set in the store settings", nil)
+		}
+		recipients = settings.Recipients
+	}
+
+	from, to := jobs.ReportPeriod(period, time.Now().In(loc))
+	digest, err := jobs.CompileDigest(ctx, h.DB, h.Config, period, from, to)
+	if err != nil {
+		return apperrors.Internal("Failed to compile report", err)
+	}
+	if send {
+		if err := jobs.QueueDigest(ctx, h.Queue, digest, recipients); err != nil {
+			return apperrors.Internal("Failed to queue report emails", err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Report compiled successfully",
+		"data":    digest,
+		"meta":    fiber.Map{"sentTo": recipients},
+	})
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// RetentionHandler reports on the data retention job
+type RetentionHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewRetentionHandler creates a new instance of RetentionHandler
+func NewRetentionHandler(db *database.DBClient, cfg *config.Config) *RetentionHandler {
+	return &RetentionHandler{DB: db, Config: cfg}
+}
+
+// retentionPolicyStatus is a retention policy with what it has purged
+type retentionPolicyStatus struct {
+	jobs.RetentionPolicy
+	Stats *models.RetentionStats `json:"stats"`
+}
+
+// GetRetention lists the retention policies and how much each has purged,
+// null for collections never pruned
+// GET /admin/retention
+func (h *RetentionHandler) GetRetention(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	cursor, err := h.DB.Collections().RetentionStats.Find(ctx, bson.M{})
+	if err != nil {
+		return apperrors.Internal("Failed to fetch retention stats", err)
+	}
+	var stats []models.RetentionStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return apperrors.Internal("Failed to decode retention stats", err)
+	}
+	byCollection := make(map[string]*models.RetentionStats, len(stats))
+	for i := range stats {
+		byCollection[stats[i].Collection] = &stats[i]
+	}
+
+	policies := jobs.RetentionPolicies(h.Config)
+	statuses := make([]retentionPolicyStatus, len(policies))
+	for i, policy := range policies {
+		statuses[i] = retentionPolicyStatus{RetentionPolicy: policy, Stats: byCollection[policy.Collection]}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Retention policies retrieved successfully",
+		"data": fiber.Map{
+			"enabled":       h.Config.RetentionIntervalHours > 0,
+			"intervalHours": h.Config.RetentionIntervalHours,
+			"policies":      statuses,
+		},
+	})
+}
@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// maxReturnPhotos is how many photos can be attached to one return
+const maxReturnPhotos = 5
+
+// ReturnHandler handles return requests and their processing by admins
+type ReturnHandler struct {
+	DB      *database.DBClient
+	Config  *config.Config
+	Storage storage.Storage
+	Events  *events.Bus // Optional
+}
+
+// NewReturnHandler creates a new instance of ReturnHandler
+func NewReturnHandler(db *database.DBClient, cfg *config.Config) *ReturnHandler {
+	return &ReturnHandler{DB: db, Config: cfg}
+}
+
+// returnLineKey identifies an order line; the same product may appear in
+// several sizes
+func returnLineKey(productID primitive.ObjectID, size string) string {
+	return productID.Hex() + "|" + size
+}
+
+// CreateReturn requests the return of some or all items of a delivered order
+// POST /orders/:orderID/return
+func (h *ReturnHandler) CreateReturn(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	orderID, err := parseObjectID(c.Params("orderID"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid order ID format", err)
+	}
+	var req models.CreateReturnRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var order models.Order
+	if err := h.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Order not found")
+		}
+		return apperrors.Internal("Failed to retrieve order", err)
+	}
+	if order.UserID != user.UserID {
+		return apperrors.Forbidden("Not authorized to return this order")
+	}
+	if order.Status != orderstatus.Delivered {
+		return apperrors.Conflict("Only delivered orders can be returned")
+	}
+	if h.Config.ReturnWindowDays > 0 {
+		// Orders delivered before delivery dates were recorded fall back to
+		// their last update, which is when they were marked delivered
+		deliveredAt := order.UpdatedAt
+		if order.DeliveredAt != nil {
+			deliveredAt = *order.DeliveredAt
+		}
+		if time.Now().After(deliveredAt.AddDate(0, 0, h.Config.ReturnWindowDays)) {
+			return apperrors.BadRequest(fmt.Sprintf("Returns must be requested within %d days of delivery", h.Config.ReturnWindowDays), nil)
+		}
+	}
+	for _, photoURL := range req.PhotoURLs {
+		if _, ok := h.Storage.KeyFromURL(photoURL); !ok {
+			return apperrors.BadRequest("Photos must be uploaded with POST /returns/photos", nil)
+		}
+	}
+
+	// Quantities already covered by other returns, unless those were rejected
+	returned, err := h.returnedQuantities(ctx, orderID)
+	if err != nil {
+		return apperrors.Internal("Failed to check earlier returns", err)
+	}
+
+	lines := make(map[string]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		lines[returnLineKey(item.ProductID, item.Size)] = item
+	}
+
+	items := make([]models.ReturnItem, 0, len(req.Items))
+	var refundAmount float64
+	for _, reqItem := range req.Items {
+		productID, _ := parseObjectID(reqItem.ProductID)
+		key := returnLineKey(productID, reqItem.Size)
+		line, ok := lines[key]
+		if !ok {
+			return apperrors.BadRequest(fmt.Sprintf("Product %s is not part of this order", reqItem.ProductID), nil)
+		}
+		if reqItem.Quantity > line.Quantity-returned[key] {
+			return apperrors.BadRequest(fmt.Sprintf("At most %d of %s can be returned", line.Quantity-returned[key], line.ProductName), nil)
+		}
+		returned[key] += reqItem.Quantity
+
+		subtotal := line.Price * float64(reqItem.Quantity)
+		items = append(items, models.ReturnItem{
+			ProductID:   line.ProductID,
+			ProductName: line.ProductName,
+			Size:        line.Size,
+			Price:       line.Price,
+			Quantity:    reqItem.Quantity,
+			Subtotal:    subtotal,
+		})
+		refundAmount += subtotal
+	}
+
+	now := time.Now()
+	ret := models.Return{
+		ID:           primitive.NewObjectID(),
+		OrderID:      orderID,
+		UserID:       user.UserID,
+		Items:        items,
+		Reason:       req.Reason,
+		Comment:      req.Comment,
+		PhotoURLs:    req.PhotoURLs,
+		Status:       models.ReturnStatusRequested,
+		RefundAmount: refundAmount,
+		History:      []models.ReturnEvent{{Status: models.ReturnStatusRequested, By: user.UserID, At: now}},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := h.DB.Collections().Returns.InsertOne(ctx, ret); err != nil {
+		return apperrors.Internal("Failed to create return", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Return requested successfully",
+		"data":    ret,
+	})
+}
+
+// returnedQuantities sums the quantity per order line of the order's returns
+// that were not rejected
+func (h *ReturnHandler) returnedQuantities(ctx context.Context, orderID primitive.ObjectID) (map[string]int, error) {
+	cursor, err := h.DB.Collections().Returns.Find(ctx, bson.M{
+		"order_id": orderID,
+		"status":   bson.M{"$ne": models.ReturnStatusRejected},
+	}, options.Find().SetProjection(bson.M{"items": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var existing []models.Return
+	if err := cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[string]int)
+	for _, ret := range existing {
+		for _, item := range ret.Items {
+			quantities[returnLineKey(item.ProductID, item.Size)] += item.Quantity
+		}
+	}
+	return quantities, nil
+}
+
+// UploadPhotos stores photos showing the condition of returned items and
+// returns their URLs, which the client sends as photoUrls with the return
+// POST /returns/photos
+func (h *ReturnHandler) UploadPhotos(c *fiber.Ctx) error {
+	urls, err := uploadPhotos(c, h.Storage, "returns", maxReturnPhotos)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Photos uploaded successfully",
+		"data":    fiber.Map{"photoUrls": urls},
+	})
+}
+
+// GetMyReturns lists the current user's returns, newest first
+// GET /returns?page=1&limit=20
+func (h *ReturnHandler) GetMyReturns(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	return h.listReturns(c, bson.M{"user_id": user.UserID})
+}
+
+// GetAllReturns lists returns for processing, optionally by status
+// GET /admin/returns?status=&page=1&limit=20
+func (h *ReturnHandler) GetAllReturns(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	return h.listReturns(c, filter)
+}
+
+func (h *ReturnHandler) listReturns(c *fiber.Ctx, filter bson.M) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	coll := h.DB.Collections().Returns
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count returns", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch returns", err)
+	}
+	defer cursor.Close(ctx)
+
+	returns := []models.Return{}
+	if err := cursor.All(ctx, &returns); err != nil {
+		return apperrors.Internal("Failed to decode returns", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Returns retrieved successfully",
+		"data":    returns,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetReturn returns a single return to its owner or an admin
+// GET /returns/:id
+func (h *ReturnHandler) GetReturn(c *fiber.Ctx) error {
+	returnID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid return ID", err)
+	}
+	ret, err := h.find(c.Context(), returnID)
+	if err != nil {
+		return err
+	}
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || (ret.UserID != user.UserID && !hasPermission(c, h.DB, models.PermissionOrdersRead)) {
+		return apperrors.Forbidden("Not authorized to view this return")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Return retrieved successfully",
+		"data":    ret,
+	})
+}
+
+// UpdateReturnStatus moves a return to its next status. Refunding completes
+// the return: the payment is refunded through Razorpay when the order was
+// paid online (otherwise it is recorded as a manual refund) and the items
+// go back into stock.
+// PATCH /admin/returns/:id/status
+func (h *ReturnHandler) UpdateReturnStatus(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	returnID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid return ID", err)
+	}
+	var req models.ReturnStatusRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	ret, err := h.find(ctx, returnID)
+	if err != nil {
+		return err
+	}
+	allowed := false
+	for _, next := range models.ReturnTransitions[ret.Status] {
+		allowed = allowed || next == req.Status
+	}
+	if !allowed {
+		return apperrors.Conflict(fmt.Sprintf("A %s return cannot be marked %s", ret.Status, req.Status))
+	}
+
+	var actorID primitive.ObjectID
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		actorID = actor.UserID
+	}
+	now := time.Now()
+
+	// Claim the transition first so a concurrent request can't apply it (or
+	// refund) a second time
+	res, err := h.DB.Collections().Returns.UpdateOne(ctx,
+		bson.M{"_id": returnID, "status": ret.Status},
+		bson.M{"$set": bson.M{"status": req.Status, "updated_at": now}},
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to update return", err)
+	}
+	if res.ModifiedCount == 0 {
+		return apperrors.Conflict("The return was changed by someone else, reload and try again")
+	}
+
+	set := bson.M{}
+	if req.Status == models.ReturnStatusRefunded {
+		method, refundID, giftCardAmount, err := h.refund(ctx, ret)
+		if err != nil {
+			// Release the claim so the refund can be retried
+			_, _ = h.DB.Collections().Returns.UpdateOne(ctx,
+				bson.M{"_id": returnID, "status": req.Status},
+				bson.M{"$set": bson.M{"status": ret.Status}},
+			)
+			return err
+		}
+		set["refund_method"] = method
+		set["refund_id"] = refundID
+		if giftCardAmount > 0 {
+			set["gift_card_amount"] = giftCardAmount
+		}
+		set["refunded_at"] = now
+	}
+
+	event := models.ReturnEvent{Status: req.Status, Note: req.Note, By: actorID, At: now}
+	update := bson.M{"$push": bson.M{"history": event}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	var updated models.Return
+	err = h.DB.Collections().Returns.FindOneAndUpdate(ctx, bson.M{"_id": returnID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return apperrors.Internal("Failed to update return", err)
+	}
+
+	if req.Status == models.ReturnStatusRefunded {
+		h.restock(ctx, updated, actorID)
+		if err := h.settleOrder(ctx, updated, actorID); err != nil {
+			log.Printf("[RETURNS] Failed to update order %s after refunding return %s: %v", updated.OrderID.Hex(), returnID.Hex(), err)
+		}
+	}
+
+	recordAudit(c, h.DB.MongoDB, "return.status_change", "return", returnID.Hex(),
+		bson.M{"status": ret.Status}, bson.M{"status": updated.Status, "refund_method": updated.RefundMethod})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Return updated successfully",
+		"data":    updated,
+	})
+}
+
+// refund pays back a return. On orders partly paid by gift card, the other
+// payment is refunded first, up to what it took, and the rest goes back on
+// the card. Orders paid online are refunded through the gateway; anything
+// else (COD, gateway not configured) is left to be settled by hand and
+// recorded as manual.
+func (h *ReturnHandler) refund(ctx context.Context, ret models.Return) (method, refundID string, giftCardAmount float64, err error) {
+	var order models.Order
+	if err := h.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": ret.OrderID}).Decode(&order); err != nil {
+		return "", "", 0, apperrors.Internal("Failed to retrieve order", err)
+	}
+	giftCardAmount = giftCardShare(order, ret.RefundAmount)
+	paymentAmount := ret.RefundAmount - giftCardAmount
+
+	method = models.RefundMethodManual
+	online := order.PaymentInfo.Method == "razorpay" && order.PaymentInfo.RazorpayPaymentID != ""
+	switch {
+	case paymentAmount < 0.01:
+		method = models.RefundMethodGiftCard
+	case order.PaymentStatus == "paid" && online:
+		// Refunded through the account that took the payment
+		keys := h.Config.Razorpay(order.PaymentInfo.Mode)
+		if !keys.Configured() {
+			return "", "", 0, apperrors.Unavailable("Payment gateway not configured", nil)
+		}
+		// The payment was made in the order's currency; the refund amount is
+		// in the base currency like the returned items' prices
+		currency, err := orderCurrency(ctx, h.DB, order)
+		if err != nil {
+			return "", "", 0, apperrors.Internal("Failed to look up the order's currency", err)
+		}
+		amount := currency.MinorUnits(currency.Convert(paymentAmount))
+		refundID, err = razorpayRefund(ctx, keys, order.PaymentInfo.RazorpayPaymentID, amount, map[string]string{
+			"order_id":  ret.OrderID.Hex(),
+			"return_id": ret.ID.Hex(),
+		})
+		if err != nil {
+			return "", "", 0, apperrors.BadGateway("Failed to refund payment", err)
+		}
+		method = models.RefundMethodRazorpay
+	}
+
+	// The payment may already be refunded, so a failure here is only logged
+	// rather than releasing the return to be refunded again
+	if giftCardAmount > 0 {
+		_, err := h.DB.Collections().Orders.UpdateOne(ctx, bson.M{"_id": order.ID},
+			bson.M{"$inc": bson.M{"gift_card.amount": -giftCardAmount, "gift_card.returned": giftCardAmount}})
+		if err == nil {
+			err = giftcards.GiveBack(ctx, h.DB, order, giftCardAmount)
+		}
+		if err != nil {
+			log.Printf("[RETURNS] Failed to give back %.2f to the gift card of order %s for return %s: %v", giftCardAmount, order.ID.Hex(), ret.ID.Hex(), err)
+		}
+	}
+	return method, refundID, giftCardAmount, nil
+}
+
+// giftCardShare is the part of a refund that goes back on the order's gift
+// card: what the other payment can't cover after earlier refunds, up to what
+// the card still holds in the order
+func giftCardShare(order models.Order, refund float64) float64 {
+	card := order.GiftCard
+	if card == nil || card.Amount <= 0 {
+		return 0
+	}
+	paid := order.Total - card.Amount - card.Returned
+	left := math.Max(paid-(order.RefundedAmount-card.Returned), 0)
+	share := math.Min(math.Max(refund-left, 0), card.Amount)
+	return math.Round(share*100) / 100
+}
+
+// restock puts returned items back into stock
+func (h *ReturnHandler) restock(ctx context.Context, ret models.Return, actorID primitive.ObjectID) {
+	products := h.DB.Collections().Products
+	for _, item := range ret.Items {
+		var restored models.Product
+		err := products.FindOneAndUpdate(ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"stock": item.Quantity}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"stock": 1}),
+		).Decode(&restored)
+		if err != nil {
+			log.Printf("[RETURNS] Failed to restore stock for product %s: %v", item.ProductID.Hex(), err)
+			continue
+		}
+		logStockMovement(ctx, h.DB, models.StockMovement{
+			ProductID:  item.ProductID,
+			Delta:      item.Quantity,
+			StockAfter: restored.Stock,
+			Reason:     models.StockReasonReturn,
+			OrderID:    &ret.OrderID,
+			ActorID:    actorID,
+		})
+		h.DB.InvalidateProductCaches(ctx, item.ProductID.Hex())
+	}
+}
+
+// settleOrder adds the refund to the order. Once everything in it has been
+// refunded the order becomes "returned".
+func (h *ReturnHandler) settleOrder(ctx context.Context, ret models.Return, actorID primitive.ObjectID) error {
+	var order models.Order
+	err := h.DB.Collections().Orders.FindOneAndUpdate(ctx,
+		bson.M{"_id": ret.OrderID},
+		bson.M{"$inc": bson.M{"refunded_amount": ret.RefundAmount}, "$set": bson.M{"updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&order)
+	if err != nil {
+		return err
+	}
+
+	// Returns refund items, never gift wrapping, so the order is fully
+	// returned once its items' subtotals are. Allow for rounding in the sums.
+	var itemsTotal float64
+	for _, item := range order.Items {
+		itemsTotal += item.Subtotal
+	}
+	if order.RefundedAmount >= itemsTotal-0.01 && orderstatus.CanTransition(order.Status, orderstatus.Returned) {
+		set := bson.M{"status": orderstatus.Returned}
+		if order.PaymentStatus == "paid" {
+			set["payment_status"] = "refunded"
+		}
+		event := models.OrderStatusEvent{
+			Status:    orderstatus.Returned,
+			Actor:     models.OrderActorAdmin,
+			ActorID:   actorID,
+			Note:      "All items returned and refunded",
+			Timestamp: time.Now(),
+		}
+		res, err := h.DB.Collections().Orders.UpdateOne(ctx, bson.M{"_id": order.ID, "status": order.Status}, bson.M{"$set": set, "$push": bson.M{"status_history": event}})
+		if err != nil {
+			return err
+		}
+		if res.ModifiedCount > 0 {
+			h.Events.Publish(ctx, events.OrderStatusChanged, statusChange(order, order.Status, event))
+		}
+	}
+
+	h.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()))
+	h.DB.CacheDel(ctx, fmt.Sprintf("orders:%s", order.UserID.Hex()))
+	return nil
+}
+
+func (h *ReturnHandler) find(ctx context.Context, id primitive.ObjectID) (models.Return, error) {
+	var ret models.Return
+	if err := h.DB.Collections().Returns.FindOne(ctx, bson.M{"_id": id}).Decode(&ret); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ret, apperrors.NotFound("Return not found")
+		}
+		return ret, apperrors.Internal("Failed to retrieve return", err)
+	}
+	return ret, nil
+}
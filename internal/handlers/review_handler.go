@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,16 +15,22 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
 // ReviewHandler handles product review operations
 type ReviewHandler struct {
-	DB     *database.DBClient
-	Config *config.Config
+	DB      *database.DBClient
+	Config  *config.Config
+	Storage storage.Storage
+	Events  *events.Bus // Optional; receives a review.created event per review
 }
 
 // NewReviewHandler creates a new instance of ReviewHandler
@@ -30,6 +41,35 @@ func NewReviewHandler(db *database.DBClient, cfg *config.Config) *ReviewHandler
 	}
 }
 
+// adjustProductRating applies one review change to a product's rating in a
+// single atomic update instead of re-reading every review: sumDelta is added
+// to the ratings total, countDelta to the number of ratings, and avg_rating
+// is recomputed from the two. Products rated before rating_sum was stored
+// derive it from their average. jobs.RatingReconciler repairs any drift.
+func adjustProductRating(ctx context.Context, products *mongo.Collection, productID primitive.ObjectID, sumDelta float64, countDelta int) error {
+	_, err := products.UpdateOne(ctx, bson.M{"_id": productID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"ratings_count": bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$ratings_count", 0}}, countDelta}}}},
+			"rating_sum": bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$rating_sum", bson.M{"$multiply": bson.A{
+					bson.M{"$ifNull": bson.A{"$avg_rating", 0}},
+					bson.M{"$ifNull": bson.A{"$ratings_count", 0}},
+				}}}},
+				sumDelta,
+			}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"rating_sum": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$ratings_count", 0}}, "$rating_sum", 0}},
+			"avg_rating": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$ratings_count", 0}},
+				bson.M{"$divide": bson.A{"$rating_sum", "$ratings_count"}},
+				0,
+			}},
+		}}},
+	})
+	return err
+}
+
 // GetProductReviews returns reviews for a specific product
 func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -37,10 +77,7 @@ func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 	// Get product ID from parameters
 	productID, err := primitive.ObjectIDFromHex(c.Params("productId"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID",
-		})
+		return apperrors.BadRequest("Invalid product ID", nil)
 	}
 
 	// Parse query parameters for pagination
@@ -61,37 +98,45 @@ func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 		}
 	}
 
+	// Opt-in cursor pagination, newest first like the page listing
+	paging, err := parseCursorPage(c, "created_at", -1)
+	if err != nil {
+		return err
+	}
+
 	// Set up options for pagination and sorting
-	skip := int64((page - 1) * limit)
-	findOptions := options.Find().
-		SetSkip(skip).
-		SetLimit(int64(limit)).
-		SetSort(bson.D{{Key: "created_at", Value: -1}}) // Newest first
+	filter := bson.M{"product_id": productID}
+	findOptions := options.Find()
+	if paging != nil {
+		filter = paging.apply(filter, findOptions, limit)
+	} else {
+		findOptions.
+			SetSkip(int64((page - 1) * limit)).
+			SetLimit(int64(limit)).
+			SetSort(bson.D{{Key: "created_at", Value: -1}}) // Newest first
+	}
 
 	// Find reviews for the product
 	reviewCollection := h.DB.Collections().Reviews
-	cursor, err := reviewCollection.Find(
-		ctx,
-		bson.M{"product_id": productID},
-		findOptions,
-	)
+	cursor, err := reviewCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve reviews",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve reviews", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode the results
 	var reviews []models.Review
 	if err := cursor.All(ctx, &reviews); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode reviews",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode reviews", err)
+	}
+	nextCursor := ""
+	if paging != nil {
+		var n int
+		n, nextCursor, err = paging.next(len(reviews), limit, func(i int) interface{} { return reviews[i] })
+		if err != nil {
+			return apperrors.Internal("Failed to build next cursor", err)
+		}
+		reviews = reviews[:n]
 	}
 
 	// Get user details for the reviews
@@ -106,11 +151,7 @@ func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 		bson.M{"_id": bson.M{"$in": userIDs}},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve user details",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve user details", err)
 	}
 	defer userCursor.Close(ctx)
 
@@ -118,17 +159,19 @@ func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 	users := make(map[primitive.ObjectID]models.User)
 	var userList []models.User
 	if err := userCursor.All(ctx, &userList); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode users",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode users", err)
 	}
 
 	for _, user := range userList {
 		users[user.ID] = user
 	}
 
+	// Signed-in callers see their own votes
+	myVotes, err := h.callerVotes(c, reviews)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve votes", err)
+	}
+
 	// Build response with user details
 	response := make([]fiber.Map, 0, len(reviews))
 	for _, review := range reviews {
@@ -140,18 +183,36 @@ func (h *ReviewHandler) GetProductReviews(c *fiber.Ctx) error {
 			userName = user.Name
 		}
 
-		response = append(response, fiber.Map{
-			"id":        review.ID,
-			"productId": review.ProductID,
-			"userId":    review.UserID,
-			"userName":  userName,
-			"rating":    review.Rating,
-			"title":     review.Title,
-			"comment":   review.Comment,
-			"photoUrls": review.PhotoURLs,
-			"helpful":   review.Helpful,
-			"verified":  review.Verified,
-			"createdAt": review.CreatedAt,
+		item := fiber.Map{
+			"id":         review.ID,
+			"productId":  review.ProductID,
+			"userId":     review.UserID,
+			"userName":   userName,
+			"rating":     review.Rating,
+			"title":      review.Title,
+			"comment":    review.Comment,
+			"photoUrls":  review.PhotoURLs,
+			"helpful":    review.Helpful,
+			"notHelpful": review.NotHelpful,
+			"verified":   review.Verified,
+			"reply":      review.Reply,
+			"createdAt":  review.CreatedAt,
+		}
+		if myVotes != nil {
+			item["myVote"] = myVotes[review.ID]
+		}
+		response = append(response, item)
+	}
+
+	if paging != nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Reviews retrieved successfully",
+			"data":    response,
+			"meta": fiber.Map{
+				"limit":      limit,
+				"nextCursor": nextCursor,
+			},
 		})
 	}
 
@@ -184,10 +245,7 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse query parameters for pagination
@@ -223,22 +281,14 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 		findOptions,
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve reviews",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve reviews", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode the results
 	var reviews []models.Review
 	if err := cursor.All(ctx, &reviews); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode reviews",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode reviews", err)
 	}
 
 	// If no reviews found, return empty array
@@ -268,11 +318,7 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 		bson.M{"_id": bson.M{"$in": productIDs}},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve product details",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve product details", err)
 	}
 	defer productCursor.Close(ctx)
 
@@ -280,11 +326,7 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 	products := make(map[primitive.ObjectID]models.Product)
 	var productList []models.Product
 	if err := productCursor.All(ctx, &productList); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode products",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode products", err)
 	}
 
 	for _, product := range productList {
@@ -314,7 +356,9 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 			"comment":      review.Comment,
 			"photoUrls":    review.PhotoURLs,
 			"helpful":      review.Helpful,
+			"notHelpful":   review.NotHelpful,
 			"verified":     review.Verified,
+			"reply":        review.Reply,
 			"createdAt":    review.CreatedAt,
 		})
 	}
@@ -348,10 +392,7 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse request body
@@ -360,39 +401,20 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 		Rating    float64  `json:"rating" validate:"required,min=1,max=5"`
 		Title     string   `json:"title" validate:"required"`
 		Comment   string   `json:"comment" validate:"required,min=5"`
-		PhotoURLs []string `json:"photoUrls,omitempty"`
+		PhotoURLs []string `json:"photoUrls,omitempty" validate:"max=5"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
-	}
-
-	// Validate the request
-	if req.Rating < 1 || req.Rating > 5 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Rating must be between 1 and 5",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
-
-	if len(req.Comment) < 5 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Comment must be at least 5 characters long",
-		})
+	if err := h.checkPhotos(req.PhotoURLs, nil); err != nil {
+		return err
 	}
 
 	// Convert string ID to ObjectID
 	productID, err := primitive.ObjectIDFromHex(req.ProductID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID",
-		})
+		return apperrors.BadRequest("Invalid product ID", nil)
 	}
 
 	// Check if product exists
@@ -401,16 +423,9 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 	err = productCollection.FindOne(ctx, bson.M{"_id": productID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check product", err)
 	}
 
 	// Check if user has already reviewed this product
@@ -423,18 +438,11 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 		},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check existing reviews",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check existing reviews", err)
 	}
 
 	if count > 0 {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"success": false,
-			"message": "You have already reviewed this product",
-		})
+		return apperrors.Conflict("You have already reviewed this product")
 	}
 
 	// Create the new review
@@ -456,50 +464,21 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 	// Insert the review
 	_, err = reviewCollection.InsertOne(ctx, review)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create review",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to create review", err)
 	}
 
 	// Update product rating
-	// Get all ratings for the product
-	cursor, err := reviewCollection.Find(
-		ctx,
-		bson.M{"product_id": productID},
-	)
-	if err == nil {
-		defer cursor.Close(ctx)
-
-		var reviews []models.Review
-		if err := cursor.All(ctx, &reviews); err == nil {
-			// Calculate average rating
-			var totalRating float64
-			for _, r := range reviews {
-				totalRating += r.Rating
-			}
-
-			avgRating := totalRating / float64(len(reviews))
-
-			// Update product rating
-			_, err = productCollection.UpdateOne(
-				ctx,
-				bson.M{"_id": productID},
-				bson.M{"$set": bson.M{
-					"avg_rating":    avgRating,
-					"ratings_count": len(reviews),
-				}},
-			)
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"success": false,
-					"message": "Failed to update product rating",
-					"error":   err.Error(),
-				})
-			}
-		}
+	if err := adjustProductRating(ctx, productCollection, productID, review.Rating, 1); err != nil {
+		return apperrors.Internal("Failed to update product rating", err)
 	}
+	h.DB.CacheDel(ctx, reviewSummaryCacheKey(productID))
+	h.Events.Publish(ctx, events.ReviewCreated, events.ReviewWritten{
+		ReviewID:    review.ID.Hex(),
+		UserID:      user.UserID.Hex(),
+		ProductID:   productID.Hex(),
+		ProductName: product.Name,
+		Rating:      review.Rating,
+	})
 
 	// Get user name
 	userCollection := h.DB.Collections().Users
@@ -515,17 +494,18 @@ func (h *ReviewHandler) CreateReview(c *fiber.Ctx) error {
 		"success": true,
 		"message": "Review created successfully",
 		"data": fiber.Map{
-			"id":        review.ID,
-			"productId": review.ProductID,
-			"userId":    review.UserID,
-			"userName":  userName,
-			"rating":    review.Rating,
-			"title":     review.Title,
-			"comment":   review.Comment,
-			"photoUrls": review.PhotoURLs,
-			"helpful":   review.Helpful,
-			"verified":  review.Verified,
-			"createdAt": review.CreatedAt,
+			"id":         review.ID,
+			"productId":  review.ProductID,
+			"userId":     review.UserID,
+			"userName":   userName,
+			"rating":     review.Rating,
+			"title":      review.Title,
+			"comment":    review.Comment,
+			"photoUrls":  review.PhotoURLs,
+			"helpful":    review.Helpful,
+			"notHelpful": review.NotHelpful,
+			"verified":   review.Verified,
+			"createdAt":  review.CreatedAt,
 		},
 	})
 }
@@ -537,19 +517,13 @@ func (h *ReviewHandler) UpdateReview(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get review ID from parameters
 	reviewID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid review ID",
-		})
+		return apperrors.BadRequest("Invalid review ID", nil)
 	}
 
 	// Parse request body
@@ -557,30 +531,11 @@ func (h *ReviewHandler) UpdateReview(c *fiber.Ctx) error {
 		Rating    float64  `json:"rating" validate:"required,min=1,max=5"`
 		Title     string   `json:"title" validate:"required"`
 		Comment   string   `json:"comment" validate:"required,min=5"`
-		PhotoURLs []string `json:"photoUrls,omitempty"`
+		PhotoURLs []string `json:"photoUrls,omitempty" validate:"max=5"` // Replaces the photos when present; [] removes them
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
-	}
-
-	// Validate the request
-	if req.Rating < 1 || req.Rating > 5 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Rating must be between 1 and 5",
-		})
-	}
-
-	if len(req.Comment) < 5 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Comment must be at least 5 characters long",
-		})
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	// Check if the review exists and belongs to the user
@@ -596,16 +551,12 @@ func (h *ReviewHandler) UpdateReview(c *fiber.Ctx) error {
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Review not found or does not belong to you",
-			})
+			return apperrors.NotFound("Review not found or does not belong to you")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check review",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check review", err)
+	}
+	if err := h.checkPhotos(req.PhotoURLs, existingReview.PhotoURLs); err != nil {
+		return err
 	}
 
 	// Update the review
@@ -616,58 +567,51 @@ func (h *ReviewHandler) UpdateReview(c *fiber.Ctx) error {
 		"updated_at": time.Now(),
 	}
 
-	if len(req.PhotoURLs) > 0 {
+	if req.PhotoURLs != nil {
 		update["photo_urls"] = req.PhotoURLs
 	}
 
-	_, err = reviewCollection.UpdateOne(
+	// The previous rating is read atomically with the update so concurrent
+	// edits each adjust the product by their own difference
+	var previous models.Review
+	err = reviewCollection.FindOneAndUpdate(
 		ctx,
 		bson.M{
 			"_id":     reviewID,
 			"user_id": user.UserID,
 		},
 		bson.M{"$set": update},
-	)
+		options.FindOneAndUpdate().SetReturnDocument(options.Before).SetProjection(bson.M{"rating": 1, "photo_urls": 1}),
+	).Decode(&previous)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update review",
-			"error":   err.Error(),
-		})
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("Review not found or does not belong to you")
+		}
+		return apperrors.Internal("Failed to update review", err)
 	}
 
 	// Update product rating
-	// Get all ratings for the product
-	productID := existingReview.ProductID
-	cursor, err := reviewCollection.Find(
-		ctx,
-		bson.M{"product_id": productID},
-	)
-	if err == nil {
-		defer cursor.Close(ctx)
-
-		var reviews []models.Review
-		if err := cursor.All(ctx, &reviews); err == nil {
-			// Calculate average rating
-			var totalRating float64
-			for _, r := range reviews {
-				totalRating += r.Rating
-			}
-
-			avgRating := totalRating / float64(len(reviews))
+	if delta := req.Rating - previous.Rating; delta != 0 {
+		if err := adjustProductRating(ctx, h.DB.Collections().Products, existingReview.ProductID, delta, 0); err != nil {
+			log.Printf("[REVIEWS] Failed to update rating of product %s: %v", existingReview.ProductID.Hex(), err)
+		}
+	}
+	h.DB.CacheDel(ctx, reviewSummaryCacheKey(existingReview.ProductID))
 
-			// Update product rating
-			productCollection := h.DB.Collections().Products
-			_, err = productCollection.UpdateOne(
-				ctx,
-				bson.M{"_id": productID},
-				bson.M{"$set": bson.M{
-					"avg_rating":    avgRating,
-					"ratings_count": len(reviews),
-				}},
-			)
+	// Photos the edit dropped are no longer referenced
+	if req.PhotoURLs != nil {
+		kept := make(map[string]bool, len(req.PhotoURLs))
+		for _, url := range req.PhotoURLs {
+			kept[url] = true
+		}
+		var removed []string
+		for _, url := range previous.PhotoURLs {
+			if !kept[url] {
+				removed = append(removed, url)
+			}
 		}
+		storage.DeleteURLs(ctx, h.Storage, removed)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -683,19 +627,13 @@ func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get review ID from parameters
 	reviewID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid review ID",
-		})
+		return apperrors.BadRequest("Invalid review ID", nil)
 	}
 
 	// Check if the review exists and belongs to the user
@@ -711,23 +649,16 @@ func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Review not found or does not belong to you",
-			})
+			return apperrors.NotFound("Review not found or does not belong to you")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check review",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check review", err)
 	}
 
 	// Store product ID for rating update
 	productID := existingReview.ProductID
 
 	// Delete the review
-	_, err = reviewCollection.DeleteOne(
+	res, err := reviewCollection.DeleteOne(
 		ctx,
 		bson.M{
 			"_id":     reviewID,
@@ -736,55 +667,20 @@ func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to delete review",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to delete review", err)
 	}
 
-	// Update product rating
-	cursor, err := reviewCollection.Find(
-		ctx,
-		bson.M{"product_id": productID},
-	)
-	if err == nil {
-		defer cursor.Close(ctx)
-
-		var reviews []models.Review
-		if err := cursor.All(ctx, &reviews); err == nil {
-			productCollection := h.DB.Collections().Products
-
-			if len(reviews) > 0 {
-				// Calculate average rating
-				var totalRating float64
-				for _, r := range reviews {
-					totalRating += r.Rating
-				}
-
-				avgRating := totalRating / float64(len(reviews))
-
-				// Update product rating
-				_, err = productCollection.UpdateOne(
-					ctx,
-					bson.M{"_id": productID},
-					bson.M{"$set": bson.M{
-						"avg_rating":    avgRating,
-						"ratings_count": len(reviews),
-					}},
-				)
-			} else {
-				// No reviews left, reset rating
-				_, err = productCollection.UpdateOne(
-					ctx,
-					bson.M{"_id": productID},
-					bson.M{"$set": bson.M{
-						"avg_rating":    0,
-						"ratings_count": 0,
-					}},
-				)
-			}
+	// Update product rating and remove the photos and votes, once even if
+	// the review is deleted twice concurrently
+	if res.DeletedCount > 0 {
+		if err := adjustProductRating(ctx, h.DB.Collections().Products, productID, -existingReview.Rating, -1); err != nil {
+			log.Printf("[REVIEWS] Failed to update rating of product %s: %v", productID.Hex(), err)
 		}
+		storage.DeleteURLs(ctx, h.Storage, existingReview.PhotoURLs)
+		if _, err := h.DB.Collections().ReviewVotes.DeleteMany(ctx, bson.M{"review_id": reviewID}); err != nil {
+			log.Printf("[REVIEWS] Failed to delete votes on review %s: %v", reviewID.Hex(), err)
+		}
+		h.DB.CacheDel(ctx, reviewSummaryCacheKey(productID))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -793,58 +689,442 @@ func (h *ReviewHandler) DeleteReview(c *fiber.Ctx) error {
 	})
 }
 
-// MarkReviewHelpful increases the helpful count for a review
+// MarkReviewHelpful toggles the current user's helpful vote on a review
+// POST /reviews/:id/helpful
 func (h *ReviewHandler) MarkReviewHelpful(c *fiber.Ctx) error {
+	return h.vote(c, models.ReviewVoteHelpful)
+}
+
+// MarkReviewNotHelpful toggles the current user's not-helpful vote on a
+// review
+// POST /reviews/:id/not-helpful
+func (h *ReviewHandler) MarkReviewNotHelpful(c *fiber.Ctx) error {
+	return h.vote(c, models.ReviewVoteNotHelpful)
+}
+
+// vote records the current user's vote on a review. Repeating the same vote
+// withdraws it and casting the other one switches it; the review's helpful
+// and not_helpful counters follow.
+func (h *ReviewHandler) vote(c *fiber.Ctx, vote string) error {
 	ctx := c.Context()
 
-	// Get review ID from parameters
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
 	reviewID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid review ID",
-		})
+		return apperrors.BadRequest("Invalid review ID", nil)
 	}
 
-	// Check if review exists
 	reviewCollection := h.DB.Collections().Reviews
-	var existingReview models.Review
-	err = reviewCollection.FindOne(
-		ctx,
-		bson.M{"_id": reviewID},
-	).Decode(&existingReview)
+	var review models.Review
+	if err := reviewCollection.FindOne(ctx, bson.M{"_id": reviewID}, options.FindOne().SetProjection(bson.M{"user_id": 1})).Decode(&review); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return apperrors.NotFound("Review not found")
+		}
+		return apperrors.Internal("Failed to check review", err)
+	}
+	if review.UserID == user.UserID {
+		return apperrors.BadRequest("You cannot vote on your own review", nil)
+	}
 
-	if err != nil {
+	// The vote is swapped in atomically, so the previous one says how the
+	// counters change even when the same user votes twice at once
+	votes := h.DB.Collections().ReviewVotes
+	key := bson.M{"review_id": reviewID, "user_id": user.UserID}
+	now := time.Now()
+	upsert := func() (models.ReviewVote, error) {
+		var previous models.ReviewVote
+		err := votes.FindOneAndUpdate(ctx, key,
+			bson.M{"$set": bson.M{"vote": vote, "updated_at": now}, "$setOnInsert": bson.M{"created_at": now}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+		).Decode(&previous)
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Review not found",
-			})
+			return previous, nil
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check review",
-			"error":   err.Error(),
-		})
+		return previous, err
+	}
+	previous, err := upsert()
+	if mongo.IsDuplicateKeyError(err) {
+		previous, err = upsert() // Lost an insert race; the vote exists now
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to record vote", err)
 	}
 
-	// Increment helpful count
-	_, err = reviewCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": reviewID},
-		bson.M{"$inc": bson.M{"helpful": 1}},
-	)
+	inc := bson.M{}
+	current := vote
+	switch previous.Vote {
+	case vote:
+		// The same vote again withdraws it, unless another request changed it
+		res, err := votes.DeleteOne(ctx, bson.M{"review_id": reviewID, "user_id": user.UserID, "vote": vote})
+		if err != nil {
+			return apperrors.Internal("Failed to withdraw vote", err)
+		}
+		if res.DeletedCount > 0 {
+			inc[models.ReviewVoteFields[vote]] = -1
+			current = ""
+		}
+	case "":
+		inc[models.ReviewVoteFields[vote]] = 1
+	default:
+		inc[models.ReviewVoteFields[vote]] = 1
+		inc[models.ReviewVoteFields[previous.Vote]] = -1
+	}
+
+	if len(inc) > 0 {
+		err = reviewCollection.FindOneAndUpdate(ctx, bson.M{"_id": reviewID}, bson.M{"$inc": inc},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"helpful": 1, "not_helpful": 1}),
+		).Decode(&review)
+	} else {
+		err = reviewCollection.FindOne(ctx, bson.M{"_id": reviewID}, options.FindOne().SetProjection(bson.M{"helpful": 1, "not_helpful": 1})).Decode(&review)
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to update review votes", err)
+	}
+
+	message := "Vote recorded"
+	if current == "" {
+		message = "Vote withdrawn"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": fiber.Map{
+			"helpful":    review.Helpful,
+			"notHelpful": review.NotHelpful,
+			"myVote":     current,
+		},
+	})
+}
+
+// callerVotes returns the current user's votes on the reviews by review ID,
+// or nil for anonymous callers
+func (h *ReviewHandler) callerVotes(c *fiber.Ctx, reviews []models.Review) (map[primitive.ObjectID]string, error) {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || len(reviews) == 0 {
+		return nil, nil
+	}
+	ids := make([]primitive.ObjectID, len(reviews))
+	for i, review := range reviews {
+		ids[i] = review.ID
+	}
+	cursor, err := h.DB.Collections().ReviewVotes.Find(c.Context(), bson.M{"review_id": bson.M{"$in": ids}, "user_id": user.UserID})
+	if err != nil {
+		return nil, err
+	}
+	var votes []models.ReviewVote
+	if err := cursor.All(c.Context(), &votes); err != nil {
+		return nil, err
+	}
+	byReview := make(map[primitive.ObjectID]string, len(votes))
+	for _, v := range votes {
+		byReview[v.ReviewID] = v.Vote
+	}
+	return byReview, nil
+}
+
+// maxReviewPhotos is how many photos a review can have, matching the max
+// on the photoUrls of review requests
+const maxReviewPhotos = 5
+
+// checkPhotos rejects photo URLs that were not uploaded with UploadPhotos,
+// so reviews only show, and deleting them only removes, review photos. URLs
+// the review already has are kept as they are.
+func (h *ReviewHandler) checkPhotos(urls, existing []string) error {
+	had := make(map[string]bool, len(existing))
+	for _, url := range existing {
+		had[url] = true
+	}
+	for _, url := range urls {
+		if had[url] {
+			continue
+		}
+		if key, ok := h.Storage.KeyFromURL(url); !ok || !strings.HasPrefix(key, "reviews/") {
+			return apperrors.BadRequest("Photos must be uploaded with POST /reviews/uploads", nil)
+		}
+	}
+	return nil
+}
+
+// UploadPhotos stores photos for a review and returns their URLs, which the
+// client then sends as photoUrls when creating or updating the review
+// POST /reviews/uploads (also POST /reviews/photos)
+func (h *ReviewHandler) UploadPhotos(c *fiber.Ctx) error {
+	urls, err := uploadPhotos(c, h.Storage, "reviews", maxReviewPhotos)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Photos uploaded successfully",
+		"data":    fiber.Map{"photoUrls": urls},
+	})
+}
+
+// reviewSummaryKeywords caps how many keywords a review summary lists
+const reviewSummaryKeywords = 10
+
+// reviewStopWords are left out of review keywords: function words and words
+// every watch review uses
+var reviewStopWords = []string{
+	"about", "after", "again", "all", "also", "and", "any", "are", "because", "been", "but", "can", "could",
+	"did", "does", "for", "from", "get", "got", "had", "has", "have", "her", "his", "how", "its", "just",
+	"more", "much", "not", "now", "one", "only", "other", "our", "out", "really", "she", "should", "some",
+	"than", "that", "the", "their", "them", "then", "there", "these", "they", "this", "too", "use", "very",
+	"was", "were", "what", "when", "which", "while", "who", "will", "with", "would", "you", "your",
+	"product", "watch", "watches",
+}
+
+// reviewSummaryCacheKey is where a product's review summary is cached
+func reviewSummaryCacheKey(productID primitive.ObjectID) string {
+	return "reviews:summary:" + productID.Hex()
+}
+
+// GetReviewSummary returns a product's rating distribution, average rating,
+// share of verified purchases and most mentioned words, so product pages
+// can draw their rating bars without fetching every review. It is cached
+// until a review of the product changes.
+// GET /products/:productId/reviews/summary
+func (h *ReviewHandler) GetReviewSummary(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	productID, err := primitive.ObjectIDFromHex(c.Params("productId"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", nil)
+	}
+
+	var summary models.ReviewSummary
+	if err := h.DB.CacheGet(ctx, reviewSummaryCacheKey(productID), &summary); err != nil {
+		count, err := h.DB.Collections().Products.CountDocuments(ctx, bson.M{"_id": productID})
+		if err != nil {
+			return apperrors.Internal("Failed to check product", err)
+		}
+		if count == 0 {
+			return apperrors.NotFound("Product not found")
+		}
+		if summary, err = summarizeReviews(ctx, h.DB, productID); err != nil {
+			return apperrors.Internal("Failed to summarize reviews", err)
+		}
+		h.DB.CacheSet(ctx, reviewSummaryCacheKey(productID), summary, 30*time.Minute)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Review summary retrieved successfully",
+		"data":    summary,
+	})
+}
+
+// summarizeReviews computes a product's review summary in one aggregation
+func summarizeReviews(ctx context.Context, db *database.DBClient, productID primitive.ObjectID) (models.ReviewSummary, error) {
+	summary := models.ReviewSummary{
+		ProductID:    productID,
+		RatingCounts: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+		Keywords:     []models.ReviewKeyword{},
+	}
+
+	// Each review counts once per word it uses, so one long review cannot
+	// dominate the keywords
+	words := bson.M{"$setUnion": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$regexFindAll": bson.M{
+			"input": bson.M{"$toLower": bson.M{"$concat": bson.A{
+				bson.M{"$ifNull": bson.A{"$title", ""}}, " ", bson.M{"$ifNull": bson.A{"$comment", ""}},
+			}}},
+			"regex": "[a-z]{3,}",
+		}},
+		"as": "m",
+		"in": "$$m.match",
+	}}}}
+	cursor, err := db.Collections().Reviews.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product_id": productID}}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":      nil,
+					"count":    bson.M{"$sum": 1},
+					"average":  bson.M{"$avg": "$rating"},
+					"verified": bson.M{"$sum": bson.M{"$cond": bson.A{"$verified", 1, 0}}},
+				}},
+			},
+			"stars": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$floor": bson.M{"$add": bson.A{"$rating", 0.5}}},
+					"count": bson.M{"$sum": 1},
+				}},
+			},
+			"keywords": bson.A{
+				bson.M{"$project": bson.M{"words": words}},
+				bson.M{"$unwind": "$words"},
+				bson.M{"$match": bson.M{"words": bson.M{"$nin": reviewStopWords}}},
+				bson.M{"$group": bson.M{"_id": "$words", "reviews": bson.M{"$sum": 1}}},
+				bson.M{"$match": bson.M{"reviews": bson.M{"$gte": 2}}},
+				bson.M{"$sort": bson.D{{Key: "reviews", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": reviewSummaryKeywords},
+			},
+		}}},
+	})
+	if err != nil {
+		return summary, err
+	}
+	var result []struct {
+		Totals []struct {
+			Count    int     `bson:"count"`
+			Average  float64 `bson:"average"`
+			Verified int     `bson:"verified"`
+		} `bson:"totals"`
+		Stars []struct {
+			Star  float64 `bson:"_id"`
+			Count int     `bson:"count"`
+		} `bson:"stars"`
+		Keywords []models.ReviewKeyword `bson:"keywords"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return summary, err
+	}
+	if len(result) == 0 || len(result[0].Totals) == 0 {
+		return summary, nil
+	}
+
+	totals := result[0].Totals[0]
+	summary.TotalReviews = totals.Count
+	summary.AverageRating = math.Round(totals.Average*10) / 10
+	summary.VerifiedPercentage = math.Round(float64(totals.Verified)/float64(totals.Count)*1000) / 10
+	for _, s := range result[0].Stars {
+		star := min(max(int(s.Star), 1), 5)
+		summary.RatingCounts[star] += s.Count
+	}
+	summary.Keywords = append(summary.Keywords, result[0].Keywords...)
+	return summary, nil
+}
+
+// CreateReviewReply publishes the store's response to a review and lets
+// the reviewer know. A review has at most one reply; edit it with PUT.
+// POST /admin/reviews/:id/reply
+func (h *ReviewHandler) CreateReviewReply(c *fiber.Ctx) error {
+	return h.saveReply(c, true)
+}
+
+// UpdateReviewReply edits the store's response to a review
+// PUT /admin/reviews/:id/reply
+func (h *ReviewHandler) UpdateReviewReply(c *fiber.Ctx) error {
+	return h.saveReply(c, false)
+}
+
+// saveReply writes the reply to a review, which must not have one yet when
+// create is set and must have one otherwise
+func (h *ReviewHandler) saveReply(c *fiber.Ctx, create bool) error {
+	ctx := c.Context()
+
+	staff, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	reviewID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid review ID", err)
+	}
+	var req models.ReviewReplyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var author models.User
+	if err := h.DB.Collections().Users.FindOne(ctx, bson.M{"_id": staff.UserID}, options.FindOne().SetProjection(bson.M{"name": 1})).Decode(&author); err != nil {
+		return apperrors.Internal("Failed to load your account", err)
+	}
 
+	now := time.Now()
+	filter := bson.M{"_id": reviewID, "reply": bson.M{"$exists": !create}}
+	set := bson.M{
+		"reply.text":        req.Text,
+		"reply.author_id":   staff.UserID,
+		"reply.author_name": author.Name,
+		"reply.updated_at":  now,
+	}
+	if create {
+		set["reply.created_at"] = now
+	}
+	var before models.Review
+	err = h.DB.Collections().Reviews.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}).Decode(&before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		count, err := h.DB.Collections().Reviews.CountDocuments(ctx, bson.M{"_id": reviewID})
+		switch {
+		case err != nil:
+			return apperrors.Internal("Failed to check review", err)
+		case count == 0:
+			return apperrors.NotFound("Review not found")
+		case create:
+			return apperrors.Conflict("The review already has a reply; edit it with PUT")
+		default:
+			return apperrors.NotFound("The review has no reply")
+		}
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to mark review as helpful",
-			"error":   err.Error(),
+		return apperrors.Internal("Failed to save reply", err)
+	}
+
+	if create {
+		err := notify.Create(ctx, h.DB, models.Notification{
+			UserID:      before.UserID,
+			Type:        notify.TypeProduct,
+			Title:       "The store replied to your review",
+			Message:     req.Text,
+			ReferenceID: before.ProductID,
 		})
+		if err != nil {
+			log.Printf("[NOTIFY] Failed to notify user %s of reply to review %s: %v", before.UserID.Hex(), reviewID.Hex(), err)
+		}
+	}
+
+	var updated models.Review
+	if err := h.DB.Collections().Reviews.FindOne(ctx, bson.M{"_id": reviewID}).Decode(&updated); err != nil {
+		return apperrors.Internal("Failed to retrieve review", err)
+	}
+	action := "review.reply.update"
+	if create {
+		action = "review.reply"
+	}
+	recordAudit(c, h.DB.MongoDB, action, "review", reviewID.Hex(), before.Reply, updated.Reply)
+
+	status := fiber.StatusOK
+	if create {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": "Reply saved successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteReviewReply removes the store's response to a review
+// DELETE /admin/reviews/:id/reply
+func (h *ReviewHandler) DeleteReviewReply(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	reviewID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid review ID", err)
+	}
+
+	var before models.Review
+	err = h.DB.Collections().Reviews.FindOneAndUpdate(ctx,
+		bson.M{"_id": reviewID, "reply": bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{"reply": ""}},
+	).Decode(&before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.NotFound("Review not found or has no reply")
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to delete reply", err)
 	}
+	recordAudit(c, h.DB.MongoDB, "review.reply.delete", "review", reviewID.Hex(), before.Reply, nil)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Review marked as helpful",
+		"message": "Reply deleted successfully",
 	})
 }
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// roleNamePattern keeps role names short, lowercase slugs, e.g. "order-desk"
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// RoleHandler manages the roles staff accounts are given
+type RoleHandler struct {
+	DB *database.DBClient
+}
+
+// NewRoleHandler creates a new instance of RoleHandler
+func NewRoleHandler(db *database.DBClient) *RoleHandler {
+	return &RoleHandler{DB: db}
+}
+
+// hasPermission reports whether the current user's role grants permission.
+// A failed lookup denies.
+func hasPermission(c *fiber.Ctx, db *database.DBClient, permission string) bool {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return false
+	}
+	allowed, err := middleware.HasPermission(c.Context(), db, user.Role, permission)
+	if err != nil {
+		log.Printf("[AUTH] Failed to check %s for role %s: %v", permission, user.Role, err)
+		return false
+	}
+	return allowed
+}
+
+// GetRoles lists every role along with the permissions roles can grant
+// GET /admin/roles
+func (h *RoleHandler) GetRoles(c *fiber.Ctx) error {
+	ctx := c.Context()
+	cursor, err := h.DB.Collections().Roles.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch roles", err)
+	}
+	roles := []models.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return apperrors.Internal("Failed to decode roles", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Roles retrieved successfully",
+		"data":    roles,
+		"meta":    fiber.Map{"permissions": models.Permissions},
+	})
+}
+
+// GetMyPermissions returns the current account's role and the permissions it
+// grants, so the admin panel can hide what the account can't use
+// GET /admin/me
+func (h *RoleHandler) GetMyPermissions(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized")
+	}
+	granted := []string{}
+	for _, permission := range models.Permissions {
+		if hasPermission(c, h.DB, permission) {
+			granted = append(granted, permission)
+		}
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Permissions retrieved successfully",
+		"data":    fiber.Map{"role": user.Role, "permissions": granted},
+	})
+}
+
+// PutRole creates or updates a staff role. The built-in admin and user roles
+// can't be changed.
+// PUT /admin/roles/:name
+func (h *RoleHandler) PutRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	name := strings.ToLower(c.Params("name"))
+	if !roleNamePattern.MatchString(name) {
+		return apperrors.BadRequest("Role name must be 2-32 lowercase letters, digits, '-' or '_', starting with a letter", nil)
+	}
+	var req models.RoleRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if req.Permissions == nil {
+		req.Permissions = []string{}
+	}
+
+	var before *models.Role
+	if existing, err := h.find(ctx, name); err == nil {
+		before = &existing
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Internal("Failed to retrieve role", err)
+	}
+	if name == models.RoleAdmin || name == models.RoleUser || (before != nil && before.BuiltIn) {
+		return apperrors.BadRequest("Built-in roles cannot be changed", nil)
+	}
+
+	now := time.Now()
+	_, err := h.DB.Collections().Roles.UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{
+			"$set": bson.M{
+				"description": strings.TrimSpace(req.Description),
+				"permissions": req.Permissions,
+				"updated_at":  now,
+			},
+			"$setOnInsert": bson.M{"built_in": false, "created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return apperrors.Internal("Failed to save role", err)
+	}
+	h.DB.CacheDel(ctx, middleware.RoleCacheKey(name))
+	role, err := h.find(ctx, name)
+	if err != nil {
+		return apperrors.Internal("Failed to retrieve role", err)
+	}
+
+	status, message := fiber.StatusOK, "Role updated successfully"
+	if before == nil {
+		status, message = fiber.StatusCreated, "Role created successfully"
+		recordAudit(c, h.DB.MongoDB, "role.create", "role", name, nil, role)
+	} else {
+		recordAudit(c, h.DB.MongoDB, "role.update", "role", name, before, role)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    role,
+	})
+}
+
+// DeleteRole removes a staff role no account holds any more
+// DELETE /admin/roles/:name
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	name := strings.ToLower(c.Params("name"))
+	role, err := h.find(ctx, name)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Role not found")
+		}
+		return apperrors.Internal("Failed to retrieve role", err)
+	}
+	if role.BuiltIn {
+		return apperrors.BadRequest("Built-in roles cannot be deleted", nil)
+	}
+	holders, err := h.DB.Collections().Users.CountDocuments(ctx, bson.M{"role": name})
+	if err != nil {
+		return apperrors.Internal("Failed to count role holders", err)
+	}
+	if holders > 0 {
+		return apperrors.Conflict(fmt.Sprintf("Role is held by %d account(s); move them to another role first", holders))
+	}
+
+	if _, err := h.DB.Collections().Roles.DeleteOne(ctx, bson.M{"_id": name}); err != nil {
+		return apperrors.Internal("Failed to delete role", err)
+	}
+	h.DB.CacheDel(ctx, middleware.RoleCacheKey(name))
+	recordAudit(c, h.DB.MongoDB, "role.delete", "role", name, role, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Role deleted successfully",
+	})
+}
+
+func (h *RoleHandler) find(ctx context.Context, name string) (models.Role, error) {
+	var role models.Role
+	err := h.DB.Collections().Roles.FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	return role, err
+}
@@ -1,25 +1,32 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // SettingsHandler handles settings related operations
 type SettingsHandler struct {
-	DB *mongo.Database
+	DB      *mongo.Database
+	Storage storage.Storage
+	Config  *config.Config // Optional; refuses payment modes without keys when set
 }
 
 // NewSettingsHandler creates a new settings handler
-func NewSettingsHandler(db *mongo.Database) *SettingsHandler {
+func NewSettingsHandler(db *mongo.Database, store storage.Storage) *SettingsHandler {
 	return &SettingsHandler{
-		DB: db,
+		DB:      db,
+		Storage: store,
 	}
 }
 
@@ -53,11 +60,7 @@ func (h *SettingsHandler) GetSettings() fiber.Handler {
 					"data":    defaultSettings,
 				})
 			}
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Error retrieving settings",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Error retrieving settings", err)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -73,11 +76,7 @@ func (h *SettingsHandler) UpdateSettings() fiber.Handler {
 		// Parse the update request
 		var updateRequest models.UpdateSettingsRequest
 		if err := c.BodyParser(&updateRequest); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid request data",
-				"error":   err.Error(),
-			})
+			return apperrors.BadRequest("Invalid request data", err)
 		}
 
 		collection := h.DB.Collection("settings")
@@ -117,9 +116,31 @@ func (h *SettingsHandler) UpdateSettings() fiber.Handler {
 		if len(updateRequest.ShippingMethods) > 0 {
 			updateSet["shipping_methods"] = updateRequest.ShippingMethods
 		}
+		if updateRequest.GiftOptions != nil {
+			if err := validateRequest(updateRequest.GiftOptions); err != nil {
+				return err
+			}
+			updateSet["gift_options"] = updateRequest.GiftOptions
+		}
+		if updateRequest.Reports != nil {
+			if err := validateRequest(updateRequest.Reports); err != nil {
+				return err
+			}
+			updateSet["reports"] = updateRequest.Reports
+		}
 		if len(updateRequest.PaymentGateways) > 0 {
 			updateSet["payment_gateways"] = updateRequest.PaymentGateways
 		}
+		if updateRequest.PaymentMode != nil {
+			mode := *updateRequest.PaymentMode
+			if mode != models.PaymentModeLive && mode != models.PaymentModeTest {
+				return apperrors.BadRequest("paymentMode must be live or test", nil)
+			}
+			if h.Config != nil && !h.Config.Razorpay(mode).Configured() {
+				return apperrors.BadRequest("Razorpay keys for "+mode+" mode are not configured", nil)
+			}
+			updateSet["payment_mode"] = mode
+		}
 		if updateRequest.SocialMedia != nil {
 			updateSet["social_media"] = updateRequest.SocialMedia
 		}
@@ -139,6 +160,13 @@ func (h *SettingsHandler) UpdateSettings() fiber.Handler {
 			updateSet["maintenance_mode"] = *updateRequest.MaintenanceMode
 		}
 
+		// Capture the current settings for the audit trail
+		var previousSettings *models.Settings
+		var existing models.Settings
+		if err := collection.FindOne(ctx, bson.M{}).Decode(&existing); err == nil {
+			previousSettings = &existing
+		}
+
 		// Find one and update (or insert if not exists)
 		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 		var updatedSettings models.Settings
@@ -150,13 +178,11 @@ func (h *SettingsHandler) UpdateSettings() fiber.Handler {
 		).Decode(&updatedSettings)
 
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Error updating settings",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Error updating settings", err)
 		}
 
+		recordAudit(c, h.DB, "settings.update", "settings", updatedSettings.ID.Hex(), previousSettings, updatedSettings)
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"success": true,
 			"message": "Settings updated successfully",
@@ -171,39 +197,30 @@ func (h *SettingsHandler) UploadLogo() fiber.Handler {
 		// Get the file from form
 		file, err := c.FormFile("logo")
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "No logo file provided",
-				"error":   err.Error(),
-			})
+			return apperrors.BadRequest("No logo file provided", err)
 		}
 
 		// Check file type
 		contentType := file.Header.Get("Content-Type")
 		if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/webp" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid file type. Only JPEG, PNG or WEBP allowed",
-			})
+			return apperrors.BadRequest("Invalid file type. Only JPEG, PNG or WEBP allowed", nil)
 		}
 
-		// Generate a unique filename
-		filename := primitive.NewObjectID().Hex() + "-" + file.Filename
-
-		// Save the file
-		if err := c.SaveFile(file, "./uploads/"+filename); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Error saving logo",
-				"error":   err.Error(),
-			})
+		// Store the file
+		logo, err := uploadFormFile(context.Background(), h.Storage, "settings", file)
+		if err != nil {
+			var appErr *apperrors.Error
+			if errors.As(err, &appErr) {
+				return appErr
+			}
+			return apperrors.Internal("Error saving logo", err)
 		}
+		logoURL := logo.URL
 
 		// Update the settings with the new logo URL
 		collection := h.DB.Collection("settings")
 		ctx := c.Context()
 
-		logoURL := "/uploads/" + filename
 		update := bson.M{
 			"$set": bson.M{
 				"logo":       logoURL,
@@ -211,6 +228,12 @@ func (h *SettingsHandler) UploadLogo() fiber.Handler {
 			},
 		}
 
+		var previousSettings *models.Settings
+		var existing models.Settings
+		if err := collection.FindOne(ctx, bson.M{}).Decode(&existing); err == nil {
+			previousSettings = &existing
+		}
+
 		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 		var updatedSettings models.Settings
 		err = collection.FindOneAndUpdate(
@@ -221,13 +244,16 @@ func (h *SettingsHandler) UploadLogo() fiber.Handler {
 		).Decode(&updatedSettings)
 
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Error updating logo in settings",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Error updating logo in settings", err)
+		}
+
+		// The replaced logo is no longer referenced anywhere
+		if previousSettings != nil && previousSettings.Logo != "" && previousSettings.Logo != logoURL {
+			storage.DeleteURLs(ctx, h.Storage, []string{previousSettings.Logo})
 		}
 
+		recordAudit(c, h.DB, "settings.logo_upload", "settings", updatedSettings.ID.Hex(), previousSettings, updatedSettings)
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"success": true,
 			"message": "Logo uploaded successfully",
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+)
+
+// AdjustStock atomically changes a product's stock and records the movement
+// POST /admin/products/:id/stock-adjustments
+func (h *ProductHandler) AdjustStock(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objectID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	switch req.Reason {
+	case models.StockReasonPurchase, models.StockReasonReturn:
+		if req.Quantity < 0 {
+			return apperrors.BadRequest("Purchases and returns must add stock", nil)
+		}
+	case models.StockReasonDamage:
+		if req.Quantity > 0 {
+			return apperrors.BadRequest("Damage must remove stock", nil)
+		}
+	}
+
+	// Guard the decrement in the filter so concurrent adjustments and
+	// checkouts can never drive stock below zero
+	filter := bson.M{"_id": objectID}
+	if req.Quantity < 0 {
+		filter["stock"] = bson.M{"$gte": -req.Quantity}
+	}
+	update := bson.M{
+		"$inc": bson.M{"stock": req.Quantity},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"stock": 1})
+
+	products := h.DB.Collections().Products
+	var product models.Product
+	if err := products.FindOneAndUpdate(ctx, filter, update, opts).Decode(&product); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.Internal("Failed to adjust stock", err)
+		}
+		if n, cerr := products.CountDocuments(ctx, bson.M{"_id": objectID}); cerr == nil && n == 0 {
+			return apperrors.NotFound("Product not found")
+		}
+		return apperrors.BadRequest("Adjustment would make stock negative", nil)
+	}
+
+	movement := models.StockMovement{
+		ProductID:  objectID,
+		Delta:      req.Quantity,
+		StockAfter: product.Stock,
+		Reason:     req.Reason,
+		Note:       req.Note,
+	}
+	movement = adminStockMovement(c, movement)
+	if err := recordStockMovement(ctx, h.DB, &movement); err != nil {
+		return apperrors.Internal("Stock adjusted but the movement could not be recorded", err)
+	}
+
+	h.DB.InvalidateProductCaches(ctx, id)
+	recordAudit(c, h.DB.MongoDB, "product.stock_adjust", "product", id, nil, movement)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock adjusted successfully",
+		"data":    movement,
+	})
+}
+
+// stockUpdate sets one product's stock, or changes it by Delta
+type stockUpdate struct {
+	ProductID string `json:"productId" validate:"required"`
+	Stock     *int   `json:"stock,omitempty" validate:"omitempty,min=0"`
+	Delta     *int   `json:"delta,omitempty"`
+}
+
+// bulkStockRequest updates the stock of many products at once, e.g. from a
+// count at the physical store
+type bulkStockRequest struct {
+	Updates []stockUpdate `json:"updates" validate:"required,min=1,max=1000,dive"`
+	Note    string        `json:"note,omitempty" validate:"max=500"`
+}
+
+// bulkStockResult is a product whose stock a bulk update changed
+type bulkStockResult struct {
+	ProductID     string `json:"productId"`
+	PreviousStock int    `json:"previousStock"`
+	Stock         int    `json:"stock"`
+}
+
+// bulkStockFailure is an entry a bulk update skipped and why
+type bulkStockFailure struct {
+	ProductID string `json:"productId"`
+	Error     string `json:"error"`
+}
+
+// BulkUpdateStock sets or adjusts the stock of up to 1000 products in one
+// bulk write. Entries that name an unknown product, would make stock
+// negative, or repeat a product are reported in data.failed and don't stop
+// the others. Each change is recorded in the stock ledger as a correction.
+// PATCH /admin/products/stock
+func (h *ProductHandler) BulkUpdateStock(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req bulkStockRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	failed := []bulkStockFailure{}
+	ids := make([]primitive.ObjectID, 0, len(req.Updates))
+	seen := make(map[primitive.ObjectID]bool, len(req.Updates))
+	for _, u := range req.Updates {
+		id, err := primitive.ObjectIDFromHex(u.ProductID)
+		switch {
+		case err != nil:
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Invalid product ID format"})
+		case (u.Stock == nil) == (u.Delta == nil):
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Give either stock or delta"})
+		case seen[id]:
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Product listed more than once"})
+		default:
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	products := h.DB.Collections().Products
+	current, err := stockLevels(ctx, products, ids)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch product stock", err)
+	}
+
+	// Work out each product's new stock. The write is guarded by the stock
+	// it was computed from, so a sale in between leaves the product alone.
+	planned := make(map[primitive.ObjectID]int, len(ids))
+	writes := make([]mongo.WriteModel, 0, len(ids))
+	now := time.Now()
+	for _, u := range req.Updates {
+		id, err := primitive.ObjectIDFromHex(u.ProductID)
+		if err != nil || !seen[id] {
+			continue
+		}
+		old, ok := current[id]
+		if !ok {
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Product not found"})
+			continue
+		}
+		stock := old
+		if u.Stock != nil {
+			stock = *u.Stock
+		} else {
+			stock += *u.Delta
+		}
+		if stock < 0 {
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Update would make stock negative"})
+			continue
+		}
+		if stock == old {
+			continue
+		}
+		planned[id] = stock
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id, "stock": old}).
+			SetUpdate(bson.M{"$set": bson.M{"stock": stock, "updated_at": now}}))
+	}
+	if len(writes) > 0 {
+		// Some writes may have gone through; reading the stock back below
+		// tells which
+		if _, err := products.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			log.Printf("[STOCK] Bulk stock update failed: %v", err)
+		}
+	}
+
+	// Products whose stock moved in the meantime weren't written
+	changedIDs := make([]primitive.ObjectID, 0, len(planned))
+	for id := range planned {
+		changedIDs = append(changedIDs, id)
+	}
+	after, err := stockLevels(ctx, products, changedIDs)
+	if err != nil {
+		return apperrors.Internal("Stock updated but could not be read back", err)
+	}
+	updated := []bulkStockResult{}
+	invalidate := make([]string, 0, len(planned))
+	for _, u := range req.Updates {
+		id, err := primitive.ObjectIDFromHex(u.ProductID)
+		stock, ok := planned[id]
+		if err != nil || !ok {
+			continue
+		}
+		if after[id] != stock {
+			failed = append(failed, bulkStockFailure{ProductID: u.ProductID, Error: "Stock changed during the update, retry it"})
+			continue
+		}
+		old := current[id]
+		movement := adminStockMovement(c, models.StockMovement{
+			ProductID:  id,
+			Delta:      stock - old,
+			StockAfter: stock,
+			Reason:     models.StockReasonCorrection,
+			Note:       req.Note,
+		})
+		logStockMovement(ctx, h.DB, movement)
+		recordAudit(c, h.DB.MongoDB, "product.stock_adjust", "product", u.ProductID, bson.M{"stock": old}, bson.M{"stock": stock})
+		updated = append(updated, bulkStockResult{ProductID: u.ProductID, PreviousStock: old, Stock: stock})
+		invalidate = append(invalidate, u.ProductID)
+	}
+	if len(invalidate) > 0 {
+		h.DB.InvalidateProductCaches(ctx, invalidate...)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("%d of %d products updated", len(updated), len(req.Updates)),
+		"data": fiber.Map{
+			"updated": updated,
+			"failed":  failed,
+		},
+	})
+}
+
+// stockLevels returns the stock of the given products that exist
+func stockLevels(ctx context.Context, products *mongo.Collection, ids []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	levels := make(map[primitive.ObjectID]int, len(ids))
+	if len(ids) == 0 {
+		return levels, nil
+	}
+	cursor, err := products.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"stock": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Stock int                `bson:"stock"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		levels[d.ID] = d.Stock
+	}
+	return levels, nil
+}
+
+// GetStockMovements lists a product's stock ledger, newest first
+// GET /admin/products/:id/stock-movements?reason=&page=1&limit=50
+func (h *ProductHandler) GetStockMovements(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid product ID", err)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	filter := bson.M{"product_id": objectID}
+	if reason := c.Query("reason"); reason != "" {
+		filter["reason"] = reason
+	}
+
+	coll := h.DB.Collections().StockMovements
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count stock movements", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch stock movements", err)
+	}
+	defer cursor.Close(ctx)
+
+	movements := []models.StockMovement{}
+	if err := cursor.All(ctx, &movements); err != nil {
+		return apperrors.Internal("Failed to decode stock movements", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stock movements retrieved successfully",
+		"data":    movements,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// recordStockMovement appends an entry to the stock ledger. A movement that
+// brings a sold-out product back in stock also notifies its wishlisters.
+func recordStockMovement(ctx context.Context, db *database.DBClient, movement *models.StockMovement) error {
+	movement.ID = primitive.NewObjectID()
+	movement.CreatedAt = time.Now()
+	if _, err := db.Collections().StockMovements.InsertOne(ctx, movement); err != nil {
+		return err
+	}
+
+	if err := notify.StockChanged(ctx, db, movement.ProductID, movement.StockAfter-movement.Delta, movement.StockAfter); err != nil {
+		log.Printf("[NOTIFY] Failed to send back-in-stock notifications for product %s: %v", movement.ProductID.Hex(), err)
+	}
+	return nil
+}
+
+// adminStockMovement attributes a movement to the authenticated admin
+func adminStockMovement(c *fiber.Ctx, movement models.StockMovement) models.StockMovement {
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		movement.ActorID = actor.UserID
+	}
+	return movement
+}
+
+// logStockMovement records a movement produced as a side effect of another
+// operation (orders, product edits). Failures are logged and never block it.
+func logStockMovement(ctx context.Context, db *database.DBClient, movement models.StockMovement) {
+	if err := recordStockMovement(ctx, db, &movement); err != nil {
+		log.Printf("[STOCK] Failed to record %s movement for product %s: %v", movement.Reason, movement.ProductID.Hex(), err)
+	}
+}
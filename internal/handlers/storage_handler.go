@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// StorageHandler reports on files held in storage
+type StorageHandler struct {
+	DB      *database.DBClient
+	Config  *config.Config
+	Storage storage.Storage
+}
+
+// NewStorageHandler creates a new instance of StorageHandler
+func NewStorageHandler(db *database.DBClient, cfg *config.Config, store storage.Storage) *StorageHandler {
+	return &StorageHandler{DB: db, Config: cfg, Storage: store}
+}
+
+// GetOrphans is a dry run of the cleanup job: it lists the files the job
+// would delete without deleting them
+// GET /admin/storage/orphans?minAgeDays=
+func (h *StorageHandler) GetOrphans(c *fiber.Ctx) error {
+	minAgeDays := h.Config.ImageCleanupMinAgeDays
+	if raw := c.Query("minAgeDays"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			return apperrors.BadRequest("minAgeDays must be a non-negative integer", err)
+		}
+		minAgeDays = days
+	}
+
+	cleaner := &jobs.ImageCleaner{DB: h.DB, Storage: h.Storage, MinAge: time.Duration(minAgeDays) * 24 * time.Hour}
+	orphans, err := cleaner.Orphans(c.Context())
+	if err != nil {
+		return apperrors.Internal("Failed to find orphaned files", err)
+	}
+
+	var totalBytes int64
+	for _, obj := range orphans {
+		totalBytes += obj.Size
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Orphaned files retrieved successfully",
+		"data": fiber.Map{
+			"minAgeDays":     minAgeDays,
+			"cleanupEnabled": h.Config.ImageCleanupIntervalHours > 0,
+			"count":          len(orphans),
+			"totalBytes":     totalBytes,
+			"files":          orphans,
+		},
+	})
+}
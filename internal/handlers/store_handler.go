@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Bounds of GET /stores/nearby
+const (
+	defaultNearbyStores = 5
+	maxNearbyStores     = 20
+	maxNearbyRadiusKm   = 1000
+)
+
+// StoreHandler manages the physical boutiques shown on the store locator
+type StoreHandler struct {
+	DB *database.DBClient
+}
+
+// NewStoreHandler creates a new instance of StoreHandler
+func NewStoreHandler(db *database.DBClient) *StoreHandler {
+	return &StoreHandler{DB: db}
+}
+
+// storeFromRequest validates req and builds the store it describes
+func storeFromRequest(req models.StoreRequest) (models.Store, error) {
+	store := models.Store{
+		Name:     strings.TrimSpace(req.Name),
+		Address:  req.Address,
+		Location: models.NewGeoPoint(*req.Latitude, *req.Longitude),
+		Hours:    req.Hours,
+		Phone:    strings.TrimSpace(req.Phone),
+		Email:    req.Email,
+		Active:   req.Active == nil || *req.Active,
+		Position: req.Position,
+	}
+	if store.Hours == nil {
+		store.Hours = []models.StoreHours{}
+	}
+	seen := map[string]bool{}
+	for i, h := range store.Hours {
+		if seen[h.Day] {
+			return store, apperrors.BadRequest(fmt.Sprintf("Opening hours list %s twice", h.Day), nil)
+		}
+		seen[h.Day] = true
+		if h.Closed {
+			store.Hours[i].Opens, store.Hours[i].Closes = "", ""
+			continue
+		}
+		// Times are zero-padded, so they compare as strings
+		if h.Opens == "" || h.Closes == "" || h.Opens >= h.Closes {
+			return store, apperrors.BadRequest(fmt.Sprintf("Opening hours on %s need an opening time before the closing time", h.Day), nil)
+		}
+	}
+	return store, nil
+}
+
+// GetStores lists the active boutiques in display order
+// GET /stores
+func (h *StoreHandler) GetStores(c *fiber.Ctx) error {
+	stores, err := h.findStores(c.Context(), bson.M{"active": true})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stores retrieved successfully",
+		"data":    stores,
+	})
+}
+
+// GetNearbyStores lists the active boutiques nearest a point, closest first,
+// with their distance from it
+// GET /stores/nearby?lat=&lng=&radiusKm=&limit=
+func (h *StoreHandler) GetNearbyStores(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return apperrors.BadRequest("lat must be a latitude between -90 and 90", nil)
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return apperrors.BadRequest("lng must be a longitude between -180 and 180", nil)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultNearbyStores)))
+	if limit < 1 || limit > maxNearbyStores {
+		limit = defaultNearbyStores
+	}
+	geoNear := bson.M{
+		"near":          models.NewGeoPoint(lat, lng),
+		"distanceField": "distance",
+		"spherical":     true,
+		"query":         bson.M{"active": true},
+	}
+	if raw := c.Query("radiusKm"); raw != "" {
+		radius, err := strconv.ParseFloat(raw, 64)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusKm {
+			return apperrors.BadRequest(fmt.Sprintf("radiusKm must be between 0 and %d", maxNearbyRadiusKm), nil)
+		}
+		geoNear["maxDistance"] = radius * 1000
+	}
+
+	cursor, err := h.DB.Collections().Stores.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$geoNear", Value: geoNear}},
+		{{Key: "$limit", Value: limit}},
+	})
+	if err != nil {
+		return apperrors.Internal("Failed to find nearby stores", err)
+	}
+	var rows []struct {
+		models.Store `bson:",inline"`
+		Distance     float64 `bson:"distance"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return apperrors.Internal("Failed to decode stores", err)
+	}
+	type storeWithDistance struct {
+		models.Store
+		DistanceKm float64 `json:"distanceKm"`
+	}
+	data := make([]storeWithDistance, 0, len(rows))
+	for _, r := range rows {
+		data = append(data, storeWithDistance{Store: r.Store, DistanceKm: math.Round(r.Distance/10) / 100})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Nearby stores retrieved successfully",
+		"data":    data,
+	})
+}
+
+// ListStores lists every boutique, inactive ones included
+// GET /admin/stores
+func (h *StoreHandler) ListStores(c *fiber.Ctx) error {
+	stores, err := h.findStores(c.Context(), bson.M{})
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Stores retrieved successfully",
+		"data":    stores,
+	})
+}
+
+// CreateStore adds a boutique
+// POST /admin/stores
+func (h *StoreHandler) CreateStore(c *fiber.Ctx) error {
+	var req models.StoreRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	store, err := storeFromRequest(req)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	store.ID = primitive.NewObjectID()
+	store.CreatedAt = now
+	store.UpdatedAt = now
+
+	if _, err := h.DB.Collections().Stores.InsertOne(c.Context(), store); err != nil {
+		return apperrors.Internal("Failed to create store", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "store.create", "store", store.ID.Hex(), nil, store)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Store created successfully",
+		"data":    store,
+	})
+}
+
+// UpdateStore edits a boutique
+// PUT /admin/stores/:id
+func (h *StoreHandler) UpdateStore(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid store ID", err)
+	}
+	var req models.StoreRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	store, err := storeFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var before models.Store
+	err = h.DB.Collections().Stores.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{
+		"name":       store.Name,
+		"address":    store.Address,
+		"location":   store.Location,
+		"hours":      store.Hours,
+		"phone":      store.Phone,
+		"email":      store.Email,
+		"active":     store.Active,
+		"position":   store.Position,
+		"updated_at": time.Now(),
+	}}).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Store not found")
+		}
+		return apperrors.Internal("Failed to update store", err)
+	}
+
+	updated, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, h.DB.MongoDB, "store.update", "store", objectID.Hex(), before, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Store updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteStore removes a boutique
+// DELETE /admin/stores/:id
+func (h *StoreHandler) DeleteStore(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid store ID", err)
+	}
+	store, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+	if _, err := h.DB.Collections().Stores.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+		return apperrors.Internal("Failed to delete store", err)
+	}
+	recordAudit(c, h.DB.MongoDB, "store.delete", "store", objectID.Hex(), store, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Store deleted successfully",
+	})
+}
+
+func (h *StoreHandler) findStores(ctx context.Context, filter bson.M) ([]models.Store, error) {
+	cursor, err := h.DB.Collections().Stores.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, apperrors.Internal("Failed to fetch stores", err)
+	}
+	stores := []models.Store{}
+	if err := cursor.All(ctx, &stores); err != nil {
+		return nil, apperrors.Internal("Failed to decode stores", err)
+	}
+	return stores, nil
+}
+
+func (h *StoreHandler) find(ctx context.Context, id primitive.ObjectID) (models.Store, error) {
+	var store models.Store
+	if err := h.DB.Collections().Stores.FindOne(ctx, bson.M{"_id": id}).Decode(&store); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return store, apperrors.NotFound("Store not found")
+		}
+		return store, apperrors.Internal("Failed to fetch store", err)
+	}
+	return store, nil
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// SupportHandler handles customer support tickets. A ticket is a chat
+// conversation; its messages are chat messages.
+type SupportHandler struct {
+	DB     *database.DBClient
+	Events *events.Bus // Optional; receives a support.message event per message
+}
+
+// NewSupportHandler creates a new instance of SupportHandler
+func NewSupportHandler(db *database.DBClient) *SupportHandler {
+	return &SupportHandler{DB: db}
+}
+
+// CreateTicket opens a support ticket with its first message
+// POST /support/tickets
+func (h *SupportHandler) CreateTicket(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	var req models.CreateTicketRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ticket := models.ChatConversation{
+		ID:            primitive.NewObjectID(),
+		UserID:        user.UserID,
+		Title:         strings.TrimSpace(req.Subject),
+		Status:        models.SupportOpen,
+		LastMessageAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.OrderID != "" {
+		orderID, err := parseObjectID(req.OrderID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid order ID format", err)
+		}
+		// Customers may only refer to their own orders
+		err = h.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": orderID, "user_id": user.UserID}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return apperrors.NotFound("Order not found")
+			}
+			return apperrors.Internal("Failed to retrieve order", err)
+		}
+		ticket.OrderID = &orderID
+	}
+	message := models.ChatMessage{
+		ID:             primitive.NewObjectID(),
+		ConversationID: ticket.ID,
+		UserID:         user.UserID,
+		Content:        strings.TrimSpace(req.Message),
+		Timestamp:      now,
+	}
+
+	if _, err := h.DB.Collections().ChatConversations.InsertOne(ctx, ticket); err != nil {
+		return apperrors.Internal("Failed to create support ticket", err)
+	}
+	if _, err := h.DB.Collections().ChatMessages.InsertOne(ctx, message); err != nil {
+		h.DB.Collections().ChatConversations.DeleteOne(ctx, bson.M{"_id": ticket.ID})
+		return apperrors.Internal("Failed to create support ticket", err)
+	}
+	h.publish(ctx, ticket, message)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Support ticket created successfully",
+		"data":    models.ChatConversationResponse{ChatConversation: ticket, Messages: []models.ChatMessage{message}},
+	})
+}
+
+// GetMyTickets lists the current user's support tickets, most recently
+// active first
+// GET /support/tickets?status=&page=1&limit=20
+func (h *SupportHandler) GetMyTickets(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	filter := bson.M{"user_id": user.UserID}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	return h.listTickets(c, filter)
+}
+
+// GetInbox lists support tickets for staff, optionally by status. Open
+// tickets are waiting for a reply.
+// GET /admin/support/tickets?status=open&page=1&limit=20
+func (h *SupportHandler) GetInbox(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if userID := c.Query("userId"); userID != "" {
+		id, err := parseObjectID(userID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid user ID", err)
+		}
+		filter["user_id"] = id
+	}
+	return h.listTickets(c, filter)
+}
+
+func (h *SupportHandler) listTickets(c *fiber.Ctx, filter bson.M) error {
+	ctx := c.Context()
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	coll := h.DB.Collections().ChatConversations
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return apperrors.Internal("Failed to count support tickets", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_message_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return apperrors.Internal("Failed to fetch support tickets", err)
+	}
+	defer cursor.Close(ctx)
+
+	tickets := []models.ChatConversation{}
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return apperrors.Internal("Failed to decode support tickets", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Support tickets retrieved successfully",
+		"data":    tickets,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetTicket returns a support ticket with its messages to its owner or staff
+// GET /support/tickets/:id
+func (h *SupportHandler) GetTicket(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	ticketID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid ticket ID", err)
+	}
+	ticket, err := h.find(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok || (ticket.UserID != user.UserID && !hasPermission(c, h.DB, models.PermissionSupportWrite)) {
+		return apperrors.Forbidden("Not authorized to view this ticket")
+	}
+
+	cursor, err := h.DB.Collections().ChatMessages.Find(ctx,
+		bson.M{"conversation_id": ticketID},
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch ticket messages", err)
+	}
+	messages := []models.ChatMessage{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return apperrors.Internal("Failed to decode ticket messages", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Support ticket retrieved successfully",
+		"data":    models.ChatConversationResponse{ChatConversation: ticket, Messages: messages},
+	})
+}
+
+// ReplyToTicket adds the customer's message to their ticket, reopening it
+// if it was pending or resolved
+// POST /support/tickets/:id/messages
+func (h *SupportHandler) ReplyToTicket(c *fiber.Ctx) error {
+	return h.reply(c, false)
+}
+
+// StaffReply answers a ticket. The ticket waits for the customer (pending)
+// unless the reply sets another status, e.g. resolved.
+// POST /admin/support/tickets/:id/messages
+func (h *SupportHandler) StaffReply(c *fiber.Ctx) error {
+	return h.reply(c, true)
+}
+
+func (h *SupportHandler) reply(c *fiber.Ctx, staff bool) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	ticketID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid ticket ID", err)
+	}
+	var req models.TicketReplyRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	ticket, err := h.find(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if !staff && ticket.UserID != user.UserID {
+		return apperrors.Forbidden("Not authorized to reply to this ticket")
+	}
+
+	now := time.Now()
+	status := models.SupportOpen
+	set := bson.M{"last_message_at": now, "updated_at": now}
+	if staff {
+		status = models.SupportPending
+		if req.Status != "" {
+			status = req.Status
+		}
+		set["last_staff_reply_at"] = now
+	}
+	set["status"] = status
+
+	message := models.ChatMessage{
+		ID:             primitive.NewObjectID(),
+		ConversationID: ticketID,
+		UserID:         user.UserID,
+		Content:        strings.TrimSpace(req.Message),
+		IsStaff:        staff,
+		Timestamp:      now,
+	}
+	if _, err := h.DB.Collections().ChatMessages.InsertOne(ctx, message); err != nil {
+		return apperrors.Internal("Failed to add message", err)
+	}
+	if _, err := h.DB.Collections().ChatConversations.UpdateOne(ctx, bson.M{"_id": ticketID}, bson.M{"$set": set}); err != nil {
+		return apperrors.Internal("Failed to update support ticket", err)
+	}
+	ticket.Status = status
+	ticket.LastMessageAt = now
+	ticket.UpdatedAt = now
+	if staff {
+		ticket.LastStaffReplyAt = &now
+	}
+	h.publish(ctx, ticket, message)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Message added successfully",
+		"data":    message,
+	})
+}
+
+// UpdateTicketStatus changes a ticket's status without replying
+// PATCH /admin/support/tickets/:id/status
+func (h *SupportHandler) UpdateTicketStatus(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	ticketID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid ticket ID", err)
+	}
+	var req models.TicketStatusRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var before models.ChatConversation
+	err = h.DB.Collections().ChatConversations.FindOneAndUpdate(ctx,
+		bson.M{"_id": ticketID},
+		bson.M{"$set": bson.M{"status": req.Status, "updated_at": time.Now()}},
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Support ticket not found")
+		}
+		return apperrors.Internal("Failed to update support ticket", err)
+	}
+	ticket, err := h.find(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	recordAudit(c, h.DB.MongoDB, "support_ticket.status", "support_ticket", ticketID.Hex(), before, ticket)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Support ticket updated successfully",
+		"data":    ticket,
+	})
+}
+
+func (h *SupportHandler) find(ctx context.Context, id primitive.ObjectID) (models.ChatConversation, error) {
+	var ticket models.ChatConversation
+	if err := h.DB.Collections().ChatConversations.FindOne(ctx, bson.M{"_id": id}).Decode(&ticket); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ticket, apperrors.NotFound("Support ticket not found")
+		}
+		return ticket, apperrors.Internal("Failed to retrieve support ticket", err)
+	}
+	return ticket, nil
+}
+
+// publish announces a new message; staff see it live and the customer is
+// notified of staff replies
+func (h *SupportHandler) publish(ctx context.Context, ticket models.ChatConversation, message models.ChatMessage) {
+	h.Events.Publish(ctx, events.SupportMessage, events.SupportTicketMessage{
+		TicketID:  ticket.ID.Hex(),
+		UserID:    ticket.UserID.Hex(),
+		Subject:   ticket.Title,
+		MessageID: message.ID.Hex(),
+		Staff:     message.IsStaff,
+		Status:    ticket.Status,
+	})
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// translatableResource is a kind of document whose text can be translated.
+// Its translatable fields are stored under the same names they are served as.
+type translatableResource struct {
+	Collection string
+	Fields     []string
+	Permission string // Needed to edit its translations
+}
+
+// translatableResources are keyed by the name used in the URL
+var translatableResources = map[string]translatableResource{
+	"hero-slides":     {heroSlidesCollectionName, []string{"title", "subtitle", "description"}, models.PermissionContentWrite},
+	"home-categories": {categoryCardsCollectionName, []string{"title", "subtitle"}, models.PermissionContentWrite},
+	"collections":     {collectionFeaturesCollectionName, []string{"tagline", "title", "description", "availability", "ctaLabel", "imageAlt"}, models.PermissionContentWrite},
+	"products":        {"products", []string{"name", "description"}, models.PermissionProductsWrite},
+}
+
+// TranslationHandler manages the translations of home content and products
+type TranslationHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewTranslationHandler creates a new instance of TranslationHandler
+func NewTranslationHandler(db *database.DBClient, cfg *config.Config) *TranslationHandler {
+	return &TranslationHandler{DB: db, Config: cfg}
+}
+
+// GetTranslations returns a document's source text and its translations
+// GET /admin/translations/:resource/:id
+func (h *TranslationHandler) GetTranslations(c *fiber.Ctx) error {
+	resource, filter, err := h.target(c)
+	if err != nil {
+		return err
+	}
+	projection := bson.M{"translations": 1}
+	for _, field := range resource.Fields {
+		projection[field] = 1
+	}
+	var doc bson.M
+	err = h.DB.MongoDB.Collection(resource.Collection).FindOne(c.Context(), filter, options.FindOne().SetProjection(projection)).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Document not found")
+		}
+		return apperrors.Internal("Failed to retrieve translations", err)
+	}
+
+	source := map[string]string{}
+	for _, field := range resource.Fields {
+		source[field], _ = doc[field].(string)
+	}
+	translations := models.Translations{}
+	if raw, ok := doc["translations"]; ok {
+		data, err := bson.Marshal(raw)
+		if err == nil {
+			err = bson.Unmarshal(data, &translations)
+		}
+		if err != nil {
+			return apperrors.Internal("Failed to decode translations", err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Translations retrieved successfully",
+		"data": fiber.Map{
+			"source":       source,
+			"translations": translations,
+		},
+		"meta": fiber.Map{
+			"defaultLocale": h.Config.DefaultLocale,
+			"locales":       h.Config.Locales,
+			"fields":        resource.Fields,
+		},
+	})
+}
+
+// PutTranslation replaces a document's translations into one locale. Fields
+// left out or empty fall back to the source text.
+// PUT /admin/translations/:resource/:id/:locale {"title": "...", "subtitle": "..."}
+func (h *TranslationHandler) PutTranslation(c *fiber.Ctx) error {
+	resource, filter, err := h.target(c)
+	if err != nil {
+		return err
+	}
+	locale, err := h.locale(c)
+	if err != nil {
+		return err
+	}
+	var req map[string]string
+	if err := c.BodyParser(&req); err != nil {
+		return apperrors.BadRequest("Invalid request body", err)
+	}
+
+	texts := map[string]string{}
+	var fieldErrs []validation.FieldError
+	for field, text := range req {
+		if !slices.Contains(resource.Fields, field) {
+			fieldErrs = append(fieldErrs, validation.FieldError{
+				Field: field, Rule: "translatable",
+				Message: fmt.Sprintf("%s can't be translated; use one of %s", field, strings.Join(resource.Fields, ", ")),
+			})
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			texts[field] = text
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return apperrors.Validation("Validation failed", fieldErrs)
+	}
+
+	update := bson.M{"$set": bson.M{"translations." + locale: texts}}
+	if len(texts) == 0 {
+		update = bson.M{"$unset": bson.M{"translations." + locale: ""}}
+	}
+	return h.update(c, resource, filter, locale, update, "translation.update", texts)
+}
+
+// DeleteTranslation removes a document's translations into one locale
+// DELETE /admin/translations/:resource/:id/:locale
+func (h *TranslationHandler) DeleteTranslation(c *fiber.Ctx) error {
+	resource, filter, err := h.target(c)
+	if err != nil {
+		return err
+	}
+	locale, err := h.locale(c)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$unset": bson.M{"translations." + locale: ""}}
+	return h.update(c, resource, filter, locale, update, "translation.delete", nil)
+}
+
+func (h *TranslationHandler) update(c *fiber.Ctx, resource translatableResource, filter bson.M, locale string, update bson.M, action string, texts map[string]string) error {
+	ctx := c.Context()
+	res, err := h.DB.MongoDB.Collection(resource.Collection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return apperrors.Internal("Failed to save translation", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound("Document not found")
+	}
+
+	id := c.Params("id")
+	if resource.Collection == "products" {
+		h.DB.InvalidateProductCaches(ctx, id)
+	} else {
+		clearHomeContentCache(ctx, h.DB, h.Config)
+	}
+	recordAudit(c, h.DB.MongoDB, action, c.Params("resource"), id, nil, fiber.Map{"locale": locale, "translations": texts})
+
+	message := "Translation saved successfully"
+	if texts == nil {
+		message = "Translation deleted successfully"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    fiber.Map{"locale": locale, "translations": texts},
+	})
+}
+
+// target resolves the resource and document named in the URL, checking the
+// current user may edit it
+func (h *TranslationHandler) target(c *fiber.Ctx) (translatableResource, bson.M, error) {
+	resource, ok := translatableResources[c.Params("resource")]
+	if !ok {
+		return resource, nil, apperrors.NotFound("Unknown translatable resource")
+	}
+	if !hasPermission(c, h.DB, resource.Permission) {
+		return resource, nil, apperrors.Forbidden("Access forbidden - Insufficient permissions")
+	}
+	id, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return resource, nil, apperrors.BadRequest("Invalid ID format", err)
+	}
+	return resource, bson.M{"_id": id}, nil
+}
+
+// locale returns the locale named in the URL, which must be a supported
+// locale other than the default one the source text is written in
+func (h *TranslationHandler) locale(c *fiber.Ctx) (string, error) {
+	locale := strings.ToLower(c.Params("locale"))
+	if !supportedLocale(h.Config, locale) {
+		return "", apperrors.BadRequest(fmt.Sprintf("Locale must be one of %s", strings.Join(h.Config.Locales, ", ")), nil)
+	}
+	if locale == h.Config.DefaultLocale {
+		return "", apperrors.BadRequest("The default locale is the source text; edit the document itself", nil)
+	}
+	return locale, nil
+}
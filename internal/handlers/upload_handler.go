@@ -1,96 +1,251 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
-	"path/filepath"
-	"time"
+	"mime/multipart"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
-	"github.com/shivam-mishra-20/mak-watches-be/internal/firebase"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/imaging"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 )
 
-// UploadHandler handles multipart image uploads and stores them in Firebase Storage
-func UploadHandler(c *fiber.Ctx) error {
-	log.Println("[UPLOAD] Starting upload process...")
+// headerSniffLimit caps how much of a streamed upload is buffered to read
+// the image's dimensions from its header
+const headerSniffLimit = 64 * 1024
 
-	// fallback: try loading config
-	cfg, err := config.LoadConfig()
+// multipartOverhead is the room an upload route's body limit leaves for
+// part headers and form fields besides its files
+const multipartOverhead = 1024 * 1024
+
+// uploadBodyLimit is the body limit of a route accepting up to files images
+func uploadBodyLimit(files int) int {
+	return files*maxImageSize + multipartOverhead
+}
+
+// errFileTooLarge stops a streamed upload that passes maxImageSize
+var errFileTooLarge = errors.New("file too large")
+
+// fileTooLarge is the 413 returned for a file over maxImageSize
+func fileTooLarge(filename string) *apperrors.Error {
+	return apperrors.PayloadTooLarge(filename+" is too large", fmt.Sprintf("Files may be at most %d MB", maxImageSize/(1024*1024)))
+}
+
+// cappedReader fails with errFileTooLarge once more than left bytes are read
+type cappedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	if r.left < 0 {
+		return 0, errFileTooLarge
+	}
+	if int64(len(p)) > r.left+1 {
+		p = p[:r.left+1]
+	}
+	n, err := r.r.Read(p)
+	r.left -= int64(n)
+	if r.left < 0 {
+		return n, errFileTooLarge
+	}
+	return n, err
+}
+
+// UploadHandler stores the images of the "images" form field as uploaded
+// and adds them to the media library. Files are streamed from the request to
+// storage one at a time, so a large upload is never held in memory as a
+// whole.
+func UploadHandler(db *database.DBClient, store storage.Storage) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		assets, err := streamImages(c, store, "images", "images", maxImageFiles)
+		if err != nil {
+			return err
+		}
+		recordMedia(c, db, assets)
+
+		urls := make([]string, len(assets))
+		for i, asset := range assets {
+			urls[i] = asset.URL
+		}
+
+		log.Printf("[UPLOAD] Stored %d files: %v", len(urls), urls)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Upload successful", "data": fiber.Map{"urls": urls}})
+	}
+}
+
+// streamImages reads the multipart body part by part and writes each file
+// of field to storage below prefix as it arrives. Every file must be a JPEG,
+// PNG, GIF or WEBP image (judged by its content, not its name) of at most
+// maxImageSize bytes. Either every file is stored or none is.
+func streamImages(c *fiber.Ctx, store storage.Storage, prefix, field string, maxFiles int) ([]models.MediaAsset, error) {
+	boundary := string(c.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return nil, apperrors.BadRequest("Invalid multipart form", errors.New("expected a multipart/form-data body"))
+	}
+	var body io.Reader
+	if c.Request().IsBodyStream() {
+		body = c.Request().BodyStream()
+	} else {
+		body = bytes.NewReader(c.Body())
+	}
+	reader := multipart.NewReader(body, boundary)
+
+	ctx := context.Background()
+	urls := []string{}
+	assets := []models.MediaAsset{}
+	fail := func(err error) ([]models.MediaAsset, error) {
+		storage.DeleteURLs(ctx, store, urls)
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(apperrors.BadRequest("Invalid multipart form", err))
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			continue
+		}
+		if len(urls) == maxFiles {
+			return fail(apperrors.BadRequest(fmt.Sprintf("At most %d images can be uploaded at once", maxFiles), nil))
+		}
+
+		// The first bytes identify the type; they are stored ahead of the rest
+		head := make([]byte, 512)
+		n, err := io.ReadFull(part, head)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fail(apperrors.BadRequest(part.FileName()+" is empty or unreadable", nil))
+		}
+		head = head[:n]
+		contentType, err := imaging.DetectContentType(head)
+		if err != nil {
+			return fail(apperrors.BadRequest(part.FileName()+" is not a supported image. Only JPEG, PNG, GIF or WEBP allowed", nil))
+		}
+
+		// Whatever reading the dimensions takes from part is replayed to storage
+		var sniffed bytes.Buffer
+		width, height := imaging.Dimensions(io.MultiReader(bytes.NewReader(head), io.TeeReader(io.LimitReader(part, headerSniffLimit), &sniffed)))
+
+		file := &cappedReader{r: io.MultiReader(bytes.NewReader(head), &sniffed, part), left: maxImageSize}
+		url, err := store.Upload(ctx, storage.NewKey(prefix, part.FileName()), file, contentType)
+		if err != nil {
+			if errors.Is(err, errFileTooLarge) {
+				return fail(fileTooLarge(part.FileName()))
+			}
+			log.Printf("[UPLOAD] Failed to store file %s: %v", part.FileName(), err)
+			return fail(apperrors.Internal("Failed to store file", err))
+		}
+		urls = append(urls, url)
+		assets = append(assets, models.MediaAsset{
+			URL:         url,
+			URLs:        []string{url},
+			Filename:    part.FileName(),
+			ContentType: contentType,
+			Size:        maxImageSize - file.left,
+			Width:       width,
+			Height:      height,
+		})
+	}
+	if len(assets) == 0 {
+		return nil, apperrors.BadRequest("No images provided", nil)
+	}
+	return assets, nil
+}
+
+// uploadFormFile stores one multipart file under a fresh key below prefix
+// and describes the stored file. The file must be an image of at most
+// maxImageSize bytes; its content type is taken from its content.
+func uploadFormFile(ctx context.Context, store storage.Storage, prefix string, fh *multipart.FileHeader) (models.MediaAsset, error) {
+	if fh.Size > maxImageSize {
+		return models.MediaAsset{}, fileTooLarge(fh.Filename)
+	}
+	file, err := fh.Open()
 	if err != nil {
-		log.Printf("[UPLOAD] Failed to load config: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to load config", "error": err.Error()})
+		return models.MediaAsset{}, apperrors.Internal("Failed to open file", err)
 	}
-	log.Printf("[UPLOAD] Config loaded. Firebase credentials: %s, bucket: %s", cfg.FirebaseCredentialsPath, cfg.FirebaseBucketName)
+	defer file.Close()
+	width, height := imaging.Dimensions(io.NewSectionReader(file, 0, fh.Size))
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return models.MediaAsset{}, apperrors.BadRequest(fh.Filename+" is empty or unreadable", nil)
+	}
+	contentType, err := imaging.DetectContentType(head[:n])
+	if err != nil {
+		return models.MediaAsset{}, apperrors.BadRequest(fh.Filename+" is not a supported image. Only JPEG, PNG, GIF or WEBP allowed", nil)
+	}
+	url, err := store.Upload(ctx, storage.NewKey(prefix, fh.Filename), io.MultiReader(bytes.NewReader(head[:n]), file), contentType)
+	if err != nil {
+		return models.MediaAsset{}, err
+	}
+	return models.MediaAsset{
+		URL:         url,
+		URLs:        []string{url},
+		Filename:    fh.Filename,
+		ContentType: contentType,
+		Size:        fh.Size,
+		Width:       width,
+		Height:      height,
+	}, nil
+}
 
+// uploadPhotos validates and stores the "photos" files of a multipart form
+// below prefix. Either every photo is stored or none is.
+func uploadPhotos(c *fiber.Ctx, store storage.Storage, prefix string, max int) ([]string, error) {
 	form, err := c.MultipartForm()
 	if err != nil {
-		log.Printf("[UPLOAD] Multipart form error: %v", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "Invalid multipart form", "error": err.Error()})
+		return nil, apperrors.BadRequest("Invalid multipart form", err)
 	}
-	files := form.File["images"]
+	files := form.File["photos"]
 	if len(files) == 0 {
-		log.Println("[UPLOAD] No images provided")
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"success": false, "message": "No images provided"})
+		return nil, apperrors.BadRequest("No photos provided", nil)
+	}
+	if len(files) > max {
+		return nil, apperrors.BadRequest(fmt.Sprintf("At most %d photos can be uploaded", max), nil)
 	}
-	log.Printf("[UPLOAD] Found %d files to upload", len(files))
 
-	ctx := context.Background()
-	fbClient, err := firebase.NewFirebaseClient(ctx, cfg.FirebaseCredentialsPath, cfg.FirebaseBucketName)
-	useLocalFallback := false
-	if err != nil {
-		log.Printf("[UPLOAD] Failed to init Firebase client: %v", err)
-		if cfg.Environment == "development" || cfg.Environment == "dev" || cfg.Environment == "local" {
-			log.Println("[UPLOAD] Development mode detected; falling back to local file storage under ./uploads")
-			useLocalFallback = true
-		} else {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to init Firebase client", "error": err.Error()})
+	photos := make([][]byte, len(files))
+	contentTypes := make([]string, len(files))
+	for i, fh := range files {
+		if fh.Size > maxImageSize {
+			return nil, fileTooLarge(fh.Filename)
 		}
-	} else {
-		log.Println("[UPLOAD] Firebase client initialized successfully")
+		file, err := fh.Open()
+		if err != nil {
+			return nil, apperrors.Internal("Failed to open file", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxImageSize))
+		file.Close()
+		if err != nil {
+			return nil, apperrors.Internal("Failed to read file", err)
+		}
+		contentType, err := imaging.DetectContentType(data)
+		if err != nil {
+			return nil, apperrors.BadRequest(fh.Filename+" is not a supported image. Only JPEG, PNG, GIF or WEBP allowed", nil)
+		}
+		photos[i], contentTypes[i] = data, contentType
 	}
 
+	ctx := context.Background()
 	urls := make([]string, 0, len(files))
-	for i, f := range files {
-		log.Printf("[UPLOAD] Processing file %d/%d: %s", i+1, len(files), f.Filename)
-		file, err := f.Open()
+	for i, fh := range files {
+		url, err := store.Upload(ctx, storage.NewKey(prefix, fh.Filename), bytes.NewReader(photos[i]), contentTypes[i])
 		if err != nil {
-			log.Printf("[UPLOAD] Failed to open file %s: %v", f.Filename, err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to open file", "error": err.Error()})
-		}
-		defer file.Close()
-
-		if useLocalFallback {
-			// Ensure uploads directory exists
-			if err := os.MkdirAll("uploads", 0o755); err != nil {
-				log.Printf("[UPLOAD] Failed to create uploads directory: %v", err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to prepare uploads directory", "error": err.Error()})
-			}
-			// Unique filename similar to Firebase pathing
-			unique := fmt.Sprintf("%d-%s", time.Now().UnixNano(), f.Filename)
-			destPath := filepath.Join("uploads", unique)
-			if err := c.SaveFile(f, destPath); err != nil {
-				log.Printf("[UPLOAD] Failed to save file locally: %v", err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to save file", "error": err.Error()})
-			}
-			base := c.BaseURL()
-			url := base + "/uploads/" + unique
-			log.Printf("[UPLOAD] Saved %s locally at %s (URL: %s)", f.Filename, destPath, url)
-			urls = append(urls, url)
-		} else {
-			log.Printf("[UPLOAD] Uploading file %s to Firebase...", f.Filename)
-			url, err := fbClient.UploadFile(ctx, file, f.Filename)
-			if err != nil {
-				log.Printf("[UPLOAD] Failed to upload file %s to Firebase: %v", f.Filename, err)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false, "message": "Failed to upload to Firebase", "error": err.Error()})
-			}
-			log.Printf("[UPLOAD] Successfully uploaded %s, URL: %s", f.Filename, url)
-			urls = append(urls, url)
+			storage.DeleteURLs(ctx, store, urls)
+			return nil, apperrors.Internal("Failed to store photo", err)
 		}
+		urls = append(urls, url)
 	}
-
-	log.Printf("[UPLOAD] Upload process completed successfully. URLs: %v", urls)
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true, "message": "Upload successful", "data": fiber.Map{"urls": urls}})
+	return urls, nil
 }
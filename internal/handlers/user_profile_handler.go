@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,8 +8,10 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/messaging"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
@@ -36,10 +37,7 @@ func (h *UserProfileHandler) GetProfile(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get base user data
@@ -48,16 +46,9 @@ func (h *UserProfileHandler) GetProfile(c *fiber.Ctx) error {
 	err := userCollection.FindOne(ctx, bson.M{"_id": user.UserID}).Decode(&userData)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "User not found",
-			})
+			return apperrors.NotFound("User not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve user data",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve user data", err)
 	}
 
 	// Get extended profile data
@@ -65,11 +56,7 @@ func (h *UserProfileHandler) GetProfile(c *fiber.Ctx) error {
 	profileCollection := h.DB.Collections().UserProfiles
 	err = profileCollection.FindOne(ctx, bson.M{"user_id": user.UserID}).Decode(&profile)
 	if err != nil && err != mongo.ErrNoDocuments {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve profile data",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve profile data", err)
 	}
 
 	// Combine user and profile data
@@ -104,20 +91,13 @@ func (h *UserProfileHandler) UpdateProfile(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse request body
 	var req models.ProfileUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
 	// Check if profile exists
@@ -141,18 +121,10 @@ func (h *UserProfileHandler) UpdateProfile(c *fiber.Ctx) error {
 
 		_, err = profileCollection.InsertOne(ctx, newProfile)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to create profile",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to create profile", err)
 		}
 	} else if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check existing profile",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check existing profile", err)
 	} else {
 		// Update existing profile
 		update := bson.M{"updated_at": now}
@@ -179,11 +151,7 @@ func (h *UserProfileHandler) UpdateProfile(c *fiber.Ctx) error {
 			bson.M{"$set": update},
 		)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update profile",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to update profile", err)
 		}
 	}
 
@@ -193,6 +161,37 @@ func (h *UserProfileHandler) UpdateProfile(c *fiber.Ctx) error {
 	})
 }
 
+// GetPreferences returns the user's preferences, with the order update
+// channels the store offers in meta.orderUpdateChannels
+// GET /preferences
+func (h *UserProfileHandler) GetPreferences(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+
+	prefs := models.UserPreferences{UserID: user.UserID}
+	err := h.DB.Collections().UserPreferences.FindOne(c.Context(), bson.M{"user_id": user.UserID}).Decode(&prefs)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return apperrors.Internal("Failed to retrieve preferences", err)
+	}
+
+	channels := []string{}
+	if h.Config.WhatsAppOrderUpdates {
+		channels = append(channels, messaging.ChannelWhatsApp)
+	}
+	if h.Config.SMSOrderUpdates {
+		channels = append(channels, messaging.ChannelSMS)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Preferences retrieved successfully",
+		"data":    prefs,
+		"meta":    fiber.Map{"orderUpdateChannels": channels},
+	})
+}
+
 // UpdatePreferences updates the user's preferences
 func (h *UserProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
 	ctx := c.Context()
@@ -200,20 +199,13 @@ func (h *UserProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse request body
 	var req models.PreferencesUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
 	// Check if preferences exist
@@ -236,21 +228,16 @@ func (h *UserProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
 			CreatedAt:          now,
 			UpdatedAt:          now,
 		}
+		if req.OrderUpdates != nil {
+			newPrefs.OrderUpdates = *req.OrderUpdates
+		}
 
 		_, err = prefsCollection.InsertOne(ctx, newPrefs)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to create preferences",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to create preferences", err)
 		}
 	} else if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check existing preferences",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check existing preferences", err)
 	} else {
 		// Update existing preferences
 		update := bson.M{"updated_at": now}
@@ -270,6 +257,9 @@ func (h *UserProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
 		if req.PriceRange != nil {
 			update["price_range"] = req.PriceRange
 		}
+		if req.OrderUpdates != nil {
+			update["order_updates"] = req.OrderUpdates
+		}
 
 		_, err = prefsCollection.UpdateOne(
 			ctx,
@@ -277,17 +267,12 @@ func (h *UserProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
 			bson.M{"$set": update},
 		)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"success": false,
-				"message": "Failed to update preferences",
-				"error":   err.Error(),
-			})
+			return apperrors.Internal("Failed to update preferences", err)
 		}
 	}
 
 	// Invalidate recommendations cache
-	cacheKey := fmt.Sprintf("recommendations:%s", user.UserID.Hex())
-	h.DB.CacheDel(ctx, cacheKey)
+	invalidateRecommendations(ctx, h.DB, user.UserID)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/validation"
+)
+
+// bindAndValidate parses the request body into dst and enforces its validate tags
+func bindAndValidate(c *fiber.Ctx, dst interface{}) error {
+	if err := c.BodyParser(dst); err != nil {
+		return apperrors.BadRequest("Invalid request body", err)
+	}
+	return validateRequest(dst)
+}
+
+// validateRequest enforces the validate tags of an already populated value,
+// producing a 422 with one entry per failed field.
+func validateRequest(v interface{}) error {
+	if errs := validation.Struct(v); len(errs) > 0 {
+		return apperrors.Validation("Validation failed", errs)
+	}
+	return nil
+}
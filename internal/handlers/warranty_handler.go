@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// maxWarrantyResults caps an admin lookup
+const maxWarrantyResults = 100
+
+// WarrantyHandler serves the warranties issued on delivery
+type WarrantyHandler struct {
+	DB     *database.DBClient
+	Config *config.Config
+}
+
+// NewWarrantyHandler creates a new instance of WarrantyHandler
+func NewWarrantyHandler(db *database.DBClient, cfg *config.Config) *WarrantyHandler {
+	return &WarrantyHandler{DB: db, Config: cfg}
+}
+
+// findWarranties returns the warranties matching filter, newest first, with
+// their status as of now
+func (h *WarrantyHandler) findWarranties(c *fiber.Ctx, filter bson.M, limit int64) ([]models.Warranty, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := h.DB.Collections().Warranties.Find(c.Context(), filter, opts)
+	if err != nil {
+		return nil, apperrors.Internal("Failed to retrieve warranties", err)
+	}
+	warranties := []models.Warranty{}
+	if err := cursor.All(c.Context(), &warranties); err != nil {
+		return nil, apperrors.Internal("Failed to decode warranties", err)
+	}
+	now := time.Now()
+	for i := range warranties {
+		warranties[i].Status = warranties[i].CurrentStatus(now)
+	}
+	return warranties, nil
+}
+
+// GetAccountWarranties lists the current user's warranties
+// GET /account/warranties
+func (h *WarrantyHandler) GetAccountWarranties(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	warranties, err := h.findWarranties(c, bson.M{"user_id": user.UserID}, 0)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Warranties retrieved successfully",
+		"data":    warranties,
+	})
+}
+
+// RegisterWarrantySerial records the serial number of the watch a warranty
+// covers. Customers register it once; a wrong one is corrected by staff.
+// PUT /account/warranties/:id/serial
+func (h *WarrantyHandler) RegisterWarrantySerial(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	user, ok := c.Locals("user").(*middleware.TokenMetadata)
+	if !ok {
+		return apperrors.Unauthorized("Unauthorized - User data not found")
+	}
+	id, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid warranty ID", err)
+	}
+	var req models.WarrantySerialRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	serial := models.NormalizeSerial(req.SerialNumber)
+
+	var warranty models.Warranty
+	err = h.DB.Collections().Warranties.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "user_id": user.UserID, "serial_number": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"serial_number": serial, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&warranty)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return apperrors.Conflict("That serial number is registered to another warranty")
+	case errors.Is(err, mongo.ErrNoDocuments):
+		n, err := h.DB.Collections().Warranties.CountDocuments(ctx, bson.M{"_id": id, "user_id": user.UserID})
+		if err != nil {
+			return apperrors.Internal("Failed to retrieve warranty", err)
+		}
+		if n == 0 {
+			return apperrors.NotFound("Warranty not found")
+		}
+		return apperrors.Conflict("The warranty already has a serial number; contact support to change it")
+	case err != nil:
+		return apperrors.Internal("Failed to register serial number", err)
+	}
+	warranty.Status = warranty.CurrentStatus(time.Now())
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Serial number registered",
+		"data":    warranty,
+	})
+}
+
+// LookupWarranties finds warranties for a service center by serial number,
+// order, customer ID or customer email; one of them is required
+// GET /admin/warranties?serial=&orderId=&userId=&email=
+func (h *WarrantyHandler) LookupWarranties(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if serial := strings.TrimSpace(c.Query("serial")); serial != "" {
+		filter["serial_number"] = models.NormalizeSerial(serial)
+	}
+	if orderID := c.Query("orderId"); orderID != "" {
+		id, err := parseObjectID(orderID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid order ID", err)
+		}
+		filter["order_id"] = id
+	}
+	if userID := c.Query("userId"); userID != "" {
+		id, err := parseObjectID(userID)
+		if err != nil {
+			return apperrors.BadRequest("Invalid user ID", err)
+		}
+		filter["user_id"] = id
+	}
+	if email := c.Query("email"); email != "" {
+		var user models.User
+		err := h.DB.Collections().Users.FindOne(c.Context(), bson.M{"email": models.NormalizeEmail(email)},
+			options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&user)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.Internal("Failed to look up customer", err)
+		}
+		// An unknown email matches nothing
+		filter["user_id"] = user.ID
+	}
+	if len(filter) == 0 {
+		return apperrors.BadRequest("Give a serial, orderId, userId or email to look up", nil)
+	}
+
+	warranties, err := h.findWarranties(c, filter, maxWarrantyResults)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Warranties retrieved successfully",
+		"data":    warranties,
+	})
+}
+
+// ExtendWarranty adds months to a warranty, e.g. after a repair under it.
+// Expired warranties can be extended too; void ones can't.
+// POST /admin/warranties/:id/extend
+func (h *WarrantyHandler) ExtendWarranty(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid warranty ID", err)
+	}
+	var req models.WarrantyExtendRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	warranties := h.DB.Collections().Warranties
+	var before models.Warranty
+	if err := warranties.FindOne(ctx, bson.M{"_id": objID}).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Warranty not found")
+		}
+		return apperrors.Internal("Failed to retrieve warranty", err)
+	}
+	if before.VoidedAt != nil {
+		return apperrors.Conflict("The warranty is void; its order was returned")
+	}
+
+	now := time.Now()
+	extension := models.WarrantyExtension{Months: req.Months, Reason: req.Reason, CreatedAt: now}
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		extension.ActorID = actor.UserID
+	}
+	// Guarded by the expiry it was computed from, so two extensions at once
+	// can't lose one
+	var warranty models.Warranty
+	err = warranties.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "expires_at": before.ExpiresAt, "voided_at": bson.M{"$exists": false}},
+		bson.M{
+			"$set":  bson.M{"expires_at": before.ExpiresAt.AddDate(0, req.Months, 0), "updated_at": now},
+			"$inc":  bson.M{"months": req.Months},
+			"$push": bson.M{"extensions": extension},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&warranty)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return apperrors.Conflict("The warranty was changed by someone else, reload and try again")
+	}
+	if err != nil {
+		return apperrors.Internal("Failed to extend warranty", err)
+	}
+	warranty.Status = warranty.CurrentStatus(now)
+
+	recordAudit(c, h.DB.MongoDB, "warranty.extend", "warranty", id,
+		bson.M{"months": before.Months, "expires_at": before.ExpiresAt},
+		bson.M{"months": warranty.Months, "expires_at": warranty.ExpiresAt, "reason": req.Reason})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Warranty extended",
+		"data":    warranty,
+	})
+}
+
+// SetWarrantySerial records or corrects a warranty's serial number
+// PUT /admin/warranties/:id/serial
+func (h *WarrantyHandler) SetWarrantySerial(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	id := c.Params("id")
+	objID, err := parseObjectID(id)
+	if err != nil {
+		return apperrors.BadRequest("Invalid warranty ID", err)
+	}
+	var req models.WarrantySerialRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	serial := models.NormalizeSerial(req.SerialNumber)
+
+	var before models.Warranty
+	err = h.DB.Collections().Warranties.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{"serial_number": serial, "updated_at": time.Now()}},
+	).Decode(&before)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return apperrors.Conflict("That serial number is registered to another warranty")
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return apperrors.NotFound("Warranty not found")
+	case err != nil:
+		return apperrors.Internal("Failed to set serial number", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "warranty.serial", "warranty", id,
+		bson.M{"serial_number": before.SerialNumber}, bson.M{"serial_number": serial})
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Serial number updated",
+	})
+}
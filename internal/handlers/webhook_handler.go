@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jobs"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// WebhookHandler manages the endpoints that receive lifecycle events
+type WebhookHandler struct {
+	DB         *database.DBClient
+	Dispatcher *jobs.WebhookDispatcher
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler
+func NewWebhookHandler(db *database.DBClient, queue *jobs.Queue) *WebhookHandler {
+	return &WebhookHandler{DB: db, Dispatcher: &jobs.WebhookDispatcher{DB: db, Queue: queue}}
+}
+
+// newWebhookSecret generates a signing secret for an endpoint
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// validateWebhookURL only allows http(s) URLs with a host
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return apperrors.BadRequest("Webhook URL must be an http or https URL", nil)
+	}
+	return nil
+}
+
+// GetWebhooks lists webhook endpoints with the outcome of their last delivery
+// GET /admin/webhooks
+func (h *WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	cursor, err := h.DB.Collections().WebhookEndpoints.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return apperrors.Internal("Failed to fetch webhooks", err)
+	}
+	defer cursor.Close(ctx)
+
+	endpoints := []models.WebhookEndpoint{}
+	if err := cursor.All(ctx, &endpoints); err != nil {
+		return apperrors.Internal("Failed to decode webhooks", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhooks retrieved successfully",
+		"data":    endpoints,
+		"meta":    fiber.Map{"eventTypes": events.Types},
+	})
+}
+
+// GetWebhook returns a single webhook endpoint
+// GET /admin/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid webhook ID", err)
+	}
+	endpoint, err := h.find(c.Context(), objectID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhook retrieved successfully",
+		"data":    endpoint,
+	})
+}
+
+// CreateWebhook registers an endpoint. The signing secret is returned only
+// in this response.
+// POST /admin/webhooks
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	var req models.WebhookEndpointRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return apperrors.Internal("Failed to generate webhook secret", err)
+	}
+
+	now := time.Now()
+	endpoint := models.WebhookEndpoint{
+		ID:          primitive.NewObjectID(),
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Secret:      secret,
+		Active:      req.Active == nil || *req.Active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if actor, ok := c.Locals("user").(*middleware.TokenMetadata); ok {
+		endpoint.CreatedBy = actor.UserID
+	}
+	if _, err := h.DB.Collections().WebhookEndpoints.InsertOne(ctx, endpoint); err != nil {
+		return apperrors.Internal("Failed to create webhook", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "webhook.create", "webhook", endpoint.ID.Hex(), nil, endpoint)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhook created successfully",
+		"data":    fiber.Map{"webhook": endpoint, "secret": secret},
+	})
+}
+
+// UpdateWebhook replaces an endpoint's URL, subscriptions and active flag.
+// The secret is kept.
+// PUT /admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid webhook ID", err)
+	}
+	var req models.WebhookEndpointRequest
+	if err := bindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return err
+	}
+
+	set := bson.M{
+		"url":         req.URL,
+		"description": req.Description,
+		"events":      req.Events,
+		"updated_at":  time.Now(),
+	}
+	if req.Active != nil {
+		set["active"] = *req.Active
+	}
+	var before models.WebhookEndpoint
+	err = h.DB.Collections().WebhookEndpoints.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": set}).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Webhook not found")
+		}
+		return apperrors.Internal("Failed to update webhook", err)
+	}
+	updated, err := h.find(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(c, h.DB.MongoDB, "webhook.update", "webhook", objectID.Hex(), before, updated)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhook updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteWebhook removes an endpoint; queued deliveries to it are dropped
+// DELETE /admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid webhook ID", err)
+	}
+	var deleted models.WebhookEndpoint
+	if err := h.DB.Collections().WebhookEndpoints.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&deleted); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.NotFound("Webhook not found")
+		}
+		return apperrors.Internal("Failed to delete webhook", err)
+	}
+
+	recordAudit(c, h.DB.MongoDB, "webhook.delete", "webhook", objectID.Hex(), deleted, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhook deleted successfully",
+	})
+}
+
+// RotateWebhookSecret replaces an endpoint's signing secret and returns it
+// POST /admin/webhooks/:id/rotate-secret
+func (h *WebhookHandler) RotateWebhookSecret(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid webhook ID", err)
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return apperrors.Internal("Failed to generate webhook secret", err)
+	}
+	res, err := h.DB.Collections().WebhookEndpoints.UpdateOne(ctx, bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{"secret": secret, "updated_at": time.Now()}})
+	if err != nil {
+		return apperrors.Internal("Failed to rotate webhook secret", err)
+	}
+	if res.MatchedCount == 0 {
+		return apperrors.NotFound("Webhook not found")
+	}
+
+	recordAudit(c, h.DB.MongoDB, "webhook.rotate_secret", "webhook", objectID.Hex(), nil, nil)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Webhook secret rotated successfully",
+		"data":    fiber.Map{"secret": secret},
+	})
+}
+
+// TestWebhook queues a ping event to the endpoint, even when it is inactive.
+// The outcome shows up in the endpoint's last delivery fields.
+// POST /admin/webhooks/:id/test
+func (h *WebhookHandler) TestWebhook(c *fiber.Ctx) error {
+	ctx := c.Context()
+
+	objectID, err := parseObjectID(c.Params("id"))
+	if err != nil {
+		return apperrors.BadRequest("Invalid webhook ID", err)
+	}
+	if _, err := h.find(ctx, objectID); err != nil {
+		return err
+	}
+
+	ping := events.New(events.Ping, fiber.Map{"webhookId": objectID.Hex()})
+	if err := h.Dispatcher.DispatchTo(ctx, objectID, ping); err != nil {
+		return apperrors.Internal("Failed to queue test delivery", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Test delivery queued",
+		"data":    fiber.Map{"eventId": ping.ID},
+	})
+}
+
+func (h *WebhookHandler) find(ctx context.Context, id primitive.ObjectID) (models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := h.DB.Collections().WebhookEndpoints.FindOne(ctx, bson.M{"_id": id}).Decode(&endpoint); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return endpoint, apperrors.NotFound("Webhook not found")
+		}
+		return endpoint, apperrors.Internal("Failed to retrieve webhook", err)
+	}
+	return endpoint, nil
+}
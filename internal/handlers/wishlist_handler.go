@@ -9,8 +9,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/middleware"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
@@ -19,6 +21,7 @@ import (
 type WishlistHandler struct {
 	DB     *database.DBClient
 	Config *config.Config
+	Events *events.Bus // Optional; receives a wishlist.added event per product added
 }
 
 // NewWishlistHandler creates a new instance of WishlistHandler
@@ -36,10 +39,7 @@ func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get wishlist items
@@ -50,22 +50,14 @@ func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
 		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve wishlist",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve wishlist", err)
 	}
 	defer cursor.Close(ctx)
 
 	// Decode wishlist items
 	var wishlistItems []models.Wishlist
 	if err := cursor.All(ctx, &wishlistItems); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode wishlist items",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode wishlist items", err)
 	}
 
 	// If no items found, return empty array
@@ -90,11 +82,7 @@ func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
 		bson.M{"_id": bson.M{"$in": productIDs}},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to retrieve product details",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to retrieve product details", err)
 	}
 	defer productCursor.Close(ctx)
 
@@ -102,11 +90,7 @@ func (h *WishlistHandler) GetWishlist(c *fiber.Ctx) error {
 	products := make(map[primitive.ObjectID]models.Product)
 	var productList []models.Product
 	if err := productCursor.All(ctx, &productList); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to decode products",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to decode products", err)
 	}
 
 	for _, product := range productList {
@@ -147,10 +131,7 @@ func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Parse request body
@@ -159,20 +140,13 @@ func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
 	}
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return apperrors.BadRequest("Invalid request body", err)
 	}
 
 	// Convert string ID to ObjectID
 	productID, err := primitive.ObjectIDFromHex(req.ProductID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid product ID",
-		})
+		return apperrors.BadRequest("Invalid product ID", nil)
 	}
 
 	// Check if product exists
@@ -181,16 +155,9 @@ func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
 	err = productCollection.FindOne(ctx, bson.M{"_id": productID}).Decode(&product)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": "Product not found",
-			})
+			return apperrors.NotFound("Product not found")
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check product",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check product", err)
 	}
 
 	// Check if product is already in wishlist
@@ -203,18 +170,11 @@ func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
 		},
 	)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to check wishlist",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to check wishlist", err)
 	}
 
 	if count > 0 {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"success": false,
-			"message": "Product already in wishlist",
-		})
+		return apperrors.Conflict("Product already in wishlist")
 	}
 
 	// Add product to wishlist
@@ -228,12 +188,13 @@ func (h *WishlistHandler) AddToWishlist(c *fiber.Ctx) error {
 
 	_, err = wishlistCollection.InsertOne(ctx, wishlistItem)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to add product to wishlist",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to add product to wishlist", err)
 	}
+	h.Events.Publish(ctx, events.WishlistAdded, events.WishlistAdd{
+		UserID:      user.UserID.Hex(),
+		ProductID:   productID.Hex(),
+		ProductName: product.Name,
+	})
 
 	// Return product details with wishlist info
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -259,19 +220,13 @@ func (h *WishlistHandler) RemoveFromWishlist(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Get wishlist item ID from parameters
 	itemID, err := primitive.ObjectIDFromHex(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid wishlist item ID",
-		})
+		return apperrors.BadRequest("Invalid wishlist item ID", nil)
 	}
 
 	// Delete wishlist item
@@ -285,18 +240,11 @@ func (h *WishlistHandler) RemoveFromWishlist(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to remove product from wishlist",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to remove product from wishlist", err)
 	}
 
 	if result.DeletedCount == 0 {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"message": "Wishlist item not found or does not belong to you",
-		})
+		return apperrors.NotFound("Wishlist item not found or does not belong to you")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -312,10 +260,7 @@ func (h *WishlistHandler) ClearWishlist(c *fiber.Ctx) error {
 	// Get user info from token
 	user, ok := c.Locals("user").(*middleware.TokenMetadata)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": "Unauthorized - User data not found",
-		})
+		return apperrors.Unauthorized("Unauthorized - User data not found")
 	}
 
 	// Delete all wishlist items
@@ -326,11 +271,7 @@ func (h *WishlistHandler) ClearWishlist(c *fiber.Ctx) error {
 	)
 
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to clear wishlist",
-			"error":   err.Error(),
-		})
+		return apperrors.Internal("Failed to clear wishlist", err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
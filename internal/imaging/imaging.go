@@ -0,0 +1,171 @@
+// Package imaging validates uploaded images and produces resized renditions
+// in the original format plus WebP, so the storefront can serve small files
+// to mobile clients.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // register decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register decoder
+)
+
+// Rendition is a named target size. Images are scaled down to fit within
+// MaxWidth while keeping their aspect ratio; they are never scaled up.
+type Rendition struct {
+	Name     string
+	MaxWidth int
+}
+
+// DefaultRenditions are generated for every product image
+var DefaultRenditions = []Rendition{
+	{Name: "thumbnail", MaxWidth: 200},
+	{Name: "medium", MaxWidth: 600},
+	{Name: "large", MaxWidth: 1200},
+}
+
+// AllowedContentTypes are the image types accepted for upload, sniffed from
+// the file contents rather than trusted from the client
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ErrUnsupportedType is returned for files that aren't an allowed image type
+var ErrUnsupportedType = errors.New("unsupported image type")
+
+// MaxPixels caps the width × height an upload may declare. Decoding holds
+// every pixel in memory, so a small file claiming huge dimensions is
+// refused before it is decoded.
+const MaxPixels = 40_000_000
+
+// ErrTooManyPixels is returned for images larger than MaxPixels
+var ErrTooManyPixels = fmt.Errorf("image exceeds %d pixels", MaxPixels)
+
+// Variant is one encoded rendition
+type Variant struct {
+	Rendition   string
+	Format      string // "jpeg", "png" or "webp"
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Extension returns the file extension for the variant's format
+func (v Variant) Extension() string {
+	if v.Format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + v.Format
+}
+
+// DetectContentType sniffs the content type of an image from its first bytes
+// and reports ErrUnsupportedType for anything not in AllowedContentTypes
+func DetectContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !AllowedContentTypes[contentType] {
+		return contentType, ErrUnsupportedType
+	}
+	return contentType, nil
+}
+
+// Dimensions reads an image's width and height from its header, without
+// decoding the pixels. Both are 0 when r doesn't start with a readable image.
+func Dimensions(r io.Reader) (width, height int) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
+
+// Process decodes an image and encodes every rendition twice: once in a
+// web-friendly form of the source format (PNG stays PNG to keep
+// transparency, everything else becomes JPEG) and once as WebP.
+func Process(data []byte, renditions []Rendition) ([]Variant, error) {
+	if _, err := DetectContentType(data); err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image header: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	variants := make([]Variant, 0, len(renditions)*2)
+	for _, r := range renditions {
+		img := resize(src, r.MaxWidth)
+		b := img.Bounds()
+
+		var buf bytes.Buffer
+		primary := Variant{Rendition: r.Name, Width: b.Dx(), Height: b.Dy()}
+		if format == "png" {
+			if err := png.Encode(&buf, img); err != nil {
+				return nil, fmt.Errorf("encode %s png: %w", r.Name, err)
+			}
+			primary.Format, primary.ContentType = "png", "image/png"
+		} else {
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 82}); err != nil {
+				return nil, fmt.Errorf("encode %s jpeg: %w", r.Name, err)
+			}
+			primary.Format, primary.ContentType = "jpeg", "image/jpeg"
+		}
+		primary.Data = buf.Bytes()
+
+		var webpBuf bytes.Buffer
+		if err := nativewebp.Encode(&webpBuf, img, nil); err != nil {
+			return nil, fmt.Errorf("encode %s webp: %w", r.Name, err)
+		}
+
+		variants = append(variants, primary, Variant{
+			Rendition:   r.Name,
+			Format:      "webp",
+			ContentType: "image/webp",
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+			Data:        webpBuf.Bytes(),
+		})
+	}
+	return variants, nil
+}
+
+// resize scales src down to maxWidth, returning an RGBA copy either way so
+// encoders always get a plain image
+func resize(src image.Image, maxWidth int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxWidth && maxWidth > 0 {
+		h = h * maxWidth / w
+		if h < 1 {
+			h = 1
+		}
+		w = maxWidth
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// ActivityRecorder writes the events that concern an account to its
+// activity timeline
+type ActivityRecorder struct {
+	DB *database.DBClient
+}
+
+// Record is an events.Handler: it adds an entry to the timeline of the
+// account an order, review, wishlist or address event belongs to
+func (r *ActivityRecorder) Record(ctx context.Context, e events.Event) error {
+	activity, userID := activityFor(e)
+	if activity == nil {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(e.ID)
+	if err != nil {
+		return fmt.Errorf("event ID: %w", err)
+	}
+	if activity.UserID.IsZero() {
+		if activity.UserID, err = primitive.ObjectIDFromHex(userID); err != nil {
+			return fmt.Errorf("user ID: %w", err)
+		}
+	}
+	activity.ID = id
+	activity.CreatedAt = e.OccurredAt
+	if activity.CreatedAt.IsZero() {
+		activity.CreatedAt = time.Now()
+	}
+
+	_, err = r.DB.Collections().Activities.InsertOne(ctx, activity)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // Already recorded
+	}
+	return err
+}
+
+// activityFor maps an event to a timeline entry, with the hex ID of its
+// account when the data only carries it as a string. It returns nil for
+// events that don't belong on a timeline.
+func activityFor(e events.Event) (*models.Activity, string) {
+	switch data := e.Data.(type) {
+	case models.Order:
+		if e.Type != events.OrderCreated {
+			return nil, ""
+		}
+		items := 0
+		for _, item := range data.Items {
+			items += item.Quantity
+		}
+		return &models.Activity{
+			UserID:    data.UserID,
+			Type:      models.ActivityOrderPlaced,
+			Summary:   fmt.Sprintf("Placed an order of %d item(s) for %.2f", items, data.Total),
+			SubjectID: data.ID.Hex(),
+		}, ""
+	case events.ReviewWritten:
+		return &models.Activity{
+			Type:      models.ActivityReviewWritten,
+			Summary:   fmt.Sprintf("Rated %s %.0f out of 5", data.ProductName, data.Rating),
+			SubjectID: data.ProductID,
+		}, data.UserID
+	case events.WishlistAdd:
+		return &models.Activity{
+			Type:      models.ActivityWishlistAdded,
+			Summary:   fmt.Sprintf("Added %s to the wishlist", data.ProductName),
+			SubjectID: data.ProductID,
+		}, data.UserID
+	case events.AddressChange:
+		activity := &models.Activity{SubjectID: data.AddressID}
+		switch data.Change {
+		case events.AddressCreated:
+			activity.Type, activity.Summary = models.ActivityAddressAdded, "Added an address in "+data.City
+		case events.AddressUpdated:
+			activity.Type, activity.Summary = models.ActivityAddressUpdated, "Updated an address in "+data.City
+		case events.AddressDeleted:
+			activity.Type, activity.Summary = models.ActivityAddressRemoved, "Removed an address in "+data.City
+		default:
+			return nil, ""
+		}
+		return activity, data.UserID
+	}
+	return nil, ""
+}
@@ -0,0 +1,351 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrJobNotFound is returned when a dead-lettered job does not exist
+var ErrJobNotFound = errors.New("job not found")
+
+// Broker stores jobs between producers and workers
+type Broker interface {
+	// Push makes a job ready for immediate processing
+	Push(ctx context.Context, job *Job) error
+	// Pop waits up to timeout for a ready job; it returns nil, nil on timeout
+	Pop(ctx context.Context, timeout time.Duration) (*Job, error)
+	// Schedule holds a job until at, when PromoteDue makes it ready again
+	Schedule(ctx context.Context, job *Job, at time.Time) error
+	// PromoteDue moves scheduled jobs whose time has come onto the ready queue
+	PromoteDue(ctx context.Context, now time.Time) error
+	// Bury moves a job to the dead-letter queue
+	Bury(ctx context.Context, job *Job) error
+	// Dead lists dead-lettered jobs, most recently failed first
+	Dead(ctx context.Context) ([]*Job, error)
+	// TakeDead removes and returns a dead-lettered job
+	TakeDead(ctx context.Context, id string) (*Job, error)
+	Stats(ctx context.Context) (Stats, error)
+	// Name identifies the backend for logging
+	Name() string
+}
+
+// NewBroker selects the broker backend: Redis when a client is available,
+// otherwise an in-process queue whose jobs are lost on restart.
+func NewBroker(redisClient *redis.Client) Broker {
+	if redisClient != nil {
+		return NewRedisBroker(redisClient, "jobs")
+	}
+	return NewMemoryBroker()
+}
+
+// RedisBroker keeps ready jobs in a list, retries in a sorted set scored by
+// due time and dead letters in a hash keyed by job ID, so every API instance
+// shares one queue.
+type RedisBroker struct {
+	client    *redis.Client
+	ready     string
+	scheduled string
+	dead      string
+}
+
+// NewRedisBroker creates a broker whose keys share prefix
+func NewRedisBroker(client *redis.Client, prefix string) *RedisBroker {
+	return &RedisBroker{
+		client:    client,
+		ready:     prefix + ":ready",
+		scheduled: prefix + ":scheduled",
+		dead:      prefix + ":dead",
+	}
+}
+
+// Push implements Broker
+func (r *RedisBroker) Push(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.client.LPush(ctx, r.ready, data).Err()
+}
+
+// Pop implements Broker
+func (r *RedisBroker) Pop(ctx context.Context, timeout time.Duration) (*Job, error) {
+	res, err := r.client.BRPop(ctx, timeout, r.ready).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// res is [key, value]
+	var job Job
+	if err := json.Unmarshal([]byte(res[1]), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Schedule implements Broker
+func (r *RedisBroker) Schedule(ctx context.Context, job *Job, at time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.client.ZAdd(ctx, r.scheduled, &redis.Z{Score: float64(at.Unix()), Member: data}).Err()
+}
+
+// PromoteDue implements Broker. ZREM decides ownership, so when several
+// instances race for the same job only one of them pushes it.
+func (r *RedisBroker) PromoteDue(ctx context.Context, now time.Time) error {
+	due, err := r.client.ZRangeByScore(ctx, r.scheduled, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: 100,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, member := range due {
+		removed, err := r.client.ZRem(ctx, r.scheduled, member).Result()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := r.client.LPush(ctx, r.ready, member).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bury implements Broker
+func (r *RedisBroker) Bury(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, r.dead, job.ID, data).Err()
+}
+
+// Dead implements Broker
+func (r *RedisBroker) Dead(ctx context.Context) ([]*Job, error) {
+	all, err := r.client.HGetAll(ctx, r.dead).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*Job, 0, len(all))
+	for _, data := range all {
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	sortByFailure(jobs)
+	return jobs, nil
+}
+
+// TakeDead implements Broker
+func (r *RedisBroker) TakeDead(ctx context.Context, id string) (*Job, error) {
+	data, err := r.client.HGet(ctx, r.dead, id).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	removed, err := r.client.HDel(ctx, r.dead, id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		// Another admin took it first
+		return nil, ErrJobNotFound
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Stats implements Broker
+func (r *RedisBroker) Stats(ctx context.Context) (Stats, error) {
+	pipe := r.client.Pipeline()
+	ready := pipe.LLen(ctx, r.ready)
+	scheduled := pipe.ZCard(ctx, r.scheduled)
+	dead := pipe.HLen(ctx, r.dead)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Stats{}, err
+	}
+	return Stats{Ready: ready.Val(), Scheduled: scheduled.Val(), Dead: dead.Val()}, nil
+}
+
+// Name implements Broker
+func (r *RedisBroker) Name() string { return "redis" }
+
+// MemoryBroker is an in-process Broker used when Redis is unavailable. Jobs
+// are not shared between instances and do not survive a restart.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	ready     []*Job
+	scheduled []scheduledJob
+	dead      map[string]*Job
+	notify    chan struct{}
+}
+
+type scheduledJob struct {
+	job *Job
+	at  time.Time
+}
+
+// NewMemoryBroker creates an empty in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		dead:   make(map[string]*Job),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Push implements Broker
+func (m *MemoryBroker) Push(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	m.ready = append(m.ready, job)
+	m.mu.Unlock()
+	m.wake()
+	return nil
+}
+
+// wake signals one waiting Pop without blocking
+func (m *MemoryBroker) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pop implements Broker
+func (m *MemoryBroker) Pop(ctx context.Context, timeout time.Duration) (*Job, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		m.mu.Lock()
+		if len(m.ready) > 0 {
+			job := m.ready[0]
+			m.ready = m.ready[1:]
+			more := len(m.ready) > 0
+			m.mu.Unlock()
+			if more {
+				m.wake()
+			}
+			return job, nil
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, nil
+		case <-m.notify:
+		}
+	}
+}
+
+// Schedule implements Broker
+func (m *MemoryBroker) Schedule(_ context.Context, job *Job, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scheduled = append(m.scheduled, scheduledJob{job: job, at: at})
+	return nil
+}
+
+// PromoteDue implements Broker
+func (m *MemoryBroker) PromoteDue(_ context.Context, now time.Time) error {
+	m.mu.Lock()
+	pending := m.scheduled[:0]
+	promoted := false
+	for _, s := range m.scheduled {
+		if s.at.After(now) {
+			pending = append(pending, s)
+			continue
+		}
+		m.ready = append(m.ready, s.job)
+		promoted = true
+	}
+	m.scheduled = pending
+	m.mu.Unlock()
+	if promoted {
+		m.wake()
+	}
+	return nil
+}
+
+// Bury implements Broker
+func (m *MemoryBroker) Bury(_ context.Context, job *Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dead[job.ID] = job
+	return nil
+}
+
+// Dead implements Broker
+func (m *MemoryBroker) Dead(_ context.Context) ([]*Job, error) {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.dead))
+	for _, job := range m.dead {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	m.mu.Unlock()
+	sortByFailure(jobs)
+	return jobs, nil
+}
+
+// TakeDead implements Broker
+func (m *MemoryBroker) TakeDead(_ context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.dead[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	delete(m.dead, id)
+	return job, nil
+}
+
+// Stats implements Broker
+func (m *MemoryBroker) Stats(_ context.Context) (Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Stats{
+		Ready:     int64(len(m.ready)),
+		Scheduled: int64(len(m.scheduled)),
+		Dead:      int64(len(m.dead)),
+	}, nil
+}
+
+// Name implements Broker
+func (m *MemoryBroker) Name() string { return "memory" }
+
+// sortByFailure orders jobs by failure time, newest first
+func sortByFailure(jobs []*Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		var a, b time.Time
+		if jobs[i].FailedAt != nil {
+			a = *jobs[i].FailedAt
+		}
+		if jobs[j].FailedAt != nil {
+			b = *jobs[j].FailedAt
+		}
+		return a.After(b)
+	})
+}
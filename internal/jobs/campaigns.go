@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/pricehistory"
+)
+
+// productDiscountFields are the per-product fields a campaign owns while active
+var productDiscountFields = bson.M{
+	"discount_percentage": "",
+	"discount_amount":     "",
+	"discount_start_date": "",
+	"discount_end_date":   "",
+	"campaign_id":         "",
+}
+
+// CampaignScheduler copies campaign discounts onto targeted products when a
+// campaign starts and removes them when it ends
+type CampaignScheduler struct {
+	DB *database.DBClient
+}
+
+// Sync activates due campaigns, extends active ones to products added since
+// and releases expired ones. It is idempotent and safe to call after every
+// admin change to make it take effect without waiting for the next tick.
+func (s *CampaignScheduler) Sync(ctx context.Context) error {
+	now := time.Now()
+	coll := s.DB.Collections().Campaigns
+	changed := false
+
+	cursor, err := coll.Find(ctx, bson.M{
+		"status": bson.M{"$in": bson.A{models.CampaignStatusScheduled, models.CampaignStatusActive}},
+	})
+	if err != nil {
+		return fmt.Errorf("find campaigns: %w", err)
+	}
+	var campaigns []models.Campaign
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return fmt.Errorf("decode campaigns: %w", err)
+	}
+
+	// Release expired campaigns first so products they held are free for others
+	for _, campaign := range campaigns {
+		if now.Before(campaign.EndDate) {
+			continue
+		}
+		released, err := ReleaseCampaign(ctx, s.DB, campaign.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$set": bson.M{
+			"status":        models.CampaignStatusEnded,
+			"applied_count": 0,
+			"ended_at":      now,
+			"updated_at":    now,
+		}}); err != nil {
+			return fmt.Errorf("end campaign %s: %w", campaign.ID.Hex(), err)
+		}
+		changed = changed || released > 0
+		log.Printf("[JOBS] campaign %q ended, discount removed from %d products", campaign.Name, released)
+	}
+
+	for _, campaign := range campaigns {
+		if now.Before(campaign.StartDate) || !now.Before(campaign.EndDate) {
+			continue
+		}
+		applied, err := s.apply(ctx, campaign)
+		if err != nil {
+			return err
+		}
+		set := bson.M{"updated_at": now}
+		if applied > 0 {
+			set["applied_count"] = campaign.AppliedCount + applied
+			changed = true
+		}
+		if campaign.Status == models.CampaignStatusScheduled {
+			set["status"] = models.CampaignStatusActive
+			set["activated_at"] = now
+			log.Printf("[JOBS] campaign %q started, discount applied to %d products", campaign.Name, applied)
+		} else if applied == 0 {
+			continue
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$set": set}); err != nil {
+			return fmt.Errorf("update campaign %s: %w", campaign.ID.Hex(), err)
+		}
+	}
+
+	if changed {
+		s.DB.InvalidateProductCaches(ctx)
+	}
+	return nil
+}
+
+// apply sets the campaign discount on targeted products that no other
+// campaign holds, returning how many products it newly claimed
+func (s *CampaignScheduler) apply(ctx context.Context, campaign models.Campaign) (int64, error) {
+	filter := bson.M{"campaign_id": bson.M{"$exists": false}}
+	switch campaign.Target {
+	case models.CampaignTargetCategory:
+		filter["category"] = models.CategorySubtree(campaign.Category)
+	case models.CampaignTargetBrand:
+		filter["brand"] = campaign.Brand
+	case models.CampaignTargetProducts:
+		filter["_id"] = bson.M{"$in": campaign.ProductIDs}
+	default:
+		return 0, nil
+	}
+
+	set := bson.M{
+		"discount_start_date": campaign.StartDate,
+		"discount_end_date":   campaign.EndDate,
+		"campaign_id":         campaign.ID,
+		"updated_at":          time.Now(),
+	}
+	unset := bson.M{}
+	if campaign.DiscountType == models.CampaignDiscountPercentage {
+		set["discount_percentage"] = campaign.DiscountValue
+		unset["discount_amount"] = ""
+	} else {
+		set["discount_amount"] = campaign.DiscountValue
+		unset["discount_percentage"] = ""
+	}
+
+	// Remember current prices so wishlisters can be told about the drop
+	products := s.DB.Collections().Products
+	var before []models.Product
+	cursor, err := products.Find(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("find campaign %s products: %w", campaign.ID.Hex(), err)
+	}
+	if err := cursor.All(ctx, &before); err != nil {
+		return 0, fmt.Errorf("decode campaign %s products: %w", campaign.ID.Hex(), err)
+	}
+	if len(before) == 0 {
+		return 0, nil
+	}
+
+	res, err := products.UpdateMany(ctx, filter, bson.M{"$set": set, "$unset": unset})
+	if err != nil {
+		return 0, fmt.Errorf("apply campaign %s: %w", campaign.ID.Hex(), err)
+	}
+	s.recordPriceChanges(ctx, campaign, before)
+	return res.ModifiedCount, nil
+}
+
+// recordPriceChanges adds the products the campaign just claimed to the
+// price history and tells their wishlisters about the new price
+func (s *CampaignScheduler) recordPriceChanges(ctx context.Context, campaign models.Campaign, before []models.Product) {
+	oldPrices := make(map[primitive.ObjectID]float64, len(before))
+	ids := make([]primitive.ObjectID, 0, len(before))
+	for _, p := range before {
+		oldPrices[p.ID] = p.GetFinalPrice()
+		ids = append(ids, p.ID)
+	}
+
+	var claimed []models.Product
+	cursor, err := s.DB.Collections().Products.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "campaign_id": campaign.ID})
+	if err == nil {
+		err = cursor.All(ctx, &claimed)
+	}
+	if err != nil {
+		log.Printf("[JOBS] campaign %q: failed to load products for price history and notifications: %v", campaign.Name, err)
+		return
+	}
+	pricehistory.Record(ctx, s.DB, models.PriceHistoryEntry{Reason: models.PriceChangeCampaignStart}, claimed...)
+	for _, p := range claimed {
+		if err := notify.PriceDrop(ctx, s.DB, p, oldPrices[p.ID]); err != nil {
+			log.Printf("[JOBS] campaign %q: failed to send price drop notifications for product %s: %v", campaign.Name, p.ID.Hex(), err)
+		}
+	}
+}
+
+// ReleaseCampaign removes a campaign's discount from every product it holds
+// and returns how many products were released
+func ReleaseCampaign(ctx context.Context, db *database.DBClient, campaignID primitive.ObjectID) (int64, error) {
+	products := db.Collections().Products
+	var ids []primitive.ObjectID
+	cursor, err := products.Find(ctx, bson.M{"campaign_id": campaignID}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err == nil {
+		var held []models.Product
+		if err = cursor.All(ctx, &held); err == nil {
+			for _, p := range held {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("find campaign %s products: %w", campaignID.Hex(), err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	res, err := products.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "campaign_id": campaignID},
+		bson.M{"$unset": productDiscountFields, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("release campaign %s: %w", campaignID.Hex(), err)
+	}
+
+	var released []models.Product
+	if cursor, err = products.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}); err == nil {
+		err = cursor.All(ctx, &released)
+	}
+	if err != nil {
+		log.Printf("[JOBS] campaign %s: failed to load released products for price history: %v", campaignID.Hex(), err)
+	} else {
+		pricehistory.Record(ctx, db, models.PriceHistoryEntry{Reason: models.PriceChangeCampaignEnd, CampaignID: &campaignID}, released...)
+	}
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/catalogread"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// The wait before reopening a failed change stream doubles between these
+const (
+	catalogWatchMinRetry = time.Second
+	catalogWatchMaxRetry = time.Minute
+)
+
+// CatalogProjector keeps the catalog_read collection in sync with products
+type CatalogProjector struct {
+	DB *database.DBClient
+}
+
+// Run follows the product change stream until ctx is cancelled, reopening
+// it after failures. It gives up when the deployment has no change streams.
+func (p *CatalogProjector) Run(ctx context.Context) {
+	retry := catalogWatchMinRetry
+	for {
+		started := time.Now()
+		err := catalogread.Watch(ctx, p.DB)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, catalogread.ErrUnsupported) {
+			log.Printf("[JOBS] catalog-read: %v; listings will read products directly", err)
+			return
+		}
+		if err != nil {
+			log.Printf("[JOBS] catalog-read: %v", err)
+		}
+
+		// A stream that ran for a while failed on its own rather than on
+		// opening, so the backoff starts over
+		if time.Since(started) > catalogWatchMaxRetry {
+			retry = catalogWatchMinRetry
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > catalogWatchMaxRetry {
+			retry = catalogWatchMaxRetry
+		}
+	}
+}
+
+// Refresh recomputes the prices of discounts that started or ended
+func (p *CatalogProjector) Refresh(ctx context.Context) error {
+	if !catalogread.Ready() {
+		return nil
+	}
+	return catalogread.Refresh(ctx, p.DB)
+}
+
+// Reconcile rebuilds catalog_read in full to repair drift
+func (p *CatalogProjector) Reconcile(ctx context.Context) error {
+	if !catalogread.Ready() {
+		return nil // Watch rebuilds before it serves
+	}
+	return catalogread.Rebuild(ctx, p.DB)
+}
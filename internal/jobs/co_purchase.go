@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// feedbackWeights is how strongly each recommendation feedback action counts
+// as interest in a product, relative to an order (1.0). Dismissals are ignored.
+var feedbackWeights = map[string]float64{
+	"purchase":    1.0,
+	"add_to_cart": 0.5,
+	"click":       0.25,
+	"view":        0.1,
+}
+
+// CoPurchaseBuilder rebuilds the "customers who bought X also bought Y"
+// scores stored in the Recommendations collection
+type CoPurchaseBuilder struct {
+	DB *database.DBClient
+	// Neighbours is how many related products are kept per product
+	Neighbours int
+}
+
+// Build recomputes every co-purchase score from orders and feedback and
+// replaces the previous set
+func (b *CoPurchaseBuilder) Build(ctx context.Context) error {
+	// interest[user][product] is the strongest signal seen for the pair
+	interest := make(map[primitive.ObjectID]map[primitive.ObjectID]float64)
+	note := func(user, product primitive.ObjectID, weight float64) {
+		products, ok := interest[user]
+		if !ok {
+			products = make(map[primitive.ObjectID]float64)
+			interest[user] = products
+		}
+		if weight > products[product] {
+			products[product] = weight
+		}
+	}
+
+	orders, err := b.DB.Collections().Orders.Find(ctx,
+		bson.M{"status": bson.M{"$ne": orderstatus.Cancelled}},
+		options.Find().SetProjection(bson.M{"user_id": 1, "items.product_id": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("find orders: %w", err)
+	}
+	for orders.Next(ctx) {
+		var order models.Order
+		if err := orders.Decode(&order); err != nil {
+			continue
+		}
+		for _, item := range order.Items {
+			note(order.UserID, item.ProductID, 1.0)
+		}
+	}
+	orders.Close(ctx)
+	if err := orders.Err(); err != nil {
+		return fmt.Errorf("read orders: %w", err)
+	}
+
+	actions := make([]string, 0, len(feedbackWeights))
+	for action := range feedbackWeights {
+		actions = append(actions, action)
+	}
+	feedback, err := b.DB.Collections().RecFeedbacks.Find(ctx,
+		bson.M{"action": bson.M{"$in": actions}},
+		options.Find().SetProjection(bson.M{"user_id": 1, "product_id": 1, "action": 1}),
+	)
+	if err != nil {
+		return fmt.Errorf("find feedback: %w", err)
+	}
+	for feedback.Next(ctx) {
+		var fb models.RecommendationFeedback
+		if err := feedback.Decode(&fb); err != nil {
+			continue
+		}
+		note(fb.UserID, fb.ProductID, feedbackWeights[fb.Action])
+	}
+	feedback.Close(ctx)
+	if err := feedback.Err(); err != nil {
+		return fmt.Errorf("read feedback: %w", err)
+	}
+
+	items := coPurchaseScores(interest, b.neighbours())
+
+	// Swap in the new scores. Readers may briefly see none, in which case
+	// GetRecommendations falls back to preference filters.
+	coll := b.DB.Collections().Recommendations
+	if _, err := coll.DeleteMany(ctx, bson.M{"source": models.SourceCoPurchase}); err != nil {
+		return fmt.Errorf("clear co-purchase scores: %w", err)
+	}
+	if len(items) > 0 {
+		docs := make([]interface{}, len(items))
+		for i := range items {
+			docs[i] = items[i]
+		}
+		if _, err := coll.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+			return fmt.Errorf("store co-purchase scores: %w", err)
+		}
+	}
+
+	log.Printf("[JOBS] co-purchase: %d scores from %d customers", len(items), len(interest))
+	return nil
+}
+
+func (b *CoPurchaseBuilder) neighbours() int {
+	if b.Neighbours > 0 {
+		return b.Neighbours
+	}
+	return 20
+}
+
+// coPurchaseScores turns per-customer interest into item-to-item cosine
+// similarity, keeping the top n neighbours of each product
+func coPurchaseScores(interest map[primitive.ObjectID]map[primitive.ObjectID]float64, n int) []models.RecommendationItem {
+	type pair struct{ a, b primitive.ObjectID }
+	co := make(map[pair]float64)
+	norm := make(map[primitive.ObjectID]float64)
+
+	for _, products := range interest {
+		if len(products) < 2 {
+			for p, w := range products {
+				norm[p] += w * w
+			}
+			continue
+		}
+		for p, wp := range products {
+			norm[p] += wp * wp
+			for q, wq := range products {
+				if p != q {
+					co[pair{p, q}] += wp * wq
+				}
+			}
+		}
+	}
+
+	neighbours := make(map[primitive.ObjectID][]models.RecommendationItem)
+	now := time.Now()
+	for k, v := range co {
+		score := v / math.Sqrt(norm[k.a]*norm[k.b])
+		neighbours[k.a] = append(neighbours[k.a], models.RecommendationItem{
+			ID:             primitive.NewObjectID(),
+			BasisProductID: k.a,
+			ProductID:      k.b,
+			Score:          score,
+			Source:         models.SourceCoPurchase,
+			Reason:         "Customers who bought this also bought",
+			CreatedAt:      now,
+		})
+	}
+
+	var items []models.RecommendationItem
+	for _, list := range neighbours {
+		sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+		if len(list) > n {
+			list = list[:n]
+		}
+		items = append(items, list...)
+	}
+	return items
+}
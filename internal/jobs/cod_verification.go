@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/notify"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// CODVerificationExpirer cancels COD orders nobody confirmed in time and
+// returns their items to stock
+type CODVerificationExpirer struct {
+	DB     *database.DBClient
+	Events *events.Bus // Optional
+}
+
+// Expire cancels every pending_verification order past its deadline
+func (e *CODVerificationExpirer) Expire(ctx context.Context) error {
+	orders := e.DB.Collections().Orders
+	cursor, err := orders.Find(ctx, bson.M{
+		"status":                      orderstatus.PendingVerification,
+		"cod_verification.expires_at": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("find expired orders: %w", err)
+	}
+	var expired []models.Order
+	if err := cursor.All(ctx, &expired); err != nil {
+		return fmt.Errorf("decode expired orders: %w", err)
+	}
+
+	cancelled := 0
+	for _, order := range expired {
+		now := time.Now()
+		// The status filter loses the race to a customer or admin verifying
+		// the order at the same moment, so stock is never restored twice
+		res, err := orders.UpdateOne(ctx,
+			bson.M{"_id": order.ID, "status": orderstatus.PendingVerification},
+			bson.M{
+				"$set": bson.M{
+					"status":        orderstatus.Cancelled,
+					"cancel_reason": "cod_verification_expired",
+					"updated_at":    now,
+				},
+				"$push": bson.M{"status_history": models.OrderStatusEvent{
+					Status:    orderstatus.Cancelled,
+					Actor:     models.OrderActorSystem,
+					Note:      "Cash on delivery not confirmed in time",
+					Timestamp: now,
+				}},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("cancel order %s: %w", order.ID.Hex(), err)
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+		cancelled++
+		restoreStock(ctx, e.DB, order, "COD verification expired")
+		giftcards.Refund(ctx, e.DB, order)
+		e.Events.Publish(ctx, events.OrderStatusChanged, events.OrderStatusChange{
+			OrderID: order.ID.Hex(),
+			UserID:  order.UserID.Hex(),
+			From:    orderstatus.PendingVerification,
+			To:      orderstatus.Cancelled,
+			Actor:   models.OrderActorSystem,
+			Note:    "Cash on delivery not confirmed in time",
+		})
+
+		e.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()))
+		e.DB.CacheDel(ctx, fmt.Sprintf("orders:%s", order.UserID.Hex()))
+	}
+	if cancelled > 0 {
+		log.Printf("[JOBS] cod verification: cancelled %d unverified orders", cancelled)
+	}
+	return nil
+}
+
+// restoreStock returns a cancelled order's items to stock and records each
+// return in the stock ledger with note. Unallocated pre-orders, which never
+// took stock, are released instead.
+func restoreStock(ctx context.Context, db *database.DBClient, order models.Order, note string) {
+	products := db.Collections().Products
+	for _, item := range order.Items {
+		if item.Preorder {
+			if _, err := products.UpdateOne(ctx, bson.M{"_id": item.ProductID}, bson.M{"$inc": bson.M{"preorder_count": -item.Quantity}}); err != nil {
+				log.Printf("[JOBS] failed to release pre-order of product %s for order %s: %v", item.ProductID.Hex(), order.ID.Hex(), err)
+			}
+			db.InvalidateProductCaches(ctx, item.ProductID.Hex())
+			continue
+		}
+		var restored models.Product
+		err := products.FindOneAndUpdate(ctx,
+			bson.M{"_id": item.ProductID},
+			bson.M{"$inc": bson.M{"stock": item.Quantity}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"stock": 1}),
+		).Decode(&restored)
+		if err != nil {
+			log.Printf("[JOBS] failed to restore stock for product %s of order %s: %v", item.ProductID.Hex(), order.ID.Hex(), err)
+			continue
+		}
+
+		orderID := order.ID
+		_, err = db.Collections().StockMovements.InsertOne(ctx, models.StockMovement{
+			ID:         primitive.NewObjectID(),
+			ProductID:  item.ProductID,
+			Delta:      item.Quantity,
+			StockAfter: restored.Stock,
+			Reason:     models.StockReasonCancellation,
+			Note:       note,
+			OrderID:    &orderID,
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			log.Printf("[JOBS] failed to record stock movement for product %s: %v", item.ProductID.Hex(), err)
+		}
+		if err := notify.StockChanged(ctx, db, item.ProductID, restored.Stock-item.Quantity, restored.Stock); err != nil {
+			log.Printf("[JOBS] failed to send back-in-stock notifications for product %s: %v", item.ProductID.Hex(), err)
+		}
+
+		db.InvalidateProductCaches(ctx, item.ProductID.Hex())
+	}
+}
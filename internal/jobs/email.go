@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+)
+
+// TypeSendEmail delivers a mailer.Message, retrying when the relay fails
+const TypeSendEmail = "email.send"
+
+// sendEmail returns the handler for TypeSendEmail jobs
+func sendEmail(sender mailer.Sender) HandlerFunc {
+	return func(ctx context.Context, job *Job) error {
+		var msg mailer.Message
+		if err := job.Decode(&msg); err != nil {
+			return fmt.Errorf("%w: decode message: %v", ErrPermanent, err)
+		}
+		if msg.To == "" {
+			return fmt.Errorf("%w: message has no recipient", ErrPermanent)
+		}
+		return sender.Send(ctx, msg)
+	}
+}
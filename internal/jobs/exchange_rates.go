@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+const exchangeRateTimeout = 15 * time.Second
+
+// ExchangeRateUpdater refreshes the rates of currencies marked autoUpdate
+// from a JSON feed of the form {"base": "INR", "rates": {"USD": 0.012, ...}}
+// ("base_code" is accepted for "base"). Feeds quoted against another base
+// are converted as long as they include INR.
+type ExchangeRateUpdater struct {
+	DB     *database.DBClient
+	URL    string
+	Client *http.Client // Optional; a client with exchangeRateTimeout is used when nil
+}
+
+// Update fetches the feed and stores the new rates
+func (u *ExchangeRateUpdater) Update(ctx context.Context) error {
+	rates, err := u.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	coll := u.DB.Collections().Currencies
+	cursor, err := coll.Find(ctx, bson.M{"auto_update": true, "_id": bson.M{"$ne": models.BaseCurrency}})
+	if err != nil {
+		return fmt.Errorf("find currencies: %w", err)
+	}
+	var currencies []models.Currency
+	if err := cursor.All(ctx, &currencies); err != nil {
+		return fmt.Errorf("decode currencies: %w", err)
+	}
+
+	now := time.Now()
+	updated := 0
+	for _, currency := range currencies {
+		rate, ok := rates[currency.Code]
+		if !ok || rate <= 0 {
+			log.Printf("[JOBS] exchange-rates: feed has no rate for %s", currency.Code)
+			continue
+		}
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": currency.Code}, bson.M{"$set": bson.M{
+			"rate":            rate,
+			"rate_updated_at": now,
+			"updated_at":      now,
+		}}); err != nil {
+			return fmt.Errorf("update %s: %w", currency.Code, err)
+		}
+		updated++
+	}
+	if updated > 0 {
+		log.Printf("[JOBS] exchange-rates: updated %d currencies", updated)
+	}
+	return nil
+}
+
+// fetch returns the feed's rates as units per one unit of the base currency
+func (u *ExchangeRateUpdater) fetch(ctx context.Context) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	client := u.Client
+	if client == nil {
+		client = &http.Client{Timeout: exchangeRateTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch rates: status %d", resp.StatusCode)
+	}
+
+	var feed struct {
+		Base     string             `json:"base"`
+		BaseCode string             `json:"base_code"`
+		Rates    map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("decode rates: %w", err)
+	}
+	if len(feed.Rates) == 0 {
+		return nil, fmt.Errorf("feed has no rates")
+	}
+
+	base := strings.ToUpper(feed.Base)
+	if base == "" {
+		base = strings.ToUpper(feed.BaseCode)
+	}
+	if base == "" || base == models.BaseCurrency {
+		return feed.Rates, nil
+	}
+	perBase, ok := feed.Rates[models.BaseCurrency]
+	if !ok || perBase <= 0 {
+		return nil, fmt.Errorf("feed is quoted in %s and has no %s rate", base, models.BaseCurrency)
+	}
+	rates := make(map[string]float64, len(feed.Rates)+1)
+	for code, rate := range feed.Rates {
+		rates[code] = rate / perBase
+	}
+	rates[base] = 1 / perBase
+	return rates, nil
+}
@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// imageReferences lists every collection field that may hold the URL of a
+// stored file. A file referenced by none of them is orphaned. Files in the
+// media library are kept until deleted from it.
+var imageReferences = []struct {
+	collection string
+	fields     []string
+}{
+	{"products", []string{"image_url", "images"}},
+	{"categories", []string{"image_url"}},
+	{"hero_slides", []string{"image"}},
+	{"home_category_cards", []string{"image"}},
+	{"home_collection_features", []string{"image"}},
+	{"home_tech_cards", []string{"image", "backgroundImage"}},
+	{"home_gallery_images", []string{"url"}},
+	{"home_testimonials", []string{"avatar"}},
+	{"settings", []string{"logo"}},
+	{"reviews", []string{"photo_urls"}},
+	{"returns", []string{"photo_urls"}},
+	{"user_profiles", []string{"avatar_url"}},
+	{mediaCollection, []string{"urls"}},
+}
+
+const mediaCollection = "media"
+
+// ImageUsage is a document using a stored file
+type ImageUsage struct {
+	Collection string `json:"collection"`
+	ID         string `json:"id"`
+}
+
+// ImageUsages finds the documents using each of urls, leaving out the media
+// library itself. URLs nothing uses are missing from the result.
+func ImageUsages(ctx context.Context, db *database.DBClient, urls []string) (map[string][]ImageUsage, error) {
+	wanted := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		wanted[url] = true
+	}
+	usages := make(map[string][]ImageUsage)
+	for _, ref := range imageReferences {
+		if ref.collection == mediaCollection {
+			continue
+		}
+		projection := bson.M{}
+		or := bson.A{}
+		for _, field := range ref.fields {
+			projection[field] = 1
+			or = append(or, bson.M{field: bson.M{"$in": urls}})
+		}
+		cursor, err := db.MongoDB.Collection(ref.collection).Find(ctx, bson.M{"$or": or}, options.Find().SetProjection(projection))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", ref.collection, err)
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return nil, fmt.Errorf("read %s: %w", ref.collection, err)
+		}
+		for _, doc := range docs {
+			usage := ImageUsage{Collection: ref.collection, ID: fmt.Sprint(doc["_id"])}
+			if id, ok := doc["_id"].(primitive.ObjectID); ok {
+				usage.ID = id.Hex()
+			}
+			delete(doc, "_id")
+			seen := make(map[string]bool)
+			collectURLs(doc, func(url string) {
+				if wanted[url] && !seen[url] {
+					seen[url] = true
+					usages[url] = append(usages[url], usage)
+				}
+			})
+		}
+	}
+	return usages, nil
+}
+
+// ImageCleaner deletes stored files that no document references any more
+type ImageCleaner struct {
+	DB      *database.DBClient
+	Storage storage.Storage
+	// MinAge protects recent uploads, e.g. ones made moments before the
+	// product that will use them is saved
+	MinAge time.Duration
+}
+
+// Orphans lists stored files older than MinAge that nothing references.
+// It never deletes anything, so it doubles as the dry run.
+func (c *ImageCleaner) Orphans(ctx context.Context) ([]storage.Object, error) {
+	// List first: a file uploaded and referenced after this point is newer
+	// than MinAge anyway, so it can't be reported by mistake
+	objects, err := c.Storage.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list stored files: %w", err)
+	}
+
+	referenced, err := c.referencedKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-c.MinAge)
+	orphans := []storage.Object{}
+	for _, obj := range objects {
+		if !referenced[obj.Key] && obj.UpdatedAt.Before(cutoff) {
+			orphans = append(orphans, obj)
+		}
+	}
+	return orphans, nil
+}
+
+// Clean deletes every orphaned file
+func (c *ImageCleaner) Clean(ctx context.Context) error {
+	orphans, err := c.Orphans(ctx)
+	if err != nil {
+		return err
+	}
+
+	deleted := 0
+	var freed int64
+	for _, obj := range orphans {
+		if err := c.Storage.Delete(ctx, obj.Key); err != nil {
+			log.Printf("[JOBS] image cleanup: failed to delete %s: %v", obj.Key, err)
+			continue
+		}
+		deleted++
+		freed += obj.Size
+	}
+	if len(orphans) > 0 {
+		log.Printf("[JOBS] image cleanup: deleted %d of %d orphaned files (%d bytes)", deleted, len(orphans), freed)
+	}
+	return nil
+}
+
+// referencedKeys collects the storage key of every URL held in
+// imageReferences. Any read failure aborts, since an incomplete set would
+// make referenced files look orphaned.
+func (c *ImageCleaner) referencedKeys(ctx context.Context) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for _, ref := range imageReferences {
+		projection := bson.M{}
+		for _, field := range ref.fields {
+			projection[field] = 1
+		}
+		cursor, err := c.DB.MongoDB.Collection(ref.collection).Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", ref.collection, err)
+		}
+		for cursor.Next(ctx) {
+			var doc bson.M
+			if err := cursor.Decode(&doc); err != nil {
+				cursor.Close(ctx)
+				return nil, fmt.Errorf("decode %s: %w", ref.collection, err)
+			}
+			delete(doc, "_id")
+			collectURLs(doc, func(url string) {
+				if key, ok := c.Storage.KeyFromURL(url); ok {
+					keys[key] = true
+				}
+			})
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", ref.collection, err)
+		}
+	}
+	return keys, nil
+}
+
+// collectURLs calls fn for every string inside a decoded document
+func collectURLs(v interface{}, fn func(string)) {
+	switch val := v.(type) {
+	case string:
+		if val != "" {
+			fn(val)
+		}
+	case bson.M:
+		for _, nested := range val {
+			collectURLs(nested, fn)
+		}
+	case bson.D:
+		for _, e := range val {
+			collectURLs(e.Value, fn)
+		}
+	case bson.A:
+		for _, nested := range val {
+			collectURLs(nested, fn)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package jobs runs background work alongside the API server: periodic
+// checks on a fixed schedule and queued jobs processed by a worker pool.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/messaging"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/razorpay"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// Start launches the background jobs enabled in cfg and the queue's worker
+// pool. They stop when ctx is cancelled.
+func Start(ctx context.Context, db *database.DBClient, cfg *config.Config, queue *Queue, store storage.Storage, bus *events.Bus) {
+	mail := mailer.New(mailer.Options{
+		SMTPHost:     cfg.SMTPHost,
+		SMTPPort:     cfg.SMTPPort,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+		From:         cfg.MailFrom,
+	})
+
+	if cfg.LowStockCheckIntervalMinutes > 0 {
+		monitor := &LowStockMonitor{DB: db, Config: cfg, Mailer: mail, Events: bus}
+		go every(ctx, db, "low-stock", time.Duration(cfg.LowStockCheckIntervalMinutes)*time.Minute, monitor.Check)
+	}
+
+	if cfg.ReportCheckIntervalMinutes > 0 {
+		scheduler := &ReportScheduler{DB: db, Config: cfg, Queue: queue}
+		go every(ctx, db, "reports", time.Duration(cfg.ReportCheckIntervalMinutes)*time.Minute, scheduler.Check)
+	}
+
+	if cfg.RecommendationRebuildIntervalMinutes > 0 {
+		builder := &CoPurchaseBuilder{DB: db}
+		go every(ctx, db, "co-purchase", time.Duration(cfg.RecommendationRebuildIntervalMinutes)*time.Minute, builder.Build)
+	}
+
+	if cfg.CampaignCheckIntervalMinutes > 0 {
+		scheduler := &CampaignScheduler{DB: db}
+		go every(ctx, db, "campaigns", time.Duration(cfg.CampaignCheckIntervalMinutes)*time.Minute, scheduler.Sync)
+	}
+
+	if cfg.RatingReconcileIntervalHours > 0 {
+		reconciler := &RatingReconciler{DB: db}
+		go every(ctx, db, "ratings", time.Duration(cfg.RatingReconcileIntervalHours)*time.Hour, reconciler.Reconcile)
+	}
+
+	if cfg.ProductViewFlushIntervalMinutes > 0 {
+		flusher := &ProductViewFlusher{DB: db, WindowDays: cfg.PopularityWindowDays}
+		go every(ctx, db, "product-views", time.Duration(cfg.ProductViewFlushIntervalMinutes)*time.Minute, flusher.Flush)
+	}
+
+	if cfg.CatalogReadModel {
+		projector := &CatalogProjector{DB: db}
+		go projector.Run(ctx)
+		go every(ctx, db, "catalog-read-prices", time.Minute, projector.Refresh)
+		if cfg.CatalogReadReconcileIntervalMinutes > 0 {
+			go every(ctx, db, "catalog-read", time.Duration(cfg.CatalogReadReconcileIntervalMinutes)*time.Minute, projector.Reconcile)
+		}
+	}
+
+	if cfg.ImageCleanupIntervalHours > 0 {
+		cleaner := &ImageCleaner{DB: db, Storage: store, MinAge: time.Duration(cfg.ImageCleanupMinAgeDays) * 24 * time.Hour}
+		go every(ctx, db, "image-cleanup", time.Duration(cfg.ImageCleanupIntervalHours)*time.Hour, cleaner.Clean)
+	}
+
+	if cfg.RetentionIntervalHours > 0 {
+		pruner := &Pruner{DB: db, Policies: RetentionPolicies(cfg)}
+		go every(ctx, db, "retention", time.Duration(cfg.RetentionIntervalHours)*time.Hour, pruner.Prune)
+	}
+
+	if cfg.ExchangeRateURL != "" && cfg.ExchangeRateIntervalHours > 0 {
+		updater := &ExchangeRateUpdater{DB: db, URL: cfg.ExchangeRateURL}
+		go every(ctx, db, "exchange-rates", time.Duration(cfg.ExchangeRateIntervalHours)*time.Hour, updater.Update)
+	}
+
+	if (cfg.CODVerification == models.CODVerificationOTP || cfg.CODVerification == models.CODVerificationEmail) && cfg.CODVerificationCheckIntervalMinutes > 0 {
+		expirer := &CODVerificationExpirer{DB: db, Events: bus}
+		go every(ctx, db, "cod-verification", time.Duration(cfg.CODVerificationCheckIntervalMinutes)*time.Minute, expirer.Expire)
+	}
+
+	live, test := cfg.Razorpay(models.PaymentModeLive), cfg.Razorpay(models.PaymentModeTest)
+	if (live.Configured() || test.Configured()) && cfg.PaymentReconcileIntervalMinutes > 0 {
+		reconciler := &PaymentReconciler{
+			DB:           db,
+			Razorpay:     razorpay.New(live.Key, live.Secret),
+			TestRazorpay: razorpay.New(test.Key, test.Secret),
+			Events:       bus,
+			Timeout:      time.Duration(cfg.PaymentTimeoutMinutes) * time.Minute,
+			CancelAfter:  time.Duration(cfg.UnpaidOrderTTLMinutes) * time.Minute,
+			Mailer:       mail,
+			Queue:        queue,
+		}
+		go every(ctx, db, "payments", time.Duration(cfg.PaymentReconcileIntervalMinutes)*time.Minute, reconciler.Reconcile)
+	}
+
+	queue.Register(TypeSendEmail, sendEmail(mail))
+
+	// Events reach webhook endpoints through the queue so slow or failing
+	// receivers never hold up the request that raised the event
+	dispatcher := &WebhookDispatcher{DB: db, Queue: queue}
+	bus.Subscribe(dispatcher.Dispatch)
+	queue.Register(TypeDeliverWebhook, dispatcher.Deliver)
+
+	// Account events are written straight to the account's timeline
+	recorder := &ActivityRecorder{DB: db}
+	bus.Subscribe(recorder.Record)
+
+	// Delivered watches get their warranties
+	warranties := &WarrantyIssuer{DB: db}
+	bus.Subscribe(warranties.Handle)
+
+	// Order updates by WhatsApp and SMS go through the queue for the same reason
+	messengers := map[string]messaging.Sender{}
+	messagingOpts := messaging.Options{
+		WhatsAppPhoneNumberID: cfg.WhatsAppPhoneNumberID,
+		WhatsAppAccessToken:   cfg.WhatsAppAccessToken,
+		WhatsAppLanguage:      cfg.WhatsAppTemplateLanguage,
+		MSG91AuthKey:          cfg.MSG91AuthKey,
+		MSG91Templates: map[string]string{
+			messaging.OrderConfirmed: cfg.MSG91OrderConfirmedTemplateID,
+			messaging.OrderShipped:   cfg.MSG91OrderShippedTemplateID,
+			messaging.OrderDelivered: cfg.MSG91OrderDeliveredTemplateID,
+		},
+	}
+	if cfg.WhatsAppOrderUpdates {
+		messengers[messaging.ChannelWhatsApp] = messaging.New(messaging.ChannelWhatsApp, messagingOpts)
+	}
+	if cfg.SMSOrderUpdates {
+		messengers[messaging.ChannelSMS] = messaging.New(messaging.ChannelSMS, messagingOpts)
+	}
+	if len(messengers) > 0 {
+		messenger := &OrderMessenger{DB: db, Queue: queue, Senders: messengers}
+		bus.Subscribe(messenger.Dispatch)
+		queue.Register(TypeSendOrderMessage, messenger.Send)
+	}
+
+	if cfg.JobWorkers > 0 {
+		log.Printf("[JOBS] Starting %d workers on the %s queue", cfg.JobWorkers, queue.Backend())
+		go queue.Run(ctx, cfg.JobWorkers)
+	}
+}
+
+// every runs fn immediately and then on each tick until ctx is cancelled.
+// Each run holds a lock named after the job, so with several API instances
+// a run is skipped while another instance is running the same job. Errors
+// are logged; a failed run never stops the schedule.
+func every(ctx context.Context, db *database.DBClient, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, interval)
+		err := db.WithLock(runCtx, "jobs:"+name, fn)
+		if err != nil && !errors.Is(err, database.ErrLockHeld) && ctx.Err() == nil {
+			log.Printf("[JOBS] %s: %v", name, err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
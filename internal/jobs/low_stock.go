@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// LowStockMonitor alerts admins when a product's stock falls to or below its
+// reorder threshold. Each product is alerted once until it is restocked.
+type LowStockMonitor struct {
+	DB     *database.DBClient
+	Config *config.Config
+	Mailer mailer.Sender
+	Events *events.Bus // Optional; receives a stock.low event per product
+}
+
+// Check sends alerts for products that newly crossed their threshold
+func (m *LowStockMonitor) Check(ctx context.Context) error {
+	products := m.DB.Collections().Products
+	lowStock := models.LowStockExpr(m.Config.LowStockThreshold)
+
+	// Restocked products become eligible for a fresh alert
+	if _, err := products.UpdateMany(ctx,
+		bson.M{"low_stock_alerted_at": bson.M{"$exists": true}, "$expr": bson.M{"$not": bson.A{lowStock}}},
+		bson.M{"$unset": bson.M{"low_stock_alerted_at": ""}},
+	); err != nil {
+		return fmt.Errorf("reset restocked alerts: %w", err)
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "stock": 1, "reorder_threshold": 1}).
+		SetSort(bson.D{{Key: "stock", Value: 1}})
+	cursor, err := products.Find(ctx, bson.M{"low_stock_alerted_at": bson.M{"$exists": false}, "$expr": lowStock}, opts)
+	if err != nil {
+		return fmt.Errorf("find low-stock products: %w", err)
+	}
+	var low []models.Product
+	if err := cursor.All(ctx, &low); err != nil {
+		return fmt.Errorf("decode low-stock products: %w", err)
+	}
+	if len(low) == 0 {
+		return nil
+	}
+
+	admins, err := m.activeAdmins(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	notifications := make([]interface{}, 0, len(admins)*len(low))
+	for _, admin := range admins {
+		for _, p := range low {
+			notifications = append(notifications, models.Notification{
+				UserID:      admin.ID,
+				Type:        "product",
+				Title:       "Low stock: " + p.Name,
+				Message:     m.describe(p),
+				ReferenceID: p.ID,
+				CreatedAt:   now,
+			})
+		}
+	}
+	if len(notifications) > 0 {
+		if _, err := m.DB.Collections().Notifications.InsertMany(ctx, notifications); err != nil {
+			return fmt.Errorf("insert low-stock notifications: %w", err)
+		}
+	}
+
+	// Email is best effort; the in-app notifications are already stored
+	m.sendEmail(ctx, admins, low)
+	for _, p := range low {
+		m.Events.Publish(ctx, events.StockLow, events.LowStock{
+			ProductID: p.ID.Hex(),
+			Name:      p.Name,
+			Stock:     p.Stock,
+			Threshold: p.EffectiveReorderThreshold(m.Config.LowStockThreshold),
+		})
+	}
+
+	ids := make([]primitive.ObjectID, len(low))
+	for i, p := range low {
+		ids[i] = p.ID
+	}
+	if _, err := products.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"low_stock_alerted_at": now}},
+	); err != nil {
+		return fmt.Errorf("mark low-stock products alerted: %w", err)
+	}
+
+	log.Printf("[JOBS] low-stock: alerted %d admin(s) about %d product(s)", len(admins), len(low))
+	return nil
+}
+
+// activeAdmins returns admin accounts that can still sign in
+func (m *LowStockMonitor) activeAdmins(ctx context.Context) ([]models.User, error) {
+	filter := bson.M{
+		"role":   "admin",
+		"status": bson.M{"$nin": bson.A{models.UserStatusSuspended, models.UserStatusDeleted}},
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "email": 1})
+	cursor, err := m.DB.Collections().Users.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("find admins: %w", err)
+	}
+	var admins []models.User
+	if err := cursor.All(ctx, &admins); err != nil {
+		return nil, fmt.Errorf("decode admins: %w", err)
+	}
+	return admins, nil
+}
+
+func (m *LowStockMonitor) sendEmail(ctx context.Context, admins []models.User, low []models.Product) {
+	var recipients []string
+	if m.Config.AdminAlertEmails != "" {
+		for _, addr := range strings.Split(m.Config.AdminAlertEmails, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				recipients = append(recipients, addr)
+			}
+		}
+	} else {
+		for _, admin := range admins {
+			if admin.Email != "" {
+				recipients = append(recipients, admin.Email)
+			}
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	var body strings.Builder
+	body.WriteString("The following products are at or below their reorder threshold:\n\n")
+	for _, p := range low {
+		fmt.Fprintf(&body, "- %s: %s\n", p.Name, m.describe(p))
+	}
+	fmt.Fprintf(&body, "\nReview stock levels in the admin inventory dashboard.\n")
+
+	subject := fmt.Sprintf("Low stock alert: %d product(s)", len(low))
+	for _, to := range recipients {
+		msg := mailer.Message{To: to, Subject: subject, Body: body.String()}
+		if err := m.Mailer.Send(ctx, msg); err != nil {
+			log.Printf("[JOBS] low-stock: failed to email %s via %s: %v", to, m.Mailer.Name(), err)
+		}
+	}
+}
+
+func (m *LowStockMonitor) describe(p models.Product) string {
+	return fmt.Sprintf("%d in stock (reorder threshold %d)", p.Stock, p.EffectiveReorderThreshold(m.Config.LowStockThreshold))
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/messaging"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// TypeSendOrderMessage delivers an order update by WhatsApp or SMS,
+// retrying when the provider fails
+const TypeSendOrderMessage = "order_message.send"
+
+// orderMessage is the payload of a TypeSendOrderMessage job
+type orderMessage struct {
+	Channel string            `json:"channel"`
+	Message messaging.Message `json:"message"`
+}
+
+// orderMessageKinds is the message sent when an order reaches each status
+var orderMessageKinds = map[string]string{
+	orderstatus.Processing: messaging.OrderConfirmed,
+	orderstatus.Shipped:    messaging.OrderShipped,
+	orderstatus.Delivered:  messaging.OrderDelivered,
+}
+
+// OrderMessenger sends order updates over the channels each customer opted
+// in to. Senders holds the channels switched on in the config.
+type OrderMessenger struct {
+	DB      *database.DBClient
+	Queue   *Queue
+	Senders map[string]messaging.Sender
+}
+
+// Dispatch is an events.Handler: it queues a message per opted-in channel
+// when an order is placed already confirmed or moves to a status customers
+// are told about
+func (m *OrderMessenger) Dispatch(ctx context.Context, e events.Event) error {
+	var orderID primitive.ObjectID
+	var kind string
+	switch data := e.Data.(type) {
+	case models.Order:
+		if e.Type != events.OrderCreated || data.Status != orderstatus.Processing {
+			return nil
+		}
+		orderID, kind = data.ID, messaging.OrderConfirmed
+	case events.OrderStatusChange:
+		if e.Type != events.OrderStatusChanged {
+			return nil
+		}
+		if kind = orderMessageKinds[data.To]; kind == "" {
+			return nil
+		}
+		id, err := primitive.ObjectIDFromHex(data.OrderID)
+		if err != nil {
+			return err
+		}
+		orderID = id
+	default:
+		return nil
+	}
+
+	var order models.Order
+	if err := m.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+		return fmt.Errorf("find order: %w", err)
+	}
+	var prefs models.UserPreferences
+	err := m.DB.Collections().UserPreferences.FindOne(ctx, bson.M{"user_id": order.UserID}).Decode(&prefs)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil // Never opted in
+	}
+	if err != nil {
+		return fmt.Errorf("find preferences: %w", err)
+	}
+	phone, ok := models.NormalizePhone(order.ShippingAddress.Phone)
+	if !ok {
+		return nil
+	}
+
+	optedIn := map[string]bool{
+		messaging.ChannelWhatsApp: prefs.OrderUpdates.WhatsApp,
+		messaging.ChannelSMS:      prefs.OrderUpdates.SMS,
+	}
+	msg := messaging.Message{To: phone, Kind: kind, Params: []string{order.ShippingAddress.Name, order.ID.Hex()}}
+	for channel := range m.Senders {
+		if !optedIn[channel] {
+			continue
+		}
+		if _, err := m.Queue.Enqueue(ctx, TypeSendOrderMessage, orderMessage{Channel: channel, Message: msg}); err != nil {
+			return fmt.Errorf("queue %s message: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+// Send is the handler for TypeSendOrderMessage jobs
+func (m *OrderMessenger) Send(ctx context.Context, job *Job) error {
+	var payload orderMessage
+	if err := job.Decode(&payload); err != nil {
+		return fmt.Errorf("%w: decode message: %v", ErrPermanent, err)
+	}
+	sender, ok := m.Senders[payload.Channel]
+	if !ok {
+		return nil // Channel switched off since the message was queued
+	}
+	return sender.Send(ctx, payload.Message)
+}
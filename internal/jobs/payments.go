@@ -0,0 +1,299 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/giftcards"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/razorpay"
+)
+
+// PaymentReconciler settles Razorpay orders placed before they were paid
+// whose payment never reached the API, e.g. when the shopper's app closed
+// before checkout called back. A captured payment marks the order paid;
+// orders still unpaid after CancelAfter are cancelled, their items returned
+// to stock and the customer told by email.
+type PaymentReconciler struct {
+	DB       *database.DBClient
+	Razorpay *razorpay.Client // Live mode
+	// TestRazorpay checks the orders paid in test mode; optional
+	TestRazorpay *razorpay.Client
+	Events       *events.Bus   // Optional
+	Timeout      time.Duration // How long an order waits before it is checked
+	// CancelAfter is how long an order may stay unpaid; 0 never cancels.
+	// Orders are only looked at after Timeout, so that is the shortest.
+	CancelAfter time.Duration
+	Mailer      mailer.Sender // Optional; no email is sent when nil
+	Queue       *Queue        // Optional; emails are sent inline when nil
+}
+
+// PaymentCheck is what Razorpay reported about an order's payment
+type PaymentCheck struct {
+	GatewayStatus string // Razorpay order status: "created", "attempted" or "paid"
+	Paid          bool   // A payment was captured
+	// InFlight is set while a payment is authorized but not yet captured
+	InFlight bool
+}
+
+// awaitingPayment matches Razorpay orders placed before they were paid
+func awaitingPayment() bson.M {
+	return bson.M{
+		"payment_info.method": "razorpay",
+		"status":              orderstatus.Pending,
+		"payment_status":      bson.M{"$in": []string{"unpaid", "failed"}},
+	}
+}
+
+// gateway returns the client for the mode an order was paid in, or nil
+// when that mode has no keys
+func (r *PaymentReconciler) gateway(order models.Order) *razorpay.Client {
+	client := r.Razorpay
+	if order.PaymentInfo.Mode == models.PaymentModeTest {
+		client = r.TestRazorpay
+	}
+	if client == nil || !client.Configured() {
+		return nil
+	}
+	return client
+}
+
+// orderPaymentMode is the Razorpay mode an order was paid in
+func orderPaymentMode(order models.Order) string {
+	if order.PaymentInfo.Mode == models.PaymentModeTest {
+		return models.PaymentModeTest
+	}
+	return models.PaymentModeLive
+}
+
+// Reconcile settles every order that has waited longer than Timeout
+func (r *PaymentReconciler) Reconcile(ctx context.Context) error {
+	filter := awaitingPayment()
+	filter["created_at"] = bson.M{"$lte": time.Now().Add(-r.Timeout)}
+	// Orders of a mode without keys can't be checked, so they are left alone
+	if r.gateway(models.Order{PaymentInfo: models.PaymentInfo{Mode: models.PaymentModeTest}}) == nil {
+		filter["payment_info.mode"] = bson.M{"$ne": models.PaymentModeTest}
+	} else if r.gateway(models.Order{}) == nil {
+		filter["payment_info.mode"] = models.PaymentModeTest
+	}
+	cursor, err := r.DB.Collections().Orders.Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("find unpaid orders: %w", err)
+	}
+	var unpaid []models.Order
+	if err := cursor.All(ctx, &unpaid); err != nil {
+		return fmt.Errorf("decode unpaid orders: %w", err)
+	}
+
+	confirmed, cancelled := 0, 0
+	for _, order := range unpaid {
+		check, err := r.Check(ctx, order)
+		if err != nil {
+			// Try again on the next run rather than cancel an order that may be paid
+			log.Printf("[JOBS] payments: failed to check order %s: %v", order.ID.Hex(), err)
+			continue
+		}
+		switch {
+		case check.Paid:
+			confirmed++
+		case !check.InFlight && r.CancelAfter > 0 && time.Since(order.CreatedAt) >= r.CancelAfter:
+			ok, err := r.cancel(ctx, order)
+			if err != nil {
+				return err
+			}
+			if ok {
+				cancelled++
+			}
+		}
+	}
+	if confirmed > 0 || cancelled > 0 {
+		log.Printf("[JOBS] payments: confirmed %d and cancelled %d unpaid orders", confirmed, cancelled)
+	}
+	return nil
+}
+
+// Check asks Razorpay for the payments of an order awaiting payment and
+// confirms the order when one was captured
+func (r *PaymentReconciler) Check(ctx context.Context, order models.Order) (PaymentCheck, error) {
+	client := r.gateway(order)
+	if client == nil {
+		return PaymentCheck{}, fmt.Errorf("no Razorpay keys for %s mode", order.PaymentInfo.Mode)
+	}
+	rzOrderID := order.PaymentInfo.RazorpayOrderID
+	rzOrder, err := client.Order(ctx, rzOrderID)
+	if err != nil {
+		return PaymentCheck{}, err
+	}
+	check := PaymentCheck{GatewayStatus: rzOrder.Status}
+	if rzOrder.Status == "created" {
+		// Nobody has tried to pay yet
+		return check, nil
+	}
+
+	payments, err := client.OrderPayments(ctx, rzOrderID)
+	if err != nil {
+		return check, err
+	}
+	for _, payment := range payments {
+		switch payment.Status {
+		case "captured":
+			if _, err := r.Confirm(ctx, order, payment); err != nil {
+				return check, err
+			}
+			check.Paid, check.InFlight = true, false
+			r.announceCapture(ctx, order, payment)
+			return check, nil
+		case "authorized":
+			check.InFlight = true
+		}
+	}
+	return check, nil
+}
+
+// Confirm marks an order awaiting payment paid by a captured payment and
+// releases it for fulfilment. It reports false when the order had already
+// moved on, e.g. because the webhook confirmed it first.
+func (r *PaymentReconciler) Confirm(ctx context.Context, order models.Order, payment razorpay.Payment) (bool, error) {
+	now := time.Now()
+	filter := awaitingPayment()
+	filter["_id"] = order.ID
+	res, err := r.DB.Collections().Orders.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":                           orderstatus.Processing,
+			"payment_status":                   "paid",
+			"payment_info.razorpay_payment_id": payment.ID,
+			"updated_at":                       now,
+		},
+		"$push": bson.M{"status_history": models.OrderStatusEvent{
+			Status:    orderstatus.Processing,
+			Actor:     models.OrderActorSystem,
+			Note:      "Payment confirmed by Razorpay",
+			Timestamp: now,
+		}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("confirm order %s: %w", order.ID.Hex(), err)
+	}
+	if res.ModifiedCount == 0 {
+		return false, nil
+	}
+
+	r.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()), fmt.Sprintf("orders:%s", order.UserID.Hex()))
+	r.Events.Publish(ctx, events.OrderStatusChanged, events.OrderStatusChange{
+		OrderID: order.ID.Hex(),
+		UserID:  order.UserID.Hex(),
+		From:    order.Status,
+		To:      orderstatus.Processing,
+		Actor:   models.OrderActorSystem,
+		Note:    "Payment confirmed by Razorpay",
+	})
+	return true, nil
+}
+
+// announceCapture publishes a payment the webhook may never have delivered.
+// It shares the webhook's key so each payment is announced once.
+func (r *PaymentReconciler) announceCapture(ctx context.Context, order models.Order, payment razorpay.Payment) {
+	seenKey := fmt.Sprintf("razorpay:captured:%s", payment.ID)
+	var seen bool
+	if err := r.DB.CacheGet(ctx, seenKey, &seen); err == nil && seen {
+		return
+	}
+	r.Events.Publish(ctx, events.PaymentCaptured, events.PaymentCapture{
+		OrderID:           order.ID.Hex(),
+		RazorpayOrderID:   payment.OrderID,
+		RazorpayPaymentID: payment.ID,
+		Amount:            float64(payment.Amount) / 100,
+		Method:            payment.Method,
+		Mode:              orderPaymentMode(order),
+	})
+	r.DB.CacheSet(ctx, seenKey, true, 72*time.Hour)
+}
+
+// cancel cancels an order whose payment never arrived and returns its
+// items to stock
+func (r *PaymentReconciler) cancel(ctx context.Context, order models.Order) (bool, error) {
+	now := time.Now()
+	filter := awaitingPayment()
+	filter["_id"] = order.ID
+	// The filter loses the race to the webhook confirming the order at the
+	// same moment, so a paid order is never cancelled
+	res, err := r.DB.Collections().Orders.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":        orderstatus.Cancelled,
+			"cancel_reason": "payment_not_received",
+			"updated_at":    now,
+		},
+		"$push": bson.M{"status_history": models.OrderStatusEvent{
+			Status:    orderstatus.Cancelled,
+			Actor:     models.OrderActorSystem,
+			Note:      "Payment not received in time",
+			Timestamp: now,
+		}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("cancel order %s: %w", order.ID.Hex(), err)
+	}
+	if res.ModifiedCount == 0 {
+		return false, nil
+	}
+
+	restoreStock(ctx, r.DB, order, "Payment not received")
+	giftcards.Refund(ctx, r.DB, order)
+	r.Events.Publish(ctx, events.OrderStatusChanged, events.OrderStatusChange{
+		OrderID: order.ID.Hex(),
+		UserID:  order.UserID.Hex(),
+		From:    order.Status,
+		To:      orderstatus.Cancelled,
+		Actor:   models.OrderActorSystem,
+		Note:    "Payment not received in time",
+	})
+	r.DB.CacheDel(ctx, fmt.Sprintf("order:%s", order.ID.Hex()), fmt.Sprintf("orders:%s", order.UserID.Hex()))
+	if err := r.emailCancellation(ctx, order); err != nil {
+		log.Printf("[JOBS] payments: failed to email the cancellation of order %s: %v", order.ID.Hex(), err)
+	}
+	return true, nil
+}
+
+// emailCancellation tells the customer their unpaid order was cancelled. The
+// in-app notification comes from the status change event.
+func (r *PaymentReconciler) emailCancellation(ctx context.Context, order models.Order) error {
+	if r.Mailer == nil && r.Queue == nil {
+		return nil
+	}
+	var account models.User
+	opts := options.FindOne().SetProjection(bson.M{"name": 1, "email": 1})
+	if err := r.DB.Collections().Users.FindOne(ctx, bson.M{"_id": order.UserID}, opts).Decode(&account); err != nil {
+		return err
+	}
+	if account.Email == "" {
+		return nil
+	}
+	name := account.Name
+	if name == "" {
+		name = "there"
+	}
+	currency, amount := order.Currency, order.ChargedTotal
+	if currency == "" {
+		currency, amount = models.BaseCurrency, order.Total
+	}
+	msg := mailer.Message{
+		To:      account.Email,
+		Subject: "Your order was cancelled",
+		Body: fmt.Sprintf("Hi %s,\n\nWe didn't receive the payment of %.2f %s for your order #%s within %d minutes, so it has been cancelled and its items released.\n\nIf you still want them, please place the order again.\n",
+			name, amount, currency, order.ID.Hex(), int(r.CancelAfter.Minutes())),
+	}
+	if r.Queue != nil {
+		_, err := r.Queue.Enqueue(ctx, TypeSendEmail, msg)
+		return err
+	}
+	return r.Mailer.Send(ctx, msg)
+}
@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/productviews"
+)
+
+// ProductViewFlusher writes the product views counted in the cache to the
+// database and reranks products by popularity
+type ProductViewFlusher struct {
+	DB         *database.DBClient
+	WindowDays int
+}
+
+// Flush runs one flush
+func (f *ProductViewFlusher) Flush(ctx context.Context) error {
+	return productviews.Flush(ctx, f.DB, f.WindowDays)
+}
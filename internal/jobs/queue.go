@@ -0,0 +1,251 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job is a unit of asynchronous work. Payload is the JSON encoding of
+// whatever the producer passed to Enqueue.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	LastError   string          `json:"lastError,omitempty"`
+	EnqueuedAt  time.Time       `json:"enqueuedAt"`
+	FailedAt    *time.Time      `json:"failedAt,omitempty"`
+}
+
+// Decode unmarshals the job payload into v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// HandlerFunc processes one job. Returning an error schedules a retry with
+// backoff until the job runs out of attempts and is dead-lettered.
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// ErrPermanent marks a failure that retrying cannot fix; wrap it to send a
+// job straight to the dead-letter queue.
+var ErrPermanent = errors.New("permanent job failure")
+
+// Stats reports queue depth
+type Stats struct {
+	Ready     int64 `json:"ready"`
+	Scheduled int64 `json:"scheduled"`
+	Dead      int64 `json:"dead"`
+}
+
+// Options configures a Queue
+type Options struct {
+	MaxAttempts int           // Attempts before a job is dead-lettered
+	BaseBackoff time.Duration // Delay before the first retry; doubles each attempt
+	MaxBackoff  time.Duration
+	JobTimeout  time.Duration // Upper bound on a single handler run
+}
+
+// Queue dispatches jobs to registered handlers through a Broker
+type Queue struct {
+	broker   Broker
+	opts     Options
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+// NewQueue creates a queue on top of broker, filling unset options with defaults
+func NewQueue(broker Broker, opts Options) *Queue {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = 10 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Minute
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = 2 * time.Minute
+	}
+	return &Queue{broker: broker, opts: opts, handlers: make(map[string]HandlerFunc)}
+}
+
+// Backend identifies the broker for logging
+func (q *Queue) Backend() string { return q.broker.Name() }
+
+// Register sets the handler for a job type
+func (q *Queue) Register(jobType string, fn HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = fn
+}
+
+// Enqueue adds a job of the given type for immediate processing
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s payload: %w", jobType, err)
+	}
+	job := &Job{
+		ID:          primitive.NewObjectID().Hex(),
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: q.opts.MaxAttempts,
+		EnqueuedAt:  time.Now(),
+	}
+	if err := q.broker.Push(ctx, job); err != nil {
+		return nil, fmt.Errorf("enqueue %s: %w", jobType, err)
+	}
+	return job, nil
+}
+
+// Stats returns the current queue depth
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	return q.broker.Stats(ctx)
+}
+
+// DeadJobs lists dead-lettered jobs, most recently failed first
+func (q *Queue) DeadJobs(ctx context.Context) ([]*Job, error) {
+	return q.broker.Dead(ctx)
+}
+
+// RetryDead moves a dead-lettered job back onto the queue with a fresh set of attempts
+func (q *Queue) RetryDead(ctx context.Context, id string) (*Job, error) {
+	job, err := q.broker.TakeDead(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.Attempts = 0
+	job.LastError = ""
+	job.FailedAt = nil
+	if err := q.broker.Push(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// DiscardDead permanently removes a dead-lettered job
+func (q *Queue) DiscardDead(ctx context.Context, id string) error {
+	_, err := q.broker.TakeDead(ctx, id)
+	return err
+}
+
+// Run starts n workers plus the scheduler that releases retries once their
+// backoff elapses. It blocks until ctx is cancelled and in-flight jobs finish.
+func (q *Queue) Run(ctx context.Context, n int) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := q.broker.PromoteDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+					log.Printf("[JOBS] promote scheduled jobs: %v", err)
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work pulls jobs until ctx is cancelled
+func (q *Queue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := q.broker.Pop(ctx, 2*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[JOBS] pop: %v", err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if job == nil {
+			continue
+		}
+		q.process(job)
+	}
+}
+
+// process runs one job and routes it to retry or the dead-letter queue on
+// failure. It uses its own context so shutdown doesn't abort a running job.
+func (q *Queue) process(job *Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.opts.JobTimeout)
+	defer cancel()
+
+	q.mu.RLock()
+	fn, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	job.Attempts++
+	var err error
+	if !ok {
+		err = fmt.Errorf("%w: no handler registered for %q", ErrPermanent, job.Type)
+	} else {
+		err = runHandler(ctx, fn, job)
+	}
+	if err == nil {
+		return
+	}
+
+	job.LastError = err.Error()
+	if errors.Is(err, ErrPermanent) || job.Attempts >= job.MaxAttempts {
+		now := time.Now()
+		job.FailedAt = &now
+		log.Printf("[JOBS] %s %s dead-lettered after %d attempt(s): %v", job.Type, job.ID, job.Attempts, err)
+		if err := q.broker.Bury(ctx, job); err != nil {
+			log.Printf("[JOBS] dead-letter %s %s: %v", job.Type, job.ID, err)
+		}
+		return
+	}
+
+	delay := q.backoff(job.Attempts)
+	log.Printf("[JOBS] %s %s failed (attempt %d/%d), retrying in %s: %v", job.Type, job.ID, job.Attempts, job.MaxAttempts, delay, err)
+	if err := q.broker.Schedule(ctx, job, time.Now().Add(delay)); err != nil {
+		log.Printf("[JOBS] schedule retry of %s %s: %v", job.Type, job.ID, err)
+	}
+}
+
+// runHandler calls fn, converting a panic into an error so one bad job
+// can't take down a worker
+func runHandler(ctx context.Context, fn HandlerFunc, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, job)
+}
+
+// backoff returns the exponential delay before retry number attempt
+func (q *Queue) backoff(attempt int) time.Duration {
+	d := q.opts.BaseBackoff
+	for i := 1; i < attempt && d < q.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > q.opts.MaxBackoff {
+		d = q.opts.MaxBackoff
+	}
+	return d
+}
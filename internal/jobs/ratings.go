@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// RatingReconciler recomputes every product's rating from its reviews.
+// Review writes adjust product ratings incrementally; this repairs drift
+// from failed adjustments and from reviews removed along with an account.
+type RatingReconciler struct {
+	DB *database.DBClient
+}
+
+// productRating is the rating stored on a product, or computed from reviews
+type productRating struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Sum   float64            `bson:"rating_sum"`
+	Count int                `bson:"ratings_count"`
+	Avg   float64            `bson:"avg_rating"`
+}
+
+// Reconcile compares each product's stored rating with one $group over its
+// reviews and rewrites the products that differ
+func (r *RatingReconciler) Reconcile(ctx context.Context) error {
+	cursor, err := r.DB.Collections().Reviews.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$product_id",
+			"rating_sum":    bson.M{"$sum": "$rating"},
+			"ratings_count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return fmt.Errorf("aggregate reviews: %w", err)
+	}
+	expected := make(map[primitive.ObjectID]productRating)
+	for cursor.Next(ctx) {
+		var rating productRating
+		if err := cursor.Decode(&rating); err != nil {
+			cursor.Close(ctx)
+			return fmt.Errorf("decode review totals: %w", err)
+		}
+		expected[rating.ID] = rating
+	}
+	cursor.Close(ctx)
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("aggregate reviews: %w", err)
+	}
+
+	products := r.DB.Collections().Products
+	cursor, err = products.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"rating_sum": 1, "ratings_count": 1, "avg_rating": 1}))
+	if err != nil {
+		return fmt.Errorf("find products: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	repaired := 0
+	for cursor.Next(ctx) {
+		var stored productRating
+		if err := cursor.Decode(&stored); err != nil {
+			return fmt.Errorf("decode product: %w", err)
+		}
+		want := expected[stored.ID]
+		avg := 0.0
+		if want.Count > 0 {
+			avg = want.Sum / float64(want.Count)
+		}
+		if stored.Count == want.Count && math.Abs(stored.Sum-want.Sum) < 1e-6 && math.Abs(stored.Avg-avg) < 1e-6 {
+			continue
+		}
+		if _, err := products.UpdateOne(ctx, bson.M{"_id": stored.ID}, bson.M{"$set": bson.M{
+			"rating_sum":    want.Sum,
+			"ratings_count": want.Count,
+			"avg_rating":    avg,
+		}}); err != nil {
+			return fmt.Errorf("update product %s: %w", stored.ID.Hex(), err)
+		}
+		repaired++
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("find products: %w", err)
+	}
+	if repaired > 0 {
+		log.Printf("[JOBS] ratings: repaired the rating of %d products", repaired)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/mailer"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// digestLowStockLimit caps how many low-stock products a digest lists
+const digestLowStockLimit = 20
+
+// Digest summarises the store's activity over a report period. Amounts are
+// in the base currency.
+type Digest struct {
+	Period        string           `json:"period"`
+	From          time.Time        `json:"from"`
+	To            time.Time        `json:"to"`            // Exclusive
+	Orders        int64            `json:"orders"`        // Placed in the period and not cancelled
+	Revenue       float64          `json:"revenue"`       // Total of those orders
+	Cancellations int64            `json:"cancellations"` // Orders cancelled in the period, whenever placed
+	NewReviews    int64            `json:"newReviews"`
+	AverageRating float64          `json:"averageRating"` // Of the new reviews
+	LowStockCount int64            `json:"lowStockCount"`
+	LowStock      []models.Product `json:"lowStock"` // The lowest digestLowStockLimit, name and stock only
+}
+
+// ReportPeriod returns the last complete period before now, in now's time
+// zone: yesterday, or last Monday to Sunday
+func ReportPeriod(period string, now time.Time) (from, to time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if period == models.ReportWeekly {
+		to = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // This Monday
+		return to.AddDate(0, 0, -7), to
+	}
+	return today.AddDate(0, 0, -1), today
+}
+
+// LoadReportSettings returns the digest settings from the store settings
+func LoadReportSettings(ctx context.Context, db *database.DBClient) (models.ReportSettings, error) {
+	var settings models.Settings
+	err := db.MongoDB.Collection("settings").FindOne(ctx, bson.M{}, options.FindOne().SetProjection(bson.M{"reports": 1})).Decode(&settings)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.ReportSettings{}, err
+	}
+	return settings.Reports, nil
+}
+
+// CompileDigest aggregates orders, cancellations and reviews in [from, to)
+// and the products currently low on stock
+func CompileDigest(ctx context.Context, db *database.DBClient, cfg *config.Config, period string, from, to time.Time) (Digest, error) {
+	d := Digest{Period: period, From: from, To: to, LowStock: []models.Product{}}
+	cols := db.Collections()
+	window := bson.M{"$gte": from, "$lt": to}
+	// Orders paid in Razorpay's test mode aren't sales
+	live := bson.M{"$ne": models.PaymentModeTest}
+
+	var sales []struct {
+		Count   int64   `bson:"count"`
+		Revenue float64 `bson:"revenue"`
+	}
+	cursor, err := cols.Orders.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": window, "status": bson.M{"$ne": orderstatus.Cancelled}, "payment_info.mode": live}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "revenue": bson.M{"$sum": "$total"}}}},
+	})
+	if err != nil {
+		return d, fmt.Errorf("aggregate orders: %w", err)
+	}
+	if err := cursor.All(ctx, &sales); err != nil {
+		return d, fmt.Errorf("decode orders: %w", err)
+	}
+	if len(sales) > 0 {
+		d.Orders, d.Revenue = sales[0].Count, sales[0].Revenue
+	}
+
+	// Orders from before status histories were recorded fall back to their
+	// last update, which is when they were cancelled
+	d.Cancellations, err = cols.Orders.CountDocuments(ctx, bson.M{"payment_info.mode": live, "$or": bson.A{
+		bson.M{"status_history": bson.M{"$elemMatch": bson.M{"status": orderstatus.Cancelled, "timestamp": window}}},
+		bson.M{"status": orderstatus.Cancelled, "status_history": bson.M{"$exists": false}, "updated_at": window},
+	}})
+	if err != nil {
+		return d, fmt.Errorf("count cancellations: %w", err)
+	}
+
+	var reviews []struct {
+		Count   int64   `bson:"count"`
+		Average float64 `bson:"average"`
+	}
+	cursor, err = cols.Reviews.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": window}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "average": bson.M{"$avg": "$rating"}}}},
+	})
+	if err != nil {
+		return d, fmt.Errorf("aggregate reviews: %w", err)
+	}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return d, fmt.Errorf("decode reviews: %w", err)
+	}
+	if len(reviews) > 0 {
+		d.NewReviews, d.AverageRating = reviews[0].Count, reviews[0].Average
+	}
+
+	lowStock := bson.M{"$expr": models.LowStockExpr(cfg.LowStockThreshold)}
+	if d.LowStockCount, err = cols.Products.CountDocuments(ctx, lowStock); err != nil {
+		return d, fmt.Errorf("count low-stock products: %w", err)
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"name": 1, "stock": 1, "reorder_threshold": 1}).
+		SetSort(bson.D{{Key: "stock", Value: 1}}).
+		SetLimit(digestLowStockLimit)
+	cursor, err = cols.Products.Find(ctx, lowStock, opts)
+	if err != nil {
+		return d, fmt.Errorf("find low-stock products: %w", err)
+	}
+	if err := cursor.All(ctx, &d.LowStock); err != nil {
+		return d, fmt.Errorf("decode low-stock products: %w", err)
+	}
+	return d, nil
+}
+
+// Email renders the digest as a plain-text email
+func (d Digest) Email(to string) mailer.Message {
+	title := "Daily digest: " + d.From.Format("Mon 2 Jan 2006")
+	if d.Period == models.ReportWeekly {
+		title = fmt.Sprintf("Weekly digest: %s to %s", d.From.Format("2 Jan"), d.To.AddDate(0, 0, -1).Format("2 Jan 2006"))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n", title)
+	fmt.Fprintf(&body, "Orders: %d\n", d.Orders)
+	fmt.Fprintf(&body, "Revenue: ₹%.2f\n", d.Revenue)
+	if d.Orders > 0 {
+		fmt.Fprintf(&body, "Average order value: ₹%.2f\n", d.Revenue/float64(d.Orders))
+	}
+	fmt.Fprintf(&body, "Cancellations: %d\n", d.Cancellations)
+	if d.NewReviews > 0 {
+		fmt.Fprintf(&body, "New reviews: %d (average rating %.1f)\n", d.NewReviews, d.AverageRating)
+	} else {
+		body.WriteString("New reviews: 0\n")
+	}
+	if d.LowStockCount > 0 {
+		fmt.Fprintf(&body, "\nLow stock (%d products):\n", d.LowStockCount)
+		for _, p := range d.LowStock {
+			fmt.Fprintf(&body, "- %s: %d in stock\n", p.Name, p.Stock)
+		}
+		if more := d.LowStockCount - int64(len(d.LowStock)); more > 0 {
+			fmt.Fprintf(&body, "- and %d more\n", more)
+		}
+	}
+	body.WriteString("\nCancelled orders are left out of orders and revenue.\n")
+
+	return mailer.Message{To: to, Subject: title, Body: body.String()}
+}
+
+// QueueDigest emails the digest to each recipient through the job queue
+func QueueDigest(ctx context.Context, queue *Queue, d Digest, recipients []string) error {
+	for _, to := range recipients {
+		if _, err := queue.Enqueue(ctx, TypeSendEmail, d.Email(to)); err != nil {
+			return fmt.Errorf("queue digest to %s: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// ReportScheduler emails the daily and weekly digests enabled in the store
+// settings once their period is over and the report hour has passed. Each
+// digest is sent once: the run is claimed in report_runs before sending, so
+// other instances skip it.
+type ReportScheduler struct {
+	DB     *database.DBClient
+	Config *config.Config
+	Queue  *Queue
+}
+
+// Check sends the digests that are due
+func (s *ReportScheduler) Check(ctx context.Context) error {
+	loc, err := time.LoadLocation(s.Config.ReportTimezone)
+	if err != nil {
+		return fmt.Errorf("load report timezone: %w", err)
+	}
+	settings, err := LoadReportSettings(ctx, s.DB)
+	if err != nil {
+		return fmt.Errorf("load report settings: %w", err)
+	}
+	if len(settings.Recipients) == 0 {
+		return nil
+	}
+
+	now := time.Now().In(loc)
+	enabled := map[string]bool{models.ReportDaily: settings.Daily, models.ReportWeekly: settings.Weekly}
+	for _, period := range []string{models.ReportDaily, models.ReportWeekly} {
+		if !enabled[period] {
+			continue
+		}
+		from, to := ReportPeriod(period, now)
+		if now.Before(to.Add(time.Duration(s.Config.ReportHour) * time.Hour)) {
+			continue
+		}
+
+		runID := period + ":" + from.Format("2006-01-02")
+		_, err := s.DB.Collections().ReportRuns.InsertOne(ctx, bson.M{"_id": runID, "period": period, "from": from, "to": to, "sent_at": now})
+		if mongo.IsDuplicateKeyError(err) {
+			continue // Already sent
+		}
+		if err != nil {
+			return fmt.Errorf("claim %s report: %w", runID, err)
+		}
+
+		digest, err := CompileDigest(ctx, s.DB, s.Config, period, from, to)
+		if err == nil {
+			err = QueueDigest(ctx, s.Queue, digest, settings.Recipients)
+		}
+		if err != nil {
+			// Release the claim so the next check tries again
+			s.DB.Collections().ReportRuns.DeleteOne(ctx, bson.M{"_id": runID})
+			return fmt.Errorf("send %s report: %w", runID, err)
+		}
+		log.Printf("[JOBS] reports: queued the %s digest for %d recipient(s)", runID, len(settings.Recipients))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// RetentionPolicy keeps the documents of a collection for Days after the
+// time in Field; 0 days keeps them forever
+type RetentionPolicy struct {
+	Collection string `json:"collection"`
+	Field      string `json:"field"`
+	Days       int    `json:"days"`
+}
+
+// RetentionPolicies returns the retention cfg sets for each collection that
+// would otherwise grow forever
+func RetentionPolicies(cfg *config.Config) []RetentionPolicy {
+	return []RetentionPolicy{
+		{Collection: "recommendation_feedbacks", Field: "created_at", Days: cfg.RecommendationFeedbackRetentionDays},
+		// Counted from the last change, so carts in use are kept
+		{Collection: "cart_items", Field: "updated_at", Days: cfg.CartItemRetentionDays},
+		{Collection: "notifications", Field: "created_at", Days: cfg.NotificationRetentionDays},
+	}
+}
+
+// Pruner deletes the documents their collection's retention policy no
+// longer keeps. Retention is configurable and every purge is counted, so
+// this runs as a job rather than through TTL indexes.
+type Pruner struct {
+	DB       *database.DBClient
+	Policies []RetentionPolicy
+}
+
+// Prune applies every policy and adds what each purged to its collection's
+// retention stats. A failing policy doesn't stop the others.
+func (p *Pruner) Prune(ctx context.Context) error {
+	var errs []error
+	for _, policy := range p.Policies {
+		if policy.Days <= 0 {
+			continue
+		}
+		now := time.Now()
+		cutoff := now.AddDate(0, 0, -policy.Days)
+		res, err := p.DB.MongoDB.Collection(policy.Collection).DeleteMany(ctx, bson.M{policy.Field: bson.M{"$lt": cutoff}})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prune %s: %w", policy.Collection, err))
+			continue
+		}
+		if res.DeletedCount > 0 {
+			log.Printf("[JOBS] retention: purged %d %s older than %d days", res.DeletedCount, policy.Collection, policy.Days)
+		}
+		_, err = p.DB.Collections().RetentionStats.UpdateOne(ctx,
+			bson.M{"_id": policy.Collection},
+			bson.M{
+				"$set": bson.M{"last_run_at": now, "last_purged": res.DeletedCount},
+				"$inc": bson.M{"total_purged": res.DeletedCount},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %s retention stats: %w", policy.Collection, err))
+		}
+	}
+	return errors.Join(errs...)
+}
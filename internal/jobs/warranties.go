@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// WarrantyIssuer issues the warranties of an order when it is delivered and
+// voids them when it is returned
+type WarrantyIssuer struct {
+	DB *database.DBClient
+}
+
+// Handle is an events.Handler for order status changes
+func (w *WarrantyIssuer) Handle(ctx context.Context, e events.Event) error {
+	change, ok := e.Data.(events.OrderStatusChange)
+	if !ok || e.Type != events.OrderStatusChanged {
+		return nil
+	}
+	orderID, err := primitive.ObjectIDFromHex(change.OrderID)
+	if err != nil {
+		return fmt.Errorf("order ID: %w", err)
+	}
+	switch change.To {
+	case orderstatus.Delivered:
+		return w.issue(ctx, orderID)
+	case orderstatus.Returned:
+		now := time.Now()
+		_, err := w.DB.Collections().Warranties.UpdateMany(ctx,
+			bson.M{"order_id": orderID, "voided_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"voided_at": now, "updated_at": now}},
+		)
+		return err
+	}
+	return nil
+}
+
+// issue adds a warranty for every unit of the order's lines whose product
+// has warranty months, running from the delivery date. Units that already
+// have one keep it.
+func (w *WarrantyIssuer) issue(ctx context.Context, orderID primitive.ObjectID) error {
+	var order models.Order
+	if err := w.DB.Collections().Orders.FindOne(ctx, bson.M{"_id": orderID}).Decode(&order); err != nil {
+		return fmt.Errorf("load order %s: %w", orderID.Hex(), err)
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(order.Items))
+	for _, item := range order.Items {
+		ids = append(ids, item.ProductID)
+	}
+	cursor, err := w.DB.Collections().Products.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "warranty_months": bson.M{"$gt": 0}},
+		options.Find().SetProjection(bson.M{"warranty_months": 1}))
+	if err != nil {
+		return fmt.Errorf("load products: %w", err)
+	}
+	var products []models.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return fmt.Errorf("load products: %w", err)
+	}
+	months := make(map[primitive.ObjectID]int, len(products))
+	for _, p := range products {
+		months[p.ID] = p.WarrantyMonths
+	}
+
+	now := time.Now()
+	start := now
+	if order.DeliveredAt != nil {
+		start = *order.DeliveredAt
+	}
+	var writes []mongo.WriteModel
+	for line, item := range order.Items {
+		m := months[item.ProductID]
+		if m <= 0 {
+			continue
+		}
+		for unit := 1; unit <= item.Quantity; unit++ {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"order_id": order.ID, "line": line, "unit": unit}).
+				SetUpdate(bson.M{"$setOnInsert": models.Warranty{
+					ID:          primitive.NewObjectID(),
+					UserID:      order.UserID,
+					OrderID:     order.ID,
+					Line:        line,
+					Unit:        unit,
+					ProductID:   item.ProductID,
+					ProductName: item.ProductName,
+					Months:      m,
+					StartsAt:    start,
+					ExpiresAt:   start.AddDate(0, m, 0),
+					CreatedAt:   now,
+					UpdatedAt:   now,
+				}}).
+				SetUpsert(true))
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err = w.DB.Collections().Warranties.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// TypeDeliverWebhook posts one event to one webhook endpoint, retrying with
+// the queue's backoff until the endpoint accepts it
+const TypeDeliverWebhook = "webhook.deliver"
+
+// webhookTimeout bounds a single delivery attempt
+const webhookTimeout = 10 * time.Second
+
+// webhookDelivery is the payload of a TypeDeliverWebhook job
+type webhookDelivery struct {
+	EndpointID primitive.ObjectID `json:"endpointId"`
+	Event      events.Event       `json:"event"`
+}
+
+// WebhookDispatcher delivers events to the webhook endpoints admins register
+type WebhookDispatcher struct {
+	DB     *database.DBClient
+	Queue  *Queue
+	Client *http.Client // Optional; a client with webhookTimeout is used when nil
+}
+
+// Dispatch is an events.Handler: it queues a delivery for every active
+// endpoint subscribed to the event
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, e events.Event) error {
+	// Only the types endpoints can subscribe to, even for "*"
+	if !slices.Contains(events.Types, e.Type) {
+		return nil
+	}
+	cursor, err := d.DB.Collections().WebhookEndpoints.Find(ctx, bson.M{
+		"active": true,
+		"events": bson.M{"$in": bson.A{e.Type, "*"}},
+	})
+	if err != nil {
+		return fmt.Errorf("find webhook endpoints: %w", err)
+	}
+	var endpoints []models.WebhookEndpoint
+	if err := cursor.All(ctx, &endpoints); err != nil {
+		return fmt.Errorf("decode webhook endpoints: %w", err)
+	}
+
+	for _, endpoint := range endpoints {
+		if err := d.DispatchTo(ctx, endpoint.ID, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchTo queues delivery of an event to one endpoint, whatever it is
+// subscribed to
+func (d *WebhookDispatcher) DispatchTo(ctx context.Context, endpointID primitive.ObjectID, e events.Event) error {
+	if _, err := d.Queue.Enqueue(ctx, TypeDeliverWebhook, webhookDelivery{EndpointID: endpointID, Event: e}); err != nil {
+		return fmt.Errorf("queue delivery to %s: %w", endpointID.Hex(), err)
+	}
+	return nil
+}
+
+// Deliver is the handler for TypeDeliverWebhook jobs. Deliveries are signed
+// with the endpoint's secret (see events.Sign). Client errors other than
+// timeouts and rate limits are not retried.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, job *Job) error {
+	var delivery webhookDelivery
+	if err := job.Decode(&delivery); err != nil {
+		return fmt.Errorf("%w: decode delivery: %v", ErrPermanent, err)
+	}
+
+	endpoints := d.DB.Collections().WebhookEndpoints
+	var endpoint models.WebhookEndpoint
+	if err := endpoints.FindOne(ctx, bson.M{"_id": delivery.EndpointID}).Decode(&endpoint); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil // Deleted since the event was queued
+		}
+		return fmt.Errorf("find endpoint: %w", err)
+	}
+	if !endpoint.Active && delivery.Event.Type != events.Ping {
+		return nil
+	}
+
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return fmt.Errorf("%w: encode event: %v", ErrPermanent, err)
+	}
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: build request: %v", ErrPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MakWatches-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.Event.ID)
+	req.Header.Set("X-Webhook-Event", delivery.Event.Type)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", "sha256="+events.Sign(endpoint.Secret, timestamp, body))
+
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		d.recordOutcome(ctx, endpoint.ID, 0, err.Error())
+		return fmt.Errorf("post to %s: %w", endpoint.URL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		d.recordOutcome(ctx, endpoint.ID, resp.StatusCode, "")
+		return nil
+	}
+	failure := fmt.Errorf("%s responded %d", endpoint.URL, resp.StatusCode)
+	d.recordOutcome(ctx, endpoint.ID, resp.StatusCode, failure.Error())
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", ErrPermanent, failure)
+	}
+	return failure
+}
+
+// recordOutcome stores the result of the latest attempt for the admin list
+func (d *WebhookDispatcher) recordOutcome(ctx context.Context, endpointID primitive.ObjectID, statusCode int, lastError string) {
+	set := bson.M{"last_delivery_at": time.Now(), "last_status_code": statusCode, "last_error": lastError}
+	_, _ = d.DB.Collections().WebhookEndpoints.UpdateOne(ctx, bson.M{"_id": endpointID}, bson.M{"$set": set})
+}
@@ -0,0 +1,339 @@
+// Package jwtkeys signs and verifies the API's JWTs with a set of keys. One
+// key signs; tokens are verified by the key named in their "kid" header, so
+// a key can be rotated without logging everyone out: the new key signs
+// while the previous one still verifies the tokens it issued until they
+// expire.
+//
+// Keys are HMAC secrets (HS256) or RSA (RS256) and Ed25519 (EdDSA) key
+// pairs. The public halves are published as a JWKS so other services can
+// verify tokens without sharing a secret.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Options configures the key set built by Load
+type Options struct {
+	// Algorithm signs new tokens: HS256 with Secret, or RS256 or EdDSA with
+	// PrivateKey, a PEM block or the path of a PEM file
+	Algorithm  string
+	Secret     string
+	PrivateKey string
+	KeyID      string // Defaults to a thumbprint of the key
+
+	// A key retired by a rotation, still verifying the tokens it signed.
+	// PreviousSecret is an HS256 secret and PreviousPublicKey an RSA or
+	// Ed25519 public key (PEM or path); set at most one.
+	PreviousSecret    string
+	PreviousPublicKey string
+	PreviousKeyID     string
+}
+
+// key is one signing or verification key
+type key struct {
+	id     string
+	method jwt.SigningMethod
+	sign   interface{} // nil for keys that only verify
+	verify interface{}
+}
+
+// Set is the API's JWT keys
+type Set struct {
+	active *key
+	byID   map[string]*key
+	// Tokens issued before key IDs were added to headers carry none; they
+	// can only have been signed with an HMAC secret
+	legacy []*key
+}
+
+// Load builds the key set described by opts
+func Load(opts Options) (*Set, error) {
+	active, err := signingKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Set{active: active, byID: map[string]*key{active.id: active}}
+	if active.method == jwt.SigningMethodHS256 {
+		s.legacy = append(s.legacy, active)
+	}
+
+	previous, err := previousKey(opts)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		if _, ok := s.byID[previous.id]; ok {
+			return nil, fmt.Errorf("previous JWT key has the same ID as the signing key (%s)", previous.id)
+		}
+		s.byID[previous.id] = previous
+		if previous.method == jwt.SigningMethodHS256 {
+			s.legacy = append(s.legacy, previous)
+		}
+	}
+	return s, nil
+}
+
+// signingKey builds the active key
+func signingKey(opts Options) (*key, error) {
+	switch opts.Algorithm {
+	case "", HS256:
+		if opts.Secret == "" {
+			return nil, errors.New("JWT_SECRET is required to sign with HS256")
+		}
+		return hmacKey(opts.Secret, opts.KeyID), nil
+	case RS256, EdDSA:
+		if opts.PrivateKey == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY is required to sign with %s", opts.Algorithm)
+		}
+		block, err := readPEM(opts.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		k, err := asymmetricKey(private.(crypto.Signer).Public(), opts.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY: %w", err)
+		}
+		if k.method.Alg() != opts.Algorithm {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY is a %s key but JWT_ALGORITHM is %s", k.method.Alg(), opts.Algorithm)
+		}
+		k.sign = private
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q; use HS256, RS256 or EdDSA", opts.Algorithm)
+	}
+}
+
+// previousKey builds the retired key, if any
+func previousKey(opts Options) (*key, error) {
+	switch {
+	case opts.PreviousSecret != "" && opts.PreviousPublicKey != "":
+		return nil, errors.New("set only one of JWT_PREVIOUS_SECRET and JWT_PREVIOUS_PUBLIC_KEY")
+	case opts.PreviousSecret != "":
+		return hmacKey(opts.PreviousSecret, opts.PreviousKeyID), nil
+	case opts.PreviousPublicKey != "":
+		block, err := readPEM(opts.PreviousPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_PUBLIC_KEY: %w", err)
+		}
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_PUBLIC_KEY: %w", err)
+		}
+		k, err := asymmetricKey(public, opts.PreviousKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_PUBLIC_KEY: %w", err)
+		}
+		return k, nil
+	}
+	return nil, nil
+}
+
+func hmacKey(secret, id string) *key {
+	if id == "" {
+		// A thumbprint of the secret itself would help guess it, so the
+		// default ID only tells secrets apart
+		sum := sha256.Sum256([]byte("kid:" + secret))
+		id = "hs-" + base64.RawURLEncoding.EncodeToString(sum[:6])
+	}
+	return &key{id: id, method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}
+}
+
+func asymmetricKey(public crypto.PublicKey, id string) (*key, error) {
+	var method jwt.SigningMethod
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use RSA or Ed25519", public)
+	}
+	k := &key{id: id, method: method, verify: public}
+	if k.id == "" {
+		k.id = thumbprint(k.jwk())
+	}
+	return k, nil
+}
+
+// readPEM decodes value as a PEM block, or as the path of a file holding one
+func readPEM(value string) (*pem.Block, error) {
+	data := []byte(value)
+	if !strings.Contains(value, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, err
+		}
+	}
+	// Environment variables often carry the PEM with escaped newlines
+	data = []byte(strings.ReplaceAll(string(data), `\n`, "\n"))
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if rsaKey, ok := private.(*rsa.PrivateKey); ok {
+		return rsaKey, nil
+	}
+	if edKey, ok := private.(ed25519.PrivateKey); ok {
+		return edKey, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T; use RSA or Ed25519", private)
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (s *Set) Algorithm() string {
+	return s.active.method.Alg()
+}
+
+// Sign issues a token with the claims, signed with the active key
+func (s *Set) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.id
+	return token.SignedString(s.active.sign)
+}
+
+// Parse verifies a token's signature and expiry with the key its header
+// names and returns its claims
+func (s *Set) Parse(raw string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, s.keyFunc, jwt.WithExpirationRequired())
+	if err == nil {
+		return claims, nil
+	}
+
+	// Without a key ID, try each HMAC secret in turn
+	if errors.Is(err, errNoKeyID) {
+		for _, k := range s.legacy {
+			claims = jwt.MapClaims{}
+			if _, err = jwt.ParseWithClaims(raw, claims, k.verifyWith, jwt.WithExpirationRequired()); err == nil {
+				return claims, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+var errNoKeyID = errors.New("token has no key ID")
+
+func (s *Set) keyFunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	if id == "" {
+		return nil, errNoKeyID
+	}
+	k, ok := s.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", id)
+	}
+	return k.verifyWith(token)
+}
+
+// verifyWith returns the key to verify a token with, refusing tokens whose
+// algorithm differs from the key's so a public key is never used as an
+// HMAC secret
+func (k *key) verifyWith(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return k.verify, nil
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that verify tokens: the signing key first,
+// then the previous one. HMAC secrets are never published, so with HS256 it
+// is empty.
+func (s *Set) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if jwk := s.active.jwk(); jwk != nil {
+		set.Keys = append(set.Keys, *jwk)
+	}
+	for id, k := range s.byID {
+		if id == s.active.id {
+			continue
+		}
+		if jwk := k.jwk(); jwk != nil {
+			set.Keys = append(set.Keys, *jwk)
+		}
+	}
+	return set
+}
+
+// jwk returns the public key as a JWK, or nil for HMAC keys
+func (k *key) jwk() *JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.verify.(type) {
+	case *rsa.PublicKey:
+		return &JWK{KeyType: "RSA", KeyID: k.id, Use: "sig", Algorithm: RS256, N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes())}
+	case ed25519.PublicKey:
+		return &JWK{KeyType: "OKP", KeyID: k.id, Use: "sig", Algorithm: EdDSA, Curve: "Ed25519", X: b64(pub)}
+	}
+	return nil
+}
+
+// thumbprint is the RFC 7638 thumbprint of a JWK, used as the default key ID
+func thumbprint(jwk *JWK) string {
+	var canonical string
+	switch jwk.KeyType {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Curve, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+// Package mailer sends transactional email. SMTP is used when configured;
+// otherwise messages are written to the log so local development works
+// without a mail server.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is a single plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// ReplyTo, when set, is where replies go instead of the sender
+	ReplyTo string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+	// Name identifies the backend for logging
+	Name() string
+}
+
+// Options configures the sender selected by New
+type Options struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// New returns an SMTP sender when a host is configured, otherwise a log sender
+func New(opts Options) Sender {
+	if opts.SMTPHost == "" {
+		return LogSender{}
+	}
+	if opts.SMTPPort == 0 {
+		opts.SMTPPort = 587
+	}
+	return &SMTPSender{opts: opts}
+}
+
+// SMTPSender delivers mail through an SMTP relay using STARTTLS when offered
+type SMTPSender struct {
+	opts Options
+}
+
+// Send implements Sender
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(s.opts.SMTPHost, fmt.Sprint(s.opts.SMTPPort))
+
+	var auth smtp.Auth
+	if s.opts.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.opts.SMTPUsername, s.opts.SMTPPassword, s.opts.SMTPHost)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.opts.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	if msg.ReplyTo != "" {
+		fmt.Fprintf(&b, "Reply-To: %s\r\n", msg.ReplyTo)
+	}
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	// net/smtp has no context support; run it in the background and honour cancellation
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.opts.From, []string{msg.To}, []byte(b.String()))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Name implements Sender
+func (s *SMTPSender) Name() string { return "smtp" }
+
+// LogSender writes messages to the server log instead of sending them.
+// Only suitable for development.
+type LogSender struct{}
+
+// Send implements Sender
+func (LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("[MAIL] To: %s | Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// Name implements Sender
+func (LogSender) Name() string { return "log" }
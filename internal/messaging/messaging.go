@@ -0,0 +1,189 @@
+// Package messaging sends order updates to customers' phones over WhatsApp
+// (the WhatsApp Business Cloud API) and SMS (MSG91 Flow). Both only deliver
+// pre-approved templates, so a message is a template kind and its variables
+// rather than free text. Channels without credentials log their messages.
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Channels a customer can opt in to
+const (
+	ChannelWhatsApp = "whatsapp"
+	ChannelSMS      = "sms"
+)
+
+// Message kinds. On WhatsApp each is also the name of the approved template.
+const (
+	OrderConfirmed = "order_confirmed"
+	OrderShipped   = "order_shipped"
+	OrderDelivered = "order_delivered"
+)
+
+// Message is one templated message to an E.164 phone number
+type Message struct {
+	To     string   `json:"to"`
+	Kind   string   `json:"kind"`
+	Params []string `json:"params"` // Template variables in order: customer name, order number
+}
+
+// Sender delivers messages over one channel
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+	// Name identifies the provider for logging
+	Name() string
+}
+
+// Options configures the senders created by New
+type Options struct {
+	WhatsAppPhoneNumberID string
+	WhatsAppAccessToken   string
+	WhatsAppLanguage      string // Template language code, e.g. "en"
+
+	MSG91AuthKey   string
+	MSG91Templates map[string]string // DLT template ID per message kind
+}
+
+// New returns the sender for a channel, falling back to the log sender when
+// the channel's credentials are missing
+func New(channel string, opts Options) Sender {
+	switch channel {
+	case ChannelWhatsApp:
+		if opts.WhatsAppPhoneNumberID != "" && opts.WhatsAppAccessToken != "" {
+			return NewWhatsApp(opts.WhatsAppPhoneNumberID, opts.WhatsAppAccessToken, opts.WhatsAppLanguage)
+		}
+		log.Println("[MESSAGING] WhatsApp order updates enabled but WHATSAPP_PHONE_NUMBER_ID/WHATSAPP_ACCESS_TOKEN are not set; logging messages instead")
+	case ChannelSMS:
+		if opts.MSG91AuthKey != "" {
+			return NewMSG91(opts.MSG91AuthKey, opts.MSG91Templates)
+		}
+		log.Println("[MESSAGING] SMS order updates enabled but MSG91_AUTH_KEY is not set; logging messages instead")
+	}
+	return LogSender{Channel: channel}
+}
+
+// httpClient is shared by the HTTP-based senders
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// post sends a JSON body and turns a non-2xx response into an error
+func post(ctx context.Context, provider, endpoint string, header http.Header, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: unexpected status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
+
+// WhatsApp sends template messages through the WhatsApp Business Cloud API
+type WhatsApp struct {
+	phoneNumberID string
+	accessToken   string
+	language      string
+}
+
+// NewWhatsApp creates a WhatsApp sender for the business phone number
+func NewWhatsApp(phoneNumberID, accessToken, language string) *WhatsApp {
+	if language == "" {
+		language = "en"
+	}
+	return &WhatsApp{phoneNumberID: phoneNumberID, accessToken: accessToken, language: language}
+}
+
+// Send implements Sender
+func (w *WhatsApp) Send(ctx context.Context, msg Message) error {
+	params := make([]map[string]string, len(msg.Params))
+	for i, p := range msg.Params {
+		params[i] = map[string]string{"type": "text", "text": p}
+	}
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(msg.To, "+"),
+		"type":              "template",
+		"template": map[string]interface{}{
+			"name":       msg.Kind,
+			"language":   map[string]string{"code": w.language},
+			"components": []map[string]interface{}{{"type": "body", "parameters": params}},
+		},
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+w.accessToken)
+	return post(ctx, "whatsapp", "https://graph.facebook.com/v19.0/"+w.phoneNumberID+"/messages", header, payload)
+}
+
+// Name implements Sender
+func (w *WhatsApp) Name() string { return "whatsapp" }
+
+// MSG91 sends SMS through MSG91's Flow API using DLT-approved templates
+type MSG91 struct {
+	authKey   string
+	templates map[string]string
+}
+
+// NewMSG91 creates an MSG91 SMS sender
+func NewMSG91(authKey string, templates map[string]string) *MSG91 {
+	return &MSG91{authKey: authKey, templates: templates}
+}
+
+// Send implements Sender. Kinds without a template ID are skipped.
+func (m *MSG91) Send(ctx context.Context, msg Message) error {
+	templateID := m.templates[msg.Kind]
+	if templateID == "" {
+		log.Printf("[MESSAGING] No MSG91 template for %s; skipping SMS to %s", msg.Kind, msg.To)
+		return nil
+	}
+	recipient := map[string]string{"mobiles": strings.TrimPrefix(msg.To, "+")} // MSG91 expects the country code without "+"
+	for i, p := range msg.Params {
+		recipient["var"+strconv.Itoa(i+1)] = p
+	}
+	payload := map[string]interface{}{
+		"template_id": templateID,
+		"recipients":  []map[string]string{recipient},
+	}
+	header := http.Header{}
+	header.Set("authkey", m.authKey)
+	return post(ctx, "msg91", "https://control.msg91.com/api/v5/flow/", header, payload)
+}
+
+// Name implements Sender
+func (m *MSG91) Name() string { return "msg91" }
+
+// LogSender writes messages to the server log instead of sending them.
+// Only suitable for development.
+type LogSender struct {
+	Channel string
+}
+
+// Send implements Sender
+func (l LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("[MESSAGING] %s %s to %s: %s", l.Channel, msg.Kind, msg.To, strings.Join(msg.Params, ", "))
+	return nil
+}
+
+// Name implements Sender
+func (LogSender) Name() string { return "log" }
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// APIKeyHeader carries the key of machine-to-machine requests
+const APIKeyHeader = "X-API-Key"
+
+// HashAPIKey returns the digest a key is stored and looked up by
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyCacheKey returns the cache key holding the key with a hash. Handlers
+// revoking a key must delete it.
+func APIKeyCacheKey(hash string) string {
+	return "api_key:" + hash
+}
+
+// APIKeyAuth authenticates integrations by their X-API-Key instead of a user
+// token. The key must carry scope and its owner must still be active staff
+// holding the scope's permission; the request then runs as the owner, with
+// TokenMetadata.APIKeyID set. Keys only read, so other methods are refused.
+func APIKeyAuth(db *database.DBClient, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Get(APIKeyHeader)
+		if raw == "" {
+			return apperrors.Unauthorized("X-API-Key header is required")
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return apperrors.Forbidden("API keys can only read")
+		}
+
+		ctx := c.Context()
+		hash := HashAPIKey(raw)
+		var key models.APIKey
+		if err := db.CacheGet(ctx, APIKeyCacheKey(hash), &key); err != nil {
+			err := db.Collections().APIKeys.FindOne(ctx, bson.M{"hash": hash}).Decode(&key)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return apperrors.Unauthorized("Invalid API key")
+			}
+			if err != nil {
+				return apperrors.Internal("Failed to verify API key", err)
+			}
+			db.CacheSet(ctx, APIKeyCacheKey(hash), key, time.Minute)
+		}
+		if key.RevokedAt != nil {
+			return apperrors.Unauthorized("API key has been revoked")
+		}
+		if !key.HasScope(scope) {
+			return apperrors.Forbidden(fmt.Sprintf("API key lacks the %s scope", scope))
+		}
+
+		owner, err := loadAccountState(ctx, db, key.OwnerID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return apperrors.Internal("Failed to verify API key", err)
+		}
+		if err != nil || !IsStaff(owner.Role) || owner.Status == models.UserStatusSuspended || owner.Status == models.UserStatusDeleted {
+			return apperrors.Forbidden("API key owner no longer has access")
+		}
+		if permission, ok := models.APIScopePermissions[scope]; ok {
+			allowed, err := HasPermission(ctx, db, owner.Role, permission)
+			if err != nil {
+				return apperrors.Internal("Failed to verify API key", err)
+			}
+			if !allowed {
+				return apperrors.Forbidden("API key owner no longer has access")
+			}
+		}
+
+		touchAPIKey(ctx, db, key, c.IP())
+		c.Locals("user", &TokenMetadata{UserID: key.OwnerID, Role: owner.Role, APIKeyID: key.ID})
+		return c.Next()
+	}
+}
+
+// touchAPIKey records when and from where a key was last used, at most once
+// a minute
+func touchAPIKey(ctx context.Context, db *database.DBClient, key models.APIKey, ip string) {
+	now := time.Now()
+	_, err := db.Collections().APIKeys.UpdateOne(ctx,
+		bson.M{"_id": key.ID, "$or": bson.A{
+			bson.M{"last_used_at": bson.M{"$exists": false}},
+			bson.M{"last_used_at": bson.M{"$lt": now.Add(-time.Minute)}},
+		}},
+		bson.M{"$set": bson.M{"last_used_at": now, "last_used_ip": ip}},
+	)
+	if err != nil {
+		log.Printf("[AUTH] Failed to record use of API key %s: %v", key.ID.Hex(), err)
+	}
+}
@@ -1,13 +1,21 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
 )
 
 // TokenMetadata contains user metadata from the JWT token
@@ -15,98 +23,91 @@ type TokenMetadata struct {
 	UserID primitive.ObjectID
 	Role   string
 	Exp    time.Time
+	// ImpersonatorID is the admin acting as the user, zero for the user's own sessions
+	ImpersonatorID primitive.ObjectID
+	// APIKeyID is the key that authenticated an integration request, zero for user tokens
+	APIKeyID primitive.ObjectID
+}
+
+// accountState is the subset of the user document re-checked on every authenticated request
+type accountState struct {
+	Role         string `json:"role" bson:"role"`
+	Status       string `json:"status" bson:"status"`
+	TokenVersion int    `json:"tokenVersion" bson:"token_version"`
+}
+
+// AccountStateCacheKey returns the cache key holding a user's role/status/token version.
+// Handlers that change any of those fields must delete this key.
+func AccountStateCacheKey(userID string) string {
+	return fmt.Sprintf("account_state:%s", userID)
+}
+
+// loadAccountState fetches the account state from cache, falling back to MongoDB
+func loadAccountState(ctx context.Context, db *database.DBClient, userID primitive.ObjectID) (*accountState, error) {
+	cacheKey := AccountStateCacheKey(userID.Hex())
+	var state accountState
+	if err := db.CacheGet(ctx, cacheKey, &state); err == nil {
+		return &state, nil
+	}
+
+	opts := options.FindOne().SetProjection(bson.M{"role": 1, "status": 1, "token_version": 1})
+	if err := db.Collections().Users.FindOne(ctx, bson.M{"_id": userID}, opts).Decode(&state); err != nil {
+		return nil, err
+	}
+
+	// Keep this short so suspensions propagate even if an invalidation is missed
+	db.CacheSet(ctx, cacheKey, state, time.Minute)
+	return &state, nil
 }
 
-// Auth middleware for protecting routes
-func Auth(jwtSecret string) fiber.Handler {
+// Auth middleware for protecting routes.
+// Tokens are verified with the key their header names; see jwtkeys.
+// When db is non-nil the account is re-checked so suspended users and revoked
+// sessions are rejected even while their tokens have not yet expired.
+func Auth(keys *jwtkeys.Set, db *database.DBClient) fiber.Handler {
     return func(c *fiber.Ctx) error {
         tokenHeader := c.Get("Authorization")
         if tokenHeader == "" {
             // Log the request details for debugging
             fmt.Printf("[AUTH] Missing Authorization header - Method: %s, Path: %s, IP: %s\n", 
                 c.Method(), c.Path(), c.IP())
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Authorization header is required",
-            })
+            return apperrors.Unauthorized("Authorization header is required")
         }
 
         // Check if the header format is correct
         parts := strings.Split(tokenHeader, " ")
         if len(parts) != 2 || parts[0] != "Bearer" {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Authorization header format must be Bearer {token}",
-            })
+            return apperrors.Unauthorized("Authorization header format must be Bearer {token}")
         }
 
         tokenString := parts[1]
 
-        // Parse the JWT token
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-            // Validate signing method
-            if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-                return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-            }
-            return []byte(jwtSecret), nil
-        })
-
+        // Parse and verify the JWT token
+        claims, err := keys.Parse(tokenString)
         if err != nil {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Invalid or expired token",
-            })
-        }
-
-        // Validate token
-        if !token.Valid {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Invalid token",
-            })
-        }
-
-        // Extract claims
-        claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Failed to extract claims from token",
-            })
+            return apperrors.Unauthorized("Invalid or expired token")
         }
 
         // Verify expiration
         expFloat, ok := claims["exp"].(float64)
         if !ok {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Invalid token expiration",
-            })
+            return apperrors.Unauthorized("Invalid token expiration")
         }
 
         expTime := time.Unix(int64(expFloat), 0)
         if time.Now().After(expTime) {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Token has expired",
-            })
+            return apperrors.Unauthorized("Token has expired")
         }
 
         // Extract user ID
         userIDStr, ok := claims["userId"].(string)
         if !ok {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Invalid user ID in token",
-            })
+            return apperrors.Unauthorized("Invalid user ID in token")
         }
 
         userID, err := primitive.ObjectIDFromHex(userIDStr)
         if err != nil {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Invalid user ID format",
-            })
+            return apperrors.Unauthorized("Invalid user ID format")
         }
 
         // Extract role
@@ -115,11 +116,46 @@ func Auth(jwtSecret string) fiber.Handler {
             role = "user" // Default role
         }
 
+        if db != nil {
+            state, err := loadAccountState(c.Context(), db, userID)
+            if err != nil {
+                if err == mongo.ErrNoDocuments {
+                    return apperrors.Unauthorized("Account no longer exists")
+                }
+                return apperrors.Internal("Failed to verify account", err)
+            }
+
+            if state.Status == models.UserStatusDeleted {
+                return apperrors.Unauthorized("Account no longer exists")
+            }
+            if state.Status == models.UserStatusSuspended {
+                return apperrors.Forbidden("Account has been suspended").WithCode(apperrors.CodeAccountSuspended)
+            }
+
+            // Tokens issued before the last session revocation carry an older version
+            tokenVersion, _ := claims["tv"].(float64)
+            if int(tokenVersion) != state.TokenVersion {
+                return apperrors.Unauthorized("Session has been revoked, please log in again").WithCode(apperrors.CodeSessionRevoked)
+            }
+
+            // Role changes take effect immediately rather than at next login
+            if state.Role != "" {
+                role = state.Role
+            }
+        }
+
+        // Admins viewing the account as its owner may only read
+        impersonatorID, err := checkImpersonation(c, db, claims, userID)
+        if err != nil {
+            return err
+        }
+
         // Set user metadata in context
         c.Locals("user", &TokenMetadata{
-            UserID: userID,
-            Role:   role,
-            Exp:    expTime,
+            UserID:         userID,
+            Role:           role,
+            Exp:            expTime,
+            ImpersonatorID: impersonatorID,
         })
 
         // Log successful authentication
@@ -136,10 +172,7 @@ func Role(roles ...string) fiber.Handler {
         // Get user metadata from context
         user, ok := c.Locals("user").(*TokenMetadata)
         if !ok {
-            return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-                "success": false,
-                "message": "Unauthorized - User data not found",
-            })
+            return apperrors.Unauthorized("Unauthorized - User data not found")
         }
 
         // Check if user role is allowed
@@ -152,10 +185,7 @@ func Role(roles ...string) fiber.Handler {
         }
 
         if !allowed {
-            return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-                "success": false,
-                "message": "Access forbidden - Insufficient permissions",
-            })
+            return apperrors.Forbidden("Access forbidden - Insufficient permissions")
         }
 
         return c.Next()
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+)
+
+// BodyLimits caps request bodies for every route: multipart/form-data
+// uploads at uploadLimit bytes and everything else (JSON) at limit bytes.
+// Routes that accept files tighten the upload cap with BodyLimit.
+//
+// The server streams bodies larger than its in-memory BodyLimit instead of
+// refusing them, so this is where oversize requests are turned away.
+func BodyLimits(limit, uploadLimit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		capacity := limit
+		if len(c.Request().Header.MultipartFormBoundary()) > 0 {
+			capacity = uploadLimit
+		}
+		if err := checkBodySize(c, capacity); err != nil {
+			return err
+		}
+		// A streamed body may be left partly unread (e.g. when auth fails),
+		// which would corrupt the next request on a reused connection
+		if c.Request().Header.ContentLength() > c.App().Config().BodyLimit {
+			c.Context().SetConnectionClose()
+		}
+		return c.Next()
+	}
+}
+
+// BodyLimit caps a route's request body at limit bytes
+func BodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := checkBodySize(c, limit); err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// checkBodySize returns a 413 error when the body is over limit. Bodies of
+// unknown length (chunked) are read into memory up to the limit to find out.
+func checkBodySize(c *fiber.Ctx, limit int) error {
+	req := c.Request()
+	n := req.Header.ContentLength()
+	if n < 0 {
+		if req.IsBodyStream() {
+			data, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+			if err != nil {
+				return apperrors.BadRequest("Failed to read request body", nil)
+			}
+			req.SetBody(data)
+		}
+		n = len(req.Body())
+	}
+	if n > limit {
+		// The rest of the body is left unread, so the connection can't be reused
+		c.Context().SetConnectionClose()
+		return bodyTooLarge(limit)
+	}
+	return nil
+}
+
+// bodyTooLarge is the 413 returned for a body or file over limit bytes
+func bodyTooLarge(limit int) *apperrors.Error {
+	return apperrors.PayloadTooLarge("Request body too large", "The limit for this request is "+formatSize(limit))
+}
+
+// formatSize renders a byte count as MB or KB for error messages
+func formatSize(n int) string {
+	if n >= 1024*1024 && n%(1024*1024) == 0 {
+		return fmt.Sprintf("%d MB", n/(1024*1024))
+	}
+	if n >= 1024*1024 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+	return fmt.Sprintf("%d KB", (n+1023)/1024)
+}
@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
 )
 
 // CookieConfig defines the config for cookie-based authentication middleware
@@ -71,38 +73,18 @@ func ClearTokenCookie(c *fiber.Ctx, name string, config CookieConfig) {
 }
 
 // CookieAuth is middleware that checks for a valid JWT token in cookies
-func CookieAuth(secret string, cookieName string) fiber.Handler {
+func CookieAuth(keys *jwtkeys.Set, cookieName string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the token from cookie
 		tokenString := c.Cookies(cookieName)
 		if tokenString == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Authentication required",
-			})
+			return apperrors.Unauthorized("Authentication required")
 		}
 
 		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the alg
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid or expired token",
-				"error":   err.Error(),
-			})
-		}
-
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid token claims",
-			})
+		claims, err := keys.Parse(tokenString)
+		if err != nil {
+			return apperrors.Unauthorized("Invalid or expired token")
 		}
 
 		// Store user info in context
@@ -1,37 +1,67 @@
 package middleware
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"log"
+	"strings"
 
-// CORSConfig holds allowed origins for future extensibility
-var AllowedOrigins = []string{
-	"http://localhost:3000",
-	"http://localhost:4200",
-	"https://mak-watches.vercel.app",
-	"https://makwatches.in",
-	"https://www.makwatches.in",
-}
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
 
-func CORSMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		origin := c.Get("Origin")
-		allowed := false
-		for _, o := range AllowedOrigins {
-			if o == origin {
-				allowed = true
-				break
-			}
-		}
-		if allowed {
-			c.Set("Access-Control-Allow-Origin", origin)
-		} else {
-			c.Set("Access-Control-Allow-Origin", AllowedOrigins[0]) // fallback to first allowed
+// CORS lets browsers call the API from the given origins, e.g.
+// "https://makwatches.in". An origin may start its host with "*." to allow
+// every subdomain ("https://*.vercel.app"). A lone "*" allows any origin but
+// then credentials are not allowed, as browsers require.
+func CORS(origins []string) fiber.Handler {
+	var exact []string
+	var suffixes []string // "https://.vercel.app" for "https://*.vercel.app"
+	anyOrigin := false
+	for _, o := range origins {
+		o = normalizeOrigin(o)
+		switch {
+		case o == "":
+		case o == "*":
+			anyOrigin = true
+		case strings.Contains(o, "://*."):
+			suffixes = append(suffixes, strings.Replace(o, "://*.", "://.", 1))
+		default:
+			exact = append(exact, o)
 		}
-		c.Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Set("Access-Control-Allow-Credentials", "true")
-		if c.Method() == fiber.MethodOptions {
-			return c.SendStatus(fiber.StatusNoContent)
-		}
-		return c.Next()
 	}
+	if anyOrigin {
+		log.Println("[CORS] ALLOWED_ORIGINS contains *, allowing any origin without credentials")
+	}
+
+	return cors.New(cors.Config{
+		AllowOriginsFunc: func(origin string) bool {
+			if anyOrigin {
+				return true
+			}
+			origin = normalizeOrigin(origin)
+			for _, o := range exact {
+				if origin == o {
+					return true
+				}
+			}
+			for _, s := range suffixes {
+				scheme, domain, _ := strings.Cut(s, "://")
+				rest, ok := strings.CutPrefix(origin, scheme+"://")
+				if ok && strings.HasSuffix(rest, domain) && len(rest) > len(domain) {
+					return true
+				}
+			}
+			return false
+		},
+		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With, X-CSRF-Token",
+		AllowCredentials: !anyOrigin,
+		ExposeHeaders:    "Content-Length, Content-Disposition, Access-Control-Allow-Origin, Access-Control-Allow-Headers",
+		MaxAge:           300,
+	})
+}
+
+// normalizeOrigin lowercases an origin and drops a trailing slash, so
+// "https://MakWatches.in/" matches "https://makwatches.in"
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
 }
@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/gofiber/fiber/v2"
+
+// Deprecated marks responses from legacy unversioned routes so clients can
+// migrate. successorPrefix (e.g. "/api/v1") is prepended to the request path
+// to advertise the replacement endpoint.
+func Deprecated(successorPrefix string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "true")
+		c.Set("Link", "<"+successorPrefix+c.Path()+">; rel=\"successor-version\"")
+		return c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/jwtkeys"
+)
+
+// OptionalAuth authenticates requests that carry a token exactly like Auth
+// and lets anonymous ones through without a user, so public routes can
+// personalise their response for signed-in callers
+func OptionalAuth(keys *jwtkeys.Set, db *database.DBClient) fiber.Handler {
+	auth := Auth(keys, db)
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return auth(c)
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/apperrors"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// RoleCacheKey returns the cache key holding a role's permissions.
+// Handlers that change or delete a role must delete this key.
+func RoleCacheKey(name string) string {
+	return fmt.Sprintf("role:%s", name)
+}
+
+// IsStaff reports whether role is a staff role rather than the customer one
+func IsStaff(role string) bool {
+	return role != "" && role != models.RoleUser
+}
+
+// loadRole fetches a role from cache, falling back to MongoDB. A role that
+// no longer exists grants nothing.
+func loadRole(ctx context.Context, db *database.DBClient, name string) (models.Role, error) {
+	var role models.Role
+	if err := db.CacheGet(ctx, RoleCacheKey(name), &role); err == nil {
+		return role, nil
+	}
+	err := db.Collections().Roles.FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		role = models.Role{Name: name}
+	} else if err != nil {
+		return models.Role{}, err
+	}
+	// Short, like the account state, so a missed invalidation heals quickly
+	db.CacheSet(ctx, RoleCacheKey(name), role, time.Minute)
+	return role, nil
+}
+
+// HasPermission reports whether role grants permission. Admins hold every
+// permission and customers none, so only other staff roles are looked up.
+func HasPermission(ctx context.Context, db *database.DBClient, role, permission string) (bool, error) {
+	switch {
+	case role == models.RoleAdmin:
+		return true, nil
+	case !IsStaff(role):
+		return false, nil
+	}
+	r, err := loadRole(ctx, db, role)
+	if err != nil {
+		return false, err
+	}
+	return r.Has(permission), nil
+}
+
+// Staff middleware lets through accounts holding any role but user. The
+// routes behind it still check their own permission.
+func Staff() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*TokenMetadata)
+		if !ok {
+			return apperrors.Unauthorized("Unauthorized - User data not found")
+		}
+		if !IsStaff(user.Role) {
+			return apperrors.Forbidden("Access forbidden - Insufficient permissions")
+		}
+		return c.Next()
+	}
+}
+
+// Permission middleware lets through accounts whose role grants at least
+// one of permissions
+func Permission(db *database.DBClient, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*TokenMetadata)
+		if !ok {
+			return apperrors.Unauthorized("Unauthorized - User data not found")
+		}
+		for _, permission := range permissions {
+			allowed, err := HasPermission(c.Context(), db, user.Role, permission)
+			if err != nil {
+				return apperrors.Internal("Failed to check permissions", err)
+			}
+			if allowed {
+				return c.Next()
+			}
+		}
+		return apperrors.Forbidden("Access forbidden - Insufficient permissions")
+	}
+}
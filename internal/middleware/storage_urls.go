@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
+)
+
+// jsonURLPattern matches a URL inside a JSON string, escapes included
+var jsonURLPattern = regexp.MustCompile(`https?:(?:\\?/){2}(?:[^"\\\s]|\\u[0-9a-fA-F]{4}|\\/)+`)
+
+// StorageURLs hands out the stored file URLs in JSON responses the way
+// delivery says, signed or on a CDN, and maps them back in JSON request
+// bodies. Documents and caches keep the saved URLs either way.
+func StorageURLs(delivery *storage.Delivery) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if isJSON(c.Get(fiber.HeaderContentType)) && len(c.Body()) > 0 {
+			c.Request().SetBody(rewriteJSONURLs(c.Body(), delivery.Saved))
+		}
+
+		err := c.Next()
+
+		if isJSON(string(c.Response().Header.ContentType())) {
+			ctx := c.Context()
+			c.Response().SetBody(rewriteJSONURLs(c.Response().Body(), func(url string) string {
+				return delivery.Outgoing(ctx, url)
+			}))
+		}
+		return err
+	}
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, fiber.MIMEApplicationJSON)
+}
+
+// rewriteJSONURLs replaces every URL in the strings of a JSON document
+// with what rewrite returns for it
+func rewriteJSONURLs(body []byte, rewrite func(string) string) []byte {
+	return jsonURLPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		var url string
+		if err := json.Unmarshal([]byte(`"`+string(match)+`"`), &url); err != nil {
+			return match
+		}
+		rewritten := rewrite(url)
+		if rewritten == url {
+			return match
+		}
+		encoded, err := json.Marshal(rewritten)
+		if err != nil {
+			return match
+		}
+		return encoded[1 : len(encoded)-1]
+	})
+}
@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Indexes backing the most frequent lookups: per-user collections, reviews
+// by product, catalogue browsing and the admin audit trail.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "core_indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			steps := []struct {
+				collection string
+				models     []mongo.IndexModel
+			}{
+				{"orders", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+					{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+				}},
+				{"cart_items", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "product_id", Value: 1}}},
+				}},
+				{"wishlists", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "product_id", Value: 1}}},
+				}},
+				{"reviews", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+					{Keys: bson.D{{Key: "user_id", Value: 1}}},
+				}},
+				{"user_addresses", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "user_id", Value: 1}}},
+				}},
+				{"products", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "category", Value: 1}, {Key: "created_at", Value: -1}}},
+					{Keys: bson.D{{Key: "main_category", Value: 1}, {Key: "subcategory", Value: 1}}},
+					{Keys: bson.D{{Key: "price", Value: 1}}},
+					{
+						Keys: bson.D{
+							{Key: "name", Value: "text"},
+							{Key: "brand", Value: "text"},
+							{Key: "description", Value: "text"},
+						},
+						Options: options.Index().
+							SetName("products_text").
+							SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "brand", Value: 5}, {Key: "description", Value: 1}}),
+					},
+				}},
+				{"audit_logs", []mongo.IndexModel{
+					{Keys: bson.D{{Key: "created_at", Value: -1}}},
+					{Keys: bson.D{{Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				}},
+			}
+
+			for _, step := range steps {
+				if err := createIndexes(ctx, db, step.collection, step.models...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
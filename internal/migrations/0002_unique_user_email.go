@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// Normalizes stored emails and enforces one account per email, ignoring case.
+// If existing data already contains duplicates the index build fails and the
+// migration is retried on the next boot once they have been merged.
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "unique_user_email",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			users := db.Collection("users")
+
+			normalize := mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{"email": bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$email"}}}}}},
+			}
+			if _, err := users.UpdateMany(ctx, bson.M{"email": bson.M{"$type": "string"}}, normalize); err != nil {
+				return fmt.Errorf("users: normalize emails: %w", err)
+			}
+
+			return createIndexes(ctx, db, "users", mongo.IndexModel{
+				Keys: bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().
+					SetName("users_email_unique").
+					SetUnique(true).
+					SetCollation(database.EmailCollation),
+			})
+		},
+	})
+}
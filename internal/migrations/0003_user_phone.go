@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// Phone-only accounts have no email, so the unique email index becomes
+// partial (only documents that have an email take part) and phone numbers
+// get their own unique index.
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "user_phone",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			users := db.Collection("users")
+
+			// Older accounts may store an empty string rather than omitting the field
+			if _, err := users.UpdateMany(ctx, bson.M{"email": ""}, bson.M{"$unset": bson.M{"email": ""}}); err != nil {
+				return fmt.Errorf("users: unset empty emails: %w", err)
+			}
+
+			if _, err := users.Indexes().DropOne(ctx, "users_email_unique"); err != nil && !isIndexNotFound(err) {
+				return fmt.Errorf("users: drop users_email_unique: %w", err)
+			}
+
+			return createIndexes(ctx, db, "users",
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "email", Value: 1}},
+					Options: options.Index().
+						SetName("users_email_unique").
+						SetUnique(true).
+						SetCollation(database.EmailCollation).
+						SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+				},
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "phone", Value: 1}},
+					Options: options.Index().
+						SetName("users_phone_unique").
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"phone": bson.M{"$type": "string"}}),
+				},
+			)
+		},
+	})
+}
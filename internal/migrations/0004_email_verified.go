@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Accounts created before email verification existed are treated as
+// verified, so turning on REQUIRE_VERIFIED_EMAIL does not lock existing
+// customers out of checkout.
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "email_verified",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx,
+				bson.M{"email_verified": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"email_verified": true}},
+			)
+			if err != nil {
+				return fmt.Errorf("users: backfill email_verified: %w", err)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The stock ledger is read per product, newest first, and by order when
+// reconciling sales and cancellations.
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "stock_movements",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "stock_movements",
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "order_id", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Co-purchase scores are looked up by the products a customer already
+// bought; feedback is read per user when picking those products.
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "co_purchase_recommendations",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "recommendations",
+				mongo.IndexModel{Keys: bson.D{{Key: "source", Value: 1}, {Key: "basis_product_id", Value: 1}, {Key: "score", Value: -1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "recommendation_feedbacks",
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The campaign job scans campaigns by status and releases products by the
+// campaign holding their discount.
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "campaigns",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "campaigns",
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "start_date", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "products",
+				mongo.IndexModel{Keys: bson.D{{Key: "campaign_id", Value: 1}}},
+			)
+		},
+	})
+}
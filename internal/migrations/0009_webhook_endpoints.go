@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Every published event looks up the active endpoints subscribed to it.
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "webhook_endpoints",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "webhook_endpoints",
+				mongo.IndexModel{Keys: bson.D{{Key: "active", Value: 1}, {Key: "events", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Product pages list questions by votes or recency; the admin queue lists
+// unanswered questions oldest first.
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "product_questions",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "product_questions",
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "upvotes", Value: -1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "answered_at", Value: 1}, {Key: "created_at", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Creates a brand for every brand already used by products. Spellings that
+// only differ in case or punctuation ("Casio", "casio") become one brand
+// named after the most common spelling, and products are updated to it.
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "brands",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := createIndexes(ctx, db, "brands",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "name", Value: 1}},
+					Options: options.Index().SetName("brands_name_unique").SetUnique(true).SetCollation(database.BrandCollation),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "slug", Value: 1}},
+					Options: options.Index().SetName("brands_slug_unique").SetUnique(true),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			products := db.Collection("products")
+			cursor, err := products.Aggregate(ctx, mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"brand": bson.M{"$type": "string", "$ne": ""}}}},
+				{{Key: "$group", Value: bson.M{"_id": "$brand", "count": bson.M{"$sum": 1}}}},
+				{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			})
+			if err != nil {
+				return fmt.Errorf("products: group brands: %w", err)
+			}
+			var spellings []struct {
+				Name string `bson:"_id"`
+			}
+			if err := cursor.All(ctx, &spellings); err != nil {
+				return fmt.Errorf("products: decode brands: %w", err)
+			}
+
+			// Most common spelling first, so it names the brand
+			canonical := map[string]string{}
+			variants := map[string][]string{}
+			var slugs []string
+			for _, s := range spellings {
+				slug := models.Slugify(s.Name)
+				if slug == "" {
+					continue
+				}
+				if _, ok := canonical[slug]; !ok {
+					canonical[slug] = s.Name
+					slugs = append(slugs, slug)
+				}
+				variants[slug] = append(variants[slug], s.Name)
+			}
+
+			brands := db.Collection("brands")
+			for _, slug := range slugs {
+				name := canonical[slug]
+				now := time.Now()
+				_, err := brands.UpdateOne(ctx,
+					bson.M{"slug": slug},
+					bson.M{"$setOnInsert": models.Brand{ID: primitive.NewObjectID(), Name: name, Slug: slug, CreatedAt: now, UpdatedAt: now}},
+					options.Update().SetUpsert(true),
+				)
+				if err != nil {
+					return fmt.Errorf("brands: create %q: %w", name, err)
+				}
+				// Re-read in case the brand already existed under another spelling
+				var brand models.Brand
+				if err := brands.FindOne(ctx, bson.M{"slug": slug}).Decode(&brand); err != nil {
+					return fmt.Errorf("brands: read %q: %w", slug, err)
+				}
+				if _, err := products.UpdateMany(ctx,
+					bson.M{"brand": bson.M{"$in": variants[slug]}},
+					bson.M{"$set": bson.M{"brand": brand.Name}},
+				); err != nil {
+					return fmt.Errorf("products: rename brand %q: %w", brand.Name, err)
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,253 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Turns the Men/Women categories with embedded subcategories into a tree of
+// category documents, keeping the subcategory IDs. Every "Main/Sub" string
+// used by products or category campaigns then gets a category, matched
+// ignoring case and spacing, and is rewritten to that category's path.
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "category_tree",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			tree := categoryTree{coll: db.Collection("categories")}
+			if err := tree.splitSubcategories(ctx); err != nil {
+				return err
+			}
+
+			products := db.Collection("products")
+			raw, err := products.Distinct(ctx, "category", bson.M{"category": bson.M{"$type": "string", "$ne": ""}})
+			if err != nil {
+				return fmt.Errorf("products: list categories: %w", err)
+			}
+			for _, r := range raw {
+				from, _ := r.(string)
+				cat, err := tree.ensure(ctx, from)
+				if err != nil {
+					return err
+				}
+				if cat == nil {
+					continue
+				}
+				main, sub := models.SplitCategoryPath(cat.Path)
+				if _, err := products.UpdateMany(ctx, bson.M{"category": from}, bson.M{"$set": bson.M{
+					"category":      cat.Path,
+					"main_category": main,
+					"subcategory":   sub,
+				}}); err != nil {
+					return fmt.Errorf("products: update category %q: %w", from, err)
+				}
+			}
+
+			campaigns := db.Collection("campaigns")
+			filter := bson.M{"target": models.CampaignTargetCategory}
+			raw, err = campaigns.Distinct(ctx, "category", filter)
+			if err != nil {
+				return fmt.Errorf("campaigns: list categories: %w", err)
+			}
+			for _, r := range raw {
+				from, _ := r.(string)
+				cat, err := tree.ensure(ctx, from)
+				if err != nil {
+					return err
+				}
+				if cat == nil {
+					continue
+				}
+				if _, err := campaigns.UpdateMany(ctx,
+					bson.M{"target": models.CampaignTargetCategory, "category": from},
+					bson.M{"$set": bson.M{"category": cat.Path}},
+				); err != nil {
+					return fmt.Errorf("campaigns: update category %q: %w", from, err)
+				}
+			}
+
+			return createIndexes(ctx, db, "categories",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "path", Value: 1}},
+					Options: options.Index().SetName("categories_path_unique").SetUnique(true).SetCollation(database.CategoryCollation),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "slug", Value: 1}},
+					Options: options.Index().SetName("categories_slug_unique").SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "sort_order", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+			)
+		},
+	})
+}
+
+// legacyCategory is the shape categories had before the tree
+type legacyCategory struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Name          string             `bson:"name"`
+	Subcategories []models.Category  `bson:"subcategories"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+type categoryTree struct {
+	coll *mongo.Collection
+}
+
+// splitSubcategories moves embedded subcategories into their own documents.
+// The parent is rewritten last, so a failed run is picked up again.
+func (t categoryTree) splitSubcategories(ctx context.Context) error {
+	cursor, err := t.coll.Find(ctx, bson.M{"subcategories": bson.M{"$exists": true}})
+	if err != nil {
+		return fmt.Errorf("categories: find legacy: %w", err)
+	}
+	var legacy []legacyCategory
+	if err := cursor.All(ctx, &legacy); err != nil {
+		return fmt.Errorf("categories: decode legacy: %w", err)
+	}
+
+	for _, l := range legacy {
+		root := models.Category{ID: l.ID, Path: models.CleanCategoryPath(l.Name), Ancestors: []primitive.ObjectID{}}
+		for i, sub := range l.Subcategories {
+			sub.Name = models.CleanCategoryPath(sub.Name)
+			if sub.Name == "" {
+				continue
+			}
+			path := models.JoinCategoryPath(root.Path, sub.Name)
+			if existing, err := t.find(ctx, path); err != nil {
+				return err
+			} else if existing != nil && existing.ID != sub.ID {
+				// A duplicate subcategory; products using it map to the first one
+				continue
+			}
+			if sub.ID.IsZero() {
+				sub.ID = primitive.NewObjectID()
+			}
+			slug, err := t.slug(ctx, path, sub.ID)
+			if err != nil {
+				return err
+			}
+			sub.Slug = slug
+			sub.ParentID = &l.ID
+			sub.Ancestors = []primitive.ObjectID{l.ID}
+			sub.Path = path
+			sub.Depth = 1
+			sub.SortOrder = i
+			sub.CreatedAt = l.CreatedAt
+			sub.UpdatedAt = time.Now()
+			if _, err := t.coll.ReplaceOne(ctx, bson.M{"_id": sub.ID}, sub, options.Replace().SetUpsert(true)); err != nil {
+				return fmt.Errorf("categories: create %q: %w", path, err)
+			}
+		}
+
+		slug, err := t.slug(ctx, root.Path, l.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := t.coll.UpdateOne(ctx, bson.M{"_id": l.ID}, bson.M{
+			"$set": bson.M{
+				"name":       root.Path,
+				"slug":       slug,
+				"ancestors":  root.Ancestors,
+				"path":       root.Path,
+				"depth":      0,
+				"sort_order": 0,
+			},
+			"$unset": bson.M{"subcategories": "", "parent_id": ""},
+		}); err != nil {
+			return fmt.Errorf("categories: convert %q: %w", root.Path, err)
+		}
+	}
+	return nil
+}
+
+// ensure returns the category for a free-form "Main/Sub" string, creating
+// it and any missing parents. Levels beyond the maximum depth are folded
+// into the deepest one.
+func (t categoryTree) ensure(ctx context.Context, raw string) (*models.Category, error) {
+	path := models.CleanCategoryPath(raw)
+	if path == "" {
+		return nil, nil
+	}
+	names := strings.Split(path, models.CategoryPathSeparator)
+	if len(names) > models.MaxCategoryDepth {
+		last := models.MaxCategoryDepth - 1
+		names = append(names[:last], strings.Join(names[last:], " "))
+	}
+
+	var parent *models.Category
+	for i := range names {
+		cat, err := t.find(ctx, models.JoinCategoryPath(names[:i+1]...))
+		if err != nil {
+			return nil, err
+		}
+		if cat == nil {
+			now := time.Now()
+			cat = &models.Category{
+				ID:        primitive.NewObjectID(),
+				Name:      names[i],
+				Ancestors: []primitive.ObjectID{},
+				Path:      names[i],
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if parent != nil {
+				cat.ParentID = &parent.ID
+				cat.Ancestors = append(append([]primitive.ObjectID{}, parent.Ancestors...), parent.ID)
+				cat.Path = models.JoinCategoryPath(parent.Path, names[i])
+				cat.Depth = parent.Depth + 1
+			}
+			if cat.Slug, err = t.slug(ctx, cat.Path, cat.ID); err != nil {
+				return nil, err
+			}
+			if _, err := t.coll.InsertOne(ctx, cat); err != nil {
+				return nil, fmt.Errorf("categories: create %q: %w", cat.Path, err)
+			}
+		}
+		parent = cat
+	}
+	return parent, nil
+}
+
+// find looks a category up by path, ignoring case
+func (t categoryTree) find(ctx context.Context, path string) (*models.Category, error) {
+	var cat models.Category
+	err := t.coll.FindOne(ctx, bson.M{"path": path}, options.FindOne().SetCollation(database.CategoryCollation)).Decode(&cat)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("categories: find %q: %w", path, err)
+	}
+	return &cat, nil
+}
+
+// slug derives a slug from path that no other category uses yet
+func (t categoryTree) slug(ctx context.Context, path string, id primitive.ObjectID) (string, error) {
+	base := models.Slugify(path)
+	if base == "" {
+		base = id.Hex()
+	}
+	slug := base
+	for n := 2; ; n++ {
+		count, err := t.coll.CountDocuments(ctx, bson.M{"slug": slug, "_id": bson.M{"$ne": id}})
+		if err != nil {
+			return "", fmt.Errorf("categories: check slug %q: %w", slug, err)
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
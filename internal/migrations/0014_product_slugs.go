@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Gives every existing product its slug and makes slugs unique, so catalog
+// pages can be addressed by /catalog/products/slug/:slug.
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "product_slugs",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			products := db.Collection("products")
+			cursor, err := products.Find(ctx,
+				bson.M{"slug": bson.M{"$exists": false}},
+				options.Find().SetProjection(bson.M{"name": 1}),
+			)
+			if err != nil {
+				return fmt.Errorf("products: find without slug: %w", err)
+			}
+			var docs []struct {
+				ID   primitive.ObjectID `bson:"_id"`
+				Name string             `bson:"name"`
+			}
+			if err := cursor.All(ctx, &docs); err != nil {
+				return fmt.Errorf("products: decode: %w", err)
+			}
+
+			writes := make([]mongo.WriteModel, 0, len(docs))
+			for _, d := range docs {
+				writes = append(writes, mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"_id": d.ID}).
+					SetUpdate(bson.M{"$set": bson.M{"slug": models.ProductSlug(d.Name, d.ID)}}))
+			}
+			if len(writes) > 0 {
+				if _, err := products.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+					return fmt.Errorf("products: set slugs: %w", err)
+				}
+			}
+
+			return createIndexes(ctx, db, "products", mongo.IndexModel{
+				Keys: bson.D{{Key: "slug", Value: 1}},
+				Options: options.Index().
+					SetName("products_slug_unique").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+			})
+		},
+	})
+}
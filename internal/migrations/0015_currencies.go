@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Seeds the currency table with the base currency, which prices are stored
+// in. Other currencies are added by admins.
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "currencies",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			base := models.DefaultCurrency()
+			now := time.Now()
+			base.RateUpdatedAt, base.CreatedAt, base.UpdatedAt = now, now, now
+			_, err := db.Collection("currencies").UpdateOne(ctx,
+				bson.M{"_id": base.Code},
+				bson.M{"$setOnInsert": base},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return fmt.Errorf("currencies: create %s: %w", base.Code, err)
+			}
+			return createIndexes(ctx, db, "currencies",
+				mongo.IndexModel{Keys: bson.D{{Key: "enabled", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Cursor pagination sorts by a field and then _id, so each sort order a
+// listing offers needs an index ending in _id to seek straight to a page.
+func init() {
+	register(Migration{
+		Version: 16,
+		Name:    "cursor_pagination",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "products",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "stock", Value: 1}, {Key: "_id", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "reviews",
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "orders",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+			)
+		},
+	})
+}
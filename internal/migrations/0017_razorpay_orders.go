@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Orders are looked up by their Razorpay order when a payment arrives or is
+// polled, and the payment reconciliation job scans the ones still waiting
+// for their payment by age.
+func init() {
+	register(Migration{
+		Version: 17,
+		Name:    "razorpay_orders",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "orders",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "payment_info.razorpay_order_id", Value: 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.M{"payment_info.razorpay_order_id": bson.M{"$exists": true}}),
+				},
+				mongo.IndexModel{Keys: bson.D{
+					{Key: "payment_info.method", Value: 1},
+					{Key: "status", Value: 1},
+					{Key: "created_at", Value: 1},
+				}},
+			)
+		},
+	})
+}
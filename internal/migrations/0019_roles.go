@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Seeds the roles collection so existing admin and user accounts keep their
+// access, and indexes accounts by role so a role in use isn't deleted
+func init() {
+	register(Migration{
+		Version: 19,
+		Name:    "roles",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			now := time.Now()
+			for _, role := range models.DefaultRoles() {
+				role.CreatedAt, role.UpdatedAt = now, now
+				_, err := db.Collection("roles").UpdateOne(ctx,
+					bson.M{"_id": role.Name},
+					bson.M{"$setOnInsert": role},
+					options.Update().SetUpsert(true),
+				)
+				if err != nil {
+					return fmt.Errorf("roles: create %s: %w", role.Name, err)
+				}
+			}
+			return createIndexes(ctx, db, "users",
+				mongo.IndexModel{Keys: bson.D{{Key: "role", Value: 1}}},
+			)
+		},
+	})
+}
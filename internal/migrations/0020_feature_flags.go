@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Seeds the feature flags, on for everyone, so flagged features keep working
+// until an admin dials them back
+func init() {
+	register(Migration{
+		Version: 20,
+		Name:    "feature_flags",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			now := time.Now()
+			for _, flag := range models.DefaultFeatureFlags() {
+				flag.CreatedAt, flag.UpdatedAt = now, now
+				_, err := db.Collection("feature_flags").UpdateOne(ctx,
+					bson.M{"_id": flag.Key},
+					bson.M{"$setOnInsert": flag},
+					options.Update().SetUpsert(true),
+				)
+				if err != nil {
+					return fmt.Errorf("feature_flags: create %s: %w", flag.Key, err)
+				}
+			}
+			return nil
+		},
+	})
+}
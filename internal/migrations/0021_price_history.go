@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Price history is read per product, newest first, and scanned per product
+// over the last 30 days for the lowest price.
+func init() {
+	register(Migration{
+		Version: 21,
+		Name:    "price_history",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "price_history",
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			)
+		},
+	})
+}
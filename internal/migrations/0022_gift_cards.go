@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Gift cards are redeemed by code, which must be unique, and listed newest
+// first for admins.
+func init() {
+	register(Migration{
+		Version: 22,
+		Name:    "gift_cards",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "gift_cards",
+				mongo.IndexModel{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}},
+			)
+		},
+	})
+}
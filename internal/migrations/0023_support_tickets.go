@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Support tickets are listed by customer and, in the staff inbox, by
+// status, most recently active first; a ticket's messages are read in order.
+func init() {
+	register(Migration{
+		Version: 23,
+		Name:    "support_tickets",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "chat_conversations",
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_message_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "last_message_at", Value: -1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "chat_messages",
+				mongo.IndexModel{Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "timestamp", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Catalog listings read catalog_read, newest first or by effective price or
+// rating, optionally within a category or brand; the projector looks up
+// documents whose discounted price is due to change.
+func init() {
+	register(Migration{
+		Version: 24,
+		Name:    "catalog_read",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "catalog_read",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "effective_price", Value: 1}, {Key: "_id", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "avg_rating", Value: -1}, {Key: "_id", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "category", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "brand", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "price_valid_until", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// One total per product and day, summed over recent days to rank products;
+// listings sort by the resulting popularity.
+func init() {
+	register(Migration{
+		Version: 27,
+		Name:    "product_views",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "product_views",
+				mongo.IndexModel{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "day", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "day", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "products",
+				mongo.IndexModel{Keys: bson.D{{Key: "popularity", Value: -1}, {Key: "_id", Value: -1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "catalog_read",
+				mongo.IndexModel{Keys: bson.D{{Key: "popularity", Value: -1}, {Key: "_id", Value: -1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Allocating a product's pre-orders walks its unallocated pre-order lines,
+// oldest order first. Only orders with such lines are indexed.
+func init() {
+	register(Migration{
+		Version: 28,
+		Name:    "preorders",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "orders",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "items.product_id", Value: 1}, {Key: "created_at", Value: 1}},
+					Options: options.Index().SetPartialFilterExpression(bson.M{"items.preorder": true}),
+				},
+			)
+		},
+	})
+}
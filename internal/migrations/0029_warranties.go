@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// One warranty per unit of an order line, so issuing them twice on a
+// repeated delivery event adds nothing. Serial numbers are unique once
+// registered and are how service centers look watches up.
+func init() {
+	register(Migration{
+		Version: 29,
+		Name:    "warranties",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "warranties",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "order_id", Value: 1}, {Key: "line", Value: 1}, {Key: "unit", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "serial_number", Value: 1}},
+					Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"serial_number": bson.M{"$type": "string"}}),
+				},
+			)
+		},
+	})
+}
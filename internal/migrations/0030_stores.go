@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The store locator finds the boutiques nearest a customer with $nearSphere,
+// which needs a 2dsphere index on their location
+func init() {
+	register(Migration{
+		Version: 30,
+		Name:    "stores",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "stores",
+				mongo.IndexModel{Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "active", Value: 1}, {Key: "position", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Journal posts are addressed by slug and listed newest first, optionally
+// by tag
+func init() {
+	register(Migration{
+		Version: 33,
+		Name:    "posts",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "posts",
+				mongo.IndexModel{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "published_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "tags", Value: 1}, {Key: "published_at", Value: -1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The help page reads the published FAQs in position order
+func init() {
+	register(Migration{
+		Version: 34,
+		Name:    "faqs",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "faqs",
+				mongo.IndexModel{Keys: bson.D{{Key: "published", Value: 1}, {Key: "position", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The media library lists uploads newest first and looks them up by URL to
+// keep product deletion from removing files it holds
+func init() {
+	register(Migration{
+		Version: 35,
+		Name:    "media",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, "media",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: -1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "urls", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The pruning job deletes by age, across every user
+func init() {
+	register(Migration{
+		Version: 36,
+		Name:    "retention",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, "recommendation_feedbacks",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			if err := createIndexes(ctx, db, "cart_items",
+				mongo.IndexModel{Keys: bson.D{{Key: "updated_at", Value: 1}}},
+			); err != nil {
+				return err
+			}
+			return createIndexes(ctx, db, "notifications",
+				mongo.IndexModel{Keys: bson.D{{Key: "created_at", Value: 1}}},
+			)
+		},
+	})
+}
@@ -0,0 +1,119 @@
+// Package migrations applies versioned, idempotent schema changes (mostly
+// index creation) to MongoDB. Applied versions are tracked in the
+// "migrations" collection so each step runs exactly once per database.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// collectionName is where applied migrations are recorded
+const collectionName = "migrations"
+
+// Migration is a single versioned step. Up must be safe to re-run in case a
+// previous attempt failed after partially applying its changes.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// record is the document stored for every applied migration
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// registry holds every known migration; steps register themselves from init
+var registry []Migration
+
+// register adds a migration to the registry, panicking on duplicate versions
+// so mistakes surface at startup rather than silently skipping a step.
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("migrations: duplicate version %d (%s, %s)", m.Version, existing.Name, m.Name))
+		}
+	}
+	registry = append(registry, m)
+}
+
+// Run applies every pending migration in version order
+func Run(ctx context.Context, db *mongo.Database) error {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+
+	pending := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	if len(pending) == 0 {
+		log.Println("[MIGRATIONS] Database is up to date")
+		return nil
+	}
+
+	collection := db.Collection(collectionName)
+	for _, m := range pending {
+		log.Printf("[MIGRATIONS] Applying %d_%s", m.Version, m.Name)
+		if err := m.Up(ctx, db); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := collection.InsertOne(ctx, record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}); err != nil {
+			return fmt.Errorf("failed to record migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	log.Printf("[MIGRATIONS] Applied %d migration(s)", len(pending))
+	return nil
+}
+
+// appliedVersions returns the set of versions already recorded
+func appliedVersions(ctx context.Context, db *mongo.Database) (map[int]bool, error) {
+	cursor, err := db.Collection(collectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, r := range records {
+		applied[r.Version] = true
+	}
+	return applied, nil
+}
+
+// createIndexes is a helper for index-only migrations. CreateMany is a no-op
+// for indexes that already exist with the same definition.
+func createIndexes(ctx context.Context, db *mongo.Database, collection string, models ...mongo.IndexModel) error {
+	if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("%s: %w", collection, err)
+	}
+	return nil
+}
+
+// isIndexNotFound reports whether err is MongoDB's IndexNotFound (code 27),
+// which dropping an index that was never created returns
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 27
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Activity types shown on an account's timeline
+const (
+	ActivityOrderPlaced    = "order_placed"
+	ActivityReviewWritten  = "review_written"
+	ActivityWishlistAdded  = "wishlist_added"
+	ActivityAddressAdded   = "address_added"
+	ActivityAddressUpdated = "address_updated"
+	ActivityAddressRemoved = "address_removed"
+)
+
+// ActivityTypes lists every activity type, for filtering the timeline
+var ActivityTypes = []string{
+	ActivityOrderPlaced,
+	ActivityReviewWritten,
+	ActivityWishlistAdded,
+	ActivityAddressAdded,
+	ActivityAddressUpdated,
+	ActivityAddressRemoved,
+}
+
+// Activity is one entry on an account's timeline, recorded from the event
+// that reported it. It shares the event's ID, so an event recorded twice
+// leaves one entry.
+type Activity struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID `json:"-" bson:"user_id"`
+	Type      string             `json:"type" bson:"type"`
+	Summary   string             `json:"summary" bson:"summary"`
+	SubjectID string             `json:"subjectId,omitempty" bson:"subject_id,omitempty"` // The order, product or address it concerns
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+}
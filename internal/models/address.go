@@ -11,11 +11,11 @@ type Address struct {
 	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	UserID      primitive.ObjectID `json:"userId" bson:"user_id"`
 	Name        string             `json:"name" bson:"name"`
-	Street      string             `json:"street" bson:"street"`
-	City        string             `json:"city" bson:"city"`
-	State       string             `json:"state" bson:"state"`
-	ZipCode     string             `json:"zipCode" bson:"zip_code"`
-	Country     string             `json:"country" bson:"country"`
+	Street      string             `json:"street" bson:"street" validate:"required"`
+	City        string             `json:"city" bson:"city" validate:"required"`
+	State       string             `json:"state" bson:"state" validate:"required"`
+	ZipCode     string             `json:"zipCode" bson:"zip_code" validate:"required"`
+	Country     string             `json:"country" bson:"country" validate:"required"`
 	Phone       string             `json:"phone" bson:"phone"`
 	IsDefault   bool               `json:"isDefault" bson:"is_default"`
 	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
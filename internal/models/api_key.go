@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// API key scopes. Keys only ever read.
+const (
+	APIScopeCatalogRead = "catalog:read"
+	APIScopeOrdersRead  = "orders:read"
+)
+
+// APIScopePermissions maps a scope to the permission the key's owner needs
+// to use it. Catalog data is public, so it needs none.
+var APIScopePermissions = map[string]string{
+	APIScopeOrdersRead: PermissionOrdersRead,
+}
+
+// APIKey lets another system call the integration routes with an X-API-Key
+// header. A key acts as the staff member who created it and stops working
+// once revoked or once they lose access.
+type APIKey struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name   string             `json:"name" bson:"name"`
+	Prefix string             `json:"prefix" bson:"prefix"` // Start of the key, to tell keys apart
+	Hash   string             `json:"-" bson:"hash"`        // SHA-256 of the key; the key itself is shown only when created
+	Scopes []string           `json:"scopes" bson:"scopes"`
+	// OwnerID is the account the key acts as
+	OwnerID    primitive.ObjectID `json:"ownerId" bson:"owner_id"`
+	LastUsedAt *time.Time         `json:"lastUsedAt,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP string             `json:"lastUsedIp,omitempty" bson:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time         `json:"revokedAt,omitempty" bson:"revoked_at,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyRequest creates an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=catalog:read orders:read"`
+}
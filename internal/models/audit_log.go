@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog records a single admin mutation for accountability
+type AuditLog struct {
+	ID           primitive.ObjectID     `json:"id,omitempty" bson:"_id,omitempty"`
+	ActorID      primitive.ObjectID     `json:"actorId" bson:"actor_id"`
+	ActorRole    string                 `json:"actorRole" bson:"actor_role"`
+	Action       string                 `json:"action" bson:"action"`              // e.g. "product.update", "order.status_change"
+	ResourceType string                 `json:"resourceType" bson:"resource_type"` // "product", "order", "settings", "category", "account"
+	ResourceID   string                 `json:"resourceId,omitempty" bson:"resource_id,omitempty"`
+	Changes      map[string]AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Method       string                 `json:"method" bson:"method"`
+	Path         string                 `json:"path" bson:"path"`
+	IP           string                 `json:"ip" bson:"ip"`
+	UserAgent    string                 `json:"userAgent,omitempty" bson:"user_agent,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt" bson:"created_at"`
+}
+
+// AuditChange holds the before and after value of a single changed field
+type AuditChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Brand is a watch brand. Products reference it by name in their brand
+// field; the slug addresses its storefront page.
+type Brand struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Slug        string             `json:"slug" bson:"slug"`
+	LogoURL     string             `json:"logoUrl,omitempty" bson:"logo_url,omitempty"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// BrandRequest creates or updates a brand. The slug is derived from the
+// name when omitted.
+type BrandRequest struct {
+	Name        string `json:"name" validate:"required,max=80"`
+	Slug        string `json:"slug,omitempty" validate:"omitempty,max=80"`
+	LogoURL     string `json:"logoUrl,omitempty" validate:"omitempty,url"`
+	Description string `json:"description,omitempty" validate:"max=2000"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Campaign targets
+const (
+	CampaignTargetCategory = "category"
+	CampaignTargetBrand    = "brand"
+	CampaignTargetProducts = "products"
+)
+
+// Campaign discount types
+const (
+	CampaignDiscountPercentage = "percentage"
+	CampaignDiscountFixed      = "fixed"
+)
+
+// Campaign lifecycle. Scheduled campaigns become active at StartDate and end
+// at EndDate; the campaign job moves them along.
+const (
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusActive    = "active"
+	CampaignStatusEnded     = "ended"
+)
+
+// Campaign is a time-boxed discount applied in bulk to the per-product
+// discount fields of every product it targets
+type Campaign struct {
+	ID            primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name          string               `json:"name" bson:"name"`
+	Description   string               `json:"description,omitempty" bson:"description,omitempty"`
+	Target        string               `json:"target" bson:"target"`
+	Category      string               `json:"category,omitempty" bson:"category,omitempty"`
+	Brand         string               `json:"brand,omitempty" bson:"brand,omitempty"`
+	ProductIDs    []primitive.ObjectID `json:"productIds,omitempty" bson:"product_ids,omitempty"`
+	DiscountType  string               `json:"discountType" bson:"discount_type"`
+	DiscountValue float64              `json:"discountValue" bson:"discount_value"`
+	StartDate     time.Time            `json:"startDate" bson:"start_date"`
+	EndDate       time.Time            `json:"endDate" bson:"end_date"`
+	Status        string               `json:"status" bson:"status"`
+	AppliedCount  int64                `json:"appliedCount" bson:"applied_count"` // Products currently carrying the discount
+	ActivatedAt   *time.Time           `json:"activatedAt,omitempty" bson:"activated_at,omitempty"`
+	EndedAt       *time.Time           `json:"endedAt,omitempty" bson:"ended_at,omitempty"`
+	CreatedBy     primitive.ObjectID   `json:"createdBy,omitempty" bson:"created_by,omitempty"`
+	CreatedAt     time.Time            `json:"createdAt" bson:"created_at"`
+	UpdatedAt     time.Time            `json:"updatedAt" bson:"updated_at"`
+}
+
+// CampaignRequest creates or replaces a campaign
+type CampaignRequest struct {
+	Name          string    `json:"name" validate:"required,max=120"`
+	Description   string    `json:"description,omitempty" validate:"max=1000"`
+	Target        string    `json:"target" validate:"required,oneof=category brand products"`
+	Category      string    `json:"category,omitempty" validate:"required_if=Target category"`
+	Brand         string    `json:"brand,omitempty" validate:"required_if=Target brand"`
+	ProductIDs    []string  `json:"productIds,omitempty" validate:"required_if=Target products,dive,len=24,hexadecimal"`
+	DiscountType  string    `json:"discountType" validate:"required,oneof=percentage fixed"`
+	DiscountValue float64   `json:"discountValue" validate:"gt=0"`
+	StartDate     time.Time `json:"startDate" validate:"required"`
+	EndDate       time.Time `json:"endDate" validate:"required,gtfield=StartDate"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,6 +18,32 @@ type CartItem struct {
 	Quantity  int       `json:"quantity" bson:"quantity"`
 	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" bson:"updated_at"`
+	// Effective (discounted) price of one unit and of the line; computed when
+	// the cart is read, not stored
+	UnitPrice float64 `json:"unitPrice" bson:"-"`
+	LineTotal float64 `json:"lineTotal" bson:"-"`
+	// Effective price of one unit when the item was last added. Checkout
+	// asks the customer to confirm when the cart no longer adds up to these.
+	// Unset on items added before prices were kept.
+	PriceAtAdd float64 `json:"priceAtAdd,omitempty" bson:"price_at_add,omitempty"`
+	// Whether UnitPrice differs from PriceAtAdd; computed when the cart is read
+	PriceChanged bool `json:"priceChanged" bson:"-"`
+}
+
+// PriceChangedFrom reports whether unitPrice differs from the price the item
+// was added at, ignoring rounding
+func (i CartItem) PriceChangedFrom(unitPrice float64) bool {
+	return i.PriceAtAdd > 0 && math.Abs(unitPrice-i.PriceAtAdd) >= 0.01
+}
+
+// CartPriceChange is a cart line whose price changed since it was added,
+// in the base currency
+type CartPriceChange struct {
+	ProductID   primitive.ObjectID `json:"productId"`
+	ProductName string             `json:"productName"`
+	Size        string             `json:"size,omitempty"`
+	OldPrice    float64            `json:"oldPrice"`
+	NewPrice    float64            `json:"newPrice"`
 }
 
 // CartItemRequest represents the data required for adding a product to cart
@@ -26,8 +53,16 @@ type CartItemRequest struct {
 	Size      string `json:"size,omitempty"`
 }
 
+// CartItemUpdateRequest sets the quantity of a cart line
+type CartItemUpdateRequest struct {
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+	Size     string `json:"size,omitempty"` // Picks the line; empty matches the line without a size
+}
+
 // CartResponse represents the response for cart operations
 type CartResponse struct {
 	Items []CartItem `json:"items"`
 	Total float64    `json:"total"`
+	// Whether any item's price changed since it was added
+	PriceChanged bool `json:"priceChanged"`
 }
@@ -1,16 +1,37 @@
 package models
 
 import (
+	"encoding/json"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Category represents a top-level category (Men or Women)
+// MaxCategoryDepth is how many levels the category tree may have, e.g.
+// Men > Luxury > Automatic
+const MaxCategoryDepth = 3
+
+// CategoryPathSeparator joins the names of a category's ancestors and its
+// own name into its path, e.g. "Men/Luxury". Products store that path in
+// their category field.
+const CategoryPathSeparator = "/"
+
+// Category is a node in the category tree. Top-level categories have no
+// parent and depth 0.
 type Category struct {
-	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name          string             `json:"name" bson:"name"`
-	Subcategories []Subcategory      `json:"subcategories" bson:"subcategories"`
+	ID       primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name     string              `json:"name" bson:"name"`
+	Slug     string              `json:"slug" bson:"slug"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty" bson:"parent_id,omitempty"`
+	// Ancestors lists the IDs from the top-level category down to the parent
+	Ancestors []primitive.ObjectID `json:"ancestors" bson:"ancestors"`
+	Path      string               `json:"path" bson:"path"`
+	Depth     int                  `json:"depth" bson:"depth"`
+	ImageURL  string               `json:"imageUrl,omitempty" bson:"image_url,omitempty"`
+	SortOrder int                  `json:"sortOrder" bson:"sort_order"`
+	SEO       *SEO                 `json:"seo,omitempty" bson:"seo,omitempty"` // Overrides of the landing page's meta tags
 	// Category-level discount fields (optional)
 	DiscountPercentage *float64   `json:"discountPercentage,omitempty" bson:"discount_percentage,omitempty"`
 	DiscountAmount     *float64   `json:"discountAmount,omitempty" bson:"discount_amount,omitempty"`
@@ -20,58 +41,59 @@ type Category struct {
 	UpdatedAt          time.Time  `json:"updatedAt" bson:"updated_at"`
 }
 
-// Subcategory represents a nested category under a main category
-type Subcategory struct {
-	ID   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Name string             `json:"name" bson:"name"`
-	// ImageURL is an optional image associated with the subcategory
-	ImageURL string `json:"imageUrl,omitempty" bson:"image_url,omitempty"`
-	// Subcategory-level discount fields (optional)
-	DiscountPercentage *float64   `json:"discountPercentage,omitempty" bson:"discount_percentage,omitempty"`
-	DiscountAmount     *float64   `json:"discountAmount,omitempty" bson:"discount_amount,omitempty"`
-	DiscountStartDate  *time.Time `json:"discountStartDate,omitempty" bson:"discount_start_date,omitempty"`
-	DiscountEndDate    *time.Time `json:"discountEndDate,omitempty" bson:"discount_end_date,omitempty"`
+// CategoryNode is a category with its subcategories, as returned by the
+// tree endpoints
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
 }
 
-// CreateCategoryRequest request body for creating a category
+// CreateCategoryRequest creates a category, under parentId when given
 // Example:
 //
 //	{
-//	  "name": "Men",
-//	  "subcategories": ["Shirts", "Jeans"]
+//	  "name": "Luxury",
+//	  "parentId": "...",
+//	  "subcategories": ["Automatic", {"name": "Chronograph", "imageUrl": "https://..."}]
 //	}
 type CreateCategoryRequest struct {
-	Name          string   `json:"name"`
-	Subcategories []string `json:"subcategories"`
+	Name          string             `json:"name" validate:"required,max=60,excludes=/"`
+	Slug          string             `json:"slug,omitempty" validate:"omitempty,max=80"`
+	ParentID      string             `json:"parentId,omitempty"`
+	ImageURL      string             `json:"imageUrl,omitempty" validate:"omitempty,url"`
+	SortOrder     int                `json:"sortOrder"`
+	SEO           *SEO               `json:"seo,omitempty"`
+	Subcategories []SubcategoryInput `json:"subcategories,omitempty" validate:"dive"`
 }
 
-// AddSubcategoryRequest for adding a new subcategory
-// Example:
-// { "name": "Shoes" }
-type AddSubcategoryRequest struct {
-	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+// SubcategoryInput is a child created along with its category
+type SubcategoryInput struct {
+	Name     string `json:"name" validate:"required,max=60,excludes=/"`
+	ImageURL string `json:"imageUrl" validate:"omitempty,url"`
 }
 
-// UpdateNameRequest used for updating category or subcategory names
-// Example:
-// { "name": "Women" }
-type UpdateNameRequest struct {
-	Name string `json:"name"`
+// UnmarshalJSON also accepts a bare name, e.g. "subcategories": ["Sports"]
+func (s *SubcategoryInput) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = SubcategoryInput{Name: name}
+		return nil
+	}
+	type plain SubcategoryInput
+	return json.Unmarshal(data, (*plain)(s))
 }
 
-// UpdateSubcategoryRequest allows updating subcategory fields optionally
+// UpdateCategoryRequest changes the given fields of a category. Setting
+// parentId moves it with its subtree; an empty parentId makes it top-level.
 // Example:
 // { "name": "Sneakers", "imageUrl": "https://..." }
-type UpdateSubcategoryRequest struct {
-	Name     *string `json:"name"`
-	ImageURL *string `json:"imageUrl"`
-}
-
-// SubcategoryInput represents input for creating subcategories with optional image
-type SubcategoryInput struct {
-	Name     string `json:"name"`
-	ImageURL string `json:"imageUrl"`
+type UpdateCategoryRequest struct {
+	Name      *string `json:"name" validate:"omitempty,min=1,max=60,excludes=/"`
+	Slug      *string `json:"slug" validate:"omitempty,max=80"`
+	ParentID  *string `json:"parentId"`
+	ImageURL  *string `json:"imageUrl" validate:"omitempty,url"`
+	SortOrder *int    `json:"sortOrder"`
+	SEO       *SEO    `json:"seo"` // Replaces the stored SEO fields; {} clears them
 }
 
 // CategoryDiscountRequest for updating category-level discounts
@@ -82,10 +104,32 @@ type CategoryDiscountRequest struct {
 	DiscountEndDate    *time.Time `json:"discountEndDate,omitempty"`
 }
 
-// SubcategoryDiscountRequest for updating subcategory-level discounts
-type SubcategoryDiscountRequest struct {
-	DiscountPercentage *float64   `json:"discountPercentage,omitempty"`
-	DiscountAmount     *float64   `json:"discountAmount,omitempty"`
-	DiscountStartDate  *time.Time `json:"discountStartDate,omitempty"`
-	DiscountEndDate    *time.Time `json:"discountEndDate,omitempty"`
+// JoinCategoryPath builds a category path from its names, e.g. "Men/Luxury"
+func JoinCategoryPath(names ...string) string {
+	return strings.Join(names, CategoryPathSeparator)
+}
+
+// CleanCategoryPath trims the names in a category path, so "Men / Luxury"
+// becomes "Men/Luxury", and drops empty ones
+func CleanCategoryPath(path string) string {
+	var names []string
+	for _, name := range strings.Split(path, CategoryPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return JoinCategoryPath(names...)
+}
+
+// SplitCategoryPath returns the top-level category of path and the rest of
+// it, which products keep as mainCategory and subcategory
+func SplitCategoryPath(path string) (main, sub string) {
+	main, sub, _ = strings.Cut(path, CategoryPathSeparator)
+	return main, sub
+}
+
+// CategorySubtree matches the category path and the paths of all its
+// subcategories, for filtering products by category
+func CategorySubtree(path string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(path) + "(/|$)"}
 }
@@ -6,24 +6,36 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Support ticket statuses
+const (
+	SupportOpen     = "open"     // Waiting for staff
+	SupportPending  = "pending"  // Waiting for the customer
+	SupportResolved = "resolved" // Reopened when the customer writes again
+)
+
 // ChatMessage represents a message in the chat support system
 type ChatMessage struct {
-	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	ConversationID primitive.ObjectID `json:"conversationId" bson:"conversation_id"`
-	UserID      primitive.ObjectID `json:"userId" bson:"user_id"`
-	Content     string             `json:"content" bson:"content"`
-	IsBot       bool               `json:"isBot" bson:"is_bot"`
-	Timestamp   time.Time          `json:"timestamp" bson:"timestamp"`
+	UserID         primitive.ObjectID `json:"userId" bson:"user_id"` // Author
+	Content        string             `json:"content" bson:"content"`
+	IsBot          bool               `json:"isBot" bson:"is_bot"`
+	IsStaff        bool               `json:"isStaff" bson:"is_staff"`
+	Timestamp      time.Time          `json:"timestamp" bson:"timestamp"`
 }
 
-// ChatConversation represents a chat conversation
+// ChatConversation represents a chat conversation. Support tickets are
+// conversations whose title is the ticket's subject.
 type ChatConversation struct {
-	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	UserID      primitive.ObjectID `json:"userId" bson:"user_id"`
-	Title       string             `json:"title" bson:"title"`
-	Status      string             `json:"status" bson:"status"` // "active", "resolved", "archived"
-	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updated_at"`
+	ID               primitive.ObjectID  `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID           primitive.ObjectID  `json:"userId" bson:"user_id"`
+	Title            string              `json:"title" bson:"title"`
+	OrderID          *primitive.ObjectID `json:"orderId,omitempty" bson:"order_id,omitempty"`
+	Status           string              `json:"status" bson:"status"` // "open", "pending", "resolved"
+	LastMessageAt    time.Time           `json:"lastMessageAt" bson:"last_message_at"`
+	LastStaffReplyAt *time.Time          `json:"lastStaffReplyAt,omitempty" bson:"last_staff_reply_at,omitempty"`
+	CreatedAt        time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt        time.Time           `json:"updatedAt" bson:"updated_at"`
 }
 
 // ChatMessageRequest is used for sending a message
@@ -34,11 +46,25 @@ type ChatMessageRequest struct {
 
 // ChatConversationResponse represents a chat conversation with messages
 type ChatConversationResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	UserID    primitive.ObjectID `json:"userId"`
-	Title     string             `json:"title"`
-	Status    string             `json:"status"`
-	CreatedAt time.Time          `json:"createdAt"`
-	UpdatedAt time.Time          `json:"updatedAt"`
-	Messages  []ChatMessage      `json:"messages"`
+	ChatConversation
+	Messages []ChatMessage `json:"messages"` // Oldest first
+}
+
+// CreateTicketRequest opens a support ticket, optionally about an order
+type CreateTicketRequest struct {
+	Subject string `json:"subject" validate:"required,max=200"`
+	OrderID string `json:"orderId,omitempty"`
+	Message string `json:"message" validate:"required,max=5000"`
+}
+
+// TicketReplyRequest adds a message to a support ticket. Staff may set the
+// status it moves to; otherwise a staff reply leaves the ticket pending.
+type TicketReplyRequest struct {
+	Message string `json:"message" validate:"required,max=5000"`
+	Status  string `json:"status,omitempty" validate:"omitempty,oneof=open pending resolved"`
+}
+
+// TicketStatusRequest changes the status of a support ticket
+type TicketStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open pending resolved"`
 }
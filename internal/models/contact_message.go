@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Contact message statuses
+const (
+	ContactMessageNew     = "new"
+	ContactMessageReplied = "replied"
+)
+
+// ContactMessage is a message sent through the storefront's contact form.
+// It is forwarded to the store's contact email and kept for staff to track.
+type ContactMessage struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name      string              `json:"name" bson:"name"`
+	Email     string              `json:"email" bson:"email"`
+	Subject   string              `json:"subject" bson:"subject"`
+	Message   string              `json:"message" bson:"message"`
+	Status    string              `json:"status" bson:"status"`
+	IP        string              `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent string              `json:"userAgent,omitempty" bson:"user_agent,omitempty"`
+	RepliedAt *time.Time          `json:"repliedAt,omitempty" bson:"replied_at,omitempty"`
+	RepliedBy *primitive.ObjectID `json:"repliedBy,omitempty" bson:"replied_by,omitempty"`
+	CreatedAt time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time           `json:"updatedAt" bson:"updated_at"`
+}
+
+// ContactRequest is the contact form. Website is a honeypot field the
+// storefront hides from people; bots filling it in are ignored.
+type ContactRequest struct {
+	Name         string `json:"name" validate:"required,max=100"`
+	Email        string `json:"email" validate:"required,email,max=254"`
+	Subject      string `json:"subject" validate:"required,max=150"`
+	Message      string `json:"message" validate:"required,max=5000"`
+	Website      string `json:"website"`
+	CaptchaToken string `json:"captchaToken"`
+}
+
+// ContactStatusRequest marks a contact message as replied to, or back as new
+type ContactStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=new replied"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency product prices and order totals are stored in
+const BaseCurrency = "INR"
+
+// Currency is a currency the storefront can show prices in. Rate converts
+// from the base currency: a price of 1 INR is Rate units of this currency.
+type Currency struct {
+	Code     string  `json:"code" bson:"_id"` // ISO 4217, e.g. "USD"
+	Name     string  `json:"name" bson:"name"`
+	Symbol   string  `json:"symbol" bson:"symbol"`
+	Rate     float64 `json:"rate" bson:"rate"`
+	Decimals int     `json:"decimals" bson:"decimals"` // Minor units, e.g. 2 for cents
+	Enabled  bool    `json:"enabled" bson:"enabled"`
+	// AutoUpdate lets the exchange-rate job overwrite Rate; otherwise only admins change it
+	AutoUpdate    bool      `json:"autoUpdate" bson:"auto_update"`
+	RateUpdatedAt time.Time `json:"rateUpdatedAt" bson:"rate_updated_at"`
+	CreatedAt     time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// CurrencyRequest creates or updates the currency named in the URL
+// Example:
+// { "name": "US Dollar", "symbol": "$", "rate": 0.012, "decimals": 2, "enabled": true, "autoUpdate": true }
+type CurrencyRequest struct {
+	Name       string  `json:"name" validate:"required,max=60"`
+	Symbol     string  `json:"symbol" validate:"required,max=8"`
+	Rate       float64 `json:"rate" validate:"gt=0"`
+	Decimals   *int    `json:"decimals" validate:"omitempty,gte=0,lte=3"` // Defaults to 2
+	Enabled    bool    `json:"enabled"`
+	AutoUpdate bool    `json:"autoUpdate"`
+}
+
+// DefaultCurrency is the base currency as served when no currency is asked for
+func DefaultCurrency() Currency {
+	return Currency{Code: BaseCurrency, Name: "Indian Rupee", Symbol: "₹", Rate: 1, Decimals: 2, Enabled: true}
+}
+
+// Convert turns an amount in the base currency into this currency, rounded
+// to its minor unit
+func (c Currency) Convert(amount float64) float64 {
+	return roundTo(amount*c.Rate, c.Decimals)
+}
+
+// ToBase turns an amount in this currency back into the base currency
+func (c Currency) ToBase(amount float64) float64 {
+	if c.Rate == 0 {
+		return amount
+	}
+	return amount / c.Rate
+}
+
+// MinorUnits returns amount, already in this currency, in its smallest unit
+// (paise, cents) as payment gateways expect it
+func (c Currency) MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(c.Decimals)))
+}
+
+// Format labels an amount in this currency, e.g. "$1,299.00"
+func (c Currency) Format(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	s := strconv.FormatFloat(amount, 'f', c.Decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteString("." + frac)
+	}
+	return sign + c.Symbol + b.String()
+}
+
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	return math.Round(v*p) / p
+}
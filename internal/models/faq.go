@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FAQ is a question answered on the storefront's help page. FAQs are shown
+// grouped by category, in position order; unpublished ones only to staff.
+type FAQ struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Question  string             `json:"question" bson:"question"`
+	Answer    string             `json:"answer" bson:"answer"`
+	Category  string             `json:"category" bson:"category"`
+	Position  int                `json:"position" bson:"position"`
+	Published bool               `json:"published" bson:"published"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// FAQCategory is one category of published FAQs
+type FAQCategory struct {
+	Category string `json:"category"`
+	FAQs     []FAQ  `json:"faqs"`
+}
+
+// FAQRequest creates or updates a FAQ. FAQs are published unless
+// published is false.
+type FAQRequest struct {
+	Question  string `json:"question" validate:"required,max=300"`
+	Answer    string `json:"answer" validate:"required,max=10000"`
+	Category  string `json:"category" validate:"required,max=80"`
+	Position  int    `json:"position" validate:"gte=0"`
+	Published *bool  `json:"published,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Feature flags gating features that are still being rolled out
+const (
+	// FlagCollaborativeRecommendations serves the collaborative and hybrid
+	// recommendation strategies; users without it get preference-based ones
+	FlagCollaborativeRecommendations = "recommendations.collaborative"
+	// FlagCODVerification holds new COD orders for confirmation when
+	// COD_VERIFICATION is set; users without it skip the confirmation
+	FlagCODVerification = "checkout.cod_verification"
+)
+
+// FeatureFlag turns a feature on for a percentage of users. Each user falls
+// in a fixed bucket per flag, so raising the rollout only adds users.
+type FeatureFlag struct {
+	Key         string    `json:"key" bson:"_id"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Enabled     bool      `json:"enabled" bson:"enabled"` // Off for everyone when false, whatever the rollout
+	Rollout     int       `json:"rollout" bson:"rollout"` // Percentage of users, 0-100; anonymous requests need 100
+	CreatedAt   time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// FeatureFlagRequest creates or updates the flag named in the URL
+// Example:
+// { "description": "Co-purchase recommendations", "enabled": true, "rollout": 25 }
+type FeatureFlagRequest struct {
+	Description string `json:"description" validate:"max=200"`
+	Enabled     bool   `json:"enabled"`
+	Rollout     int    `json:"rollout" validate:"min=0,max=100"`
+}
+
+// DefaultFeatureFlags are the flags every store starts with, on for everyone
+// so features behave as they did before they were flagged
+func DefaultFeatureFlags() []FeatureFlag {
+	return []FeatureFlag{
+		{Key: FlagCollaborativeRecommendations, Description: "Co-purchase based recommendation strategies", Enabled: true, Rollout: 100},
+		{Key: FlagCODVerification, Description: "Confirm COD orders by OTP or email before fulfilment", Enabled: true, Rollout: 100},
+	}
+}
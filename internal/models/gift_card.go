@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Gift card ledger entry kinds
+const (
+	GiftCardRedeemed = "redeemed" // Spent on an order
+	GiftCardRefunded = "refunded" // Given back when the order was cancelled
+	GiftCardAdjusted = "adjusted" // Given back when the order's total went down or items were returned
+)
+
+// GiftCard is an admin-issued code whose balance pays for orders, in part
+// or in full. Balances are in the base currency.
+type GiftCard struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Code           string             `json:"code" bson:"code"` // Upper case; unique
+	InitialBalance float64            `json:"initialBalance" bson:"initial_balance"`
+	Balance        float64            `json:"balance" bson:"balance"`
+	ExpiresAt      *time.Time         `json:"expiresAt,omitempty" bson:"expires_at,omitempty"`
+	Disabled       bool               `json:"disabled" bson:"disabled"`
+	Note           string             `json:"note,omitempty" bson:"note,omitempty"` // Who it was issued to and why
+	IssuedBy       primitive.ObjectID `json:"issuedBy,omitempty" bson:"issued_by,omitempty"`
+	Ledger         []GiftCardEntry    `json:"ledger" bson:"ledger"` // Oldest first
+	CreatedAt      time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// GiftCardEntry records one use of a gift card's balance
+type GiftCardEntry struct {
+	Kind      string             `json:"kind" bson:"kind"`
+	OrderID   primitive.ObjectID `json:"orderId" bson:"order_id"`
+	Amount    float64            `json:"amount" bson:"amount"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// Usable reports whether the card can pay for an order at the given time
+func (g GiftCard) Usable(now time.Time) bool {
+	return !g.Disabled && g.Balance > 0 && (g.ExpiresAt == nil || now.Before(*g.ExpiresAt))
+}
+
+// IssueGiftCardRequest issues a gift card; the code is generated when omitted
+type IssueGiftCardRequest struct {
+	Code      string     `json:"code,omitempty" validate:"omitempty,min=6,max=32,alphanum"`
+	Amount    float64    `json:"amount" validate:"gt=0"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Note      string     `json:"note,omitempty" validate:"max=500"`
+}
+
+// UpdateGiftCardRequest disables a gift card or changes its expiry
+type UpdateGiftCardRequest struct {
+	Disabled  *bool      `json:"disabled,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Note      *string    `json:"note,omitempty" validate:"omitempty,max=500"`
+}
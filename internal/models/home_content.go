@@ -8,6 +8,7 @@ import (
 
 // HeroSlide represents the hero carousel cards rendered on the landing page
 // It mirrors the shape the frontend HeroContent component expects.
+// StartAt and EndAt schedule campaign slides; either may be left open.
 type HeroSlide struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Title       string             `bson:"title" json:"title"`
@@ -19,8 +20,20 @@ type HeroSlide struct {
 	Gradient    string             `bson:"gradient" json:"gradient"`
 	GlowColor   string             `bson:"glowColor" json:"glowColor"`
 	Position    int                `bson:"position" json:"position"`
-	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+	StartAt     *time.Time         `bson:"startAt,omitempty" json:"startAt,omitempty"`
+	EndAt       *time.Time         `bson:"endAt,omitempty" json:"endAt,omitempty"`
+	// Translations of title, subtitle and description
+	Translations Translations `bson:"translations,omitempty" json:"translations,omitempty"`
+	CreatedAt    time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time    `bson:"updatedAt" json:"updatedAt"`
+}
+
+// VisibleAt reports whether the slide is scheduled to show at t
+func (s HeroSlide) VisibleAt(t time.Time) bool {
+	if s.StartAt != nil && t.Before(*s.StartAt) {
+		return false
+	}
+	return s.EndAt == nil || t.Before(*s.EndAt)
 }
 
 // HomeCategoryCard powers the curated category tiles on the landing page.
@@ -32,8 +45,10 @@ type HomeCategoryCard struct {
 	Image      string             `bson:"image" json:"image"`
 	BgGradient string             `bson:"bgGradient" json:"bgGradient"`
 	Position   int                `bson:"position" json:"position"`
-	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// Translations of title and subtitle
+	Translations Translations `bson:"translations,omitempty" json:"translations,omitempty"`
+	CreatedAt    time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time    `bson:"updatedAt" json:"updatedAt"`
 }
 
 // HomeCollectionFeature represents the collection spotlight sections.
@@ -49,8 +64,10 @@ type HomeCollectionFeature struct {
 	ImageAlt     string             `bson:"imageAlt" json:"imageAlt"`
 	Layout       string             `bson:"layout" json:"layout"`
 	Position     int                `bson:"position" json:"position"`
-	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+	// Translations of tagline, title, description, availability, ctaLabel and imageAlt
+	Translations Translations `bson:"translations,omitempty" json:"translations,omitempty"`
+	CreatedAt    time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time    `bson:"updatedAt" json:"updatedAt"`
 }
 
 // TechShowcaseHighlight controls the short highlight banner in the tech showcase section.
@@ -83,25 +100,67 @@ type TechShowcaseCard struct {
 
 // HomeContent bundles all landing page sections for the storefront response.
 type HomeContent struct {
+	// HeroSlides holds only the slides visible when the content was built
 	HeroSlides  []HeroSlide             `json:"heroSlides"`
 	Categories  []HomeCategoryCard      `json:"categories"`
 	Collections []HomeCollectionFeature `json:"collections"`
 	TechCards   []TechShowcaseCard      `json:"techCards"`
 	Highlight   *TechShowcaseHighlight  `json:"highlight"`
+	// Gallery holds only the images visible when the content was built
+	Gallery []GalleryImage `json:"gallery"`
+	// Testimonials holds only the visible testimonials
+	Testimonials []Testimonial `json:"testimonials"`
+}
+
+// HomeContentBundle is every home page section as exported to move a
+// landing page design between environments. Unlike HomeContent it holds
+// every hero slide and gallery image, scheduled ones included, and hidden
+// testimonials too. Sections an import leaves out (or sets to null) are kept
+// as they are.
+type HomeContentBundle struct {
+	ExportedAt   time.Time               `json:"exportedAt"`
+	HeroSlides   []HeroSlide             `json:"heroSlides"`
+	Categories   []HomeCategoryCard      `json:"categories"`
+	Collections  []HomeCollectionFeature `json:"collections"`
+	TechCards    []TechShowcaseCard      `json:"techCards"`
+	Highlight    *TechShowcaseHighlight  `json:"highlight"`
+	Gallery      []GalleryImage          `json:"gallery"`
+	Testimonials []Testimonial           `json:"testimonials"`
 }
 
-// GalleryImage represents a single image in the homepage gallery section
+// Testimonial is a customer quote shown on the landing page. Hidden
+// testimonials are kept for admins but left out of the storefront.
+type Testimonial struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Author    string             `bson:"author" json:"author"`
+	Avatar    string             `bson:"avatar" json:"avatar"`
+	Quote     string             `bson:"quote" json:"quote"`
+	Rating    int                `bson:"rating" json:"rating"` // 1 to 5 stars, 0 when not rated
+	Position  int                `bson:"position" json:"position"`
+	Visible   bool               `bson:"visible" json:"visible"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// GalleryImage represents a single image in the homepage gallery section.
+// StartDate and EndDate schedule seasonal images; either may be left open.
 type GalleryImage struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Url       string             `bson:"url" json:"url"`
 	Alt       string             `bson:"alt" json:"alt"`
+	Caption   string             `bson:"caption" json:"caption"`
+	Href      string             `bson:"href" json:"href"` // Where clicking the image leads, if anywhere
 	Position  int                `bson:"position" json:"position"`
+	StartDate *time.Time         `bson:"startDate,omitempty" json:"startDate,omitempty"`
+	EndDate   *time.Time         `bson:"endDate,omitempty" json:"endDate,omitempty"`
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
-// Extend HomeContent to include gallery images (backwards compatible for existing clients not using it)
-type HomeContentWithGallery struct {
-	HomeContent
-	Gallery []GalleryImage `json:"gallery"`
+// VisibleAt reports whether the image is scheduled to show at t
+func (g GalleryImage) VisibleAt(t time.Time) bool {
+	if g.StartDate != nil && t.Before(*g.StartDate) {
+		return false
+	}
+	return g.EndDate == nil || t.Before(*g.EndDate)
 }
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MediaAsset is an image staff uploaded, kept in the media library so it
+// can be reused instead of uploaded again. URL is the file to use; URLs
+// holds every file stored for the upload, resized renditions included.
+type MediaAsset struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL         string             `json:"url" bson:"url"`
+	URLs        []string           `json:"urls" bson:"urls"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"contentType" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`     // Bytes, across every file
+	Width       int                `json:"width" bson:"width"`   // Of URL; 0 when unknown
+	Height      int                `json:"height" bson:"height"` // Of URL; 0 when unknown
+	UploadedBy  primitive.ObjectID `json:"uploadedBy,omitempty" bson:"uploaded_by,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
+}
@@ -10,7 +10,7 @@ import (
 type Notification struct {
 	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	UserID      primitive.ObjectID `json:"userId" bson:"user_id"`
-	Type        string             `json:"type" bson:"type"` // "order", "promotion", "product", "system"
+	Type        string             `json:"type" bson:"type"` // "order", "promotion", "product", "system", "support"
 	Title       string             `json:"title" bson:"title"`
 	Message     string             `json:"message" bson:"message"`
 	IsRead      bool               `json:"isRead" bson:"is_read"`
@@ -21,7 +21,7 @@ type Notification struct {
 // NotificationRequest is used for creating a notification
 type NotificationRequest struct {
 	UserID      string `json:"userId" validate:"required"`
-	Type        string `json:"type" validate:"required,oneof=order promotion product system"`
+	Type        string `json:"type" validate:"required,oneof=order promotion product system support"`
 	Title       string `json:"title" validate:"required"`
 	Message     string `json:"message" validate:"required"`
 	ReferenceID string `json:"referenceId,omitempty"`
@@ -8,13 +8,54 @@ import (
 
 // PaymentInfo represents payment information
 type PaymentInfo struct {
-	Method            string `json:"method" bson:"method"` // "razorpay", "card", "cod", etc.
+	Method            string `json:"method" bson:"method" validate:"required"` // "razorpay", "card", "cod", "offline" (paid in store), etc.
 	CardNumber        string `json:"cardNumber,omitempty" bson:"card_number,omitempty"`
 	ExpiryDate        string `json:"expiryDate,omitempty" bson:"expiry_date,omitempty"`
 	CVV               string `json:"cvv,omitempty" bson:"-"` // Never store CVV
 	RazorpayOrderID   string `json:"razorpayOrderId,omitempty" bson:"razorpay_order_id,omitempty"`
 	RazorpayPaymentID string `json:"razorpayPaymentId,omitempty" bson:"razorpay_payment_id,omitempty"`
 	RazorpaySignature string `json:"razorpaySignature,omitempty" bson:"razorpay_signature,omitempty"`
+	// Mode is the Razorpay mode ("live" or "test") the order was paid in,
+	// set by the server at checkout
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+}
+
+// COD verification methods
+const (
+	CODVerificationOTP   = "otp"   // Code sent by SMS to the shipping phone
+	CODVerificationEmail = "email" // Confirmation link sent to the account email
+	CODVerificationAdmin = "admin" // No way to reach the customer; admin approval only
+)
+
+// CODVerification tracks confirmation of a cash-on-delivery order
+type CODVerification struct {
+	Method     string     `json:"method" bson:"method"`
+	CodeHash   string     `json:"-" bson:"code_hash,omitempty"`
+	Attempts   int        `json:"-" bson:"attempts"`
+	ExpiresAt  time.Time  `json:"expiresAt" bson:"expires_at"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty" bson:"verified_at,omitempty"`
+	VerifiedBy string     `json:"verifiedBy,omitempty" bson:"verified_by,omitempty"` // "customer" or "admin"
+}
+
+// CODVerifyRequest carries the code from the SMS or the token from the email link
+type CODVerifyRequest struct {
+	Code string `json:"code" validate:"required,max=128"`
+}
+
+// Who changed an order's status
+const (
+	OrderActorCustomer = "customer"
+	OrderActorAdmin    = "admin"
+	OrderActorSystem   = "system" // Background jobs, e.g. expiring unverified COD orders
+)
+
+// OrderStatusEvent is one entry in an order's status timeline
+type OrderStatusEvent struct {
+	Status    string             `json:"status" bson:"status"`
+	Actor     string             `json:"actor" bson:"actor"`
+	ActorID   primitive.ObjectID `json:"actorId,omitempty" bson:"actor_id,omitempty"`
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
 }
 
 // OrderItem represents an item in an order
@@ -25,6 +66,13 @@ type OrderItem struct {
 	Size        string             `json:"size,omitempty" bson:"size,omitempty"`
 	Quantity    int                `json:"quantity" bson:"quantity"`
 	Subtotal    float64            `json:"subtotal" bson:"subtotal"`
+	// Ordered ahead of release; the units are taken from stock once an
+	// admin allocates them
+	Preorder bool `json:"preorder,omitempty" bson:"preorder,omitempty"`
+	// Set when staff overrode the price of a line in an order they placed:
+	// the product's price at the time and why it was changed
+	ListPrice   float64 `json:"listPrice,omitempty" bson:"list_price,omitempty"`
+	PriceReason string  `json:"priceReason,omitempty" bson:"price_reason,omitempty"`
 }
 
 // Order represents a user order
@@ -32,19 +80,117 @@ type Order struct {
 	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"` // <-- ensure json:"id"
 	UserID          primitive.ObjectID `json:"userId" bson:"user_id"`   // <-- ensure json:"userId"
 	Items           []OrderItem        `json:"items" bson:"items"`
-	Total           float64            `json:"total" bson:"total"`
+	Total           float64            `json:"total" bson:"total"`                                    // In the base currency, like item prices
+	Currency        string             `json:"currency,omitempty" bson:"currency,omitempty"`          // Currency the customer paid in; unset on older orders
+	ExchangeRate    float64            `json:"exchangeRate,omitempty" bson:"exchange_rate,omitempty"` // Units of Currency per base unit at checkout
+	ChargedTotal    float64            `json:"chargedTotal,omitempty" bson:"charged_total,omitempty"` // Total converted at ExchangeRate
 	Status          string             `json:"status" bson:"status"`
 	PaymentStatus   string             `json:"paymentStatus" bson:"payment_status"`
 	ShippingAddress Address            `json:"shippingAddress" bson:"shipping_address"`
 	PaymentInfo     PaymentInfo        `json:"paymentInfo" bson:"payment_info"`
+	CODVerification *CODVerification   `json:"codVerification,omitempty" bson:"cod_verification,omitempty"`
+	Shipment        *Shipment          `json:"shipment,omitempty" bson:"shipment,omitempty"` // Packed at checkout, for booking the carrier
+	Gift            *OrderGift         `json:"gift,omitempty" bson:"gift,omitempty"`
+	GiftCard        *OrderGiftCard     `json:"giftCard,omitempty" bson:"gift_card,omitempty"` // Paid part of the total
+	CancelReason    string             `json:"cancelReason,omitempty" bson:"cancel_reason,omitempty"`
+	DeliveredAt     *time.Time         `json:"deliveredAt,omitempty" bson:"delivered_at,omitempty"`
+	RefundedAmount  float64            `json:"refundedAmount,omitempty" bson:"refunded_amount,omitempty"` // Total refunded through returns
+	StatusHistory   []OrderStatusEvent `json:"statusHistory,omitempty" bson:"status_history,omitempty"`   // Oldest first
 	CreatedAt       time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt       time.Time          `json:"updatedAt" bson:"updated_at"`
 }
 
 // CheckoutRequest represents the data required for placing an order
 type CheckoutRequest struct {
-	UserID          string      `json:"userId" validate:"required"`
+	UserID          string      `json:"userId,omitempty"` // Ignored; the order belongs to the authenticated user
 	ShippingAddress Address     `json:"shippingAddress" validate:"required"`
 	PaymentInfo     PaymentInfo `json:"paymentInfo" validate:"required"`
-	ClientTotal     *float64    `json:"clientTotal,omitempty" bson:"-"`
+	ClientTotal     *float64    `json:"clientTotal,omitempty" bson:"-"`                // In Currency
+	Currency        string      `json:"currency,omitempty" validate:"omitempty,len=3"` // Defaults to the base currency
+
+	// Gift options; see Settings.GiftOptions for the wrap price and message limit
+	GiftWrap     bool   `json:"giftWrap,omitempty"`
+	GiftMessage  string `json:"giftMessage,omitempty" validate:"max=1000"`
+	GiftCardCode string `json:"giftCardCode,omitempty" validate:"omitempty,max=32"`
+
+	// Confirms the customer saw that the cart total changed since items were
+	// added; without it such a checkout is refused with the changed items
+	AcceptPriceChanges bool `json:"acceptPriceChanges,omitempty"`
+
+	// Cart lines to order; the rest stay in the cart. Empty orders the
+	// whole cart.
+	CartItemIDs []string `json:"cartItemIds,omitempty" validate:"max=100,dive,len=24,hexadecimal"`
+}
+
+// AdminOrderRequest is an order staff place on a customer's behalf, e.g.
+// taken over the phone or sold in a boutique. It names the customer's
+// account with UserID, or describes a walk-in customer in Customer.
+type AdminOrderRequest struct {
+	UserID   string           `json:"userId,omitempty" validate:"omitempty,len=24,hexadecimal"`
+	Customer *GuestCustomer   `json:"customer,omitempty"`
+	Items    []AdminOrderItem `json:"items" validate:"required,min=1,max=100,dive"`
+	// "offline" was paid in store; "cod" is paid on delivery
+	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=offline cod"`
+	// Without one the order was handed over in store and is placed as
+	// delivered
+	ShippingAddress *Address `json:"shippingAddress,omitempty"`
+	Note            string   `json:"note,omitempty" validate:"max=500"`
+}
+
+// GuestCustomer identifies a customer without an account by email, phone or
+// both. An account with the same email or phone is used when there is one.
+type GuestCustomer struct {
+	Name  string `json:"name" validate:"required,max=100"`
+	Email string `json:"email,omitempty" validate:"required_without=Phone,omitempty,email"`
+	Phone string `json:"phone,omitempty" validate:"required_without=Email"`
+}
+
+// AdminOrderItem is a line of an AdminOrderRequest. Price overrides the
+// product's price and needs a PriceReason.
+type AdminOrderItem struct {
+	ProductID   string   `json:"productId" validate:"required,len=24,hexadecimal"`
+	Size        string   `json:"size,omitempty"`
+	Quantity    int      `json:"quantity" validate:"required,min=1"`
+	Price       *float64 `json:"price,omitempty" validate:"omitempty,gte=0"`
+	PriceReason string   `json:"priceReason,omitempty" validate:"required_with=Price,max=500"`
+}
+
+// OrderEditRequest corrects an order before it is processed: a new
+// shipping address and/or lower quantities for some of its lines
+type OrderEditRequest struct {
+	ShippingAddress *Address        `json:"shippingAddress,omitempty"`
+	Items           []OrderItemEdit `json:"items,omitempty" validate:"max=100,dive"`
+}
+
+// OrderItemEdit lowers the quantity of the order line for a product and
+// size; 0 drops the line
+type OrderItemEdit struct {
+	ProductID string `json:"productId" validate:"required,len=24,hexadecimal"`
+	Size      string `json:"size,omitempty"`
+	Quantity  int    `json:"quantity" validate:"min=0"`
+}
+
+// BuyNowRequest is the product a buy-now checkout orders. The rest of the
+// body is a CheckoutRequest, whose cartItemIds are ignored.
+type BuyNowRequest struct {
+	ProductID string `json:"productId" validate:"required,len=24,hexadecimal"`
+	Size      string `json:"size,omitempty" validate:"max=50"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// OrderGift is the gift wrapping and message of an order. WrapPrice is in
+// the base currency and included in the order total.
+type OrderGift struct {
+	Wrap      bool    `json:"wrap" bson:"wrap"`
+	WrapPrice float64 `json:"wrapPrice" bson:"wrap_price"`
+	Message   string  `json:"message,omitempty" bson:"message,omitempty"`
+}
+
+// OrderGiftCard is the part of an order's total paid by gift card, in the
+// base currency. The rest is charged as ChargedTotal.
+type OrderGiftCard struct {
+	Code   string  `json:"code" bson:"code"`
+	Amount float64 `json:"amount" bson:"amount"`
+	// Given back to the card by refunded returns, and no longer in Amount
+	Returned float64 `json:"returned,omitempty" bson:"returned,omitempty"`
 }
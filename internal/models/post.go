@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Post statuses
+const (
+	PostDraft     = "draft"
+	PostPublished = "published"
+)
+
+// Post is a journal article. Published posts appear on the storefront from
+// their publish date, so articles can be scheduled ahead.
+type Post struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Title       string              `json:"title" bson:"title"`
+	Slug        string              `json:"slug" bson:"slug"`
+	Excerpt     string              `json:"excerpt,omitempty" bson:"excerpt,omitempty"`
+	Body        string              `json:"body,omitempty" bson:"body"` // HTML written by staff; listings leave it out
+	CoverImage  string              `json:"coverImage,omitempty" bson:"cover_image,omitempty"`
+	Tags        []string            `json:"tags" bson:"tags"`
+	Status      string              `json:"status" bson:"status"`
+	PublishedAt *time.Time          `json:"publishedAt,omitempty" bson:"published_at,omitempty"`
+	AuthorID    *primitive.ObjectID `json:"authorId,omitempty" bson:"author_id,omitempty"`
+	SEO         *SEO                `json:"seo,omitempty" bson:"seo,omitempty"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt   time.Time           `json:"updatedAt" bson:"updated_at"`
+}
+
+// Live reports whether the post is shown on the storefront at now
+func (p Post) Live(now time.Time) bool {
+	return p.Status == PostPublished && p.PublishedAt != nil && !p.PublishedAt.After(now)
+}
+
+// PostRequest creates or updates a post. The slug is derived from the
+// title when omitted; publishing without a publish date publishes now.
+type PostRequest struct {
+	Title       string     `json:"title" validate:"required,max=200"`
+	Slug        string     `json:"slug,omitempty" validate:"omitempty,max=200"`
+	Excerpt     string     `json:"excerpt,omitempty" validate:"max=500"`
+	Body        string     `json:"body" validate:"max=200000"`
+	CoverImage  string     `json:"coverImage,omitempty" validate:"omitempty,url"`
+	Tags        []string   `json:"tags" validate:"max=20,dive,max=40"`
+	Status      string     `json:"status" validate:"required,oneof=draft published"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	SEO         *SEO       `json:"seo,omitempty"`
+}
+
+// NormalizeTags lower-cases and trims tags, dropping empty and repeated ones
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
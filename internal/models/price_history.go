@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Price change reasons. Product edits record created and update entries;
+// the campaign scheduler records campaign_start and campaign_end.
+const (
+	PriceChangeCreated       = "created"
+	PriceChangeUpdate        = "update"
+	PriceChangeCampaignStart = "campaign_start"
+	PriceChangeCampaignEnd   = "campaign_end"
+)
+
+// PriceHistoryEntry is a product's pricing from the time it was recorded
+// until the next entry
+type PriceHistoryEntry struct {
+	ID                 primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	ProductID          primitive.ObjectID  `json:"productId" bson:"product_id"`
+	Price              float64             `json:"price" bson:"price"`
+	FinalPrice         float64             `json:"finalPrice" bson:"final_price"` // Price after any discount active when recorded
+	DiscountPercentage *float64            `json:"discountPercentage,omitempty" bson:"discount_percentage,omitempty"`
+	DiscountAmount     *float64            `json:"discountAmount,omitempty" bson:"discount_amount,omitempty"`
+	DiscountStartDate  *time.Time          `json:"discountStartDate,omitempty" bson:"discount_start_date,omitempty"`
+	DiscountEndDate    *time.Time          `json:"discountEndDate,omitempty" bson:"discount_end_date,omitempty"`
+	Reason             string              `json:"reason" bson:"reason"`
+	CampaignID         *primitive.ObjectID `json:"campaignId,omitempty" bson:"campaign_id,omitempty"`
+	ActorID            primitive.ObjectID  `json:"actorId,omitempty" bson:"actor_id,omitempty"`
+	CreatedAt          time.Time           `json:"createdAt" bson:"created_at"`
+}
+
+// NewPriceHistoryEntry records the current pricing of p
+func NewPriceHistoryEntry(p Product, reason string) PriceHistoryEntry {
+	return PriceHistoryEntry{
+		ProductID:          p.ID,
+		Price:              p.Price,
+		FinalPrice:         p.GetFinalPrice(),
+		DiscountPercentage: p.DiscountPercentage,
+		DiscountAmount:     p.DiscountAmount,
+		DiscountStartDate:  p.DiscountStartDate,
+		DiscountEndDate:    p.DiscountEndDate,
+		Reason:             reason,
+		CampaignID:         p.CampaignID,
+	}
+}
+
+// LowestPriceBetween returns the lowest price charged under this pricing
+// between from and to. Its discount counts if its dates overlap the period.
+func (e PriceHistoryEntry) LowestPriceBetween(from, to time.Time) float64 {
+	if e.DiscountStartDate != nil && !e.DiscountStartDate.Before(to) {
+		return e.Price
+	}
+	if e.DiscountEndDate != nil && e.DiscountEndDate.Before(from) {
+		return e.Price
+	}
+	// Without its dates the discount is active throughout
+	undated := Product{Price: e.Price, DiscountPercentage: e.DiscountPercentage, DiscountAmount: e.DiscountAmount}
+	return undated.GetFinalPrice()
+}
+
+// PriceChanged reports whether the price or discount of a product differs
+// between before and after
+func PriceChanged(before, after Product) bool {
+	return before.Price != after.Price ||
+		!equalFloatPtr(before.DiscountPercentage, after.DiscountPercentage) ||
+		!equalFloatPtr(before.DiscountAmount, after.DiscountAmount) ||
+		!equalTimePtr(before.DiscountStartDate, after.DiscountStartDate) ||
+		!equalTimePtr(before.DiscountEndDate, after.DiscountEndDate)
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
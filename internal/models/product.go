@@ -1,24 +1,29 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Product represents a product in the system
 type Product struct {
 	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name         string             `json:"name" bson:"name"`
+	Name         string             `json:"name" bson:"name" validate:"required"`
+	Slug         string             `json:"slug,omitempty" bson:"slug,omitempty"` // Set from the name on save; see ProductSlug
 	Brand        string             `json:"brand,omitempty" bson:"brand,omitempty"`
-	Description  string             `json:"description" bson:"description"`
-	Price        float64            `json:"price" bson:"price"`
-	Category     string             `json:"category" bson:"category"`
+	Description  string             `json:"description" bson:"description" validate:"required"`
+	Price        float64            `json:"price" bson:"price" validate:"gt=0"`
+	Category     string             `json:"category" bson:"category" validate:"required"`
 	MainCategory string             `json:"mainCategory,omitempty" bson:"main_category,omitempty"`
 	Subcategory  string             `json:"subcategory,omitempty" bson:"subcategory,omitempty"`
 	ImageURL     string             `json:"imageUrl" bson:"image_url"` // Main image (legacy support)
 	Images       []string           `json:"images" bson:"images"`      // Multiple S3 image URLs
-	Stock        int                `json:"stock" bson:"stock"`
+	Stock        int                `json:"stock" bson:"stock" validate:"gte=0"`
+	Translations Translations       `json:"translations,omitempty" bson:"translations,omitempty"` // Of name and description; see Localize
 	// Optional filterable attributes (for dynamic filters)
 	Gender        string `json:"gender,omitempty" bson:"gender,omitempty"`
 	DialColor     string `json:"dialColor,omitempty" bson:"dial_color,omitempty"`
@@ -28,13 +33,60 @@ type Product struct {
 	StrapMaterial string `json:"strapMaterial,omitempty" bson:"strap_material,omitempty"`
 	Style         string `json:"style,omitempty" bson:"style,omitempty"`
 	DialThickness string `json:"dialThickness,omitempty" bson:"dial_thickness,omitempty"`
+
+	// Watch specifications, filterable like the attributes above
+	Movement        string `json:"movement,omitempty" bson:"movement,omitempty"`                // e.g. "Automatic", "Quartz"
+	WaterResistance string `json:"waterResistance,omitempty" bson:"water_resistance,omitempty"` // e.g. "100m"
+	CaseSize        string `json:"caseSize,omitempty" bson:"case_size,omitempty"`               // e.g. "42mm"
+
+	// Packed for shipping, box included; see PackShipment
+	WeightGrams float64     `json:"weightGrams,omitempty" bson:"weight_grams,omitempty" validate:"omitempty,gt=0,lte=50000"`
+	Dimensions  *Dimensions `json:"dimensions,omitempty" bson:"dimensions,omitempty"`
+
+	// Low-stock alerting; a nil threshold falls back to LOW_STOCK_THRESHOLD
+	ReorderThreshold  *int       `json:"reorderThreshold,omitempty" bson:"reorder_threshold,omitempty" validate:"omitempty,gte=0"`
+	LowStockAlertedAt *time.Time `json:"-" bson:"low_stock_alerted_at,omitempty"` // Set once admins were alerted; cleared on restock
+	// Purchase limits; a nil MaxPerOrder falls back to MAX_QUANTITY_PER_ORDER.
+	// MaxPerCustomer caps the units one customer may ever buy, for limited
+	// editions. Saving 0 removes a limit.
+	MaxPerOrder    *int `json:"maxPerOrder,omitempty" bson:"max_per_order,omitempty" validate:"omitempty,gte=0"`
+	MaxPerCustomer *int `json:"maxPerCustomer,omitempty" bson:"max_per_customer,omitempty" validate:"omitempty,gte=0"`
+
+	// Upcoming releases. Until ReleaseDate a product can only be ordered
+	// when it allows pre-orders; see TakesPreorders. PreorderCount is the
+	// units on pre-order not yet allocated from stock.
+	ReleaseDate   *time.Time `json:"releaseDate,omitempty" bson:"release_date,omitempty"`
+	AllowPreorder bool       `json:"allowPreorder,omitempty" bson:"allow_preorder,omitempty"`
+	PreorderCount int        `json:"preorderCount,omitempty" bson:"preorder_count,omitempty"`
+
+	// Warranty issued for each unit on delivery; 0 issues none
+	WarrantyMonths int `json:"warrantyMonths,omitempty" bson:"warranty_months,omitempty" validate:"omitempty,gte=0,lte=240"`
 	// Discount fields (optional)
-	DiscountPercentage *float64   `json:"discountPercentage,omitempty" bson:"discount_percentage,omitempty"` // Percentage discount (0-100)
-	DiscountAmount     *float64   `json:"discountAmount,omitempty" bson:"discount_amount,omitempty"`         // Fixed amount discount
-	DiscountStartDate  *time.Time `json:"discountStartDate,omitempty" bson:"discount_start_date,omitempty"`  // When discount starts
-	DiscountEndDate    *time.Time `json:"discountEndDate,omitempty" bson:"discount_end_date,omitempty"`      // When discount ends
-	CreatedAt          time.Time  `json:"createdAt" bson:"created_at"`
-	UpdatedAt          time.Time  `json:"updatedAt" bson:"updated_at"`
+	DiscountPercentage *float64            `json:"discountPercentage,omitempty" bson:"discount_percentage,omitempty" validate:"omitempty,gte=0,lte=100"` // Percentage discount (0-100)
+	DiscountAmount     *float64            `json:"discountAmount,omitempty" bson:"discount_amount,omitempty" validate:"omitempty,gte=0"`                 // Fixed amount discount
+	DiscountStartDate  *time.Time          `json:"discountStartDate,omitempty" bson:"discount_start_date,omitempty"`                                     // When discount starts
+	DiscountEndDate    *time.Time          `json:"discountEndDate,omitempty" bson:"discount_end_date,omitempty"`                                         // When discount ends
+	CampaignID         *primitive.ObjectID `json:"campaignId,omitempty" bson:"campaign_id,omitempty"`                                                    // Set while a campaign owns the discount fields
+	CreatedAt          time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt          time.Time           `json:"updatedAt" bson:"updated_at"`
+
+	SEO *SEO `json:"seo,omitempty" bson:"seo,omitempty"` // Overrides of the product page's meta tags
+
+	// Maintained from product page views; see the productviews package
+	ViewCount  int64 `json:"viewCount,omitempty" bson:"view_count,omitempty"`
+	Popularity int64 `json:"popularity,omitempty" bson:"popularity,omitempty"` // Views over the recent window; unset when there were none
+}
+
+// ProductSlug builds the URL slug of a product from its name and a short
+// hash of its ID, e.g. "seiko-presage-cocktail-time-3f9a1c2b". The hash keeps
+// slugs unique when products share a name.
+func ProductSlug(name string, id primitive.ObjectID) string {
+	sum := sha256.Sum256(id[:])
+	hash := hex.EncodeToString(sum[:4])
+	if slug := Slugify(name); slug != "" {
+		return slug + "-" + hash
+	}
+	return hash
 }
 
 // IsDiscountActive checks if the product has an active discount
@@ -89,6 +141,43 @@ func (p *Product) GetDiscountAmount() float64 {
 	return p.Price - p.GetFinalPrice()
 }
 
+// EffectiveReorderThreshold returns the product's reorder threshold, or
+// defaultThreshold when none is set
+func (p *Product) EffectiveReorderThreshold(defaultThreshold int) int {
+	if p.ReorderThreshold != nil {
+		return *p.ReorderThreshold
+	}
+	return defaultThreshold
+}
+
+// EffectiveMaxPerOrder returns the most units of the product one order may
+// hold, or defaultLimit when the product sets none; 0 means no limit
+func (p *Product) EffectiveMaxPerOrder(defaultLimit int) int {
+	if p.MaxPerOrder != nil && *p.MaxPerOrder > 0 {
+		return *p.MaxPerOrder
+	}
+	return defaultLimit
+}
+
+// Released reports whether the product's release date, if it has one, has
+// passed
+func (p *Product) Released(now time.Time) bool {
+	return p.ReleaseDate == nil || !now.Before(*p.ReleaseDate)
+}
+
+// TakesPreorders reports whether orders for the product are pre-orders now,
+// taken without stock: it allows them and its release date, if set, is
+// still to come
+func (p *Product) TakesPreorders(now time.Time) bool {
+	return p.AllowPreorder && (p.ReleaseDate == nil || now.Before(*p.ReleaseDate))
+}
+
+// LowStockExpr is an aggregation expression that is true when a product's
+// stock is at or below its reorder threshold
+func LowStockExpr(defaultThreshold int) bson.M {
+	return bson.M{"$lte": bson.A{"$stock", bson.M{"$ifNull": bson.A{"$reorder_threshold", defaultThreshold}}}}
+}
+
 // ProductFilters represents filters for product queries
 type ProductFilters struct {
 	Category string   `query:"category"`
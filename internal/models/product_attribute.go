@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ProductAttributeFields maps the keys of the filterable product attributes,
+// which are also their JSON names and catalog query params, to the fields
+// holding them
+var ProductAttributeFields = map[string]string{
+	"gender":          "gender",
+	"dialColor":       "dial_color",
+	"dialShape":       "dial_shape",
+	"dialType":        "dial_type",
+	"strapColor":      "strap_color",
+	"strapMaterial":   "strap_material",
+	"style":           "style",
+	"dialThickness":   "dial_thickness",
+	"movement":        "movement",
+	"waterResistance": "water_resistance",
+	"caseSize":        "case_size",
+}
+
+// ProductAttribute is the controlled vocabulary of a product attribute.
+// Products may only use its values, matched ignoring case and saved as
+// written here; attributes without one accept any value.
+type ProductAttribute struct {
+	Key       string    `json:"key" bson:"_id"` // One of ProductAttributeFields
+	Label     string    `json:"label" bson:"label"`
+	Values    []string  `json:"values" bson:"values"` // In display order
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// Match returns the allowed value equal to value ignoring case and
+// surrounding space
+func (a ProductAttribute) Match(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	for _, allowed := range a.Values {
+		if strings.EqualFold(allowed, value) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// ProductAttributeRequest sets the vocabulary of the attribute named in the URL
+// Example:
+// { "label": "Strap material", "values": ["Leather", "Stainless Steel", "Rubber"] }
+type ProductAttributeRequest struct {
+	Label  string   `json:"label" validate:"max=60"`
+	Values []string `json:"values" validate:"required,min=1,max=200,dive,required,max=60"`
+}
+
+// Attributes returns pointers to the product's filterable attributes by key
+func (p *Product) Attributes() map[string]*string {
+	return map[string]*string{
+		"gender":          &p.Gender,
+		"dialColor":       &p.DialColor,
+		"dialShape":       &p.DialShape,
+		"dialType":        &p.DialType,
+		"strapColor":      &p.StrapColor,
+		"strapMaterial":   &p.StrapMaterial,
+		"style":           &p.Style,
+		"dialThickness":   &p.DialThickness,
+		"movement":        &p.Movement,
+		"waterResistance": &p.WaterResistance,
+		"caseSize":        &p.CaseSize,
+	}
+}
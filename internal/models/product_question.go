@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductQuestion is a customer's question about a product, answered by the
+// store
+type ProductQuestion struct {
+	ID         primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	ProductID  primitive.ObjectID   `json:"productId" bson:"product_id"`
+	UserID     primitive.ObjectID   `json:"userId" bson:"user_id"`
+	Question   string               `json:"question" bson:"question"`
+	Answer     string               `json:"answer,omitempty" bson:"answer,omitempty"`
+	AnsweredBy primitive.ObjectID   `json:"-" bson:"answered_by,omitempty"`
+	AnsweredAt *time.Time           `json:"answeredAt,omitempty" bson:"answered_at,omitempty"`
+	Upvotes    int                  `json:"upvotes" bson:"upvotes"`
+	UpvotedBy  []primitive.ObjectID `json:"-" bson:"upvoted_by,omitempty"` // One upvote per user
+	CreatedAt  time.Time            `json:"createdAt" bson:"created_at"`
+	UpdatedAt  time.Time            `json:"updatedAt" bson:"updated_at"`
+}
+
+// ProductQuestionResponse is a question with the asker's display name
+type ProductQuestionResponse struct {
+	ProductQuestion
+	UserName string `json:"userName"`
+}
+
+// ProductQuestionRequest is the body of POST /products/:productId/questions
+type ProductQuestionRequest struct {
+	Question string `json:"question" validate:"required,min=10,max=500"`
+}
+
+// ProductAnswerRequest is the body of PUT /admin/questions/:id/answer
+type ProductAnswerRequest struct {
+	Answer string `json:"answer" validate:"required,max=2000"`
+}
@@ -15,6 +15,16 @@ const (
 	SourceSimilarUsers    RecommendationSource = "similar_users"
 	SourceTrending        RecommendationSource = "trending"
 	SourceFavorites       RecommendationSource = "favorites"
+	// SourceCoPurchase items are product-to-product scores ("customers who
+	// bought X also bought Y") built offline from orders and feedback
+	SourceCoPurchase RecommendationSource = "co_purchase"
+)
+
+// Recommendation strategies accepted by GetRecommendations
+const (
+	StrategyPreferences   = "preferences"   // Static preference filters only
+	StrategyCollaborative = "collaborative" // Co-purchase scores only
+	StrategyHybrid        = "hybrid"        // Co-purchase scores re-ranked by preferences
 )
 
 // RecommendationFeedback represents user feedback on recommendations
@@ -27,15 +37,17 @@ type RecommendationFeedback struct {
 	CreatedAt       time.Time            `json:"createdAt" bson:"created_at"`
 }
 
-// RecommendationItem represents a single recommendation
+// RecommendationItem represents a single recommendation. Co-purchase items
+// have no user; they relate ProductID to BasisProductID.
 type RecommendationItem struct {
-	ID         primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
-	UserID     primitive.ObjectID   `json:"userId" bson:"user_id"`
-	ProductID  primitive.ObjectID   `json:"productId" bson:"product_id"`
-	Score      float64              `json:"score" bson:"score"`
-	Source     RecommendationSource `json:"source" bson:"source"`
-	Reason     string               `json:"reason" bson:"reason"`
-	CreatedAt  time.Time            `json:"createdAt" bson:"created_at"`
+	ID             primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID   `json:"userId,omitempty" bson:"user_id,omitempty"`
+	BasisProductID primitive.ObjectID   `json:"basisProductId,omitempty" bson:"basis_product_id,omitempty"`
+	ProductID      primitive.ObjectID   `json:"productId" bson:"product_id"`
+	Score          float64              `json:"score" bson:"score"`
+	Source         RecommendationSource `json:"source" bson:"source"`
+	Reason         string               `json:"reason" bson:"reason"`
+	CreatedAt      time.Time            `json:"createdAt" bson:"created_at"`
 }
 
 // RecommendationResponse represents a recommendation with product details
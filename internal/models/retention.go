@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RetentionStats is how much the pruning job has purged from a collection
+type RetentionStats struct {
+	Collection  string    `json:"collection" bson:"_id"`
+	LastRunAt   time.Time `json:"lastRunAt" bson:"last_run_at"`
+	LastPurged  int64     `json:"lastPurged" bson:"last_purged"`
+	TotalPurged int64     `json:"totalPurged" bson:"total_purged"`
+}
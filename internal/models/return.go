@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Return lifecycle. A request is approved or rejected by an admin; approved
+// returns are picked up and then refunded, which restores stock and
+// completes the return.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusPickedUp  = "picked_up"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// ReturnTransitions lists the statuses each status may move to
+var ReturnTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusPickedUp, ReturnStatusRejected},
+	ReturnStatusPickedUp:  {ReturnStatusRefunded},
+}
+
+// Refund methods recorded when a return is refunded
+const (
+	RefundMethodRazorpay = "razorpay"  // Refunded through the gateway
+	RefundMethodManual   = "manual"    // COD or gateway not configured; settled outside the system
+	RefundMethodGiftCard = "gift_card" // Given back in full to the gift card the order was paid with
+)
+
+// ReturnItem is one order line, or part of one, being sent back
+type ReturnItem struct {
+	ProductID   primitive.ObjectID `json:"productId" bson:"product_id"`
+	ProductName string             `json:"productName" bson:"product_name"`
+	Size        string             `json:"size,omitempty" bson:"size,omitempty"`
+	Price       float64            `json:"price" bson:"price"`
+	Quantity    int                `json:"quantity" bson:"quantity"`
+	Subtotal    float64            `json:"subtotal" bson:"subtotal"`
+}
+
+// ReturnEvent records one status change of a return
+type ReturnEvent struct {
+	Status string             `json:"status" bson:"status"`
+	Note   string             `json:"note,omitempty" bson:"note,omitempty"`
+	By     primitive.ObjectID `json:"by,omitempty" bson:"by,omitempty"`
+	At     time.Time          `json:"at" bson:"at"`
+}
+
+// Return is a customer's request to send back items from a delivered order
+type Return struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderID      primitive.ObjectID `json:"orderId" bson:"order_id"`
+	UserID       primitive.ObjectID `json:"userId" bson:"user_id"`
+	Items        []ReturnItem       `json:"items" bson:"items"`
+	Reason       string             `json:"reason" bson:"reason"`
+	Comment      string             `json:"comment,omitempty" bson:"comment,omitempty"`
+	PhotoURLs    []string           `json:"photoUrls,omitempty" bson:"photo_urls,omitempty"`
+	Status       string             `json:"status" bson:"status"`
+	RefundAmount float64            `json:"refundAmount" bson:"refund_amount"`
+	RefundMethod string             `json:"refundMethod,omitempty" bson:"refund_method,omitempty"`
+	RefundID     string             `json:"refundId,omitempty" bson:"refund_id,omitempty"` // Gateway refund reference
+	History      []ReturnEvent      `json:"history" bson:"history"`
+	RefundedAt   *time.Time         `json:"refundedAt,omitempty" bson:"refunded_at,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updated_at"`
+
+	// Part of RefundAmount given back to the order's gift card; the rest
+	// went through RefundMethod
+	GiftCardAmount float64 `json:"giftCardAmount,omitempty" bson:"gift_card_amount,omitempty"`
+}
+
+// ReturnItemRequest selects a quantity of one order line
+type ReturnItemRequest struct {
+	ProductID string `json:"productId" validate:"required,len=24,hexadecimal"`
+	Size      string `json:"size,omitempty"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// CreateReturnRequest is the body of POST /orders/:orderID/return
+type CreateReturnRequest struct {
+	Reason    string              `json:"reason" validate:"required,oneof=damaged defective wrong_item not_as_described size_issue changed_mind other"`
+	Comment   string              `json:"comment,omitempty" validate:"max=1000"`
+	Items     []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+	PhotoURLs []string            `json:"photoUrls,omitempty" validate:"max=5,dive,url"`
+}
+
+// ReturnStatusRequest moves a return along its lifecycle
+type ReturnStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected picked_up refunded"`
+	Note   string `json:"note,omitempty" validate:"max=500"`
+}
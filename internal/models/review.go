@@ -16,6 +16,8 @@ type Review struct {
 	Comment     string             `json:"comment" bson:"comment"`
 	PhotoURLs   []string           `json:"photoUrls,omitempty" bson:"photo_urls,omitempty"`
 	Helpful     int                `json:"helpful" bson:"helpful"`
+	NotHelpful  int                `json:"notHelpful" bson:"not_helpful"`
+	Reply       *ReviewReply       `json:"reply,omitempty" bson:"reply,omitempty"` // The store's response, if any
 	Verified    bool               `json:"verified" bson:"verified"`
 	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt   time.Time          `json:"updatedAt" bson:"updated_at"`
@@ -47,8 +49,30 @@ type ReviewResponse struct {
 
 // ReviewSummary represents the summary of reviews for a product
 type ReviewSummary struct {
-	ProductID    primitive.ObjectID `json:"productId"`
-	AverageRating float64           `json:"averageRating"`
-	TotalReviews  int               `json:"totalReviews"`
-	RatingCounts  map[int]int       `json:"ratingCounts"` // map of rating to count
+	ProductID          primitive.ObjectID `json:"productId"`
+	AverageRating      float64            `json:"averageRating"`
+	TotalReviews       int                `json:"totalReviews"`
+	RatingCounts       map[int]int        `json:"ratingCounts"`       // Reviews per star, 1 to 5, ratings rounded half up
+	VerifiedPercentage float64            `json:"verifiedPercentage"` // Of the reviews, from verified purchases
+	Keywords           []ReviewKeyword    `json:"keywords"`           // Most mentioned first
+}
+
+// ReviewKeyword is a word used in reviews and how many reviews use it
+type ReviewKeyword struct {
+	Word    string `json:"word" bson:"_id"`
+	Reviews int    `json:"reviews" bson:"reviews"`
+}
+
+// ReviewReply is the store's public response to a review
+type ReviewReply struct {
+	Text       string             `json:"text" bson:"text"`
+	AuthorID   primitive.ObjectID `json:"-" bson:"author_id"`
+	AuthorName string             `json:"author" bson:"author_name"` // The staff member who last wrote it
+	CreatedAt  time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// ReviewReplyRequest is the body of POST and PUT /admin/reviews/:id/reply
+type ReviewReplyRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
 }
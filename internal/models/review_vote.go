@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Review votes
+const (
+	ReviewVoteHelpful    = "helpful"
+	ReviewVoteNotHelpful = "not_helpful"
+)
+
+// ReviewVoteFields maps each vote to the review counter it is tallied in
+var ReviewVoteFields = map[string]string{
+	ReviewVoteHelpful:    "helpful",
+	ReviewVoteNotHelpful: "not_helpful",
+}
+
+// ReviewVote is one user's vote on a review; a user has at most one per
+// review
+type ReviewVote struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ReviewID  primitive.ObjectID `json:"reviewId" bson:"review_id"`
+	UserID    primitive.ObjectID `json:"userId" bson:"user_id"`
+	Vote      string             `json:"vote" bson:"vote"` // ReviewVoteHelpful or ReviewVoteNotHelpful
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updated_at"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Built-in roles. Every other role is a staff role defined by admins.
+const (
+	RoleAdmin = "admin" // Holds every permission; cannot be changed
+	RoleUser  = "user"  // Customers; holds no permissions
+)
+
+// Permissions a role can grant. PermissionAll grants every one of them.
+const (
+	PermissionAll            = "*"
+	PermissionOrdersRead     = "orders:read"     // Any customer's orders and returns, the live order feed
+	PermissionOrdersWrite    = "orders:write"    // Order statuses, COD approval, returns, gift cards, warranties
+	PermissionProductsWrite  = "products:write"  // Products, stock, categories, brands, campaigns, Q&A, review replies
+	PermissionContentWrite   = "content:write"   // Home page content, journal posts, FAQs and stores
+	PermissionCustomersRead  = "customers:read"  // Accounts and carts
+	PermissionCustomersWrite = "customers:write" // Suspending, unlocking, deleting and impersonating accounts
+	PermissionSettingsWrite  = "settings:write"  // Store settings, currencies, webhooks, API keys, feature flags, jobs, storage
+	PermissionAuditRead      = "audit:read"
+	PermissionRolesWrite     = "roles:write"   // Roles and who holds them
+	PermissionSupportWrite   = "support:write" // Support tickets and contact messages
+)
+
+// Permissions lists every permission a role can be granted
+var Permissions = []string{
+	PermissionOrdersRead,
+	PermissionOrdersWrite,
+	PermissionProductsWrite,
+	PermissionContentWrite,
+	PermissionCustomersRead,
+	PermissionCustomersWrite,
+	PermissionSettingsWrite,
+	PermissionAuditRead,
+	PermissionRolesWrite,
+	PermissionSupportWrite,
+}
+
+// Role is a named set of permissions. Accounts hold one role, by name, in
+// their role field; any role but "user" makes the account staff.
+type Role struct {
+	Name        string    `json:"name" bson:"_id"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Permissions []string  `json:"permissions" bson:"permissions"`
+	BuiltIn     bool      `json:"builtIn" bson:"built_in"` // admin and user can't be changed or deleted
+	CreatedAt   time.Time `json:"createdAt" bson:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updated_at"`
+}
+
+// Has reports whether the role grants permission
+func (r Role) Has(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission || p == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleRequest creates or updates the role named in the URL
+// Example:
+// { "description": "Packs and ships orders", "permissions": ["orders:read", "orders:write"] }
+type RoleRequest struct {
+	Description string   `json:"description" validate:"max=200"`
+	Permissions []string `json:"permissions" validate:"dive,oneof=orders:read orders:write products:write content:write customers:read customers:write settings:write audit:read roles:write support:write"`
+}
+
+// DefaultRoles are the roles every store starts with. Staff and editor are
+// starting points admins may change.
+func DefaultRoles() []Role {
+	return []Role{
+		{Name: RoleAdmin, Description: "Full access", Permissions: []string{PermissionAll}, BuiltIn: true},
+		{Name: RoleUser, Description: "Customer", Permissions: []string{}, BuiltIn: true},
+		{Name: "staff", Description: "Manages orders and returns", Permissions: []string{PermissionOrdersRead, PermissionOrdersWrite, PermissionCustomersRead}},
+		{Name: "editor", Description: "Edits home page content, journal posts, FAQs and stores", Permissions: []string{PermissionContentWrite}},
+	}
+}
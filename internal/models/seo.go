@@ -0,0 +1,66 @@
+package models
+
+import "strings"
+
+// SEO is the search-engine metadata of a storefront page. Empty fields fall
+// back to the page's own name and URL; see WithDefaults.
+type SEO struct {
+	MetaTitle       string `json:"metaTitle,omitempty" bson:"meta_title,omitempty" validate:"omitempty,max=120"`
+	MetaDescription string `json:"metaDescription,omitempty" bson:"meta_description,omitempty" validate:"omitempty,max=320"`
+	// CanonicalURL points search engines at the page to index when several
+	// show the same product, e.g. a watch listed in two colours
+	CanonicalURL string `json:"canonicalUrl,omitempty" bson:"canonical_url,omitempty" validate:"omitempty,url,max=2048"`
+}
+
+// WithDefaults fills the empty fields of s (which may be nil) with the
+// page's title, description and URL
+func (s *SEO) WithDefaults(title, description, url string) SEO {
+	var out SEO
+	if s != nil {
+		out = *s
+	}
+	if out.MetaTitle == "" {
+		out.MetaTitle = title
+	}
+	if out.MetaDescription == "" {
+		out.MetaDescription = description
+	}
+	if out.CanonicalURL == "" {
+		out.CanonicalURL = url
+	}
+	return out
+}
+
+// Clean trims the fields of s and returns nil when none is set, so pages
+// without overrides store no SEO document
+func (s *SEO) Clean() *SEO {
+	if s == nil {
+		return nil
+	}
+	out := SEO{
+		MetaTitle:       strings.TrimSpace(s.MetaTitle),
+		MetaDescription: strings.TrimSpace(s.MetaDescription),
+		CanonicalURL:    strings.TrimSpace(s.CanonicalURL),
+	}
+	if out == (SEO{}) {
+		return nil
+	}
+	return &out
+}
+
+// maxMetaDescription is about what search engines show of a description
+const maxMetaDescription = 160
+
+// MetaDescription shortens text to a meta description, cutting at a word
+// boundary
+func MetaDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len([]rune(text)) <= maxMetaDescription {
+		return text
+	}
+	runes := []rune(text)[:maxMetaDescription-1]
+	if i := strings.LastIndex(string(runes), " "); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SerialNumber is a genuine serial number of a product, as supplied by the
+// brand. Customers check a watch against the registry before or after
+// buying it; the first signed-in customer to check a serial becomes its
+// registered owner.
+type SerialNumber struct {
+	ID             primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Serial         string              `json:"serialNumber" bson:"serial"`
+	ProductID      primitive.ObjectID  `json:"productId" bson:"product_id"`
+	RegisteredTo   *primitive.ObjectID `json:"registeredTo,omitempty" bson:"registered_to,omitempty"`
+	RegisteredAt   *time.Time          `json:"registeredAt,omitempty" bson:"registered_at,omitempty"`
+	Verifications  int                 `json:"verifications" bson:"verifications"`
+	LastVerifiedAt *time.Time          `json:"lastVerifiedAt,omitempty" bson:"last_verified_at,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt" bson:"created_at"`
+}
+
+// NormalizeSerial returns the form serial numbers are stored and matched in
+func NormalizeSerial(serial string) string {
+	return strings.ToUpper(strings.TrimSpace(serial))
+}
+
+// SerialNumbersRequest adds genuine serial numbers to a product
+type SerialNumbersRequest struct {
+	Serials []string `json:"serials" validate:"required,min=1,max=500,dive,required,max=64"`
+}
+
+// AuthenticityRequest checks a serial number against the registry
+type AuthenticityRequest struct {
+	SerialNumber string `json:"serialNumber" validate:"required,max=64"`
+}
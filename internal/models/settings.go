@@ -18,7 +18,10 @@ type Settings struct {
 	Currency           string             `json:"currency" bson:"currency"`
 	TaxRate            float64            `json:"taxRate" bson:"tax_rate"`
 	ShippingMethods    []ShippingMethod   `json:"shippingMethods" bson:"shipping_methods"`
+	GiftOptions        GiftOptions        `json:"giftOptions" bson:"gift_options"`
+	Reports            ReportSettings     `json:"reports" bson:"reports"`
 	PaymentGateways    []PaymentGateway   `json:"paymentGateways" bson:"payment_gateways"`
+	PaymentMode        string             `json:"paymentMode,omitempty" bson:"payment_mode,omitempty"` // Empty uses RAZORPAY_MODE
 	SocialMedia        SocialMedia        `json:"socialMedia" bson:"social_media"`
 	PrivacyPolicy      string             `json:"privacyPolicy" bson:"privacy_policy"`
 	TermsOfService     string             `json:"termsOfService" bson:"terms_of_service"`
@@ -37,6 +40,44 @@ type ShippingMethod struct {
 	Enabled     bool    `json:"enabled" bson:"enabled"`
 }
 
+// DefaultGiftMessageLength is the gift message limit when settings don't set one
+const DefaultGiftMessageLength = 200
+
+// GiftOptions configures gift wrapping and messages at checkout
+type GiftOptions struct {
+	WrapEnabled   bool    `json:"wrapEnabled" bson:"wrap_enabled"`
+	WrapPrice     float64 `json:"wrapPrice" bson:"wrap_price" validate:"gte=0"`                  // Per order, in the base currency
+	MessageLength int     `json:"messageLength" bson:"message_length" validate:"gte=0,lte=1000"` // Longest gift message; 0 uses DefaultGiftMessageLength
+}
+
+// MaxMessageLength returns the longest gift message allowed
+func (o GiftOptions) MaxMessageLength() int {
+	if o.MessageLength > 0 {
+		return o.MessageLength
+	}
+	return DefaultGiftMessageLength
+}
+
+// Report periods
+const (
+	ReportDaily  = "daily"  // The previous day
+	ReportWeekly = "weekly" // The previous Monday to Sunday
+)
+
+// ReportSettings configures the sales digests emailed to admins
+type ReportSettings struct {
+	Recipients []string `json:"recipients" bson:"recipients" validate:"max=20,dive,email"`
+	Daily      bool     `json:"daily" bson:"daily"`
+	Weekly     bool     `json:"weekly" bson:"weekly"`
+}
+
+// Payment modes: which Razorpay key pair takes payments. Orders record the
+// mode they were paid in; those without one were paid live.
+const (
+	PaymentModeLive = "live"
+	PaymentModeTest = "test"
+)
+
 // PaymentGateway represents a payment method
 type PaymentGateway struct {
 	Name        string `json:"name" bson:"name"`
@@ -63,7 +104,10 @@ type UpdateSettingsRequest struct {
 	Currency           *string          `json:"currency,omitempty"`
 	TaxRate            *float64         `json:"taxRate,omitempty"`
 	ShippingMethods    []ShippingMethod `json:"shippingMethods,omitempty"`
+	GiftOptions        *GiftOptions     `json:"giftOptions,omitempty"`
+	Reports            *ReportSettings  `json:"reports,omitempty"`
 	PaymentGateways    []PaymentGateway `json:"paymentGateways,omitempty"`
+	PaymentMode        *string          `json:"paymentMode,omitempty"`
 	SocialMedia        *SocialMedia     `json:"socialMedia,omitempty"`
 	PrivacyPolicy      *string          `json:"privacyPolicy,omitempty"`
 	TermsOfService     *string          `json:"termsOfService,omitempty"`
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// Dimensions of a packed item in centimetres
+type Dimensions struct {
+	Length float64 `json:"length" bson:"length" validate:"gt=0,lte=300"`
+	Width  float64 `json:"width" bson:"width" validate:"gt=0,lte=300"`
+	Height float64 `json:"height" bson:"height" validate:"gt=0,lte=300"`
+}
+
+// Volume returns the volume in cubic centimetres
+func (d Dimensions) Volume() float64 {
+	return d.Length * d.Width * d.Height
+}
+
+// Shipment is the packed parcel of an order, as carriers bill it: by the
+// greater of its actual weight and its volumetric weight
+type Shipment struct {
+	WeightGrams           float64    `json:"weightGrams" bson:"weight_grams"`
+	Box                   Dimensions `json:"box" bson:"box"`
+	VolumetricWeightGrams float64    `json:"volumetricWeightGrams" bson:"volumetric_weight_grams"`
+	ChargeableWeightGrams float64    `json:"chargeableWeightGrams" bson:"chargeable_weight_grams"`
+	// Some items had no weight or dimensions and were left out
+	Incomplete bool `json:"incomplete,omitempty" bson:"incomplete,omitempty"`
+}
+
+// ShipmentItem is a product and how many units of it are packed
+type ShipmentItem struct {
+	Product  Product
+	Quantity int
+}
+
+// PackShipment packs the items into one box, each unit lying flat on the
+// one below: the box is as long and wide as the largest item and as high as
+// the stack. divisor is the carrier's volumetric divisor in cubic
+// centimetres per kilogram, commonly 5000.
+func PackShipment(items []ShipmentItem, divisor int) Shipment {
+	var s Shipment
+	for _, item := range items {
+		p := item.Product
+		if p.WeightGrams <= 0 || p.Dimensions == nil {
+			s.Incomplete = true
+		}
+		s.WeightGrams += p.WeightGrams * float64(item.Quantity)
+		if p.Dimensions == nil {
+			continue
+		}
+
+		// Longest side along the box, shortest upright
+		sides := []float64{p.Dimensions.Length, p.Dimensions.Width, p.Dimensions.Height}
+		sort.Sort(sort.Reverse(sort.Float64Slice(sides)))
+		s.Box.Length = math.Max(s.Box.Length, sides[0])
+		s.Box.Width = math.Max(s.Box.Width, sides[1])
+		s.Box.Height += sides[2] * float64(item.Quantity)
+	}
+
+	if divisor > 0 {
+		s.VolumetricWeightGrams = math.Ceil(s.Box.Volume() / float64(divisor) * 1000)
+	}
+	s.ChargeableWeightGrams = math.Max(s.WeightGrams, s.VolumetricWeightGrams)
+	return s
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a name into its URL slug, e.g. "TAG Heuer" into "tag-heuer"
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
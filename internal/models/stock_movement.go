@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stock movement reasons. Admins record purchase, correction, damage and
+// return adjustments; sale and cancellation are written by the order flow.
+const (
+	StockReasonPurchase     = "purchase"
+	StockReasonCorrection   = "correction"
+	StockReasonDamage       = "damage"
+	StockReasonReturn       = "return"
+	StockReasonSale         = "sale"
+	StockReasonCancellation = "cancellation"
+)
+
+// StockMovement is one entry in a product's stock ledger
+type StockMovement struct {
+	ID         primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	ProductID  primitive.ObjectID  `json:"productId" bson:"product_id"`
+	Delta      int                 `json:"delta" bson:"delta"`            // Signed change in stock
+	StockAfter int                 `json:"stockAfter" bson:"stock_after"` // Stock once the movement was applied
+	Reason     string              `json:"reason" bson:"reason"`
+	Note       string              `json:"note,omitempty" bson:"note,omitempty"`
+	OrderID    *primitive.ObjectID `json:"orderId,omitempty" bson:"order_id,omitempty"`
+	ActorID    primitive.ObjectID  `json:"actorId,omitempty" bson:"actor_id,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt" bson:"created_at"`
+}
+
+// StockAdjustmentRequest is an admin's manual stock change. Purchases and
+// returns add stock, damage removes it and corrections may go either way.
+type StockAdjustmentRequest struct {
+	Quantity int    `json:"quantity" validate:"required"` // Signed; non-zero
+	Reason   string `json:"reason" validate:"required,oneof=purchase correction damage return"`
+	Note     string `json:"note,omitempty" validate:"max=500"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GeoPoint is a GeoJSON point, as the 2dsphere index on stores expects.
+// Coordinates are longitude first, then latitude.
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// NewGeoPoint returns the point at lat, lng
+func NewGeoPoint(lat, lng float64) GeoPoint {
+	return GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+// StoreHours are a boutique's opening hours on one day of the week.
+// Opens and Closes are local times like "10:00".
+type StoreHours struct {
+	Day    string `json:"day" bson:"day" validate:"required,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	Opens  string `json:"opens,omitempty" bson:"opens,omitempty" validate:"omitempty,datetime=15:04"`
+	Closes string `json:"closes,omitempty" bson:"closes,omitempty" validate:"omitempty,datetime=15:04"`
+	Closed bool   `json:"closed,omitempty" bson:"closed,omitempty"`
+}
+
+// StoreAddress is where a boutique is
+type StoreAddress struct {
+	Street  string `json:"street" bson:"street" validate:"required,max=200"`
+	City    string `json:"city" bson:"city" validate:"required,max=100"`
+	State   string `json:"state" bson:"state" validate:"required,max=100"`
+	ZipCode string `json:"zipCode" bson:"zip_code" validate:"required,max=20"`
+	Country string `json:"country" bson:"country" validate:"required,max=100"`
+}
+
+// Store is a physical boutique shown on the storefront's store locator.
+// Inactive stores are only listed to staff.
+type Store struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Address   StoreAddress       `json:"address" bson:"address"`
+	Location  GeoPoint           `json:"location" bson:"location"`
+	Hours     []StoreHours       `json:"hours" bson:"hours"`
+	Phone     string             `json:"phone" bson:"phone"`
+	Email     string             `json:"email,omitempty" bson:"email,omitempty"`
+	Active    bool               `json:"active" bson:"active"`
+	Position  int                `json:"position" bson:"position"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// StoreRequest creates or updates a store
+type StoreRequest struct {
+	Name      string       `json:"name" validate:"required,max=120"`
+	Address   StoreAddress `json:"address" validate:"required"`
+	Latitude  *float64     `json:"latitude" validate:"required,gte=-90,lte=90"`
+	Longitude *float64     `json:"longitude" validate:"required,gte=-180,lte=180"`
+	Hours     []StoreHours `json:"hours" validate:"max=7,dive"`
+	Phone     string       `json:"phone" validate:"required,max=30"`
+	Email     string       `json:"email,omitempty" validate:"omitempty,email"`
+	Active    *bool        `json:"active,omitempty"`
+	Position  int          `json:"position" validate:"gte=0"`
+}
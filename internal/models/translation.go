@@ -0,0 +1,48 @@
+package models
+
+// Translations holds a document's translated text by locale and then by
+// field, e.g. {"hi": {"title": "..."}}. The document's own fields are in the
+// default locale, which is also what missing translations fall back to.
+type Translations map[string]map[string]string
+
+// Text returns field in locale, or fallback when it isn't translated
+func (t Translations) Text(locale, field, fallback string) string {
+	if text := t[locale][field]; text != "" {
+		return text
+	}
+	return fallback
+}
+
+// Localize swaps the translatable fields of the slide for locale and drops
+// the translations, which storefront responses don't carry
+func (s *HeroSlide) Localize(locale string) {
+	s.Title = s.Translations.Text(locale, "title", s.Title)
+	s.Subtitle = s.Translations.Text(locale, "subtitle", s.Subtitle)
+	s.Description = s.Translations.Text(locale, "description", s.Description)
+	s.Translations = nil
+}
+
+// Localize swaps the translatable fields of the card for locale
+func (c *HomeCategoryCard) Localize(locale string) {
+	c.Title = c.Translations.Text(locale, "title", c.Title)
+	c.Subtitle = c.Translations.Text(locale, "subtitle", c.Subtitle)
+	c.Translations = nil
+}
+
+// Localize swaps the translatable fields of the feature for locale
+func (f *HomeCollectionFeature) Localize(locale string) {
+	f.Tagline = f.Translations.Text(locale, "tagline", f.Tagline)
+	f.Title = f.Translations.Text(locale, "title", f.Title)
+	f.Description = f.Translations.Text(locale, "description", f.Description)
+	f.Availability = f.Translations.Text(locale, "availability", f.Availability)
+	f.CtaLabel = f.Translations.Text(locale, "ctaLabel", f.CtaLabel)
+	f.ImageAlt = f.Translations.Text(locale, "imageAlt", f.ImageAlt)
+	f.Translations = nil
+}
+
+// Localize swaps the name and description of the product for locale
+func (p *Product) Localize(locale string) {
+	p.Name = p.Translations.Text(locale, "name", p.Name)
+	p.Description = p.Translations.Text(locale, "description", p.Description)
+	p.Translations = nil
+}
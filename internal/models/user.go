@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,26 +9,112 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name         string             `json:"name" bson:"name"`
-	Email        string             `json:"email" bson:"email"`
-	Password     string             `json:"-" bson:"password"` // Password is not included in JSON responses
-	Role         string             `json:"role" bson:"role"`
-	GoogleID     string             `json:"googleId,omitempty" bson:"google_id,omitempty"`
-	Picture      string             `json:"picture,omitempty" bson:"picture,omitempty"`
-	AuthProvider string             `json:"authProvider" bson:"auth_provider"` // "local", "google", etc.
-	CreatedAt    time.Time          `json:"createdAt" bson:"created_at"`
-	UpdatedAt    time.Time          `json:"updatedAt" bson:"updated_at"`
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name          string             `json:"name" bson:"name"`
+	Email         string             `json:"email" bson:"email,omitempty"` // Absent for phone-only accounts
+	EmailVerified bool               `json:"emailVerified" bson:"email_verified"`
+	Password      string             `json:"-" bson:"password"` // Password is not included in JSON responses
+	Role          string             `json:"role" bson:"role"`
+	GoogleID      string             `json:"googleId,omitempty" bson:"google_id,omitempty"`
+	Picture       string             `json:"picture,omitempty" bson:"picture,omitempty"`
+	Phone         string             `json:"phone,omitempty" bson:"phone,omitempty"` // E.164, e.g. "+919876543210"
+	PhoneVerified bool               `json:"phoneVerified" bson:"phone_verified"`
+	AuthProvider  string             `json:"authProvider" bson:"auth_provider"`        // "local", "google", "phone", etc.
+	Status        string             `json:"status,omitempty" bson:"status,omitempty"` // "active" (default when empty), "suspended" or "deleted"
+	TokenVersion  int                `json:"-" bson:"token_version"`                   // Bumped to invalidate all issued tokens
+	DeletedAt     *time.Time         `json:"deletedAt,omitempty" bson:"deleted_at,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updatedAt" bson:"updated_at"`
+
+	// RazorpayCustomerID links the user to their Razorpay Customer, which
+	// holds the cards and UPI IDs they chose to save at checkout
+	RazorpayCustomerID string `json:"-" bson:"razorpay_customer_id,omitempty"`
+	// The same in Razorpay's test mode, whose customers are separate
+	RazorpayTestCustomerID string `json:"-" bson:"razorpay_test_customer_id,omitempty"`
+}
+
+// Account status values
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDeleted   = "deleted" // Self-service deletion; the record is kept anonymized
+)
+
+// IsSuspended reports whether the account has been suspended by an admin
+func (u *User) IsSuspended() bool {
+	return u.Status == UserStatusSuspended
+}
+
+// IsDeleted reports whether the user has deleted their account
+func (u *User) IsDeleted() bool {
+	return u.Status == UserStatusDeleted
+}
+
+// NormalizeEmail trims and lower-cases an email so it is stored and compared consistently
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone converts a user-entered phone number to E.164. Bare
+// 10-digit numbers are treated as Indian mobiles. ok is false when the input
+// can't be a valid number.
+func NormalizePhone(raw string) (phone string, ok bool) {
+	raw = strings.TrimSpace(raw)
+	hasPlus := strings.HasPrefix(raw, "+")
+
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' || r == '+':
+			// formatting characters
+		default:
+			return "", false
+		}
+	}
+	digits := b.String()
+
+	switch {
+	case hasPlus:
+	case len(digits) == 10:
+		digits = "91" + digits
+	case len(digits) == 11 && strings.HasPrefix(digits, "0"):
+		digits = "91" + digits[1:]
+	case len(digits) == 12 && strings.HasPrefix(digits, "91"):
+	default:
+		return "", false
+	}
+
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", false
+	}
+	return "+" + digits, true
+}
+
+// OTPRequest asks for a one-time code to be sent to a phone number
+type OTPRequest struct {
+	Phone string `json:"phone" validate:"required"`
+}
+
+// OTPVerifyRequest exchanges a one-time code for a session. Name is used only
+// when the phone number doesn't belong to an account yet.
+type OTPVerifyRequest struct {
+	Phone string `json:"phone" validate:"required"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+	Name  string `json:"name,omitempty" validate:"omitempty,max=100"`
 }
 
 // UserResponse is the response returned after user actions (omits sensitive info)
 type UserResponse struct {
-	ID           primitive.ObjectID `json:"id"`
-	Name         string             `json:"name"`
-	Email        string             `json:"email"`
-	Role         string             `json:"role"`
-	Picture      string             `json:"picture,omitempty"`
-	AuthProvider string             `json:"authProvider,omitempty"`
+	ID            primitive.ObjectID `json:"id"`
+	Name          string             `json:"name"`
+	Email         string             `json:"email"`
+	EmailVerified bool               `json:"emailVerified"`
+	Role          string             `json:"role"`
+	Picture       string             `json:"picture,omitempty"`
+	Phone         string             `json:"phone,omitempty"`
+	AuthProvider  string             `json:"authProvider,omitempty"`
 }
 
 // RegisterRequest represents the data required for user registration
@@ -35,7 +122,7 @@ type RegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"required,oneof=admin user"`
+	Role     string `json:"role" validate:"omitempty,oneof=admin user"`
 }
 
 // LoginRequest represents the data required for user login
@@ -44,6 +131,34 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// ForgotPasswordRequest asks for a password reset link by email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password using the token from a reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// UpdateUserRoleRequest is used by admins to change a user's role to any
+// existing role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,max=32"`
+}
+
+// UpdateUserStatusRequest is used by admins to suspend or reactivate a user
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended"`
+}
+
+// DeleteAccountRequest confirms a self-service account deletion. Password is
+// required for local accounts and ignored for OAuth-only accounts.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
 // GoogleUser represents the data received from Google OAuth
 type GoogleUser struct {
 	ID            string `json:"id"`
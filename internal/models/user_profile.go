@@ -26,10 +26,19 @@ type UserPreferences struct {
 	SizePreferences   map[string]string  `json:"sizePreferences" bson:"size_preferences"`
 	ColorPreferences  []string           `json:"colorPreferences" bson:"color_preferences"`
 	PriceRange        []float64          `json:"priceRange" bson:"price_range"` // [min, max]
+	OrderUpdates      OrderUpdateChannels `json:"orderUpdates" bson:"order_updates"`
 	CreatedAt         time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt         time.Time          `json:"updatedAt" bson:"updated_at"`
 }
 
+// OrderUpdateChannels are the channels, besides in-app notifications, a
+// customer has opted in to for order updates. Messages go to the phone
+// number of the order's shipping address.
+type OrderUpdateChannels struct {
+	WhatsApp bool `json:"whatsapp" bson:"whatsapp"`
+	SMS      bool `json:"sms" bson:"sms"`
+}
+
 // ProfileUpdateRequest is used for updating user profile
 type ProfileUpdateRequest struct {
 	DateOfBirth *time.Time `json:"dateOfBirth,omitempty"`
@@ -46,4 +55,5 @@ type PreferencesUpdateRequest struct {
 	SizePreferences    map[string]string `json:"sizePreferences,omitempty"`
 	ColorPreferences   []string          `json:"colorPreferences,omitempty"`
 	PriceRange         []float64         `json:"priceRange,omitempty"`
+	OrderUpdates       *OrderUpdateChannels `json:"orderUpdates,omitempty"`
 }
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Warranty statuses, derived from its dates; see Warranty.CurrentStatus
+const (
+	WarrantyActive  = "active"
+	WarrantyExpired = "expired"
+	WarrantyVoid    = "void" // The order was returned
+)
+
+// Warranty covers one delivered watch: one is issued per unit of each order
+// line whose product has warranty months, starting on delivery
+type Warranty struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID  `json:"userId" bson:"user_id"`
+	OrderID      primitive.ObjectID  `json:"orderId" bson:"order_id"`
+	Line         int                 `json:"line" bson:"line"` // Index of the order line
+	Unit         int                 `json:"unit" bson:"unit"` // 1 to the line's quantity
+	ProductID    primitive.ObjectID  `json:"productId" bson:"product_id"`
+	ProductName  string              `json:"productName" bson:"product_name"`
+	SerialNumber string              `json:"serialNumber,omitempty" bson:"serial_number,omitempty"`
+	Months       int                 `json:"months" bson:"months"` // Including extensions
+	StartsAt     time.Time           `json:"startsAt" bson:"starts_at"`
+	ExpiresAt    time.Time           `json:"expiresAt" bson:"expires_at"`
+	Extensions   []WarrantyExtension `json:"extensions,omitempty" bson:"extensions,omitempty"`
+	VoidedAt     *time.Time          `json:"voidedAt,omitempty" bson:"voided_at,omitempty"`
+	Status       string              `json:"status" bson:"-"` // Set on read
+	CreatedAt    time.Time           `json:"createdAt" bson:"created_at"`
+	UpdatedAt    time.Time           `json:"updatedAt" bson:"updated_at"`
+}
+
+// WarrantyExtension records an admin extending a warranty, e.g. after a
+// repair at a service center
+type WarrantyExtension struct {
+	Months    int                `json:"months" bson:"months"`
+	Reason    string             `json:"reason" bson:"reason"`
+	ActorID   primitive.ObjectID `json:"actorId,omitempty" bson:"actor_id,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// CurrentStatus is the warranty's status at now
+func (w *Warranty) CurrentStatus(now time.Time) string {
+	switch {
+	case w.VoidedAt != nil:
+		return WarrantyVoid
+	case now.After(w.ExpiresAt):
+		return WarrantyExpired
+	default:
+		return WarrantyActive
+	}
+}
+
+// WarrantySerialRequest registers the serial number of the covered watch
+type WarrantySerialRequest struct {
+	SerialNumber string `json:"serialNumber" validate:"required,max=64"`
+}
+
+// WarrantyExtendRequest extends a warranty by Months
+type WarrantyExtendRequest struct {
+	Months int    `json:"months" validate:"required,min=1,max=60"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookEndpoint is an external URL that receives signed event deliveries
+type WebhookEndpoint struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL         string             `json:"url" bson:"url"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Events      []string           `json:"events" bson:"events"` // Event types to deliver; "*" for all
+	Secret      string             `json:"-" bson:"secret"`      // Signs deliveries; shown only when created or rotated
+	Active      bool               `json:"active" bson:"active"`
+	// Outcome of the most recent delivery attempt
+	LastDeliveryAt *time.Time         `json:"lastDeliveryAt,omitempty" bson:"last_delivery_at,omitempty"`
+	LastStatusCode int                `json:"lastStatusCode,omitempty" bson:"last_status_code,omitempty"`
+	LastError      string             `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	CreatedBy      primitive.ObjectID `json:"createdBy,omitempty" bson:"created_by,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// WebhookEndpointRequest creates or replaces a webhook endpoint
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Description string   `json:"description,omitempty" validate:"max=500"`
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=* order.created order.status_changed payment.captured product.updated stock.low support.message"`
+	Active      *bool    `json:"active,omitempty"`
+}
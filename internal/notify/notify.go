@@ -0,0 +1,177 @@
+// Package notify creates in-app notifications for customers: order updates,
+// replies to their support tickets, and price drops or restocks of products
+// on their wishlist. Handlers and
+// jobs call it after the change they describe has been stored; failures are
+// logged by the caller and never undo that change.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/orderstatus"
+)
+
+// Notification types
+const (
+	TypeOrder     = "order"
+	TypePromotion = "promotion"
+	TypeProduct   = "product"
+	TypeSystem    = "system"
+	TypeSupport   = "support"
+)
+
+// Create stores notifications, filling in their IDs and timestamps
+func Create(ctx context.Context, db *database.DBClient, notifications ...models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	now := time.Now()
+	docs := make([]interface{}, len(notifications))
+	for i := range notifications {
+		if notifications[i].ID.IsZero() {
+			notifications[i].ID = primitive.NewObjectID()
+		}
+		if notifications[i].CreatedAt.IsZero() {
+			notifications[i].CreatedAt = now
+		}
+		docs[i] = notifications[i]
+	}
+	if _, err := db.Collections().Notifications.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("insert notifications: %w", err)
+	}
+	return nil
+}
+
+// orderMessages titles and describes each status an order can move to
+var orderMessages = map[string][2]string{
+	orderstatus.Processing: {"Order confirmed", "Your order #%s has been confirmed and is being prepared."},
+	orderstatus.Shipped:    {"Order shipped", "Your order #%s is on its way."},
+	orderstatus.Delivered:  {"Order delivered", "Your order #%s has been delivered."},
+	orderstatus.Cancelled:  {"Order cancelled", "Your order #%s has been cancelled."},
+	orderstatus.Returned:   {"Order returned", "Your return for order #%s is complete."},
+}
+
+// OrderStatus tells the customer their order moved to a new status. Changes
+// the customer made themselves are not echoed back.
+func OrderStatus(ctx context.Context, db *database.DBClient, change events.OrderStatusChange) error {
+	text, ok := orderMessages[change.To]
+	if !ok || change.Actor == models.OrderActorCustomer {
+		return nil
+	}
+	orderID, err := primitive.ObjectIDFromHex(change.OrderID)
+	if err != nil {
+		return err
+	}
+	userID, err := primitive.ObjectIDFromHex(change.UserID)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf(text[1], change.OrderID)
+	if change.Note != "" {
+		message += " " + change.Note
+	}
+	return Create(ctx, db, models.Notification{
+		UserID:      userID,
+		Type:        TypeOrder,
+		Title:       text[0],
+		Message:     message,
+		ReferenceID: orderID,
+	})
+}
+
+// OrderUpdates is an events.Handler creating OrderStatus notifications
+func OrderUpdates(db *database.DBClient) events.Handler {
+	return func(ctx context.Context, e events.Event) error {
+		change, ok := e.Data.(events.OrderStatusChange)
+		if e.Type != events.OrderStatusChanged || !ok {
+			return nil
+		}
+		return OrderStatus(ctx, db, change)
+	}
+}
+
+// SupportReplies is an events.Handler telling customers staff replied to
+// their support ticket
+func SupportReplies(db *database.DBClient) events.Handler {
+	return func(ctx context.Context, e events.Event) error {
+		msg, ok := e.Data.(events.SupportTicketMessage)
+		if e.Type != events.SupportMessage || !ok || !msg.Staff {
+			return nil
+		}
+		ticketID, err := primitive.ObjectIDFromHex(msg.TicketID)
+		if err != nil {
+			return err
+		}
+		userID, err := primitive.ObjectIDFromHex(msg.UserID)
+		if err != nil {
+			return err
+		}
+		return Create(ctx, db, models.Notification{
+			UserID:      userID,
+			Type:        TypeSupport,
+			Title:       "New reply to your support request",
+			Message:     fmt.Sprintf("We replied to \"%s\".", msg.Subject),
+			ReferenceID: ticketID,
+		})
+	}
+}
+
+// PriceDrop tells everyone with the product on their wishlist that its price
+// fell from oldPrice
+func PriceDrop(ctx context.Context, db *database.DBClient, product models.Product, oldPrice float64) error {
+	newPrice := product.GetFinalPrice()
+	if newPrice >= oldPrice {
+		return nil
+	}
+	return wishlisters(ctx, db, product.ID, models.Notification{
+		Type:    TypePromotion,
+		Title:   "Price drop: " + product.Name,
+		Message: fmt.Sprintf("%s on your wishlist is now ₹%.2f, down from ₹%.2f.", product.Name, newPrice, oldPrice),
+	})
+}
+
+// StockChanged tells wishlisters a product is available again when its stock
+// went from none to some
+func StockChanged(ctx context.Context, db *database.DBClient, productID primitive.ObjectID, before, after int) error {
+	if before > 0 || after <= 0 {
+		return nil
+	}
+	var product models.Product
+	if err := db.Collections().Products.FindOne(ctx, bson.M{"_id": productID}).Decode(&product); err != nil {
+		return fmt.Errorf("find product: %w", err)
+	}
+	return wishlisters(ctx, db, productID, models.Notification{
+		Type:    TypeProduct,
+		Title:   "Back in stock: " + product.Name,
+		Message: fmt.Sprintf("%s from your wishlist is available again.", product.Name),
+	})
+}
+
+// wishlisters sends a copy of template to every user with the product on
+// their wishlist
+func wishlisters(ctx context.Context, db *database.DBClient, productID primitive.ObjectID, template models.Notification) error {
+	userIDs, err := db.Collections().Wishlists.Distinct(ctx, "user_id", bson.M{"product_id": productID})
+	if err != nil {
+		return fmt.Errorf("find wishlisters: %w", err)
+	}
+	notifications := make([]models.Notification, 0, len(userIDs))
+	for _, id := range userIDs {
+		userID, ok := id.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		n := template
+		n.UserID = userID
+		n.ReferenceID = productID
+		notifications = append(notifications, n)
+	}
+	return Create(ctx, db, notifications...)
+}
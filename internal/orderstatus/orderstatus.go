@@ -0,0 +1,66 @@
+// Package orderstatus defines the order lifecycle: the statuses an order can
+// be in and which changes between them are allowed. Every handler or job that
+// changes an order's status checks the change here first.
+package orderstatus
+
+// Order statuses
+const (
+	// PendingVerification holds a cash-on-delivery order until the customer
+	// confirms it or an admin approves it
+	PendingVerification = "pending_verification"
+	Pending             = "pending"
+	Processing          = "processing"
+	Shipped             = "shipped"
+	Delivered           = "delivered"
+	Cancelled           = "cancelled"
+	Returned            = "returned"
+)
+
+// All lists every status in lifecycle order
+var All = []string{PendingVerification, Pending, Processing, Shipped, Delivered, Cancelled, Returned}
+
+// Reserving lists the statuses whose items have left the sellable stock but
+// not yet the warehouse
+var Reserving = []string{PendingVerification, Pending, Processing}
+
+// transitions maps each status to the statuses it may move to. Orders can be
+// cancelled until they ship; shipped orders come back as returned when the
+// courier can't deliver them, delivered ones when a return is refunded.
+// Cancelled and returned are final.
+var transitions = map[string][]string{
+	PendingVerification: {Processing, Cancelled},
+	Pending:             {Processing, Cancelled},
+	Processing:          {Shipped, Cancelled},
+	Shipped:             {Delivered, Returned},
+	Delivered:           {Returned},
+}
+
+// Valid reports whether status is a known order status
+func Valid(status string) bool {
+	for _, s := range All {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Next lists the statuses an order in status may move to
+func Next(status string) []string {
+	return transitions[status]
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCancel reports whether an order in status may still be cancelled
+func CanCancel(status string) bool {
+	return CanTransition(status, Cancelled)
+}
@@ -0,0 +1,187 @@
+// Package pincode looks up Indian postal codes (pincodes): the district and
+// state each one serves. Lookups read the pincodes collection first, which
+// can be seeded from the India Post directory, and fall back to an external
+// API whose answers are added to the collection. Results are cached.
+package pincode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+const (
+	lookupTimeout = 5 * time.Second
+	cacheTTL      = 7 * 24 * time.Hour
+	// Unknown pincodes are remembered for less time in case the API was wrong
+	missTTL = 24 * time.Hour
+)
+
+// ErrNotFound reports a well-formed pincode that no post office uses
+var ErrNotFound = errors.New("pincode not found")
+
+// Place is the area a pincode serves
+type Place struct {
+	Pincode  string `json:"pincode" bson:"_id"`
+	City     string `json:"city" bson:"city"`
+	District string `json:"district" bson:"district"`
+	State    string `json:"state" bson:"state"`
+	// Areas are the post offices using the pincode, for locality suggestions
+	Areas []string `json:"areas,omitempty" bson:"areas,omitempty"`
+}
+
+// Directory resolves pincodes
+type Directory struct {
+	DB *database.DBClient
+	// URL is the API the pincode is appended to, answering like
+	// https://api.postalpincode.in/pincode/; empty disables it
+	URL    string
+	Client *http.Client // Optional; a client with lookupTimeout is used when nil
+}
+
+// Valid reports whether code has the shape of a pincode: six digits, the
+// first of them not zero
+func Valid(code string) bool {
+	if len(code) != 6 || code[0] == '0' {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsIndia reports whether country names India. Pincodes are only checked
+// for Indian addresses.
+func IsIndia(country string) bool {
+	switch strings.ToLower(strings.TrimSpace(country)) {
+	case "india", "in", "ind", "bharat":
+		return true
+	}
+	return false
+}
+
+// Lookup returns the place a pincode serves, or ErrNotFound
+func (d *Directory) Lookup(ctx context.Context, code string) (Place, error) {
+	cacheKey := "pincode:" + code
+	var place Place
+	if err := d.DB.CacheGet(ctx, cacheKey, &place); err == nil {
+		if place.State == "" {
+			return place, ErrNotFound
+		}
+		return place, nil
+	}
+
+	coll := d.DB.Collections().Pincodes
+	err := coll.FindOne(ctx, bson.M{"_id": code}).Decode(&place)
+	switch {
+	case err == nil:
+		d.DB.CacheSet(ctx, cacheKey, place, cacheTTL)
+		return place, nil
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return place, fmt.Errorf("find pincode: %w", err)
+	case d.URL == "":
+		return place, ErrNotFound
+	}
+
+	place, err = d.fetch(ctx, code)
+	if errors.Is(err, ErrNotFound) {
+		d.DB.CacheSet(ctx, cacheKey, Place{Pincode: code}, missTTL)
+		return place, err
+	}
+	if err != nil {
+		return place, err
+	}
+	if _, err := coll.ReplaceOne(ctx, bson.M{"_id": code}, place, options.Replace().SetUpsert(true)); err != nil {
+		return place, fmt.Errorf("store pincode: %w", err)
+	}
+	d.DB.CacheSet(ctx, cacheKey, place, cacheTTL)
+	return place, nil
+}
+
+// fetch asks the API for a pincode
+func (d *Directory) fetch(ctx context.Context, code string) (Place, error) {
+	place := Place{Pincode: code}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL+code, nil)
+	if err != nil {
+		return place, fmt.Errorf("build request: %w", err)
+	}
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: lookupTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return place, fmt.Errorf("fetch pincode: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return place, fmt.Errorf("fetch pincode: status %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Status     string `json:"Status"`
+		PostOffice []struct {
+			Name     string `json:"Name"`
+			District string `json:"District"`
+			State    string `json:"State"`
+		} `json:"PostOffice"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return place, fmt.Errorf("decode pincode: %w", err)
+	}
+	if len(results) == 0 || results[0].Status != "Success" || len(results[0].PostOffice) == 0 {
+		return place, ErrNotFound
+	}
+
+	offices := results[0].PostOffice
+	place.District, place.State = offices[0].District, offices[0].State
+	place.City = place.District
+	for _, office := range offices {
+		place.Areas = append(place.Areas, office.Name)
+	}
+	return place, nil
+}
+
+// stateAliases maps former and informal state names to the ones the postal
+// directory uses, after normalizeState
+var stateAliases = map[string]string{
+	"orissa":      "odisha",
+	"pondicherry": "puducherry",
+	"nctofdelhi":  "delhi",
+	"newdelhi":    "delhi",
+	"uttaranchal": "uttarakhand",
+	"jandk":       "jammuandkashmir",
+}
+
+// normalizeState lowercases a state name and drops everything but letters,
+// spelling "&" as "and", so "Jammu & Kashmir" matches "JAMMU AND KASHMIR"
+func normalizeState(state string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.ReplaceAll(state, "&", "and")) {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	if alias, ok := stateAliases[b.String()]; ok {
+		return alias
+	}
+	return b.String()
+}
+
+// InState reports whether state names the state the place is in
+func (p Place) InState(state string) bool {
+	return normalizeState(state) == normalizeState(p.State)
+}
@@ -0,0 +1,90 @@
+// Package pricehistory records every change to a product's price or discount
+// in the price_history collection, and answers the lowest price a product
+// sold at over a recent period, which storefronts show next to discounts.
+package pricehistory
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Record appends the current pricing of each product to its history.
+// change supplies the reason, actor and campaign shared by the entries; its
+// campaign defaults to the one holding each product. Failures are logged
+// and never block the price change itself.
+func Record(ctx context.Context, db *database.DBClient, change models.PriceHistoryEntry, products ...models.Product) {
+	if len(products) == 0 {
+		return
+	}
+	now := time.Now()
+	docs := make([]interface{}, 0, len(products))
+	for _, p := range products {
+		entry := models.NewPriceHistoryEntry(p, change.Reason)
+		entry.ActorID = change.ActorID
+		if change.CampaignID != nil {
+			entry.CampaignID = change.CampaignID
+		}
+		entry.CreatedAt = now
+		docs = append(docs, entry)
+	}
+	if _, err := db.Collections().PriceHistory.InsertMany(ctx, docs); err != nil {
+		log.Printf("[PRICE] Failed to record %s price history for %d products: %v", change.Reason, len(products), err)
+	}
+}
+
+// Lowest returns the lowest price the product sold at since the given time,
+// counting its current pricing. Products without history only count their
+// current pricing.
+func Lowest(ctx context.Context, db *database.DBClient, product models.Product, since time.Time) (float64, error) {
+	coll := db.Collections().PriceHistory
+	now := time.Now()
+
+	// The entry in effect when the period began, then every later one
+	var entries []models.PriceHistoryEntry
+	var first models.PriceHistoryEntry
+	err := coll.FindOne(ctx,
+		bson.M{"product_id": product.ID, "created_at": bson.M{"$lte": since}},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&first)
+	if err == nil {
+		first.CreatedAt = since
+		entries = append(entries, first)
+	}
+	cursor, err := coll.Find(ctx,
+		bson.M{"product_id": product.ID, "created_at": bson.M{"$gt": since}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var later []models.PriceHistoryEntry
+	if err := cursor.All(ctx, &later); err != nil {
+		return 0, err
+	}
+	entries = append(entries, later...)
+
+	current := models.NewPriceHistoryEntry(product, "")
+	current.CreatedAt = since
+	if len(entries) > 0 {
+		current.CreatedAt = entries[len(entries)-1].CreatedAt
+		entries = entries[:len(entries)-1]
+	}
+	lowest := current.LowestPriceBetween(current.CreatedAt, now)
+	for i, e := range entries {
+		until := current.CreatedAt
+		if i+1 < len(entries) {
+			until = entries[i+1].CreatedAt
+		}
+		if price := e.LowestPriceBetween(e.CreatedAt, until); price < lowest {
+			lowest = price
+		}
+	}
+	return lowest, nil
+}
@@ -0,0 +1,123 @@
+// Package productviews counts product page views and ranks products by
+// them. Views are counted in the cache, which is cheap enough to do on every
+// page load, and flushed periodically into each product's view_count, the
+// daily totals in product_views, and the product's popularity: its views
+// over the recent window, which listings sort by.
+package productviews
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+)
+
+// pendingKey is the cache hash of views not yet flushed, by product ID
+const pendingKey = "product_views:pending"
+
+// Record counts a view of the product. Failures are logged; a lost view
+// never fails the page.
+func Record(ctx context.Context, db *database.DBClient, productID primitive.ObjectID) {
+	if db.Cache == nil {
+		return
+	}
+	if err := db.Cache.HIncr(ctx, pendingKey, productID.Hex()); err != nil {
+		log.Printf("[VIEWS] Failed to count a view of %s: %v", productID.Hex(), err)
+	}
+}
+
+// Flush moves the counted views into the database, then recomputes the
+// popularity of every product from its views over the last windowDays days.
+// Views drained from the cache but not written are lost; they only rank
+// products, so that is preferred to counting them twice.
+func Flush(ctx context.Context, db *database.DBClient, windowDays int) error {
+	if db.Cache == nil {
+		return nil
+	}
+	if windowDays < 1 {
+		windowDays = 1
+	}
+	counts, err := db.Cache.HDrain(ctx, pendingKey)
+	if err != nil {
+		return fmt.Errorf("drain views: %w", err)
+	}
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	var products, daily []mongo.WriteModel
+	for hex, views := range counts {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil || views <= 0 {
+			continue
+		}
+		products = append(products, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$inc": bson.M{"view_count": views}}))
+		daily = append(daily, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"product_id": id, "day": day}).
+			SetUpdate(bson.M{"$inc": bson.M{"views": views}}).
+			SetUpsert(true))
+	}
+	if len(products) > 0 {
+		unordered := options.BulkWrite().SetOrdered(false)
+		if _, err := db.Collections().Products.BulkWrite(ctx, products, unordered); err != nil {
+			return fmt.Errorf("update view counts: %w", err)
+		}
+		if _, err := db.Collections().ProductViews.BulkWrite(ctx, daily, unordered); err != nil {
+			return fmt.Errorf("update daily views: %w", err)
+		}
+	}
+
+	return rank(ctx, db, day.AddDate(0, 0, 1-windowDays))
+}
+
+// rank sets each product's popularity to its views since the given day.
+// Products without views have it unset rather than zero, so they sort
+// together with products that were never viewed.
+func rank(ctx context.Context, db *database.DBClient, since time.Time) error {
+	cursor, err := db.Collections().ProductViews.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"day": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$product_id", "views": bson.M{"$sum": "$views"}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("aggregate views: %w", err)
+	}
+	var totals []struct {
+		ProductID primitive.ObjectID `bson:"_id"`
+		Views     int64              `bson:"views"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return fmt.Errorf("aggregate views: %w", err)
+	}
+
+	ranked := make([]primitive.ObjectID, 0, len(totals))
+	updates := make([]mongo.WriteModel, 0, len(totals))
+	for _, t := range totals {
+		ranked = append(ranked, t.ProductID)
+		// Only products whose popularity moved are written, so the catalog
+		// read model isn't resynced for nothing
+		updates = append(updates, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": t.ProductID, "popularity": bson.M{"$ne": t.Views}}).
+			SetUpdate(bson.M{"$set": bson.M{"popularity": t.Views}}))
+	}
+	products := db.Collections().Products
+	if len(updates) > 0 {
+		if _, err := products.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("update popularity: %w", err)
+		}
+	}
+	_, err = products.UpdateMany(ctx,
+		bson.M{"popularity": bson.M{"$exists": true}, "_id": bson.M{"$nin": ranked}},
+		bson.M{"$unset": bson.M{"popularity": ""}},
+	)
+	if err != nil {
+		return fmt.Errorf("clear popularity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package razorpay is a minimal client for the parts of the Razorpay REST
+// API that run outside a request: orders, their payments and customers.
+package razorpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const baseURL = "https://api.razorpay.com/v1"
+
+// Client calls the Razorpay API with a key pair
+type Client struct {
+	KeyID  string
+	Secret string
+	HTTP   *http.Client // Optional; a client with a 10 second timeout is used when nil
+}
+
+// New creates a client for the key pair
+func New(keyID, secret string) *Client {
+	return &Client{KeyID: keyID, Secret: secret}
+}
+
+// Configured reports whether both halves of the key pair are set
+func (c *Client) Configured() bool {
+	return c.KeyID != "" && c.Secret != ""
+}
+
+// Do sends a request and decodes the JSON response into out. payload is
+// sent as the JSON body when non-nil.
+func (c *Client) Do(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.KeyID, c.Secret)
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("razorpay %s %s failed with status %d: %s", method, path, resp.StatusCode, respBody)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decode razorpay %s response: %w", path, err)
+	}
+	return nil
+}
+
+// Order is a Razorpay order
+type Order struct {
+	ID         string `json:"id"`
+	Amount     int64  `json:"amount"` // In the currency's smallest unit
+	AmountPaid int64  `json:"amount_paid"`
+	Currency   string `json:"currency"`
+	Status     string `json:"status"` // "created", "attempted" or "paid"
+	// Notes is an object of strings, or an empty array when there are none
+	Notes json.RawMessage `json:"notes"`
+}
+
+// Note returns one of the order's notes
+func (o Order) Note(key string) string {
+	var notes map[string]string
+	if json.Unmarshal(o.Notes, &notes) != nil {
+		return ""
+	}
+	return notes[key]
+}
+
+// Payment is one attempt to pay a Razorpay order
+type Payment struct {
+	ID       string `json:"id"`
+	OrderID  string `json:"order_id"`
+	Amount   int64  `json:"amount"` // In the currency's smallest unit
+	Currency string `json:"currency"`
+	Status   string `json:"status"` // "created", "authorized", "captured", "refunded" or "failed"
+	Method   string `json:"method"`
+}
+
+// Order fetches an order
+func (c *Client) Order(ctx context.Context, id string) (Order, error) {
+	var order Order
+	err := c.Do(ctx, http.MethodGet, "/orders/"+id, nil, &order)
+	return order, err
+}
+
+// OrderPayments lists every payment attempted for an order
+func (c *Client) OrderPayments(ctx context.Context, orderID string) ([]Payment, error) {
+	var list struct {
+		Items []Payment `json:"items"`
+	}
+	err := c.Do(ctx, http.MethodGet, "/orders/"+orderID+"/payments", nil, &list)
+	return list.Items, err
+}
@@ -0,0 +1,156 @@
+// Package realtime pushes events to connected admin dashboards. When Redis is
+// configured events travel through Redis pub/sub, so a dashboard connected to
+// any API instance sees events raised on every other one.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/events"
+)
+
+// AdminEvents lists the event types streamed to admin dashboards
+var AdminEvents = []string{events.OrderCreated, events.PaymentCaptured, events.SupportMessage}
+
+// clientBuffer is how many messages a slow client may fall behind before
+// further messages to it are dropped
+const clientBuffer = 16
+
+// Message is one event as sent to clients
+type Message struct {
+	ID   string
+	Type string
+	Data []byte // The JSON-encoded events.Event
+}
+
+// Hub relays events to the clients connected to this instance
+type Hub struct {
+	redis   *redis.Client // nil relays in-process only
+	channel string
+
+	mu      sync.Mutex
+	clients map[chan Message]struct{}
+	closed  bool
+}
+
+// NewHub creates a hub. A nil redisClient only reaches clients connected to
+// this instance.
+func NewHub(redisClient *redis.Client) *Hub {
+	return &Hub{
+		redis:   redisClient,
+		channel: "realtime:admin",
+		clients: make(map[chan Message]struct{}),
+	}
+}
+
+// Forward is an events.Handler that sends admin events to every instance's
+// clients
+func (h *Hub) Forward(ctx context.Context, e events.Event) error {
+	if !isAdminEvent(e.Type) {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if h.redis != nil {
+		return h.redis.Publish(ctx, h.channel, data).Err()
+	}
+	h.broadcast(Message{ID: e.ID, Type: e.Type, Data: data})
+	return nil
+}
+
+// Run relays events published through Redis to this instance's clients until
+// ctx is cancelled. Without Redis there is nothing to relay and it returns
+// immediately.
+func (h *Hub) Run(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+	sub := h.redis.Subscribe(ctx, h.channel)
+	defer sub.Close()
+
+	log.Printf("[REALTIME] relaying admin events through redis channel %s", h.channel)
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var e struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+				log.Printf("[REALTIME] dropping malformed message: %v", err)
+				continue
+			}
+			h.broadcast(Message{ID: e.ID, Type: e.Type, Data: []byte(msg.Payload)})
+		}
+	}
+}
+
+// Subscribe registers a client. The returned channel is closed when the
+// client unsubscribes or the hub closes.
+func (h *Hub) Subscribe() (<-chan Message, func()) {
+	ch := make(chan Message, clientBuffer)
+
+	h.mu.Lock()
+	if h.closed {
+		close(ch)
+	} else {
+		h.clients[ch] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.clients[ch]; ok {
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close disconnects every client so open streams end and the server can shut
+// down
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+func (h *Hub) broadcast(msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- msg:
+		default:
+			// Drop rather than hold up publishers for a client that isn't
+			// keeping up
+		}
+	}
+}
+
+func isAdminEvent(eventType string) bool {
+	for _, t := range AdminEvents {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -13,7 +13,7 @@ func SetupAccountRoutes(app *fiber.App, db *database.DBClient, cfg *config.Confi
 	accountHandler := handlers.NewAccountHandler(db, cfg)
 
 	// Create a group for account routes with authentication middleware
-	accountGroup := app.Group("/account", middleware.Auth(cfg.JWTSecret))
+	accountGroup := app.Group("/account", middleware.Auth(cfg.JWTKeys, db))
 
 	// Account overview
 	accountGroup.Get("/overview", accountHandler.GetAccountOverview)
@@ -3,17 +3,18 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/shivam-mishra-20/mak-watches-be/internal/handlers"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/storage"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func AdminRoutes(app *fiber.App, db *mongo.Database) {
+func AdminRoutes(app *fiber.App, db *mongo.Database, store storage.Storage) {
 	admin := app.Group("/admin")
 
 	// Other admin routes...
 	admin.Get("/accounts", handlers.GetAllAccounts(db))
 
 	// Settings routes
-	settingsHandler := handlers.NewSettingsHandler(db)
+	settingsHandler := handlers.NewSettingsHandler(db, store)
 	admin.Get("/settings", settingsHandler.GetSettings())
 	admin.Put("/settings", settingsHandler.UpdateSettings())
 	admin.Post("/settings/logo", settingsHandler.UploadLogo())
@@ -0,0 +1,156 @@
+package seed
+
+import "github.com/shivam-mishra-20/mak-watches-be/internal/models"
+
+// galleryImages is how many placeholder images the home gallery gets
+const galleryImages = 6
+
+var sampleCategories = []struct {
+	name     string
+	children []string
+}{
+	{"Men", []string{"Luxury", "Sports", "Classic"}},
+	{"Women", []string{"Luxury", "Everyday"}},
+	{"Smart", []string{"Fitness", "Hybrid"}},
+}
+
+var sampleBrands = []struct {
+	name        string
+	description string
+}{
+	{"Seiko", "Japanese watchmaker known for in-house automatic movements"},
+	{"Casio", "Durable digital and analog watches"},
+	{"Titan", "India's largest watch brand"},
+	{"Fossil", "Fashion watches and hybrid smartwatches"},
+}
+
+var sampleProducts = []struct {
+	name, brand, description  string
+	price                     float64
+	stock                     int
+	mainCategory, subcategory string
+	gender, dialColor         string
+	strapMaterial, movement   string
+	waterResistance, caseSize string
+}{
+	{
+		name: "Seiko Presage Cocktail Time", brand: "Seiko",
+		description: "Automatic dress watch with a textured sunburst dial inspired by classic cocktails.",
+		price:       38500, stock: 12, mainCategory: "Men", subcategory: "Luxury",
+		gender: "Men", dialColor: "Blue", strapMaterial: "Leather", movement: "Automatic",
+		waterResistance: "50m", caseSize: "40.5mm",
+	},
+	{
+		name: "Seiko 5 Sports Field", brand: "Seiko",
+		description: "Rugged automatic field watch with day-date display and luminous hands.",
+		price:       24900, stock: 20, mainCategory: "Men", subcategory: "Sports",
+		gender: "Men", dialColor: "Black", strapMaterial: "Nylon", movement: "Automatic",
+		waterResistance: "100m", caseSize: "39.4mm",
+	},
+	{
+		name: "Casio G-Shock GA-2100", brand: "Casio",
+		description: "Slim octagonal G-Shock with carbon core guard structure and analog-digital display.",
+		price:       9995, stock: 40, mainCategory: "Men", subcategory: "Sports",
+		gender: "Unisex", dialColor: "Black", strapMaterial: "Resin", movement: "Quartz",
+		waterResistance: "200m", caseSize: "45.4mm",
+	},
+	{
+		name: "Casio Vintage A168", brand: "Casio",
+		description: "Retro stainless steel digital watch with backlight, alarm and stopwatch.",
+		price:       3495, stock: 60, mainCategory: "Women", subcategory: "Everyday",
+		gender: "Unisex", dialColor: "Silver", strapMaterial: "Stainless Steel", movement: "Quartz",
+		waterResistance: "30m", caseSize: "36mm",
+	},
+	{
+		name: "Titan Edge Ceramic", brand: "Titan",
+		description: "One of the world's slimmest watches, in scratch-resistant ceramic.",
+		price:       19995, stock: 8, mainCategory: "Men", subcategory: "Classic",
+		gender: "Men", dialColor: "White", strapMaterial: "Ceramic", movement: "Quartz",
+		waterResistance: "30m", caseSize: "40mm",
+	},
+	{
+		name: "Titan Raga Aurora", brand: "Titan",
+		description: "Jewellery-inspired women's watch with a mother-of-pearl dial and rose gold bracelet.",
+		price:       12495, stock: 15, mainCategory: "Women", subcategory: "Luxury",
+		gender: "Women", dialColor: "Mother of Pearl", strapMaterial: "Stainless Steel", movement: "Quartz",
+		waterResistance: "30m", caseSize: "32mm",
+	},
+	{
+		name: "Fossil Gen 6 Smartwatch", brand: "Fossil",
+		description: "Wear OS smartwatch with heart rate, SpO2 and fast charging.",
+		price:       23995, stock: 25, mainCategory: "Smart", subcategory: "Fitness",
+		gender: "Unisex", dialColor: "Black", strapMaterial: "Silicone", movement: "Smart",
+		waterResistance: "30m", caseSize: "44mm",
+	},
+	{
+		name: "Fossil Machine Hybrid", brand: "Fossil",
+		description: "Analog hands with a hidden display for notifications and activity tracking.",
+		price:       17495, stock: 0, mainCategory: "Smart", subcategory: "Hybrid",
+		gender: "Men", dialColor: "Smoke", strapMaterial: "Stainless Steel", movement: "Hybrid",
+		waterResistance: "30m", caseSize: "45mm",
+	},
+}
+
+var sampleHeroSlides = []models.HeroSlide{
+	{
+		Title:       "Presage Cocktail Time",
+		Subtitle:    "Crafted in Japan",
+		Price:       "₹38,500",
+		Description: "A sunburst dial that catches the evening light.",
+		Features:    []string{"Automatic movement", "Sapphire crystal", "41-hour power reserve"},
+		Gradient:    "from-slate-900 to-blue-900",
+		GlowColor:   "#3b82f6",
+	},
+	{
+		Title:       "G-Shock GA-2100",
+		Subtitle:    "Built tough",
+		Price:       "₹9,995",
+		Description: "Carbon core guard in a slim octagonal case.",
+		Features:    []string{"200m water resistance", "Shock resistant", "World time"},
+		Gradient:    "from-zinc-900 to-neutral-700",
+		GlowColor:   "#f97316",
+	},
+}
+
+var sampleCategoryCards = []models.HomeCategoryCard{
+	{Title: "Men", Subtitle: "Automatic, sports and classic", Href: "/categories/men", BgGradient: "from-slate-100 to-slate-300"},
+	{Title: "Women", Subtitle: "Elegant everyday pieces", Href: "/categories/women", BgGradient: "from-rose-100 to-rose-300"},
+	{Title: "Smart", Subtitle: "Track every step", Href: "/categories/smart", BgGradient: "from-emerald-100 to-emerald-300"},
+}
+
+var sampleCollections = []models.HomeCollectionFeature{
+	{
+		Tagline:      "New season",
+		Title:        "The Dress Watch Edit",
+		Description:  "Slim cases and refined dials for evenings out.",
+		Availability: "Available now",
+		CtaLabel:     "Shop the edit",
+		CtaHref:      "/categories/men-classic",
+		ImageAlt:     "Dress watches on a marble table",
+		Layout:       "image-left",
+	},
+	{
+		Tagline:      "Adventure ready",
+		Title:        "Sports & Dive",
+		Description:  "Water resistant, shock resistant and ready for anything.",
+		Availability: "Available now",
+		CtaLabel:     "Explore sports watches",
+		CtaHref:      "/categories/men-sports",
+		ImageAlt:     "Dive watch under water",
+		Layout:       "image-right",
+	},
+}
+
+var sampleTechCards = []models.TechShowcaseCard{
+	{Title: "Heart rate", Subtitle: "All-day monitoring", Rating: 4.6, ReviewCount: 128, Badge: "Smart", Color: "rose"},
+	{Title: "Hybrid display", Subtitle: "Notifications on real hands", Rating: 4.4, ReviewCount: 64, Badge: "Hybrid", Color: "gray"},
+	{Title: "Solar charging", Subtitle: "Never change a battery", Rating: 4.8, ReviewCount: 212, Badge: "Eco", Color: "amber"},
+}
+
+var sampleHighlight = models.TechShowcaseHighlight{
+	Value:      "7 days",
+	Title:      "Battery life",
+	Subtitle:   "On a single charge in smartwatch mode",
+	AccentHex:  "#f97316",
+	Background: "bg-rose-50",
+}
@@ -0,0 +1,323 @@
+// Package seed fills a database with demo data for local development and
+// staging: a category tree, brands, sample products, an admin user, store
+// settings and home page content. Every document is inserted only when it
+// is missing, matched by its slug, email or another natural key, so seeding
+// twice adds nothing and never overwrites edits made since.
+package seed
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/database"
+	"github.com/shivam-mishra-20/mak-watches-be/internal/models"
+)
+
+// Home content collections, as the home content handlers store them
+const (
+	heroSlidesCollection         = "hero_slides"
+	categoryCardsCollection      = "home_category_cards"
+	collectionFeaturesCollection = "home_collection_features"
+	techCardsCollection          = "home_tech_cards"
+	techHighlightCollection      = "home_tech_highlights"
+	galleryCollection            = "home_gallery_images"
+	settingsCollection           = "settings"
+)
+
+// Options configure a seed run
+type Options struct {
+	AdminEmail string
+	// AdminPassword is used when the admin is created; a random one is
+	// generated when empty
+	AdminPassword string
+}
+
+// Report counts the documents a run inserted, by collection
+type Report struct {
+	Inserted map[string]int
+	// AdminPassword is the password generated for a newly created admin,
+	// to show once
+	AdminPassword string
+}
+
+// Run seeds db. It may be interrupted and run again.
+func Run(ctx context.Context, db *database.DBClient, opts Options) (Report, error) {
+	s := &seeder{db: db, now: time.Now().UTC(), report: Report{Inserted: map[string]int{}}}
+	steps := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"categories", s.categories},
+		{"brands", s.brands},
+		{"products", s.products},
+		{"admin user", func(ctx context.Context) error { return s.admin(ctx, opts) }},
+		{"settings", s.settings},
+		{"home content", s.homeContent},
+	}
+	for _, step := range steps {
+		if err := step.run(ctx); err != nil {
+			return s.report, fmt.Errorf("seed %s: %w", step.name, err)
+		}
+	}
+	return s.report, nil
+}
+
+type seeder struct {
+	db     *database.DBClient
+	now    time.Time
+	report Report
+}
+
+// insertMissing inserts doc unless a document matches filter, and returns
+// the ID of whichever document is there afterwards
+func (s *seeder) insertMissing(ctx context.Context, coll *mongo.Collection, filter bson.M, doc interface{}) (primitive.ObjectID, error) {
+	res, err := coll.UpdateOne(ctx, filter, bson.M{"$setOnInsert": doc}, options.Update().SetUpsert(true))
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if id, ok := res.UpsertedID.(primitive.ObjectID); ok {
+		s.report.Inserted[coll.Name()]++
+		return id, nil
+	}
+	var existing struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	err = coll.FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&existing)
+	return existing.ID, err
+}
+
+func (s *seeder) categories(ctx context.Context) error {
+	coll := s.db.Collections().Categories
+	for i, top := range sampleCategories {
+		parent := models.Category{
+			ID:        primitive.NewObjectID(),
+			Name:      top.name,
+			Ancestors: []primitive.ObjectID{},
+			Path:      top.name,
+			ImageURL:  sampleImage(top.name, 1200, 800),
+			SortOrder: i,
+			CreatedAt: s.now,
+			UpdatedAt: s.now,
+		}
+		parent.Slug = models.Slugify(parent.Path)
+		id, err := s.insertMissing(ctx, coll, bson.M{"slug": parent.Slug}, parent)
+		if err != nil {
+			return err
+		}
+		for j, name := range top.children {
+			child := models.Category{
+				ID:        primitive.NewObjectID(),
+				Name:      name,
+				ParentID:  &id,
+				Ancestors: []primitive.ObjectID{id},
+				Path:      models.JoinCategoryPath(parent.Path, name),
+				Depth:     1,
+				SortOrder: j,
+				CreatedAt: s.now,
+				UpdatedAt: s.now,
+			}
+			child.Slug = models.Slugify(child.Path)
+			if _, err := s.insertMissing(ctx, coll, bson.M{"slug": child.Slug}, child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *seeder) brands(ctx context.Context) error {
+	coll := s.db.Collections().Brands
+	for _, b := range sampleBrands {
+		brand := models.Brand{
+			ID:          primitive.NewObjectID(),
+			Name:        b.name,
+			Slug:        models.Slugify(b.name),
+			Description: b.description,
+			CreatedAt:   s.now,
+			UpdatedAt:   s.now,
+		}
+		if _, err := s.insertMissing(ctx, coll, bson.M{"slug": brand.Slug}, brand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// products are matched by name: their slugs carry a hash of the product ID,
+// which differs between runs
+func (s *seeder) products(ctx context.Context) error {
+	coll := s.db.Collections().Products
+	for _, p := range sampleProducts {
+		product := models.Product{
+			ID:              primitive.NewObjectID(),
+			Name:            p.name,
+			Brand:           p.brand,
+			Description:     p.description,
+			Price:           p.price,
+			Category:        models.JoinCategoryPath(p.mainCategory, p.subcategory),
+			MainCategory:    p.mainCategory,
+			Subcategory:     p.subcategory,
+			Stock:           p.stock,
+			Gender:          p.gender,
+			DialColor:       p.dialColor,
+			StrapMaterial:   p.strapMaterial,
+			Movement:        p.movement,
+			WaterResistance: p.waterResistance,
+			CaseSize:        p.caseSize,
+			WeightGrams:     350,
+			CreatedAt:       s.now,
+			UpdatedAt:       s.now,
+		}
+		product.Slug = models.ProductSlug(product.Name, product.ID)
+		product.Images = []string{
+			sampleImage(product.Name, 800, 800),
+			sampleImage(product.Name+" side", 800, 800),
+		}
+		product.ImageURL = product.Images[0]
+		if _, err := s.insertMissing(ctx, coll, bson.M{"name": product.Name}, product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) admin(ctx context.Context, opts Options) error {
+	email := models.NormalizeEmail(opts.AdminEmail)
+	if email == "" {
+		return fmt.Errorf("no admin email given")
+	}
+	coll := s.db.Collections().Users
+	n, err := coll.CountDocuments(ctx, bson.M{"email": email})
+	if err != nil || n > 0 {
+		return err
+	}
+
+	password, generated := opts.AdminPassword, opts.AdminPassword == ""
+	if generated {
+		buf := make([]byte, 12)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		password = hex.EncodeToString(buf)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user := models.User{
+		ID:            primitive.NewObjectID(),
+		Name:          "Store Admin",
+		Email:         email,
+		EmailVerified: true,
+		Password:      string(hash),
+		Role:          models.RoleAdmin,
+		AuthProvider:  "local",
+		CreatedAt:     s.now,
+		UpdatedAt:     s.now,
+	}
+	if _, err := s.insertMissing(ctx, coll, bson.M{"email": email}, user); err != nil {
+		return err
+	}
+	if generated {
+		s.report.AdminPassword = password
+	}
+	return nil
+}
+
+// settings are inserted only into a store that has none
+func (s *seeder) settings(ctx context.Context) error {
+	settings := models.Settings{
+		ID:               primitive.NewObjectID(),
+		StoreName:        "Makwatches",
+		StoreDescription: "Watches for every wrist",
+		ContactEmail:     "support@example.com",
+		ContactPhone:     "+911234567890",
+		Currency:         "INR",
+		TaxRate:          18.0,
+		ShippingMethods: []models.ShippingMethod{
+			{Name: "Standard", Description: "3-5 business days", Cost: 0, Enabled: true},
+			{Name: "Express", Description: "1-2 business days", Cost: 250, Enabled: true},
+		},
+		GiftOptions:        models.GiftOptions{WrapEnabled: true, WrapPrice: 99},
+		EnableRegistration: true,
+		CreatedAt:          s.now,
+		UpdatedAt:          s.now,
+	}
+	_, err := s.insertMissing(ctx, s.db.MongoDB.Collection(settingsCollection), bson.M{}, settings)
+	return err
+}
+
+// homeContent adds items matched by title, or by URL for gallery images
+func (s *seeder) homeContent(ctx context.Context) error {
+	mongoDB := s.db.MongoDB
+	for i, slide := range sampleHeroSlides {
+		slide.ID = primitive.NewObjectID()
+		slide.Position = i + 1
+		slide.Image = sampleImage(slide.Title, 1600, 900)
+		slide.CreatedAt, slide.UpdatedAt = s.now, s.now
+		if _, err := s.insertMissing(ctx, mongoDB.Collection(heroSlidesCollection), bson.M{"title": slide.Title}, slide); err != nil {
+			return err
+		}
+	}
+	for i, card := range sampleCategoryCards {
+		card.ID = primitive.NewObjectID()
+		card.Position = i + 1
+		card.Image = sampleImage(card.Title, 800, 1000)
+		card.CreatedAt, card.UpdatedAt = s.now, s.now
+		if _, err := s.insertMissing(ctx, mongoDB.Collection(categoryCardsCollection), bson.M{"title": card.Title}, card); err != nil {
+			return err
+		}
+	}
+	for i, feature := range sampleCollections {
+		feature.ID = primitive.NewObjectID()
+		feature.Position = i + 1
+		feature.Image = sampleImage(feature.Title, 1200, 900)
+		feature.CreatedAt, feature.UpdatedAt = s.now, s.now
+		if _, err := s.insertMissing(ctx, mongoDB.Collection(collectionFeaturesCollection), bson.M{"title": feature.Title}, feature); err != nil {
+			return err
+		}
+	}
+	for i, card := range sampleTechCards {
+		card.ID = primitive.NewObjectID()
+		card.Position = i + 1
+		card.Image = sampleImage(card.Title, 800, 800)
+		card.CreatedAt, card.UpdatedAt = s.now, s.now
+		if _, err := s.insertMissing(ctx, mongoDB.Collection(techCardsCollection), bson.M{"title": card.Title}, card); err != nil {
+			return err
+		}
+	}
+	highlight := sampleHighlight
+	highlight.ID = primitive.NewObjectID()
+	highlight.CreatedAt, highlight.UpdatedAt = s.now, s.now
+	if _, err := s.insertMissing(ctx, mongoDB.Collection(techHighlightCollection), bson.M{}, highlight); err != nil {
+		return err
+	}
+	for i := 0; i < galleryImages; i++ {
+		img := models.GalleryImage{
+			ID:        primitive.NewObjectID(),
+			Url:       sampleImage(fmt.Sprintf("gallery %d", i+1), 1000, 1000),
+			Alt:       fmt.Sprintf("Watch on the wrist %d", i+1),
+			Position:  i + 1,
+			CreatedAt: s.now,
+			UpdatedAt: s.now,
+		}
+		if _, err := s.insertMissing(ctx, mongoDB.Collection(galleryCollection), bson.M{"url": img.Url}, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleImage is a placeholder photo that stays the same for a given name
+func sampleImage(name string, width, height int) string {
+	return fmt.Sprintf("https://picsum.photos/seed/%s/%d/%d", models.Slugify(name), width, height)
+}
@@ -0,0 +1,151 @@
+// Package sms delivers one-time codes by text message through a pluggable
+// provider. MSG91 and Twilio are supported; without credentials codes are
+// written to the log so local development works offline.
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Provider sends text messages to E.164 phone numbers (e.g. "+919876543210")
+type Provider interface {
+	// SendOTP delivers a one-time verification code
+	SendOTP(ctx context.Context, phone, code string) error
+	// Name identifies the provider for logging
+	Name() string
+}
+
+// Options configures the provider selected by New
+type Options struct {
+	Provider string // "msg91", "twilio" or "log"
+
+	MSG91AuthKey    string
+	MSG91TemplateID string
+
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFromNumber string
+}
+
+// New returns the provider named in opts, falling back to the log provider
+// when it is unknown or missing credentials
+func New(opts Options) Provider {
+	switch strings.ToLower(opts.Provider) {
+	case "msg91":
+		if opts.MSG91AuthKey != "" && opts.MSG91TemplateID != "" {
+			return NewMSG91(opts.MSG91AuthKey, opts.MSG91TemplateID)
+		}
+		log.Println("[SMS] MSG91 selected but MSG91_AUTH_KEY/MSG91_TEMPLATE_ID are not set; logging codes instead")
+	case "twilio":
+		if opts.TwilioAccountSID != "" && opts.TwilioAuthToken != "" && opts.TwilioFromNumber != "" {
+			return NewTwilio(opts.TwilioAccountSID, opts.TwilioAuthToken, opts.TwilioFromNumber)
+		}
+		log.Println("[SMS] Twilio selected but TWILIO_* credentials are not set; logging codes instead")
+	}
+	return LogProvider{}
+}
+
+// httpClient is shared by the HTTP-based providers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// checkResponse turns a non-2xx provider response into an error
+func checkResponse(provider string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: unexpected status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// MSG91 sends codes through MSG91's OTP API using a DLT-approved template
+type MSG91 struct {
+	authKey    string
+	templateID string
+}
+
+// NewMSG91 creates an MSG91 provider
+func NewMSG91(authKey, templateID string) *MSG91 {
+	return &MSG91{authKey: authKey, templateID: templateID}
+}
+
+// SendOTP implements Provider
+func (m *MSG91) SendOTP(ctx context.Context, phone, code string) error {
+	q := url.Values{}
+	q.Set("template_id", m.templateID)
+	q.Set("mobile", strings.TrimPrefix(phone, "+")) // MSG91 expects the country code without "+"
+	q.Set("otp", code)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://control.msg91.com/api/v5/otp?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authkey", m.authKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("msg91: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse("msg91", resp)
+}
+
+// Name implements Provider
+func (m *MSG91) Name() string { return "msg91" }
+
+// Twilio sends codes as plain SMS through Twilio's Messages API
+type Twilio struct {
+	accountSID string
+	authToken  string
+	from       string
+}
+
+// NewTwilio creates a Twilio provider
+func NewTwilio(accountSID, authToken, from string) *Twilio {
+	return &Twilio{accountSID: accountSID, authToken: authToken, from: from}
+}
+
+// SendOTP implements Provider
+func (t *Twilio) SendOTP(ctx context.Context, phone, code string) error {
+	form := url.Values{}
+	form.Set("To", phone)
+	form.Set("From", t.from)
+	form.Set("Body", fmt.Sprintf("%s is your MAK Watches verification code. It expires in a few minutes.", code))
+
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", t.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse("twilio", resp)
+}
+
+// Name implements Provider
+func (t *Twilio) Name() string { return "twilio" }
+
+// LogProvider writes codes to the server log instead of sending them.
+// Only suitable for development.
+type LogProvider struct{}
+
+// SendOTP implements Provider
+func (LogProvider) SendOTP(_ context.Context, phone, code string) error {
+	log.Printf("[SMS] OTP for %s: %s", phone, code)
+	return nil
+}
+
+// Name implements Provider
+func (LogProvider) Name() string { return "log" }
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+)
+
+// maxSignedURLs bounds how many signed URLs are kept for reuse
+const maxSignedURLs = 10000
+
+// Delivery maps the URLs of stored files, as saved in documents, to the
+// URLs handed to clients, signed or on a CDN, and back again
+type Delivery struct {
+	Storage Storage
+	// CDNURL is the origin of a CDN in front of the bucket. It takes
+	// precedence over signing: the CDN reads a private bucket itself.
+	CDNURL string
+	// SignedTTL is how long signed URLs stay valid; 0 doesn't sign
+	SignedTTL time.Duration
+
+	mu     sync.Mutex
+	signed map[string]signedURL
+}
+
+type signedURL struct {
+	url     string
+	expires time.Time
+}
+
+// NewDelivery returns the delivery cfg asks for, nil when URLs are handed
+// out as saved
+func NewDelivery(s Storage, cfg *config.Config) *Delivery {
+	if cfg.StorageCDNURL == "" && cfg.StorageSignedURLTTLMinutes <= 0 {
+		return nil
+	}
+	return &Delivery{
+		Storage:   s,
+		CDNURL:    strings.TrimSuffix(cfg.StorageCDNURL, "/"),
+		SignedTTL: time.Duration(cfg.StorageSignedURLTTLMinutes) * time.Minute,
+		signed:    make(map[string]signedURL),
+	}
+}
+
+// Outgoing returns the URL to hand out for a saved one. URLs of files the
+// storage doesn't hold come back unchanged, as does a URL failing to sign.
+func (d *Delivery) Outgoing(ctx context.Context, url string) string {
+	key, ok := d.Storage.KeyFromURL(url)
+	if !ok {
+		return url
+	}
+	if d.CDNURL != "" {
+		return d.CDNURL + "/" + key
+	}
+	if d.SignedTTL <= 0 {
+		return url
+	}
+	return d.sign(ctx, key, url)
+}
+
+// sign returns a signed URL for key. Signing can take an RSA signature per
+// file, so a URL is reused while at least half its lifetime is left.
+func (d *Delivery) sign(ctx context.Context, key, fallback string) string {
+	now := time.Now()
+	d.mu.Lock()
+	cached, ok := d.signed[key]
+	d.mu.Unlock()
+	if ok && cached.expires.Sub(now) > d.SignedTTL/2 {
+		return cached.url
+	}
+
+	url, err := d.Storage.SignedURL(ctx, key, d.SignedTTL)
+	if err != nil {
+		log.Printf("[STORAGE] Failed to sign a URL for %s: %v", key, err)
+		return fallback
+	}
+	d.mu.Lock()
+	if len(d.signed) >= maxSignedURLs {
+		d.signed = make(map[string]signedURL)
+	}
+	d.signed[key] = signedURL{url: url, expires: now.Add(d.SignedTTL)}
+	d.mu.Unlock()
+	return url
+}
+
+// Saved maps a URL handed out by Outgoing back to the one saved in
+// documents, so clients can send back the URLs they were given. Other URLs
+// come back unchanged.
+func (d *Delivery) Saved(url string) string {
+	if d.CDNURL != "" {
+		if key, ok := strings.CutPrefix(url, d.CDNURL+"/"); ok && validKey(key) == nil {
+			return d.Storage.PublicURL(key)
+		}
+	}
+	if d.SignedTTL > 0 {
+		if unsigned, _, found := strings.Cut(url, "?"); found {
+			if key, ok := d.Storage.KeyFromURL(unsigned); ok {
+				return d.Storage.PublicURL(key)
+			}
+		}
+	}
+	return url
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/firebase"
+)
+
+// Firebase stores files in a Firebase Storage (Google Cloud Storage) bucket
+type Firebase struct {
+	client *firebase.FirebaseClient
+	// Private leaves uploads readable only through signed URLs
+	Private bool
+}
+
+// NewFirebase connects to the bucket and checks that it exists
+func NewFirebase(ctx context.Context, credentialsPath, bucketName string) (*Firebase, error) {
+	client, err := firebase.NewFirebaseClient(ctx, credentialsPath, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return &Firebase{client: client}, nil
+}
+
+func (f *Firebase) object(key string) *gcs.ObjectHandle {
+	return f.client.StorageClient.Bucket(f.client.BucketName).Object(key)
+}
+
+func (f *Firebase) publicPrefix() string {
+	return fmt.Sprintf("https://storage.googleapis.com/%s/", f.client.BucketName)
+}
+
+// Upload writes the object and, unless the bucket is private, makes it
+// publicly readable
+func (f *Firebase) Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	obj := f.object(key)
+	// Cancelling the writer's context abandons the object, so a failed or
+	// oversize upload doesn't leave a truncated file behind
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wc := obj.NewWriter(wctx)
+	wc.ContentType = contentType
+	if _, err := io.Copy(wc, r); err != nil {
+		cancel()
+		wc.Close()
+		return "", fmt.Errorf("failed to copy file data: %w", err)
+	}
+	if err := wc.Close(); err != nil {
+		return "", fmt.Errorf("failed to close writer: %w", err)
+	}
+	if !f.Private {
+		if err := obj.ACL().Set(ctx, gcs.AllUsers, gcs.RoleReader); err != nil {
+			return "", fmt.Errorf("failed to set public access: %w", err)
+		}
+	}
+	return f.PublicURL(key), nil
+}
+
+// Delete removes the object
+func (f *Firebase) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := f.object(key).Delete(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL signs a GET URL with the service account credentials
+func (f *Firebase) SignedURL(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return f.client.StorageClient.Bucket(f.client.BucketName).SignedURL(key, &gcs.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(expiry),
+		Scheme:  gcs.SigningSchemeV4,
+	})
+}
+
+// PublicURL returns the object's URL on storage.googleapis.com
+func (f *Firebase) PublicURL(key string) string {
+	return f.publicPrefix() + key
+}
+
+// KeyFromURL recognises public URLs of this bucket
+func (f *Firebase) KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, f.publicPrefix())
+	if !ok || validKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// List pages through the bucket's objects below prefix
+func (f *Firebase) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	it := f.client.StorageClient.Bucket(f.client.BucketName).Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, Object{
+			Key:       attrs.Name,
+			URL:       f.publicPrefix() + attrs.Name,
+			Size:      attrs.Size,
+			UpdatedAt: attrs.Updated,
+		})
+	}
+}
+
+// Ping lists at most one object, which needs the same access as List
+func (f *Firebase) Ping(ctx context.Context) error {
+	it := f.client.StorageClient.Bucket(f.client.BucketName).Objects(ctx, nil)
+	it.PageInfo().MaxSize = 1
+	if _, err := it.Next(); err != nil && !errors.Is(err, iterator.Done) {
+		return err
+	}
+	return nil
+}
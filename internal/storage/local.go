@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Local stores files on disk under Dir, served by the API at /uploads
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+// NewLocal creates a disk backend; baseURL is the API's public origin
+func NewLocal(dir, baseURL string) *Local {
+	return &Local{Dir: dir, BaseURL: baseURL}
+}
+
+// Upload writes the file below Dir, creating directories for prefixed keys
+func (l *Local) Upload(_ context.Context, key string, r io.Reader, _ string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	path := filepath.Join(l.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return l.PublicURL(key), nil
+}
+
+// Delete removes the file from disk
+func (l *Local) Delete(_ context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(l.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// SignedURL returns the public URL; local files are not access controlled
+func (l *Local) SignedURL(_ context.Context, key string, _ time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return l.PublicURL(key), nil
+}
+
+// PublicURL returns the file's URL under /uploads/
+func (l *Local) PublicURL(key string) string {
+	return l.BaseURL + "/uploads/" + key
+}
+
+// KeyFromURL accepts any URL, absolute or relative, whose path is under
+// /uploads/, since older uploads were stored with the request's origin
+func (l *Local) KeyFromURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	key, ok := strings.CutPrefix(u.Path, "/uploads/")
+	if !ok || validKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// List walks Dir for files below prefix
+func (l *Local) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(l.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{
+			Key:       key,
+			URL:       l.BaseURL + "/uploads/" + key,
+			Size:      info.Size(),
+			UpdatedAt: info.ModTime(),
+		})
+		return nil
+	})
+	return objects, err
+}
+
+// Ping checks that Dir is a directory. A missing Dir is fine; the first
+// upload creates it.
+func (l *Local) Ping(_ context.Context) error {
+	info, err := os.Stat(l.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", l.Dir)
+	}
+	return nil
+}
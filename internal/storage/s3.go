@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3 stores files in an Amazon S3 bucket. Objects are expected to be made
+// public by the bucket policy; no per-object ACL is set.
+type S3 struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	bucket  string
+	region  string
+}
+
+// NewS3 creates an S3 backend from static credentials
+func NewS3(region, bucket, accessKey, secretKey string) (*S3, error) {
+	if bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("AWS_S3_BUCKET_NAME, AWS_S3_ACCESS_KEY and AWS_S3_SECRET_KEY are required for S3 storage")
+	}
+	client := s3.New(s3.Options{
+		Region:      region,
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+	})
+	return &S3{client: client, presign: s3.NewPresignClient(client), bucket: bucket, region: region}, nil
+}
+
+func (s *S3) publicPrefix() string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s.bucket, s.region)
+}
+
+// Upload puts the object and returns its virtual-hosted URL
+func (s *S3) Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	// PutObject needs a body it can measure and rewind; streamed uploads
+	// are buffered here (callers cap their size)
+	if _, ok := r.(io.ReadSeeker); !ok {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file data: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	return s.PublicURL(key), nil
+}
+
+// Delete removes the object; S3 reports success for missing keys
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// SignedURL presigns a GET request for the object
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// PublicURL returns the object's virtual-hosted URL
+func (s *S3) PublicURL(key string) string {
+	return s.publicPrefix() + key
+}
+
+// KeyFromURL recognises virtual-hosted URLs of this bucket
+func (s *S3) KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.publicPrefix())
+	if !ok || validKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// List pages through the bucket's objects below prefix
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			objects = append(objects, Object{
+				Key:       key,
+				URL:       s.publicPrefix() + key,
+				Size:      aws.ToInt64(obj.Size),
+				UpdatedAt: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// Ping lists at most one object, which needs the same access as List
+func (s *S3) Ping(ctx context.Context) error {
+	_, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		MaxKeys: aws.Int32(1),
+	})
+	return err
+}
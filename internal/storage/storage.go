@@ -0,0 +1,172 @@
+// Package storage stores uploaded files in Firebase Storage, Amazon S3 or on
+// local disk behind one interface, selected with STORAGE_PROVIDER.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/shivam-mishra-20/mak-watches-be/internal/config"
+)
+
+// Supported values of STORAGE_PROVIDER
+const (
+	ProviderFirebase = "firebase"
+	ProviderS3       = "s3"
+	ProviderLocal    = "local"
+)
+
+// Storage is a bucket of publicly readable files addressed by key
+type Storage interface {
+	// Upload writes r under key and returns the file's public URL
+	Upload(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+	// Delete removes the file; deleting a missing file is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited URL for the file
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// PublicURL returns the URL Upload returns for key
+	PublicURL(key string) string
+	// KeyFromURL maps a URL returned by Upload back to its key. ok is false
+	// for URLs this backend does not own.
+	KeyFromURL(url string) (key string, ok bool)
+	// List returns every stored file whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Ping checks that the bucket or directory is reachable
+	Ping(ctx context.Context) error
+}
+
+// Object describes a stored file
+type Object struct {
+	Key       string    `json:"key"`
+	URL       string    `json:"url"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// New returns the backend selected by cfg.StorageProvider. In development a
+// Firebase or S3 backend that fails to initialise falls back to local disk.
+func New(ctx context.Context, cfg *config.Config) (Storage, error) {
+	var (
+		s   Storage
+		err error
+	)
+	switch cfg.StorageProvider {
+	case ProviderFirebase, "":
+		var fb *Firebase
+		fb, err = NewFirebase(ctx, cfg.FirebaseCredentialsPath, cfg.FirebaseBucketName)
+		if err == nil {
+			// Signed URLs are for private buckets, so objects stay private
+			fb.Private = cfg.StorageSignedURLTTLMinutes > 0
+			s = fb
+		}
+	case ProviderS3:
+		s, err = NewS3(cfg.AWSS3Region, cfg.AWSS3BucketName, cfg.AWSS3AccessKey, cfg.AWSS3SecretKey)
+	case ProviderLocal:
+		return NewLocal("uploads", localBaseURL(cfg)), nil
+	default:
+		return nil, fmt.Errorf("unknown storage provider %q", cfg.StorageProvider)
+	}
+	if err == nil {
+		return s, nil
+	}
+
+	switch cfg.Environment {
+	case "development", "dev", "local":
+		log.Printf("[STORAGE] %s unavailable (%v); falling back to local file storage under ./uploads", cfg.StorageProvider, err)
+		return NewLocal("uploads", localBaseURL(cfg)), nil
+	}
+	return nil, err
+}
+
+func localBaseURL(cfg *config.Config) string {
+	if cfg.PublicBaseURL != "" {
+		return strings.TrimSuffix(cfg.PublicBaseURL, "/")
+	}
+	return "http://localhost:" + cfg.Port
+}
+
+// NewKey builds a unique key for an uploaded file under prefix, keeping a
+// sanitised form of the original name so objects stay recognisable
+func NewKey(prefix, filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+	if name == "" {
+		name = "file"
+	}
+	key := primitive.NewObjectID().Hex() + "-" + name + ext
+	if prefix != "" {
+		key = strings.Trim(prefix, "/") + "/" + key
+	}
+	return key
+}
+
+// DeleteURLs removes every file in urls that s owns. It is best effort:
+// failures are logged and the count of deleted files is returned.
+func DeleteURLs(ctx context.Context, s Storage, urls []string) int {
+	deleted := 0
+	for _, url := range urls {
+		key, ok := s.KeyFromURL(url)
+		if !ok {
+			continue
+		}
+		if err := s.Delete(ctx, key); err != nil {
+			log.Printf("[STORAGE] Failed to delete %s: %v", key, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted
+}
+
+// Unavailable returns a Storage whose every operation fails with err. It
+// lets the API start without storage so only uploads are affected.
+func Unavailable(err error) Storage {
+	return unavailable{err: err}
+}
+
+type unavailable struct{ err error }
+
+func (u unavailable) Upload(context.Context, string, io.Reader, string) (string, error) {
+	return "", u.wrap()
+}
+
+func (u unavailable) Delete(context.Context, string) error { return u.wrap() }
+
+func (u unavailable) SignedURL(context.Context, string, time.Duration) (string, error) {
+	return "", u.wrap()
+}
+
+func (u unavailable) PublicURL(string) string { return "" }
+
+func (u unavailable) KeyFromURL(string) (string, bool) { return "", false }
+
+func (u unavailable) List(context.Context, string) ([]Object, error) { return nil, u.wrap() }
+
+func (u unavailable) Ping(context.Context) error { return u.wrap() }
+
+func (u unavailable) wrap() error {
+	return errors.Join(errors.New("storage unavailable"), u.err)
+}
+
+// validKey rejects keys that could escape the bucket or upload directory
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package validation wraps go-playground/validator so request DTOs declare
+// their rules once in `validate` struct tags and every handler reports
+// failures in the same shape.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one failed rule on one request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	once     sync.Once
+	instance *validator.Validate
+)
+
+// get lazily builds the shared validator. Field names are reported using
+// their json tag so clients see the same names they sent.
+func get() *validator.Validate {
+	once.Do(func() {
+		instance = validator.New(validator.WithRequiredStructEnabled())
+		instance.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	})
+	return instance
+}
+
+// Struct validates v against its `validate` tags and returns one FieldError
+// per failed rule, or nil when v is valid.
+func Struct(v interface{}) []FieldError {
+	err := get().Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		// InvalidValidationError: a programming error such as passing a nil pointer
+		return []FieldError{{Field: "", Rule: "invalid", Message: err.Error()}}
+	}
+
+	out := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		out = append(out, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return out
+}
+
+// fieldPath drops the top-level struct name from the namespace
+// ("CheckoutRequest.shippingAddress.city" -> "shippingAddress.city")
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// message renders a human readable explanation for the common rules
+func message(fe validator.FieldError) string {
+	field := fieldPath(fe)
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters long", field, fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must contain at least %s items", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters long", field, fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("%s must contain at most %s items", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "len":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be exactly %s characters long", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must contain exactly %s items", field, fe.Param())
+	case "numeric":
+		return field + " must contain only digits"
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "mongodb":
+		return field + " must be a valid ID"
+	default:
+		return fmt.Sprintf("%s failed the %q rule", field, fe.Tag())
+	}
+}
@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+// stateTTL is how long a login may take between redirect and callback
+const stateTTL = 10 * time.Minute
+
 // GoogleOAuth handles Google OAuth authentication
 type GoogleOAuth struct {
 	config *oauth2.Config
-	states map[string]bool
+	states map[string]time.Time // When each state expires
 	mu     sync.RWMutex
 }
 
@@ -45,7 +49,7 @@ func NewGoogleOAuth(clientID, clientSecret, redirectURL string) *GoogleOAuth {
 
 	return &GoogleOAuth{
 		config: config,
-		states: make(map[string]bool),
+		states: make(map[string]time.Time),
 	}
 }
 
@@ -54,20 +58,27 @@ func (g *GoogleOAuth) GetAuthURL(state string) string {
 	return g.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
 }
 
-// SaveState saves a state for CSRF protection
+// SaveState saves a state for CSRF protection. States of abandoned logins
+// are dropped here once expired, so they don't pile up.
 func (g *GoogleOAuth) SaveState(state string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	g.states[state] = true
+	now := time.Now()
+	for s, expires := range g.states {
+		if now.After(expires) {
+			delete(g.states, s)
+		}
+	}
+	g.states[state] = now.Add(stateTTL)
 }
 
 // ValidateState validates a state for CSRF protection
 func (g *GoogleOAuth) ValidateState(state string) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	if _, exists := g.states[state]; exists {
+	if expires, exists := g.states[state]; exists {
 		delete(g.states, state) // Remove state after validation
-		return true
+		return time.Now().Before(expires)
 	}
 	return false
 }